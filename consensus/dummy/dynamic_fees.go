@@ -46,7 +46,6 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 		isApricotPhase3 = config.IsApricotPhase3(parent.Time)
 		isApricotPhase4 = config.IsApricotPhase4(parent.Time)
 		isApricotPhase5 = config.IsApricotPhase5(parent.Time)
-		isEUpgrade      = config.IsEUpgrade(parent.Time)
 	)
 	if !isApricotPhase3 || parent.Number.Cmp(common.Big0) == 0 {
 		initialSlice := make([]byte, params.DynamicFeeExtraDataSize)
@@ -178,16 +177,8 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, timestamp uin
 	}
 
 	// Ensure that the base fee does not increase/decrease outside of the bounds
-	switch {
-	case isEUpgrade:
-		baseFee = selectBigWithinBounds(EUpgradeMinBaseFee, baseFee, nil)
-	case isApricotPhase5:
-		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, nil)
-	case isApricotPhase4:
-		baseFee = selectBigWithinBounds(ApricotPhase4MinBaseFee, baseFee, ApricotPhase4MaxBaseFee)
-	default:
-		baseFee = selectBigWithinBounds(ApricotPhase3MinBaseFee, baseFee, ApricotPhase3MaxBaseFee)
-	}
+	minBaseFee, maxBaseFee := config.BaseFeeBounds(parent.Time)
+	baseFee = selectBigWithinBounds(minBaseFee, baseFee, maxBaseFee)
 
 	return newRollupWindow, baseFee, nil
 }
@@ -10,4 +10,11 @@ var (
 		1, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 	}
+	// StorageLastModifiedAddr is the account whose storage maps each storage
+	// slot to the block number at which it was last modified. Like
+	// BlackholeAddr, it is prohibited as a sender or contract address.
+	StorageLastModifiedAddr = common.Address{
+		4, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
 )
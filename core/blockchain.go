@@ -171,6 +171,12 @@ type CacheConfig struct {
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+
+	HotContracts              []common.Address // Contracts to maintain flat storage copies of, to serve storage reads without trie traversal
+	HotContractsCheckInterval uint64           // Number of hot contract storage reads between checks against the trie (0 = disabled)
+
+	GasAnalyticsWindow      uint64 // Number of recently accepted blocks to accumulate the gas used by each address over (0 = disabled)
+	GasAnalyticsMetricsTopN int    // Number of addresses using the most gas to export metrics for
 }
 
 // triedbConfig derives the configures for trie database.
@@ -238,6 +244,9 @@ type BlockChain struct {
 	triedb       *trie.Database // The database handler for maintaining trie nodes.
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
 	stateManager TrieWriter
+	hotContracts *hotContractStorage       // Flat storage copies of hot contracts, nil if none are configured
+	gasAnalytics *gasAnalytics             // Gas used by each address over recently accepted blocks, nil if disabled
+	missingNodes *state.MissingNodeHandler // Classifies and heals the trie nodes missing when reading state
 
 	hc                *HeaderChain
 	rmLogsFeed        event.Feed
@@ -368,6 +377,7 @@ func NewBlockChain(
 		acceptedLogsCache: NewFIFOCache[common.Hash, [][]*types.Log](cacheConfig.AcceptedCacheSize),
 	}
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
+	bc.missingNodes = state.NewMissingNodeHandler(bc.db, cacheConfig.Pruning)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
@@ -424,6 +434,22 @@ func NewBlockChain(
 	// Warm up [hc.acceptedNumberCache] and [acceptedLogsCache]
 	bc.warmAcceptedCaches()
 
+	// Bring the flat storage copies of hot contracts up to date with the last
+	// accepted state.
+	if len(cacheConfig.HotContracts) > 0 {
+		hotContracts, err := newHotContractStorage(bc.db, bc.triedb, cacheConfig.HotContracts, cacheConfig.HotContractsCheckInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not load hot contract storage: %w", err)
+		}
+		if err := hotContracts.Update(head.Root); err != nil {
+			log.Error("failed to update flat storage of hot contracts", "err", err)
+		}
+		bc.hotContracts = hotContracts
+	}
+	if cacheConfig.GasAnalyticsWindow > 0 {
+		bc.gasAnalytics = newGasAnalytics(cacheConfig.GasAnalyticsWindow, cacheConfig.GasAnalyticsMetricsTopN, metrics.DefaultRegistry)
+	}
+
 	// if txlookup limit is 0 (uindexing disabled), we don't need to repair the tx index tail.
 	if bc.cacheConfig.TxLookupLimit != 0 {
 		latestStateSynced := rawdb.GetLatestSyncPerformed(bc.db)
@@ -644,6 +670,18 @@ func (bc *BlockChain) startAcceptor() {
 			log.Crit("failed to write accepted block effects", "err", err)
 		}
 
+		// The flat storage copies of hot contracts are local to this node and
+		// are rebuilt if they fall behind, so failing to update them is not fatal.
+		if bc.hotContracts != nil {
+			if err := bc.hotContracts.Update(next.Root()); err != nil {
+				log.Error("failed to update flat storage of hot contracts", "blockHash", next.Hash(), "err", err)
+			}
+		}
+
+		if bc.gasAnalytics != nil {
+			bc.gasAnalytics.Accept(next.Transactions(), bc.GetReceiptsByHash(next.Hash()))
+		}
+
 		// Ensure [hc.acceptedNumberCache] and [acceptedLogsCache] have latest content
 		bc.hc.acceptedNumberCache.Put(next.NumberU64(), next.Header())
 		logs := bc.collectUnflattenedLogs(next, false)
@@ -1390,6 +1428,7 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	if err != nil {
 		return err
 	}
+	statedb.SetMissingNodeHandler(bc.missingNodes)
 	blockStateInitTimer.Inc(time.Since(substart).Milliseconds())
 
 	// Enable prefetching to pull in trie node paths while processing transactions
@@ -237,7 +237,27 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache, bc.snaps)
+	statedb, err := state.New(root, bc.stateCache, bc.snaps)
+	if err != nil {
+		return nil, err
+	}
+	statedb.SetMissingNodeHandler(bc.missingNodes)
+	return statedb, nil
+}
+
+// RPCStateAt returns a new mutable state based on a particular point in time,
+// for serving RPC requests. Storage reads of hot contracts are served from
+// their flat storage copies, which are local to this node, so the returned
+// state must not be used to process or build blocks.
+func (bc *BlockChain) RPCStateAt(root common.Hash) (*state.StateDB, error) {
+	statedb, err := bc.StateAt(root)
+	if err != nil {
+		return nil, err
+	}
+	if bc.hotContracts != nil {
+		statedb.SetFlatStorageReader(bc.hotContracts)
+	}
+	return statedb, nil
 }
 
 // Config retrieves the chain's fork configuration.
@@ -251,6 +271,12 @@ func (bc *BlockChain) Snapshots() *snapshot.Tree {
 	return bc.snaps
 }
 
+// MissingNodeHandler returns the handler of the trie nodes missing when
+// reading state, on which a healer can be set.
+func (bc *BlockChain) MissingNodeHandler() *state.MissingNodeHandler {
+	return bc.missingNodes
+}
+
 // Validator returns the current validator.
 func (bc *BlockChain) Validator() Validator {
 	return bc.validator
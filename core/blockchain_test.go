@@ -1330,3 +1330,23 @@ func createAndInsertChain(db ethdb.Database, cacheConfig *CacheConfig, gspec *Ge
 
 	return chain, nil
 }
+
+func TestEVMBlockContextGenesisHash(t *testing.T) {
+	require := require.New(t)
+
+	gspec := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  GenesisAlloc{},
+	}
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 2, 10, func(int, *BlockGen) {})
+	require.NoError(err)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, dummy.NewFaker(), vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(err)
+
+	blockCtx := NewEVMBlockContext(blocks[1].Header(), chain, nil)
+	require.Equal(chain.Genesis().Hash(), blockCtx.GetGenesisHash())
+}
@@ -57,6 +57,10 @@ type BlockGen struct {
 	receipts []*types.Receipt
 	uncles   []*types.Header
 
+	// migrationGas is the gas charged to the block for precompile storage
+	// migrations, which is added to the gas used once the block is generated.
+	migrationGas uint64
+
 	engine           consensus.Engine
 	onBlockGenerated func(*types.Block)
 }
@@ -71,7 +75,8 @@ func (b *BlockGen) SetCoinbase(addr common.Address) {
 		panic("coinbase can only be set once")
 	}
 	b.header.Coinbase = addr
-	b.gasPool = new(GasPool).AddGas(b.header.GasLimit)
+	// Gas is reserved for precompile storage migrations.
+	b.gasPool = new(GasPool).AddGas(b.header.GasLimit - b.migrationGas)
 }
 
 // SetExtra sets the extra data field of the generated block.
@@ -196,7 +201,7 @@ func (b *BlockGen) BaseFee() *big.Int {
 
 // Gas returns the amount of gas left in the current block.
 func (b *BlockGen) Gas() uint64 {
-	return b.header.GasLimit - b.header.GasUsed
+	return b.header.GasLimit - b.header.GasUsed - b.migrationGas
 }
 
 // Signer returns a valid signer instance for the current block.
@@ -285,11 +290,15 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to configure precompiles %w", err)
 		}
+		// Reserve the gas charged to the block for the precompile storage
+		// migrations applied above.
+		b.migrationGas = config.PrecompileStorageMigrationGas(&parent.Header().Time, b.header.Time)
 
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
 		}
+		b.header.GasUsed += b.migrationGas
 		// Finalize and seal the block
 		block, err := b.engine.FinalizeAndAssemble(cm, b.header, parent.Header(), statedb, b.txs, b.uncles, b.receipts)
 		if err != nil {
@@ -30,6 +30,7 @@ import (
 	"errors"
 
 	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/vmerrs"
 )
 
 var (
@@ -50,11 +51,11 @@ var (
 var (
 	// ErrNonceTooLow is returned if the nonce of a transaction is lower than the
 	// one present in the local chain.
-	ErrNonceTooLow = errors.New("nonce too low")
+	ErrNonceTooLow = vmerrs.ErrNonceTooLow
 
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
-	ErrNonceTooHigh = errors.New("nonce too high")
+	ErrNonceTooHigh = vmerrs.ErrNonceTooHigh
 
 	// ErrNonceMax is returned if the nonce of a transaction sender account has
 	// maximum allowed value and would become invalid if incremented.
@@ -74,14 +75,14 @@ var (
 
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
-	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+	ErrInsufficientFunds = vmerrs.ErrInsufficientFunds
 
 	// ErrGasUintOverflow is returned when calculating gas usage.
 	ErrGasUintOverflow = errors.New("gas uint64 overflow")
 
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
-	ErrIntrinsicGas = errors.New("intrinsic gas too low")
+	ErrIntrinsicGas = vmerrs.ErrIntrinsicGas
 
 	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
 	// current network configuration.
@@ -101,7 +102,7 @@ var (
 
 	// ErrFeeCapTooLow is returned if the transaction fee cap is less than the
 	// base fee of the block.
-	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+	ErrFeeCapTooLow = vmerrs.ErrFeeCapTooLow
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
@@ -27,6 +27,7 @@
 package core
 
 import (
+	"math"
 	"math/big"
 
 	"github.com/ava-labs/coreth/consensus"
@@ -49,6 +50,12 @@ type ChainContext interface {
 	GetHeader(common.Hash, uint64) *types.Header
 }
 
+// canonicalChainContext is implemented by ChainContexts which can look up
+// headers of the canonical chain by number, such as the BlockChain.
+type canonicalChainContext interface {
+	GetHeaderByNumber(number uint64) *types.Header
+}
+
 // NewEVMBlockContext creates a new context for use in the EVM.
 func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common.Address) vm.BlockContext {
 	predicateBytes, ok := predicate.GetPredicateResultBytes(header.Extra)
@@ -96,7 +103,7 @@ func newEVMBlockContext(header *types.Header, chain ChainContext, author *common
 	if header.ExcessBlobGas != nil {
 		blobBaseFee = eip4844.CalcBlobFee(*header.ExcessBlobGas)
 	}
-	return vm.BlockContext{
+	blockCtx := vm.BlockContext{
 		CanTransfer:       CanTransfer,
 		CanTransferMC:     CanTransferMC,
 		Transfer:          Transfer,
@@ -111,6 +118,26 @@ func newEVMBlockContext(header *types.Header, chain ChainContext, author *common
 		BlobBaseFee:       blobBaseFee,
 		GasLimit:          header.GasLimit,
 	}
+	if canonicalChain, ok := chain.(canonicalChainContext); ok {
+		blockCtx.GenesisHash = func() common.Hash {
+			genesis := canonicalChain.GetHeaderByNumber(0)
+			if genesis == nil {
+				return common.Hash{}
+			}
+			return genesis.Hash()
+		}
+	}
+	return blockCtx
+}
+
+// SimulatedBlockGasRemaining returns a BlockGasRemaining function for use in
+// eth_call and other simulations, which reports the RPC [gasCap] as the gas
+// remaining in the block. A [gasCap] of 0 is reported as unlimited gas.
+func SimulatedBlockGasRemaining(gasCap uint64) func() uint64 {
+	if gasCap == 0 {
+		gasCap = math.MaxUint64
+	}
+	return func() uint64 { return gasCap }
 }
 
 // NewEVMTxContext creates a new transaction context for a single transaction.
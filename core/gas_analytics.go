@@ -0,0 +1,224 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	errGasAnalyticsDisabled = errors.New("gas analytics are disabled")
+	errInvalidGasWindow     = errors.New("invalid gas analytics window")
+	errInvalidGasLimit      = errors.New("invalid gas analytics limit")
+)
+
+// ContractGasUsage is the gas used by the transactions sent to, or creating,
+// an address over a window of accepted blocks.
+type ContractGasUsage struct {
+	Address  common.Address `json:"address"`
+	GasUsed  uint64         `json:"gasUsed"`
+	TxCount  uint64         `json:"txCount"`
+	Failures uint64         `json:"failures"`
+}
+
+func (u *ContractGasUsage) add(other *ContractGasUsage) {
+	u.GasUsed += other.GasUsed
+	u.TxCount += other.TxCount
+	u.Failures += other.Failures
+}
+
+func (u *ContractGasUsage) sub(other *ContractGasUsage) {
+	u.GasUsed -= other.GasUsed
+	u.TxCount -= other.TxCount
+	u.Failures -= other.Failures
+}
+
+// gasAnalytics accumulates the gas used by the transactions of the last
+// [window] accepted blocks, attributed to the recipient of each transaction,
+// or to the contract it creates. Internal calls are not attributed, so that
+// accumulating a block only requires its receipts.
+//
+// Only the usage of the blocks in the window is kept, so memory is bounded by
+// the number of transactions that fit in [window] blocks. The usage is not
+// persisted, so it only covers the blocks accepted since the node started.
+type gasAnalytics struct {
+	window      uint64
+	metricsTopN int
+	registry    metrics.Registry
+
+	// [lock] must be held when accessing the fields below.
+	lock   sync.RWMutex
+	blocks []map[common.Address]*ContractGasUsage // Usage of each block in the window, oldest first
+	totals map[common.Address]*ContractGasUsage   // Sum of the usage of [blocks]
+	// metered are the addresses with gas used gauges registered in
+	// [registry], which are the [metricsTopN] addresses using the most gas
+	// over the window.
+	metered map[common.Address]metrics.Gauge
+}
+
+func newGasAnalytics(window uint64, metricsTopN int, registry metrics.Registry) *gasAnalytics {
+	return &gasAnalytics{
+		window:      window,
+		metricsTopN: metricsTopN,
+		registry:    registry,
+		totals:      make(map[common.Address]*ContractGasUsage),
+		metered:     make(map[common.Address]metrics.Gauge),
+	}
+}
+
+// Accept adds the gas used by [txs], the transactions of an accepted block,
+// to the window, evicting the oldest block if the window is full. [receipts]
+// must be the receipts of [txs].
+func (g *gasAnalytics) Accept(txs types.Transactions, receipts types.Receipts) {
+	usage := make(map[common.Address]*ContractGasUsage)
+	for i, tx := range txs {
+		if i >= len(receipts) {
+			break
+		}
+		receipt := receipts[i]
+		addr := receipt.ContractAddress
+		if to := tx.To(); to != nil {
+			addr = *to
+		}
+		u, ok := usage[addr]
+		if !ok {
+			u = &ContractGasUsage{Address: addr}
+			usage[addr] = u
+		}
+		u.GasUsed += receipt.GasUsed
+		u.TxCount++
+		if receipt.Status == types.ReceiptStatusFailed {
+			u.Failures++
+		}
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if uint64(len(g.blocks)) == g.window {
+		for addr, u := range g.blocks[0] {
+			total := g.totals[addr]
+			total.sub(u)
+			if total.TxCount == 0 {
+				delete(g.totals, addr)
+			}
+		}
+		g.blocks[0] = nil
+		g.blocks = g.blocks[1:]
+	}
+	g.blocks = append(g.blocks, usage)
+	for addr, u := range usage {
+		total, ok := g.totals[addr]
+		if !ok {
+			total = &ContractGasUsage{Address: addr}
+			g.totals[addr] = total
+		}
+		total.add(u)
+	}
+	g.updateMetrics()
+}
+
+// updateMetrics registers gauges for the [metricsTopN] addresses using the
+// most gas over the window, and unregisters those of the addresses no longer
+// among them, so that the number of gauges is bounded.
+//
+// Assumes [lock] is held.
+func (g *gasAnalytics) updateMetrics() {
+	if g.metricsTopN <= 0 {
+		return
+	}
+	top := rankGasUsage(g.totals, g.metricsTopN)
+	inTop := make(map[common.Address]struct{}, len(top))
+	for _, u := range top {
+		inTop[u.Address] = struct{}{}
+		gauge, ok := g.metered[u.Address]
+		if !ok {
+			gauge = metrics.GetOrRegisterGauge(gasUsedMetricName(u.Address), g.registry)
+			g.metered[u.Address] = gauge
+		}
+		gauge.Update(int64(u.GasUsed))
+	}
+	for addr := range g.metered {
+		if _, ok := inTop[addr]; !ok {
+			g.registry.Unregister(gasUsedMetricName(addr))
+			delete(g.metered, addr)
+		}
+	}
+}
+
+func gasUsedMetricName(addr common.Address) string {
+	return fmt.Sprintf("chain/gasanalytics/%s/gasused", addr.Hex())
+}
+
+// Top returns the [limit] addresses whose transactions used the most gas in
+// the last [windowBlocks] accepted blocks, in order of decreasing gas used.
+// [windowBlocks] must be at most the window of [g].
+func (g *gasAnalytics) Top(windowBlocks uint64, limit int) ([]ContractGasUsage, error) {
+	if windowBlocks == 0 || windowBlocks > g.window {
+		return nil, fmt.Errorf("%w: %d blocks must be in the range [1, %d]", errInvalidGasWindow, windowBlocks, g.window)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("%w: %d must be positive", errInvalidGasLimit, limit)
+	}
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	if windowBlocks >= uint64(len(g.blocks)) {
+		return rankGasUsage(g.totals, limit), nil
+	}
+	usage := make(map[common.Address]*ContractGasUsage)
+	for _, block := range g.blocks[uint64(len(g.blocks))-windowBlocks:] {
+		for addr, u := range block {
+			total, ok := usage[addr]
+			if !ok {
+				total = &ContractGasUsage{Address: addr}
+				usage[addr] = total
+			}
+			total.add(u)
+		}
+	}
+	return rankGasUsage(usage, limit), nil
+}
+
+// rankGasUsage returns the [limit] entries of [usage] with the most gas used,
+// in order of decreasing gas used and then of address.
+func rankGasUsage(usage map[common.Address]*ContractGasUsage, limit int) []ContractGasUsage {
+	ranked := make([]ContractGasUsage, 0, len(usage))
+	for _, u := range usage {
+		ranked = append(ranked, *u)
+	}
+	slices.SortFunc(ranked, func(a, b ContractGasUsage) int {
+		if a.GasUsed != b.GasUsed {
+			if a.GasUsed > b.GasUsed {
+				return -1
+			}
+			return 1
+		}
+		return bytes.Compare(a.Address[:], b.Address[:])
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// TopGasConsumers returns the [limit] addresses whose transactions used the
+// most gas in the last [windowBlocks] accepted blocks, attributing the gas of
+// each transaction to its recipient or to the contract it creates. Only the
+// blocks accepted since the node started are included.
+func (bc *BlockChain) TopGasConsumers(windowBlocks uint64, limit int) ([]ContractGasUsage, error) {
+	if bc.gasAnalytics == nil {
+		return nil, errGasAnalyticsDisabled
+	}
+	return bc.gasAnalytics.Top(windowBlocks, limit)
+}
@@ -0,0 +1,117 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// gasAnalyticsBlock builds the transactions and receipts of a block.
+type gasAnalyticsBlock struct {
+	txs      types.Transactions
+	receipts types.Receipts
+}
+
+func (b *gasAnalyticsBlock) call(to common.Address, gasUsed uint64, failed bool) *gasAnalyticsBlock {
+	status := types.ReceiptStatusSuccessful
+	if failed {
+		status = types.ReceiptStatusFailed
+	}
+	b.txs = append(b.txs, types.NewTransaction(uint64(len(b.txs)), to, big.NewInt(0), gasUsed, big.NewInt(1), nil))
+	b.receipts = append(b.receipts, &types.Receipt{Status: status, GasUsed: gasUsed})
+	return b
+}
+
+func (b *gasAnalyticsBlock) create(contract common.Address, gasUsed uint64) *gasAnalyticsBlock {
+	b.txs = append(b.txs, types.NewContractCreation(uint64(len(b.txs)), big.NewInt(0), gasUsed, big.NewInt(1), nil))
+	b.receipts = append(b.receipts, &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: gasUsed, ContractAddress: contract})
+	return b
+}
+
+func TestGasAnalytics(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		contractA = common.HexToAddress("0xa")
+		contractB = common.HexToAddress("0xb")
+		contractC = common.HexToAddress("0xc")
+		eoa       = common.HexToAddress("0xe")
+	)
+	registry := metrics.NewRegistry()
+	g := newGasAnalytics(2, 2, registry)
+
+	// A mixed block of calls, a failed call, a contract creation and a
+	// transfer.
+	block := new(gasAnalyticsBlock).
+		call(contractA, 50_000, false).
+		call(contractB, 60_000, false).
+		create(contractC, 100_000).
+		call(contractA, 30_000, true).
+		call(eoa, 21_000, false)
+	g.Accept(block.txs, block.receipts)
+
+	top, err := g.Top(1, 10)
+	require.NoError(err)
+	require.Equal([]ContractGasUsage{
+		{Address: contractC, GasUsed: 100_000, TxCount: 1},
+		{Address: contractA, GasUsed: 80_000, TxCount: 2, Failures: 1},
+		{Address: contractB, GasUsed: 60_000, TxCount: 1},
+		{Address: eoa, GasUsed: 21_000, TxCount: 1},
+	}, top)
+
+	top, err = g.Top(2, 2)
+	require.NoError(err)
+	require.Equal([]ContractGasUsage{
+		{Address: contractC, GasUsed: 100_000, TxCount: 1},
+		{Address: contractA, GasUsed: 80_000, TxCount: 2, Failures: 1},
+	}, top)
+	require.NotNil(registry.Get(gasUsedMetricName(contractC)))
+	require.NotNil(registry.Get(gasUsedMetricName(contractA)))
+	require.Nil(registry.Get(gasUsedMetricName(contractB)))
+
+	block = new(gasAnalyticsBlock).call(contractB, 200_000, false)
+	g.Accept(block.txs, block.receipts)
+
+	top, err = g.Top(1, 10)
+	require.NoError(err)
+	require.Equal([]ContractGasUsage{
+		{Address: contractB, GasUsed: 200_000, TxCount: 1},
+	}, top)
+
+	top, err = g.Top(2, 3)
+	require.NoError(err)
+	require.Equal([]ContractGasUsage{
+		{Address: contractB, GasUsed: 260_000, TxCount: 2},
+		{Address: contractC, GasUsed: 100_000, TxCount: 1},
+		{Address: contractA, GasUsed: 80_000, TxCount: 2, Failures: 1},
+	}, top)
+
+	// Only the gauges of the top addresses are registered.
+	require.NotNil(registry.Get(gasUsedMetricName(contractB)))
+	require.NotNil(registry.Get(gasUsedMetricName(contractC)))
+	require.Nil(registry.Get(gasUsedMetricName(contractA)))
+
+	// The first block is evicted from the window.
+	g.Accept(nil, nil)
+	top, err = g.Top(2, 10)
+	require.NoError(err)
+	require.Equal([]ContractGasUsage{
+		{Address: contractB, GasUsed: 200_000, TxCount: 1},
+	}, top)
+	require.Len(g.totals, 1)
+	require.Nil(registry.Get(gasUsedMetricName(contractC)))
+
+	_, err = g.Top(0, 10)
+	require.ErrorIs(err, errInvalidGasWindow)
+	_, err = g.Top(3, 10)
+	require.ErrorIs(err, errInvalidGasWindow)
+	_, err = g.Top(1, 0)
+	require.ErrorIs(err, errInvalidGasLimit)
+}
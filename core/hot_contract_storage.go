@@ -0,0 +1,318 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/metrics"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	hotContractStorageHits          = metrics.NewRegisteredCounter("chain/hotcontracts/storage/hits", nil)
+	hotContractStorageMisses        = metrics.NewRegisteredCounter("chain/hotcontracts/storage/misses", nil)
+	hotContractStorageChecks        = metrics.NewRegisteredCounter("chain/hotcontracts/storage/checks", nil)
+	hotContractStorageInconsistents = metrics.NewRegisteredCounter("chain/hotcontracts/storage/inconsistent", nil)
+	hotContractStorageRebuilds      = metrics.NewRegisteredCounter("chain/hotcontracts/storage/rebuilds", nil)
+)
+
+// hotContractRoots are the roots a flat storage copy is consistent with.
+type hotContractRoots struct {
+	state   common.Hash
+	storage common.Hash
+}
+
+// hotContractStorage maintains flat copies of the storage of a configured set
+// of contracts, so that reads of their storage do not traverse the storage
+// trie. The copies are updated when blocks are accepted and are local to this
+// node: any copy that is missing, cannot be updated incrementally, or is found
+// to be inconsistent with the trie is rebuilt from the trie.
+type hotContractStorage struct {
+	db        ethdb.Database
+	triedb    *trie.Database
+	contracts []common.Address
+
+	// checkInterval is the number of reads between checks of a read value
+	// against the trie. 0 disables the checks.
+	checkInterval uint64
+	reads         atomic.Uint64
+
+	// [lock] must be held when accessing [roots], and read locked while
+	// reading a flat storage copy, so that the copy is not modified between
+	// checking its roots and reading it. Contracts without an entry in [roots]
+	// are not served.
+	lock  sync.RWMutex
+	roots map[common.Address]hotContractRoots
+}
+
+// newHotContractStorage returns a hotContractStorage serving the flat storage
+// copies of [contracts] already in [db], and removes the copies of contracts
+// no longer configured.
+func newHotContractStorage(db ethdb.Database, triedb *trie.Database, contracts []common.Address, checkInterval uint64) (*hotContractStorage, error) {
+	h := &hotContractStorage{
+		db:            db,
+		triedb:        triedb,
+		contracts:     contracts,
+		checkInterval: checkInterval,
+		roots:         make(map[common.Address]hotContractRoots, len(contracts)),
+	}
+	configured := make(map[common.Address]struct{}, len(contracts))
+	for _, addr := range contracts {
+		configured[addr] = struct{}{}
+		if stateRoot, storageRoot, ok := rawdb.ReadHotContractRoots(db, addr); ok {
+			h.roots[addr] = hotContractRoots{state: stateRoot, storage: storageRoot}
+		}
+	}
+
+	var stale []common.Address
+	it := rawdb.NewHotContractRootsIterator(db)
+	for it.Next() {
+		addr := rawdb.UnpackHotContractRootsKey(it.Key())
+		if _, ok := configured[addr]; !ok {
+			stale = append(stale, addr)
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	for _, addr := range stale {
+		log.Info("Removing flat storage of contract no longer configured as hot", "address", addr)
+		rawdb.DeleteHotContractRoots(db, addr)
+		if err := rawdb.ClearHotContractStorage(db, addr); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Storage implements state.FlatStorageReader.
+func (h *hotContractStorage) Storage(addr common.Address, storageRoot common.Hash, slotHash common.Hash) ([]byte, bool) {
+	h.lock.RLock()
+	roots, ok := h.roots[addr]
+	if !ok || roots.storage != storageRoot {
+		h.lock.RUnlock()
+		hotContractStorageMisses.Inc(1)
+		return nil, false
+	}
+	// Updates remove the roots of a copy under [lock] before modifying it, so
+	// the copy holds the values at [roots] until the lock is released.
+	enc := rawdb.ReadHotContractStorage(h.db, addr, slotHash)
+	h.lock.RUnlock()
+
+	if h.checkInterval > 0 && h.reads.Add(1)%h.checkInterval == 0 && !h.check(addr, roots, slotHash, enc) {
+		hotContractStorageMisses.Inc(1)
+		return nil, false
+	}
+	hotContractStorageHits.Inc(1)
+	return enc, true
+}
+
+// check returns false and invalidates the flat storage copy of [addr] if [enc]
+// is not the value of [slotHash] in the storage trie.
+func (h *hotContractStorage) check(addr common.Address, roots hotContractRoots, slotHash common.Hash, enc []byte) bool {
+	hotContractStorageChecks.Inc(1)
+	tr, err := trie.New(trie.StorageTrieID(roots.state, crypto.Keccak256Hash(addr[:]), roots.storage), h.triedb)
+	if err != nil {
+		// The trie may no longer be available, in which case the value
+		// cannot be checked.
+		log.Debug("Failed to open storage trie to check hot contract storage", "address", addr, "err", err)
+		return true
+	}
+	expected, err := tr.Get(slotHash[:])
+	if err != nil {
+		log.Debug("Failed to read storage trie to check hot contract storage", "address", addr, "err", err)
+		return true
+	}
+	if bytes.Equal(expected, enc) {
+		return true
+	}
+
+	log.Warn("Flat storage of hot contract is inconsistent with the trie, will rebuild", "address", addr, "slot", slotHash, "storageRoot", roots.storage)
+	hotContractStorageInconsistents.Inc(1)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.roots[addr] == roots {
+		delete(h.roots, addr)
+		rawdb.DeleteHotContractRoots(h.db, addr)
+	}
+	return false
+}
+
+// Update brings the flat storage copy of each hot contract up to date with
+// the state with root [root]. Copies that are missing or were invalidated are
+// rebuilt.
+func (h *hotContractStorage) Update(root common.Hash) error {
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), h.triedb)
+	if err != nil {
+		return err
+	}
+	for _, addr := range h.contracts {
+		acct, err := tr.GetAccount(addr)
+		if err != nil {
+			return err
+		}
+		next := hotContractRoots{state: root, storage: types.EmptyRootHash}
+		if acct != nil {
+			next.storage = acct.Root
+		}
+		if err := h.update(addr, next); err != nil {
+			return fmt.Errorf("failed to update flat storage of hot contract %s: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+func (h *hotContractStorage) update(addr common.Address, next hotContractRoots) error {
+	h.lock.Lock()
+	prev, ok := h.roots[addr]
+	if ok && prev.storage == next.storage {
+		// Only the state root the copy is consistent with changes.
+		h.roots[addr] = next
+		h.lock.Unlock()
+		rawdb.WriteHotContractRoots(h.db, addr, next.state, next.storage)
+		return nil
+	}
+	// Stop serving the copy while it is modified. The roots are deleted on
+	// disk first, so that if the update is interrupted the copy is rebuilt.
+	delete(h.roots, addr)
+	h.lock.Unlock()
+	rawdb.DeleteHotContractRoots(h.db, addr)
+
+	var err error
+	if ok {
+		err = h.updateDiff(addr, prev, next)
+		if err != nil {
+			log.Debug("Failed to update flat storage of hot contract incrementally, rebuilding", "address", addr, "err", err)
+		}
+	}
+	if !ok || err != nil {
+		if err := h.rebuild(addr, next); err != nil {
+			return err
+		}
+	}
+
+	rawdb.WriteHotContractRoots(h.db, addr, next.state, next.storage)
+	h.lock.Lock()
+	h.roots[addr] = next
+	h.lock.Unlock()
+	return nil
+}
+
+// updateDiff applies the difference between the storage tries of [addr] at
+// [prev] and [next] to its flat storage copy.
+func (h *hotContractStorage) updateDiff(addr common.Address, prev, next hotContractRoots) error {
+	addrHash := crypto.Keccak256Hash(addr[:])
+	prevTrie, err := trie.New(trie.StorageTrieID(prev.state, addrHash, prev.storage), h.triedb)
+	if err != nil {
+		return err
+	}
+	nextTrie, err := trie.New(trie.StorageTrieID(next.state, addrHash, next.storage), h.triedb)
+	if err != nil {
+		return err
+	}
+
+	batch := h.db.NewBatch()
+	// Write the slots which were added or modified.
+	changed, err := newTrieDifferenceIterator(prevTrie, nextTrie)
+	if err != nil {
+		return err
+	}
+	for changed.Next() {
+		rawdb.WriteHotContractStorage(batch, addr, common.BytesToHash(changed.Key), changed.Value)
+		if err := writeBatchIfFull(batch); err != nil {
+			return err
+		}
+	}
+	if changed.Err != nil {
+		return changed.Err
+	}
+	// Delete the slots which were removed.
+	removed, err := newTrieDifferenceIterator(nextTrie, prevTrie)
+	if err != nil {
+		return err
+	}
+	for removed.Next() {
+		value, err := nextTrie.Get(removed.Key)
+		if err != nil {
+			return err
+		}
+		if len(value) == 0 {
+			rawdb.DeleteHotContractStorage(batch, addr, common.BytesToHash(removed.Key))
+			if err := writeBatchIfFull(batch); err != nil {
+				return err
+			}
+		}
+	}
+	if removed.Err != nil {
+		return removed.Err
+	}
+	return batch.Write()
+}
+
+// rebuild replaces the flat storage copy of [addr] with the contents of its
+// storage trie at [next].
+func (h *hotContractStorage) rebuild(addr common.Address, next hotContractRoots) error {
+	log.Info("Rebuilding flat storage of hot contract", "address", addr, "storageRoot", next.storage)
+	hotContractStorageRebuilds.Inc(1)
+	if err := rawdb.ClearHotContractStorage(h.db, addr); err != nil {
+		return err
+	}
+	tr, err := trie.New(trie.StorageTrieID(next.state, crypto.Keccak256Hash(addr[:]), next.storage), h.triedb)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	it := trie.NewIterator(nodeIt)
+	batch := h.db.NewBatch()
+	for it.Next() {
+		rawdb.WriteHotContractStorage(batch, addr, common.BytesToHash(it.Key), it.Value)
+		if err := writeBatchIfFull(batch); err != nil {
+			return err
+		}
+	}
+	if it.Err != nil {
+		return it.Err
+	}
+	return batch.Write()
+}
+
+// newTrieDifferenceIterator returns an iterator over the leaves of [b] which
+// are not in [a].
+func newTrieDifferenceIterator(a, b *trie.Trie) (*trie.Iterator, error) {
+	aIt, err := a.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	bIt, err := b.NodeIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	diff, _ := trie.NewDifferenceIterator(aIt, bIt)
+	return trie.NewIterator(diff), nil
+}
+
+func writeBatchIfFull(batch ethdb.Batch) error {
+	if batch.ValueSize() < ethdb.IdealBatchSize {
+		return nil
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	batch.Reset()
+	return nil
+}
@@ -0,0 +1,247 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var hotContractAddr = common.HexToAddress("0xaa")
+
+// commitHotContractStorage applies [slots] to the storage of [hotContractAddr]
+// in the state with root [root] and returns the new state root. The keys of
+// [slots] must be normalized, see state.NormalizeStateKey.
+func commitHotContractStorage(t testing.TB, sdb state.Database, root common.Hash, slots map[common.Hash]common.Hash) common.Hash {
+	statedb, err := state.New(root, sdb, nil)
+	require.NoError(t, err)
+	statedb.SetNonce(hotContractAddr, 1)
+	for key, value := range slots {
+		statedb.SetState(hotContractAddr, key, value)
+	}
+	root, err = statedb.Commit(0, false, false)
+	require.NoError(t, err)
+	return root
+}
+
+// requireHotContractStorage checks that the storage of [hotContractAddr] in
+// the state with root [root] is served from [h] and equals [expected].
+func requireHotContractStorage(t *testing.T, sdb state.Database, h *hotContractStorage, root common.Hash, expected map[common.Hash]common.Hash) {
+	require := require.New(t)
+
+	statedb, err := state.New(root, sdb, nil)
+	require.NoError(err)
+	storageRoot := statedb.GetStorageRoot(hotContractAddr)
+	statedb.SetFlatStorageReader(h)
+	for key, value := range expected {
+		_, ok := h.Storage(hotContractAddr, storageRoot, crypto.Keccak256Hash(key[:]))
+		require.True(ok)
+		require.Equal(value, statedb.GetState(hotContractAddr, key))
+	}
+	require.NoError(statedb.Error())
+}
+
+func TestHotContractStorageUpdate(t *testing.T) {
+	require := require.New(t)
+
+	db := rawdb.NewMemoryDatabase()
+	sdb := state.NewDatabase(db)
+	h, err := newHotContractStorage(db, sdb.TrieDB(), []common.Address{hotContractAddr}, 0)
+	require.NoError(err)
+
+	// A missing copy is built from the trie.
+	root1 := commitHotContractStorage(t, sdb, types.EmptyRootHash, map[common.Hash]common.Hash{
+		{0x10}: {1},
+		{0x20}: {2},
+		{0x30}: {3},
+	})
+	rebuilds := hotContractStorageRebuilds.Snapshot().Count()
+	require.NoError(h.Update(root1))
+	require.Equal(rebuilds+1, hotContractStorageRebuilds.Snapshot().Count())
+	requireHotContractStorage(t, sdb, h, root1, map[common.Hash]common.Hash{
+		{0x10}: {1},
+		{0x20}: {2},
+		{0x30}: {3},
+		{0x40}: {},
+	})
+
+	// Modified, added and deleted slots are applied incrementally.
+	root2 := commitHotContractStorage(t, sdb, root1, map[common.Hash]common.Hash{
+		{0x10}: {},
+		{0x20}: {5},
+		{0x40}: {4},
+	})
+	require.NoError(h.Update(root2))
+	require.Equal(rebuilds+1, hotContractStorageRebuilds.Snapshot().Count())
+	requireHotContractStorage(t, sdb, h, root2, map[common.Hash]common.Hash{
+		{0x10}: {},
+		{0x20}: {5},
+		{0x30}: {3},
+		{0x40}: {4},
+	})
+
+	// The copy is not served for other storage roots.
+	statedb, err := state.New(root1, sdb, nil)
+	require.NoError(err)
+	_, ok := h.Storage(hotContractAddr, statedb.GetStorageRoot(hotContractAddr), crypto.Keccak256Hash(common.Hash{0x10}.Bytes()))
+	require.False(ok)
+
+	// The copy is loaded on restart.
+	h, err = newHotContractStorage(db, sdb.TrieDB(), []common.Address{hotContractAddr}, 0)
+	require.NoError(err)
+	requireHotContractStorage(t, sdb, h, root2, map[common.Hash]common.Hash{
+		{0x20}: {5},
+	})
+
+	// The copy is removed once the contract is no longer configured.
+	_, err = newHotContractStorage(db, sdb.TrieDB(), nil, 0)
+	require.NoError(err)
+	_, _, ok = rawdb.ReadHotContractRoots(db, hotContractAddr)
+	require.False(ok)
+	require.Empty(rawdb.ReadHotContractStorage(db, hotContractAddr, crypto.Keccak256Hash(common.Hash{0x20}.Bytes())))
+}
+
+func TestHotContractStorageRebuildInconsistent(t *testing.T) {
+	require := require.New(t)
+
+	db := rawdb.NewMemoryDatabase()
+	sdb := state.NewDatabase(db)
+	// Check every read against the trie.
+	h, err := newHotContractStorage(db, sdb.TrieDB(), []common.Address{hotContractAddr}, 1)
+	require.NoError(err)
+
+	slots := map[common.Hash]common.Hash{
+		{0x10}: {1},
+		{0x20}: {2},
+	}
+	root := commitHotContractStorage(t, sdb, types.EmptyRootHash, slots)
+	require.NoError(h.Update(root))
+	requireHotContractStorage(t, sdb, h, root, slots)
+
+	// Corrupt the copy of a slot.
+	slotHash := crypto.Keccak256Hash(common.Hash{0x10}.Bytes())
+	rawdb.WriteHotContractStorage(db, hotContractAddr, slotHash, []byte{0x09})
+
+	// The inconsistency is detected, so the copy is no longer used and reads
+	// fall back to the trie.
+	statedb, err := state.New(root, sdb, nil)
+	require.NoError(err)
+	statedb.SetFlatStorageReader(h)
+	inconsistents := hotContractStorageInconsistents.Snapshot().Count()
+	require.Equal(common.Hash{1}, statedb.GetState(hotContractAddr, common.Hash{0x10}))
+	require.Equal(inconsistents+1, hotContractStorageInconsistents.Snapshot().Count())
+	_, ok := h.Storage(hotContractAddr, statedb.GetStorageRoot(hotContractAddr), slotHash)
+	require.False(ok)
+	_, _, ok = rawdb.ReadHotContractRoots(db, hotContractAddr)
+	require.False(ok)
+
+	// The copy is rebuilt on the next update, even though the storage of the
+	// contract did not change.
+	rebuilds := hotContractStorageRebuilds.Snapshot().Count()
+	require.NoError(h.Update(root))
+	require.Equal(rebuilds+1, hotContractStorageRebuilds.Snapshot().Count())
+	requireHotContractStorage(t, sdb, h, root, slots)
+}
+
+func TestHotContractStorageConcurrentUpdate(t *testing.T) {
+	require := require.New(t)
+
+	db := rawdb.NewMemoryDatabase()
+	sdb := state.NewDatabase(db)
+	h, err := newHotContractStorage(db, sdb.TrieDB(), []common.Address{hotContractAddr}, 0)
+	require.NoError(err)
+
+	// The slot has a different value at each root.
+	key := common.Hash{0x10}
+	slotHash := crypto.Keccak256Hash(key[:])
+	roots := []common.Hash{
+		commitHotContractStorage(t, sdb, types.EmptyRootHash, map[common.Hash]common.Hash{key: {1}}),
+	}
+	roots = append(roots, commitHotContractStorage(t, sdb, roots[0], map[common.Hash]common.Hash{key: {2}}))
+	expected := make(map[common.Hash][]byte, len(roots))
+	for _, root := range roots {
+		statedb, err := state.New(root, sdb, nil)
+		require.NoError(err)
+		storageRoot := statedb.GetStorageRoot(hotContractAddr)
+		tr, err := trie.New(trie.StorageTrieID(root, crypto.Keccak256Hash(hotContractAddr[:]), storageRoot), sdb.TrieDB())
+		require.NoError(err)
+		expected[storageRoot], err = tr.Get(slotHash[:])
+		require.NoError(err)
+	}
+
+	// A value served for a storage root is always the value at that root,
+	// while the copy is updated back and forth between the roots.
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for storageRoot, value := range expected {
+				if enc, ok := h.Storage(hotContractAddr, storageRoot, slotHash); ok && !bytes.Equal(enc, value) {
+					errs <- fmt.Errorf("served %x for storage root %s, expected %x", enc, storageRoot, value)
+					return
+				}
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		require.NoError(h.Update(roots[i%len(roots)]))
+	}
+	close(done)
+	require.NoError(<-errs)
+}
+
+func BenchmarkHotContractStorageRead(b *testing.B) {
+	const numSlots = 1_000_000
+
+	db := rawdb.NewMemoryDatabase()
+	sdb := state.NewDatabase(db)
+	slots := make(map[common.Hash]common.Hash, numSlots)
+	keys := make([]common.Hash, 0, numSlots)
+	for i := 0; i < numSlots; i++ {
+		key := crypto.Keccak256Hash(common.BigToHash(big.NewInt(int64(i))).Bytes())
+		state.NormalizeStateKey(&key)
+		slots[key] = common.Hash{1}
+		keys = append(keys, key)
+	}
+	root := commitHotContractStorage(b, sdb, types.EmptyRootHash, slots)
+
+	h, err := newHotContractStorage(db, sdb.TrieDB(), []common.Address{hotContractAddr}, 0)
+	require.NoError(b, err)
+	require.NoError(b, h.Update(root))
+	roots := h.roots[hotContractAddr]
+
+	b.Run("trie", func(b *testing.B) {
+		tr, err := trie.New(trie.StorageTrieID(roots.state, crypto.Keccak256Hash(hotContractAddr[:]), roots.storage), sdb.TrieDB())
+		require.NoError(b, err)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := tr.Get(crypto.Keccak256(keys[i%numSlots][:])); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("flat", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, ok := h.Storage(hotContractAddr, roots.storage, crypto.Keccak256Hash(keys[i%numSlots][:])); !ok {
+				b.Fatal("slot not served")
+			}
+		}
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ava-labs/coreth/predicate"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -27,7 +28,7 @@ func CheckPredicates(rules params.Rules, predicateContext *precompileconfig.Pred
 		return nil, err
 	}
 	if tx.Gas() < intrinsicGas {
-		return nil, fmt.Errorf("%w for predicate verification (%d) < intrinsic gas (%d)", ErrIntrinsicGas, tx.Gas(), intrinsicGas)
+		return nil, fmt.Errorf("%w for predicate verification (%d) < intrinsic gas (%d)", vmerrs.ErrIntrinsicGas, tx.Gas(), intrinsicGas)
 	}
 
 	predicateResults := make(map[common.Address][]byte)
@@ -0,0 +1,105 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadHotContractStorage retrieves the flat copy of the storage slot [slotHash]
+// of the hot contract [address], in the same format as the storage trie value.
+func ReadHotContractStorage(db ethdb.KeyValueReader, address common.Address, slotHash common.Hash) []byte {
+	data, _ := db.Get(hotContractStorageKey(address, slotHash))
+	return data
+}
+
+// WriteHotContractStorage stores the flat copy of the storage slot [slotHash]
+// of the hot contract [address].
+func WriteHotContractStorage(db ethdb.KeyValueWriter, address common.Address, slotHash common.Hash, value []byte) {
+	if err := db.Put(hotContractStorageKey(address, slotHash), value); err != nil {
+		log.Crit("Failed to store hot contract storage slot", "err", err)
+	}
+}
+
+// DeleteHotContractStorage removes the flat copy of the storage slot
+// [slotHash] of the hot contract [address].
+func DeleteHotContractStorage(db ethdb.KeyValueWriter, address common.Address, slotHash common.Hash) {
+	if err := db.Delete(hotContractStorageKey(address, slotHash)); err != nil {
+		log.Crit("Failed to delete hot contract storage slot", "err", err)
+	}
+}
+
+// ClearHotContractStorage removes the flat copy of all storage slots of the hot
+// contract [address].
+func ClearHotContractStorage(db ethdb.KeyValueStore, address common.Address) error {
+	prefix := make([]byte, len(hotContractStoragePrefix)+common.AddressLength)
+	copy(prefix, hotContractStoragePrefix)
+	copy(prefix[len(hotContractStoragePrefix):], address[:])
+	return ClearPrefix(db, prefix, hotContractStorageKeyLength)
+}
+
+// ReadHotContractRoots retrieves the state root and storage root the flat
+// storage copy of the hot contract [address] is consistent with. Returns false
+// if the copy is missing or incomplete.
+func ReadHotContractRoots(db ethdb.KeyValueReader, address common.Address) (common.Hash, common.Hash, bool) {
+	data, _ := db.Get(hotContractRootsKey(address))
+	if len(data) != 2*common.HashLength {
+		return common.Hash{}, common.Hash{}, false
+	}
+	return common.BytesToHash(data[:common.HashLength]), common.BytesToHash(data[common.HashLength:]), true
+}
+
+// WriteHotContractRoots stores the state root and storage root the flat
+// storage copy of the hot contract [address] is consistent with.
+func WriteHotContractRoots(db ethdb.KeyValueWriter, address common.Address, stateRoot common.Hash, storageRoot common.Hash) {
+	data := make([]byte, 0, 2*common.HashLength)
+	data = append(data, stateRoot[:]...)
+	data = append(data, storageRoot[:]...)
+	if err := db.Put(hotContractRootsKey(address), data); err != nil {
+		log.Crit("Failed to store hot contract roots", "err", err)
+	}
+}
+
+// DeleteHotContractRoots removes the roots of the flat storage copy of the hot
+// contract [address], marking the copy as unusable. This must be done before
+// the copy is modified so a crash or failure causes it to be rebuilt.
+func DeleteHotContractRoots(db ethdb.KeyValueWriter, address common.Address) {
+	if err := db.Delete(hotContractRootsKey(address)); err != nil {
+		log.Crit("Failed to delete hot contract roots", "err", err)
+	}
+}
+
+// NewHotContractRootsIterator returns a KeyLength iterator over the roots of
+// all hot contract storage copies. It is the caller's responsibility to unpack
+// the key with UnpackHotContractRootsKey and call Release on the returned
+// iterator.
+func NewHotContractRootsIterator(db ethdb.Iteratee) ethdb.Iterator {
+	return NewKeyLengthIterator(
+		db.NewIterator(hotContractRootsPrefix, nil),
+		hotContractRootsKeyLength,
+	)
+}
+
+// UnpackHotContractRootsKey returns the address of the hot contract from a key
+// returned by NewHotContractRootsIterator.
+func UnpackHotContractRootsKey(key []byte) common.Address {
+	return common.BytesToAddress(key[len(hotContractRootsPrefix):])
+}
+
+func hotContractStorageKey(address common.Address, slotHash common.Hash) []byte {
+	key := make([]byte, hotContractStorageKeyLength)
+	copy(key, hotContractStoragePrefix)
+	copy(key[len(hotContractStoragePrefix):], address[:])
+	copy(key[len(hotContractStoragePrefix)+common.AddressLength:], slotHash[:])
+	return key
+}
+
+func hotContractRootsKey(address common.Address) []byte {
+	key := make([]byte, hotContractRootsKeyLength)
+	copy(key, hotContractRootsPrefix)
+	copy(key[len(hotContractRootsPrefix):], address[:])
+	return key
+}
@@ -124,6 +124,12 @@ var (
 	// State sync metadata
 	syncPerformedPrefix    = []byte("sync_performed")
 	syncPerformedKeyLength = len(syncPerformedPrefix) + wrappers.LongLen // prefix + block number as uint64
+
+	// State flattening for hot contracts
+	hotContractStoragePrefix    = []byte("hot_contract_storage") // hotContractStoragePrefix + address + slot hash -> storage trie value
+	hotContractRootsPrefix      = []byte("hot_contract_roots")   // hotContractRootsPrefix + address -> state root + storage root
+	hotContractStorageKeyLength = len(hotContractStoragePrefix) + common.AddressLength + common.HashLength
+	hotContractRootsKeyLength   = len(hotContractRootsPrefix) + common.AddressLength
 )
 
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
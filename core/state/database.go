@@ -80,6 +80,15 @@ type Database interface {
 	TrieDB() *trie.Database
 }
 
+// FlatStorageReader serves storage slots of contracts from flat copies of their
+// storage, avoiding storage trie traversal.
+type FlatStorageReader interface {
+	// Storage returns the value of the slot [slotHash] in the storage of
+	// [address], in the same format as the storage trie value. It returns false
+	// if there is no flat copy of the storage trie with root [storageRoot].
+	Storage(address common.Address, storageRoot common.Hash, slotHash common.Hash) ([]byte, bool)
+}
+
 // Trie is a Ethereum Merkle Patricia trie.
 type Trie interface {
 	// GetKey returns the sha3 preimage of a hashed key that was previously used
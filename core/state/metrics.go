@@ -44,4 +44,10 @@ var (
 	slotDeletionCount    = metrics.NewRegisteredMeter("state/delete/storage/slot", nil)
 	slotDeletionSize     = metrics.NewRegisteredMeter("state/delete/storage/size", nil)
 	slotDeletionSkip     = metrics.NewRegisteredGauge("state/delete/storage/skip", nil)
+
+	trieMissingPrunedMeter           = metrics.NewRegisteredMeter("state/trie/missing/pruned", nil)
+	trieMissingSnapshotMismatchMeter = metrics.NewRegisteredMeter("state/trie/missing/snapshotmismatch", nil)
+	trieMissingCorruptionMeter       = metrics.NewRegisteredMeter("state/trie/missing/corruption", nil)
+	trieHealedMeter                  = metrics.NewRegisteredMeter("state/trie/heal/success", nil)
+	trieHealFailedMeter              = metrics.NewRegisteredMeter("state/trie/heal/failure", nil)
 )
@@ -0,0 +1,207 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Classes of the failures to resolve trie nodes, reported in [TrieNodeError].
+const (
+	// MissingNodePruned is a missing trie root while the state of old blocks
+	// is pruned, which is expected when reading historical state.
+	MissingNodePruned = "pruned"
+	// MissingNodeSnapshotMismatch is a missing trie node on the path to a
+	// value the snapshot has, so the trie and the snapshot are inconsistent.
+	MissingNodeSnapshotMismatch = "snapshot mismatch"
+	// MissingNodeCorruption is any other missing trie node.
+	MissingNodeCorruption = "corruption"
+)
+
+var errHealedNodeHashMismatch = errors.New("healed trie node does not match its hash")
+
+// TrieNodeError is returned by a StateDB when a trie node it needs to read
+// state is missing from the database. It wraps the trie.MissingNodeError with
+// the context of the read, to tell pruning from corruption.
+type TrieNodeError struct {
+	// Address is the account read, or whose storage is read.
+	Address common.Address
+	// Storage is true if the node is missing from the storage trie of
+	// [Address], and false if it is missing from the account trie.
+	Storage bool
+	// Depth is the length of the path of the missing node in nibbles.
+	Depth int
+	// InSnapshot is true if the snapshot has the value read.
+	InSnapshot bool
+	// Pruning is true if the state of old blocks is pruned.
+	Pruning bool
+	// Class is the class of the failure, one of the MissingNode constants.
+	Class string
+	// HealErr is the reason the node could not be healed, if healing was
+	// attempted.
+	HealErr error
+
+	err *trie.MissingNodeError
+}
+
+// Unwrap returns the trie.MissingNodeError.
+func (e *TrieNodeError) Unwrap() error {
+	return e.err
+}
+
+func (e *TrieNodeError) Error() string {
+	trieName := "account trie"
+	if e.Storage {
+		trieName = "storage trie of " + e.Address.Hex()
+	}
+	msg := fmt.Sprintf("%s (%s at depth %d, class %s, in snapshot %t, pruning %t)", e.err, trieName, e.Depth, e.Class, e.InSnapshot, e.Pruning)
+	if e.HealErr != nil {
+		msg += fmt.Sprintf(" (heal failed: %v)", e.HealErr)
+	}
+	return msg
+}
+
+// NodeHealer fetches trie nodes missing from the local database.
+type NodeHealer interface {
+	// HealNode returns the trie node with [hash] at [path] in the trie with
+	// [root]. [account] is the hash of the address owning the trie, or the
+	// zero hash for the account trie.
+	HealNode(ctx context.Context, root common.Hash, account common.Hash, path []byte, hash common.Hash) ([]byte, error)
+}
+
+// nodeHealing is the healer of a MissingNodeHandler and its time budget per
+// missing node.
+type nodeHealing struct {
+	healer  NodeHealer
+	timeout time.Duration
+}
+
+// MissingNodeHandler handles the trie nodes missing from the database of the
+// StateDBs it is set on: it classifies the failures, and heals the missing
+// nodes once a NodeHealer is set. Healed nodes are written with the hash
+// scheme, so it must only be used with a hash based trie database.
+type MissingNodeHandler struct {
+	diskdb  ethdb.KeyValueWriter
+	pruning bool
+	healing atomic.Pointer[nodeHealing]
+}
+
+// NewMissingNodeHandler returns a MissingNodeHandler writing healed nodes to
+// [diskdb]. [pruning] reports whether the state of old blocks is pruned.
+func NewMissingNodeHandler(diskdb ethdb.KeyValueWriter, pruning bool) *MissingNodeHandler {
+	return &MissingNodeHandler{
+		diskdb:  diskdb,
+		pruning: pruning,
+	}
+}
+
+// SetHealer sets [healer] to fetch missing nodes, spending at most [timeout]
+// on each of them. A nil [healer] disables healing.
+func (h *MissingNodeHandler) SetHealer(healer NodeHealer, timeout time.Duration) {
+	if healer == nil {
+		h.healing.Store(nil)
+		return
+	}
+	h.healing.Store(&nodeHealing{healer: healer, timeout: timeout})
+}
+
+// heal fetches the node missing from the trie with [root] according to [err]
+// and writes it to the database. It returns false if healing is disabled, and
+// otherwise the reason the node could not be healed, if any.
+func (h *MissingNodeHandler) heal(root common.Hash, err *trie.MissingNodeError) (bool, error) {
+	healing := h.healing.Load()
+	if healing == nil {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), healing.timeout)
+	defer cancel()
+
+	blob, healErr := healing.healer.HealNode(ctx, root, err.Owner, err.Path, err.NodeHash)
+	if healErr == nil && crypto.Keccak256Hash(blob) != err.NodeHash {
+		healErr = fmt.Errorf("%w: %x", errHealedNodeHashMismatch, err.NodeHash)
+	}
+	if healErr != nil {
+		trieHealFailedMeter.Mark(1)
+		return true, healErr
+	}
+	rawdb.WriteLegacyTrieNode(h.diskdb, err.NodeHash, blob)
+	trieHealedMeter.Mark(1)
+	log.Info("Healed missing trie node", "root", root, "owner", err.Owner, "path", common.Bytes2Hex(err.Path), "hash", err.NodeHash)
+	return true, nil
+}
+
+// readTrie runs [read], which reads [addr] from the account trie, or [slot]
+// from the storage trie with [root] of [addr] if [slot] is non-nil. If [read]
+// fails because of a missing trie node, the node is healed if a healer is set
+// and [read] is retried once. The returned error has the context of the
+// failure.
+func (s *StateDB) readTrie(addr common.Address, slot *common.Hash, root common.Hash, read func() error) error {
+	err := read()
+	var missing *trie.MissingNodeError
+	if !errors.As(err, &missing) {
+		return err
+	}
+
+	var healErr error
+	if s.missingNodes != nil {
+		var healed bool
+		healed, healErr = s.missingNodes.heal(root, missing)
+		if healed && healErr == nil {
+			if err = read(); !errors.As(err, &missing) {
+				return err
+			}
+		}
+	}
+	return s.newTrieNodeError(addr, slot, root, missing, healErr)
+}
+
+// newTrieNodeError returns a TrieNodeError for [missing], which failed a read
+// of [addr], or of [slot] of [addr] if [slot] is non-nil, from the trie with
+// [root].
+func (s *StateDB) newTrieNodeError(addr common.Address, slot *common.Hash, root common.Hash, missing *trie.MissingNodeError, healErr error) *TrieNodeError {
+	err := &TrieNodeError{
+		Address: addr,
+		Storage: slot != nil,
+		Depth:   len(missing.Path),
+		HealErr: healErr,
+		err:     missing,
+	}
+	if s.missingNodes != nil {
+		err.Pruning = s.missingNodes.pruning
+	}
+	if s.snap != nil {
+		addrHash := crypto.Keccak256Hash(addr.Bytes())
+		if slot == nil {
+			acc, snapErr := s.snap.Account(addrHash)
+			err.InSnapshot = snapErr == nil && acc != nil
+		} else {
+			enc, snapErr := s.snap.Storage(addrHash, crypto.Keccak256Hash(slot.Bytes()))
+			err.InSnapshot = snapErr == nil && len(enc) > 0
+		}
+	}
+
+	switch {
+	case err.Pruning && missing.NodeHash == root:
+		err.Class = MissingNodePruned
+		trieMissingPrunedMeter.Mark(1)
+	case err.InSnapshot:
+		err.Class = MissingNodeSnapshotMismatch
+		trieMissingSnapshotMismatchMeter.Mark(1)
+	default:
+		err.Class = MissingNodeCorruption
+		trieMissingCorruptionMeter.Mark(1)
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/require"
+)
+
+// testNodeHealer serves trie nodes from [nodes] and records its requests.
+type testNodeHealer struct {
+	nodes    map[common.Hash][]byte
+	requests []testHealRequest
+}
+
+type testHealRequest struct {
+	root, account common.Hash
+	path          []byte
+}
+
+func (h *testNodeHealer) HealNode(_ context.Context, root common.Hash, account common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	h.requests = append(h.requests, testHealRequest{root: root, account: account, path: common.CopyBytes(path)})
+	node, ok := h.nodes[hash]
+	if !ok {
+		return nil, errors.New("unknown node")
+	}
+	return node, nil
+}
+
+// deleteStorageNode commits the storage of [addr] with 100 slots, and deletes
+// a node of its storage trie from the database on the path to [slot], other
+// than the root. It returns the state root, and the path, hash and blob of the
+// deleted node.
+func deleteStorageNode(t *testing.T, diskdb ethdb.Database, addr common.Address, slot common.Hash) (common.Hash, []byte, common.Hash, []byte) {
+	require := require.New(t)
+
+	statedb, err := New(types.EmptyRootHash, NewDatabase(diskdb), nil)
+	require.NoError(err)
+	statedb.SetBalance(addr, big.NewInt(1))
+	for i := 0; i < 100; i++ {
+		statedb.SetState(addr, common.BigToHash(big.NewInt(int64(i))), common.Hash{0x1})
+	}
+	root, err := statedb.Commit(0, false, false)
+	require.NoError(err)
+	require.NoError(statedb.Database().TrieDB().Commit(root, false))
+
+	addrHash := crypto.Keccak256Hash(addr.Bytes())
+	tr, err := trie.New(trie.StorageTrieID(root, addrHash, statedb.GetStorageRoot(addr)), statedb.Database().TrieDB())
+	require.NoError(err)
+	it, err := tr.NodeIterator(nil)
+	require.NoError(err)
+
+	slotPath := keyNibbles(crypto.Keccak256(slot.Bytes()))
+	for it.Next(true) {
+		path := it.Path()
+		if len(path) == 0 || it.Hash() == (common.Hash{}) || !bytes.HasPrefix(slotPath, path) {
+			continue
+		}
+		blob := common.CopyBytes(it.NodeBlob())
+		rawdb.DeleteLegacyTrieNode(diskdb, it.Hash())
+		return root, common.CopyBytes(path), it.Hash(), blob
+	}
+	require.NoError(it.Error())
+	t.Fatal("no node on the path to the slot")
+	return common.Hash{}, nil, common.Hash{}, nil
+}
+
+// keyNibbles returns the nibbles of [key].
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, 2*len(key))
+	for _, b := range key {
+		nibbles = append(nibbles, b>>4, b&0xf)
+	}
+	return nibbles
+}
+
+func TestMissingStorageNode(t *testing.T) {
+	addr := common.Address{0x1}
+	addrHash := crypto.Keccak256Hash(addr.Bytes())
+	slot := common.BigToHash(big.NewInt(42))
+
+	tests := map[string]struct {
+		nodes       func(hash common.Hash, blob []byte) map[common.Hash][]byte
+		heal        bool
+		wantHealErr error
+	}{
+		"no healer": {},
+		"healed": {
+			nodes: func(hash common.Hash, blob []byte) map[common.Hash][]byte {
+				return map[common.Hash][]byte{hash: blob}
+			},
+			heal: true,
+		},
+		"healed node does not match hash": {
+			nodes: func(hash common.Hash, _ []byte) map[common.Hash][]byte {
+				return map[common.Hash][]byte{hash: {0x1}}
+			},
+			wantHealErr: errHealedNodeHashMismatch,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			diskdb := rawdb.NewMemoryDatabase()
+			root, path, hash, blob := deleteStorageNode(t, diskdb, addr, slot)
+
+			statedb, err := New(root, NewDatabase(diskdb), nil)
+			require.NoError(err)
+			storageRoot := statedb.GetStorageRoot(addr)
+			handler := NewMissingNodeHandler(diskdb, true)
+			var healer *testNodeHealer
+			if test.nodes != nil {
+				healer = &testNodeHealer{nodes: test.nodes(hash, blob)}
+				handler.SetHealer(healer, time.Second)
+			}
+			statedb.SetMissingNodeHandler(handler)
+
+			value := statedb.GetState(addr, slot)
+			if healer != nil {
+				require.Equal([]testHealRequest{{root: storageRoot, account: addrHash, path: path}}, healer.requests)
+			}
+			if test.heal {
+				require.NoError(statedb.Error())
+				require.Equal(common.Hash{0x1}, value)
+				require.Equal(blob, rawdb.ReadLegacyTrieNode(diskdb, hash))
+				return
+			}
+
+			require.Equal(common.Hash{}, value)
+			err = statedb.Error()
+			var nodeErr *TrieNodeError
+			require.ErrorAs(err, &nodeErr)
+			require.Equal(addr, nodeErr.Address)
+			require.True(nodeErr.Storage)
+			require.Equal(len(path), nodeErr.Depth)
+			require.False(nodeErr.InSnapshot)
+			require.True(nodeErr.Pruning)
+			require.Equal(MissingNodeCorruption, nodeErr.Class)
+			require.ErrorIs(nodeErr.HealErr, test.wantHealErr)
+
+			var missingErr *trie.MissingNodeError
+			require.ErrorAs(err, &missingErr)
+			require.Equal(hash, missingErr.NodeHash)
+			require.Nil(rawdb.ReadLegacyTrieNode(diskdb, hash))
+		})
+	}
+}
@@ -204,6 +204,21 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 		err   error
 		value common.Hash
 	)
+	// Prefer a flat copy of the contract's storage if one is consistent with
+	// the object's storage root.
+	if s.db.flatStorage != nil {
+		if enc, ok := s.db.flatStorage.Storage(s.address, s.data.Root, crypto.Keccak256Hash(key.Bytes())); ok {
+			if len(enc) > 0 {
+				_, content, _, err := rlp.Split(enc)
+				if err != nil {
+					s.db.setError(err)
+				}
+				value.SetBytes(content)
+			}
+			s.originStorage[key] = value
+			return value
+		}
+	}
 	if s.db.snap != nil {
 		start := time.Now()
 		enc, err = s.db.snap.Storage(s.addrHash, crypto.Keccak256Hash(key.Bytes()))
@@ -221,12 +236,15 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 	// If the snapshot is unavailable or reading from it fails, load from the database.
 	if s.db.snap == nil || err != nil {
 		start := time.Now()
-		tr, err := s.getTrie()
-		if err != nil {
-			s.db.setError(err)
-			return common.Hash{}
-		}
-		val, err := tr.GetStorage(s.address, key.Bytes())
+		var val []byte
+		err := s.db.readTrie(s.address, &key, s.data.Root, func() error {
+			tr, err := s.getTrie()
+			if err != nil {
+				return err
+			}
+			val, err = tr.GetStorage(s.address, key.Bytes())
+			return err
+		})
 		if metrics.EnabledExpensive {
 			s.db.StorageReads += time.Since(start)
 		}
@@ -28,11 +28,13 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"sort"
 	"time"
 
+	"github.com/ava-labs/coreth/constants"
 	"github.com/ava-labs/coreth/core/rawdb"
 	"github.com/ava-labs/coreth/core/state/snapshot"
 	"github.com/ava-labs/coreth/core/types"
@@ -45,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
@@ -76,6 +79,13 @@ type StateDB struct {
 	hasher     crypto.KeccakState
 	snap       snapshot.Snapshot // Nil if snapshot is not available
 
+	// flatStorage serves the storage of some contracts without traversing
+	// their storage tries. Nil if not available.
+	flatStorage FlatStorageReader
+
+	// missingNodes classifies and heals the trie nodes missing from [db].
+	missingNodes *MissingNodeHandler
+
 	// originalRoot is the pre-state root, before any changes were made.
 	// It will be updated when the Commit is called.
 	originalRoot common.Hash
@@ -106,6 +116,13 @@ type StateDB struct {
 	// The refund counter, also used by state transitioning.
 	refund uint64
 
+	// totalSupply caches the sum of all account balances. It is nil if the
+	// supply has not been computed since the last balance change.
+	// totalSupplyAccounts is the number of accounts in the trie at
+	// [originalRoot] visited to compute it.
+	totalSupply         *big.Int
+	totalSupplyAccounts uint64
+
 	// The tx context and all occurred logs in the scope of transaction.
 	thash   common.Hash
 	txIndex int
@@ -124,6 +141,12 @@ type StateDB struct {
 	// Transient storage
 	transientStorage transientStorage
 
+	// trackLastModified is set if the block number at which each storage slot
+	// is modified by SSTORE should be recorded, as set in Prepare. blockNumber
+	// is the number of the block being processed.
+	trackLastModified bool
+	blockNumber       uint64
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -200,6 +223,18 @@ func NewWithSnapshot(root common.Hash, db Database, snap snapshot.Snapshot) (*St
 	return sdb, nil
 }
 
+// SetFlatStorageReader sets [reader] to serve storage reads of the contracts
+// it has flat storage copies for.
+func (s *StateDB) SetFlatStorageReader(reader FlatStorageReader) {
+	s.flatStorage = reader
+}
+
+// SetMissingNodeHandler sets [handler] to classify and heal the trie nodes
+// missing from the database when reading state.
+func (s *StateDB) SetMissingNodeHandler(handler *MissingNodeHandler) {
+	s.missingNodes = handler
+}
+
 // StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
 // state trie concurrently while the state is mutated so that when we reach the
 // commit phase, most of the needed data is already hot.
@@ -350,6 +385,83 @@ func (s *StateDB) GetBalanceMultiCoin(addr common.Address, coinID common.Hash) *
 	return new(big.Int).Set(common.Big0)
 }
 
+// GetTotalSupply returns the sum of the native balances of all accounts,
+// including changes which have not yet been committed, and the number of
+// accounts in the trie at the start of the block. Multicoin balances are not
+// included.
+//
+// Computing the supply requires iterating over the entire account trie, so
+// callers on the consensus path must charge for every account. The number of
+// accounts only depends on the state at the start of the block, so the charge
+// is the same on every node. If the trie holds more than [maxAccounts]
+// accounts, the iteration is stopped and false is returned.
+//
+// The result is cached until the next balance change.
+func (s *StateDB) GetTotalSupply(maxAccounts uint64) (*big.Int, uint64, bool) {
+	if s.totalSupply == nil {
+		supply, accounts, ok, err := s.computeTotalSupply(maxAccounts)
+		if err != nil {
+			s.setError(err)
+			return new(big.Int), 0, true
+		}
+		if !ok {
+			return nil, accounts, false
+		}
+		s.totalSupply = supply
+		s.totalSupplyAccounts = accounts
+	}
+	if s.totalSupplyAccounts > maxAccounts {
+		return nil, s.totalSupplyAccounts, false
+	}
+	return new(big.Int).Set(s.totalSupply), s.totalSupplyAccounts, true
+}
+
+// computeTotalSupply sums the balances of the live state objects and of the
+// accounts in the trie at [originalRoot] which have not been loaded. It stops
+// and returns false once more than [maxAccounts] accounts have been visited.
+func (s *StateDB) computeTotalSupply(maxAccounts uint64) (*big.Int, uint64, bool, error) {
+	var (
+		supply   = new(big.Int)
+		accounts uint64
+		live     = make(map[common.Hash]struct{}, len(s.stateObjects))
+	)
+	for _, obj := range s.stateObjects {
+		live[obj.addrHash] = struct{}{}
+		if !obj.deleted {
+			supply.Add(supply, obj.Balance())
+		}
+	}
+	tr, err := s.db.OpenTrie(s.originalRoot)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		// Loaded accounts are counted as well, so that the number of accounts
+		// does not depend on which accounts this node has loaded.
+		accounts++
+		if accounts > maxAccounts {
+			return nil, accounts, false, nil
+		}
+		if _, ok := live[common.BytesToHash(it.Key)]; ok {
+			continue
+		}
+		var data types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return nil, 0, false, err
+		}
+		supply.Add(supply, data.Balance)
+	}
+	if it.Err != nil {
+		return nil, 0, false, it.Err
+	}
+	return supply, accounts, true, nil
+}
+
 // GetNonce retrieves the nonce from the given address or 0 if object not found
 func (s *StateDB) GetNonce(addr common.Address) uint64 {
 	stateObject := s.getStateObject(addr)
@@ -428,6 +540,86 @@ func (s *StateDB) GetCommittedStateAP1(addr common.Address, hash common.Hash) co
 	return common.Hash{}
 }
 
+// ForEachStorage calls [cb] with each non-empty storage slot of [addr], in
+// ascending key order, until [cb] returns false. Slots written in the current
+// block are visited with their latest value. Multicoin balances, which are
+// kept in the same storage, are skipped.
+//
+// Keys of slots read from the storage trie are recovered from their preimages,
+// which are local to the node and are not recorded by every node, and the
+// iteration is not metered. ForEachStorage must therefore not be used while
+// processing blocks. It is intended for tests and offline tooling. A missing
+// preimage is recorded as a database error.
+func (s *StateDB) ForEachStorage(addr common.Address, cb func(key, value common.Hash) bool) {
+	stateObject := s.getStateObject(addr)
+	if stateObject == nil {
+		return
+	}
+	storage := make(map[common.Hash]common.Hash)
+	// Dirty slots take precedence over pending ones, which take precedence
+	// over the trie.
+	for key, value := range stateObject.pendingStorage {
+		storage[key] = value
+	}
+	for key, value := range stateObject.dirtyStorage {
+		storage[key] = value
+	}
+	// The storage of accounts destructed in this block has been cleared.
+	if _, destructed := s.stateObjectsDestruct[addr]; !destructed && stateObject.data.Root != types.EmptyRootHash {
+		if err := s.readTrieStorage(addr, stateObject, storage); err != nil {
+			s.setError(err)
+			return
+		}
+	}
+
+	keys := make([]common.Hash, 0, len(storage))
+	for key, value := range storage {
+		// Multicoin keys have the lowest bit of their first byte set.
+		if key[0]&0x01 != 0 || value == (common.Hash{}) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+	for _, key := range keys {
+		if !cb(key, storage[key]) {
+			return
+		}
+	}
+}
+
+// readTrieStorage adds the slots of the storage trie of [stateObject] to
+// [storage], unless [storage] already holds a newer value of the slot.
+func (s *StateDB) readTrieStorage(addr common.Address, stateObject *stateObject, storage map[common.Hash]common.Hash) error {
+	tr, err := stateObject.getTrie()
+	if err != nil {
+		return err
+	}
+	nodeIt, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	it := trie.NewIterator(nodeIt)
+	for it.Next() {
+		preimage := tr.GetKey(it.Key)
+		if preimage == nil {
+			return fmt.Errorf("missing preimage of storage key %x of %s", it.Key, addr)
+		}
+		key := common.BytesToHash(preimage)
+		if _, ok := storage[key]; ok {
+			continue
+		}
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return err
+		}
+		storage[key] = common.BytesToHash(content)
+	}
+	return it.Err
+}
+
 // Database retrieves the low level database supporting the lower level trie ops.
 func (s *StateDB) Database() Database {
 	return s.db
@@ -450,6 +642,7 @@ func (s *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.AddBalance(amount)
+		s.invalidateTotalSupply(amount)
 	}
 }
 
@@ -458,6 +651,7 @@ func (s *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
+		s.invalidateTotalSupply(amount)
 	}
 }
 
@@ -465,6 +659,15 @@ func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetBalance(amount)
+		s.totalSupply = nil
+	}
+}
+
+// invalidateTotalSupply clears the cached total supply if a balance changed
+// by [amount].
+func (s *StateDB) invalidateTotalSupply(amount *big.Int) {
+	if amount.Sign() != 0 {
+		s.totalSupply = nil
 	}
 }
 
@@ -513,6 +716,63 @@ func (s *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SStore sets the storage slot [key] of [addr] to [value] on behalf of an
+// SSTORE, recording the block in which the slot was last modified if tracking
+// is active. Only SSTORE pays for the record, so the writes of precompiles,
+// which go through SetState, are not tracked.
+func (s *StateDB) SStore(addr common.Address, key, value common.Hash) {
+	stateObject := s.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		NormalizeStateKey(&key)
+		if s.trackLastModified && stateObject.GetState(key) != value {
+			s.setLastModifiedBlock(addr, key, value == (common.Hash{}))
+		}
+		stateObject.SetState(key, value)
+	}
+}
+
+// GetLastModifiedBlock returns the number of the block in which the storage
+// slot [slot] of [addr] was last modified, or 0 if it has not been modified
+// since tracking was activated or was last modified by clearing it.
+func (s *StateDB) GetLastModifiedBlock(addr common.Address, slot common.Hash) uint64 {
+	NormalizeStateKey(&slot)
+	tracker := s.getStateObject(constants.StorageLastModifiedAddr)
+	if tracker == nil {
+		return 0
+	}
+	return tracker.GetState(lastModifiedKey(addr, slot)).Big().Uint64()
+}
+
+// setLastModifiedBlock records the current block number as the block in which
+// the normalized storage slot [slot] of [addr] was last modified, or deletes
+// the record if the slot was [cleared], so that cleared slots do not leave a
+// permanent entry behind. The number is stored in the storage of
+// [constants.StorageLastModifiedAddr], so it is journaled along with the
+// modification itself. The SSTORE gas of the record is charged by the EVM.
+// It is only called by SStore.
+func (s *StateDB) setLastModifiedBlock(addr common.Address, slot common.Hash, cleared bool) {
+	if cleared {
+		if tracker := s.getStateObject(constants.StorageLastModifiedAddr); tracker != nil {
+			tracker.SetState(lastModifiedKey(addr, slot), common.Hash{})
+		}
+		return
+	}
+	tracker := s.GetOrNewStateObject(constants.StorageLastModifiedAddr)
+	if tracker.Nonce() == 0 {
+		// Prevent the tracker from being removed as an empty account.
+		tracker.SetNonce(1)
+	}
+	tracker.SetState(lastModifiedKey(addr, slot), common.BigToHash(new(big.Int).SetUint64(s.blockNumber)))
+}
+
+// lastModifiedKey returns the normalized key under which the block number at
+// which [slot] of [addr] was last modified is stored.
+func lastModifiedKey(addr common.Address, slot common.Hash) common.Hash {
+	key := crypto.Keccak256Hash(addr[:], slot[:])
+	NormalizeStateKey(&key)
+	return key
+}
+
 // SetStorage replaces the entire storage for the specified account with given
 // storage. This function should only be used for debugging and the mutations
 // must be discarded afterwards.
@@ -551,6 +811,7 @@ func (s *StateDB) SelfDestruct(addr common.Address) {
 	})
 	stateObject.markSelfdestructed()
 	stateObject.data.Balance = new(big.Int)
+	s.totalSupply = nil
 }
 
 func (s *StateDB) Selfdestruct6780(addr common.Address) {
@@ -689,8 +950,11 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	// If snapshot unavailable or reading from it failed, load from the database
 	if data == nil {
 		start := time.Now()
-		var err error
-		data, err = s.trie.GetAccount(addr)
+		err := s.readTrie(addr, nil, s.originalRoot, func() error {
+			var err error
+			data, err = s.trie.GetAccount(addr)
+			return err
+		})
 		if metrics.EnabledExpensive {
 			s.AccountReads += time.Since(start)
 		}
@@ -821,6 +1085,9 @@ func (s *StateDB) Copy() *StateDB {
 		// block mined by ourselves will cause gaps in the tree, and force the
 		// miner to operate trie-backed only.
 		snap: s.snap,
+
+		flatStorage:  s.flatStorage,
+		missingNodes: s.missingNodes,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
@@ -858,6 +1125,10 @@ func (s *StateDB) Copy() *StateDB {
 	for addr, value := range s.stateObjectsDestruct {
 		state.stateObjectsDestruct[addr] = value
 	}
+	if s.totalSupply != nil {
+		state.totalSupply = new(big.Int).Set(s.totalSupply)
+		state.totalSupplyAccounts = s.totalSupplyAccounts
+	}
 	// Deep copy the state changes made in the scope of block
 	// along with their original values.
 	state.accounts = copySet(s.accounts)
@@ -887,6 +1158,8 @@ func (s *StateDB) Copy() *StateDB {
 	state.accessList = s.accessList.Copy()
 	state.transientStorage = s.transientStorage.Copy()
 	state.predicateStorageSlots = copyPredicateStorageSlots(s.predicateStorageSlots)
+	state.trackLastModified = s.trackLastModified
+	state.blockNumber = s.blockNumber
 
 	// If there's a prefetcher running, make an inactive copy of it that can
 	// only access data but does not actively preload (since the user will not
@@ -919,6 +1192,9 @@ func (s *StateDB) RevertToSnapshot(revid int) {
 	// Replay the journal to undo changes and remove invalidated snapshots
 	s.journal.revert(s, snapshot)
 	s.validRevisions = s.validRevisions[:idx]
+
+	// The reverted changes may have included balance changes.
+	s.totalSupply = nil
 }
 
 // GetRefund returns the current value of the refund counter.
@@ -1398,6 +1674,8 @@ func (s *StateDB) commit(block uint64, deleteEmptyObjects bool, snaps *snapshot.
 			}
 		}
 		s.originalRoot = root
+		// The number of accounts is counted in the trie at [originalRoot].
+		s.totalSupply = nil
 		if metrics.EnabledExpensive {
 			s.TrieDBCommits += time.Since(start)
 		}
@@ -1428,6 +1706,9 @@ func (s *StateDB) commit(block uint64, deleteEmptyObjects bool, snaps *snapshot.
 // - Reset access list (Berlin/ApricotPhase2)
 // - Add coinbase to access list (EIP-3651/Durango)
 // - Reset transient storage (EIP-1153)
+//
+// Coreth:
+// - Track the block at which storage slots are modified, if activated
 func (s *StateDB) Prepare(rules params.Rules, sender, coinbase common.Address, dst *common.Address, precompiles []common.Address, list types.AccessList) {
 	if rules.IsApricotPhase2 {
 		// Clear out any leftover from previous executions
@@ -1456,6 +1737,9 @@ func (s *StateDB) Prepare(rules params.Rules, sender, coinbase common.Address, d
 	}
 	// Reset transient storage at the beginning of transaction execution
 	s.transientStorage = newTransientStorage()
+
+	s.trackLastModified = rules.IsStorageLastModified
+	s.blockNumber = rules.BlockNumber
 }
 
 // AddAddressToAccessList adds the given address to the access list
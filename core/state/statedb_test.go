@@ -40,9 +40,11 @@ import (
 	"testing"
 	"testing/quick"
 
+	"github.com/ava-labs/coreth/constants"
 	"github.com/ava-labs/coreth/core/rawdb"
 	"github.com/ava-labs/coreth/core/state/snapshot"
 	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/trie"
 	"github.com/ava-labs/coreth/trie/triedb/hashdb"
 	"github.com/ava-labs/coreth/trie/triedb/pathdb"
@@ -1352,3 +1354,282 @@ func TestDeleteStorage(t *testing.T) {
 		t.Fatalf("difference found:\nfast: %v\nslow: %v\n", fastRes, slowRes)
 	}
 }
+
+func TestGetTotalSupply(t *testing.T) {
+	state, _ := New(types.EmptyRootHash, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	checkSupply := func(want int64) {
+		t.Helper()
+		got, _, ok := state.GetTotalSupply(math.MaxUint64)
+		if !ok {
+			t.Fatal("total supply not computed")
+		}
+		if got.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("total supply mismatch: have %v, want %v", got, want)
+		}
+	}
+	var (
+		addr1 = common.HexToAddress("0x1")
+		addr2 = common.HexToAddress("0x2")
+		addr3 = common.HexToAddress("0x3")
+	)
+	checkSupply(0)
+
+	// Uncommitted balance changes are reflected.
+	state.SetBalance(addr1, big.NewInt(100))
+	state.AddBalance(addr2, big.NewInt(50))
+	checkSupply(150)
+
+	// Balances are read from the trie once committed.
+	root, err := state.Commit(0, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ = New(root, state.db, nil)
+	checkSupply(150)
+
+	// Transfers do not change the supply.
+	state.SubBalance(addr1, big.NewInt(30))
+	state.AddBalance(addr3, big.NewInt(30))
+	checkSupply(150)
+
+	// Minting and burning do.
+	state.AddBalance(addr3, big.NewInt(25))
+	checkSupply(175)
+	state.SubBalance(addr2, big.NewInt(50))
+	checkSupply(125)
+
+	// Reverted changes are not included.
+	snapshot := state.Snapshot()
+	state.AddBalance(addr1, big.NewInt(1000))
+	checkSupply(1125)
+	state.RevertToSnapshot(snapshot)
+	checkSupply(125)
+
+	// Self-destructing an account burns its balance.
+	state.SelfDestruct(addr1)
+	checkSupply(55)
+	state.Finalise(true)
+	checkSupply(55)
+
+	root, err = state.Commit(1, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSupply(55)
+	state, _ = New(root, state.db, nil)
+	checkSupply(55)
+
+	// The cached supply is returned as a copy.
+	supply, _, _ := state.GetTotalSupply(math.MaxUint64)
+	supply.SetInt64(0)
+	checkSupply(55)
+
+	// Only the remaining account of the committed trie is counted, not the
+	// account created since, and iteration stops once the limit is exceeded.
+	state.AddBalance(common.HexToAddress("0x4"), big.NewInt(1))
+	if _, accounts, ok := state.GetTotalSupply(1); !ok || accounts != 1 {
+		t.Fatalf("expected 1 account within limit, got %d (ok: %v)", accounts, ok)
+	}
+	if _, _, ok := state.GetTotalSupply(0); ok {
+		t.Fatal("expected total supply to exceed account limit")
+	}
+}
+
+func TestGetLastModifiedBlock(t *testing.T) {
+	var (
+		sdb   = NewDatabase(rawdb.NewMemoryDatabase())
+		addr  = common.Address{1}
+		slotA = common.Hash{0x10}
+		slotB = common.Hash{0x20}
+	)
+	prepare := func(state *StateDB, blockNumber uint64) {
+		rules := params.TestChainConfig.Rules(new(big.Int).SetUint64(blockNumber), 0)
+		rules.IsStorageLastModified = true
+		state.Prepare(rules, common.Address{}, common.Address{}, nil, nil, nil)
+	}
+
+	state, _ := New(types.EmptyRootHash, sdb, nil)
+	prepare(state, 5)
+	state.SStore(addr, slotA, common.Hash{1})
+	root, err := state.Commit(5, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, _ = New(root, sdb, nil)
+	prepare(state, 7)
+	state.SStore(addr, slotB, common.Hash{2})
+	// Writing the current value is not a modification.
+	state.SStore(addr, slotA, common.Hash{1})
+	if got := state.GetLastModifiedBlock(addr, slotA); got != 5 {
+		t.Fatalf("slot A: expected block 5, got %d", got)
+	}
+	if got := state.GetLastModifiedBlock(addr, slotB); got != 7 {
+		t.Fatalf("slot B: expected block 7, got %d", got)
+	}
+	if got := state.GetLastModifiedBlock(addr, common.Hash{0x30}); got != 0 {
+		t.Fatalf("unmodified slot: expected block 0, got %d", got)
+	}
+
+	// Reverting a write also reverts its block number.
+	snapshot := state.Snapshot()
+	state.SStore(addr, slotA, common.Hash{3})
+	if got := state.GetLastModifiedBlock(addr, slotA); got != 7 {
+		t.Fatalf("slot A: expected block 7, got %d", got)
+	}
+	state.RevertToSnapshot(snapshot)
+	if got := state.GetLastModifiedBlock(addr, slotA); got != 5 {
+		t.Fatalf("slot A after revert: expected block 5, got %d", got)
+	}
+
+	// Clearing a slot deletes its record.
+	state.SStore(addr, slotA, common.Hash{})
+	if got := state.GetLastModifiedBlock(addr, slotA); got != 0 {
+		t.Fatalf("cleared slot A: expected block 0, got %d", got)
+	}
+	tracker := state.getStateObject(constants.StorageLastModifiedAddr)
+	if value := tracker.GetState(lastModifiedKey(addr, slotA)); value != (common.Hash{}) {
+		t.Fatalf("cleared slot A: expected no record, got %x", value)
+	}
+
+	// Writes outside of SSTORE, such as those of precompiles, are not tracked.
+	state.SetState(addr, slotA, common.Hash{4})
+	if got := state.GetLastModifiedBlock(addr, slotA); got != 0 {
+		t.Fatalf("slot A set outside of SSTORE: expected block 0, got %d", got)
+	}
+}
+
+func TestGetLastModifiedBlockInactive(t *testing.T) {
+	sdb := NewDatabase(rawdb.NewMemoryDatabase())
+	addr := common.Address{1}
+
+	// Without tracking, the tracker account is not created, so the state root
+	// is unchanged.
+	expected, _ := New(types.EmptyRootHash, sdb, nil)
+	expected.SetState(addr, common.Hash{0x10}, common.Hash{1})
+
+	state, _ := New(types.EmptyRootHash, sdb, nil)
+	state.Prepare(params.TestChainConfig.Rules(big.NewInt(5), 0), common.Address{}, common.Address{}, nil, nil, nil)
+	state.SStore(addr, common.Hash{0x10}, common.Hash{1})
+	if got := state.GetLastModifiedBlock(addr, common.Hash{0x10}); got != 0 {
+		t.Fatalf("expected block 0, got %d", got)
+	}
+	if state.IntermediateRoot(false) != expected.IntermediateRoot(false) {
+		t.Fatal("state root changed without tracking")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	state, _ := New(types.EmptyRootHash, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	addr := common.Address{1}
+
+	if !state.Empty(addr) {
+		t.Fatal("expected non-existent account to be empty")
+	}
+	state.CreateAccount(addr)
+	if !state.Exist(addr) || !state.Empty(addr) {
+		t.Fatal("expected created account with zero balance and nonce to exist and be empty")
+	}
+	state.SetNonce(addr, 1)
+	if state.Empty(addr) {
+		t.Fatal("expected account with non-zero nonce not to be empty")
+	}
+}
+
+func TestHasSelfDestructedRecreated(t *testing.T) {
+	state, _ := New(types.EmptyRootHash, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	addr := common.Address{1}
+	state.SetNonce(addr, 1)
+
+	state.SelfDestruct(addr)
+	if !state.HasSelfDestructed(addr) {
+		t.Fatal("expected account to be self-destructed")
+	}
+
+	// Recreating the account in the same transaction clears the flag.
+	snapshot := state.Snapshot()
+	state.CreateAccount(addr)
+	if state.HasSelfDestructed(addr) {
+		t.Fatal("expected recreated account not to be self-destructed")
+	}
+
+	// Reverting the recreation restores it.
+	state.RevertToSnapshot(snapshot)
+	if !state.HasSelfDestructed(addr) {
+		t.Fatal("expected account to be self-destructed after revert")
+	}
+
+	// The flag does not outlive the transaction.
+	state.Finalise(true)
+	if state.HasSelfDestructed(addr) {
+		t.Fatal("expected flag to be cleared after finalise")
+	}
+}
+
+func TestForEachStorage(t *testing.T) {
+	tdb := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	state, _ := New(types.EmptyRootHash, tdb, nil)
+	addr := common.Address{1}
+
+	expected := make(map[common.Hash]common.Hash)
+	for i := 0; i < 100; i++ {
+		key := common.Hash{30: byte(i >> 8), 31: byte(i)}
+		value := common.Hash{31: byte(i + 1)}
+		state.SetState(addr, key, value)
+		expected[key] = value
+	}
+	// Multicoin balances share the storage but are not storage slots.
+	state.AddBalanceMultiCoin(addr, common.Hash{2}, big.NewInt(1))
+
+	collect := func(state *StateDB) map[common.Hash]common.Hash {
+		var (
+			got  = make(map[common.Hash]common.Hash)
+			prev *common.Hash
+		)
+		state.ForEachStorage(addr, func(key, value common.Hash) bool {
+			if prev != nil && bytes.Compare(prev[:], key[:]) >= 0 {
+				t.Fatalf("key %x visited after %x", key, *prev)
+			}
+			prev = &key
+			got[key] = value
+			return true
+		})
+		if err := state.Error(); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if got := collect(state); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("dirty storage: expected %d slots, got %d", len(expected), len(got))
+	}
+
+	// Iteration stops when the callback returns false.
+	var visits int
+	state.ForEachStorage(addr, func(common.Hash, common.Hash) bool {
+		visits++
+		return visits < 10
+	})
+	if visits != 10 {
+		t.Fatalf("expected iteration to stop after 10 slots, got %d", visits)
+	}
+
+	// Committed slots are read from the trie, and are overridden by later
+	// writes.
+	root, err := state.Commit(0, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ = New(root, tdb, nil)
+	if got := collect(state); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("committed storage: expected %d slots, got %d", len(expected), len(got))
+	}
+	deleted := common.Hash{31: 0}
+	state.SetState(addr, deleted, common.Hash{})
+	delete(expected, deleted)
+	updated := common.Hash{31: 1}
+	state.SetState(addr, updated, common.Hash{0xff})
+	expected[updated] = common.Hash{0xff}
+	if got := collect(state); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("modified storage: expected %d slots, got %d", len(expected), len(got))
+	}
+}
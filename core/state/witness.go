@@ -0,0 +1,124 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var _ Database = (*WitnessDatabase)(nil)
+
+// witnessTrie is implemented by the tries which track the nodes they load
+// from the database.
+type witnessTrie interface {
+	Witness() map[string]struct{}
+}
+
+// WitnessDatabase is a Database recording the trie nodes and the contract
+// codes read through it, which are sufficient to repeat the state transitions
+// made with it from a database containing only them.
+//
+// The trie nodes are tracked by the tries themselves, which forget them once
+// committed, so Witness must be called before committing the state.
+type WitnessDatabase struct {
+	Database
+
+	lock  sync.Mutex
+	tries []Trie
+	codes map[common.Hash][]byte
+}
+
+// NewWitnessDatabase returns a WitnessDatabase reading from [db].
+func NewWitnessDatabase(db Database) *WitnessDatabase {
+	return &WitnessDatabase{
+		Database: db,
+		codes:    make(map[common.Hash][]byte),
+	}
+}
+
+// OpenTrie implements Database.
+func (db *WitnessDatabase) OpenTrie(root common.Hash) (Trie, error) {
+	tr, err := db.Database.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	db.addTrie(tr)
+	return tr, nil
+}
+
+// OpenStorageTrie implements Database.
+func (db *WitnessDatabase) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	tr, err := db.Database.OpenStorageTrie(stateRoot, address, root, self)
+	if err != nil {
+		return nil, err
+	}
+	db.addTrie(tr)
+	return tr, nil
+}
+
+// CopyTrie implements Database.
+func (db *WitnessDatabase) CopyTrie(t Trie) Trie {
+	tr := db.Database.CopyTrie(t)
+	db.addTrie(tr)
+	return tr
+}
+
+// ContractCode implements Database.
+func (db *WitnessDatabase) ContractCode(addr common.Address, codeHash common.Hash) ([]byte, error) {
+	code, err := db.Database.ContractCode(addr, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.codes[codeHash] = code
+	return code, nil
+}
+
+// ContractCodeSize implements Database. The code is read, rather than only
+// its size, so that it is part of the witness.
+func (db *WitnessDatabase) ContractCodeSize(addr common.Address, codeHash common.Hash) (int, error) {
+	code, err := db.ContractCode(addr, codeHash)
+	return len(code), err
+}
+
+func (db *WitnessDatabase) addTrie(tr Trie) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.tries = append(db.tries, tr)
+}
+
+// Witness returns the rlp-encoded trie nodes and the contract codes read
+// through [db], each sorted and without duplicates.
+func (db *WitnessDatabase) Witness() (nodes [][]byte, codes [][]byte) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	witness := make(map[string]struct{})
+	for _, tr := range db.tries {
+		if tr, ok := tr.(witnessTrie); ok {
+			for node := range tr.Witness() {
+				witness[node] = struct{}{}
+			}
+		}
+	}
+	nodes = make([][]byte, 0, len(witness))
+	for node := range witness {
+		nodes = append(nodes, []byte(node))
+	}
+	slices.SortFunc(nodes, bytes.Compare)
+
+	codes = make([][]byte, 0, len(db.codes))
+	for _, code := range db.codes {
+		codes = append(codes, code)
+	}
+	slices.SortFunc(codes, bytes.Compare)
+	return nodes, codes
+}
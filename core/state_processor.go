@@ -38,6 +38,7 @@ import (
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/precompile/contract"
 	"github.com/ava-labs/coreth/precompile/modules"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
@@ -70,6 +71,20 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 // returns the amount of gas that was used in the process. If any of the
 // transactions failed to execute due to insufficient gas it will return an error.
 func (p *StateProcessor) Process(block *types.Block, parent *types.Header, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+	return ExecuteBlock(p.config, p.bc, p.engine, block, parent, statedb, cfg)
+}
+
+// BlockExecutionChain provides the ancestors of the blocks executed by
+// ExecuteBlock.
+type BlockExecutionChain interface {
+	ChainContext
+	consensus.ChainHeaderReader
+}
+
+// ExecuteBlock processes [block] on top of [statedb], the state of [parent],
+// as StateProcessor.Process does, looking up the ancestors of the block in
+// [chain]. It allows re-executing a block outside of a BlockChain.
+func ExecuteBlock(config *params.ChainConfig, chain BlockExecutionChain, engine consensus.Engine, block *types.Block, parent *types.Header, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
 	var (
 		receipts    types.Receipts
 		usedGas     = new(uint64)
@@ -80,18 +95,33 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 		gp          = new(GasPool).AddGas(block.GasLimit())
 	)
 
+	if err := config.ValidateAtBlockTimestamp(block.Time()); err != nil {
+		log.Error("invalid chain config processing block", "hash", block.Hash(), "number", block.NumberU64(), "timestamp", block.Time(), "err", err)
+		return nil, nil, 0, err
+	}
 	// Configure any upgrades that should go into effect during this block.
-	err := ApplyUpgrades(p.config, &parent.Time, block, statedb)
+	err := ApplyUpgrades(config, &parent.Time, block, statedb)
 	if err != nil {
 		log.Error("failed to configure precompiles processing block", "hash", block.Hash(), "number", block.NumberU64(), "timestamp", block.Time(), "err", err)
 		return nil, nil, 0, err
 	}
+	// Reserve the gas charged to the block for the precompile storage
+	// migrations applied above. It is added to the gas used by the block after
+	// the transactions, so that it is not included in the cumulative gas used
+	// of the receipts.
+	migrationGas := config.PrecompileStorageMigrationGas(&parent.Time, block.Time())
+	if err := gp.SubGas(migrationGas); err != nil {
+		return nil, nil, 0, fmt.Errorf("could not charge precompile storage migration gas: %w", err)
+	}
 
 	var (
-		context = NewEVMBlockContext(header, p.bc, nil)
-		vmenv   = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
-		signer  = types.MakeSigner(p.config, header.Number, header.Time)
+		rules   = config.Rules(header.Number, header.Time)
+		context = NewEVMBlockContext(header, chain, nil)
+		signer  = types.MakeSignerWithRules(rules)
 	)
+	context.Rules = &rules
+	context.BlockGasRemaining = gp.Gas
+	vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, config, cfg)
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
 	}
@@ -102,7 +132,7 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
 		statedb.SetTxContext(tx.Hash(), i)
-		receipt, err := applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		receipt, err := applyTransaction(msg, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
 		if err != nil {
 			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
@@ -110,14 +140,14 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	if err := p.engine.Finalize(p.bc, block, parent, statedb, receipts); err != nil {
+	if err := engine.Finalize(chain, block, parent, statedb, receipts); err != nil {
 		return nil, nil, 0, fmt.Errorf("engine finalization check failed: %w", err)
 	}
 
-	return receipts, allLogs, *usedGas, nil
+	return receipts, allLogs, *usedGas + migrationGas, nil
 }
 
-func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+func applyTransaction(msg *Message, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
 	// Create a new context to be used in the EVM environment.
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
@@ -129,11 +159,14 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	}
 
 	// Update the state with pending changes.
-	var root []byte
-	if config.IsByzantium(blockNumber) {
+	var (
+		root  []byte
+		rules = evm.Rules()
+	)
+	if rules.IsByzantium {
 		statedb.Finalise(true)
 	} else {
-		root = statedb.IntermediateRoot(config.IsEIP158(blockNumber)).Bytes()
+		root = statedb.IntermediateRoot(rules.IsEIP158).Bytes()
 	}
 	*usedGas += result.UsedGas
 
@@ -171,15 +204,24 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
+//
+// If [blockContext] specifies the block's Rules, they are used rather than
+// being resolved from [config] for every transaction.
 func ApplyTransaction(config *params.ChainConfig, bc ChainContext, blockContext vm.BlockContext, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, error) {
-	msg, err := TransactionToMessage(tx, types.MakeSigner(config, header.Number, header.Time), header.BaseFee)
+	var signer types.Signer
+	if blockContext.Rules != nil {
+		signer = types.MakeSignerWithRules(*blockContext.Rules)
+	} else {
+		signer = types.MakeSigner(config, header.Number, header.Time)
+	}
+	msg, err := TransactionToMessage(tx, signer, header.BaseFee)
 	if err != nil {
 		return nil, err
 	}
 	// Create a new context to be used in the EVM environment
 	txContext := NewEVMTxContext(msg)
 	vmenv := vm.NewEVM(blockContext, txContext, statedb, config, cfg)
-	return applyTransaction(msg, config, gp, statedb, header.Number, header.Hash(), tx, usedGas, vmenv)
+	return applyTransaction(msg, gp, statedb, header.Number, header.Hash(), tx, usedGas, vmenv)
 }
 
 // ProcessBeaconBlockRoot applies the EIP-4788 system call to the beacon block root
@@ -245,6 +287,11 @@ func ApplyPrecompileActivations(c *params.ChainConfig, parentTimestamp *uint64,
 				if err := module.Configure(c, activatingConfig, statedb, blockContext); err != nil {
 					return fmt.Errorf("could not configure precompile, name: %s, reason: %w", module.ConfigKey, err)
 				}
+				if activator, ok := module.Contract.(contract.Activator); ok {
+					if err := activator.OnActivate(statedb, blockContext); err != nil {
+						return fmt.Errorf("could not activate precompile, name: %s, reason: %w", module.ConfigKey, err)
+					}
+				}
 			}
 		}
 	}
@@ -257,6 +304,52 @@ func ApplyPrecompileActivations(c *params.ChainConfig, parentTimestamp *uint64,
 // This function is called:
 // - in block processing to update the state when processing a block.
 // - in the miner to apply the state upgrades when producing a block.
+//
+// Precompile storage migrations are applied after all precompile activations of the block. The gas
+// charged to the block for them is given by [params.ChainConfig.PrecompileStorageMigrationGas].
 func ApplyUpgrades(c *params.ChainConfig, parentTimestamp *uint64, blockContext contract.ConfigurationBlockContext, statedb *state.StateDB) error {
-	return ApplyPrecompileActivations(c, parentTimestamp, blockContext, statedb)
+	if err := ApplyPrecompileActivations(c, parentTimestamp, blockContext, statedb); err != nil {
+		return err
+	}
+	return ApplyPrecompileStorageMigrations(c, parentTimestamp, blockContext, statedb)
+}
+
+// ApplyPrecompileStorageMigrations runs the precompile storage migrations specified by the chain config which
+// activate in the block transition from [parentTimestamp] to the timestamp set in [blockContext], in the order
+// they are configured. The storage of a precompile is only migrated if the precompile was enabled in the parent
+// block and is not reconfigured in this block, since otherwise its storage is already in the new layout.
+func ApplyPrecompileStorageMigrations(c *params.ChainConfig, parentTimestamp *uint64, blockContext contract.ConfigurationBlockContext, statedb *state.StateDB) error {
+	if parentTimestamp == nil {
+		return nil
+	}
+	blockTimestamp := blockContext.Timestamp()
+	// versions tracks the storage version of each precompile, as several
+	// migrations of the same precompile may activate in one block.
+	versions := make(map[string]precompileconfig.ConfigVersion)
+	for _, migration := range c.GetActivatingPrecompileStorageMigrations(parentTimestamp, blockTimestamp) {
+		key := migration.PrecompileKey
+		from, ok := versions[key]
+		if !ok {
+			from = c.GetPrecompileStorageVersion(key, *parentTimestamp)
+		}
+		versions[key] = migration.Version
+
+		module, ok := modules.GetPrecompileModule(key)
+		if !ok {
+			return fmt.Errorf("could not migrate precompile storage, unknown precompile: %s", key)
+		}
+		migrator, ok := module.Configurator.(contract.StorageMigrator)
+		if !ok {
+			return fmt.Errorf("could not migrate precompile storage, name: %s, reason: storage migrations not supported", key)
+		}
+		if !c.IsPrecompileEnabled(module.Address, *parentTimestamp) ||
+			len(c.GetActivatingPrecompileConfigs(module.Address, parentTimestamp, blockTimestamp, c.PrecompileUpgrades)) > 0 {
+			continue
+		}
+		log.Info("Migrating precompile storage", "name", key, "from", from, "to", migration.Version)
+		if err := migrator.MigrateStorage(statedb, from, migration.Version); err != nil {
+			return fmt.Errorf("could not migrate precompile storage, name: %s, reason: %w", key, err)
+		}
+	}
+	return nil
 }
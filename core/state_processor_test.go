@@ -28,6 +28,7 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -35,14 +36,19 @@ import (
 	"github.com/ava-labs/coreth/consensus/dummy"
 	"github.com/ava-labs/coreth/consensus/misc/eip4844"
 	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/core/vm"
 	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/modules"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ava-labs/coreth/trie"
 	"github.com/ava-labs/coreth/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -403,3 +409,406 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// BenchmarkApplyTransactions measures applying a block of value transfers with
+// and without the block's rules resolved ahead of time.
+func BenchmarkApplyTransactions(b *testing.B) {
+	const numTxs = 500
+	var (
+		config    = params.TestChainConfig
+		key, _    = crypto.GenerateKey()
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.Address{1}
+		signer    = types.LatestSigner(config)
+		db        = state.NewDatabase(rawdb.NewMemoryDatabase())
+		header    = &types.Header{
+			Number:     big.NewInt(1),
+			Time:       1,
+			Difficulty: big.NewInt(1),
+			GasLimit:   numTxs * params.TxGas,
+			BaseFee:    big.NewInt(params.ApricotPhase3InitialBaseFee),
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, db, nil)
+	statedb.SetBalance(addr, new(big.Int).Lsh(common.Big1, 128))
+	root, err := statedb.Commit(0, false, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	txs := make([]*types.Transaction, numTxs)
+	for i := range txs {
+		txs[i], err = types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: common.Big0,
+			GasFeeCap: header.BaseFee,
+			Gas:       params.TxGas,
+			To:        &recipient,
+			Value:     common.Big1,
+		}), signer, key)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	rules := config.Rules(header.Number, header.Time)
+	for name, blockRules := range map[string]*params.Rules{
+		"resolve rules": nil,
+		"cached rules":  &rules,
+	} {
+		b.Run(name, func(b *testing.B) {
+			blockContext := NewEVMBlockContext(header, nil, &common.Address{})
+			blockContext.Rules = blockRules
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				statedb, _ := state.New(root, db, nil)
+				gp := new(GasPool).AddGas(header.GasLimit)
+				usedGas := uint64(0)
+				b.StartTimer()
+
+				for _, tx := range txs {
+					if _, err := ApplyTransaction(config, nil, blockContext, gp, statedb, header, tx, &usedGas, vm.Config{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+const activationCounterConfigKey = "activationCounterTest"
+
+var (
+	activationCounterAddress = common.HexToAddress("0x03000000000000000000000000000000000000aa")
+
+	// activationCounterKey holds the counter initialized by OnActivate, and
+	// activationsKey holds the number of times OnActivate has been called.
+	activationCounterKey = common.Hash{1}
+	activationsKey       = common.Hash{2}
+)
+
+func registerActivationCounterModule(t *testing.T) {
+	modules.RegisterModuleForTest(t, modules.Module{
+		ConfigKey:    activationCounterConfigKey,
+		Address:      activationCounterAddress,
+		Contract:     activationCounterPrecompile{},
+		Configurator: activationCounterConfigurator{},
+	})
+}
+
+type activationCounterConfig struct {
+	precompileconfig.Upgrade
+}
+
+func (*activationCounterConfig) Key() string { return activationCounterConfigKey }
+
+func (c *activationCounterConfig) Equal(other precompileconfig.Config) bool {
+	o, ok := other.(*activationCounterConfig)
+	return ok && c.Upgrade.Equal(&o.Upgrade)
+}
+
+func (*activationCounterConfig) Verify(precompileconfig.ChainConfig) error { return nil }
+
+type activationCounterConfigurator struct{}
+
+func (activationCounterConfigurator) MakeConfig() precompileconfig.Config {
+	return new(activationCounterConfig)
+}
+
+func (activationCounterConfigurator) Configure(precompileconfig.ChainConfig, precompileconfig.Config, contract.StateDB, contract.ConfigurationBlockContext) error {
+	return nil
+}
+
+// activationCounterPrecompile initializes a counter to 0 when it is activated.
+type activationCounterPrecompile struct{}
+
+func (activationCounterPrecompile) Run(_ contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	return nil, suppliedGas, nil
+}
+
+func (activationCounterPrecompile) OnActivate(stateDB contract.StateDB, _ contract.ConfigurationBlockContext) error {
+	stateDB.SetState(activationCounterAddress, activationCounterKey, common.Hash{})
+	activations := new(big.Int).SetBytes(stateDB.GetState(activationCounterAddress, activationsKey).Bytes())
+	stateDB.SetState(activationCounterAddress, activationsKey, common.BigToHash(activations.Add(activations, common.Big1)))
+	return nil
+}
+
+func TestApplyPrecompileActivationsOnActivate(t *testing.T) {
+	require := require.New(t)
+	registerActivationCounterModule(t)
+
+	config := *params.TestChainConfig
+	config.UpgradeConfig.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Config: &activationCounterConfig{Upgrade: precompileconfig.Upgrade{BlockTimestamp: utils.NewUint64(10)}}},
+	}
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	// Make the counter non-zero so that initializing it is observable.
+	statedb.SetState(activationCounterAddress, activationCounterKey, common.Hash{1})
+
+	activations := func() uint64 {
+		return statedb.GetState(activationCounterAddress, activationsKey).Big().Uint64()
+	}
+	applyUpgrades := func(parentTime, time uint64) {
+		header := &types.Header{Number: big.NewInt(int64(time)), Time: time}
+		require.NoError(ApplyUpgrades(&config, &parentTime, types.NewBlockWithHeader(header), statedb))
+	}
+
+	// OnActivate is not called before the activation timestamp.
+	applyUpgrades(0, 5)
+	require.Zero(activations())
+	require.Equal(common.Hash{1}, statedb.GetState(activationCounterAddress, activationCounterKey))
+
+	// It is called exactly once, in the block crossing the activation timestamp.
+	applyUpgrades(5, 10)
+	require.EqualValues(1, activations())
+	require.Equal(common.Hash{}, statedb.GetState(activationCounterAddress, activationCounterKey))
+	applyUpgrades(10, 15)
+	require.EqualValues(1, activations())
+}
+
+const storageMigrationConfigKey = "storageMigrationTest"
+
+var (
+	storageMigrationAddress = common.HexToAddress("0x03000000000000000000000000000000000000ab")
+
+	// In version 0, the fields a and b are stored in separate slots. In
+	// version 1, they are packed into a single slot. migrationsKey holds the
+	// number of times MigrateStorage has been called.
+	storageMigrationAKey      = common.Hash{1}
+	storageMigrationBKey      = common.Hash{2}
+	storageMigrationPackedKey = common.Hash{3}
+	migrationsKey             = common.Hash{4}
+)
+
+func registerStorageMigrationModule(t *testing.T) {
+	modules.RegisterModuleForTest(t, modules.Module{
+		ConfigKey:    storageMigrationConfigKey,
+		Address:      storageMigrationAddress,
+		Contract:     activationCounterPrecompile{},
+		Configurator: storageMigrationConfigurator{},
+	})
+}
+
+type storageMigrationConfig struct {
+	precompileconfig.Upgrade
+}
+
+func (*storageMigrationConfig) Key() string { return storageMigrationConfigKey }
+
+func (c *storageMigrationConfig) Equal(other precompileconfig.Config) bool {
+	o, ok := other.(*storageMigrationConfig)
+	return ok && c.Upgrade.Equal(&o.Upgrade)
+}
+
+func (*storageMigrationConfig) Verify(precompileconfig.ChainConfig) error { return nil }
+
+// storageMigrationConfigurator writes the fields a = 1 and b = 2 in the
+// storage layout of the version active when the precompile is configured.
+type storageMigrationConfigurator struct{}
+
+func (storageMigrationConfigurator) MakeConfig() precompileconfig.Config {
+	return new(storageMigrationConfig)
+}
+
+func (storageMigrationConfigurator) Configure(chainConfig precompileconfig.ChainConfig, _ precompileconfig.Config, state contract.StateDB, blockContext contract.ConfigurationBlockContext) error {
+	versions, ok := chainConfig.(precompileconfig.StorageVersions)
+	if !ok || versions.GetPrecompileStorageVersion(storageMigrationConfigKey, blockContext.Timestamp()) == 0 {
+		state.SetState(storageMigrationAddress, storageMigrationAKey, common.BigToHash(big.NewInt(1)))
+		state.SetState(storageMigrationAddress, storageMigrationBKey, common.BigToHash(big.NewInt(2)))
+		return nil
+	}
+	state.SetState(storageMigrationAddress, storageMigrationPackedKey, packStorageMigrationFields(1, 2))
+	return nil
+}
+
+func (storageMigrationConfigurator) MigrateStorage(state contract.StateDB, from, to precompileconfig.ConfigVersion) error {
+	if from != 0 || to != 1 {
+		return fmt.Errorf("unexpected migration from %d to %d", from, to)
+	}
+	a := state.GetState(storageMigrationAddress, storageMigrationAKey).Big().Uint64()
+	b := state.GetState(storageMigrationAddress, storageMigrationBKey).Big().Uint64()
+	state.SetState(storageMigrationAddress, storageMigrationAKey, common.Hash{})
+	state.SetState(storageMigrationAddress, storageMigrationBKey, common.Hash{})
+	state.SetState(storageMigrationAddress, storageMigrationPackedKey, packStorageMigrationFields(a, b))
+
+	migrations := state.GetState(storageMigrationAddress, migrationsKey).Big()
+	state.SetState(storageMigrationAddress, migrationsKey, common.BigToHash(migrations.Add(migrations, common.Big1)))
+	return nil
+}
+
+func packStorageMigrationFields(a, b uint64) common.Hash {
+	packed := new(big.Int).Lsh(new(big.Int).SetUint64(a), 64)
+	return common.BigToHash(packed.Or(packed, new(big.Int).SetUint64(b)))
+}
+
+func TestApplyPrecompileStorageMigrations(t *testing.T) {
+	registerStorageMigrationModule(t)
+
+	config := *params.TestChainConfig
+	config.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			{Config: &storageMigrationConfig{Upgrade: precompileconfig.Upgrade{BlockTimestamp: utils.NewUint64(10)}}},
+		},
+		PrecompileStorageMigrations: []params.PrecompileStorageMigration{
+			{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(20), Version: 1},
+		},
+	}
+	require.NoError(t, config.Verify())
+
+	// applyBlocks applies the upgrades of blocks with the given timestamps
+	// to a new state, and returns the state.
+	applyBlocks := func(t *testing.T, times ...uint64) *state.StateDB {
+		statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		require.NoError(t, err)
+		parentTime := uint64(0)
+		for _, time := range times {
+			header := &types.Header{Number: big.NewInt(int64(time)), Time: time}
+			require.NoError(t, ApplyUpgrades(&config, &parentTime, types.NewBlockWithHeader(header), statedb))
+			parentTime = time
+		}
+		return statedb
+	}
+	migrations := func(statedb *state.StateDB) uint64 {
+		return statedb.GetState(storageMigrationAddress, migrationsKey).Big().Uint64()
+	}
+
+	t.Run("migrated at boundary", func(t *testing.T) {
+		require := require.New(t)
+
+		statedb := applyBlocks(t, 10, 15)
+		require.Zero(migrations(statedb))
+		require.Equal(common.BigToHash(big.NewInt(1)), statedb.GetState(storageMigrationAddress, storageMigrationAKey))
+
+		// The migration runs exactly once, in the first block at or after its
+		// timestamp.
+		statedb = applyBlocks(t, 10, 15, 20, 25)
+		require.EqualValues(1, migrations(statedb))
+		require.Equal(common.Hash{}, statedb.GetState(storageMigrationAddress, storageMigrationAKey))
+		require.Equal(common.Hash{}, statedb.GetState(storageMigrationAddress, storageMigrationBKey))
+		require.Equal(packStorageMigrationFields(1, 2), statedb.GetState(storageMigrationAddress, storageMigrationPackedKey))
+
+		// Re-executing the same blocks results in the same state.
+		require.Equal(statedb.IntermediateRoot(true), applyBlocks(t, 10, 15, 20, 25).IntermediateRoot(true))
+	})
+
+	t.Run("configured in migration block", func(t *testing.T) {
+		require := require.New(t)
+
+		// The precompile is configured with the new layout, so its storage is
+		// not migrated.
+		statedb := applyBlocks(t, 20)
+		require.Zero(migrations(statedb))
+		require.Equal(packStorageMigrationFields(1, 2), statedb.GetState(storageMigrationAddress, storageMigrationPackedKey))
+	})
+
+	t.Run("gas", func(t *testing.T) {
+		require := require.New(t)
+
+		require.Zero(config.PrecompileStorageMigrationGas(utils.NewUint64(10), 15))
+		require.Equal(params.PrecompileStorageMigrationGas, config.PrecompileStorageMigrationGas(utils.NewUint64(15), 20))
+		require.Zero(config.PrecompileStorageMigrationGas(utils.NewUint64(20), 25))
+	})
+}
+
+func TestPrecompileStorageMigrationGasNotInReceipts(t *testing.T) {
+	require := require.New(t)
+	registerStorageMigrationModule(t)
+
+	config := *params.TestChainConfig
+	config.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			{Config: &storageMigrationConfig{Upgrade: precompileconfig.Upgrade{BlockTimestamp: utils.NewUint64(10)}}},
+		},
+		PrecompileStorageMigrations: []params.PrecompileStorageMigration{
+			{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(20), Version: 1},
+		},
+	}
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		signer = types.LatestSigner(&config)
+		gspec  = &Genesis{
+			Config:   &config,
+			Alloc:    GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+			GasLimit: params.CortinaGasLimit,
+		}
+	)
+	// The migration activates in the second block, which has a single
+	// transfer.
+	_, blocks, _, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 2, 10, func(i int, gen *BlockGen) {
+		if i != 1 {
+			return
+		}
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     gen.TxNonce(addr),
+			To:        &common.Address{1},
+			Gas:       params.TxGas,
+			GasFeeCap: big.NewInt(params.GWei * 300),
+			GasTipCap: big.NewInt(0),
+		}), signer, key)
+		require.NoError(err)
+		gen.AddTx(tx)
+	})
+	require.NoError(err)
+	require.Equal(params.TxGas+params.PrecompileStorageMigrationGas, blocks[1].GasUsed())
+
+	// Processing the block charges the migration to the block.
+	blockchain, err := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, dummy.NewFaker(), vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer blockchain.Stop()
+	_, err = blockchain.InsertChain(blocks)
+	require.NoError(err)
+
+	// The gas used of the transaction does not include the migration.
+	receipts := blockchain.GetReceiptsByHash(blocks[1].Hash())
+	require.Len(receipts, 1)
+	require.Equal(params.TxGas, receipts[0].CumulativeGasUsed)
+	require.Equal(params.TxGas, receipts[0].GasUsed)
+}
+
+func TestVerifyPrecompileStorageMigrations(t *testing.T) {
+	registerActivationCounterModule(t)
+	registerStorageMigrationModule(t)
+
+	tests := map[string]struct {
+		migrations []params.PrecompileStorageMigration
+		err        string
+	}{
+		"unsupported precompile": {
+			migrations: []params.PrecompileStorageMigration{
+				{PrecompileKey: activationCounterConfigKey, BlockTimestamp: utils.NewUint64(1), Version: 1},
+			},
+			err: "does not support storage migrations",
+		},
+		"nil timestamp": {
+			migrations: []params.PrecompileStorageMigration{
+				{PrecompileKey: storageMigrationConfigKey, Version: 1},
+			},
+			err: "block timestamp cannot be nil",
+		},
+		"same timestamp": {
+			migrations: []params.PrecompileStorageMigration{
+				{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(1), Version: 1},
+				{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(1), Version: 2},
+			},
+			err: "<= previous timestamp",
+		},
+		"version not increasing": {
+			migrations: []params.PrecompileStorageMigration{
+				{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(1), Version: 2},
+				{PrecompileKey: storageMigrationConfigKey, BlockTimestamp: utils.NewUint64(2), Version: 2},
+			},
+			err: "<= previous version",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := *params.TestChainConfig
+			config.UpgradeConfig = params.UpgradeConfig{PrecompileStorageMigrations: test.migrations}
+			require.ErrorContains(t, config.Verify(), test.err)
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/core/vm"
 	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	ethCrypto "github.com/ethereum/go-ethereum/crypto"
@@ -263,3 +264,67 @@ func TestNativeAssetDirectEOACall(t *testing.T) {
 		})
 	}
 }
+
+func TestNativeAssetCallGasTableReexecution(t *testing.T) {
+	require := require.New(t)
+
+	gasTable := &params.NativeAssetCallGasTable{
+		Base:         5_000,
+		PerInputByte: 100,
+		NewAccount:   1_000,
+	}
+	newGenesis := func(gasTableTimestamp *uint64) *Genesis {
+		config := *params.TestApricotPhase5Config
+		config.NativeAssetCallGasTableTimestamp = gasTableTimestamp
+		config.NativeAssetCallGasTable = gasTable
+		return &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				testAddr: GenesisAccount{
+					Balance: big.NewInt(2000000000000000000), // 2 ether
+				},
+			},
+			GasLimit: params.ApricotPhase1GasLimit,
+		}
+	}
+	// The fixture block calls Native Asset Call directly from an EOA, sending
+	// no assets to an account which does not exist yet.
+	input := vm.PackNativeAssetCallInput(common.HexToAddress("0xaa"), common.HexToHash("0x01"), common.Big0, []byte{1, 2, 3, 4})
+	generate := func(gspec *Genesis) ([]*types.Block, []types.Receipts) {
+		_, blocks, receipts, err := GenerateChainWithGenesis(gspec, dummy.NewFaker(), 1, 10, func(_ int, b *BlockGen) {
+			b.AddTx(makeTx(0, vm.NativeAssetCallAddr, common.Big0, 100_000, big.NewInt(params.LaunchMinGasPrice), input))
+		})
+		require.NoError(err)
+		return blocks, receipts
+	}
+	reexecute := func(gspec *Genesis, blocks []*types.Block) error {
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), DefaultCacheConfig, gspec, dummy.NewFaker(), vm.Config{}, common.Hash{}, false)
+		require.NoError(err)
+		defer chain.Stop()
+		_, err = chain.InsertChain(blocks)
+		return err
+	}
+
+	legacyGenesis := newGenesis(nil)
+	intrinsicGas, err := IntrinsicGas(input, nil, false, legacyGenesis.Config.Rules(common.Big1, 10))
+	require.NoError(err)
+
+	fixture, receipts := generate(legacyGenesis)
+	require.Equal(intrinsicGas+params.AssetCallApricot+params.CallNewAccountGas, receipts[0][0].GasUsed)
+
+	// The fixture re-executes identically while the gas table is not active
+	// at its timestamp.
+	require.NoError(reexecute(legacyGenesis, fixture))
+	scheduledGenesis := newGenesis(utils.NewUint64(fixture[0].Time() + 1))
+	require.NoError(reexecute(scheduledGenesis, fixture))
+	_, scheduledReceipts := generate(scheduledGenesis)
+	require.Equal(receipts[0][0].GasUsed, scheduledReceipts[0][0].GasUsed)
+
+	// Once the gas table is active, the call is charged according to it, so
+	// the fixture is no longer valid.
+	activeGenesis := newGenesis(utils.NewUint64(fixture[0].Time()))
+	require.Error(reexecute(activeGenesis, fixture))
+	active, activeReceipts := generate(activeGenesis)
+	require.Equal(intrinsicGas+gasTable.Base+uint64(len(input))*gasTable.PerInputByte+gasTable.NewAccount, activeReceipts[0][0].GasUsed)
+	require.NoError(reexecute(activeGenesis, active))
+}
@@ -26,7 +26,10 @@
 
 package txpool
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrAlreadyKnown is returned if the transactions is already contained
@@ -64,4 +67,23 @@ var (
 	// ErrFutureReplacePending is returned if a future transaction replaces a pending
 	// one. Future transactions should only be able to replace other future transactions.
 	ErrFutureReplacePending = errors.New("future transaction tries to replace pending")
+
+	// ErrPredicateTooLarge is returned if the predicates in the access list of
+	// a transaction are larger than the pool accepts. This is not a consensus
+	// error making the transaction invalid, rather a DOS protection.
+	ErrPredicateTooLarge = errors.New("predicate too large")
 )
+
+// PredicateTooLargeError is an ErrPredicateTooLarge recording the total
+// [Size] of the predicates of a transaction and the [Limit] of the pool.
+type PredicateTooLargeError struct {
+	Size, Limit uint64
+}
+
+func (e *PredicateTooLargeError) Error() string {
+	return fmt.Sprintf("%s: size %d, limit %d", ErrPredicateTooLarge, e.Size, e.Limit)
+}
+
+func (*PredicateTooLargeError) Unwrap() error {
+	return ErrPredicateTooLarge
+}
@@ -41,6 +41,12 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// MaxPredicateSize is the maximum total size of the predicates in the access
+// list of a transaction accepted by the pool. It bounds the work needed to
+// compute the predicate gas of the transaction, before it is stored and
+// gossiped.
+const MaxPredicateSize = 64 * 1024
+
 // ValidationOptions define certain differences between transaction validation
 // across the different pools without having to duplicate those checks.
 type ValidationOptions struct {
@@ -113,12 +119,12 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	}
 	// Ensure the transaction has more gas than the bare minimum needed to cover
 	// the transaction metadata
-	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, opts.Config.Rules(head.Number, head.Time))
+	intrGas, err := ValidateIntrinsicGas(tx, head, opts.Config.Rules(head.Number, head.Time))
 	if err != nil {
 		return err
 	}
 	if txGas := tx.Gas(); txGas < intrGas {
-		return fmt.Errorf("%w: address %v tx gas (%v) < intrinsic gas (%v)", core.ErrIntrinsicGas, from.Hex(), tx.Gas(), intrGas)
+		return fmt.Errorf("%w: address %v tx gas (%v) < intrinsic gas (%v)", vmerrs.ErrIntrinsicGas, from.Hex(), tx.Gas(), intrGas)
 	}
 	// Ensure the gasprice is high enough to cover the requirement of the calling
 	// pool and/or block producer
@@ -183,6 +189,34 @@ func validateBlobSidecar(hashes []common.Hash, sidecar *types.BlobTxSidecar) err
 	return nil
 }
 
+// ValidateIntrinsicGas returns the intrinsic gas of [tx] under [rules],
+// including the gas charged for the predicates in its access list, computed
+// the same way as during block verification. It returns an error if the
+// predicates of [tx] exceed [MaxPredicateSize] bytes, or if its intrinsic gas
+// exceeds the gas limit of [head], as [tx] could then never be included in a
+// block.
+func ValidateIntrinsicGas(tx *types.Transaction, head *types.Header, rules params.Rules) (uint64, error) {
+	// Check the size of the predicates before computing their gas, which
+	// requires parsing them.
+	var predicateSize uint64
+	for _, tuple := range tx.AccessList() {
+		if rules.PredicaterExists(tuple.Address) {
+			predicateSize += uint64(len(tuple.StorageKeys)) * common.HashLength
+		}
+	}
+	if predicateSize > MaxPredicateSize {
+		return 0, &PredicateTooLargeError{Size: predicateSize, Limit: MaxPredicateSize}
+	}
+	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, rules)
+	if err != nil {
+		return 0, err
+	}
+	if head.GasLimit < intrGas {
+		return 0, fmt.Errorf("%w: intrinsic gas (%d) > current max gas (%d)", ErrGasLimit, intrGas, head.GasLimit)
+	}
+	return intrGas, nil
+}
+
 // ValidationOptionsWithState define certain differences between stateful transaction
 // validation across the different pools without having to duplicate those checks.
 type ValidationOptionsWithState struct {
@@ -231,13 +265,13 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 
 	next := opts.State.GetNonce(from)
 	if next > tx.Nonce() {
-		return fmt.Errorf("%w: next nonce %v, tx nonce %v", core.ErrNonceTooLow, next, tx.Nonce())
+		return vmerrs.NonceTooLowError{Addr: from, TxNonce: tx.Nonce(), StateNonce: next}
 	}
 	// Ensure the transaction doesn't produce a nonce gap in pools that do not
 	// support arbitrary orderings
 	if opts.FirstNonceGap != nil {
 		if gap := opts.FirstNonceGap(from); gap < tx.Nonce() {
-			return fmt.Errorf("%w: tx nonce %v, gapped nonce %v", core.ErrNonceTooHigh, tx.Nonce(), gap)
+			return fmt.Errorf("%w: tx nonce %v, gapped nonce %v", vmerrs.ErrNonceTooHigh, tx.Nonce(), gap)
 		}
 	}
 	// Ensure the transactor has enough funds to cover the transaction costs
@@ -246,7 +280,7 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 		cost    = tx.Cost()
 	)
 	if balance.Cmp(cost) < 0 {
-		return fmt.Errorf("%w: balance %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, balance, cost, new(big.Int).Sub(cost, balance))
+		return fmt.Errorf("%w: balance %v, tx cost %v, overshot %v", vmerrs.ErrInsufficientFunds, balance, cost, new(big.Int).Sub(cost, balance))
 	}
 	// Ensure the transactor has enough funds to cover for replacements or nonce
 	// expansions without overdrafts
@@ -255,12 +289,12 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 		bump := new(big.Int).Sub(cost, prev)
 		need := new(big.Int).Add(spent, bump)
 		if balance.Cmp(need) < 0 {
-			return fmt.Errorf("%w: balance %v, queued cost %v, tx bumped %v, overshot %v", core.ErrInsufficientFunds, balance, spent, bump, new(big.Int).Sub(need, balance))
+			return fmt.Errorf("%w: balance %v, queued cost %v, tx bumped %v, overshot %v", vmerrs.ErrInsufficientFunds, balance, spent, bump, new(big.Int).Sub(need, balance))
 		}
 	} else {
 		need := new(big.Int).Add(spent, cost)
 		if balance.Cmp(need) < 0 {
-			return fmt.Errorf("%w: balance %v, queued cost %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, balance, spent, cost, new(big.Int).Sub(need, balance))
+			return fmt.Errorf("%w: balance %v, queued cost %v, tx cost %v, overshot %v", vmerrs.ErrInsufficientFunds, balance, spent, cost, new(big.Int).Sub(need, balance))
 		}
 		// Transaction takes a new nonce value out of the pool. Ensure it doesn't
 		// overflow the number of permitted transactions from a single account
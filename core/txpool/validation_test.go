@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testPredicateGasPerByte = 100
+
+// testPredicater charges [testPredicateGasPerByte] per predicate byte.
+type testPredicater struct{}
+
+func (testPredicater) PredicateGas(predicateBytes []byte) (uint64, error) {
+	return testPredicateGasPerByte * uint64(len(predicateBytes)), nil
+}
+
+func (testPredicater) VerifyPredicate(*precompileconfig.PredicateContext, []byte) error {
+	return nil
+}
+
+func TestValidateIntrinsicGas(t *testing.T) {
+	var (
+		predicateAddr = common.Address{0x1}
+		otherAddr     = common.Address{0x2}
+	)
+	rules := params.TestChainConfig.Rules(common.Big0, 0)
+	rules.Predicaters = map[common.Address]precompileconfig.Predicater{
+		predicateAddr: testPredicater{},
+	}
+	newTx := func(predicateKeys int, otherKeys int) *types.Transaction {
+		return types.NewTx(&types.AccessListTx{
+			To:  &otherAddr,
+			Gas: 1_000_000,
+			AccessList: types.AccessList{
+				{Address: predicateAddr, StorageKeys: make([]common.Hash, predicateKeys)},
+				{Address: otherAddr, StorageKeys: make([]common.Hash, otherKeys)},
+			},
+		})
+	}
+	// The intrinsic gas of a transaction with two predicate keys.
+	includableGas := params.TxGas +
+		testPredicateGasPerByte*2*common.HashLength +
+		params.TxAccessListAddressGas
+	maxPredicateKeys := MaxPredicateSize / common.HashLength
+
+	tests := map[string]struct {
+		tx       *types.Transaction
+		gasLimit uint64
+		wantGas  uint64
+		wantErr  error
+	}{
+		"just under gas limit": {
+			tx:       newTx(2, 0),
+			gasLimit: includableGas,
+			wantGas:  includableGas,
+		},
+		"just over gas limit": {
+			tx:       newTx(2, 0),
+			gasLimit: includableGas - 1,
+			wantErr:  ErrGasLimit,
+		},
+		"max predicate size": {
+			tx:       newTx(maxPredicateKeys, 0),
+			gasLimit: 100_000_000,
+			wantGas:  params.TxGas + testPredicateGasPerByte*MaxPredicateSize + params.TxAccessListAddressGas,
+		},
+		"predicate too large": {
+			tx:       newTx(maxPredicateKeys+1, 0),
+			gasLimit: 100_000_000,
+			wantErr:  ErrPredicateTooLarge,
+		},
+		"non-predicate keys not counted": {
+			tx:       newTx(0, maxPredicateKeys+1),
+			gasLimit: 100_000_000,
+			wantGas:  params.TxGas + params.TxAccessListAddressGas + uint64(maxPredicateKeys+1)*params.TxAccessListStorageKeyGas,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			head := &types.Header{Number: big.NewInt(1), GasLimit: test.gasLimit}
+			gas, err := ValidateIntrinsicGas(test.tx, head, rules)
+			require.ErrorIs(err, test.wantErr)
+			require.Equal(test.wantGas, gas)
+
+			var sizeErr *PredicateTooLargeError
+			if errors.As(err, &sizeErr) {
+				require.Equal(uint64(MaxPredicateSize), sizeErr.Limit)
+				require.Equal(uint64(maxPredicateKeys+1)*common.HashLength, sizeErr.Size)
+			}
+		})
+	}
+}
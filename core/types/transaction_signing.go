@@ -64,6 +64,25 @@ func MakeSigner(config *params.ChainConfig, blockNumber *big.Int, blockTime uint
 	}
 }
 
+// MakeSignerWithRules returns the Signer MakeSigner would return for the block
+// [rules] were resolved for.
+func MakeSignerWithRules(rules params.Rules) Signer {
+	switch {
+	case rules.IsCancun:
+		return NewCancunSigner(rules.ChainID)
+	case rules.IsApricotPhase3:
+		return NewLondonSigner(rules.ChainID)
+	case rules.IsApricotPhase2:
+		return NewEIP2930Signer(rules.ChainID)
+	case rules.IsEIP155:
+		return NewEIP155Signer(rules.ChainID)
+	case rules.IsHomestead:
+		return HomesteadSigner{}
+	default:
+		return FrontierSigner{}
+	}
+}
+
 // LatestSigner returns the 'most permissive' Signer available for the given chain
 // configuration. Specifically, this enables support of all types of transactions
 // when their respective forks are scheduled to occur at any block number (or time)
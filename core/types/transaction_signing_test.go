@@ -31,6 +31,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ava-labs/coreth/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -149,3 +150,23 @@ func TestChainId(t *testing.T) {
 		t.Error("expected no error")
 	}
 }
+
+func TestMakeSignerWithRules(t *testing.T) {
+	for _, config := range []*params.ChainConfig{
+		params.TestLaunchConfig,
+		params.TestApricotPhase1Config,
+		params.TestApricotPhase2Config,
+		params.TestApricotPhase3Config,
+		params.TestChainConfig,
+	} {
+		var (
+			number = big.NewInt(1)
+			time   = uint64(1)
+			want   = MakeSigner(config, number, time)
+			have   = MakeSignerWithRules(config.Rules(number, time))
+		)
+		if !want.Equal(have) {
+			t.Errorf("signer mismatch: have %T, want %T", have, want)
+		}
+	}
+}
@@ -255,6 +255,29 @@ func init() {
 	}
 }
 
+// activePrecompiledContracts returns the native precompiled contracts enabled
+// by [rules].
+func activePrecompiledContracts(rules params.Rules) map[common.Address]contract.StatefulPrecompiledContract {
+	switch {
+	case rules.IsCancun:
+		return PrecompiledContractsCancun
+	case rules.IsBanff:
+		return PrecompiledContractsBanff
+	case rules.IsApricotPhase6:
+		return PrecompiledContractsApricotPhase6
+	case rules.IsApricotPhasePre6:
+		return PrecompiledContractsApricotPhasePre6
+	case rules.IsApricotPhase2:
+		return PrecompiledContractsApricotPhase2
+	case rules.IsIstanbul:
+		return PrecompiledContractsIstanbul
+	case rules.IsByzantium:
+		return PrecompiledContractsByzantium
+	default:
+		return PrecompiledContractsHomestead
+	}
+}
+
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	switch {
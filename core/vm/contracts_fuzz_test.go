@@ -27,8 +27,15 @@
 package vm
 
 import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
 	"testing"
 
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -52,3 +59,54 @@ func FuzzPrecompiledContracts(f *testing.F) {
 		}
 	})
 }
+
+// gasLimitPricedRun charges 1 gas per million gas of the block's gas limit,
+// plus 1, and returns the block's gas limit as a uint256.
+func gasLimitPricedRun(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	gasLimit := accessibleState.GetBlockContext().GasLimit()
+	remainingGas, err := contract.DeductGas(suppliedGas, gasLimit/1_000_000+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	return common.BigToHash(new(big.Int).SetUint64(gasLimit)).Bytes(), remainingGas, nil
+}
+
+func FuzzPrecompileGasLimit(f *testing.F) {
+	f.Add(uint64(1), uint64(1))
+	f.Add(params.CortinaGasLimit, uint64(100))
+	f.Add(uint64(math.MaxUint64), uint64(math.MaxUint64))
+	f.Add(uint64(math.MaxUint64), uint64(0))
+	f.Fuzz(func(t *testing.T, gasLimit uint64, suppliedGas uint64) {
+		if gasLimit == 0 {
+			return
+		}
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1), GasLimit: gasLimit}, TxContext{}, nil, params.TestChainConfig, Config{})
+		if have := evm.GetBlockContext().GasLimit(); have != gasLimit {
+			t.Fatalf("GasLimit() = %d, want %d", have, gasLimit)
+		}
+
+		ret, remainingGas, err := gasLimitPricedRun(evm, common.Address{}, common.Address{}, nil, suppliedGas, true)
+		cost := gasLimit/1_000_000 + 1
+		if suppliedGas < cost {
+			if !errors.Is(err, vmerrs.ErrOutOfGas) {
+				t.Fatalf("expected out of gas with %d gas supplied for cost %d, got %v", suppliedGas, cost, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if remainingGas != suppliedGas-cost {
+			t.Fatalf("remaining gas = %d, want %d", remainingGas, suppliedGas-cost)
+		}
+		if have := new(big.Int).SetBytes(ret); !have.IsUint64() || have.Uint64() != gasLimit {
+			t.Fatalf("returned gas limit %s, want %d", have, gasLimit)
+		}
+
+		// Running again against the same block gives the same result.
+		ret2, remainingGas2, err := gasLimitPricedRun(evm, common.Address{}, common.Address{}, nil, suppliedGas, true)
+		if err != nil || remainingGas2 != remainingGas || !bytes.Equal(ret, ret2) {
+			t.Fatalf("inconsistent result on second run: %x, %d, %v", ret2, remainingGas2, err)
+		}
+	})
+}
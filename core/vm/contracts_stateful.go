@@ -4,8 +4,13 @@
 package vm
 
 import (
+	"errors"
+
+	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 )
 
 // wrappedPrecompiledContract implements StatefulPrecompiledContract by wrapping stateless native precompiled contracts
@@ -25,6 +30,63 @@ func (w *wrappedPrecompiledContract) Run(accessibleState contract.AccessibleStat
 	return RunPrecompiledContract(w.p, input, suppliedGas)
 }
 
+// runPrecompile runs [p] at the current depth, scaling the gas it charges by
+// the gas multiplier configured for [addr].
+func (evm *EVM) runPrecompile(p contract.StatefulPrecompiledContract, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	// The gas used by the calls [p] makes through NativeAssetCall is tracked
+	// separately, as it is charged by the callees and is not scaled.
+	prevNestedCallGas := evm.precompileNestedCallGas
+	evm.precompileNestedCallGas = 0
+	defer func() { evm.precompileNestedCallGas = prevNestedCallGas }()
+
+	ret, remainingGas, err = evm.runPrecompileUnscaled(p, caller, addr, input, suppliedGas, readOnly)
+	multiplier := evm.chainConfig.GetPrecompileGasMultiplier(addr, evm.Context.Time)
+	if multiplier == params.PrecompileGasMultiplierDenominator || errors.Is(err, vmerrs.ErrOutOfGas) || remainingGas > suppliedGas {
+		return ret, remainingGas, err
+	}
+
+	// [p] is never supplied more than [suppliedGas], and only the gas it
+	// charges itself is scaled, rounding up so that a multiplier never
+	// reduces the cost of a call below its exact value.
+	usedGas := suppliedGas - remainingGas
+	nestedCallGas := min(evm.precompileNestedCallGas, usedGas)
+	scaledGas, overflow := math.SafeMul(usedGas-nestedCallGas, multiplier)
+	if !overflow {
+		scaledGas, overflow = math.SafeAdd(scaledGas, params.PrecompileGasMultiplierDenominator-1)
+	}
+	chargedGas := scaledGas/params.PrecompileGasMultiplierDenominator + nestedCallGas
+	if overflow || chargedGas > suppliedGas {
+		return nil, 0, vmerrs.ErrOutOfGas
+	}
+	return ret, suppliedGas - chargedGas, err
+}
+
+// runPrecompileUnscaled runs [p] at the current depth, reporting its
+// sub-operations to the tracer if both the tracer and [p] support it.
+func (evm *EVM) runPrecompileUnscaled(p contract.StatefulPrecompiledContract, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	// Make sure IsReadOnly reports the static context of the precompile call,
+	// as the interpreter does for contract calls.
+	if readOnly && !evm.interpreter.readOnly {
+		evm.interpreter.readOnly = true
+		defer func() { evm.interpreter.readOnly = false }()
+	}
+	// Precompiles may call other precompiles, for example through
+	// NativeAssetCall, so the caller is running again once [p] returns.
+	prevPrecompileAddr := evm.precompileAddr
+	evm.precompileAddr = addr
+	defer func() { evm.precompileAddr = prevPrecompileAddr }()
+	if tracer, ok := p.(contract.ContractTracer); ok {
+		if logger, ok := evm.Config.Tracer.(PrecompileOpLogger); ok {
+			depth := evm.depth + 1
+			traceOp := func(label string, gasUsed uint64) {
+				logger.CapturePrecompileOp(depth, label, gasUsed)
+			}
+			return tracer.RunWithTracing(evm, caller, addr, input, suppliedGas, readOnly, traceOp)
+		}
+	}
+	return RunStatefulPrecompiledContract(p, evm, caller, addr, input, suppliedGas, readOnly)
+}
+
 // RunStatefulPrecompiledContract confirms runs [precompile] with the specified parameters.
 func RunStatefulPrecompiledContract(precompile contract.StatefulPrecompiledContract, accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
 	return precompile.Run(accessibleState, caller, addr, input, suppliedGas, readOnly)
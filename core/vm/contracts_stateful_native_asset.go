@@ -60,12 +60,12 @@ func (b *nativeAssetBalance) Run(accessibleState contract.AccessibleState, calle
 
 	address, assetID, err := UnpackNativeAssetBalanceInput(input)
 	if err != nil {
-		return nil, remainingGas, vmerrs.ErrExecutionReverted
+		return nil, remainingGas, &vmerrs.RevertError{}
 	}
 
 	res, overflow := uint256.FromBig(accessibleState.GetStateDB().GetBalanceMultiCoin(address, assetID))
 	if overflow {
-		return nil, remainingGas, vmerrs.ErrExecutionReverted
+		return nil, remainingGas, &vmerrs.RevertError{}
 	}
 	return common.LeftPadBytes(res.Bytes(), 32), remainingGas, nil
 }
@@ -109,5 +109,5 @@ func (c *nativeAssetCall) Run(accessibleState contract.AccessibleState, caller c
 type deprecatedContract struct{}
 
 func (*deprecatedContract) Run(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	return nil, suppliedGas, vmerrs.ErrExecutionReverted
+	return nil, suppliedGas, &vmerrs.RevertError{}
 }
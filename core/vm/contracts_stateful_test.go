@@ -4,16 +4,25 @@
 package vm
 
 import (
+	"bytes"
+	"errors"
+	"maps"
 	"math/big"
 	"testing"
 
+	"github.com/ava-labs/coreth/consensus/misc/eip4844"
 	"github.com/ava-labs/coreth/core/rawdb"
 	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/contracts/warp"
+	"github.com/ava-labs/coreth/utils"
 	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrecompiledContractSpendsGas(t *testing.T) {
@@ -474,7 +483,7 @@ func TestStatefulPrecompile(t *testing.T) {
 			assert.Equal(t, test.expectedGasRemaining, gasRemaining, "unexpected gas remaining")
 
 			if test.expectedErr != nil {
-				assert.Equal(t, test.expectedErr, err, "expected error to match")
+				assert.ErrorIs(t, err, test.expectedErr, "expected error to match")
 				return
 			}
 			if assert.NoError(t, err, "EVM Call produced unexpected error") {
@@ -486,3 +495,1037 @@ func TestStatefulPrecompile(t *testing.T) {
 		})
 	}
 }
+
+// mintPrecompile credits the caller with the amount given as input and returns
+// the resulting total supply.
+type mintPrecompile struct{}
+
+func (mintPrecompile) Run(accessibleState contract.AccessibleState, caller common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	accessibleState.GetStateDB().AddBalance(caller, new(big.Int).SetBytes(input))
+	supply, remainingGas, err := accessibleState.GetTotalSupply(suppliedGas)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return common.BigToHash(supply).Bytes(), remainingGas, nil
+}
+
+func TestPrecompileGetTotalSupply(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	userAddr1 := common.BytesToAddress([]byte("user1"))
+	userAddr2 := common.BytesToAddress([]byte("user2"))
+	statedb.SetBalance(userAddr1, big.NewInt(100))
+	root, err := statedb.Commit(0, false, false)
+	require.NoError(err)
+	statedb, err = state.New(root, statedb.Database(), nil)
+	require.NoError(err)
+
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	supply, remainingGas, err := evm.GetTotalSupply(contract.TotalSupplyGasPerAccount)
+	require.NoError(err)
+	require.Equal(big.NewInt(100), supply)
+	require.Zero(remainingGas)
+
+	// Each account in the state at the start of the block is charged for.
+	ret, remainingGas, err := mintPrecompile{}.Run(evm, userAddr2, common.Address{}, big.NewInt(50).Bytes(), 10_000, false)
+	require.NoError(err)
+	require.Equal(big.NewInt(150), new(big.Int).SetBytes(ret))
+	require.Equal(10_000-contract.TotalSupplyGasPerAccount, remainingGas)
+
+	// Balance changes made outside of the precompile are also reflected.
+	statedb.SubBalance(userAddr1, big.NewInt(25))
+	supply, _, err = evm.GetTotalSupply(contract.TotalSupplyGasPerAccount)
+	require.NoError(err)
+	require.Equal(big.NewInt(125), supply)
+
+	// The state is not iterated without enough gas for every account.
+	_, _, err = evm.GetTotalSupply(contract.TotalSupplyGasPerAccount - 1)
+	require.ErrorIs(err, vmerrs.ErrOutOfGas)
+}
+
+// blockGasPrecompile returns the gas remaining in the block.
+type blockGasPrecompile struct{}
+
+func (blockGasPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	remaining := accessibleState.GetBlockContext().GetBlockGasRemaining()
+	return common.BigToHash(new(big.Int).SetUint64(remaining)).Bytes(), suppliedGas, nil
+}
+
+func TestPrecompileGetBlockGasRemaining(t *testing.T) {
+	tests := map[string]struct {
+		blockCtx  BlockContext
+		remaining uint64
+	}{
+		"gas pool": {
+			blockCtx: BlockContext{
+				GasLimit:          8_000_000,
+				BlockGasRemaining: func() uint64 { return 5_000_000 },
+			},
+			remaining: 5_000_000,
+		},
+		"no gas pool": {
+			blockCtx:  BlockContext{GasLimit: 8_000_000},
+			remaining: 8_000_000,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			test.blockCtx.BlockNumber = big.NewInt(0)
+			evm := NewEVM(test.blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+			ret, _, err := blockGasPrecompile{}.Run(evm, common.Address{}, common.Address{}, nil, 0, false)
+			require.NoError(err)
+			require.Equal(test.remaining, new(big.Int).SetBytes(ret).Uint64())
+		})
+	}
+}
+
+const timeLockDuration = 1000
+
+var errTimeLocked = errors.New("time locked")
+
+// timeLockPrecompile unlocks once the parent of the block is [timeLockDuration]
+// blocks past the lock height given as input.
+type timeLockPrecompile struct{}
+
+func (timeLockPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	lockHeight := new(big.Int).SetBytes(input).Uint64()
+	currentHeight := accessibleState.GetLatestAcceptedHeight()
+	if currentHeight < lockHeight || currentHeight-lockHeight < timeLockDuration {
+		return nil, suppliedGas, errTimeLocked
+	}
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileTimeLock(t *testing.T) {
+	tests := map[string]struct {
+		blockCtx    BlockContext
+		lockHeight  uint64
+		expectedErr error
+	}{
+		"locked": {
+			blockCtx:    BlockContext{BlockNumber: big.NewInt(2_000)},
+			lockHeight:  1_000,
+			expectedErr: errTimeLocked,
+		},
+		"unlocked": {
+			blockCtx:   BlockContext{BlockNumber: big.NewInt(2_001)},
+			lockHeight: 1_000,
+		},
+		"lock height after parent height": {
+			blockCtx:    BlockContext{BlockNumber: big.NewInt(2_001)},
+			lockHeight:  2_500,
+			expectedErr: errTimeLocked,
+		},
+		"genesis": {
+			blockCtx:    BlockContext{BlockNumber: big.NewInt(0)},
+			lockHeight:  0,
+			expectedErr: errTimeLocked,
+		},
+		"no block number": {
+			blockCtx:    BlockContext{},
+			lockHeight:  0,
+			expectedErr: errTimeLocked,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			evm := NewEVM(test.blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+			input := new(big.Int).SetUint64(test.lockHeight).Bytes()
+			_, _, err = timeLockPrecompile{}.Run(evm, common.Address{}, common.Address{}, input, 0, false)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
+func TestPrecompileBlockContextFees(t *testing.T) {
+	coinbase := common.HexToAddress("0x0100000000000000000000000000000000000abc")
+	tests := map[string]struct {
+		baseFee *big.Int
+	}{
+		"dynamic fees": {
+			baseFee: big.NewInt(25_000_000_000),
+		},
+		// Blocks prior to Apricot Phase 3 do not have a base fee.
+		"pre dynamic fees": {
+			baseFee: nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			blockCtx := BlockContext{
+				BlockNumber: big.NewInt(0),
+				BaseFee:     test.baseFee,
+				Coinbase:    coinbase,
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+			precompileCtx := evm.GetBlockContext()
+			require.Equal(coinbase, precompileCtx.Coinbase())
+			baseFee := precompileCtx.BaseFee()
+			require.Equal(test.baseFee, baseFee)
+			if baseFee == nil {
+				return
+			}
+			// Modifying the returned base fee must not modify the block.
+			baseFee.SetUint64(1)
+			require.Equal(test.baseFee, precompileCtx.BaseFee())
+		})
+	}
+}
+
+func TestPrecompileMinerFeeRecipient(t *testing.T) {
+	coinbase := common.Address{0xc}
+	feeRecipient := common.Address{0xf}
+	withFeeRecipient := *params.TestChainConfig
+	withFeeRecipient.MinerFeeRecipient = feeRecipient
+
+	tests := map[string]struct {
+		config   *params.ChainConfig
+		expected common.Address
+	}{
+		"fee recipient": {
+			config:   &withFeeRecipient,
+			expected: feeRecipient,
+		},
+		"no fee recipient": {
+			config:   params.TestChainConfig,
+			expected: coinbase,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			blockCtx := BlockContext{
+				BlockNumber: big.NewInt(0),
+				Coinbase:    coinbase,
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, test.config, Config{})
+
+			precompileCtx := evm.GetBlockContext()
+			require.Equal(coinbase, precompileCtx.Coinbase())
+			require.Equal(test.expected, precompileCtx.GetMinerFeeRecipient())
+		})
+	}
+}
+
+// helperAddr is the address at which helperDeployerPrecompile installs the
+// helper bytecode given as input.
+var helperAddr = common.HexToAddress("0x0100000000000000000000000000000000000bbb")
+
+// helperDeployerPrecompile installs the helper bytecode given as input unless
+// code is already deployed, and returns the hash of the deployed code.
+type helperDeployerPrecompile struct{}
+
+func (helperDeployerPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	stateDB := accessibleState.GetStateDB()
+	if len(stateDB.GetCode(helperAddr)) == 0 {
+		var err error
+		suppliedGas, err = contract.SetCodeWithGas(accessibleState, helperAddr, input, suppliedGas)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return stateDB.GetCodeHash(helperAddr).Bytes(), suppliedGas, nil
+}
+
+func TestPrecompileDeploysHelper(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0), CanTransfer: CanTransfer, Transfer: Transfer}, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	// PUSH1 0x2a PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+	helperCode := common.Hex2Bytes("602a60005260206000f3")
+	deployCost, err := evm.GetGasSchedule().SetCodeCost(len(helperCode))
+	require.NoError(err)
+
+	ret, remainingGas, err := helperDeployerPrecompile{}.Run(evm, common.Address{}, common.Address{}, helperCode, deployCost, false)
+	require.NoError(err)
+	require.Zero(remainingGas)
+	require.Equal(crypto.Keccak256(helperCode), ret)
+	require.Equal(helperCode, statedb.GetCode(helperAddr))
+
+	// The helper is only deployed once.
+	ret, remainingGas, err = helperDeployerPrecompile{}.Run(evm, common.Address{}, common.Address{}, []byte{0xfe}, 0, false)
+	require.NoError(err)
+	require.Zero(remainingGas)
+	require.Equal(crypto.Keccak256(helperCode), ret)
+
+	// The deployed helper can be called.
+	ret, _, err = evm.Call(AccountRef(common.Address{}), helperAddr, nil, 100_000, big.NewInt(0))
+	require.NoError(err)
+	require.Equal(common.BigToHash(big.NewInt(42)).Bytes(), ret)
+}
+
+// envelopePrecompile wraps the payload given as input in a cross-chain message
+// envelope bound to the chain's genesis hash, so that messages cannot be
+// replayed on other networks with the same chain ID.
+type envelopePrecompile struct{}
+
+func (envelopePrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	genesisHash := accessibleState.GetGenesisHash()
+	envelope := make([]byte, 0, common.HashLength+len(input))
+	envelope = append(envelope, genesisHash[:]...)
+	envelope = append(envelope, input...)
+	return envelope, suppliedGas, nil
+}
+
+func TestPrecompileGenesisHashEnvelope(t *testing.T) {
+	payload := []byte("transfer")
+	tests := map[string]struct {
+		genesisHash func() common.Hash
+		expected    common.Hash
+	}{
+		"network a": {
+			genesisHash: func() common.Hash { return common.Hash{0xa} },
+			expected:    common.Hash{0xa},
+		},
+		"network b": {
+			genesisHash: func() common.Hash { return common.Hash{0xb} },
+			expected:    common.Hash{0xb},
+		},
+		"no chain": {
+			expected: common.Hash{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			blockCtx := BlockContext{
+				BlockNumber: big.NewInt(1),
+				GenesisHash: test.genesisHash,
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+			envelope, _, err := envelopePrecompile{}.Run(evm, common.Address{}, common.Address{}, payload, 0, false)
+			require.NoError(err)
+			require.Equal(append(test.expected.Bytes(), payload...), envelope)
+		})
+	}
+}
+
+// transientCounterPrecompile increments a counter kept in transient storage
+// and returns its new value.
+type transientCounterPrecompile struct{}
+
+func (transientCounterPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, addr common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	stateDB := accessibleState.GetStateDB()
+	counter := new(big.Int).SetBytes(stateDB.GetTransientState(addr, common.Hash{}).Bytes())
+	next := common.BigToHash(counter.Add(counter, common.Big1))
+	stateDB.SetTransientState(addr, common.Hash{}, next)
+	return next.Bytes(), suppliedGas, nil
+}
+
+func TestPrecompileTransientState(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	precompileAddr := common.HexToAddress("0xaa")
+
+	// The counter is shared between calls within a transaction.
+	for i := int64(1); i <= 2; i++ {
+		ret, _, err := transientCounterPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+		require.NoError(err)
+		require.Equal(common.BigToHash(big.NewInt(i)).Bytes(), ret)
+	}
+
+	// Transient storage is discarded once the next transaction is prepared.
+	statedb.Prepare(evm.chainRules, common.Address{}, common.Address{}, nil, nil, nil)
+	require.Equal(common.Hash{}, statedb.GetTransientState(precompileAddr, common.Hash{}))
+	ret, _, err := transientCounterPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+	require.NoError(err)
+	require.Equal(common.BigToHash(common.Big1).Bytes(), ret)
+}
+
+// reentrancyLockPrecompile holds a lock in transient storage for the rest of
+// the transaction. If the input is non-empty the call fails after taking the
+// lock, reverting to the snapshot taken when it was called.
+type reentrancyLockPrecompile struct{}
+
+var errLocked = errors.New("locked")
+
+func (reentrancyLockPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, addr common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	stateDB := accessibleState.GetStateDB()
+	if stateDB.GetTransientState(addr, common.Hash{}) != (common.Hash{}) {
+		return nil, suppliedGas, errLocked
+	}
+	snapshot := stateDB.Snapshot()
+	stateDB.SetTransientState(addr, common.Hash{}, common.Hash{1})
+	if len(input) != 0 {
+		stateDB.RevertToSnapshot(snapshot)
+		return nil, suppliedGas, vmerrs.ErrExecutionReverted
+	}
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileTransientStateRevert(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	precompileAddr := common.HexToAddress("0xaa")
+
+	// A reverted call does not hold the lock.
+	_, _, err = reentrancyLockPrecompile{}.Run(evm, common.Address{}, precompileAddr, []byte{1}, 0, false)
+	require.ErrorIs(err, vmerrs.ErrExecutionReverted)
+	require.Equal(common.Hash{}, statedb.GetTransientState(precompileAddr, common.Hash{}))
+
+	_, _, err = reentrancyLockPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+	require.NoError(err)
+	_, _, err = reentrancyLockPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+	require.ErrorIs(err, errLocked)
+
+	// Reverting to a snapshot taken by the caller before the lock was taken
+	// releases it.
+	statedb.Prepare(evm.chainRules, common.Address{}, common.Address{}, nil, nil, nil)
+	snapshot := statedb.Snapshot()
+	_, _, err = reentrancyLockPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+	require.NoError(err)
+	statedb.RevertToSnapshot(snapshot)
+	_, _, err = reentrancyLockPrecompile{}.Run(evm, common.Address{}, precompileAddr, nil, 0, false)
+	require.NoError(err)
+}
+
+// storageReaderPrecompile reads the storage slot given by the last 32 bytes of
+// its input from the contract given by the first 20 bytes, charging EIP-2929
+// access costs for both.
+type storageReaderPrecompile struct{}
+
+func (storageReaderPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	stateDB := accessibleState.GetStateDB()
+	target := common.BytesToAddress(input[:common.AddressLength])
+	slot := common.BytesToHash(input[common.AddressLength:])
+
+	var cost uint64
+	addrOk, slotOk := stateDB.SlotInAccessList(target, slot)
+	if !addrOk {
+		cost += params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929
+	}
+	if slotOk {
+		cost += params.WarmStorageReadCostEIP2929
+	} else {
+		cost += params.ColdSloadCostEIP2929
+	}
+	if suppliedGas < cost {
+		return nil, 0, vmerrs.ErrOutOfGas
+	}
+	stateDB.AddSlotToAccessList(target, slot)
+	return stateDB.GetState(target, slot).Bytes(), suppliedGas - cost, nil
+}
+
+func TestPrecompileAccessList(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	precompileAddr := common.HexToAddress("0xaa")
+	target := common.HexToAddress("0xbb")
+	slot, value := common.Hash{1}, common.Hash{2}
+	statedb.SetState(target, slot, value)
+	input := append(target.Bytes(), slot.Bytes()...)
+
+	statedb.Prepare(evm.chainRules, common.Address{}, common.Address{}, nil, nil, nil)
+	require.False(statedb.AddressInAccessList(target))
+
+	// Cold access pays for both the account and the slot.
+	_, _, err = storageReaderPrecompile{}.Run(evm, common.Address{}, precompileAddr, input, params.ColdSloadCostEIP2929, false)
+	require.ErrorIs(err, vmerrs.ErrOutOfGas)
+	coldCost := params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929 + params.ColdSloadCostEIP2929
+	ret, remainingGas, err := storageReaderPrecompile{}.Run(evm, common.Address{}, precompileAddr, input, coldCost, false)
+	require.NoError(err)
+	require.Zero(remainingGas)
+	require.Equal(value.Bytes(), ret)
+	require.True(statedb.AddressInAccessList(target))
+
+	// Subsequent access in the same transaction is warm.
+	ret, remainingGas, err = storageReaderPrecompile{}.Run(evm, common.Address{}, precompileAddr, input, coldCost, false)
+	require.NoError(err)
+	require.Equal(coldCost-params.WarmStorageReadCostEIP2929, remainingGas)
+	require.Equal(value.Bytes(), ret)
+
+	// An account added to the access list makes only the slot cold.
+	other := common.HexToAddress("0xcc")
+	statedb.AddAddressToAccessList(other)
+	addrOk, slotOk := statedb.SlotInAccessList(other, slot)
+	require.True(addrOk)
+	require.False(slotOk)
+	_, remainingGas, err = storageReaderPrecompile{}.Run(evm, common.Address{}, precompileAddr, append(other.Bytes(), slot.Bytes()...), coldCost, false)
+	require.NoError(err)
+	require.Equal(coldCost-params.ColdSloadCostEIP2929, remainingGas)
+}
+
+// readOnlyPrecompile records whether the accessible state reported a static
+// context when it was called.
+type readOnlyPrecompile struct {
+	isReadOnly bool
+}
+
+func (p *readOnlyPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	p.isReadOnly = accessibleState.IsReadOnly()
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileIsReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	p := &readOnlyPrecompile{}
+	_, _, err = evm.runPrecompile(p, common.Address{}, common.Address{}, nil, 0, false)
+	require.NoError(err)
+	require.False(p.isReadOnly)
+
+	// A STATICCALL to the precompile from a frame which is not static.
+	_, _, err = evm.runPrecompile(p, common.Address{}, common.Address{}, nil, 0, true)
+	require.NoError(err)
+	require.True(p.isReadOnly)
+	require.False(evm.IsReadOnly())
+
+	// A call to the precompile from a static frame.
+	evm.interpreter.readOnly = true
+	_, _, err = evm.runPrecompile(p, common.Address{}, common.Address{}, nil, 0, true)
+	require.NoError(err)
+	require.True(p.isReadOnly)
+	require.True(evm.IsReadOnly())
+}
+
+// txIndexPrecompile records the tx index reported by the accessible state
+// each time it is called.
+type txIndexPrecompile struct {
+	txIndices []uint
+}
+
+func (p *txIndexPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	p.txIndices = append(p.txIndices, accessibleState.GetTxIndex())
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileTxIndex(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	// The precompile is called by two successive transactions of a block.
+	p := &txIndexPrecompile{}
+	for i := 0; i < 2; i++ {
+		statedb.SetTxContext(common.Hash{byte(i)}, i)
+		_, _, err = evm.runPrecompile(p, common.Address{}, common.Address{}, nil, 0, false)
+		require.NoError(err)
+	}
+	require.Equal([]uint{0, 1}, p.txIndices)
+}
+
+// minBaseFeePrecompile reverts if the base fee of the block is below the
+// minimum base fee of the chain for the child of a block with [parentTimestamp].
+type minBaseFeePrecompile struct {
+	parentTimestamp uint64
+}
+
+func (p minBaseFeePrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	minBaseFee := accessibleState.GetChainConfig().GetMinBaseFee(p.parentTimestamp)
+	baseFee := accessibleState.GetBlockContext().BaseFee()
+	if minBaseFee == nil || baseFee == nil || baseFee.Cmp(minBaseFee) < 0 {
+		return nil, suppliedGas, vmerrs.ErrExecutionReverted
+	}
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileMinBaseFee(t *testing.T) {
+	tests := map[string]struct {
+		config      *params.ChainConfig
+		baseFee     *big.Int
+		expectedErr error
+	}{
+		"at minimum": {
+			config:  params.TestEUpgradeChainConfig,
+			baseFee: big.NewInt(params.EUpgradeMinBaseFee),
+		},
+		"below minimum": {
+			config:      params.TestEUpgradeChainConfig,
+			baseFee:     big.NewInt(params.EUpgradeMinBaseFee - 1),
+			expectedErr: vmerrs.ErrExecutionReverted,
+		},
+		"below minimum of earlier upgrade": {
+			config:      params.TestApricotPhase4Config,
+			baseFee:     big.NewInt(params.EUpgradeMinBaseFee),
+			expectedErr: vmerrs.ErrExecutionReverted,
+		},
+		"pre dynamic fees": {
+			config:      params.TestApricotPhase2Config,
+			expectedErr: vmerrs.ErrExecutionReverted,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			blockCtx := BlockContext{
+				BlockNumber: big.NewInt(1),
+				BaseFee:     test.baseFee,
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, test.config, Config{})
+
+			_, _, err = minBaseFeePrecompile{}.Run(evm, common.Address{}, common.Address{}, nil, 0, false)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
+// tracedPrecompile charges 10 gas and then 20 gas, reporting each step when
+// traced.
+type tracedPrecompile struct{}
+
+func (p tracedPrecompile) Run(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) ([]byte, uint64, error) {
+	return p.RunWithTracing(accessibleState, caller, addr, input, suppliedGas, readOnly, func(string, uint64) {})
+}
+
+func (tracedPrecompile) RunWithTracing(_ contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool, traceOp contract.TraceOpFunc) ([]byte, uint64, error) {
+	remainingGas, err := contract.DeductGas(suppliedGas, 10)
+	if err != nil {
+		return nil, 0, err
+	}
+	traceOp("first", 10)
+	if remainingGas, err = contract.DeductGas(remainingGas, 20); err != nil {
+		return nil, 0, err
+	}
+	traceOp("second", 20)
+	return nil, remainingGas, nil
+}
+
+type precompileOp struct {
+	depth   int
+	label   string
+	gasUsed uint64
+}
+
+// precompileOpRecorder is an EVMLogger that only records the sub-operations
+// reported by precompiles.
+type precompileOpRecorder struct {
+	ops []precompileOp
+}
+
+func (*precompileOpRecorder) CaptureTxStart(uint64) {}
+func (*precompileOpRecorder) CaptureTxEnd(uint64)   {}
+func (*precompileOpRecorder) CaptureStart(*EVM, common.Address, common.Address, bool, []byte, uint64, *big.Int) {
+}
+func (*precompileOpRecorder) CaptureEnd([]byte, uint64, error) {}
+func (*precompileOpRecorder) CaptureEnter(OpCode, common.Address, common.Address, []byte, uint64, *big.Int) {
+}
+func (*precompileOpRecorder) CaptureExit([]byte, uint64, error) {}
+func (*precompileOpRecorder) CaptureState(uint64, OpCode, uint64, uint64, *ScopeContext, []byte, int, error) {
+}
+func (*precompileOpRecorder) CaptureFault(uint64, OpCode, uint64, uint64, *ScopeContext, int, error) {
+}
+
+func (r *precompileOpRecorder) CapturePrecompileOp(depth int, label string, gasUsed uint64) {
+	r.ops = append(r.ops, precompileOp{depth, label, gasUsed})
+}
+
+func TestPrecompileOpTracing(t *testing.T) {
+	precompileAddr := common.Address{0xff}
+	// Calls [precompileAddr] with all remaining gas and stops.
+	caller := []byte{
+		byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0,
+		byte(PUSH20),
+	}
+	caller = append(caller, precompileAddr[:]...)
+	caller = append(caller, byte(GAS), byte(CALL), byte(STOP))
+	callerAddr := common.Address{0xfe}
+
+	tests := map[string]struct {
+		to          common.Address
+		expectedOps []precompileOp
+	}{
+		"direct call": {
+			to:          precompileAddr,
+			expectedOps: []precompileOp{{1, "first", 10}, {1, "second", 20}},
+		},
+		"call from contract": {
+			to:          callerAddr,
+			expectedOps: []precompileOp{{2, "first", 10}, {2, "second", 20}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			run := func(tracer EVMLogger) uint64 {
+				statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+				require.NoError(err)
+				statedb.SetCode(callerAddr, caller)
+				vmCtx := BlockContext{
+					BlockNumber: big.NewInt(0),
+					CanTransfer: CanTransfer,
+					Transfer:    Transfer,
+				}
+				evm := NewEVM(vmCtx, TxContext{}, statedb, params.TestChainConfig, Config{Tracer: tracer})
+				// Copy the precompiles, since they are shared by all EVMs.
+				evm.precompiles = maps.Clone(evm.precompiles)
+				evm.precompiles[precompileAddr] = tracedPrecompile{}
+				_, remainingGas, err := evm.Call(AccountRef(common.Address{}), test.to, nil, 100_000, big0)
+				require.NoError(err)
+				return remainingGas
+			}
+
+			recorder := &precompileOpRecorder{}
+			require.Equal(run(nil), run(recorder))
+			require.Equal(test.expectedOps, recorder.ops)
+		})
+	}
+}
+
+// fixedCostPrecompile charges [cost] gas.
+type fixedCostPrecompile struct {
+	cost uint64
+}
+
+func (p fixedCostPrecompile) Run(_ contract.AccessibleState, _ common.Address, _ common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	remainingGas, err := contract.DeductGas(suppliedGas, p.cost)
+	return nil, remainingGas, err
+}
+
+func TestPrecompileGasMultiplier(t *testing.T) {
+	precompileAddr := common.Address{0x1}
+	tests := map[string]struct {
+		multipliers          []params.PrecompileGasMultiplier
+		suppliedGas          uint64
+		expectedRemainingGas uint64
+		expectedErr          error
+	}{
+		"no multiplier": {
+			suppliedGas:          100,
+			expectedRemainingGas: 97,
+		},
+		"multiplier of other precompile": {
+			multipliers:          []params.PrecompileGasMultiplier{{Address: common.Address{0x2}, BlockTimestamp: utils.NewUint64(0), Multiplier: 20_000}},
+			suppliedGas:          100,
+			expectedRemainingGas: 97,
+		},
+		"multiplier not activated": {
+			multipliers:          []params.PrecompileGasMultiplier{{Address: precompileAddr, BlockTimestamp: utils.NewUint64(20), Multiplier: 20_000}},
+			suppliedGas:          100,
+			expectedRemainingGas: 97,
+		},
+		"doubled": {
+			multipliers:          []params.PrecompileGasMultiplier{{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 20_000}},
+			suppliedGas:          100,
+			expectedRemainingGas: 94,
+		},
+		"doubled out of gas": {
+			multipliers: []params.PrecompileGasMultiplier{{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 20_000}},
+			suppliedGas: 5,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+		"halved rounding up": {
+			multipliers:          []params.PrecompileGasMultiplier{{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 5_000}},
+			suppliedGas:          100,
+			expectedRemainingGas: 98,
+		},
+		// The precompile is never supplied more gas than the caller.
+		"halved with less gas than unscaled cost": {
+			multipliers: []params.PrecompileGasMultiplier{{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 5_000}},
+			suppliedGas: 2,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+		"latest multiplier": {
+			multipliers: []params.PrecompileGasMultiplier{
+				{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 5_000},
+				{Address: precompileAddr, BlockTimestamp: utils.NewUint64(10), Multiplier: 20_000},
+			},
+			suppliedGas:          100,
+			expectedRemainingGas: 94,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := *params.TestChainConfig
+			config.UpgradeConfig = params.UpgradeConfig{PrecompileGasMultipliers: test.multipliers}
+			require.NoError(config.Verify())
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1), Time: 10}, TxContext{}, statedb, &config, Config{})
+
+			_, remainingGas, err := evm.runPrecompile(fixedCostPrecompile{cost: 3}, common.Address{}, precompileAddr, nil, test.suppliedGas, false)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedRemainingGas, remainingGas)
+		})
+	}
+}
+
+// nestedCallPrecompile charges [cost] and calls [callee] through
+// NativeAssetCall with the remaining gas.
+type nestedCallPrecompile struct {
+	cost   uint64
+	callee common.Address
+}
+
+func (p nestedCallPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, addr common.Address, _ []byte, suppliedGas uint64, readOnly bool) ([]byte, uint64, error) {
+	remainingGas, err := contract.DeductGas(suppliedGas, p.cost)
+	if err != nil {
+		return nil, 0, err
+	}
+	input := PackNativeAssetCallInput(p.callee, common.Hash{}, common.Big0, nil)
+	return accessibleState.NativeAssetCall(addr, input, remainingGas, 0, readOnly)
+}
+
+func TestPrecompileGasMultiplierNestedCall(t *testing.T) {
+	require := require.New(t)
+
+	precompileAddr := common.Address{0x1}
+	callee := common.Address{0xca}
+	config := *params.TestChainConfig
+	config.UpgradeConfig = params.UpgradeConfig{PrecompileGasMultipliers: []params.PrecompileGasMultiplier{
+		{Address: precompileAddr, BlockTimestamp: utils.NewUint64(0), Multiplier: 20_000},
+	}}
+	require.NoError(config.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	// The callee uses 3 gas: JUMPDEST, JUMPDEST, JUMPDEST, STOP.
+	statedb.SetCode(callee, []byte{byte(JUMPDEST), byte(JUMPDEST), byte(JUMPDEST), byte(STOP)})
+	blockCtx := BlockContext{
+		BlockNumber:       big.NewInt(1),
+		Time:              10,
+		CanTransfer:       func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:          func(StateDB, common.Address, common.Address, *big.Int) {},
+		TransferMultiCoin: func(StateDB, common.Address, common.Address, common.Hash, *big.Int) {},
+	}
+	evm := NewEVM(blockCtx, TxContext{}, statedb, &config, Config{})
+
+	// Only the 3 gas charged by the precompile itself is doubled, not the 3
+	// gas used by the callee.
+	_, remainingGas, err := evm.runPrecompile(nestedCallPrecompile{cost: 3, callee: callee}, common.Address{}, precompileAddr, nil, 100, false)
+	require.NoError(err)
+	require.Equal(uint64(100-6-3), remainingGas)
+}
+
+// quorumPrecompile returns the quorum numerator of its own warp config.
+type quorumPrecompile struct{}
+
+func (quorumPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, addr common.Address, _ []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	config, ok := accessibleState.GetPrecompileConfig(addr)
+	if !ok {
+		return nil, suppliedGas, errors.New("precompile not enabled")
+	}
+	quorum := config.(*warp.Config).QuorumNumerator
+	return common.BigToHash(new(big.Int).SetUint64(quorum)).Bytes(), suppliedGas, nil
+}
+
+func TestPrecompileGetPrecompileConfig(t *testing.T) {
+	config := *params.TestChainConfig
+	config.UpgradeConfig.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Config: warp.NewConfig(utils.NewUint64(10), 80)},
+		{Config: warp.NewDisableConfig(utils.NewUint64(20))},
+	}
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		time    uint64
+		want    uint64
+		wantErr bool
+	}{
+		"before activation": {time: 5, wantErr: true},
+		"enabled":           {time: 10, want: 80},
+		"disabled":          {time: 20, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1), Time: test.time}, TxContext{}, statedb, &config, Config{})
+			ret, _, err := quorumPrecompile{}.Run(evm, common.Address{}, warp.ContractAddress, nil, 0, false)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, new(big.Int).SetBytes(ret).Uint64())
+		})
+	}
+}
+
+func TestPrecompileGetBlobGasPrice(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		excessBlobGas *uint64
+		want          int64
+	}{
+		"before cancun":         {want: 0},
+		"no excess":             {excessBlobGas: utils.NewUint64(0), want: 1},
+		"below first increment": {excessBlobGas: utils.NewUint64(2314057), want: 1},
+		"first increment":       {excessBlobGas: utils.NewUint64(2314058), want: 2},
+		"large excess":          {excessBlobGas: utils.NewUint64(10 * 1024 * 1024), want: 23},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			blockCtx := BlockContext{BlockNumber: big.NewInt(1)}
+			if test.excessBlobGas != nil {
+				blockCtx.BlobBaseFee = eip4844.CalcBlobFee(*test.excessBlobGas)
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+			price := evm.GetBlobGasPrice()
+			require.Equal(t, test.want, price.Int64())
+
+			// The price returned may be modified by the caller.
+			price.SetInt64(100)
+			require.Equal(t, test.want, evm.GetBlobGasPrice().Int64())
+		})
+	}
+}
+
+// feeGovernancePrecompile stores the fee parameters in [input] if it is
+// called by the creator of the subnet.
+type feeGovernancePrecompile struct{}
+
+func (feeGovernancePrecompile) Run(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) ([]byte, uint64, error) {
+	creator := accessibleState.GetChainConfig().GetSubnetCreatorAddress()
+	if creator == (common.Address{}) || caller != creator {
+		return nil, suppliedGas, vmerrs.ErrExecutionReverted
+	}
+	if readOnly {
+		return nil, suppliedGas, vmerrs.ErrWriteProtection
+	}
+	accessibleState.GetStateDB().SetState(addr, common.Hash{}, common.BytesToHash(input))
+	return nil, suppliedGas, nil
+}
+
+func TestPrecompileSubnetCreatorAddress(t *testing.T) {
+	creator := common.Address{0xc}
+	withCreator := *params.TestChainConfig
+	withCreator.SubnetCreatorAddress = creator
+
+	tests := map[string]struct {
+		config      *params.ChainConfig
+		caller      common.Address
+		readOnly    bool
+		expectedErr error
+	}{
+		"creator": {
+			config: &withCreator,
+			caller: creator,
+		},
+		"other caller": {
+			config:      &withCreator,
+			caller:      common.Address{0xd},
+			expectedErr: vmerrs.ErrExecutionReverted,
+		},
+		"read only": {
+			config:      &withCreator,
+			caller:      creator,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection,
+		},
+		"no creator": {
+			config:      params.TestChainConfig,
+			caller:      common.Address{},
+			expectedErr: vmerrs.ErrExecutionReverted,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(err)
+			evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, test.config, Config{})
+
+			addr := common.Address{0xfe}
+			input := common.Hash{0x1}
+			_, _, err = feeGovernancePrecompile{}.Run(evm, test.caller, addr, input[:], 0, test.readOnly)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				require.Equal(input, statedb.GetState(addr, common.Hash{}))
+			} else {
+				require.Equal(common.Hash{}, statedb.GetState(addr, common.Hash{}))
+			}
+		})
+	}
+}
+
+// precompileDataPrecompile stores its input under a fixed key, and returns
+// the value previously stored.
+type precompileDataPrecompile struct{}
+
+func (precompileDataPrecompile) Run(accessibleState contract.AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	prev, err := accessibleState.GetPrecompileData([]byte("key"))
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	if err := accessibleState.SetPrecompileData([]byte("key"), input); err != nil {
+		return nil, suppliedGas, err
+	}
+	return prev, suppliedGas, nil
+}
+
+func TestPrecompileSetPrecompileData(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	// The data is stored in the storage of the running precompile.
+	addr := common.Address{0xfe}
+	value := bytes.Repeat([]byte{0x1}, 100)
+	ret, _, err := evm.runPrecompile(precompileDataPrecompile{}, common.Address{}, addr, value, 0, false)
+	require.NoError(err)
+	require.Empty(ret)
+	ret, _, err = evm.runPrecompile(precompileDataPrecompile{}, common.Address{}, addr, []byte("short"), 0, false)
+	require.NoError(err)
+	require.Equal(value, ret)
+	stored, err := contract.ReadPrecompileData(statedb, addr, []byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("short"), stored)
+
+	ret, _, err = evm.runPrecompile(precompileDataPrecompile{}, common.Address{}, common.Address{0xfd}, nil, 0, false)
+	require.NoError(err)
+	require.Empty(ret)
+
+	// The data cannot be written in a static context, nor accessed outside
+	// of a precompile.
+	_, _, err = evm.runPrecompile(precompileDataPrecompile{}, common.Address{}, addr, value, 0, true)
+	require.ErrorIs(err, vmerrs.ErrWriteProtection)
+	stored, err = contract.ReadPrecompileData(statedb, addr, []byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("short"), stored)
+	_, err = evm.GetPrecompileData([]byte("key"))
+	require.ErrorIs(err, errNoRunningPrecompile)
+	require.ErrorIs(evm.SetPrecompileData([]byte("key"), value), errNoRunningPrecompile)
+}
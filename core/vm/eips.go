@@ -33,6 +33,7 @@ import (
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/holiman/uint256"
 )
 
@@ -172,6 +173,49 @@ func enableAP1(jt *JumpTable) {
 	jt[CALLEX].dynamicGas = gasCallExpertAP1
 }
 
+// enableStorageLastModified charges SSTORE for the record of the block in
+// which the slot was last modified, kept under [constants.StorageLastModifiedAddr].
+// The record is charged as an SSTORE of its own: creating it costs
+// SSTORE_SET_GAS, updating or deleting it costs SSTORE_RESET_GAS, and leaving
+// it unchanged, as when the slot was already modified in the same block, is
+// free.
+func enableStorageLastModified(jt *JumpTable) {
+	sstoreGas := jt[SSTORE].dynamicGas
+	jt[SSTORE].dynamicGas = func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		gas, err := sstoreGas(evm, contract, stack, mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		var (
+			slot  = common.Hash(stack.peek().Bytes32())
+			value = common.Hash(stack.Back(1).Bytes32())
+		)
+		if evm.StateDB.GetState(contract.Address(), slot) == value {
+			return gas, nil
+		}
+		var (
+			current = evm.StateDB.GetLastModifiedBlock(contract.Address(), slot)
+			next    = evm.Context.BlockNumber.Uint64()
+		)
+		if value == (common.Hash{}) {
+			next = 0
+		}
+		var recordGas uint64
+		switch {
+		case current == next:
+			return gas, nil
+		case current == 0:
+			recordGas = params.SstoreSetGasEIP2200
+		default:
+			recordGas = params.SstoreResetGasEIP2200
+		}
+		if gas, overflow := math.SafeAdd(gas, recordGas); !overflow {
+			return gas, nil
+		}
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+}
+
 func enableAP2(jt *JumpTable) {
 	jt[BALANCEMC] = &operation{execute: opUndefined, maxStack: maxStack(0, 0)}
 	jt[CALLEX] = &operation{execute: opUndefined, maxStack: maxStack(0, 0)}
@@ -220,7 +264,7 @@ func opTload(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]by
 // opTstore implements TSTORE opcode
 func opTstore(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(TSTORE)}
 	}
 	loc := scope.Stack.pop()
 	val := scope.Stack.pop()
@@ -27,6 +27,8 @@
 package vm
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -47,15 +49,21 @@ import (
 
 var (
 	_ contract.AccessibleState = &EVM{}
-	_ contract.BlockContext    = &BlockContext{}
+	_ contract.BlockContext    = precompileBlockContext{}
+	_ contract.StateDB         = precompileStateDB{}
+
+	errNoRunningPrecompile = errors.New("no precompile is running")
 )
 
 // IsProhibited returns true if [addr] is in the prohibited list of addresses which should
-// not be allowed as an EOA or newly created contract address.
-func IsProhibited(addr common.Address) bool {
+// not be allowed as an EOA or newly created contract address under [rules].
+func IsProhibited(addr common.Address, rules params.Rules) bool {
 	if addr == constants.BlackholeAddr {
 		return true
 	}
+	if rules.IsStorageLastModified && addr == constants.StorageLastModifiedAddr {
+		return true
+	}
 
 	return modules.ReservedAddress(addr)
 }
@@ -73,28 +81,8 @@ type (
 )
 
 func (evm *EVM) precompile(addr common.Address) (contract.StatefulPrecompiledContract, bool) {
-	var precompiles map[common.Address]contract.StatefulPrecompiledContract
-	switch {
-	case evm.chainRules.IsCancun:
-		precompiles = PrecompiledContractsCancun
-	case evm.chainRules.IsBanff:
-		precompiles = PrecompiledContractsBanff
-	case evm.chainRules.IsApricotPhase6:
-		precompiles = PrecompiledContractsApricotPhase6
-	case evm.chainRules.IsApricotPhasePre6:
-		precompiles = PrecompiledContractsApricotPhasePre6
-	case evm.chainRules.IsApricotPhase2:
-		precompiles = PrecompiledContractsApricotPhase2
-	case evm.chainRules.IsIstanbul:
-		precompiles = PrecompiledContractsIstanbul
-	case evm.chainRules.IsByzantium:
-		precompiles = PrecompiledContractsByzantium
-	default:
-		precompiles = PrecompiledContractsHomestead
-	}
-
 	// Check the existing precompiles first
-	p, ok := precompiles[addr]
+	p, ok := evm.precompiles[addr]
 	if ok {
 		return p, true
 	}
@@ -126,6 +114,15 @@ type BlockContext struct {
 	// PredicateResults are the results of predicate verification available throughout the EVM's execution.
 	// PredicateResults may be nil if it is not encoded in the block's header.
 	PredicateResults *predicate.Results
+	// Rules are the chain rules in effect for the block. If nil, they are
+	// resolved from the chain config each time an EVM is created.
+	Rules *params.Rules
+	// BlockGasRemaining returns the gas remaining in the block's gas pool.
+	// If nil, the block's gas limit is reported instead.
+	BlockGasRemaining func() uint64
+	// GenesisHash returns the hash of the chain's genesis block. If nil, the
+	// zero hash is reported instead.
+	GenesisHash func() common.Hash
 
 	// Block information
 	Coinbase    common.Address // Provides information for COINBASE
@@ -145,13 +142,86 @@ func (b *BlockContext) Timestamp() uint64 {
 	return b.Time
 }
 
-func (b *BlockContext) GetPredicateResults(txHash common.Hash, address common.Address) []byte {
+func (b *BlockContext) GetBlockGasRemaining() uint64 {
+	if b.BlockGasRemaining == nil {
+		return b.GasLimit
+	}
+	return b.BlockGasRemaining()
+}
+
+// GetLatestAcceptedHeight returns the height of the block's parent. It is
+// derived from the block rather than the node's last accepted block, so that
+// every node executing the block observes the same value.
+func (b *BlockContext) GetLatestAcceptedHeight() uint64 {
+	if b.BlockNumber == nil || b.BlockNumber.Sign() == 0 {
+		return 0
+	}
+	return b.BlockNumber.Uint64() - 1
+}
+
+func (b *BlockContext) GetGenesisHash() common.Hash {
+	if b.GenesisHash == nil {
+		return common.Hash{}
+	}
+	return b.GenesisHash()
+}
+
+func (b *BlockContext) GetPredicateResults(txHash common.Hash, address common.Address) ([]byte, bool) {
 	if b.PredicateResults == nil {
-		return nil
+		return nil, false
 	}
 	return b.PredicateResults.GetResults(txHash, address)
 }
 
+// precompileBlockContext exposes a BlockContext to stateful precompiles as a
+// contract.BlockContext.
+type precompileBlockContext struct {
+	*BlockContext
+	minerFeeRecipient common.Address
+}
+
+// BaseFee returns a copy of the block's base fee, or nil prior to Apricot
+// Phase 3.
+func (b precompileBlockContext) BaseFee() *big.Int {
+	if b.BlockContext.BaseFee == nil {
+		return nil
+	}
+	return new(big.Int).Set(b.BlockContext.BaseFee)
+}
+
+func (b precompileBlockContext) Coinbase() common.Address {
+	return b.BlockContext.Coinbase
+}
+
+// GetMinerFeeRecipient returns the fee recipient of the chain config, or the
+// block's coinbase if there is none.
+func (b precompileBlockContext) GetMinerFeeRecipient() common.Address {
+	if b.minerFeeRecipient != (common.Address{}) {
+		return b.minerFeeRecipient
+	}
+	return b.BlockContext.Coinbase
+}
+
+func (b precompileBlockContext) GasLimit() uint64 {
+	return b.BlockContext.GasLimit
+}
+
+// precompileStateDB exposes a StateDB to stateful precompiles as a
+// contract.StateDB.
+type precompileStateDB struct {
+	StateDB
+}
+
+// SubBalance panics if [amount] exceeds the balance of [addr]. Unlike the EVM,
+// precompiles are not guarded by CanTransfer, so an unchecked debit is a bug in
+// the precompile rather than a negative balance to be committed.
+func (s precompileStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	if balance := s.StateDB.GetBalance(addr); balance.Cmp(amount) < 0 {
+		panic(fmt.Sprintf("insufficient balance to subtract %v from %v: %v", amount, addr, balance))
+	}
+	s.StateDB.SubBalance(addr, amount)
+}
+
 // TxContext provides the EVM with information about a transaction.
 // All fields can change between transactions.
 type TxContext struct {
@@ -184,6 +254,11 @@ type EVM struct {
 	chainConfig *params.ChainConfig
 	// chain rules contains the chain rules for the current epoch
 	chainRules params.Rules
+	// precompiles contains the native precompiled contracts enabled by
+	// [chainRules]
+	precompiles map[common.Address]contract.StatefulPrecompiledContract
+	// activePrecompiles contains the addresses of [precompiles]
+	activePrecompiles []common.Address
 	// virtual machine configuration options used to initialise the
 	// evm.
 	Config Config
@@ -192,10 +267,18 @@ type EVM struct {
 	interpreter *EVMInterpreter
 	// abort is used to abort the EVM calling operations
 	abort atomic.Bool
+	// timedOut is set if the EVM was aborted by CancelAfter
+	timedOut atomic.Bool
 	// callGasTemp holds the gas available for the current call. This is needed because the
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// precompileAddr is the address of the running stateful precompile, whose
+	// storage is accessed by SetPrecompileData and GetPrecompileData.
+	precompileAddr common.Address
+	// precompileNestedCallGas is the gas used by the calls made by the
+	// running stateful precompile through NativeAssetCall.
+	precompileNestedCallGas uint64
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -212,13 +295,21 @@ func NewEVM(blockCtx BlockContext, txCtx TxContext, statedb StateDB, chainConfig
 			blockCtx.BlobBaseFee = new(big.Int)
 		}
 	}
+	var chainRules params.Rules
+	if blockCtx.Rules != nil {
+		chainRules = *blockCtx.Rules
+	} else {
+		chainRules = chainConfig.Rules(blockCtx.BlockNumber, blockCtx.Time)
+	}
 	evm := &EVM{
-		Context:     blockCtx,
-		TxContext:   txCtx,
-		StateDB:     statedb,
-		Config:      config,
-		chainConfig: chainConfig,
-		chainRules:  chainConfig.Rules(blockCtx.BlockNumber, blockCtx.Time),
+		Context:           blockCtx,
+		TxContext:         txCtx,
+		StateDB:           statedb,
+		Config:            config,
+		chainConfig:       chainConfig,
+		chainRules:        chainRules,
+		precompiles:       activePrecompiledContracts(chainRules),
+		activePrecompiles: ActivePrecompiles(chainRules),
 	}
 	evm.interpreter = NewEVMInterpreter(evm)
 	return evm
@@ -242,6 +333,22 @@ func (evm *EVM) Cancelled() bool {
 	return evm.abort.Load()
 }
 
+// CancelAfter cancels the EVM once [timeout] has elapsed, after which TimedOut
+// returns true. The returned function stops the timer if the EVM has not yet
+// been cancelled.
+func (evm *EVM) CancelAfter(timeout time.Duration) (stop func() bool) {
+	timer := time.AfterFunc(timeout, func() {
+		evm.timedOut.Store(true)
+		evm.Cancel()
+	})
+	return timer.Stop
+}
+
+// TimedOut returns true if the EVM was cancelled by CancelAfter
+func (evm *EVM) TimedOut() bool {
+	return evm.timedOut.Load()
+}
+
 // GetSnowContext returns the evm's snow.Context.
 func (evm *EVM) GetSnowContext() *snow.Context {
 	return evm.chainConfig.SnowCtx
@@ -249,12 +356,15 @@ func (evm *EVM) GetSnowContext() *snow.Context {
 
 // GetStateDB returns the evm's StateDB
 func (evm *EVM) GetStateDB() contract.StateDB {
-	return evm.StateDB
+	return precompileStateDB{evm.StateDB}
 }
 
 // GetBlockContext returns the evm's BlockContext
 func (evm *EVM) GetBlockContext() contract.BlockContext {
-	return &evm.Context
+	return precompileBlockContext{
+		BlockContext:      &evm.Context,
+		minerFeeRecipient: evm.chainConfig.MinerFeeRecipient,
+	}
 }
 
 // Interpreter returns the current interpreter
@@ -271,6 +381,11 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, vmerrs.ErrDepth
 	}
+	// Fail if the EVM was cancelled during execution, so no further contracts
+	// or precompiles are run.
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, gas, vmerrs.ErrExecutionAborted
+	}
 	// Fail if we're trying to transfer more than the available balance
 	// Note: it is not possible for a negative value to be passed in here due to the fact
 	// that [value] will be popped from the stack and decoded to a *big.Int, which will
@@ -317,7 +432,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 
 	if isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runPrecompile(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
@@ -339,7 +454,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			gas = 0
 		}
 		// TODO: consider clearing up unused snapshots:
@@ -355,6 +470,11 @@ func (evm *EVM) CallExpert(caller ContractRef, addr common.Address, input []byte
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, vmerrs.ErrDepth
 	}
+	// Fail if the EVM was cancelled during execution, so no further contracts
+	// or precompiles are run.
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, gas, vmerrs.ErrExecutionAborted
+	}
 
 	// Fail if we're trying to transfer more than the available balance
 	// Note: it is not possible for a negative value to be passed in here due to the fact
@@ -417,7 +537,7 @@ func (evm *EVM) CallExpert(caller ContractRef, addr common.Address, input []byte
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			gas = 0
 		}
 		// TODO: consider clearing up unused snapshots:
@@ -439,6 +559,11 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, vmerrs.ErrDepth
 	}
+	// Fail if the EVM was cancelled during execution, so no further contracts
+	// or precompiles are run.
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, gas, vmerrs.ErrExecutionAborted
+	}
 	// Fail if we're trying to transfer more than the available balance
 	// Note although it's noop to transfer X ether to caller itself. But
 	// if caller doesn't have enough balance, it would be an error to allow
@@ -461,7 +586,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runPrecompile(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		addrCopy := addr
 		// Initialise a new contract and set the code that is to be used by the EVM.
@@ -473,7 +598,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	}
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			gas = 0
 		}
 	}
@@ -490,6 +615,11 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, vmerrs.ErrDepth
 	}
+	// Fail if the EVM was cancelled during execution, so no further contracts
+	// or precompiles are run.
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, gas, vmerrs.ErrExecutionAborted
+	}
 	var snapshot = evm.StateDB.Snapshot()
 
 	// Invoke tracer hooks that signal entering/exiting a call frame
@@ -506,7 +636,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runPrecompile(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		addrCopy := addr
 		// Initialise a new contract and make initialise the delegate values
@@ -517,7 +647,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	}
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			gas = 0
 		}
 	}
@@ -533,6 +663,11 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, vmerrs.ErrDepth
 	}
+	// Fail if the EVM was cancelled during execution, so no further contracts
+	// or precompiles are run.
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, gas, vmerrs.ErrExecutionAborted
+	}
 	// We take a snapshot here. This is a bit counter-intuitive, and could probably be skipped.
 	// However, even a staticcall is considered a 'touch'. On mainnet, static calls were introduced
 	// after all empty accounts were deleted, so this is not required. However, if we omit this,
@@ -555,7 +690,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	}
 
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, true)
+		ret, gas, err = evm.runPrecompile(p, caller.Address(), addr, input, gas, true)
 	} else {
 		// At this point, we use a copy of address. If we don't, the go compiler will
 		// leak the 'contract' to the outer scope, and make allocation for 'contract'
@@ -573,7 +708,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	}
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			gas = 0
 		}
 	}
@@ -599,6 +734,9 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, common.Address{}, gas, vmerrs.ErrDepth
 	}
+	if evm.depth > 0 && evm.Cancelled() {
+		return nil, common.Address{}, gas, vmerrs.ErrExecutionAborted
+	}
 	// Note: it is not possible for a negative value to be passed in here due to the fact
 	// that [value] will be popped from the stack and decoded to a *big.Int, which will
 	// always yield a positive result.
@@ -607,8 +745,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	}
 	// If there is any collision with a prohibited address, return an error instead
 	// of allowing the contract to be created.
-	if IsProhibited(address) {
-		return nil, common.Address{}, gas, vmerrs.ErrAddrProhibited
+	if IsProhibited(address, evm.chainRules) {
+		return nil, common.Address{}, gas, vmerrs.AddrProhibitedError{Addr: address}
 	}
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	if nonce+1 < nonce {
@@ -650,7 +788,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 
 	// Check whether the max code size has been exceeded, assign err if the case.
 	if err == nil && evm.chainRules.IsEIP158 && len(ret) > params.MaxCodeSize {
-		err = vmerrs.ErrMaxCodeSizeExceeded
+		err = vmerrs.ErrMaxCodeSizeExceeded{Limit: params.MaxCodeSize, Size: len(ret)}
 	}
 
 	// Reject code starting with 0xEF if EIP-3541 is enabled.
@@ -676,7 +814,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil && (evm.chainRules.IsHomestead || err != vmerrs.ErrCodeStoreOutOfGas) {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			contract.UseGas(contract.Gas)
 		}
 	}
@@ -710,22 +848,102 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
+// Rules returns the chain rules in effect for the EVM's block.
+func (evm *EVM) Rules() params.Rules { return evm.chainRules }
+
+// ActivePrecompiles returns the addresses of the native precompiles enabled
+// for the EVM's block.
+func (evm *EVM) ActivePrecompiles() []common.Address { return evm.activePrecompiles }
+
 // GetChainConfig implements AccessibleState
 func (evm *EVM) GetChainConfig() precompileconfig.ChainConfig { return evm.chainConfig }
 
+// GetPrecompileConfig implements AccessibleState
+func (evm *EVM) GetPrecompileConfig(addr common.Address) (precompileconfig.Config, bool) {
+	return evm.chainConfig.GetPrecompileConfig(addr, evm.Context.Time)
+}
+
+// GetBlobGasPrice implements AccessibleState
+func (evm *EVM) GetBlobGasPrice() *big.Int {
+	if evm.Context.BlobBaseFee == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(evm.Context.BlobBaseFee)
+}
+
+// GetGasSchedule implements AccessibleState
+func (evm *EVM) GetGasSchedule() contract.GasSchedule {
+	return evm.chainConfig.GetPrecompileGasSchedule(evm.Context.Time)
+}
+
+// GetTotalSupply implements AccessibleState
+func (evm *EVM) GetTotalSupply(suppliedGas uint64) (*big.Int, uint64, error) {
+	return contract.TotalSupplyWithGas(evm.StateDB.GetTotalSupply, suppliedGas)
+}
+
+// GetLatestAcceptedHeight implements AccessibleState
+func (evm *EVM) GetLatestAcceptedHeight() uint64 {
+	return evm.Context.GetLatestAcceptedHeight()
+}
+
+// GetTxIndex implements AccessibleState
+func (evm *EVM) GetTxIndex() uint {
+	return uint(evm.StateDB.TxIndex())
+}
+
+// IsReadOnly implements AccessibleState
+func (evm *EVM) IsReadOnly() bool {
+	return evm.interpreter.readOnly
+}
+
+// SetPrecompileData implements AccessibleState
+func (evm *EVM) SetPrecompileData(key []byte, value []byte) error {
+	if evm.precompileAddr == (common.Address{}) {
+		return errNoRunningPrecompile
+	}
+	if evm.interpreter.readOnly {
+		return vmerrs.ErrWriteProtection
+	}
+	return contract.WritePrecompileData(evm.GetStateDB(), evm.precompileAddr, key, value)
+}
+
+// GetPrecompileData implements AccessibleState
+func (evm *EVM) GetPrecompileData(key []byte) ([]byte, error) {
+	if evm.precompileAddr == (common.Address{}) {
+		return nil, errNoRunningPrecompile
+	}
+	return contract.ReadPrecompileData(evm.GetStateDB(), evm.precompileAddr, key)
+}
+
+func (evm *EVM) GetGenesisHash() common.Hash {
+	return evm.Context.GetGenesisHash()
+}
+
+// NativeAssetCall implements AccessibleState
+//
+// [gasCost] is charged until the chain config activates its NativeAssetCall
+// gas table, after which the table determines the cost of the call.
 func (evm *EVM) NativeAssetCall(caller common.Address, input []byte, suppliedGas uint64, gasCost uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	gasTable := evm.chainConfig.GetNativeAssetCallGasTable(evm.Context.Time)
+	if evm.chainConfig.IsNativeAssetCallGasTable(evm.Context.Time) {
+		var overflow bool
+		gasCost, overflow = gasTable.IntrinsicCost(len(input))
+		if overflow {
+			return nil, 0, vmerrs.ErrGasUintOverflow
+		}
+	}
 	if suppliedGas < gasCost {
 		return nil, 0, vmerrs.ErrOutOfGas
 	}
 	remainingGas = suppliedGas - gasCost
 
 	if readOnly {
-		return nil, remainingGas, vmerrs.ErrExecutionReverted
+		return nil, remainingGas, &vmerrs.RevertError{}
 	}
 
 	to, assetID, assetAmount, callData, err := UnpackNativeAssetCallInput(input)
 	if err != nil {
-		return nil, remainingGas, vmerrs.ErrExecutionReverted
+		return nil, remainingGas, &vmerrs.RevertError{}
 	}
 
 	// Note: it is not possible for a negative assetAmount to be passed in here due to the fact that decoding a
@@ -737,10 +955,10 @@ func (evm *EVM) NativeAssetCall(caller common.Address, input []byte, suppliedGas
 	snapshot := evm.StateDB.Snapshot()
 
 	if !evm.StateDB.Exist(to) {
-		if remainingGas < params.CallNewAccountGas {
+		if remainingGas < gasTable.NewAccount {
 			return nil, 0, vmerrs.ErrOutOfGas
 		}
-		remainingGas -= params.CallNewAccountGas
+		remainingGas -= gasTable.NewAccount
 		evm.StateDB.CreateAccount(to)
 	}
 
@@ -750,14 +968,16 @@ func (evm *EVM) NativeAssetCall(caller common.Address, input []byte, suppliedGas
 
 	// Send [assetAmount] of [assetID] to [to] address
 	evm.Context.TransferMultiCoin(evm.StateDB, caller, to, assetID, assetAmount)
+	callGas := remainingGas
 	ret, remainingGas, err = evm.Call(AccountRef(caller), to, callData, remainingGas, new(big.Int))
+	defer func() { evm.precompileNestedCallGas += callGas - remainingGas }()
 
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in homestead this also counts for code storage gas errors.
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
-		if err != vmerrs.ErrExecutionReverted {
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
 			remainingGas = 0
 		}
 		// TODO: consider clearing up unused snapshots:
@@ -4,32 +4,109 @@
 package vm
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/coreth/constants"
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsProhibited(t *testing.T) {
+	rules := params.TestChainConfig.Rules(common.Big0, 0)
+
 	// reserved addresses
-	assert.True(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000000")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000010")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x01000000000000000000000000000000000000f0")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x01000000000000000000000000000000000000ff")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000000")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000010")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x02000000000000000000000000000000000000f0")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x02000000000000000000000000000000000000ff")))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000000"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000010"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x01000000000000000000000000000000000000f0"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x01000000000000000000000000000000000000ff"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000000"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000010"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x02000000000000000000000000000000000000f0"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x02000000000000000000000000000000000000ff"), rules))
 	// reserved addresses (custom precompiles)
-	assert.True(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000000")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000010")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x03000000000000000000000000000000000000f0")))
-	assert.True(t, IsProhibited(common.HexToAddress("0x03000000000000000000000000000000000000ff")))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000000"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000010"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x03000000000000000000000000000000000000f0"), rules))
+	assert.True(t, IsProhibited(common.HexToAddress("0x03000000000000000000000000000000000000ff"), rules))
 
 	// allowed for use
-	assert.False(t, IsProhibited(common.HexToAddress("0x00000000000000000000000000000000000000ff")))
-	assert.False(t, IsProhibited(common.HexToAddress("0x00ffffffffffffffffffffffffffffffffffffff")))
-	assert.False(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000100")))
-	assert.False(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000100")))
-	assert.False(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000100")))
+	assert.False(t, IsProhibited(common.HexToAddress("0x00000000000000000000000000000000000000ff"), rules))
+	assert.False(t, IsProhibited(common.HexToAddress("0x00ffffffffffffffffffffffffffffffffffffff"), rules))
+	assert.False(t, IsProhibited(common.HexToAddress("0x0100000000000000000000000000000000000100"), rules))
+	assert.False(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000100"), rules))
+	assert.False(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000100"), rules))
+
+	// the storage last modified tracker is only prohibited once it is activated
+	assert.False(t, IsProhibited(constants.StorageLastModifiedAddr, rules))
+	rules.IsStorageLastModified = true
+	assert.True(t, IsProhibited(constants.StorageLastModifiedAddr, rules))
+}
+
+func TestCancelAfter(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	loopAddr := common.HexToAddress("0xaa")
+	statedb.SetCode(loopAddr, common.Hex2Bytes("5b600056")) // JUMPDEST PUSH1 0 JUMP
+
+	vmCtx := BlockContext{
+		BlockNumber: big.NewInt(0),
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+	}
+	evm := NewEVM(vmCtx, TxContext{}, statedb, params.TestChainConfig, Config{})
+
+	timeout := 50 * time.Millisecond
+	defer evm.CancelAfter(timeout)()
+	start := time.Now()
+	_, _, err = evm.Call(AccountRef(common.Address{}), loopAddr, nil, 1<<50, big.NewInt(0))
+	require.Less(time.Since(start), 100*timeout)
+	require.True(evm.Cancelled())
+	require.True(evm.TimedOut())
+	require.NoError(err) // The interpreter stops without error when cancelled
+
+	// No further calls are made from within a contract once the EVM is
+	// cancelled, including calls to precompiles.
+	evm.depth = 1
+	_, gas, err := evm.Call(AccountRef(common.Address{}), common.BytesToAddress([]byte{4}), nil, 100, big.NewInt(0))
+	require.ErrorIs(err, vmerrs.ErrExecutionAborted)
+	require.Equal(uint64(100), gas)
+}
+
+func TestCancelAfterStopped(t *testing.T) {
+	require := require.New(t)
+
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, nil, params.TestChainConfig, Config{})
+	stop := evm.CancelAfter(time.Hour)
+	require.True(stop())
+	require.False(evm.Cancelled())
+	require.False(evm.TimedOut())
+}
+
+func TestPrecompileStateDBSubBalance(t *testing.T) {
+	require := require.New(t)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	addr := common.Address{1}
+	statedb.AddBalance(addr, big.NewInt(10))
+
+	// Subtracting the entire balance is allowed.
+	precompileState := evm.GetStateDB()
+	precompileState.SubBalance(addr, big.NewInt(10))
+	require.Zero(statedb.GetBalance(addr).Sign())
+
+	require.Panics(func() { precompileState.SubBalance(addr, big.NewInt(1)) })
+
+	// The StateDB used on the consensus path does not panic.
+	require.NotPanics(func() { statedb.SubBalance(addr, big.NewInt(1)) })
 }
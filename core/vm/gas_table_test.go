@@ -37,9 +37,11 @@ import (
 	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/utils"
 	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryGasCost(t *testing.T) {
@@ -120,6 +122,60 @@ func TestEIP2200(t *testing.T) {
 	}
 }
 
+func TestStorageLastModifiedGas(t *testing.T) {
+	tests := []struct {
+		name        string
+		original    byte
+		recordBlock uint64 // block in which the original value was written
+		input       string
+		recordGas   uint64
+	}{
+		{"create", 0, 0, "0x6001600055", params.SstoreSetGasEIP2200},                                                     // 0 -> 1
+		{"update", 1, 1, "0x6002600055", params.SstoreResetGasEIP2200},                                                   // 1 -> 2
+		{"delete", 1, 1, "0x6000600055", params.SstoreResetGasEIP2200},                                                   // 1 -> 0
+		{"noop", 1, 1, "0x6001600055", 0},                                                                                // 1 -> 1
+		{"same block", 1, 2, "0x6002600055", 0},                                                                          // 1 -> 2, already recorded
+		{"untracked delete", 1, 0, "0x6000600055", 0},                                                                    // 1 -> 0, never recorded
+		{"create twice", 0, 0, "0x60016000556002600055", params.SstoreSetGasEIP2200},                                     // 0 -> 1 -> 2
+		{"create and delete", 0, 0, "0x60016000556000600055", params.SstoreSetGasEIP2200 + params.SstoreResetGasEIP2200}, // 0 -> 1 -> 0
+	}
+	address := common.BytesToAddress([]byte("contract"))
+	run := func(t *testing.T, config *params.ChainConfig, original byte, recordBlock uint64, input string) uint64 {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(input))
+		if original != 0 {
+			if recordBlock != 0 {
+				statedb.Prepare(config.Rules(new(big.Int).SetUint64(recordBlock), 0), common.Address{}, common.Address{}, nil, nil, nil)
+			}
+			statedb.SStore(address, common.Hash{}, common.BytesToHash([]byte{original}))
+		}
+		statedb.Finalise(true) // Push the state into the "original" slot
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(2),
+		}
+		statedb.Prepare(config.Rules(vmctx.BlockNumber, 0), common.Address{}, common.Address{}, &address, nil, nil)
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, math.MaxUint64, new(big.Int))
+		require.NoError(t, err)
+		return math.MaxUint64 - gas
+	}
+
+	tracking := *params.TestChainConfig
+	tracking.StorageLastModifiedTimestamp = utils.NewUint64(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := run(t, params.TestChainConfig, tt.original, 0, tt.input)
+			used := run(t, &tracking, tt.original, tt.recordBlock, tt.input)
+			require.Equal(t, tt.recordGas, used-base)
+		})
+	}
+}
+
 var createGasTests = []struct {
 	code       string
 	eip3860    bool
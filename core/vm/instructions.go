@@ -539,11 +539,11 @@ func opSload(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]by
 
 func opSstore(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(SSTORE)}
 	}
 	loc := scope.Stack.pop()
 	val := scope.Stack.pop()
-	interpreter.evm.StateDB.SetState(scope.Contract.Address(), loc.Bytes32(), val.Bytes32())
+	interpreter.evm.StateDB.SStore(scope.Contract.Address(), loc.Bytes32(), val.Bytes32())
 	return nil, nil
 }
 
@@ -594,7 +594,7 @@ func opGas(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte
 
 func opCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(CREATE)}
 	}
 	var (
 		value        = scope.Stack.pop()
@@ -630,7 +630,7 @@ func opCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]b
 	scope.Stack.push(&stackvalue)
 	scope.Contract.Gas += returnGas
 
-	if suberr == vmerrs.ErrExecutionReverted {
+	if errors.Is(suberr, vmerrs.ErrExecutionReverted) {
 		interpreter.returnData = res // set REVERT data to return data buffer
 		return res, nil
 	}
@@ -640,7 +640,7 @@ func opCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]b
 
 func opCreate2(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(CREATE2)}
 	}
 	var (
 		endowment    = scope.Stack.pop()
@@ -670,7 +670,7 @@ func opCreate2(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]
 	scope.Stack.push(&stackvalue)
 	scope.Contract.Gas += returnGas
 
-	if suberr == vmerrs.ErrExecutionReverted {
+	if errors.Is(suberr, vmerrs.ErrExecutionReverted) {
 		interpreter.returnData = res // set REVERT data to return data buffer
 		return res, nil
 	}
@@ -691,7 +691,7 @@ func opCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byt
 	args := scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
 
 	if interpreter.readOnly && !value.IsZero() {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(CALL)}
 	}
 	var bigVal = big0
 	//TODO: use uint256.Int instead of converting with toBig()
@@ -709,7 +709,7 @@ func opCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byt
 		temp.SetOne()
 	}
 	stack.push(&temp)
-	if err == nil || err == vmerrs.ErrExecutionReverted {
+	if err == nil || errors.Is(err, vmerrs.ErrExecutionReverted) {
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 	scope.Contract.Gas += returnGas
@@ -735,7 +735,7 @@ func opCallExpert(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext)
 	// Note: this code fails to check that value2 is zero, which was a bug when CALLEX was active.
 	// The CALLEX opcode was de-activated in ApricotPhase2 resolving this issue.
 	if interpreter.readOnly && !value.IsZero() {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(CALLEX)}
 	}
 	var bigVal = big0
 	//TODO: use uint256.Int instead of converting with toBig()
@@ -761,7 +761,7 @@ func opCallExpert(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext)
 		temp.SetOne()
 	}
 	stack.push(&temp)
-	if err == nil || err == vmerrs.ErrExecutionReverted {
+	if err == nil || errors.Is(err, vmerrs.ErrExecutionReverted) {
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 	scope.Contract.Gas += returnGas
@@ -795,7 +795,7 @@ func opCallCode(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([
 		temp.SetOne()
 	}
 	stack.push(&temp)
-	if err == nil || err == vmerrs.ErrExecutionReverted {
+	if err == nil || errors.Is(err, vmerrs.ErrExecutionReverted) {
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 	scope.Contract.Gas += returnGas
@@ -823,7 +823,7 @@ func opDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 		temp.SetOne()
 	}
 	stack.push(&temp)
-	if err == nil || err == vmerrs.ErrExecutionReverted {
+	if err == nil || errors.Is(err, vmerrs.ErrExecutionReverted) {
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 	scope.Contract.Gas += returnGas
@@ -851,7 +851,7 @@ func opStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext)
 		temp.SetOne()
 	}
 	stack.push(&temp)
-	if err == nil || err == vmerrs.ErrExecutionReverted {
+	if err == nil || errors.Is(err, vmerrs.ErrExecutionReverted) {
 		scope.Memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
 	}
 	scope.Contract.Gas += returnGas
@@ -872,7 +872,7 @@ func opRevert(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]b
 	ret := scope.Memory.GetPtr(int64(offset.Uint64()), int64(size.Uint64()))
 
 	interpreter.returnData = ret
-	return ret, vmerrs.ErrExecutionReverted
+	return ret, &vmerrs.RevertError{Reason: ret}
 }
 
 func opUndefined(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
@@ -885,7 +885,7 @@ func opStop(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byt
 
 func opSelfdestruct(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(SELFDESTRUCT)}
 	}
 	beneficiary := scope.Stack.pop()
 	balance := interpreter.evm.StateDB.GetBalance(scope.Contract.Address())
@@ -900,7 +900,7 @@ func opSelfdestruct(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 
 func opSelfdestruct6780(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 	if interpreter.readOnly {
-		return nil, vmerrs.ErrWriteProtection
+		return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(SELFDESTRUCT)}
 	}
 	beneficiary := scope.Stack.pop()
 	balance := interpreter.evm.StateDB.GetBalance(scope.Contract.Address())
@@ -920,7 +920,7 @@ func opSelfdestruct6780(pc *uint64, interpreter *EVMInterpreter, scope *ScopeCon
 func makeLog(size int) executionFunc {
 	return func(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
 		if interpreter.readOnly {
-			return nil, vmerrs.ErrWriteProtection
+			return nil, vmerrs.ErrWriteProtectionWithOpcode{Opcode: byte(LOG0 + OpCode(size))}
 		}
 		topics := make([]common.Hash, size)
 		stack := scope.Stack
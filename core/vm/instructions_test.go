@@ -29,6 +29,7 @@ package vm
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -943,3 +944,47 @@ func TestOpMCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteProtectionOpcode(t *testing.T) {
+	tests := map[string]struct {
+		code   []byte
+		opcode OpCode
+	}{
+		"sstore": {
+			code:   []byte{byte(PUSH1), 1, byte(PUSH1), 0, byte(SSTORE)},
+			opcode: SSTORE,
+		},
+		"create": {
+			code:   []byte{byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(CREATE)},
+			opcode: CREATE,
+		},
+		"log2": {
+			code:   []byte{byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(LOG2)},
+			opcode: LOG2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+				env        = NewEVM(BlockContext{BlockNumber: big.NewInt(0)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+				caller     = common.Address{}
+				to         = common.HexToAddress("0xaa")
+			)
+			statedb.SetCode(to, test.code)
+			statedb.AddAddressToAccessList(to)
+
+			_, _, err := env.StaticCall(AccountRef(caller), to, nil, 100_000)
+			if !errors.Is(err, vmerrs.ErrWriteProtection) {
+				t.Fatalf("expected write protection error, got %v", err)
+			}
+			var writeErr vmerrs.ErrWriteProtectionWithOpcode
+			if !errors.As(err, &writeErr) {
+				t.Fatalf("expected ErrWriteProtectionWithOpcode, got %T", err)
+			}
+			if have := OpCode(writeErr.Opcode); have != test.opcode {
+				t.Fatalf("have opcode %v, want %v", have, test.opcode)
+			}
+		})
+	}
+}
@@ -41,6 +41,7 @@ type StateDB interface {
 	SubBalance(common.Address, *big.Int)
 	AddBalance(common.Address, *big.Int)
 	GetBalance(common.Address) *big.Int
+	GetTotalSupply(maxAccounts uint64) (*big.Int, uint64, bool)
 
 	SubBalanceMultiCoin(common.Address, common.Hash, *big.Int)
 	AddBalanceMultiCoin(common.Address, common.Hash, *big.Int)
@@ -62,6 +63,8 @@ type StateDB interface {
 	GetCommittedStateAP1(common.Address, common.Hash) common.Hash
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
+	SStore(common.Address, common.Hash, common.Hash)
+	GetLastModifiedBlock(addr common.Address, slot common.Hash) uint64
 
 	GetTransientState(addr common.Address, key common.Hash) common.Hash
 	SetTransientState(addr common.Address, key, value common.Hash)
@@ -97,6 +100,7 @@ type StateDB interface {
 	SetPredicateStorageSlots(address common.Address, predicates [][]byte)
 
 	GetTxHash() common.Hash
+	TxIndex() int
 
 	AddPreimage(common.Hash, []byte)
 }
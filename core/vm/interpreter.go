@@ -97,6 +97,10 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	default:
 		table = &frontierInstructionSet
 	}
+	if evm.chainRules.IsStorageLastModified {
+		table = copyJumpTable(table)
+		enableStorageLastModified(table)
+	}
 	var extraEips []int
 	if len(evm.Config.ExtraEips) > 0 {
 		// Deep-copy jumptable to prevent modification of opcodes in other tables
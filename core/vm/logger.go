@@ -51,3 +51,12 @@ type EVMLogger interface {
 	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error)
 	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
 }
+
+// PrecompileOpLogger is an optional interface for EVMLoggers to implement.
+// If implemented, CapturePrecompileOp is called for each sub-operation reported
+// by a stateful precompile implementing contract.ContractTracer, between the
+// capture of entering and exiting the call into the precompile. [depth] is the
+// depth of the precompile's call frame, where the top call frame has depth 1.
+type PrecompileOpLogger interface {
+	CapturePrecompileOp(depth int, label string, gasUsed uint64)
+}
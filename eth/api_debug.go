@@ -32,10 +32,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ava-labs/coreth/core"
 	"github.com/ava-labs/coreth/core/rawdb"
 	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/internal/ethapi"
+	"github.com/ava-labs/coreth/miner"
 	"github.com/ava-labs/coreth/rpc"
 	"github.com/ava-labs/coreth/trie"
 	"github.com/ethereum/go-ethereum/common"
@@ -371,3 +373,21 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	}
 	return 0, errors.New("no state found")
 }
+
+// TopGasConsumers returns the [limit] addresses whose transactions used the
+// most gas in the last [windowBlocks] accepted blocks. The gas used by each
+// transaction is attributed to its recipient, or to the contract it creates,
+// including the gas used by internal calls. Only the blocks accepted since the
+// node started are included, and [windowBlocks] is limited by the
+// gas-analytics-window config option.
+func (api *DebugAPI) TopGasConsumers(windowBlocks uint64, limit int) ([]core.ContractGasUsage, error) {
+	return api.eth.BlockChain().TopGasConsumers(windowBlocks, limit)
+}
+
+// BlockBuildingReport returns the reports of the last blocks built by this
+// node from the pending transactions, oldest first. Each report lists the
+// transactions included in the block and those skipped, with the reason they
+// were skipped.
+func (api *DebugAPI) BlockBuildingReport() []*miner.BlockBuildingReport {
+	return api.eth.Miner().BlockBuildingReports()
+}
@@ -0,0 +1,271 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/core/vm"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/predicate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// defaultExecutionContextMaxSize is the default maximum size of the
+	// execution context of a block returned by debug_executionContext, and
+	// maxExecutionContextMaxSize the maximum size which can be requested.
+	defaultExecutionContextMaxSize = 32 * 1024 * 1024
+	maxExecutionContextMaxSize     = 256 * 1024 * 1024
+)
+
+var (
+	errExecutionContextTooLarge = errors.New("execution context too large")
+	errNoParentBlock            = errors.New("genesis block has no parent")
+)
+
+// ExecutionContext is a self-contained bundle of the inputs used to execute
+// a block, sufficient to re-execute it without the rest of the chain and
+// obtain the state root of its header: the state of its parent block is
+// rebuilt from [StateNodes] and [Codes], and the headers looked up during the
+// execution, such as by the BLOCKHASH opcode, are in [Ancestors].
+type ExecutionContext struct {
+	ChainConfig *params.ChainConfig `json:"chainConfig"`
+	// Rules are the rules of [ChainConfig] active for the block.
+	Rules *ExecutionRules `json:"rules"`
+	// Block is the RLP encoding of the block.
+	Block     hexutil.Bytes   `json:"block"`
+	Parent    *types.Header   `json:"parent"`
+	Ancestors []*types.Header `json:"ancestors"`
+	// PredicateResults are the results of the predicates of the transactions
+	// of the block, which are also encoded in its header.
+	PredicateResults map[common.Hash]map[common.Address]hexutil.Bytes `json:"predicateResults,omitempty"`
+	// ProposerPChainHeight is the P-Chain height proposed with the block, if
+	// the node verified it with its proposer context. It is only used to
+	// verify the predicates of the block, not to execute it.
+	ProposerPChainHeight *hexutil.Uint64 `json:"proposerPChainHeight,omitempty"`
+	// StateNodes are the rlp-encoded trie nodes of the state of the parent
+	// block read or modified while executing the block, and Codes the
+	// contract codes read.
+	StateNodes []hexutil.Bytes `json:"stateNodes"`
+	Codes      []hexutil.Bytes `json:"codes"`
+}
+
+// ExecutionRules are the rules of a chain config active for a block. The
+// precompiles are identified by their addresses, which replace the maps of
+// Rules in the JSON encoding, as their configs are in the chain config.
+type ExecutionRules struct {
+	params.Rules
+	ActivePrecompiles   []common.Address
+	Predicaters         []common.Address
+	AccepterPrecompiles []common.Address
+}
+
+// ExecutionContextConfig are the options of debug_executionContext.
+type ExecutionContextConfig struct {
+	// MaxSize is the maximum size in bytes of the execution context, after
+	// compression if [Compress] is set. It defaults to 32 MiB, and is at most
+	// 256 MiB.
+	MaxSize *uint64 `json:"maxSize"`
+	// Compress returns the execution context compressed with gzip.
+	Compress bool `json:"compress"`
+}
+
+// ExecutionContextResult is the result of debug_executionContext, which
+// contains the execution context either as is or compressed.
+type ExecutionContextResult struct {
+	Context *ExecutionContext `json:"context,omitempty"`
+	// Compressed is the JSON encoding of the execution context compressed
+	// with gzip.
+	Compressed hexutil.Bytes `json:"compressed,omitempty"`
+	// Size is the size of the JSON encoding of the execution context, before
+	// compression.
+	Size hexutil.Uint64 `json:"size"`
+}
+
+// ExecutionContext returns the inputs used to execute the block with [hash],
+// whose parent state must be available, so that it can be re-executed off the
+// node.
+func (api *DebugAPI) ExecutionContext(hash common.Hash, config *ExecutionContextConfig) (*ExecutionContextResult, error) {
+	ec, err := newExecutionContext(api.eth.blockchain, api.eth.ProposerPChainHeight, hash)
+	if err != nil {
+		return nil, err
+	}
+	return encodeExecutionContext(ec, config)
+}
+
+// executionContextChain looks up headers in a BlockChain, recording the
+// headers looked up.
+type executionContextChain struct {
+	*core.BlockChain
+	headers map[common.Hash]*types.Header
+}
+
+func (c *executionContextChain) record(header *types.Header) *types.Header {
+	if header != nil {
+		c.headers[header.Hash()] = header
+	}
+	return header
+}
+
+func (c *executionContextChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return c.record(c.BlockChain.GetHeader(hash, number))
+}
+
+func (c *executionContextChain) GetHeaderByNumber(number uint64) *types.Header {
+	return c.record(c.BlockChain.GetHeaderByNumber(number))
+}
+
+func (c *executionContextChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.record(c.BlockChain.GetHeaderByHash(hash))
+}
+
+// newExecutionContext re-executes the block with [hash] on top of the state
+// of its parent in [bc] to collect its execution context.
+func newExecutionContext(bc *core.BlockChain, proposerPChainHeight func(common.Hash) (uint64, bool), hash common.Hash) (*ExecutionContext, error) {
+	block := bc.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", hash)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errNoParentBlock
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x of block %#x not found", block.ParentHash(), hash)
+	}
+
+	// The state is read without snapshot, so that every account and storage
+	// slot is read through the tries and recorded.
+	db := state.NewWitnessDatabase(bc.StateCache())
+	statedb, err := state.New(parent.Root, db, nil)
+	if err != nil {
+		return nil, fmt.Errorf("state of parent %#x of block %#x not available: %w", parent.Hash(), hash, err)
+	}
+	config := bc.Config()
+	chain := &executionContextChain{
+		BlockChain: bc,
+		headers:    make(map[common.Hash]*types.Header),
+	}
+	if _, _, _, err := core.ExecuteBlock(config, chain, bc.Engine(), block, parent, statedb, vm.Config{}); err != nil {
+		return nil, fmt.Errorf("failed to execute block %#x: %w", hash, err)
+	}
+	if root := statedb.IntermediateRoot(config.IsEIP158(block.Number())); root != block.Root() {
+		return nil, fmt.Errorf("executing block %#x resulted in state root %#x, expected %#x", hash, root, block.Root())
+	}
+
+	blockBytes, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		return nil, err
+	}
+	rules := config.Rules(block.Number(), block.Time())
+	ec := &ExecutionContext{
+		ChainConfig: config,
+		Rules: &ExecutionRules{
+			Rules:               rules,
+			ActivePrecompiles:   sortedAddresses(rules.ActivePrecompiles),
+			Predicaters:         sortedAddresses(rules.Predicaters),
+			AccepterPrecompiles: sortedAddresses(rules.AccepterPrecompiles),
+		},
+		Block:     blockBytes,
+		Parent:    parent,
+		Ancestors: make([]*types.Header, 0, len(chain.headers)),
+	}
+	for headerHash, header := range chain.headers {
+		if headerHash != parent.Hash() {
+			ec.Ancestors = append(ec.Ancestors, header)
+		}
+	}
+	slices.SortFunc(ec.Ancestors, func(a, b *types.Header) int {
+		return a.Number.Cmp(b.Number)
+	})
+	if resultBytes, ok := predicate.GetPredicateResultBytes(block.Extra()); ok {
+		results, err := predicate.ParseResults(resultBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse predicate results of block %#x: %w", hash, err)
+		}
+		ec.PredicateResults = make(map[common.Hash]map[common.Address]hexutil.Bytes, len(results.Results))
+		for txHash, txResults := range results.Results {
+			ec.PredicateResults[txHash] = make(map[common.Address]hexutil.Bytes, len(txResults))
+			for addr, result := range txResults {
+				ec.PredicateResults[txHash][addr] = result
+			}
+		}
+	}
+	if height, ok := proposerPChainHeight(hash); ok {
+		ec.ProposerPChainHeight = (*hexutil.Uint64)(&height)
+	}
+	nodes, codes := db.Witness()
+	ec.StateNodes = make([]hexutil.Bytes, len(nodes))
+	for i, node := range nodes {
+		ec.StateNodes[i] = node
+	}
+	ec.Codes = make([]hexutil.Bytes, len(codes))
+	for i, code := range codes {
+		ec.Codes[i] = code
+	}
+	return ec, nil
+}
+
+// encodeExecutionContext returns [ec] as requested by [config], or an error
+// if it exceeds the maximum size.
+func encodeExecutionContext(ec *ExecutionContext, config *ExecutionContextConfig) (*ExecutionContextResult, error) {
+	if config == nil {
+		config = &ExecutionContextConfig{}
+	}
+	maxSize := uint64(defaultExecutionContextMaxSize)
+	if config.MaxSize != nil {
+		maxSize = *config.MaxSize
+	}
+	if maxSize > maxExecutionContextMaxSize {
+		return nil, fmt.Errorf("maximum size %d exceeds %d", maxSize, maxExecutionContextMaxSize)
+	}
+
+	encoded, err := json.Marshal(ec)
+	if err != nil {
+		return nil, err
+	}
+	result := &ExecutionContextResult{Size: hexutil.Uint64(len(encoded))}
+	if !config.Compress {
+		if uint64(len(encoded)) > maxSize {
+			return nil, fmt.Errorf("%w: %d bytes exceeds %d, try compressing it", errExecutionContextTooLarge, len(encoded), maxSize)
+		}
+		result.Context = ec
+		return result, nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(encoded); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if uint64(buf.Len()) > maxSize {
+		return nil, fmt.Errorf("%w: %d compressed bytes exceeds %d", errExecutionContextTooLarge, buf.Len(), maxSize)
+	}
+	result.Compressed = buf.Bytes()
+	return result, nil
+}
+
+// sortedAddresses returns the keys of [m] sorted.
+func sortedAddresses[V any](m map[common.Address]V) []common.Address {
+	addrs := make([]common.Address, 0, len(m))
+	for addr := range m {
+		addrs = append(addrs, addr)
+	}
+	slices.SortFunc(addrs, common.Address.Cmp)
+	return addrs
+}
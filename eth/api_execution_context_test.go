@@ -0,0 +1,205 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/consensus"
+	"github.com/ava-labs/coreth/consensus/dummy"
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/core/vm"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+// bundleChain serves the headers of an ExecutionContext.
+type bundleChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	parent  *types.Header
+	headers map[common.Hash]*types.Header
+}
+
+func newBundleChain(ec *ExecutionContext, engine consensus.Engine) *bundleChain {
+	c := &bundleChain{
+		config:  ec.ChainConfig,
+		engine:  engine,
+		parent:  ec.Parent,
+		headers: map[common.Hash]*types.Header{ec.Parent.Hash(): ec.Parent},
+	}
+	for _, header := range ec.Ancestors {
+		c.headers[header.Hash()] = header
+	}
+	return c
+}
+
+func (c *bundleChain) Config() *params.ChainConfig  { return c.config }
+func (c *bundleChain) Engine() consensus.Engine     { return c.engine }
+func (c *bundleChain) CurrentHeader() *types.Header { return c.parent }
+
+func (c *bundleChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *bundleChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+func (c *bundleChain) GetHeaderByNumber(number uint64) *types.Header {
+	for _, header := range c.headers {
+		if header.Number.Uint64() == number {
+			return header
+		}
+	}
+	return nil
+}
+
+// reexecute executes the block of [ec] from a database containing only the
+// state nodes and codes of [ec], and returns the resulting state root.
+func reexecute(t *testing.T, ec *ExecutionContext, engine consensus.Engine) common.Hash {
+	require := require.New(t)
+
+	db := rawdb.NewMemoryDatabase()
+	for _, node := range ec.StateNodes {
+		rawdb.WriteLegacyTrieNode(db, crypto.Keccak256Hash(node), node)
+	}
+	for _, code := range ec.Codes {
+		rawdb.WriteCode(db, crypto.Keccak256Hash(code), code)
+	}
+	statedb, err := state.New(ec.Parent.Root, state.NewDatabase(db), nil)
+	require.NoError(err)
+
+	block := new(types.Block)
+	require.NoError(rlp.DecodeBytes(ec.Block, block))
+	_, _, _, err = core.ExecuteBlock(ec.ChainConfig, newBundleChain(ec, engine), engine, block, ec.Parent, statedb, vm.Config{})
+	require.NoError(err)
+	return statedb.IntermediateRoot(ec.ChainConfig.IsEIP158(block.Number()))
+}
+
+func TestExecutionContext(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc0}
+		other    = common.Address{0xc1}
+		// The contract stores the hash of the block 3 blocks before the
+		// current one in slot 0, increments slot 1, stores the code size of
+		// [other] in slot 2 and clears slot 3.
+		code  = common.FromHex("436003900340600055600154600101600155" + "73" + other.Hex()[2:] + "3b600255600060035500")
+		alloc = core.GenesisAlloc{
+			addr:  {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+			other: {Balance: common.Big0, Code: []byte{0x60, 0x00, 0x00}},
+		}
+	)
+	storage := make(map[common.Hash]common.Hash)
+	for i := byte(1); i <= 8; i++ {
+		storage[common.Hash{31: i}] = common.Hash{31: i}
+	}
+	alloc[contract] = core.GenesisAccount{Balance: common.Big0, Code: code, Storage: storage}
+	for i := 0; i < 64; i++ {
+		alloc[common.Address{0xaa, byte(i)}] = core.GenesisAccount{Balance: big.NewInt(int64(i + 1))}
+	}
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  alloc,
+	}
+	engine := dummy.NewETHFaker()
+	signer := types.LatestSigner(gspec.Config)
+	_, blocks, _, err := core.GenerateChainWithGenesis(gspec, engine, 4, 10, func(i int, gen *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    gen.TxNonce(addr),
+			GasPrice: gen.BaseFee(),
+			Gas:      200_000,
+			To:       &contract,
+		}), signer, key)
+		require.NoError(err)
+		gen.AddTx(tx)
+		tx, err = types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    gen.TxNonce(addr),
+			GasPrice: gen.BaseFee(),
+			Gas:      params.TxGas,
+			To:       &common.Address{0xbb, byte(i)},
+			Value:    big.NewInt(1),
+		}), signer, key)
+		require.NoError(err)
+		gen.AddTx(tx)
+	})
+	require.NoError(err)
+	bc, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfig, gspec, engine, vm.Config{}, common.Hash{}, false)
+	require.NoError(err)
+	defer bc.Stop()
+	_, err = bc.InsertChain(blocks)
+	require.NoError(err)
+
+	noHeight := func(common.Hash) (uint64, bool) { return 0, false }
+	block := blocks[3]
+	ec, err := newExecutionContext(bc, noHeight, block.Hash())
+	require.NoError(err)
+	require.Equal(blocks[2].Hash(), ec.Parent.Hash())
+	// The header of block 2 is looked up to get the hash of block 1.
+	require.Len(ec.Ancestors, 1)
+	require.Equal(blocks[1].Hash(), ec.Ancestors[0].Hash())
+	require.Contains(ec.Codes, hexutil.Bytes(code))
+	require.Nil(ec.ProposerPChainHeight)
+
+	// The block is re-executed from the decoded bundle only.
+	result, err := encodeExecutionContext(ec, nil)
+	require.NoError(err)
+	encoded, err := json.Marshal(result)
+	require.NoError(err)
+	var decoded ExecutionContextResult
+	require.NoError(json.Unmarshal(encoded, &decoded))
+	require.Equal(block.Root(), reexecute(t, decoded.Context, engine))
+
+	// The bundle can be compressed.
+	result, err = encodeExecutionContext(ec, &ExecutionContextConfig{Compress: true})
+	require.NoError(err)
+	require.Nil(result.Context)
+	require.Less(len(result.Compressed), int(result.Size))
+	r, err := gzip.NewReader(bytes.NewReader(result.Compressed))
+	require.NoError(err)
+	uncompressed, err := io.ReadAll(r)
+	require.NoError(err)
+	require.Len(uncompressed, int(result.Size))
+	decompressed := new(ExecutionContext)
+	require.NoError(json.Unmarshal(uncompressed, decompressed))
+	require.Equal(block.Root(), reexecute(t, decompressed, engine))
+
+	// The bundle is refused if it exceeds the maximum size.
+	maxSize := uint64(len(result.Compressed)) - 1
+	_, err = encodeExecutionContext(ec, &ExecutionContextConfig{Compress: true, MaxSize: &maxSize})
+	require.ErrorIs(err, errExecutionContextTooLarge)
+	maxSize = uint64(result.Size) - 1
+	_, err = encodeExecutionContext(ec, &ExecutionContextConfig{MaxSize: &maxSize})
+	require.ErrorIs(err, errExecutionContextTooLarge)
+
+	// The proposed P-Chain height is included if it is known.
+	ec, err = newExecutionContext(bc, func(hash common.Hash) (uint64, bool) {
+		return 7, hash == block.Hash()
+	}, block.Hash())
+	require.NoError(err)
+	require.NotNil(ec.ProposerPChainHeight)
+	require.EqualValues(7, *ec.ProposerPChainHeight)
+
+	_, err = newExecutionContext(bc, noHeight, bc.Genesis().Hash())
+	require.ErrorIs(err, errNoParentBlock)
+}
@@ -0,0 +1,22 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+// MinerAPI provides an API to control the block building of the node.
+type MinerAPI struct {
+	e *Ethereum
+}
+
+// NewMinerAPI creates a new MinerAPI instance.
+func NewMinerAPI(e *Ethereum) *MinerAPI {
+	return &MinerAPI{e}
+}
+
+// SetPrioritizeLocals sets whether the transactions of local senders are
+// added to the blocks built by the node before those of remote senders,
+// regardless of their tips.
+func (api *MinerAPI) SetPrioritizeLocals(prioritize bool) bool {
+	api.e.Miner().SetPrioritizeLocals(prioritize)
+	return true
+}
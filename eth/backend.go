@@ -104,6 +104,10 @@ type Ethereum struct {
 	miner     *miner.Miner
 	etherbase common.Address
 
+	// proposerPChainHeights returns the P-Chain height proposed with a
+	// block, if it is known.
+	proposerPChainHeights func(blockHash common.Hash) (uint64, bool)
+
 	networkID     uint64
 	netRPCService *ethapi.NetAPI
 
@@ -227,6 +231,10 @@ func New(
 			SkipTxIndexing:                  config.SkipTxIndexing,
 			StateHistory:                    config.StateHistory,
 			StateScheme:                     scheme,
+			HotContracts:                    config.HotContracts,
+			HotContractsCheckInterval:       config.HotContractsCheckInterval,
+			GasAnalyticsWindow:              config.GasAnalyticsWindow,
+			GasAnalyticsMetricsTopN:         config.GasAnalyticsMetricsTopN,
 		}
 	)
 
@@ -320,6 +328,10 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "admin",
 			Service:   NewAdminAPI(s),
 			Name:      "admin",
+		}, {
+			Namespace: "miner",
+			Service:   NewMinerAPI(s),
+			Name:      "miner",
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(s),
@@ -352,6 +364,29 @@ func (s *Ethereum) SetEtherbase(etherbase common.Address) {
 	s.miner.SetEtherbase(etherbase)
 }
 
+// SetProposerPChainHeights sets [f] to return the P-Chain height proposed
+// with a block, which the node only knows for the blocks it verified with
+// their proposer context.
+func (s *Ethereum) SetProposerPChainHeights(f func(blockHash common.Hash) (uint64, bool)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.proposerPChainHeights = f
+}
+
+// ProposerPChainHeight returns the P-Chain height proposed with the block
+// with [blockHash], if it is known.
+func (s *Ethereum) ProposerPChainHeight(blockHash common.Hash) (uint64, bool) {
+	s.lock.RLock()
+	f := s.proposerPChainHeights
+	s.lock.RUnlock()
+
+	if f == nil {
+		return 0, false
+	}
+	return f(blockHash)
+}
+
 func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 
 func (s *Ethereum) AccountManager() *accounts.Manager { return s.accountManager }
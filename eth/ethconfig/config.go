@@ -68,6 +68,7 @@ func NewDefaultConfig() Config {
 		BlobPool:                  blobpool.DefaultConfig,
 		RPCGasCap:                 25000000,
 		RPCEVMTimeout:             5 * time.Second,
+		RPCEVMExecutionTimeout:    2 * time.Second,
 		GPO:                       DefaultFullGPOConfig,
 		RPCTxFeeCap:               1, // 1 AVAX
 	}
@@ -130,6 +131,11 @@ type Config struct {
 	// RPCEVMTimeout is the global timeout for eth-call.
 	RPCEVMTimeout time.Duration
 
+	// RPCEVMExecutionTimeout is the timeout for a single EVM execution within
+	// an eth-call or gas estimation. Trace calls are bounded by the trace
+	// timeout instead.
+	RPCEVMExecutionTimeout time.Duration
+
 	// RPCTxFeeCap is the global transaction fee(price * gaslimit) cap for
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64 `toml:",omitempty"`
@@ -175,4 +181,19 @@ type Config struct {
 	// This is useful for validators that don't need to index transactions.
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool
+
+	// HotContracts are contracts whose storage is copied into a flat key-value
+	// layout, updated on accept, to serve storage reads without trie traversal.
+	HotContracts []common.Address
+	// HotContractsCheckInterval is the number of hot contract storage reads
+	// between checks of a read value against the trie (0 disables the checks).
+	HotContractsCheckInterval uint64
+
+	// GasAnalyticsWindow is the number of recently accepted blocks over which
+	// the gas used by the transactions sent to each address is accumulated
+	// (0 disables the accumulation).
+	GasAnalyticsWindow uint64
+	// GasAnalyticsMetricsTopN is the number of addresses using the most gas
+	// over the window to export metrics for.
+	GasAnalyticsMetricsTopN int
 }
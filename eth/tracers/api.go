@@ -46,6 +46,7 @@ import (
 	"github.com/ava-labs/coreth/internal/ethapi"
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/rpc"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -233,8 +234,33 @@ type blockTraceResult struct {
 // txTraceTask represents a single transaction trace task when an entire block
 // is being traced.
 type txTraceTask struct {
-	statedb *state.StateDB // Intermediate state prepped for tracing
-	index   int            // Transaction offset in the block
+	statedb      *state.StateDB // Intermediate state prepped for tracing
+	index        int            // Transaction offset in the block
+	gasRemaining uint64         // Gas remaining in the block before the transaction
+}
+
+// blockGas tracks the gas remaining in a block while its transactions are
+// replayed. Each transaction buys gas from its own pool, so that replaying or
+// tracing one transaction can not cause the next one to fail, while
+// precompiles are reported the gas remaining in the block as they are when
+// the block is processed.
+type blockGas struct {
+	remaining uint64
+}
+
+// pool returns a new gas pool for [msg], and sets the gas remaining in the
+// block reported by [vmctx] to the gas remaining once the gas limit of [msg]
+// is reserved.
+func (b *blockGas) pool(vmctx *vm.BlockContext, msg *core.Message) *core.GasPool {
+	remaining := b.remaining - min(b.remaining, msg.GasLimit)
+	vmctx.BlockGasRemaining = func() uint64 { return remaining }
+	return new(core.GasPool).AddGas(msg.GasLimit)
+}
+
+// used deducts the gas used by [msg], as left in [gp] by applying it, from
+// the gas remaining in the block.
+func (b *blockGas) used(msg *core.Message, gp *core.GasPool) {
+	b.remaining -= min(b.remaining, msg.GasLimit-gp.Gas())
 }
 
 // TraceChain returns the structured logs created during the execution of EVM
@@ -299,6 +325,7 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 				var (
 					signer   = types.MakeSigner(api.backend.ChainConfig(), task.block.Number(), task.block.Time())
 					blockCtx = core.NewEVMBlockContext(task.block.Header(), api.chainContext(ctx), nil)
+					gas      = blockGas{remaining: task.block.GasLimit()}
 				)
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
@@ -309,12 +336,14 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 						TxIndex:     i,
 						TxHash:      tx.Hash(),
 					}
-					res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
+					gp := gas.pool(&blockCtx, msg)
+					res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, gp, config)
 					if err != nil {
 						task.results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
 						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
 						break
 					}
+					gas.used(msg, gp)
 					// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
 					task.statedb.Finalise(api.backend.ChainConfig().IsEIP158(task.block.Number()))
 					task.results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
@@ -562,6 +591,7 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
 		chainConfig        = api.backend.ChainConfig()
 		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		gas                = blockGas{remaining: block.GasLimit()}
 		deleteEmptyObjects = chainConfig.IsEIP158(block.Number())
 	)
 	for i, tx := range block.Transactions() {
@@ -571,10 +601,11 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		var (
 			msg, _    = core.TransactionToMessage(tx, signer, block.BaseFee())
 			txContext = core.NewEVMTxContext(msg)
+			gp        = gas.pool(&vmctx, msg)
 			vmenv     = vm.NewEVM(vmctx, txContext, statedb, chainConfig, vm.Config{})
 		)
 		statedb.SetTxContext(tx.Hash(), i)
-		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+		if _, err := core.ApplyMessage(vmenv, msg, gp); err != nil {
 			log.Warn("Tracing intermediate roots did not complete", "txindex", i, "txhash", tx.Hash(), "err", err)
 			// We intentionally don't return the error here: if we do, then the RPC server will not
 			// return the roots. Most likely, the caller already knows that a certain transaction fails to
@@ -584,6 +615,7 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 			// N.B: This should never happen while tracing canon blocks, only when tracing bad blocks.
 			return roots, nil
 		}
+		gas.used(msg, gp)
 		// calling IntermediateRoot will internally call Finalize on the state
 		// so any modifications are written to the trie
 		roots = append(roots, statedb.IntermediateRoot(deleteEmptyObjects))
@@ -648,6 +680,7 @@ func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *
 		blockHash = block.Hash()
 		is158     = api.backend.ChainConfig().IsEIP158(block.Number())
 		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		gas       = blockGas{remaining: block.GasLimit()}
 		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
 		results   = make([]*txTraceResult, len(txs))
 	)
@@ -660,10 +693,12 @@ func (api *baseAPI) traceBlock(ctx context.Context, block *types.Block, config *
 			TxIndex:     i,
 			TxHash:      tx.Hash(),
 		}
-		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
+		gp := gas.pool(&blockCtx, msg)
+		res, err := api.traceTx(ctx, msg, txctx, blockCtx, statedb, gp, config)
 		if err != nil {
 			return nil, err
 		}
+		gas.used(msg, gp)
 		results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
@@ -681,6 +716,7 @@ func (api *baseAPI) traceBlockParallel(ctx context.Context, block *types.Block,
 		txs       = block.Transactions()
 		blockHash = block.Hash()
 		blockCtx  = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		gas       = blockGas{remaining: block.GasLimit()}
 		signer    = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
 		results   = make([]*txTraceResult, len(txs))
 		pend      sync.WaitGroup
@@ -703,7 +739,12 @@ func (api *baseAPI) traceBlockParallel(ctx context.Context, block *types.Block,
 					TxIndex:     task.index,
 					TxHash:      txs[task.index].Hash(),
 				}
-				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
+				var (
+					taskGas = blockGas{remaining: task.gasRemaining}
+					taskCtx = blockCtx
+					gp      = taskGas.pool(&taskCtx, msg)
+				)
+				res, err := api.traceTx(ctx, msg, txctx, taskCtx, task.statedb, gp, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
 					continue
@@ -718,7 +759,7 @@ func (api *baseAPI) traceBlockParallel(ctx context.Context, block *types.Block,
 txloop:
 	for i, tx := range txs {
 		// Send the trace task over for execution
-		task := &txTraceTask{statedb: statedb.Copy(), index: i}
+		task := &txTraceTask{statedb: statedb.Copy(), index: i, gasRemaining: gas.remaining}
 		select {
 		case <-ctx.Done():
 			failed = ctx.Err()
@@ -729,11 +770,14 @@ txloop:
 		// Generate the next state snapshot fast without tracing
 		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
 		statedb.SetTxContext(tx.Hash(), i)
-		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
-		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+		execCtx := blockCtx
+		gp := gas.pool(&execCtx, msg)
+		vmenv := vm.NewEVM(execCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
+		if _, err := core.ApplyMessage(vmenv, msg, gp); err != nil {
 			failed = err
 			break txloop
 		}
+		gas.used(msg, gp)
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
 		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
@@ -793,6 +837,7 @@ func (api *FileTracerAPI) standardTraceBlockToFile(ctx context.Context, block *t
 		signer      = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
 		chainConfig = api.backend.ChainConfig()
 		vmctx       = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		gas         = blockGas{remaining: block.GasLimit()}
 		canon       = true
 	)
 	// Check if there are any overrides: the caller may wish to enable a future
@@ -835,9 +880,10 @@ func (api *FileTracerAPI) standardTraceBlockToFile(ctx context.Context, block *t
 			}
 		}
 		// Execute the transaction and flush any traces to disk
+		gp := gas.pool(&vmctx, msg)
 		vmenv := vm.NewEVM(vmctx, txContext, statedb, chainConfig, vmConf)
 		statedb.SetTxContext(tx.Hash(), i)
-		_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
+		_, err = core.ApplyMessage(vmenv, msg, gp)
 		if writer != nil {
 			writer.Flush()
 		}
@@ -848,6 +894,7 @@ func (api *FileTracerAPI) standardTraceBlockToFile(ctx context.Context, block *t
 		if err != nil {
 			return dumps, err
 		}
+		gas.used(msg, gp)
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
 		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
@@ -906,7 +953,9 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		TxIndex:     int(index),
 		TxHash:      hash,
 	}
-	return api.traceTx(ctx, msg, txctx, vmctx, statedb, config)
+	gas := blockGas{remaining: vmctx.GetBlockGasRemaining()}
+	gp := gas.pool(&vmctx, msg)
+	return api.traceTx(ctx, msg, txctx, vmctx, statedb, gp, config)
 }
 
 // TraceCall lets you trace a given eth_call. It collects the structured logs
@@ -959,6 +1008,7 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	defer release()
 
 	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	vmctx.BlockGasRemaining = core.SimulatedBlockGasRemaining(api.backend.RPCGasCap())
 	// Apply the customization rules if required.
 	if config != nil {
 		originalTime := block.Time()
@@ -984,13 +1034,14 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	if config != nil {
 		traceConfig = &config.TraceConfig
 	}
-	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, traceConfig)
+	gp := new(core.GasPool).AddGas(msg.GasLimit)
+	return api.traceTx(ctx, msg, new(Context), vmctx, statedb, gp, traceConfig)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
-// executes the given message in the provided environment. The return value will
-// be tracer dependent.
-func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+// executes the given message in the provided environment, buying gas from [gp].
+// The return value will be tracer dependent.
+func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, gp *core.GasPool, config *TraceConfig) (interface{}, error) {
 	var (
 		tracer    Tracer
 		err       error
@@ -1020,7 +1071,7 @@ func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *C
 	go func() {
 		<-deadlineCtx.Done()
 		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
-			tracer.Stop(errors.New("execution timeout"))
+			tracer.Stop(vmerrs.ErrExecutionTimeout)
 			// Stop evm execution. Note cancellation is not necessarily immediate.
 			vmenv.Cancel()
 		}
@@ -1029,7 +1080,7 @@ func (api *baseAPI) traceTx(ctx context.Context, message *core.Message, txctx *C
 
 	// Call Prepare to clear out the statedb access list
 	statedb.SetTxContext(txctx.TxHash, txctx.TxIndex)
-	if _, err = core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.GasLimit)); err != nil {
+	if _, err = core.ApplyMessage(vmenv, message, gp); err != nil {
 		return nil, fmt.Errorf("tracing failed: %w", err)
 	}
 	return tracer.GetResult()
@@ -36,6 +36,7 @@ import (
 	"reflect"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/coreth/consensus"
 	"github.com/ava-labs/coreth/consensus/dummy"
@@ -48,6 +49,7 @@ import (
 	"github.com/ava-labs/coreth/internal/ethapi"
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/rpc"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -438,6 +440,72 @@ func TestTraceCall(t *testing.T) {
 	}
 }
 
+func TestTraceCallTimeout(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestBanffChainConfig,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			// JUMPDEST; PUSH1 0; JUMP: loops until the gas is exhausted.
+			accounts[1].addr: {Balance: common.Big0, Code: []byte{0x5b, 0x60, 0x00, 0x56}},
+		},
+	}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.teardown()
+	api := NewAPI(backend)
+
+	// The trace is bounded by the timeout of the trace config rather than the
+	// RPC per-execution timeout.
+	timeout := (10 * time.Millisecond).String()
+	latest := rpc.LatestBlockNumber
+	_, err := api.TraceCall(
+		context.Background(),
+		ethapi.TransactionArgs{From: &accounts[0].addr, To: &accounts[1].addr},
+		rpc.BlockNumberOrHash{BlockNumber: &latest},
+		&TraceCallConfig{TraceConfig: TraceConfig{Config: &logger.Config{Limit: 1}, Timeout: &timeout}},
+	)
+	if !errors.Is(err, vmerrs.ErrExecutionTimeout) {
+		t.Fatalf("expected %v, got %v", vmerrs.ErrExecutionTimeout, err)
+	}
+}
+
+func TestBlockGas(t *testing.T) {
+	gas := blockGas{remaining: 100_000}
+	var vmctx vm.BlockContext
+
+	// A transaction that fails before buying gas uses none of the block's gas.
+	msg := &core.Message{GasLimit: 30_000}
+	gp := gas.pool(&vmctx, msg)
+	if have, want := vmctx.GetBlockGasRemaining(), uint64(70_000); have != want {
+		t.Errorf("block gas remaining mismatch: have %d, want %d", have, want)
+	}
+	gas.used(msg, gp)
+	if gas.remaining != 100_000 {
+		t.Errorf("block gas remaining changed by a failed transaction: %d", gas.remaining)
+	}
+
+	// A transaction buys its gas from its own pool, and only the gas it uses
+	// is deducted from the block.
+	gp = gas.pool(&vmctx, msg)
+	if err := gp.SubGas(msg.GasLimit); err != nil {
+		t.Fatal(err)
+	}
+	gp.AddGas(9_000)
+	gas.used(msg, gp)
+	if gas.remaining != 79_000 {
+		t.Errorf("block gas remaining mismatch: have %d, want %d", gas.remaining, 79_000)
+	}
+
+	// The gas remaining never underflows.
+	msg = &core.Message{GasLimit: 200_000}
+	gas.pool(&vmctx, msg)
+	if have := vmctx.GetBlockGasRemaining(); have != 0 {
+		t.Errorf("block gas remaining mismatch: have %d, want 0", have)
+	}
+}
+
 func TestTraceTransaction(t *testing.T) {
 	t.Parallel()
 
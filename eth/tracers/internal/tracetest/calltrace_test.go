@@ -31,6 +31,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -63,19 +64,26 @@ type callLog struct {
 	Position hexutil.Uint   `json:"position"`
 }
 
+// precompileOp is a sub-operation reported by a stateful precompile
+type precompileOp struct {
+	Label   string         `json:"label"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+}
+
 // callTrace is the result of a callTracer run.
 type callTrace struct {
-	From         common.Address  `json:"from"`
-	Gas          *hexutil.Uint64 `json:"gas"`
-	GasUsed      *hexutil.Uint64 `json:"gasUsed"`
-	To           *common.Address `json:"to,omitempty"`
-	Input        hexutil.Bytes   `json:"input"`
-	Output       hexutil.Bytes   `json:"output,omitempty"`
-	Error        string          `json:"error,omitempty"`
-	RevertReason string          `json:"revertReason,omitempty"`
-	Calls        []callTrace     `json:"calls,omitempty"`
-	Logs         []callLog       `json:"logs,omitempty"`
-	Value        *hexutil.Big    `json:"value,omitempty"`
+	From          common.Address  `json:"from"`
+	Gas           *hexutil.Uint64 `json:"gas"`
+	GasUsed       *hexutil.Uint64 `json:"gasUsed"`
+	To            *common.Address `json:"to,omitempty"`
+	Input         hexutil.Bytes   `json:"input"`
+	Output        hexutil.Bytes   `json:"output,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	RevertReason  string          `json:"revertReason,omitempty"`
+	Calls         []callTrace     `json:"calls,omitempty"`
+	Logs          []callLog       `json:"logs,omitempty"`
+	PrecompileOps []precompileOp  `json:"precompileOps,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
 	// Gencodec adds overridden fields at the end
 	Type string `json:"type"`
 }
@@ -414,3 +422,68 @@ func TestInternals(t *testing.T) {
 		})
 	}
 }
+
+func TestCallTracerPrecompileOps(t *testing.T) {
+	var (
+		from       = common.HexToAddress("0x00000000000000000000000000000000feed")
+		to         = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		precompile = common.HexToAddress("0x0200000000000000000000000000000000000005")
+	)
+	for _, tc := range []struct {
+		name        string
+		config      json.RawMessage
+		wantTopOps  []precompileOp
+		wantCallOps []precompileOp
+	}{
+		{
+			name:        "all calls",
+			wantTopOps:  []precompileOp{{Label: "top", GasUsed: 5}},
+			wantCallOps: []precompileOp{{Label: "first", GasUsed: 10}, {Label: "second", GasUsed: 20}},
+		},
+		{
+			name:       "only top call",
+			config:     json.RawMessage(`{ "onlyTopCall": true }`),
+			wantTopOps: []precompileOp{{Label: "top", GasUsed: 5}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer, err := tracers.DefaultDirectory.New("callTracer", nil, tc.config)
+			if err != nil {
+				t.Fatalf("failed to create call tracer: %v", err)
+			}
+			opLogger, ok := tracer.(vm.PrecompileOpLogger)
+			if !ok {
+				t.Fatal("call tracer does not implement vm.PrecompileOpLogger")
+			}
+			// Ops are reported at the depth of the call into the precompile.
+			tracer.CaptureTxStart(1000)
+			tracer.CaptureStart(nil, from, to, false, nil, 1000, big.NewInt(0))
+			opLogger.CapturePrecompileOp(1, "top", 5)
+			tracer.CaptureEnter(vm.STATICCALL, to, precompile, nil, 500, nil)
+			opLogger.CapturePrecompileOp(2, "first", 10)
+			opLogger.CapturePrecompileOp(2, "second", 20)
+			tracer.CaptureExit(nil, 30, nil)
+			tracer.CaptureEnd(nil, 100, nil)
+			tracer.CaptureTxEnd(900)
+
+			res, err := tracer.GetResult()
+			if err != nil {
+				t.Fatalf("failed to retrieve trace result: %v", err)
+			}
+			var trace callTrace
+			if err := json.Unmarshal(res, &trace); err != nil {
+				t.Fatalf("failed to unmarshal trace result: %v", err)
+			}
+			if !reflect.DeepEqual(trace.PrecompileOps, tc.wantTopOps) {
+				t.Errorf("top call precompile ops mismatch\n have: %v\n want: %v", trace.PrecompileOps, tc.wantTopOps)
+			}
+			var callOps []precompileOp
+			if len(trace.Calls) > 0 {
+				callOps = trace.Calls[0].PrecompileOps
+			}
+			if !reflect.DeepEqual(callOps, tc.wantCallOps) {
+				t.Errorf("precompile call ops mismatch\n have: %v\n want: %v", callOps, tc.wantCallOps)
+			}
+		})
+	}
+}
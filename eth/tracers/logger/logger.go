@@ -19,6 +19,7 @@ package logger
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -113,6 +114,7 @@ type StructLogger struct {
 
 	storage  map[common.Address]Storage
 	logs     []StructLog
+	ops      []PrecompileOpLog
 	output   []byte
 	err      error
 	gasLimit uint64
@@ -138,6 +140,7 @@ func (l *StructLogger) Reset() {
 	l.storage = make(map[common.Address]Storage)
 	l.output = make([]byte, 0)
 	l.logs = l.logs[:0]
+	l.ops = l.ops[:0]
 	l.err = nil
 }
 
@@ -237,6 +240,24 @@ func (l *StructLogger) CaptureEnter(typ vm.OpCode, from common.Address, to commo
 func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) {
 }
 
+// CapturePrecompileOp implements the vm.PrecompileOpLogger interface to record
+// a sub-operation reported by a precompile.
+func (l *StructLogger) CapturePrecompileOp(depth int, label string, gasUsed uint64) {
+	if l.interrupt.Load() {
+		return
+	}
+	// Ops are dropped with the logs of the calls they happen in.
+	if l.cfg.Limit != 0 && l.cfg.Limit <= len(l.logs) {
+		return
+	}
+	l.ops = append(l.ops, PrecompileOpLog{
+		Step:    len(l.logs) - 1,
+		Depth:   depth,
+		Label:   label,
+		GasUsed: gasUsed,
+	})
+}
+
 func (l *StructLogger) GetResult() (json.RawMessage, error) {
 	// Tracing aborted
 	if l.reason != nil {
@@ -246,14 +267,15 @@ func (l *StructLogger) GetResult() (json.RawMessage, error) {
 	returnData := common.CopyBytes(l.output)
 	// Return data when successful and revert reason when reverted, otherwise empty.
 	returnVal := fmt.Sprintf("%x", returnData)
-	if failed && l.err != vmerrs.ErrExecutionReverted {
+	if failed && !errors.Is(l.err, vmerrs.ErrExecutionReverted) {
 		returnVal = ""
 	}
 	return json.Marshal(&ExecutionResult{
-		Gas:         l.usedGas,
-		Failed:      failed,
-		ReturnValue: returnVal,
-		StructLogs:  formatLogs(l.StructLogs()),
+		Gas:           l.usedGas,
+		Failed:        failed,
+		ReturnValue:   returnVal,
+		StructLogs:    formatLogs(l.StructLogs()),
+		PrecompileOps: l.ops,
 	})
 }
 
@@ -274,6 +296,9 @@ func (l *StructLogger) CaptureTxEnd(restGas uint64) {
 // StructLogs returns the captured log entries.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// PrecompileOps returns the captured precompile sub-operations.
+func (l *StructLogger) PrecompileOps() []PrecompileOpLog { return l.ops }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
@@ -408,6 +433,19 @@ type ExecutionResult struct {
 	Failed      bool           `json:"failed"`
 	ReturnValue string         `json:"returnValue"`
 	StructLogs  []StructLogRes `json:"structLogs"`
+	// PrecompileOps are the sub-operations reported by stateful precompiles.
+	PrecompileOps []PrecompileOpLog `json:"precompileOps,omitempty"`
+}
+
+// PrecompileOpLog is a named sub-operation of a call into a stateful
+// precompile and the gas it used.
+type PrecompileOpLog struct {
+	// Step is the index in the struct logs of the opcode that called the
+	// precompile, or -1 if the transaction called the precompile directly.
+	Step    int    `json:"step"`
+	Depth   int    `json:"depth"`
+	Label   string `json:"label"`
+	GasUsed uint64 `json:"gasUsed"`
 }
 
 // StructLogRes stores a structured log emitted by the EVM while replaying a
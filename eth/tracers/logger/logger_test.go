@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"errors"
 	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ava-labs/coreth/core/rawdb"
@@ -100,3 +102,44 @@ func TestStructLogMarshalingOmitEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestStructLoggerPrecompileOps(t *testing.T) {
+	var (
+		logger     = NewStructLogger(nil)
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		env        = vm.NewEVM(vm.BlockContext{}, vm.TxContext{}, statedb, params.TestChainConfig, vm.Config{Tracer: logger})
+	)
+	// A transaction calling a precompile directly has no struct logs.
+	logger.CaptureTxStart(100)
+	logger.CaptureStart(env, common.Address{}, common.Address{1}, false, nil, 100, nil)
+	logger.CapturePrecompileOp(1, "first", 10)
+	logger.CapturePrecompileOp(1, "second", 20)
+	logger.CaptureEnd(nil, 30, nil)
+	logger.CaptureTxEnd(70)
+
+	res, err := logger.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result ExecutionResult
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatal(err)
+	}
+	want := []PrecompileOpLog{
+		{Step: -1, Depth: 1, Label: "first", GasUsed: 10},
+		{Step: -1, Depth: 1, Label: "second", GasUsed: 20},
+	}
+	if !reflect.DeepEqual(result.PrecompileOps, want) {
+		t.Fatalf("precompile ops mismatch: have %+v, want %+v", result.PrecompileOps, want)
+	}
+
+	// The field is omitted without precompile ops.
+	logger.Reset()
+	res, err = logger.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(res), "precompileOps") {
+		t.Fatalf("unexpected precompileOps in %s", res)
+	}
+}
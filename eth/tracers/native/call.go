@@ -56,21 +56,31 @@ type callLog struct {
 	Position hexutil.Uint `json:"position"`
 }
 
+// precompileOp is a named sub-operation of a call into a stateful precompile.
+type precompileOp struct {
+	Label   string         `json:"label"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+}
+
 type callFrame struct {
-	Type         vm.OpCode       `json:"-"`
-	From         common.Address  `json:"from"`
-	Gas          uint64          `json:"gas"`
-	GasUsed      uint64          `json:"gasUsed"`
-	To           *common.Address `json:"to,omitempty" rlp:"optional"`
-	Input        []byte          `json:"input" rlp:"optional"`
-	Output       []byte          `json:"output,omitempty" rlp:"optional"`
-	Error        string          `json:"error,omitempty" rlp:"optional"`
-	RevertReason string          `json:"revertReason,omitempty"`
-	Calls        []callFrame     `json:"calls,omitempty" rlp:"optional"`
-	Logs         []callLog       `json:"logs,omitempty" rlp:"optional"`
+	Type          vm.OpCode       `json:"-"`
+	From          common.Address  `json:"from"`
+	Gas           uint64          `json:"gas"`
+	GasUsed       uint64          `json:"gasUsed"`
+	To            *common.Address `json:"to,omitempty" rlp:"optional"`
+	Input         []byte          `json:"input" rlp:"optional"`
+	Output        []byte          `json:"output,omitempty" rlp:"optional"`
+	Error         string          `json:"error,omitempty" rlp:"optional"`
+	RevertReason  string          `json:"revertReason,omitempty"`
+	Calls         []callFrame     `json:"calls,omitempty" rlp:"optional"`
+	Logs          []callLog       `json:"logs,omitempty" rlp:"optional"`
+	PrecompileOps []precompileOp  `json:"precompileOps,omitempty" rlp:"optional"`
 	// Placed at end on purpose. The RLP will be decoded to 0 instead of
 	// nil if there are non-empty elements after in the struct.
 	Value *big.Int `json:"value,omitempty" rlp:"optional"`
+
+	// err is the error the call failed with, which is not serialized.
+	err error
 }
 
 func (f callFrame) TypeString() string {
@@ -87,6 +97,7 @@ func (f *callFrame) processOutput(output []byte, err error) {
 		f.Output = output
 		return
 	}
+	f.err = err
 	f.Error = err.Error()
 	if f.Type == vm.CREATE || f.Type == vm.CREATE2 {
 		f.To = nil
@@ -254,6 +265,24 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
 }
 
+// CapturePrecompileOp implements the vm.PrecompileOpLogger interface to record
+// a sub-operation reported by a precompile in the frame of its call.
+func (t *callTracer) CapturePrecompileOp(depth int, label string, gasUsed uint64) {
+	// Only the top call has a frame when only caring about the top call
+	if t.config.OnlyTopCall && depth > 1 {
+		return
+	}
+	// Skip if tracing was interrupted
+	if t.interrupt.Load() {
+		return
+	}
+	frame := &t.callstack[len(t.callstack)-1]
+	frame.PrecompileOps = append(frame.PrecompileOps, precompileOp{
+		Label:   label,
+		GasUsed: hexutil.Uint64(gasUsed),
+	})
+}
+
 func (t *callTracer) CaptureTxStart(gasLimit uint64) {
 	t.gasLimit = gasLimit
 }
@@ -51,7 +51,6 @@ var parityErrorMapping = map[string]string{
 	"contract creation code storage out of gas": "Out of gas",
 	"out of gas":                      "Out of gas",
 	"gas uint64 overflow":             "Out of gas",
-	"max code size exceeded":          "Out of gas",
 	"invalid jump destination":        "Bad jump destination",
 	"execution reverted":              "Reverted",
 	"return data out of bounds":       "Out of bounds",
@@ -61,8 +60,9 @@ var parityErrorMapping = map[string]string{
 }
 
 var parityErrorMappingStartingWith = map[string]string{
-	"invalid opcode:": "Bad instruction",
-	"stack underflow": "Stack underflow",
+	"invalid opcode:":        "Bad instruction",
+	"stack underflow":        "Stack underflow",
+	"max code size exceeded": "Out of gas",
 }
 
 // flatCallFrame is a standalone callframe.
@@ -276,7 +276,7 @@ func flatFromNested(input *callFrame, traceAddress []int, convertErrs bool, ctx
 
 	// Revert output contains useful information (revert reason).
 	// Otherwise discard result.
-	if input.Error != "" && input.Error != vmerrs.ErrExecutionReverted.Error() {
+	if input.Error != "" && !errors.Is(input.err, vmerrs.ErrExecutionReverted) {
 		frame.Result = nil
 	}
 
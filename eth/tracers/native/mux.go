@@ -111,6 +111,16 @@ func (t *muxTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	}
 }
 
+// CapturePrecompileOp implements the vm.PrecompileOpLogger interface for the
+// tracers that implement it.
+func (t *muxTracer) CapturePrecompileOp(depth int, label string, gasUsed uint64) {
+	for _, t := range t.tracers {
+		if t, ok := t.(vm.PrecompileOpLogger); ok {
+			t.CapturePrecompileOp(depth, label, gasUsed)
+		}
+	}
+}
+
 func (t *muxTracer) CaptureTxStart(gasLimit uint64) {
 	for _, t := range t.tracers {
 		t.CaptureTxStart(gasLimit)
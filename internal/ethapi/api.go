@@ -49,6 +49,7 @@ import (
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/rpc"
 	"github.com/ava-labs/coreth/trie"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -1064,6 +1065,16 @@ func (context *ChainContext) GetHeader(hash common.Hash, number uint64) *types.H
 	return header
 }
 
+// GetHeaderByNumber returns the canonical header at [number], or nil if it is
+// not found.
+func (context *ChainContext) GetHeaderByNumber(number uint64) *types.Header {
+	header, err := context.b.HeaderByNumber(context.ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil
+	}
+	return header
+}
+
 func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.StateDB, header *types.Header, overrides *StateOverride, blockOverrides *BlockOverrides, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
 	if err := overrides.Apply(state); err != nil {
 		return nil, err
@@ -1087,6 +1098,7 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 		return nil, err
 	}
 	blockCtx := core.NewEVMBlockContext(header, NewChainContext(ctx, b), nil)
+	blockCtx.BlockGasRemaining = core.SimulatedBlockGasRemaining(globalGasCap)
 	if blockOverrides != nil {
 		blockOverrides.Apply(&blockCtx)
 	}
@@ -1098,6 +1110,13 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 		<-ctx.Done()
 		evm.Cancel()
 	}()
+	// Bound the duration of this execution separately from [timeout], so a
+	// single pathological call is cut short without consuming the whole
+	// request's time budget.
+	execTimeout := b.RPCEVMExecutionTimeout()
+	if execTimeout > 0 {
+		defer evm.CancelAfter(execTimeout)()
+	}
 
 	// Execute the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
@@ -1107,6 +1126,9 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 	}
 
 	// If the timer caused an abort, return an appropriate error message
+	if evm.TimedOut() {
+		return nil, fmt.Errorf("%w (timeout = %v)", vmerrs.ErrExecutionTimeout, execTimeout)
+	}
 	if evm.Cancelled() {
 		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
 	}
@@ -1182,11 +1204,12 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 	}
 	// Construct the gas estimator option from the user input
 	opts := &gasestimator.Options{
-		Config:     b.ChainConfig(),
-		Chain:      NewChainContext(ctx, b),
-		Header:     header,
-		State:      state,
-		ErrorRatio: estimateGasErrorRatio,
+		Config:           b.ChainConfig(),
+		Chain:            NewChainContext(ctx, b),
+		Header:           header,
+		State:            state,
+		ErrorRatio:       estimateGasErrorRatio,
+		ExecutionTimeout: b.RPCEVMExecutionTimeout(),
 	}
 
 	// If the user has not specified a gas limit, use the block gas limit
@@ -1544,7 +1567,9 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 		// Apply the transaction with the access list tracer
 		tracer := logger.NewAccessListTracer(accessList, args.from(), to, precompiles)
 		config := vm.Config{Tracer: tracer, NoBaseFee: true}
-		vmenv := b.GetEVM(ctx, msg, statedb, header, &config, nil)
+		blockCtx := core.NewEVMBlockContext(header, NewChainContext(ctx, b), nil)
+		blockCtx.BlockGasRemaining = core.SimulatedBlockGasRemaining(b.RPCGasCap())
+		vmenv := b.GetEVM(ctx, msg, statedb, header, &config, &blockCtx)
 		res, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
 		if err != nil {
 			return nil, 0, nil, fmt.Errorf("failed to apply transaction: %v err: %v", args.toTransaction().Hash(), err)
@@ -51,6 +51,7 @@ import (
 	"github.com/ava-labs/coreth/internal/blocktest"
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/rpc"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -414,6 +415,8 @@ func allBlobTxs(addr common.Address, config *params.ChainConfig) []txData {
 type testBackend struct {
 	db    ethdb.Database
 	chain *core.BlockChain
+
+	execTimeout time.Duration // Timeout of a single EVM execution, zero for no limit
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, engine consensus.Engine, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -456,6 +459,7 @@ func (b testBackend) AccountManager() *accounts.Manager          { return nil }
 func (b testBackend) ExtRPCEnabled() bool                        { return false }
 func (b testBackend) RPCGasCap() uint64                          { return 10000000 }
 func (b testBackend) RPCEVMTimeout() time.Duration               { return time.Second }
+func (b testBackend) RPCEVMExecutionTimeout() time.Duration      { return b.execTimeout }
 func (b testBackend) RPCTxFeeCap() float64                       { return 0 }
 func (b testBackend) UnprotectedAllowed(*types.Transaction) bool { return false }
 func (b testBackend) SetHead(number uint64)                      {}
@@ -912,6 +916,154 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// revertCode returns code which reverts with [data].
+func revertCode(data []byte) *hexutil.Bytes {
+	var code hexutil.Bytes
+	for offset := 0; offset < len(data); offset += 32 {
+		word := common.RightPadBytes(data[offset:min(offset+32, len(data))], 32)
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, word...)
+		code = append(code, byte(vm.PUSH1), byte(offset), byte(vm.MSTORE))
+	}
+	code = append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.REVERT))
+	return &code
+}
+
+func TestCallRevert(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {}))
+
+	tests := map[string]struct {
+		data        []byte
+		wantMessage string
+	}{
+		"error string": {
+			// abi.encodeWithSignature("Error(string)", "insufficient allowance")
+			data: common.FromHex("0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000016" +
+				"696e73756666696369656e7420616c6c6f77616e636500000000000000000000"),
+			wantMessage: "execution reverted: insufficient allowance",
+		},
+		"panic": {
+			// abi.encodeWithSignature("Panic(uint256)", 0x01)
+			data: common.FromHex("0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000001"),
+			wantMessage: "execution reverted: assert(false)",
+		},
+		"custom error": {
+			// abi.encodeWithSignature("InsufficientBalance(uint256,uint256)", 1, 2)
+			data: common.FromHex("0xcf479181" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002"),
+			wantMessage: "execution reverted",
+		},
+		"no data": {
+			wantMessage: "execution reverted",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			call := TransactionArgs{
+				From: &accounts[0].addr,
+				To:   &accounts[1].addr,
+			}
+			overrides := StateOverride{
+				accounts[1].addr: OverrideAccount{Code: revertCode(test.data)},
+			}
+			_, callErr := api.Call(context.Background(), call, &latest, &overrides, nil)
+			_, estimateErr := api.EstimateGas(context.Background(), call, &latest, &overrides)
+
+			for _, err := range []error{callErr, estimateErr} {
+				require.ErrorIs(err, vmerrs.ErrExecutionReverted)
+				require.EqualError(err, test.wantMessage)
+
+				// The revert data is surfaced as is, including the selector
+				// and arguments of custom errors.
+				var dataErr rpc.DataError
+				require.True(errors.As(err, &dataErr))
+				require.Equal(hexutil.Encode(test.data), dataErr.ErrorData())
+				var rpcErr rpc.Error
+				require.True(errors.As(err, &rpcErr))
+				require.Equal(3, rpcErr.ErrorCode())
+			}
+		})
+	}
+}
+
+func TestCallExecutionTimeout(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		// The gas cap is high enough that the loop is only stopped by the
+		// execution timeout.
+		gasCap      = uint64(1 << 50)
+		execTimeout = 100 * time.Millisecond
+		timeout     = 10 * time.Second
+		latest      = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	backend := newTestBackend(t, 1, genesis, dummy.NewCoinbaseFaker(), func(i int, b *core.BlockGen) {})
+	backend.execTimeout = execTimeout
+
+	loop := TransactionArgs{
+		From: &accounts[0].addr,
+		To:   &accounts[1].addr,
+	}
+	overrides := StateOverride{
+		accounts[1].addr: OverrideAccount{
+			Code: hex2Bytes("5b600056"), // JUMPDEST PUSH1 0 JUMP
+		},
+	}
+	start := time.Now()
+	_, err := DoCall(context.Background(), backend, loop, latest, &overrides, nil, timeout, gasCap)
+	if elapsed := time.Since(start); elapsed >= timeout/2 {
+		t.Fatalf("execution was not cut short, took %v", elapsed)
+	}
+	if !errors.Is(err, vmerrs.ErrExecutionTimeout) {
+		t.Fatalf("error mismatch, want %v, have %v", vmerrs.ErrExecutionTimeout, err)
+	}
+
+	// A subsequent call is not affected by the timed out execution.
+	transfer := TransactionArgs{
+		From:  &accounts[0].addr,
+		To:    &accounts[1].addr,
+		Value: (*hexutil.Big)(big.NewInt(1000)),
+	}
+	if _, err := DoCall(context.Background(), backend, transfer, latest, nil, nil, timeout, gasCap); err != nil {
+		t.Fatalf("want no error, have %v", err)
+	}
+
+	// Each gas estimation trial is subject to the execution timeout.
+	gas := hexutil.Uint64(gasCap)
+	loop.Gas = &gas
+	start = time.Now()
+	_, err = DoEstimateGas(context.Background(), backend, loop, latest, &overrides, gasCap)
+	if elapsed := time.Since(start); elapsed >= timeout/2 {
+		t.Fatalf("estimation was not cut short, took %v", elapsed)
+	}
+	if !errors.Is(err, vmerrs.ErrExecutionTimeout) {
+		t.Fatalf("error mismatch, want %v, have %v", vmerrs.ErrExecutionTimeout, err)
+	}
+}
+
 type account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
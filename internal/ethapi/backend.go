@@ -57,9 +57,10 @@ type Backend interface {
 	ChainDb() ethdb.Database
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
-	RPCGasCap() uint64            // global gas cap for eth_call over rpc: DoS protection
-	RPCEVMTimeout() time.Duration // global timeout for eth_call over rpc: DoS protection
-	RPCTxFeeCap() float64         // global tx fee cap for all transaction related APIs
+	RPCGasCap() uint64                     // global gas cap for eth_call over rpc: DoS protection
+	RPCEVMTimeout() time.Duration          // global timeout for eth_call over rpc: DoS protection
+	RPCEVMExecutionTimeout() time.Duration // timeout for a single EVM execution over rpc: DoS protection
+	RPCTxFeeCap() float64                  // global tx fee cap for all transaction related APIs
 
 	UnprotectedAllowed(tx *types.Transaction) bool // allows only for EIP155 transactions.
 
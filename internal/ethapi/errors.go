@@ -29,39 +29,31 @@ package ethapi
 import (
 	"fmt"
 
-	"github.com/ava-labs/coreth/accounts/abi"
 	"github.com/ava-labs/coreth/vmerrs"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // revertError is an API error that encompasses an EVM revert with JSON error
 // code and a binary data blob.
 type revertError struct {
-	error
-	reason string // revert reason hex encoded
+	*vmerrs.RevertError
 }
 
-// ErrorCode returns the JSON error code for a revert.
-// See: https://github.com/ethereum/wiki/wiki/JSON-RPC-Error-Codes-Improvement-Proposal
-func (e *revertError) ErrorCode() int {
-	return 3
+// Error returns the revert error, including the revert reason if it can be
+// decoded.
+func (e *revertError) Error() string {
+	if reason, err := e.Decode(); err == nil {
+		return fmt.Sprintf("%s: %v", vmerrs.ErrExecutionReverted, reason)
+	}
+	return e.RevertError.Error()
 }
 
-// ErrorData returns the hex encoded revert reason.
-func (e *revertError) ErrorData() interface{} {
-	return e.reason
+func (e *revertError) Unwrap() error {
+	return e.RevertError
 }
 
 // newRevertError creates a revertError instance with the provided revert data.
 func newRevertError(revert []byte) *revertError {
-	err := vmerrs.ErrExecutionReverted
-
-	reason, errUnpack := abi.UnpackRevert(revert)
-	if errUnpack == nil {
-		err = fmt.Errorf("%w: %v", vmerrs.ErrExecutionReverted, reason)
-	}
 	return &revertError{
-		error:  err,
-		reason: hexutil.Encode(revert),
+		RevertError: &vmerrs.RevertError{Reason: revert},
 	}
 }
@@ -28,6 +28,9 @@
 package miner
 
 import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/coreth/consensus"
 	"github.com/ava-labs/coreth/core"
@@ -47,7 +50,12 @@ type Backend interface {
 
 // Config is the configuration parameters of mining.
 type Config struct {
-	Etherbase common.Address `toml:",omitempty"` // Public address for block mining rewards
+	Etherbase      common.Address `toml:",omitempty"` // Public address for block mining rewards
+	OrderingPolicy OrderingPolicy `toml:",omitempty"` // Order in which pending transactions are added to blocks
+	// DisableLocalsPriority adds the pending transactions of local senders to
+	// blocks along with those of remote senders, ordered by their tips, instead
+	// of before them.
+	DisableLocalsPriority bool `toml:",omitempty"`
 }
 
 type Miner struct {
@@ -64,8 +72,64 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
 
-func (miner *Miner) GenerateBlock(predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
-	return miner.worker.commitNewWork(predicateContext)
+// ProcessingTxs is the set of transactions of the processing ancestors of the
+// blocks built, keyed by the transaction hash or the ID of the atomic
+// transaction.
+type ProcessingTxs interface {
+	Has(txHash common.Hash) bool
+}
+
+// SetProcessingTxs sets the transactions of the processing ancestors of the
+// blocks built after the call, which are not included in them.
+func (miner *Miner) SetProcessingTxs(txs ProcessingTxs) {
+	miner.worker.setProcessingTxs(txs)
+}
+
+// SetPrioritizeLocals sets whether the transactions of local senders are added
+// to the blocks built after the call before those of remote senders.
+func (miner *Miner) SetPrioritizeLocals(prioritize bool) {
+	miner.worker.setPrioritizeLocals(prioritize)
+}
+
+// GenerateBlock builds a block on top of the preferred block from the pending
+// transactions of the pool. Once [ctx] is done, the block is built from the
+// transactions already executed.
+func (miner *Miner) GenerateBlock(ctx context.Context, predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
+	return miner.worker.commitNewWork(ctx, predicateContext)
+}
+
+// GenerateBlockWithTransactions builds a block on top of [parent] at
+// [timestamp] from [txs] instead of the pending transactions of the pool. The
+// transactions are executed in order, and those which cannot be included,
+// such as those exceeding the gas limit or with invalid nonces or fees, are
+// skipped and returned with the reason. Transactions with predicates are
+// skipped, as there is no proposer block context to verify them with.
+//
+// The returned block is not inserted in the chain. As for any block built by
+// the miner, the consensus engine may add the atomic transactions of the
+// mempool to it.
+func (miner *Miner) GenerateBlockWithTransactions(ctx context.Context, parent common.Hash, txs types.Transactions, timestamp uint64) (*types.Block, []*SkippedTx, error) {
+	return miner.worker.commitForcedWork(ctx, parent, txs, timestamp)
+}
+
+// BlockBuildingReports returns the reports of the last blocks built by
+// GenerateBlock, oldest first. The reports must not be modified.
+func (miner *Miner) BlockBuildingReports() []*BlockBuildingReport {
+	return miner.worker.reports.list()
+}
+
+// ReportAtomicTxIncluded records that the atomic transaction [txID] was
+// included in the block being built by GenerateBlock, if any. It is called by
+// the consensus engine while the block is assembled.
+func (miner *Miner) ReportAtomicTxIncluded(txID ids.ID) {
+	miner.worker.reports.includeAtomic(txID)
+}
+
+// ReportAtomicTxSkipped records that the atomic transaction [txID] was
+// skipped for [reason] by the block being built by GenerateBlock, if any.
+// [err] is the verification error of the transaction, if any.
+func (miner *Miner) ReportAtomicTxSkipped(txID ids.ID, reason SkipReason, err error) {
+	miner.worker.reports.skipAtomic(txID, reason, err)
 }
 
 // SubscribePendingLogs starts delivering logs from pending transactions
@@ -29,6 +29,7 @@ package miner
 import (
 	"container/heap"
 	"math/big"
+	"slices"
 
 	"github.com/ava-labs/coreth/core/txpool"
 	"github.com/ava-labs/coreth/core/types"
@@ -155,3 +156,81 @@ func (t *transactionsByPriceAndNonce) Shift() {
 func (t *transactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
+
+// Size returns the number of remaining transactions, including those queued
+// behind the head transaction of each sender.
+func (t *transactionsByPriceAndNonce) Size() int {
+	size := 0
+	for _, head := range t.heads {
+		size += 1 + len(t.txs[head.from])
+	}
+	return size
+}
+
+// PeekN returns the next [n] transactions in the order they would be returned
+// by Peek if each of them was followed by Shift, without modifying the heap.
+func (t *transactionsByPriceAndNonce) PeekN(n int) []*txpool.LazyTransaction {
+	if n <= 0 || len(t.heads) == 0 {
+		return nil
+	}
+	// Shift is replayed on a copy of the heads, with the number of queued
+	// transactions of each sender moved to the copy.
+	var (
+		heads   = slices.Clone(t.heads)
+		shifted = make(map[common.Address]int)
+		txs     = make([]*txpool.LazyTransaction, 0, min(n, t.Size()))
+	)
+	for len(txs) < n && len(heads) > 0 {
+		acc := heads[0].from
+		txs = append(txs, heads[0].tx)
+		if queued, i := t.txs[acc], shifted[acc]; i < len(queued) {
+			if wrapped, err := newTxWithMinerFee(queued[i], acc, t.baseFee); err == nil {
+				heads[0], shifted[acc] = wrapped, i+1
+				heap.Fix(&heads, 0)
+				continue
+			}
+		}
+		heap.Pop(&heads)
+	}
+	return txs
+}
+
+// HeapStats describes the transactions remaining in a
+// transactionsByPriceAndNonce.
+type HeapStats struct {
+	// TxCount is the number of transactions remaining, including those queued
+	// behind the head transaction of each sender.
+	TxCount int
+	// SenderCount is the number of senders with a transaction in the heap.
+	SenderCount int
+	// MaxEffectiveGasPrice and MinEffectiveGasPrice are the highest and lowest
+	// effective gas prices of the transactions in the heap, which are the next
+	// transaction of each sender. They are nil if the heap is empty.
+	MaxEffectiveGasPrice *big.Int
+	MinEffectiveGasPrice *big.Int
+}
+
+// Stats returns diagnostics about the remaining transactions without
+// modifying the heap.
+func (t *transactionsByPriceAndNonce) Stats() HeapStats {
+	stats := HeapStats{
+		SenderCount: len(t.heads),
+	}
+	for _, head := range t.heads {
+		stats.TxCount += 1 + len(t.txs[head.from])
+
+		// [fees] is the effective miner tip, which is already capped by the
+		// fee cap of the transaction.
+		price := new(big.Int).Set(head.fees)
+		if t.baseFee != nil {
+			price.Add(price, t.baseFee)
+		}
+		if stats.MaxEffectiveGasPrice == nil || price.Cmp(stats.MaxEffectiveGasPrice) > 0 {
+			stats.MaxEffectiveGasPrice = price
+		}
+		if stats.MinEffectiveGasPrice == nil || price.Cmp(stats.MinEffectiveGasPrice) < 0 {
+			stats.MinEffectiveGasPrice = price
+		}
+	}
+	return stats
+}
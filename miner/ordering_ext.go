@@ -10,6 +10,27 @@ import (
 
 type TransactionsByPriceAndNonce = transactionsByPriceAndNonce
 
+// TransactionsLookahead is implemented by the transaction sets which can
+// return their next transactions without removing them.
+type TransactionsLookahead interface {
+	Size() int
+	PeekN(n int) []*txpool.LazyTransaction
+}
+
+var _ TransactionsLookahead = (*TransactionsByPriceAndNonce)(nil)
+
 func NewTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *TransactionsByPriceAndNonce {
 	return newTransactionsByPriceAndNonce(signer, txs, baseFee)
 }
+
+type TransactionsByTimeAndNonce = transactionsByTimeAndNonce
+
+func NewTransactionsByTimeAndNonce(txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *TransactionsByTimeAndNonce {
+	return newTransactionsByTimeAndNonce(txs, baseFee)
+}
+
+type TransactionsBySenderRoundRobin = transactionsBySenderRoundRobin
+
+func NewTransactionsBySenderRoundRobin(txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *TransactionsBySenderRoundRobin {
+	return newTransactionsBySenderRoundRobin(txs, baseFee)
+}
@@ -0,0 +1,234 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OrderingPolicy determines the order in which the worker selects pending
+// transactions for a block. The transactions of each sender are always
+// selected in nonce order.
+type OrderingPolicy string
+
+const (
+	// OrderingPrice selects the transaction paying the highest effective
+	// miner tip first. This is the default policy.
+	OrderingPrice OrderingPolicy = "price"
+	// OrderingFIFO selects the transaction first seen by the node first.
+	OrderingFIFO OrderingPolicy = "fifo"
+	// OrderingRoundRobin selects one transaction from each sender in turn,
+	// so that a single sender cannot fill a block ahead of the others.
+	// Senders are ordered by the time their first transaction was seen.
+	OrderingRoundRobin OrderingPolicy = "roundrobin"
+)
+
+var errUnknownOrderingPolicy = errors.New("unknown transaction ordering policy")
+
+// Validate returns an error if [p] is not a known policy. The empty policy is
+// [OrderingPrice].
+func (p OrderingPolicy) Validate() error {
+	switch p {
+	case "", OrderingPrice, OrderingFIFO, OrderingRoundRobin:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", errUnknownOrderingPolicy, p)
+	}
+}
+
+// orderedTransactions is a set of transactions which the worker selects from
+// in the order of an OrderingPolicy.
+type orderedTransactions interface {
+	// Peek returns the next transaction, or nil if there are none left.
+	Peek() *txpool.LazyTransaction
+	// Shift replaces the next transaction with the next one from the same
+	// account.
+	Shift()
+	// Pop removes the next transaction, *not* replacing it with the next one
+	// from the same account.
+	Pop()
+}
+
+// newOrderedTransactionsFunc creates a transaction set from the nonce-sorted
+// transactions of each account. The input map is reowned by the set.
+type newOrderedTransactionsFunc func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) orderedTransactions
+
+// orderingFunc returns the constructor of the transaction sets ordered by
+// [policy]. Unknown policies are ordered by price.
+func orderingFunc(policy OrderingPolicy) newOrderedTransactionsFunc {
+	switch policy {
+	case OrderingFIFO:
+		return func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) orderedTransactions {
+			return newTransactionsByTimeAndNonce(txs, baseFee)
+		}
+	case OrderingRoundRobin:
+		return func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) orderedTransactions {
+			return newTransactionsBySenderRoundRobin(txs, baseFee)
+		}
+	default:
+		return func(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) orderedTransactions {
+			return newTransactionsByPriceAndNonce(signer, txs, baseFee)
+		}
+	}
+}
+
+// newHeads returns the first transaction of each account in [txs], removing
+// it from [txs]. Accounts whose first transaction cannot pay [baseFee] are
+// removed from [txs].
+func newHeads(txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) []*txWithMinerFee {
+	heads := make([]*txWithMinerFee, 0, len(txs))
+	for from, accTxs := range txs {
+		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFee)
+		if err != nil {
+			delete(txs, from)
+			continue
+		}
+		heads = append(heads, wrapped)
+		txs[from] = accTxs[1:]
+	}
+	return heads
+}
+
+// compareTime orders transactions by the time they were first seen, using the
+// hash of the transactions for deterministic ordering.
+func compareTime(a, b *txWithMinerFee) int {
+	if cmp := a.tx.Time.Compare(b.tx.Time); cmp != 0 {
+		return cmp
+	}
+	return bytes.Compare(a.tx.Hash[:], b.tx.Hash[:])
+}
+
+// txByTime implements the heap interface, ordering transactions by the time
+// they were first seen.
+type txByTime []*txWithMinerFee
+
+func (s txByTime) Len() int           { return len(s) }
+func (s txByTime) Less(i, j int) bool { return compareTime(s[i], s[j]) < 0 }
+func (s txByTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *txByTime) Push(x interface{}) {
+	*s = append(*s, x.(*txWithMinerFee))
+}
+
+func (s *txByTime) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*s = old[0 : n-1]
+	return x
+}
+
+// transactionsByTimeAndNonce represents a set of transactions that can return
+// transactions in the order they were first seen, while supporting removing
+// entire batches of transactions for non-executable accounts.
+type transactionsByTimeAndNonce struct {
+	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
+	heads   txByTime                                     // Next transaction for each unique account (time heap)
+	baseFee *big.Int                                     // Current base fee
+}
+
+// newTransactionsByTimeAndNonce creates a transaction set that can retrieve
+// first seen transactions first in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// it after providing it to the constructor.
+func newTransactionsByTimeAndNonce(txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByTimeAndNonce {
+	heads := txByTime(newHeads(txs, baseFee))
+	heap.Init(&heads)
+
+	return &transactionsByTimeAndNonce{
+		txs:     txs,
+		heads:   heads,
+		baseFee: baseFee,
+	}
+}
+
+// Peek returns the first seen transaction.
+func (t *transactionsByTimeAndNonce) Peek() *txpool.LazyTransaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0].tx
+}
+
+// Shift replaces the current head with the next one from the same account.
+func (t *transactionsByTimeAndNonce) Shift() {
+	acc := t.heads[0].from
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
+			t.heads[0], t.txs[acc] = wrapped, txs[1:]
+			heap.Fix(&t.heads, 0)
+			return
+		}
+	}
+	heap.Pop(&t.heads)
+}
+
+// Pop removes the current head, *not* replacing it with the next one from the
+// same account.
+func (t *transactionsByTimeAndNonce) Pop() {
+	heap.Pop(&t.heads)
+}
+
+// transactionsBySenderRoundRobin represents a set of transactions that can
+// return one transaction from each account in turn, while supporting removing
+// entire batches of transactions for non-executable accounts.
+type transactionsBySenderRoundRobin struct {
+	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
+	heads   []*txWithMinerFee                            // Next transaction for each unique account, in turn order
+	baseFee *big.Int                                     // Current base fee
+}
+
+// newTransactionsBySenderRoundRobin creates a transaction set that can
+// retrieve transactions from each account in turn in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// it after providing it to the constructor.
+func newTransactionsBySenderRoundRobin(txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsBySenderRoundRobin {
+	heads := newHeads(txs, baseFee)
+	slices.SortFunc(heads, compareTime)
+
+	return &transactionsBySenderRoundRobin{
+		txs:     txs,
+		heads:   heads,
+		baseFee: baseFee,
+	}
+}
+
+// Peek returns the next transaction of the account whose turn it is.
+func (t *transactionsBySenderRoundRobin) Peek() *txpool.LazyTransaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0].tx
+}
+
+// Shift moves the account whose turn it is to the end of the turn order,
+// with its next transaction.
+func (t *transactionsBySenderRoundRobin) Shift() {
+	head := t.heads[0]
+	t.Pop()
+	if txs, ok := t.txs[head.from]; ok && len(txs) > 0 {
+		if wrapped, err := newTxWithMinerFee(txs[0], head.from, t.baseFee); err == nil {
+			t.heads, t.txs[head.from] = append(t.heads, wrapped), txs[1:]
+		}
+	}
+}
+
+// Pop removes the account whose turn it is, *not* replacing its transaction
+// with the next one from the same account.
+func (t *transactionsBySenderRoundRobin) Pop() {
+	t.heads[0] = nil
+	t.heads = t.heads[1:]
+}
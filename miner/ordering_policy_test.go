@@ -0,0 +1,151 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var orderingTestStart = time.Unix(1_700_000_000, 0)
+
+// newOrderingTestTx returns a transaction identified by [id], first seen
+// [seen] seconds after [orderingTestStart].
+func newOrderingTestTx(id byte, seen int, gasFeeCap int64) *txpool.LazyTransaction {
+	return &txpool.LazyTransaction{
+		Hash:      common.Hash{id},
+		Time:      orderingTestStart.Add(time.Duration(seen) * time.Second),
+		GasFeeCap: big.NewInt(gasFeeCap),
+		GasTipCap: big.NewInt(gasFeeCap),
+		Gas:       21_000,
+	}
+}
+
+// newOrderingTestGroups returns the pending transactions of three senders
+// with interleaved first seen times, and a fourth sender whose transaction
+// cannot pay the base fee of 10.
+func newOrderingTestGroups() map[common.Address][]*txpool.LazyTransaction {
+	return map[common.Address][]*txpool.LazyTransaction{
+		{0xa}: {newOrderingTestTx(0xa0, 1, 10), newOrderingTestTx(0xa1, 5, 50), newOrderingTestTx(0xa2, 6, 20)},
+		{0xb}: {newOrderingTestTx(0xb0, 2, 30), newOrderingTestTx(0xb1, 3, 40)},
+		{0xc}: {newOrderingTestTx(0xc0, 4, 60)},
+		{0xd}: {newOrderingTestTx(0xd0, 0, 5)},
+	}
+}
+
+// drain returns the ids of the transactions of [txs] in order, calling Pop
+// instead of Shift after the transaction identified by [popAfter].
+func drain(txs orderedTransactions, popAfter byte) []byte {
+	var ids []byte
+	for tx := txs.Peek(); tx != nil; tx = txs.Peek() {
+		ids = append(ids, tx.Hash[0])
+		if tx.Hash[0] == popAfter {
+			txs.Pop()
+		} else {
+			txs.Shift()
+		}
+	}
+	return ids
+}
+
+func TestOrderingPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy   OrderingPolicy
+		popAfter byte
+		want     []byte
+	}{
+		{policy: OrderingPrice, want: []byte{0xc0, 0xb0, 0xb1, 0xa0, 0xa1, 0xa2}},
+		{policy: "", want: []byte{0xc0, 0xb0, 0xb1, 0xa0, 0xa1, 0xa2}},
+		{policy: OrderingFIFO, want: []byte{0xa0, 0xb0, 0xb1, 0xc0, 0xa1, 0xa2}},
+		{policy: OrderingFIFO, popAfter: 0xa0, want: []byte{0xa0, 0xb0, 0xb1, 0xc0}},
+		{policy: OrderingRoundRobin, want: []byte{0xa0, 0xb0, 0xc0, 0xa1, 0xb1, 0xa2}},
+		{policy: OrderingRoundRobin, popAfter: 0xb0, want: []byte{0xa0, 0xb0, 0xc0, 0xa1, 0xa2}},
+	}
+	for _, test := range tests {
+		// Run each ordering twice to check it does not depend on map
+		// iteration order.
+		for i := 0; i < 2; i++ {
+			txs := orderingFunc(test.policy)(nil, newOrderingTestGroups(), big.NewInt(10))
+			if have := drain(txs, test.popAfter); string(have) != string(test.want) {
+				t.Errorf("policy %q, pop after %x: have order %x, want %x", test.policy, test.popAfter, have, test.want)
+			}
+		}
+	}
+}
+
+func TestOrderingPolicyTieBreak(t *testing.T) {
+	t.Parallel()
+
+	// Transactions first seen at the same time are ordered by hash.
+	for _, policy := range []OrderingPolicy{OrderingFIFO, OrderingRoundRobin} {
+		groups := map[common.Address][]*txpool.LazyTransaction{
+			{0x1}: {newOrderingTestTx(0x30, 0, 1)},
+			{0x2}: {newOrderingTestTx(0x10, 0, 1)},
+			{0x3}: {newOrderingTestTx(0x20, 0, 1)},
+		}
+		want := []byte{0x10, 0x20, 0x30}
+		if have := drain(orderingFunc(policy)(nil, groups, nil), 0); string(have) != string(want) {
+			t.Errorf("policy %q: have order %x, want %x", policy, have, want)
+		}
+	}
+}
+
+func TestOrderingPolicyValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []OrderingPolicy{"", OrderingPrice, OrderingFIFO, OrderingRoundRobin} {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("policy %q: unexpected error %v", policy, err)
+		}
+	}
+	if err := OrderingPolicy("lifo").Validate(); !errors.Is(err, errUnknownOrderingPolicy) {
+		t.Errorf("unexpected error %v, want %v", err, errUnknownOrderingPolicy)
+	}
+}
+
+func BenchmarkOrderingPolicies(b *testing.B) {
+	const (
+		senders      = 1_000
+		txsPerSender = 10
+	)
+	newGroups := func() map[common.Address][]*txpool.LazyTransaction {
+		groups := make(map[common.Address][]*txpool.LazyTransaction, senders)
+		for i := 0; i < senders; i++ {
+			addr := common.BigToAddress(big.NewInt(int64(i)))
+			for j := 0; j < txsPerSender; j++ {
+				n := i*txsPerSender + j
+				groups[addr] = append(groups[addr], &txpool.LazyTransaction{
+					Hash:      common.BigToHash(big.NewInt(int64(n))),
+					Time:      orderingTestStart.Add(time.Duration((n*7919)%(senders*txsPerSender)) * time.Millisecond),
+					GasFeeCap: big.NewInt(int64(100 + n%50)),
+					GasTipCap: big.NewInt(int64(n % 50)),
+					Gas:       21_000,
+				})
+			}
+		}
+		return groups
+	}
+	for _, policy := range []OrderingPolicy{OrderingPrice, OrderingFIFO, OrderingRoundRobin} {
+		b.Run(string(policy), func(b *testing.B) {
+			newTxs := orderingFunc(policy)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				groups := newGroups()
+				b.StartTimer()
+
+				txs := newTxs(nil, groups, big.NewInt(100))
+				for tx := txs.Peek(); tx != nil; tx = txs.Peek() {
+					txs.Shift()
+				}
+			}
+		})
+	}
+}
@@ -203,3 +203,142 @@ func TestTransactionTimeSort(t *testing.T) {
 		}
 	}
 }
+
+func TestTransactionsByPriceAndNonceStats(t *testing.T) {
+	t.Parallel()
+
+	signer := types.LatestSignerForChainID(common.Big1)
+	baseFee := big.NewInt(10)
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64, gasFeeCap, gasTipCap int64) *txpool.LazyTransaction {
+		tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			To:        &common.Address{},
+			Gas:       21000,
+			GasFeeCap: big.NewInt(gasFeeCap),
+			GasTipCap: big.NewInt(gasTipCap),
+		}), signer, key)
+		if err != nil {
+			t.Fatalf("failed to sign tx: %s", err)
+		}
+		return &txpool.LazyTransaction{
+			Hash:      tx.Hash(),
+			Tx:        tx,
+			Time:      tx.Time(),
+			GasFeeCap: tx.GasFeeCap(),
+			GasTipCap: tx.GasTipCap(),
+			Gas:       tx.Gas(),
+		}
+	}
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	keyC, _ := crypto.GenerateKey()
+	groups := map[common.Address][]*txpool.LazyTransaction{
+		crypto.PubkeyToAddress(keyA.PublicKey): {newTx(keyA, 0, 30, 5), newTx(keyA, 1, 100, 50)},
+		crypto.PubkeyToAddress(keyB.PublicKey): {newTx(keyB, 0, 12, 10)},
+		// Below the base fee, so never part of the heap.
+		crypto.PubkeyToAddress(keyC.PublicKey): {newTx(keyC, 0, 5, 5)},
+	}
+	txset := newTransactionsByPriceAndNonce(signer, groups, baseFee)
+
+	checkStats := func(txCount, senderCount int, maxPrice, minPrice *big.Int) {
+		t.Helper()
+		stats := txset.Stats()
+		if stats.TxCount != txCount {
+			t.Errorf("tx count mismatch: have %d, want %d", stats.TxCount, txCount)
+		}
+		if stats.SenderCount != senderCount {
+			t.Errorf("sender count mismatch: have %d, want %d", stats.SenderCount, senderCount)
+		}
+		if (stats.MaxEffectiveGasPrice == nil) != (maxPrice == nil) || (maxPrice != nil && stats.MaxEffectiveGasPrice.Cmp(maxPrice) != 0) {
+			t.Errorf("max effective gas price mismatch: have %v, want %v", stats.MaxEffectiveGasPrice, maxPrice)
+		}
+		if (stats.MinEffectiveGasPrice == nil) != (minPrice == nil) || (minPrice != nil && stats.MinEffectiveGasPrice.Cmp(minPrice) != 0) {
+			t.Errorf("min effective gas price mismatch: have %v, want %v", stats.MinEffectiveGasPrice, minPrice)
+		}
+	}
+
+	// The effective gas price of A's first transaction is 10 + min(5, 30-10)
+	// and of B's is 10 + min(10, 12-10).
+	checkStats(3, 2, big.NewInt(15), big.NewInt(12))
+	// Stats does not modify the heap.
+	checkStats(3, 2, big.NewInt(15), big.NewInt(12))
+
+	// A's next transaction pays 10 + min(50, 100-10).
+	txset.Shift()
+	checkStats(2, 2, big.NewInt(60), big.NewInt(12))
+
+	txset.Pop()
+	checkStats(1, 1, big.NewInt(12), big.NewInt(12))
+
+	txset.Shift()
+	checkStats(0, 0, nil, nil)
+}
+
+func TestTransactionsByPriceAndNonceLookahead(t *testing.T) {
+	t.Parallel()
+
+	// Each sender has transactions with random fee caps, so that the
+	// transactions after the first one below the base fee are dropped.
+	var (
+		baseFee = big.NewInt(10)
+		groups  = make(map[common.Address][]*txpool.LazyTransaction)
+		seen    = 0
+	)
+	for sender := byte(0); sender < 10; sender++ {
+		for i := 0; i < 10; i++ {
+			tx := newOrderingTestTx(sender<<4|byte(i), seen, int64(8+rand.Intn(50)))
+			groups[common.Address{sender}] = append(groups[common.Address{sender}], tx)
+			seen++
+		}
+	}
+	// newSet returns a transaction set of [groups], which the set does not
+	// reown.
+	newSet := func() *transactionsByPriceAndNonce {
+		txs := make(map[common.Address][]*txpool.LazyTransaction, len(groups))
+		for sender, accTxs := range groups {
+			txs[sender] = accTxs
+		}
+		return newTransactionsByPriceAndNonce(nil, txs, baseFee)
+	}
+	var want []*txpool.LazyTransaction
+	for txset := newSet(); txset.Peek() != nil; txset.Shift() {
+		want = append(want, txset.Peek())
+	}
+
+	for _, shifted := range []int{0, 1, 7, len(want)} {
+		txset := newSet()
+		for i := 0; i < shifted; i++ {
+			txset.Shift()
+		}
+		if have := txset.Size(); have < len(want)-shifted {
+			t.Errorf("shifted %d: have size %d, want at least %d", shifted, have, len(want)-shifted)
+		}
+		for _, n := range []int{0, 1, 5, len(want) - shifted, len(want) + 5} {
+			size := txset.Size()
+			have := txset.PeekN(n)
+			wantN := want[shifted:]
+			if n < len(wantN) {
+				wantN = wantN[:n]
+			}
+			if len(have) != len(wantN) {
+				t.Fatalf("shifted %d: have %d transactions peeked for %d, want %d", shifted, len(have), n, len(wantN))
+			}
+			for i := range have {
+				if have[i] != wantN[i] {
+					t.Errorf("shifted %d: peeked transaction %d for %d: have %x, want %x", shifted, i, n, have[i].Hash[0], wantN[i].Hash[0])
+				}
+			}
+			// Peeking does not modify the set.
+			if txset.Size() != size {
+				t.Errorf("shifted %d: size changed from %d to %d by peeking %d", shifted, size, txset.Size(), n)
+			}
+		}
+		var rest []*txpool.LazyTransaction
+		for ; txset.Peek() != nil; txset.Shift() {
+			rest = append(rest, txset.Peek())
+		}
+		if len(rest) != len(want)-shifted {
+			t.Errorf("shifted %d: have %d transactions left after peeking, want %d", shifted, len(rest), len(want)-shifted)
+		}
+	}
+}
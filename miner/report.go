@@ -0,0 +1,237 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"bytes"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// blockBuildingReportsSize is the number of block building reports kept by
+// the worker.
+const blockBuildingReportsSize = 32
+
+// SkipReason is the reason a pending transaction was not included in a block.
+type SkipReason string
+
+const (
+	// SkipFeeBelowBaseFee is a transaction whose fee cap is below the base
+	// fee of the block.
+	SkipFeeBelowBaseFee SkipReason = "feeBelowBaseFee"
+	// SkipGasLimitReached is a transaction whose gas exceeds the gas left in
+	// the block.
+	SkipGasLimitReached SkipReason = "gasLimitReached"
+	// SkipBlobGasLimitReached is a transaction whose blob gas exceeds the
+	// blob gas left in the block.
+	SkipBlobGasLimitReached SkipReason = "blobGasLimitReached"
+	// SkipTxsSizeExceeded is a transaction which would exceed the target size
+	// of the transactions of the block.
+	SkipTxsSizeExceeded SkipReason = "txsSizeExceeded"
+	// SkipBlockFull is a transaction which was not tried, as the block had
+	// too little gas left for any transaction.
+	SkipBlockFull SkipReason = "blockFull"
+	// SkipDeadlineExceeded is a transaction which was not tried, as the
+	// block building deadline expired.
+	SkipDeadlineExceeded SkipReason = "deadlineExceeded"
+	// SkipNonceTooLow is a transaction whose nonce was already used.
+	SkipNonceTooLow SkipReason = "nonceTooLow"
+	// SkipNonceGap is a transaction which was not tried, as a transaction of
+	// the same sender with a lower nonce was not included.
+	SkipNonceGap SkipReason = "nonceGap"
+	// SkipReplayProtected is a replay protected transaction in a block before
+	// EIP-155.
+	SkipReplayProtected SkipReason = "replayProtected"
+	// SkipEvicted is a transaction evicted from the pool while the block was
+	// built.
+	SkipEvicted SkipReason = "evicted"
+	// SkipInvalid is a transaction which failed to apply.
+	SkipInvalid SkipReason = "invalid"
+	// SkipProcessing is a transaction which is already in a processing
+	// ancestor of the block.
+	SkipProcessing SkipReason = "processing"
+	// SkipAtomicConflict is an atomic transaction spending an input spent by
+	// another atomic transaction of the block.
+	SkipAtomicConflict SkipReason = "atomicConflict"
+	// SkipAtomicGasLimitReached is an atomic transaction whose gas exceeds
+	// the atomic gas left in the block.
+	SkipAtomicGasLimitReached SkipReason = "atomicGasLimitReached"
+	// SkipAtomicTxsSizeExceeded is an atomic transaction which would exceed
+	// the target size of the atomic transactions of the block.
+	SkipAtomicTxsSizeExceeded SkipReason = "atomicTxsSizeExceeded"
+	// SkipAtomicInvalid is an atomic transaction which failed verification.
+	SkipAtomicInvalid SkipReason = "atomicInvalid"
+)
+
+// SkippedTxReport is a transaction which was not included in a block, with
+// the reason it was skipped.
+type SkippedTxReport struct {
+	TxID   string     `json:"txID"`
+	Reason SkipReason `json:"reason"`
+	// Error is the error of the transaction for the invalid reasons.
+	Error string `json:"error,omitempty"`
+}
+
+// BlockBuildingReport reports the pending transactions included in and
+// skipped by an attempt to build a block. Transaction IDs are hex hashes for
+// EVM transactions and CB58 IDs for atomic transactions. The transactions
+// filtered out by the pool before the block is filled, such as those of
+// remote senders tipping below the minimum, are not reported.
+type BlockBuildingReport struct {
+	ParentHash common.Hash `json:"parentHash"`
+	Number     uint64      `json:"number"`
+	Timestamp  uint64      `json:"timestamp"`
+	// BlockHash is the hash of the built block, and is nil if building failed
+	// with [Error].
+	BlockHash *common.Hash `json:"blockHash,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	// Truncated is set if the block building deadline expired before all the
+	// pending transactions were tried.
+	Truncated bool              `json:"truncated,omitempty"`
+	Included  []string          `json:"included"`
+	Skipped   []SkippedTxReport `json:"skipped"`
+}
+
+// include records that [txID] was included. It is a no-op on a nil report.
+func (r *BlockBuildingReport) include(txID string) {
+	if r == nil {
+		return
+	}
+	r.Included = append(r.Included, txID)
+}
+
+// skip records that [txID] was skipped for [reason], with [err] if non-nil.
+// It is a no-op on a nil report.
+func (r *BlockBuildingReport) skip(txID string, reason SkipReason, err error) {
+	if r == nil {
+		return
+	}
+	skipped := SkippedTxReport{TxID: txID, Reason: reason}
+	if err != nil {
+		skipped.Error = err.Error()
+	}
+	r.Skipped = append(r.Skipped, skipped)
+}
+
+// skipUntried records the transactions of [pending] which were neither
+// included nor skipped while filling the block with [baseFee]. These were
+// dropped by the ordering or left over once the block was full or the
+// deadline expired.
+func (r *BlockBuildingReport) skipUntried(pending map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) {
+	if r == nil {
+		return
+	}
+	included := make(map[string]bool, len(r.Included))
+	for _, txID := range r.Included {
+		included[txID] = true
+	}
+	skipped := make(map[string]SkipReason, len(r.Skipped))
+	for _, tx := range r.Skipped {
+		skipped[tx.TxID] = tx.Reason
+	}
+
+	// Senders are sorted so that the report does not depend on the order of
+	// the map.
+	senders := make([]common.Address, 0, len(pending))
+	for sender := range pending {
+		senders = append(senders, sender)
+	}
+	slices.SortFunc(senders, func(a, b common.Address) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	for _, sender := range senders {
+		// [gap] is set once a transaction of [sender] is not included, as the
+		// following ones cannot be executed. A transaction with a nonce too
+		// low or already in a processing ancestor does not prevent the
+		// following ones from being executed.
+		gap := false
+		for _, tx := range pending[sender] {
+			txID := tx.Hash.Hex()
+			reason, tried := skipped[txID]
+			switch {
+			case included[txID] || reason == SkipNonceTooLow || reason == SkipProcessing:
+				continue
+			case tried:
+			case gap:
+				r.skip(txID, SkipNonceGap, nil)
+			case baseFee != nil && tx.GasFeeCap.Cmp(baseFee) < 0:
+				r.skip(txID, SkipFeeBelowBaseFee, nil)
+			case r.Truncated:
+				r.skip(txID, SkipDeadlineExceeded, nil)
+			default:
+				r.skip(txID, SkipBlockFull, nil)
+			}
+			gap = true
+		}
+	}
+}
+
+// blockBuildingReports keeps the reports of the last block building attempts
+// in a ring buffer, and the report of the attempt in progress.
+type blockBuildingReports struct {
+	lock    sync.Mutex
+	current *BlockBuildingReport
+	reports [blockBuildingReportsSize]*BlockBuildingReport
+	next    int
+	count   int
+}
+
+// start sets [report] as the report of the attempt in progress.
+func (b *blockBuildingReports) start(report *BlockBuildingReport) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.current = report
+}
+
+// finish adds the report of the attempt in progress to the ring buffer,
+// evicting the oldest report if it is full.
+func (b *blockBuildingReports) finish() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.current == nil {
+		return
+	}
+	b.reports[b.next] = b.current
+	b.next = (b.next + 1) % blockBuildingReportsSize
+	b.count = min(b.count+1, blockBuildingReportsSize)
+	b.current = nil
+}
+
+// includeAtomic records that the atomic transaction [txID] was included in
+// the attempt in progress, if any.
+func (b *blockBuildingReports) includeAtomic(txID ids.ID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.current.include(txID.String())
+}
+
+// skipAtomic records that the atomic transaction [txID] was skipped by the
+// attempt in progress, if any.
+func (b *blockBuildingReports) skipAtomic(txID ids.ID, reason SkipReason, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.current.skip(txID.String(), reason, err)
+}
+
+// list returns the finished reports, oldest first. The reports must not be
+// modified.
+func (b *blockBuildingReports) list() []*BlockBuildingReport {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	reports := make([]*BlockBuildingReport, 0, b.count)
+	for i := b.next - b.count; i < b.next; i++ {
+		reports = append(reports, b.reports[(i+blockBuildingReportsSize)%blockBuildingReportsSize])
+	}
+	return reports
+}
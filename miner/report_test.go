@@ -0,0 +1,87 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlockBuildingReportSkipUntried(t *testing.T) {
+	t.Parallel()
+
+	pending := map[common.Address][]*txpool.LazyTransaction{
+		// 0xa0 is included, and 0xa1 cannot pay the base fee of 10, so 0xa2
+		// has a nonce gap.
+		{0xa}: {newOrderingTestTx(0xa0, 0, 20), newOrderingTestTx(0xa1, 1, 5), newOrderingTestTx(0xa2, 2, 20)},
+		// 0xb0 had a nonce too low and 0xb1 was included, then the block was
+		// full.
+		{0xb}: {newOrderingTestTx(0xb0, 3, 20), newOrderingTestTx(0xb1, 4, 20), newOrderingTestTx(0xb2, 5, 20)},
+		// 0xc0 failed, so 0xc1 has a nonce gap.
+		{0xc}: {newOrderingTestTx(0xc0, 6, 20), newOrderingTestTx(0xc1, 7, 20)},
+	}
+	errFailed := errors.New("failed")
+	report := &BlockBuildingReport{}
+	report.include(common.Hash{0xa0}.Hex())
+	report.skip(common.Hash{0xb0}.Hex(), SkipNonceTooLow, errFailed)
+	report.include(common.Hash{0xb1}.Hex())
+	report.skip(common.Hash{0xc0}.Hex(), SkipInvalid, errFailed)
+
+	report.skipUntried(pending, big.NewInt(10))
+	want := []SkippedTxReport{
+		{TxID: common.Hash{0xb0}.Hex(), Reason: SkipNonceTooLow, Error: "failed"},
+		{TxID: common.Hash{0xc0}.Hex(), Reason: SkipInvalid, Error: "failed"},
+		{TxID: common.Hash{0xa1}.Hex(), Reason: SkipFeeBelowBaseFee},
+		{TxID: common.Hash{0xa2}.Hex(), Reason: SkipNonceGap},
+		{TxID: common.Hash{0xb2}.Hex(), Reason: SkipBlockFull},
+		{TxID: common.Hash{0xc1}.Hex(), Reason: SkipNonceGap},
+	}
+	if !reflect.DeepEqual(report.Skipped, want) {
+		t.Errorf("have skipped %v, want %v", report.Skipped, want)
+	}
+
+	// Reporting is a no-op without a report.
+	var nilReport *BlockBuildingReport
+	nilReport.include(common.Hash{0xa0}.Hex())
+	nilReport.skipUntried(pending, big.NewInt(10))
+}
+
+func TestBlockBuildingReports(t *testing.T) {
+	t.Parallel()
+
+	var reports blockBuildingReports
+	// Atomic transactions are not recorded outside of an attempt.
+	reports.includeAtomic(ids.GenerateTestID())
+	reports.finish()
+	if have := reports.list(); len(have) != 0 {
+		t.Fatalf("have %d reports, want none", len(have))
+	}
+
+	txID := ids.GenerateTestID()
+	for i := 0; i < blockBuildingReportsSize+2; i++ {
+		reports.start(&BlockBuildingReport{Number: uint64(i)})
+		reports.skipAtomic(txID, SkipAtomicConflict, nil)
+		reports.finish()
+	}
+	have := reports.list()
+	if len(have) != blockBuildingReportsSize {
+		t.Fatalf("have %d reports, want %d", len(have), blockBuildingReportsSize)
+	}
+	// The oldest reports are evicted.
+	for i, report := range have {
+		if want := uint64(i + 2); report.Number != want {
+			t.Errorf("report %d: have number %d, want %d", i, report.Number, want)
+		}
+		want := []SkippedTxReport{{TxID: txID.String(), Reason: SkipAtomicConflict}}
+		if !reflect.DeepEqual(report.Skipped, want) {
+			t.Errorf("report %d: have skipped %v, want %v", i, report.Skipped, want)
+		}
+	}
+}
@@ -30,10 +30,12 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
@@ -46,9 +48,11 @@ import (
 	"github.com/ava-labs/coreth/core/txpool"
 	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/core/vm"
+	"github.com/ava-labs/coreth/metrics"
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ava-labs/coreth/predicate"
+	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -60,6 +64,24 @@ const (
 	targetTxsSize = 1792 * units.KiB
 )
 
+var (
+	errUnknownParent          = errors.New("unknown parent block")
+	errTimestampBeforeParent  = errors.New("timestamp before parent block")
+	errTxsSizeExceeded        = errors.New("transactions exceed target block size")
+	errReplayProtectedPreFork = errors.New("replay protected transaction before EIP-155")
+
+	// truncatedBlocksCounter counts the blocks whose transactions were cut
+	// short by the block building deadline.
+	truncatedBlocksCounter = metrics.NewRegisteredCounter("miner/blocks/truncated", nil)
+)
+
+// SkippedTx is a transaction which was not included in a block built from an
+// explicit list of transactions, with the reason it was skipped.
+type SkippedTx struct {
+	Tx  *types.Transaction
+	Err error
+}
+
 // environment is the worker's current environment and holds all of the current state information.
 type environment struct {
 	signer  types.Signer
@@ -67,6 +89,10 @@ type environment struct {
 	tcount  int            // tx count in cycle
 	gasPool *core.GasPool  // available gas used to pack transactions
 
+	// migrationGas is the gas charged to the block for precompile storage
+	// migrations, which is not included in the receipts.
+	migrationGas uint64
+
 	parent   *types.Header
 	header   *types.Header
 	txs      []*types.Transaction
@@ -83,6 +109,13 @@ type environment struct {
 	// way that the gas pool and state is reset.
 	predicateResults *predicate.Results
 
+	// report records the transactions included and skipped, if the block is
+	// built from the pending transactions of the pool.
+	report *BlockBuildingReport
+	// truncated is set if the block building deadline expired before all the
+	// pending transactions were tried.
+	truncated bool
+
 	start time.Time // Time that block building began
 }
 
@@ -95,6 +128,17 @@ type worker struct {
 	eth         Backend
 	chain       *core.BlockChain
 
+	// newTransactions creates the set of pending transactions ordered by
+	// the ordering policy of [config].
+	newTransactions newOrderedTransactionsFunc
+	// prioritizeLocals is set if the transactions of local senders are
+	// committed before those of remote senders.
+	prioritizeLocals atomic.Bool
+
+	// reports are the reports of the last blocks built from the pending
+	// transactions of the pool.
+	reports blockBuildingReports
+
 	// Feeds
 	// TODO remove since this will never be written to
 	pendingLogsFeed event.Feed
@@ -105,20 +149,29 @@ type worker struct {
 	coinbase   common.Address
 	clock      *mockable.Clock // Allows us mock the clock for testing
 	beaconRoot *common.Hash    // TODO: set to empty hash, retained for upstream compatibility and future use
+
+	// processingTxs are the transactions of the processing ancestors of the
+	// blocks built, which are not included again. It is protected by [mu].
+	processingTxs ProcessingTxs
 }
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, clock *mockable.Clock) *worker {
+	if err := config.OrderingPolicy.Validate(); err != nil {
+		log.Warn("Ordering transactions by price", "err", err)
+	}
 	worker := &worker{
-		config:      config,
-		chainConfig: chainConfig,
-		engine:      engine,
-		eth:         eth,
-		chain:       eth.BlockChain(),
-		mux:         mux,
-		coinbase:    config.Etherbase,
-		clock:       clock,
-		beaconRoot:  &common.Hash{},
+		config:          config,
+		chainConfig:     chainConfig,
+		engine:          engine,
+		eth:             eth,
+		chain:           eth.BlockChain(),
+		newTransactions: orderingFunc(config.OrderingPolicy),
+		mux:             mux,
+		coinbase:        config.Etherbase,
+		clock:           clock,
+		beaconRoot:      &common.Hash{},
 	}
+	worker.prioritizeLocals.Store(!config.DisableLocalsPriority)
 
 	return worker
 }
@@ -130,8 +183,24 @@ func (w *worker) setEtherbase(addr common.Address) {
 	w.coinbase = addr
 }
 
+// setProcessingTxs sets the transactions of the processing ancestors of the
+// blocks built.
+func (w *worker) setProcessingTxs(txs ProcessingTxs) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.processingTxs = txs
+}
+
+// setPrioritizeLocals sets whether the transactions of local senders are
+// committed before those of remote senders.
+func (w *worker) setPrioritizeLocals(prioritize bool) {
+	w.prioritizeLocals.Store(prioritize)
+}
+
 // commitNewWork generates several new sealing tasks based on the parent block.
-func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
+// Once [ctx] is done, no more pending transactions are tried, and the block is
+// built from those already executed.
+func (w *worker) commitNewWork(ctx context.Context, predicateContext *precompileconfig.PredicateContext) (*types.Block, error) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
@@ -145,6 +214,61 @@ func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateConte
 		timestamp = parent.Time
 	}
 
+	env, err := w.prepareWork(predicateContext, parent, timestamp, tstart)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure we always stop prefetcher after block building is complete.
+	defer env.state.StopPrefetcher()
+
+	pending := w.eth.TxPool().PendingWithBaseFee(true, env.header.BaseFee)
+	// The orderings reown the pending transactions, so a copy is kept to
+	// report those left untried.
+	reported := make(map[common.Address][]*txpool.LazyTransaction, len(pending))
+	for sender, txs := range pending {
+		reported[sender] = txs
+	}
+	env.report = &BlockBuildingReport{
+		ParentHash: parent.Hash(),
+		Number:     env.header.Number.Uint64(),
+		Timestamp:  env.header.Time,
+	}
+	w.reports.start(env.report)
+	defer w.reports.finish()
+
+	// Fill the block with all available pending transactions.
+	for _, group := range groupPending(pending, w.eth.TxPool().Locals(), w.prioritizeLocals.Load()) {
+		txs := w.newTransactions(env.signer, group, env.header.BaseFee)
+		w.commitTransactions(ctx, env, txs, env.header.Coinbase)
+		if env.truncated {
+			break
+		}
+	}
+	if env.truncated {
+		log.Debug("Block building deadline expired", "number", env.header.Number, "txs", env.tcount, "elapsed", common.PrettyDuration(time.Since(env.start)))
+		truncatedBlocksCounter.Inc(1)
+		env.report.Truncated = true
+	}
+	env.report.skipUntried(reported, env.header.BaseFee)
+
+	block, err := w.commit(env)
+	if err != nil {
+		env.report.Error = err.Error()
+		return nil, err
+	}
+	hash := block.Hash()
+	env.report.BlockHash = &hash
+	return block, nil
+}
+
+// prepareWork creates the environment of a block built on top of [parent] at
+// [timestamp], applying the upgrades activated by the block. The caller must
+// hold [w.mu] and stop the prefetcher of the returned state.
+func (w *worker) prepareWork(predicateContext *precompileconfig.PredicateContext, parent *types.Header, timestamp uint64, tstart time.Time) (*environment, error) {
+	if err := w.chainConfig.ValidateAtBlockTimestamp(timestamp); err != nil {
+		return nil, fmt.Errorf("invalid chain config at timestamp %d: %w", timestamp, err)
+	}
+
 	var gasLimit uint64
 	if w.chainConfig.IsCortina(timestamp) {
 		gasLimit = params.CortinaGasLimit
@@ -202,42 +326,53 @@ func (w *worker) commitNewWork(predicateContext *precompileconfig.PredicateConte
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
-	// Ensure we always stop prefetcher after block building is complete.
-	defer func() {
-		if env.state == nil {
-			return
-		}
-		env.state.StopPrefetcher()
-	}()
 	// Configure any upgrades that should go into effect during this block.
 	err = core.ApplyUpgrades(w.chainConfig, &parent.Time, types.NewBlockWithHeader(header), env.state)
 	if err != nil {
 		log.Error("failed to configure precompiles mining new block", "parent", parent.Hash(), "number", header.Number, "timestamp", header.Time, "err", err)
+		env.state.StopPrefetcher()
 		return nil, err
 	}
+	// Reserve the gas charged to the block for the precompile storage
+	// migrations applied above. It is added to the gas used by the block in
+	// [commit], so that it is not included in the cumulative gas used of the
+	// receipts.
+	env.migrationGas = w.chainConfig.PrecompileStorageMigrationGas(&parent.Time, header.Time)
+	if err := env.gasPool.SubGas(env.migrationGas); err != nil {
+		env.state.StopPrefetcher()
+		return nil, fmt.Errorf("could not charge precompile storage migration gas: %w", err)
+	}
+	return env, nil
+}
 
-	pending := w.eth.TxPool().PendingWithBaseFee(true, header.BaseFee)
+// groupPending returns the groups of [pending] transactions to commit, in
+// order. If [prioritizeLocals] is set, the transactions of the [locals]
+// senders are committed first, in their own group. Otherwise, all the pending
+// transactions are ordered together. Empty groups are omitted.
+func groupPending(pending map[common.Address][]*txpool.LazyTransaction, locals []common.Address, prioritizeLocals bool) []map[common.Address][]*txpool.LazyTransaction {
+	if len(pending) == 0 {
+		return nil
+	}
+	if !prioritizeLocals {
+		return []map[common.Address][]*txpool.LazyTransaction{pending}
+	}
 
 	// Split the pending transactions into locals and remotes.
 	localTxs, remoteTxs := make(map[common.Address][]*txpool.LazyTransaction), pending
-	for _, account := range w.eth.TxPool().Locals() {
+	for _, account := range locals {
 		if txs := remoteTxs[account]; len(txs) > 0 {
 			delete(remoteTxs, account)
 			localTxs[account] = txs
 		}
 	}
-
-	// Fill the block with all available pending transactions.
+	var groups []map[common.Address][]*txpool.LazyTransaction
 	if len(localTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, localTxs, header.BaseFee)
-		w.commitTransactions(env, txs, header.Coinbase)
+		groups = append(groups, localTxs)
 	}
 	if len(remoteTxs) > 0 {
-		txs := newTransactionsByPriceAndNonce(env.signer, remoteTxs, header.BaseFee)
-		w.commitTransactions(env, txs, header.Coinbase)
+		groups = append(groups, remoteTxs)
 	}
-
-	return w.commit(env)
+	return groups
 }
 
 func (w *worker) createCurrentEnvironment(predicateContext *precompileconfig.PredicateContext, parent *types.Header, header *types.Header, tstart time.Time) (*environment, error) {
@@ -317,6 +452,8 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction, coinb
 	} else {
 		blockContext = core.NewEVMBlockContext(env.header, w.chain, &coinbase)
 	}
+	blockContext.Rules = &env.rules
+	blockContext.BlockGasRemaining = env.gasPool.Gas
 
 	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, blockContext, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
 	if err != nil {
@@ -327,8 +464,14 @@ func (w *worker) applyTransaction(env *environment, tx *types.Transaction, coinb
 	return receipt, err
 }
 
-func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAndNonce, coinbase common.Address) {
+func (w *worker) commitTransactions(ctx context.Context, env *environment, txs orderedTransactions, coinbase common.Address) {
 	for {
+		// If the deadline expired, the block is built from the transactions
+		// already executed.
+		if ctx.Err() != nil {
+			env.truncated = true
+			break
+		}
 		// If we don't have enough gas for any further transactions then we're done.
 		if env.gasPool.Gas() < params.TxGas {
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
@@ -339,14 +482,24 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 		if ltx == nil {
 			break
 		}
+		// Skip the transactions already included in a processing ancestor,
+		// which could not be included again once it is accepted.
+		if w.processingTxs != nil && w.processingTxs.Has(ltx.Hash) {
+			log.Trace("Skipping transaction in processing block", "hash", ltx.Hash)
+			env.report.skip(ltx.Hash.Hex(), SkipProcessing, nil)
+			txs.Shift()
+			continue
+		}
 		// If we don't have enough space for the next transaction, skip the account.
 		if env.gasPool.Gas() < ltx.Gas {
 			log.Trace("Not enough gas left for transaction", "hash", ltx.Hash, "left", env.gasPool.Gas(), "needed", ltx.Gas)
+			env.report.skip(ltx.Hash.Hex(), SkipGasLimitReached, nil)
 			txs.Pop()
 			continue
 		}
 		if left := uint64(params.MaxBlobGasPerBlock - env.blobs*params.BlobTxBlobGasPerBlob); left < ltx.BlobGas {
 			log.Trace("Not enough blob gas left for transaction", "hash", ltx.Hash, "left", left, "needed", ltx.BlobGas)
+			env.report.skip(ltx.Hash.Hex(), SkipBlobGasLimitReached, nil)
 			txs.Pop()
 			continue
 		}
@@ -354,6 +507,7 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 		tx := ltx.Resolve()
 		if tx == nil {
 			log.Trace("Ignoring evicted transaction", "hash", ltx.Hash)
+			env.report.skip(ltx.Hash.Hex(), SkipEvicted, nil)
 			txs.Pop()
 			continue
 		}
@@ -361,6 +515,7 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 		// transction that will fit.
 		if totalTxsSize := env.size + tx.Size(); totalTxsSize > targetTxsSize {
 			log.Trace("Skipping transaction that would exceed target size", "hash", tx.Hash(), "totalTxsSize", totalTxsSize, "txSize", tx.Size())
+			env.report.skip(ltx.Hash.Hex(), SkipTxsSizeExceeded, nil)
 			txs.Pop()
 			continue
 		}
@@ -373,6 +528,7 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 		// phase, start ignoring the sender until we do.
 		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
 			log.Trace("Ignoring replay protected transaction", "hash", ltx.Hash, "eip155", w.chainConfig.EIP155Block)
+			env.report.skip(ltx.Hash.Hex(), SkipReplayProtected, nil)
 			txs.Pop()
 			continue
 		}
@@ -382,24 +538,105 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 
 		_, err := w.commitTransaction(env, tx, coinbase)
 		switch {
-		case errors.Is(err, core.ErrNonceTooLow):
+		case errors.Is(err, vmerrs.ErrNonceTooLow):
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "hash", ltx.Hash, "sender", from, "nonce", tx.Nonce())
+			env.report.skip(ltx.Hash.Hex(), SkipNonceTooLow, err)
 			txs.Shift()
 
 		case errors.Is(err, nil):
 			env.tcount++
+			env.report.include(ltx.Hash.Hex())
 			txs.Shift()
 
 		default:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
 			// the same sender because of `nonce-too-high` clause.
 			log.Debug("Transaction failed, account skipped", "hash", ltx.Hash, "err", err)
+			env.report.skip(ltx.Hash.Hex(), SkipInvalid, err)
 			txs.Pop()
 		}
 	}
 }
 
+// commitTransactionsForced commits [txs] in order, skipping the transactions
+// which cannot be included in the block of [env]. Unlike commitTransactions,
+// a skipped transaction does not cause the next transactions of its sender to
+// be skipped, but they fail their nonce check.
+func (w *worker) commitTransactionsForced(txs types.Transactions, env *environment) []*SkippedTx {
+	var skipped []*SkippedTx
+	for _, tx := range txs {
+		if err := w.checkForcedTransaction(env, tx); err != nil {
+			log.Trace("Skipping forced transaction", "hash", tx.Hash(), "err", err)
+			skipped = append(skipped, &SkippedTx{Tx: tx, Err: err})
+			continue
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if _, err := w.commitTransaction(env, tx, env.header.Coinbase); err != nil {
+			log.Trace("Skipping failed forced transaction", "hash", tx.Hash(), "err", err)
+			skipped = append(skipped, &SkippedTx{Tx: tx, Err: err})
+			continue
+		}
+		env.tcount++
+	}
+	return skipped
+}
+
+// checkForcedTransaction returns an error if [tx] cannot fit in the block of
+// [env] or is invalid regardless of the state. Nonces and fees are checked
+// when the transaction is applied.
+func (w *worker) checkForcedTransaction(env *environment, tx *types.Transaction) error {
+	if env.gasPool.Gas() < tx.Gas() {
+		return fmt.Errorf("%w: have %d, want %d", core.ErrGasLimitReached, env.gasPool.Gas(), tx.Gas())
+	}
+	if totalTxsSize := env.size + tx.Size(); totalTxsSize > targetTxsSize {
+		return fmt.Errorf("%w: %d > %d", errTxsSizeExceeded, totalTxsSize, targetTxsSize)
+	}
+	if _, err := types.Sender(env.signer, tx); err != nil {
+		return err
+	}
+	if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number) {
+		return errReplayProtectedPreFork
+	}
+	return nil
+}
+
+// commitForcedWork builds a block on top of [parentHash] at [timestamp],
+// containing the transactions of [txs] which can be included, in order. It
+// returns the block, which is not inserted in the chain, and the skipped
+// transactions.
+func (w *worker) commitForcedWork(ctx context.Context, parentHash common.Hash, txs types.Transactions, timestamp uint64) (*types.Block, []*SkippedTx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	tstart := w.clock.Time()
+	parent := w.chain.GetHeaderByHash(parentHash)
+	if parent == nil {
+		return nil, nil, fmt.Errorf("%w: %s", errUnknownParent, parentHash)
+	}
+	if timestamp < parent.Time {
+		return nil, nil, fmt.Errorf("%w: %d < %d", errTimestampBeforeParent, timestamp, parent.Time)
+	}
+	// Transactions with predicates are skipped, as there is no proposer block
+	// context to verify them with.
+	env, err := w.prepareWork(nil, parent, timestamp, tstart)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer env.state.StopPrefetcher()
+
+	skipped := w.commitTransactionsForced(txs, env)
+	block, err := w.commit(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, skipped, nil
+}
+
 // commit runs any post-transaction state modifications, assembles the final block
 // and commits new work if consensus engine is running.
 func (w *worker) commit(env *environment) (*types.Block, error) {
@@ -410,6 +647,7 @@ func (w *worker) commit(env *environment) (*types.Block, error) {
 		}
 		env.header.Extra = append(env.header.Extra, predicateResultsBytes...)
 	}
+	env.header.GasUsed += env.migrationGas
 	// Deep copy receipts here to avoid interaction between different tasks.
 	receipts := copyReceipts(env.receipts)
 	block, err := w.engine.FinalizeAndAssemble(w.chain, env.header, env.parent, env.state, env.txs, nil, receipts)
@@ -0,0 +1,121 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGroupPendingPrioritizeLocals(t *testing.T) {
+	t.Parallel()
+
+	var (
+		local  = common.Address{0x1}
+		remote = common.Address{0x2}
+	)
+	tests := []struct {
+		prioritizeLocals bool
+		want             []byte
+	}{
+		// The low tip local transaction is committed first only if locals
+		// are prioritized.
+		{prioritizeLocals: true, want: []byte{0x10, 0x20}},
+		{prioritizeLocals: false, want: []byte{0x20, 0x10}},
+	}
+	for _, test := range tests {
+		pending := map[common.Address][]*txpool.LazyTransaction{
+			local:  {newOrderingTestTx(0x10, 0, 11)},
+			remote: {newOrderingTestTx(0x20, 1, 100)},
+		}
+		var have []byte
+		for _, group := range groupPending(pending, []common.Address{local}, test.prioritizeLocals) {
+			have = append(have, drain(orderingFunc(OrderingPrice)(nil, group, big.NewInt(10)), 0)...)
+		}
+		if string(have) != string(test.want) {
+			t.Errorf("prioritize locals %t: have order %x, want %x", test.prioritizeLocals, have, test.want)
+		}
+	}
+}
+
+// nilBackend is a Backend without a chain or a transaction pool.
+type nilBackend struct{}
+
+func (nilBackend) BlockChain() *core.BlockChain { return nil }
+func (nilBackend) TxPool() *txpool.TxPool       { return nil }
+
+func TestWorkerPrioritizesLocalsByDefault(t *testing.T) {
+	t.Parallel()
+
+	for _, disable := range []bool{false, true} {
+		w := newWorker(&Config{DisableLocalsPriority: disable}, params.TestChainConfig, nil, nilBackend{}, nil, nil)
+		if have := w.prioritizeLocals.Load(); have == disable {
+			t.Errorf("disable locals priority %t: have prioritize locals %t", disable, have)
+		}
+	}
+}
+
+// slowResolver resolves transactions once [ctx] is done, as evicted.
+type slowResolver struct {
+	ctx      context.Context
+	resolved int
+}
+
+func (r *slowResolver) Get(common.Hash) *types.Transaction {
+	<-r.ctx.Done()
+	r.resolved++
+	return nil
+}
+
+func TestCommitTransactionsDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	resolver := &slowResolver{ctx: ctx}
+	pending := map[common.Address][]*txpool.LazyTransaction{
+		{0xa}: {newOrderingTestTx(0xa0, 0, 20), newOrderingTestTx(0xa1, 1, 20)},
+		{0xb}: {newOrderingTestTx(0xb0, 2, 15)},
+	}
+	reported := make(map[common.Address][]*txpool.LazyTransaction, len(pending))
+	for sender, txs := range pending {
+		reported[sender] = txs
+		for _, tx := range txs {
+			tx.Pool = resolver
+		}
+	}
+	env := &environment{
+		gasPool: new(core.GasPool).AddGas(10 * params.TxGas),
+		report:  &BlockBuildingReport{},
+	}
+	w := &worker{}
+	// Resolving 0xa0 outlasts the deadline, so no other transaction is tried.
+	w.commitTransactions(ctx, env, orderingFunc(OrderingPrice)(nil, pending, big.NewInt(10)), common.Address{})
+	if !env.truncated {
+		t.Fatal("block building not truncated by the deadline")
+	}
+	if resolver.resolved != 1 {
+		t.Errorf("have %d transactions resolved, want 1", resolver.resolved)
+	}
+
+	env.report.Truncated = env.truncated
+	env.report.skipUntried(reported, big.NewInt(10))
+	want := []SkippedTxReport{
+		{TxID: common.Hash{0xa0}.Hex(), Reason: SkipEvicted},
+		{TxID: common.Hash{0xa1}.Hex(), Reason: SkipNonceGap},
+		{TxID: common.Hash{0xb0}.Hex(), Reason: SkipDeadlineExceeded},
+	}
+	if !reflect.DeepEqual(env.report.Skipped, want) {
+		t.Errorf("have skipped %v, want %v", env.report.Skipped, want)
+	}
+}
@@ -37,6 +37,17 @@ const (
 
 	// The base cost to charge per atomic transaction. Added in Apricot Phase 5.
 	AtomicTxBaseCost uint64 = 10_000
+
+	// The gas charged to a block for each precompile storage migration which
+	// activates in it.
+	PrecompileStorageMigrationGas uint64 = 1_000_000
+
+	// Limits on the contents of each atomic transaction, enforced as of the
+	// atomic tx limits upgrade.
+	AtomicTxMaxSize        = 32 * units.KiB // maximum size of the signed tx bytes
+	AtomicTxMaxInputs      = 128
+	AtomicTxMaxOutputs     = 128
+	AtomicTxMaxCredentials = 128
 )
 
 // The atomic gas limit specifies the maximum amount of gas that can be consumed by the atomic
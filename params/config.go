@@ -34,6 +34,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/coreth/precompile/contract"
 	"github.com/ava-labs/coreth/precompile/modules"
 	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ava-labs/coreth/utils"
@@ -550,6 +551,44 @@ type ChainConfig struct {
 	// Verkle activates the Verkle upgrade from Ethereum. (nil = no fork, 0 = already activated)
 	VerkleTime *uint64 `json:"verkleTime,omitempty"` // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	// PrecompileGasScheduleTimestamp activates [PrecompileGasSchedule] for
+	// stateful precompiles. Blocks before this timestamp are charged the
+	// default schedule. (nil = no fork, 0 = already activated)
+	PrecompileGasScheduleTimestamp *uint64 `json:"precompileGasScheduleTimestamp,omitempty"`
+	// PrecompileGasSchedule overrides the gas charged by stateful precompiles
+	// once [PrecompileGasScheduleTimestamp] is reached.
+	// Fields which are not specified keep their default values. (nil = default schedule)
+	PrecompileGasSchedule *contract.GasSchedule `json:"precompileGasSchedule,omitempty"`
+
+	// NativeAssetCallGasTableTimestamp activates [NativeAssetCallGasTable] for the
+	// NativeAssetCall precompile. Blocks before this timestamp are charged the
+	// Apricot gas costs. (nil = no fork, 0 = already activated)
+	NativeAssetCallGasTableTimestamp *uint64 `json:"nativeAssetCallGasTableTimestamp,omitempty"`
+	// NativeAssetCallGasTable specifies the gas charged by the NativeAssetCall
+	// precompile once [NativeAssetCallGasTableTimestamp] is reached.
+	// Fields which are not specified keep their default values. (nil = default table)
+	NativeAssetCallGasTable *NativeAssetCallGasTable `json:"nativeAssetCallGasTable,omitempty"`
+
+	// StorageLastModifiedTimestamp activates tracking of the block number at
+	// which each storage slot was last modified by SSTORE, which is exposed to
+	// stateful precompiles. (nil = no fork, 0 = already activated)
+	StorageLastModifiedTimestamp *uint64 `json:"storageLastModifiedTimestamp,omitempty"`
+
+	// AtomicTxLimitsTimestamp activates the limits on the size, inputs,
+	// outputs and credentials of each atomic transaction.
+	// (nil = no fork, 0 = already activated)
+	AtomicTxLimitsTimestamp *uint64 `json:"atomicTxLimitsTimestamp,omitempty"`
+
+	// SubnetCreatorAddress is the address of the creator of the subnet, which
+	// stateful precompiles can use to authorize governance operations. It is
+	// set in the genesis and cannot change afterwards. (zero = no creator)
+	SubnetCreatorAddress common.Address `json:"subnetCreatorAddress,omitempty"`
+
+	// MinerFeeRecipient is the address to which stateful precompiles route
+	// the share of the fees of a block which does not go to its coinbase. It
+	// is set in the genesis and cannot change afterwards. (zero = coinbase)
+	MinerFeeRecipient common.Address `json:"minerFeeRecipient,omitempty"`
+
 	UpgradeConfig `json:"-"` // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
 }
 
@@ -733,6 +772,30 @@ func (c *ChainConfig) IsEUpgrade(time uint64) bool {
 	return utils.IsTimestampForked(c.EUpgradeTime, time)
 }
 
+// IsPrecompileGasSchedule returns whether [time] represents a block
+// with a timestamp after the precompile gas schedule activation time.
+func (c *ChainConfig) IsPrecompileGasSchedule(time uint64) bool {
+	return utils.IsTimestampForked(c.PrecompileGasScheduleTimestamp, time)
+}
+
+// IsNativeAssetCallGasTable returns whether [time] represents a block
+// with a timestamp after the NativeAssetCall gas table activation time.
+func (c *ChainConfig) IsNativeAssetCallGasTable(time uint64) bool {
+	return utils.IsTimestampForked(c.NativeAssetCallGasTableTimestamp, time)
+}
+
+// IsStorageLastModified returns whether [time] represents a block
+// with a timestamp after the storage last modified tracking activation time.
+func (c *ChainConfig) IsStorageLastModified(time uint64) bool {
+	return utils.IsTimestampForked(c.StorageLastModifiedTimestamp, time)
+}
+
+// IsAtomicTxLimits returns whether [time] represents a block
+// with a timestamp after the atomic tx limits activation time.
+func (c *ChainConfig) IsAtomicTxLimits(time uint64) bool {
+	return utils.IsTimestampForked(c.AtomicTxLimitsTimestamp, time)
+}
+
 // IsCancun returns whether [time] represents a block
 // with a timestamp after the Cancun upgrade time.
 func (c *ChainConfig) IsCancun(num *big.Int, time uint64) bool {
@@ -767,6 +830,11 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, time u
 		bhead = new(big.Int).SetUint64(height)
 		btime = time
 	)
+	// The fields set in the genesis cannot be corrected by rewinding, so they
+	// are checked outside of the rewind loop.
+	if err := c.checkGenesisCompatible(newcfg, height); err != nil {
+		return err
+	}
 	// Iterate checkCompatible to find the lowest conflict.
 	var lasterr *ConfigCompatError
 	for {
@@ -785,12 +853,34 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, time u
 	return lasterr
 }
 
+// checkGenesisCompatible checks that the fields of [newcfg] which can only be
+// set in the genesis match [c]. They may change until a block is accepted
+// after the genesis, at [height] 0.
+func (c *ChainConfig) checkGenesisCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
+	if height == 0 {
+		return nil
+	}
+	if c.SubnetCreatorAddress != newcfg.SubnetCreatorAddress {
+		return newGenesisCompatError("subnet creator address")
+	}
+	if c.MinerFeeRecipient != newcfg.MinerFeeRecipient {
+		return newGenesisCompatError("miner fee recipient")
+	}
+	return nil
+}
+
 // Verify verifies chain config and returns error
 func (c *ChainConfig) Verify() error {
 	// Verify the precompile upgrades are internally consistent given the existing chainConfig.
 	if err := c.verifyPrecompileUpgrades(); err != nil {
 		return fmt.Errorf("invalid precompile upgrades: %w", err)
 	}
+	if err := c.verifyPrecompileStorageMigrations(); err != nil {
+		return fmt.Errorf("invalid precompile storage migrations: %w", err)
+	}
+	if err := c.verifyPrecompileGasMultipliers(); err != nil {
+		return fmt.Errorf("invalid precompile gas multipliers: %w", err)
+	}
 
 	return nil
 }
@@ -835,6 +925,11 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	if err := checkForks(c.forkOrder(), false); err != nil {
 		return err
 	}
+	for _, forks := range c.featureForkOrders() {
+		if err := checkForks(forks, false); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -889,6 +984,46 @@ func checkForks(forks []fork, blockFork bool) error {
 	return nil
 }
 
+// ValidateAtBlockTimestamp checks that the upgrades active at [timestamp] are
+// consistent, applying the checks of [CheckConfigForkOrder] and
+// [verifyPrecompileUpgrades] to the upgrades that are active at [timestamp].
+// This catches configs that were changed after the node started, or that
+// were never verified, before a block is built or processed with them.
+func (c *ChainConfig) ValidateAtBlockTimestamp(timestamp uint64) error {
+	if err := checkForks(forksActiveAt(c.forkOrder(), timestamp), false); err != nil {
+		return fmt.Errorf("invalid upgrades at timestamp %d: %w", timestamp, err)
+	}
+	for _, forks := range c.featureForkOrders() {
+		if err := checkForks(forksActiveAt(forks, timestamp), false); err != nil {
+			return fmt.Errorf("invalid upgrades at timestamp %d: %w", timestamp, err)
+		}
+	}
+
+	var active []PrecompileUpgrade
+	for _, upgrade := range c.PrecompileUpgrades {
+		if utils.IsTimestampForked(upgrade.Timestamp(), timestamp) {
+			active = append(active, upgrade)
+		}
+	}
+	if err := checkPrecompileUpgradeOrder(active); err != nil {
+		return fmt.Errorf("invalid upgrades at timestamp %d: %w", timestamp, err)
+	}
+	return nil
+}
+
+// forksActiveAt returns a copy of [forks] in which the forks that are not
+// active at [timestamp] are unset.
+func forksActiveAt(forks []fork, timestamp uint64) []fork {
+	active := make([]fork, len(forks))
+	for i, f := range forks {
+		active[i] = f
+		if !utils.IsTimestampForked(f.timestamp, timestamp) {
+			active[i].timestamp = nil
+		}
+	}
+	return active
+}
+
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, height *big.Int, time uint64) *ConfigCompatError {
 	if isForkBlockIncompatible(c.HomesteadBlock, newcfg.HomesteadBlock, height) {
 		return newBlockCompatError("Homestead fork block", c.HomesteadBlock, newcfg.HomesteadBlock)
@@ -966,12 +1101,38 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, height *big.Int, time
 	if isForkTimestampIncompatible(c.EUpgradeTime, newcfg.EUpgradeTime, time) {
 		return newTimestampCompatError("EUpgrade fork block timestamp", c.EUpgradeTime, newcfg.EUpgradeTime)
 	}
+	if isForkTimestampIncompatible(c.PrecompileGasScheduleTimestamp, newcfg.PrecompileGasScheduleTimestamp, time) {
+		return newTimestampCompatError("precompile gas schedule timestamp", c.PrecompileGasScheduleTimestamp, newcfg.PrecompileGasScheduleTimestamp)
+	}
+	// The schedule cannot change once it is active.
+	if c.GetPrecompileGasSchedule(time) != newcfg.GetPrecompileGasSchedule(time) {
+		return newTimestampCompatError("precompile gas schedule", c.PrecompileGasScheduleTimestamp, newcfg.PrecompileGasScheduleTimestamp)
+	}
+	if isForkTimestampIncompatible(c.NativeAssetCallGasTableTimestamp, newcfg.NativeAssetCallGasTableTimestamp, time) {
+		return newTimestampCompatError("NativeAssetCall gas table timestamp", c.NativeAssetCallGasTableTimestamp, newcfg.NativeAssetCallGasTableTimestamp)
+	}
+	// The table cannot change once it is active.
+	if c.GetNativeAssetCallGasTable(time) != newcfg.GetNativeAssetCallGasTable(time) {
+		return newTimestampCompatError("NativeAssetCall gas table", c.NativeAssetCallGasTableTimestamp, newcfg.NativeAssetCallGasTableTimestamp)
+	}
+	if isForkTimestampIncompatible(c.StorageLastModifiedTimestamp, newcfg.StorageLastModifiedTimestamp, time) {
+		return newTimestampCompatError("storage last modified timestamp", c.StorageLastModifiedTimestamp, newcfg.StorageLastModifiedTimestamp)
+	}
+	if isForkTimestampIncompatible(c.AtomicTxLimitsTimestamp, newcfg.AtomicTxLimitsTimestamp, time) {
+		return newTimestampCompatError("atomic tx limits timestamp", c.AtomicTxLimitsTimestamp, newcfg.AtomicTxLimitsTimestamp)
+	}
 	if isForkTimestampIncompatible(c.CancunTime, newcfg.CancunTime, time) {
 		return newTimestampCompatError("Cancun fork block timestamp", c.CancunTime, newcfg.CancunTime)
 	}
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, time) {
 		return newTimestampCompatError("Verkle fork block timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if err := c.checkPrecompileStorageMigrationsCompatible(newcfg.PrecompileStorageMigrations, time); err != nil {
+		return err
+	}
+	if err := c.checkPrecompileGasMultipliersCompatible(newcfg.PrecompileGasMultipliers, time); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -1024,6 +1185,10 @@ type ConfigCompatError struct {
 
 	// the timestamp to which the local chain must be rewound to correct the error
 	RewindToTime uint64
+
+	// set if the mismatching field can only be set in the genesis, in which
+	// case rewinding cannot correct the error and the chain must be resynced
+	Genesis bool
 }
 
 func newBlockCompatError(what string, storedblock, newblock *big.Int) *ConfigCompatError {
@@ -1070,7 +1235,19 @@ func newTimestampCompatError(what string, storedtime, newtime *uint64) *ConfigCo
 	return err
 }
 
+// newGenesisCompatError returns the error for a mismatching field [what] which
+// can only be set in the genesis.
+func newGenesisCompatError(what string) *ConfigCompatError {
+	return &ConfigCompatError{
+		What:    what,
+		Genesis: true,
+	}
+}
+
 func (err *ConfigCompatError) Error() string {
+	if err.Genesis {
+		return fmt.Sprintf("mismatching %s in database (set in genesis, resync required)", err.What)
+	}
 	if err.StoredBlock != nil {
 		return fmt.Sprintf("mismatching %s in database (have block %d, want block %d, rewindto block %d)", err.What, err.StoredBlock, err.NewBlock, err.RewindToBlock)
 	}
@@ -1098,6 +1275,15 @@ type Rules struct {
 	// Rules for Avalanche releases
 	AvalancheRules
 
+	// IsStorageLastModified is true if the block number at which each storage
+	// slot was last modified is tracked in state.
+	IsStorageLastModified bool
+	// IsAtomicTxLimits is true if the limits on the contents of each atomic
+	// transaction are enforced.
+	IsAtomicTxLimits bool
+	// BlockNumber is the number of the block these rules were created for.
+	BlockNumber uint64
+
 	// ActivePrecompiles maps addresses to stateful precompiled contracts that are enabled
 	// for this rule set.
 	// Note: none of these addresses should conflict with the address space used by
@@ -1143,6 +1329,11 @@ func (c *ChainConfig) Rules(blockNum *big.Int, timestamp uint64) Rules {
 	rules := c.rules(blockNum, timestamp)
 
 	rules.AvalancheRules = c.GetAvalancheRules(timestamp)
+	rules.IsStorageLastModified = c.IsStorageLastModified(timestamp)
+	rules.IsAtomicTxLimits = c.IsAtomicTxLimits(timestamp)
+	if blockNum != nil {
+		rules.BlockNumber = blockNum.Uint64()
+	}
 
 	// Initialize the stateful precompiles that should be enabled at [blockTimestamp].
 	rules.ActivePrecompiles = make(map[common.Address]precompileconfig.Config)
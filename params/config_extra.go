@@ -6,18 +6,30 @@ package params
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 	"time"
 
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/coreth/precompile/contract"
 	"github.com/ava-labs/coreth/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // UpgradeConfig includes the following configs that may be specified in upgradeBytes:
 // - Timestamps that enable avalanche network upgrades,
-// - Enabling or disabling precompiles as network upgrades.
+// - Enabling or disabling precompiles as network upgrades,
+// - Migrating the storage of precompiles as network upgrades,
+// - Scaling the gas charged by precompiles as network upgrades.
 type UpgradeConfig struct {
 	// Config for enabling and disabling precompiles as network upgrades.
 	PrecompileUpgrades []PrecompileUpgrade `json:"precompileUpgrades,omitempty"`
+
+	// Config for migrating the storage of precompiles to new layouts as
+	// network upgrades.
+	PrecompileStorageMigrations []PrecompileStorageMigration `json:"precompileStorageMigrations,omitempty"`
+
+	// Config for scaling the gas charged by precompiles as network upgrades.
+	PrecompileGasMultipliers []PrecompileGasMultiplier `json:"precompileGasMultipliers,omitempty"`
 }
 
 // AvalancheContext provides Avalanche specific context directly into the EVM.
@@ -25,6 +37,50 @@ type AvalancheContext struct {
 	SnowCtx *snow.Context
 }
 
+// GetPrecompileGasSchedule returns the gas schedule charged by stateful
+// precompiles in blocks with [time]. Prior to [PrecompileGasScheduleTimestamp]
+// this is always the default schedule, so that configuring an override does
+// not change the gas used by blocks which have already been accepted.
+func (c *ChainConfig) GetPrecompileGasSchedule(time uint64) contract.GasSchedule {
+	if !c.IsPrecompileGasSchedule(time) || c.PrecompileGasSchedule == nil {
+		return contract.DefaultGasSchedule()
+	}
+	return *c.PrecompileGasSchedule
+}
+
+// GetMinBaseFee returns the minimum base fee of the child of a block with
+// [parentTimestamp], or nil if the child does not have a bounded base fee.
+func (c *ChainConfig) GetMinBaseFee(parentTimestamp uint64) *big.Int {
+	minBaseFee, _ := c.BaseFeeBounds(parentTimestamp)
+	return minBaseFee
+}
+
+// GetSubnetCreatorAddress returns the address of the creator of the subnet
+// specified in the genesis, or the zero address if there is none.
+func (c *ChainConfig) GetSubnetCreatorAddress() common.Address {
+	return c.SubnetCreatorAddress
+}
+
+// BaseFeeBounds returns the bounds of the base fee of the child of a block
+// with [parentTimestamp]. The base fee is bounded by the rules of the parent,
+// so the bounds of an upgrade do not apply to the first block after it
+// activates. A nil bound is unbounded, and both bounds are nil if the parent
+// is prior to Apricot Phase 3.
+func (c *ChainConfig) BaseFeeBounds(parentTimestamp uint64) (minBaseFee *big.Int, maxBaseFee *big.Int) {
+	switch {
+	case c.IsEUpgrade(parentTimestamp):
+		return big.NewInt(EUpgradeMinBaseFee), nil
+	case c.IsApricotPhase5(parentTimestamp):
+		return big.NewInt(ApricotPhase4MinBaseFee), nil
+	case c.IsApricotPhase4(parentTimestamp):
+		return big.NewInt(ApricotPhase4MinBaseFee), big.NewInt(ApricotPhase4MaxBaseFee)
+	case c.IsApricotPhase3(parentTimestamp):
+		return big.NewInt(ApricotPhase3MinBaseFee), big.NewInt(ApricotPhase3MaxBaseFee)
+	default:
+		return nil, nil
+	}
+}
+
 // UnmarshalJSON parses the JSON-encoded data and stores the result in the
 // object pointed to by c.
 // This is a custom unmarshaler to handle the Precompiles field.
@@ -27,13 +27,17 @@
 package params
 
 import (
+	"encoding/json"
 	"math"
 	"math/big"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
 	"github.com/ava-labs/coreth/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -43,6 +47,10 @@ func TestCheckCompatible(t *testing.T) {
 		headTimestamp uint64
 		wantErr       *ConfigCompatError
 	}
+	withCreator := *TestChainConfig
+	withCreator.SubnetCreatorAddress = common.Address{0x1}
+	withFeeRecipient := *TestChainConfig
+	withFeeRecipient.MinerFeeRecipient = common.Address{0x1}
 	tests := []test{
 		{stored: TestChainConfig, new: TestChainConfig, headBlock: 0, headTimestamp: 0, wantErr: nil},
 		{stored: TestChainConfig, new: TestChainConfig, headBlock: 0, headTimestamp: uint64(time.Now().Unix()), wantErr: nil},
@@ -133,6 +141,40 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 0,
 			},
 		},
+		{
+			stored:        TestChainConfig,
+			new:           &withCreator,
+			headBlock:     10,
+			headTimestamp: 100,
+			wantErr: &ConfigCompatError{
+				What:    "subnet creator address",
+				Genesis: true,
+			},
+		},
+		{
+			stored:        TestChainConfig,
+			new:           &withCreator,
+			headBlock:     0,
+			headTimestamp: 0,
+			wantErr:       nil,
+		},
+		{
+			stored:        &withFeeRecipient,
+			new:           TestChainConfig,
+			headBlock:     10,
+			headTimestamp: 100,
+			wantErr: &ConfigCompatError{
+				What:    "miner fee recipient",
+				Genesis: true,
+			},
+		},
+		{
+			stored:        &withFeeRecipient,
+			new:           TestChainConfig,
+			headBlock:     0,
+			headTimestamp: 0,
+			wantErr:       nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -143,6 +185,179 @@ func TestCheckCompatible(t *testing.T) {
 	}
 }
 
+func TestCheckCompatiblePrecompileStorageMigrations(t *testing.T) {
+	withMigrations := func(migrations ...PrecompileStorageMigration) *ChainConfig {
+		config := *TestChainConfig
+		config.UpgradeConfig = UpgradeConfig{PrecompileStorageMigrations: migrations}
+		return &config
+	}
+	migration := PrecompileStorageMigration{PrecompileKey: "test", BlockTimestamp: utils.NewUint64(20), Version: 1}
+	tests := map[string]struct {
+		stored, new   *ChainConfig
+		headTimestamp uint64
+		wantErr       *ConfigCompatError
+	}{
+		"unchanged": {
+			stored:        withMigrations(migration),
+			new:           withMigrations(migration),
+			headTimestamp: 30,
+		},
+		"changed before activation": {
+			stored:        withMigrations(migration),
+			new:           withMigrations(PrecompileStorageMigration{PrecompileKey: "test", BlockTimestamp: utils.NewUint64(40), Version: 1}),
+			headTimestamp: 10,
+		},
+		"changed version after activation": {
+			stored:        withMigrations(migration),
+			new:           withMigrations(PrecompileStorageMigration{PrecompileKey: "test", BlockTimestamp: utils.NewUint64(20), Version: 2}),
+			headTimestamp: 30,
+			wantErr: &ConfigCompatError{
+				What:         "PrecompileStorageMigration[0]",
+				StoredTime:   utils.NewUint64(20),
+				NewTime:      utils.NewUint64(20),
+				RewindToTime: 19,
+			},
+		},
+		"removed after activation": {
+			stored:        withMigrations(migration),
+			new:           withMigrations(),
+			headTimestamp: 30,
+			wantErr: &ConfigCompatError{
+				What:         "missing PrecompileStorageMigration[0]",
+				StoredTime:   utils.NewUint64(20),
+				RewindToTime: 19,
+			},
+		},
+		"added retroactively": {
+			stored:        withMigrations(),
+			new:           withMigrations(migration),
+			headTimestamp: 30,
+			wantErr: &ConfigCompatError{
+				What:         "cannot retroactively apply PrecompileStorageMigration[0]",
+				NewTime:      utils.NewUint64(20),
+				RewindToTime: 19,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.stored.CheckCompatible(test.new, 0, test.headTimestamp)
+			if !reflect.DeepEqual(err, test.wantErr) {
+				t.Errorf("error mismatch:\nerr: %v\nwant: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckCompatiblePrecompileGasMultipliers(t *testing.T) {
+	withMultipliers := func(multipliers ...PrecompileGasMultiplier) *ChainConfig {
+		config := *TestChainConfig
+		config.UpgradeConfig = UpgradeConfig{PrecompileGasMultipliers: multipliers}
+		return &config
+	}
+	multiplier := PrecompileGasMultiplier{Address: common.Address{0x1}, BlockTimestamp: utils.NewUint64(20), Multiplier: 20_000}
+	tests := map[string]struct {
+		stored, new   *ChainConfig
+		headTimestamp uint64
+		wantErr       *ConfigCompatError
+	}{
+		"unchanged": {
+			stored:        withMultipliers(multiplier),
+			new:           withMultipliers(multiplier),
+			headTimestamp: 30,
+		},
+		"changed before activation": {
+			stored:        withMultipliers(multiplier),
+			new:           withMultipliers(PrecompileGasMultiplier{Address: common.Address{0x1}, BlockTimestamp: utils.NewUint64(40), Multiplier: 5_000}),
+			headTimestamp: 10,
+		},
+		"changed multiplier after activation": {
+			stored:        withMultipliers(multiplier),
+			new:           withMultipliers(PrecompileGasMultiplier{Address: common.Address{0x1}, BlockTimestamp: utils.NewUint64(20), Multiplier: 5_000}),
+			headTimestamp: 30,
+			wantErr: &ConfigCompatError{
+				What:         "PrecompileGasMultiplier[0]",
+				StoredTime:   utils.NewUint64(20),
+				NewTime:      utils.NewUint64(20),
+				RewindToTime: 19,
+			},
+		},
+		"added retroactively": {
+			stored:        withMultipliers(),
+			new:           withMultipliers(multiplier),
+			headTimestamp: 30,
+			wantErr: &ConfigCompatError{
+				What:         "cannot retroactively apply PrecompileGasMultiplier[0]",
+				NewTime:      utils.NewUint64(20),
+				RewindToTime: 19,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.stored.CheckCompatible(test.new, 0, test.headTimestamp)
+			if !reflect.DeepEqual(err, test.wantErr) {
+				t.Errorf("error mismatch:\nerr: %v\nwant: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyPrecompileGasMultipliers(t *testing.T) {
+	addr := common.Address{0x1}
+	tests := map[string]struct {
+		multipliers []PrecompileGasMultiplier
+		wantErr     bool
+	}{
+		"valid": {
+			multipliers: []PrecompileGasMultiplier{
+				{Address: addr, BlockTimestamp: utils.NewUint64(10), Multiplier: 20_000},
+				{Address: common.Address{0x2}, BlockTimestamp: utils.NewUint64(10), Multiplier: 5_000},
+				{Address: addr, BlockTimestamp: utils.NewUint64(20), Multiplier: 10_000},
+			},
+		},
+		"nil timestamp": {
+			multipliers: []PrecompileGasMultiplier{{Address: addr, Multiplier: 20_000}},
+			wantErr:     true,
+		},
+		"decreasing timestamps": {
+			multipliers: []PrecompileGasMultiplier{
+				{Address: addr, BlockTimestamp: utils.NewUint64(20), Multiplier: 20_000},
+				{Address: common.Address{0x2}, BlockTimestamp: utils.NewUint64(10), Multiplier: 20_000},
+			},
+			wantErr: true,
+		},
+		"same timestamp for same address": {
+			multipliers: []PrecompileGasMultiplier{
+				{Address: addr, BlockTimestamp: utils.NewUint64(10), Multiplier: 20_000},
+				{Address: addr, BlockTimestamp: utils.NewUint64(10), Multiplier: 30_000},
+			},
+			wantErr: true,
+		},
+		"zero multiplier": {
+			multipliers: []PrecompileGasMultiplier{{Address: addr, BlockTimestamp: utils.NewUint64(10)}},
+			wantErr:     true,
+		},
+		"multiplier below range": {
+			multipliers: []PrecompileGasMultiplier{{Address: addr, BlockTimestamp: utils.NewUint64(10), Multiplier: MinPrecompileGasMultiplier - 1}},
+			wantErr:     true,
+		},
+		"multiplier above range": {
+			multipliers: []PrecompileGasMultiplier{{Address: addr, BlockTimestamp: utils.NewUint64(10), Multiplier: MaxPrecompileGasMultiplier + 1}},
+			wantErr:     true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := &ChainConfig{UpgradeConfig: UpgradeConfig{PrecompileGasMultipliers: test.multipliers}}
+			err := config.verifyPrecompileGasMultipliers()
+			if test.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestConfigRules(t *testing.T) {
 	c := &ChainConfig{
 		CortinaBlockTimestamp: utils.NewUint64(500),
@@ -160,3 +375,301 @@ func TestConfigRules(t *testing.T) {
 		t.Errorf("expected %v to be cortina", stamp)
 	}
 }
+
+func TestPrecompileGasSchedule(t *testing.T) {
+	c := &ChainConfig{}
+	if got := c.GetPrecompileGasSchedule(0); got != contract.DefaultGasSchedule() {
+		t.Errorf("expected default gas schedule, got %+v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"precompileGasScheduleTimestamp":10,"precompileGasSchedule":{"storeWrite":1000,"logData":50}}`), c); err != nil {
+		t.Fatal(err)
+	}
+	// The configured schedule only applies once activated.
+	if got := c.GetPrecompileGasSchedule(9); got != contract.DefaultGasSchedule() {
+		t.Errorf("expected default gas schedule before activation, got %+v", got)
+	}
+	want := contract.DefaultGasSchedule()
+	want.StoreWrite = 1000
+	want.LogData = 50
+	if got := c.GetPrecompileGasSchedule(10); got != want {
+		t.Errorf("gas schedule mismatch: got %+v, want %+v", got, want)
+	}
+
+	// Changing the schedule after it has activated is incompatible.
+	newcfg := ChainConfig{}
+	if err := json.Unmarshal([]byte(`{"precompileGasScheduleTimestamp":10,"precompileGasSchedule":{"storeRead":0}}`), &newcfg); err != nil {
+		t.Fatal(err)
+	}
+	// Explicit zero values override the default.
+	want = contract.DefaultGasSchedule()
+	want.StoreRead = 0
+	if got := newcfg.GetPrecompileGasSchedule(10); got != want {
+		t.Errorf("gas schedule mismatch: got %+v, want %+v", got, want)
+	}
+	if err := c.CheckCompatible(&newcfg, 0, 10); err == nil {
+		t.Error("expected incompatible gas schedule")
+	}
+	if err := c.CheckCompatible(&newcfg, 0, 9); err != nil {
+		t.Errorf("unexpected error changing inactive gas schedule: %v", err)
+	}
+
+	// Rescheduling the activation after it has occurred is incompatible.
+	newcfg = *c
+	newcfg.PrecompileGasScheduleTimestamp = utils.NewUint64(20)
+	if err := c.CheckCompatible(&newcfg, 0, 10); err == nil {
+		t.Error("expected incompatible gas schedule timestamp")
+	}
+}
+
+func TestNativeAssetCallGasTable(t *testing.T) {
+	c := &ChainConfig{}
+	if got := c.GetNativeAssetCallGasTable(0); got != DefaultNativeAssetCallGasTable() {
+		t.Errorf("expected default gas table, got %+v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"nativeAssetCallGasTableTimestamp":10,"nativeAssetCallGasTable":{"perInputByte":16}}`), c); err != nil {
+		t.Fatal(err)
+	}
+	// The configured table only applies once activated.
+	if got := c.GetNativeAssetCallGasTable(9); got != DefaultNativeAssetCallGasTable() {
+		t.Errorf("expected default gas table before activation, got %+v", got)
+	}
+	want := DefaultNativeAssetCallGasTable()
+	want.PerInputByte = 16
+	if got := c.GetNativeAssetCallGasTable(10); got != want {
+		t.Errorf("gas table mismatch: got %+v, want %+v", got, want)
+	}
+
+	// Rescheduling the activation after it has occurred is incompatible.
+	newcfg := *c
+	newcfg.NativeAssetCallGasTableTimestamp = utils.NewUint64(20)
+	if err := c.CheckCompatible(&newcfg, 0, 10); err == nil {
+		t.Error("expected incompatible gas table timestamp")
+	}
+	if err := c.CheckCompatible(&newcfg, 0, 9); err != nil {
+		t.Errorf("unexpected error rescheduling inactive gas table: %v", err)
+	}
+
+	// Changing the table after it has activated is incompatible.
+	newcfg = *c
+	changed := want
+	changed.PerInputByte = 32
+	newcfg.NativeAssetCallGasTable = &changed
+	if err := c.CheckCompatible(&newcfg, 0, 10); err == nil {
+		t.Error("expected incompatible gas table")
+	}
+	if err := c.CheckCompatible(&newcfg, 0, 9); err != nil {
+		t.Errorf("unexpected error changing inactive gas table: %v", err)
+	}
+}
+
+func TestGetMinBaseFee(t *testing.T) {
+	eUpgradeAt10 := *TestEUpgradeChainConfig
+	eUpgradeAt10.EUpgradeTime = utils.NewUint64(10)
+
+	tests := map[string]struct {
+		config          *ChainConfig
+		parentTimestamp uint64
+		expected        *big.Int
+	}{
+		"pre Apricot Phase 3": {
+			config: TestApricotPhase2Config,
+		},
+		"Apricot Phase 3": {
+			config:   TestApricotPhase3Config,
+			expected: big.NewInt(ApricotPhase3MinBaseFee),
+		},
+		"Apricot Phase 4": {
+			config:   TestApricotPhase4Config,
+			expected: big.NewInt(ApricotPhase4MinBaseFee),
+		},
+		"Durango": {
+			config:   TestDurangoChainConfig,
+			expected: big.NewInt(ApricotPhase4MinBaseFee),
+		},
+		"EUpgrade": {
+			config:   TestEUpgradeChainConfig,
+			expected: big.NewInt(EUpgradeMinBaseFee),
+		},
+		"parent before EUpgrade": {
+			config:          &eUpgradeAt10,
+			parentTimestamp: 9,
+			expected:        big.NewInt(ApricotPhase4MinBaseFee),
+		},
+		"parent at EUpgrade": {
+			config:          &eUpgradeAt10,
+			parentTimestamp: 10,
+			expected:        big.NewInt(EUpgradeMinBaseFee),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.config.GetMinBaseFee(test.parentTimestamp); !reflect.DeepEqual(got, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+type validateTestConfig struct {
+	precompileconfig.Upgrade
+	key string
+}
+
+func (c *validateTestConfig) Key() string { return c.key }
+
+func (c *validateTestConfig) Verify(precompileconfig.ChainConfig) error { return nil }
+
+func (c *validateTestConfig) Equal(cfg precompileconfig.Config) bool {
+	other, ok := cfg.(*validateTestConfig)
+	return ok && c.key == other.key && c.Upgrade.Equal(&other.Upgrade)
+}
+
+func TestValidateAtBlockTimestamp(t *testing.T) {
+	upgrade := func(key string, timestamp uint64, disable bool) PrecompileUpgrade {
+		return PrecompileUpgrade{Config: &validateTestConfig{
+			Upgrade: precompileconfig.Upgrade{BlockTimestamp: utils.NewUint64(timestamp), Disable: disable},
+			key:     key,
+		}}
+	}
+	tests := map[string]struct {
+		config    func() *ChainConfig
+		timestamp uint64
+		expectErr bool
+	}{
+		"valid network upgrades": {
+			config:    func() *ChainConfig { return TestChainConfig },
+			timestamp: 0,
+		},
+		"network upgrade active before preceding upgrade": {
+			config: func() *ChainConfig {
+				c := *TestDurangoChainConfig
+				c.EUpgradeTime = utils.NewUint64(5)
+				c.DurangoBlockTimestamp = utils.NewUint64(10)
+				return &c
+			},
+			timestamp: 5,
+			expectErr: true,
+		},
+		"network upgrades activated out of order": {
+			config: func() *ChainConfig {
+				c := *TestDurangoChainConfig
+				c.CortinaBlockTimestamp = utils.NewUint64(10)
+				c.DurangoBlockTimestamp = utils.NewUint64(5)
+				return &c
+			},
+			timestamp: 10,
+			expectErr: true,
+		},
+		"out of order upgrades not yet active": {
+			config: func() *ChainConfig {
+				c := *TestDurangoChainConfig
+				c.CortinaBlockTimestamp = utils.NewUint64(10)
+				c.DurangoBlockTimestamp = utils.NewUint64(5)
+				return &c
+			},
+			timestamp: 4,
+		},
+		"feature active before its required upgrade": {
+			config: func() *ChainConfig {
+				c := *TestApricotPhase1Config
+				c.StorageLastModifiedTimestamp = utils.NewUint64(0)
+				return &c
+			},
+			timestamp: 0,
+			expectErr: true,
+		},
+		"feature activated before its required upgrade": {
+			config: func() *ChainConfig {
+				c := *TestApricotPhase4Config
+				c.ApricotPhase2BlockTimestamp = utils.NewUint64(10)
+				c.ApricotPhase3BlockTimestamp = utils.NewUint64(10)
+				c.ApricotPhase4BlockTimestamp = utils.NewUint64(10)
+				c.NativeAssetCallGasTableTimestamp = utils.NewUint64(5)
+				return &c
+			},
+			timestamp: 10,
+			expectErr: true,
+		},
+		"feature active after its required upgrade": {
+			config: func() *ChainConfig {
+				c := *TestApricotPhase5Config
+				c.PrecompileGasScheduleTimestamp = utils.NewUint64(5)
+				c.AtomicTxLimitsTimestamp = utils.NewUint64(5)
+				return &c
+			},
+			timestamp: 5,
+		},
+		"valid precompile upgrades": {
+			config: func() *ChainConfig {
+				c := *TestChainConfig
+				c.PrecompileUpgrades = []PrecompileUpgrade{
+					upgrade("a", 1, false),
+					upgrade("b", 1, false),
+					upgrade("a", 2, true),
+					upgrade("a", 3, false),
+				}
+				return &c
+			},
+			timestamp: 3,
+		},
+		"precompile upgrades out of order": {
+			config: func() *ChainConfig {
+				c := *TestChainConfig
+				c.PrecompileUpgrades = []PrecompileUpgrade{
+					upgrade("a", 2, false),
+					upgrade("b", 1, false),
+				}
+				return &c
+			},
+			timestamp: 2,
+			expectErr: true,
+		},
+		"precompile enabled twice": {
+			config: func() *ChainConfig {
+				c := *TestChainConfig
+				c.PrecompileUpgrades = []PrecompileUpgrade{
+					upgrade("a", 1, false),
+					upgrade("a", 2, false),
+				}
+				return &c
+			},
+			timestamp: 2,
+			expectErr: true,
+		},
+		"precompile enabled twice not yet active": {
+			config: func() *ChainConfig {
+				c := *TestChainConfig
+				c.PrecompileUpgrades = []PrecompileUpgrade{
+					upgrade("a", 1, false),
+					upgrade("a", 2, false),
+				}
+				return &c
+			},
+			timestamp: 1,
+		},
+		"precompile disabled before enabled": {
+			config: func() *ChainConfig {
+				c := *TestChainConfig
+				c.PrecompileUpgrades = []PrecompileUpgrade{
+					upgrade("a", 1, true),
+				}
+				return &c
+			},
+			timestamp: 1,
+			expectErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.config().ValidateAtBlockTimestamp(test.timestamp)
+			if test.expectErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// NativeAssetCallGasTable specifies the gas charged by the NativeAssetCall
+// precompile, in addition to the gas consumed by the call it performs.
+type NativeAssetCallGasTable struct {
+	// Base is charged for every call to the precompile.
+	Base uint64 `json:"base"`
+	// PerInputByte is charged for each byte of input to the precompile.
+	PerInputByte uint64 `json:"perInputByte"`
+	// NewAccount is charged when the recipient does not exist.
+	NewAccount uint64 `json:"newAccount"`
+}
+
+// DefaultNativeAssetCallGasTable returns the gas table used when no overrides
+// are specified in the chain config. It matches the Apricot gas costs.
+func DefaultNativeAssetCallGasTable() NativeAssetCallGasTable {
+	return NativeAssetCallGasTable{
+		Base:         AssetCallApricot,
+		PerInputByte: 0,
+		NewAccount:   CallNewAccountGas,
+	}
+}
+
+// IntrinsicCost returns the gas charged for a call with [inputLen] bytes of
+// input, excluding the [NewAccount] surcharge.
+func (g NativeAssetCallGasTable) IntrinsicCost(inputLen int) (uint64, bool) {
+	cost, overflow := math.SafeMul(g.PerInputByte, uint64(inputLen))
+	if overflow {
+		return 0, true
+	}
+	return math.SafeAdd(cost, g.Base)
+}
+
+// UnmarshalJSON parses [data] on top of the default gas table, so that
+// fields omitted from [data] keep their default values.
+func (g *NativeAssetCallGasTable) UnmarshalJSON(data []byte) error {
+	// Alias NativeAssetCallGasTable to avoid recursion
+	type _NativeAssetCallGasTable NativeAssetCallGasTable
+	tmp := _NativeAssetCallGasTable(DefaultNativeAssetCallGasTable())
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*g = NativeAssetCallGasTable(tmp)
+	return nil
+}
+
+// GetNativeAssetCallGasTable returns the gas table charged by the
+// NativeAssetCall precompile for a block at [time]. Before
+// [NativeAssetCallGasTableTimestamp] this is always the default table, so that
+// historical blocks are re-executed with the gas costs they were built with.
+func (c *ChainConfig) GetNativeAssetCallGasTable(time uint64) NativeAssetCallGasTable {
+	if !c.IsNativeAssetCallGasTable(time) || c.NativeAssetCallGasTable == nil {
+		return DefaultNativeAssetCallGasTable()
+	}
+	return *c.NativeAssetCallGasTable
+}
@@ -20,6 +20,23 @@ func (c *ChainConfig) forkOrder() []fork {
 	}
 }
 
+// featureForkOrders returns the optional features activated by timestamp,
+// each preceded by the network upgrade it requires. The features are
+// independent of each other, so each is ordered only against its
+// requirement.
+func (c *ChainConfig) featureForkOrders() [][]fork {
+	var (
+		apricotPhase2 = fork{name: "apricotPhase2BlockTimestamp", timestamp: c.ApricotPhase2BlockTimestamp}
+		apricotPhase5 = fork{name: "apricotPhase5BlockTimestamp", timestamp: c.ApricotPhase5BlockTimestamp}
+	)
+	return [][]fork{
+		{apricotPhase2, {name: "precompileGasScheduleTimestamp", timestamp: c.PrecompileGasScheduleTimestamp, optional: true}},
+		{apricotPhase2, {name: "nativeAssetCallGasTableTimestamp", timestamp: c.NativeAssetCallGasTableTimestamp, optional: true}},
+		{apricotPhase2, {name: "storageLastModifiedTimestamp", timestamp: c.StorageLastModifiedTimestamp, optional: true}},
+		{apricotPhase5, {name: "atomicTxLimitsTimestamp", timestamp: c.AtomicTxLimitsTimestamp, optional: true}},
+	}
+}
+
 type AvalancheRules struct {
 	IsApricotPhase1, IsApricotPhase2, IsApricotPhase3, IsApricotPhase4, IsApricotPhase5 bool
 	IsApricotPhasePre6, IsApricotPhase6, IsApricotPhasePost6                            bool
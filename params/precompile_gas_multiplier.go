@@ -0,0 +1,134 @@
+// (c) 2024 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/coreth/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// PrecompileGasMultiplierDenominator is the denominator of the gas
+	// multipliers of precompiles, which are expressed in basis points.
+	PrecompileGasMultiplierDenominator uint64 = 10_000
+	// MinPrecompileGasMultiplier and MaxPrecompileGasMultiplier bound the gas
+	// multipliers of precompiles, from 0.1x to 100x.
+	MinPrecompileGasMultiplier uint64 = PrecompileGasMultiplierDenominator / 10
+	MaxPrecompileGasMultiplier uint64 = 100 * PrecompileGasMultiplierDenominator
+)
+
+// PrecompileGasMultiplier schedules a multiplier of the gas charged by the
+// precompile at [Address], in blocks with timestamp >= [BlockTimestamp], until
+// the next multiplier scheduled for the same address. [Multiplier] is in basis
+// points of [PrecompileGasMultiplierDenominator], so 20000 doubles the gas
+// charged and 5000 halves it.
+type PrecompileGasMultiplier struct {
+	Address        common.Address `json:"address"`
+	BlockTimestamp *uint64        `json:"blockTimestamp"`
+	Multiplier     uint64         `json:"multiplier"`
+}
+
+// verifyPrecompileGasMultipliers checks [c.PrecompileGasMultipliers] is well
+// formed:
+//   - the specified blockTimestamps must monotonically increase, and strictly
+//     increase for the same address
+//   - the multipliers must be within [MinPrecompileGasMultiplier] and
+//     [MaxPrecompileGasMultiplier]
+func (c *ChainConfig) verifyPrecompileGasMultipliers() error {
+	lastTimestamps := make(map[common.Address]uint64)
+
+	var previousTimestamp *uint64
+	for i, multiplier := range c.PrecompileGasMultipliers {
+		addr := multiplier.Address
+		if multiplier.BlockTimestamp == nil {
+			return fmt.Errorf("PrecompileGasMultiplier (%s) at [%d]: block timestamp cannot be nil", addr, i)
+		}
+		timestamp := *multiplier.BlockTimestamp
+		if previousTimestamp != nil && timestamp < *previousTimestamp {
+			return fmt.Errorf("PrecompileGasMultiplier (%s) at [%d]: block timestamp (%v) < previous timestamp (%v)", addr, i, timestamp, *previousTimestamp)
+		}
+		if last, ok := lastTimestamps[addr]; ok && timestamp <= last {
+			return fmt.Errorf("PrecompileGasMultiplier (%s) at [%d]: block timestamp (%v) <= previous timestamp (%v) of same address", addr, i, timestamp, last)
+		}
+		if multiplier.Multiplier < MinPrecompileGasMultiplier || multiplier.Multiplier > MaxPrecompileGasMultiplier {
+			return fmt.Errorf("PrecompileGasMultiplier (%s) at [%d]: multiplier (%d) must be in the range [%d, %d]", addr, i, multiplier.Multiplier, MinPrecompileGasMultiplier, MaxPrecompileGasMultiplier)
+		}
+
+		lastTimestamps[addr] = timestamp
+		previousTimestamp = multiplier.BlockTimestamp
+	}
+	return nil
+}
+
+// checkPrecompileGasMultipliersCompatible checks that the gas multipliers of
+// [c] which have activated at [time] are present and unchanged in
+// [multipliers], and that [multipliers] does not schedule a multiplier which
+// would have already activated at [time].
+func (c *ChainConfig) checkPrecompileGasMultipliersCompatible(multipliers []PrecompileGasMultiplier, time uint64) *ConfigCompatError {
+	activeMultipliers := c.getActivePrecompileGasMultipliers(time)
+	newMultipliers := (&ChainConfig{UpgradeConfig: UpgradeConfig{PrecompileGasMultipliers: multipliers}}).getActivePrecompileGasMultipliers(time)
+
+	for i, multiplier := range activeMultipliers {
+		if len(newMultipliers) <= i {
+			return newTimestampCompatError(
+				fmt.Sprintf("missing PrecompileGasMultiplier[%d]", i),
+				multiplier.BlockTimestamp,
+				nil,
+			)
+		}
+		// All multipliers that have activated must be identical.
+		if !multiplier.Equal(newMultipliers[i]) {
+			return newTimestampCompatError(
+				fmt.Sprintf("PrecompileGasMultiplier[%d]", i),
+				multiplier.BlockTimestamp,
+				newMultipliers[i].BlockTimestamp,
+			)
+		}
+	}
+	// Multipliers cannot be scheduled retroactively.
+	if len(newMultipliers) > len(activeMultipliers) {
+		return newTimestampCompatError(
+			fmt.Sprintf("cannot retroactively apply PrecompileGasMultiplier[%d]", len(activeMultipliers)),
+			nil,
+			newMultipliers[len(activeMultipliers)].BlockTimestamp,
+		)
+	}
+	return nil
+}
+
+// Equal returns true if [m] and [other] apply the same multiplier to the same
+// precompile at the same timestamp.
+func (m PrecompileGasMultiplier) Equal(other PrecompileGasMultiplier) bool {
+	return m.Address == other.Address &&
+		configTimestampEqual(m.BlockTimestamp, other.BlockTimestamp) &&
+		m.Multiplier == other.Multiplier
+}
+
+// getActivePrecompileGasMultipliers returns the gas multipliers which have
+// activated at [timestamp], in the order they are configured.
+func (c *ChainConfig) getActivePrecompileGasMultipliers(timestamp uint64) []PrecompileGasMultiplier {
+	var multipliers []PrecompileGasMultiplier
+	for _, multiplier := range c.PrecompileGasMultipliers {
+		if utils.IsTimestampForked(multiplier.BlockTimestamp, timestamp) {
+			multipliers = append(multipliers, multiplier)
+		}
+	}
+	return multipliers
+}
+
+// GetPrecompileGasMultiplier returns the multiplier of the gas charged by the
+// precompile at [addr] in blocks with [timestamp], in basis points of
+// [PrecompileGasMultiplierDenominator]. It is the denominator, meaning 1x,
+// until the first multiplier scheduled for [addr] activates.
+func (c *ChainConfig) GetPrecompileGasMultiplier(addr common.Address, timestamp uint64) uint64 {
+	multiplier := PrecompileGasMultiplierDenominator
+	for _, m := range c.getActivePrecompileGasMultipliers(timestamp) {
+		if m.Address == addr {
+			multiplier = m.Multiplier
+		}
+	}
+	return multiplier
+}
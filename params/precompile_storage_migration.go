@@ -0,0 +1,150 @@
+// (c) 2024 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/modules"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
+	"github.com/ava-labs/coreth/utils"
+)
+
+// PrecompileStorageMigration schedules the migration of the storage of a
+// stateful precompile to a new layout version, in the first block with
+// timestamp >= [BlockTimestamp].
+type PrecompileStorageMigration struct {
+	// PrecompileKey is the config key of the precompile module.
+	PrecompileKey  string                         `json:"precompileKey"`
+	BlockTimestamp *uint64                        `json:"blockTimestamp"`
+	Version        precompileconfig.ConfigVersion `json:"version"`
+}
+
+// verifyPrecompileStorageMigrations checks [c.PrecompileStorageMigrations] is
+// well formed:
+//   - each migration must refer to a registered precompile module which
+//     implements contract.StorageMigrator
+//   - the specified blockTimestamps must monotonically increase, and strictly
+//     increase for the same precompile
+//   - the versions of each precompile must strictly increase
+func (c *ChainConfig) verifyPrecompileStorageMigrations() error {
+	type lastMigrationData struct {
+		blockTimestamp uint64
+		version        precompileconfig.ConfigVersion
+	}
+	lastMigrations := make(map[string]lastMigrationData)
+
+	var previousTimestamp *uint64
+	for i, migration := range c.PrecompileStorageMigrations {
+		key := migration.PrecompileKey
+		module, ok := modules.GetPrecompileModule(key)
+		if !ok {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: unknown precompile", key, i)
+		}
+		if _, ok := module.Configurator.(contract.StorageMigrator); !ok {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: precompile does not support storage migrations", key, i)
+		}
+		if migration.BlockTimestamp == nil {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: block timestamp cannot be nil", key, i)
+		}
+		timestamp := *migration.BlockTimestamp
+		if previousTimestamp != nil && timestamp < *previousTimestamp {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: block timestamp (%v) < previous timestamp (%v)", key, i, timestamp, *previousTimestamp)
+		}
+		last, ok := lastMigrations[key]
+		if ok && timestamp <= last.blockTimestamp {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: block timestamp (%v) <= previous timestamp (%v) of same key", key, i, timestamp, last.blockTimestamp)
+		}
+		if migration.Version <= last.version {
+			return fmt.Errorf("PrecompileStorageMigration (%s) at [%d]: version (%d) <= previous version (%d)", key, i, migration.Version, last.version)
+		}
+
+		lastMigrations[key] = lastMigrationData{
+			blockTimestamp: timestamp,
+			version:        migration.Version,
+		}
+		previousTimestamp = migration.BlockTimestamp
+	}
+	return nil
+}
+
+// checkPrecompileStorageMigrationsCompatible checks that the storage
+// migrations of [c] which have activated at [time] are present and unchanged
+// in [migrations], and that [migrations] does not schedule a migration which
+// would have already activated at [time].
+func (c *ChainConfig) checkPrecompileStorageMigrationsCompatible(migrations []PrecompileStorageMigration, time uint64) *ConfigCompatError {
+	activeMigrations := c.GetActivatingPrecompileStorageMigrations(nil, time)
+	newMigrations := (&ChainConfig{UpgradeConfig: UpgradeConfig{PrecompileStorageMigrations: migrations}}).GetActivatingPrecompileStorageMigrations(nil, time)
+
+	for i, migration := range activeMigrations {
+		if len(newMigrations) <= i {
+			return newTimestampCompatError(
+				fmt.Sprintf("missing PrecompileStorageMigration[%d]", i),
+				migration.BlockTimestamp,
+				nil,
+			)
+		}
+		// All migrations that have activated must be identical.
+		if !migration.Equal(newMigrations[i]) {
+			return newTimestampCompatError(
+				fmt.Sprintf("PrecompileStorageMigration[%d]", i),
+				migration.BlockTimestamp,
+				newMigrations[i].BlockTimestamp,
+			)
+		}
+	}
+	// Migrations cannot be scheduled retroactively.
+	if len(newMigrations) > len(activeMigrations) {
+		return newTimestampCompatError(
+			fmt.Sprintf("cannot retroactively apply PrecompileStorageMigration[%d]", len(activeMigrations)),
+			nil,
+			newMigrations[len(activeMigrations)].BlockTimestamp,
+		)
+	}
+	return nil
+}
+
+// Equal returns true if [m] and [other] migrate the same precompile to the
+// same version at the same timestamp.
+func (m PrecompileStorageMigration) Equal(other PrecompileStorageMigration) bool {
+	return m.PrecompileKey == other.PrecompileKey &&
+		configTimestampEqual(m.BlockTimestamp, other.BlockTimestamp) &&
+		m.Version == other.Version
+}
+
+// GetPrecompileStorageVersion returns the storage layout version of the
+// precompile with [key] at [timestamp], which is 0 until its first storage
+// migration. It implements precompileconfig.StorageVersions.
+func (c *ChainConfig) GetPrecompileStorageVersion(key string, timestamp uint64) precompileconfig.ConfigVersion {
+	var version precompileconfig.ConfigVersion
+	for _, migration := range c.GetActivatingPrecompileStorageMigrations(nil, timestamp) {
+		if migration.PrecompileKey == key {
+			version = migration.Version
+		}
+	}
+	return version
+}
+
+// GetActivatingPrecompileStorageMigrations returns the storage migrations of
+// all precompiles configured to activate during the state transition from a
+// block with timestamp [from] to a block with timestamp [to], in the order
+// they are configured.
+func (c *ChainConfig) GetActivatingPrecompileStorageMigrations(from *uint64, to uint64) []PrecompileStorageMigration {
+	var migrations []PrecompileStorageMigration
+	for _, migration := range c.PrecompileStorageMigrations {
+		if utils.IsForkTransition(migration.BlockTimestamp, from, to) {
+			migrations = append(migrations, migration)
+		}
+	}
+	return migrations
+}
+
+// PrecompileStorageMigrationGas returns the gas charged to a block with
+// timestamp [to] and a parent with timestamp [from] for the storage migrations
+// which activate in it. The gas is charged whether or not the precompile is
+// enabled, so that it only depends on the chain config.
+func (c *ChainConfig) PrecompileStorageMigrationGas(from *uint64, to uint64) uint64 {
+	return uint64(len(c.GetActivatingPrecompileStorageMigrations(from, to))) * PrecompileStorageMigrationGas
+}
@@ -68,6 +68,21 @@ func (u *PrecompileUpgrade) MarshalJSON() ([]byte, error) {
 //     specified in the chainConfig by genesis.
 //   - check a precompile is disabled before it is re-enabled
 func (c *ChainConfig) verifyPrecompileUpgrades() error {
+	if err := checkPrecompileUpgradeOrder(c.PrecompileUpgrades); err != nil {
+		return err
+	}
+	for _, upgrade := range c.PrecompileUpgrades {
+		if err := upgrade.Verify(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPrecompileUpgradeOrder checks that the timestamps of [upgrades]
+// monotonically increase and that each precompile is disabled before it is
+// re-enabled.
+func checkPrecompileUpgradeOrder(upgrades []PrecompileUpgrade) error {
 	// Store this struct to keep track of the last upgrade for each precompile key.
 	// Required for timestamp and disabled checks.
 	type lastUpgradeData struct {
@@ -80,7 +95,7 @@ func (c *ChainConfig) verifyPrecompileUpgrades() error {
 	// next range over upgrades to verify correct use of disabled and blockTimestamps.
 	// previousUpgradeTimestamp is used to verify monotonically increasing timestamps.
 	var previousUpgradeTimestamp *uint64
-	for i, upgrade := range c.PrecompileUpgrades {
+	for i, upgrade := range upgrades {
 		key := upgrade.Key()
 
 		// lastUpgradeByKey is the previous processed upgrade for this precompile key.
@@ -116,10 +131,6 @@ func (c *ChainConfig) verifyPrecompileUpgrades() error {
 			return fmt.Errorf("PrecompileUpgrade (%s) at [%d]: config block timestamp (%v) <= previous timestamp (%v) of same key", key, i, *upgradeTimestamp, *lastTimestamp)
 		}
 
-		if err := upgrade.Verify(c); err != nil {
-			return err
-		}
-
 		lastPrecompileUpgrades[key] = lastUpgradeData{
 			disabled:       upgrade.IsDisabled(),
 			blockTimestamp: *upgradeTimestamp,
@@ -141,6 +152,16 @@ func (c *ChainConfig) getActivePrecompileConfig(address common.Address, timestam
 	return configs[len(configs)-1] // return the most recent config
 }
 
+// GetPrecompileConfig returns the config of the precompile at [address]
+// active at [timestamp]. It returns false if the precompile is not enabled.
+func (c *ChainConfig) GetPrecompileConfig(address common.Address, timestamp uint64) (precompileconfig.Config, bool) {
+	config := c.getActivePrecompileConfig(address, timestamp)
+	if config == nil || config.IsDisabled() {
+		return nil, false
+	}
+	return config, true
+}
+
 // GetActivatingPrecompileConfigs returns all precompile upgrades configured to activate during the
 // state transition from a block with timestamp [from] to a block with timestamp [to].
 func (c *ChainConfig) GetActivatingPrecompileConfigs(address common.Address, from *uint64, to uint64, upgrades []PrecompileUpgrade) []precompileconfig.Config {
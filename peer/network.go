@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -31,11 +32,17 @@ import (
 const minRequestHandlingDuration = 100 * time.Millisecond
 
 var (
-	errAcquiringSemaphore                      = errors.New("error acquiring semaphore")
-	errExpiredRequest                          = errors.New("expired request")
-	_                     Network              = &network{}
-	_                     validators.Connector = &network{}
-	_                     common.AppHandler    = &network{}
+	errAcquiringSemaphore = errors.New("error acquiring semaphore")
+	errExpiredRequest     = errors.New("expired request")
+	// ErrPeerVersionTooLow is sent in response to sync requests from peers
+	// running a version below the minimum sync peer version.
+	ErrPeerVersionTooLow = &common.AppError{
+		Code:    -10,
+		Message: "peer version below minimum sync peer version",
+	}
+	_ Network              = &network{}
+	_ validators.Connector = &network{}
+	_ common.AppHandler    = &network{}
 )
 
 type Network interface {
@@ -71,6 +78,9 @@ type Network interface {
 	// Size returns the size of the network in number of connected peers
 	Size() uint32
 
+	// PeerStats returns the version of each connected peer, sorted by node ID
+	PeerStats() []PeerStat
+
 	// TrackBandwidth should be called for each valid request with the bandwidth
 	// (length of response divided by request time), and with 0 if the response is invalid.
 	TrackBandwidth(nodeID ids.NodeID, bandwidth float64)
@@ -91,15 +101,17 @@ type network struct {
 	activeAppRequests          *semaphore.Weighted                // controls maximum number of active outbound requests
 	activeCrossChainRequests   *semaphore.Weighted                // controls maximum number of active outbound cross chain requests
 	p2pNetwork                 *p2p.Network
-	appSender                  common.AppSender                 // avalanchego AppSender for sending messages
-	codec                      codec.Manager                    // Codec used for parsing messages
-	crossChainCodec            codec.Manager                    // Codec used for parsing cross chain messages
-	appRequestHandler          message.RequestHandler           // maps request type => handler
-	crossChainRequestHandler   message.CrossChainRequestHandler // maps cross chain request type => handler
-	gossipHandler              message.GossipHandler            // maps gossip type => handler
-	peers                      *peerTracker                     // tracking of peers & bandwidth
-	appStats                   stats.RequestHandlerStats        // Provide request handler metrics
-	crossChainStats            stats.RequestHandlerStats        // Provide cross chain request handler metrics
+	appSender                  common.AppSender                    // avalanchego AppSender for sending messages
+	codec                      codec.Manager                       // Codec used for parsing messages
+	crossChainCodec            codec.Manager                       // Codec used for parsing cross chain messages
+	appRequestHandler          message.RequestHandler              // maps request type => handler
+	crossChainRequestHandler   message.CrossChainRequestHandler    // maps cross chain request type => handler
+	gossipHandler              message.GossipHandler               // maps gossip type => handler
+	peers                      *peerTracker                        // tracking of peers & bandwidth
+	peerVersions               map[ids.NodeID]*version.Application // versions of all connected peers
+	minSyncPeerVersion         *version.Application                // peers below this version do not take part in sync
+	appStats                   stats.RequestHandlerStats           // Provide request handler metrics
+	crossChainStats            stats.RequestHandlerStats           // Provide cross chain request handler metrics
 
 	// Set to true when Shutdown is called, after which all operations on this
 	// struct are no-ops.
@@ -112,7 +124,20 @@ type network struct {
 	closed utils.Atomic[bool]
 }
 
-func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec.Manager, crossChainCodec codec.Manager, self ids.NodeID, maxActiveAppRequests int64, maxActiveCrossChainRequests int64) Network {
+// PeerStat describes a connected peer.
+type PeerStat struct {
+	NodeID  ids.NodeID `json:"nodeID"`
+	Version string     `json:"version"`
+	// SyncEligible is false if the peer's version is below the minimum sync
+	// peer version, in which case no sync requests are sent to or served for
+	// the peer.
+	SyncEligible bool `json:"syncEligible"`
+}
+
+// NewNetwork returns a Network which does not send sync requests to, or serve
+// sync requests for, peers with a version below [minSyncPeerVersion]. If
+// [minSyncPeerVersion] is nil, peers of any version take part in sync.
+func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec.Manager, crossChainCodec codec.Manager, self ids.NodeID, maxActiveAppRequests int64, maxActiveCrossChainRequests int64, minSyncPeerVersion *version.Application) Network {
 	return &network{
 		appSender:                  appSender,
 		codec:                      codec,
@@ -126,6 +151,8 @@ func NewNetwork(p2pNetwork *p2p.Network, appSender common.AppSender, codec codec
 		appRequestHandler:          message.NoopRequestHandler{},
 		crossChainRequestHandler:   message.NoopCrossChainRequestHandler{},
 		peers:                      NewPeerTracker(),
+		peerVersions:               make(map[ids.NodeID]*version.Application),
+		minSyncPeerVersion:         minSyncPeerVersion,
 		appStats:                   stats.NewRequestHandlerStats(),
 		crossChainStats:            stats.NewCrossChainRequestHandlerStats(),
 	}
@@ -384,6 +411,12 @@ func (n *network) AppRequest(ctx context.Context, nodeID ids.NodeID, requestID u
 		return nil
 	}
 
+	if isSyncRequest(req) && !n.isSyncPeer(nodeID) {
+		log.Debug("refusing sync request from peer below minimum version", "nodeID", nodeID, "requestID", requestID, "minVersion", n.minSyncPeerVersion)
+		n.appStats.IncVersionRefusedRequest()
+		return n.appSender.SendAppError(ctx, nodeID, requestID, ErrPeerVersionTooLow.Code, ErrPeerVersionTooLow.Message)
+	}
+
 	log.Debug("processing incoming request", "nodeID", nodeID, "requestID", requestID, "req", req)
 	// We make a new context here because we don't want to cancel the context
 	// passed into n.AppSender.SendAppResponse below
@@ -401,6 +434,35 @@ func (n *network) AppRequest(ctx context.Context, nodeID ids.NodeID, requestID u
 	}
 }
 
+// isSyncRequest returns true if [req] is served from the state sync handlers.
+func isSyncRequest(req message.Request) bool {
+	switch req.(type) {
+	case message.LeafsRequest, message.BlockRequest, message.CodeRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// isSyncPeer returns true if there is no [minSyncPeerVersion], or if [nodeID]
+// is connected with a version that is at least [minSyncPeerVersion].
+func (n *network) isSyncPeer(nodeID ids.NodeID) bool {
+	if n.minSyncPeerVersion == nil {
+		return true
+	}
+
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	nodeVersion, ok := n.peerVersions[nodeID]
+	return ok && n.isSyncVersion(nodeVersion)
+}
+
+// isSyncVersion returns true if a peer running [nodeVersion] takes part in sync.
+func (n *network) isSyncVersion(nodeVersion *version.Application) bool {
+	return n.minSyncPeerVersion == nil || nodeVersion.Compare(n.minSyncPeerVersion) >= 0
+}
+
 // AppResponse is invoked when there is a response received from a peer regarding a request
 // Error returned by this function is expected to be treated as fatal by the engine
 // If [requestID] is not known, this function will emit a log and return a nil error.
@@ -506,8 +568,17 @@ func (n *network) Connected(ctx context.Context, nodeID ids.NodeID, nodeVersion
 	}
 
 	if nodeID != n.self {
+		n.peerVersions[nodeID] = nodeVersion
+
 		// The legacy peer tracker doesn't expect to be connected to itself.
-		n.peers.Connected(nodeID, nodeVersion)
+		// Peers below the minimum sync peer version are not tracked so that
+		// outbound requests are never routed to them.
+		if n.isSyncVersion(nodeVersion) {
+			n.peers.Connected(nodeID, nodeVersion)
+		} else {
+			log.Debug("not tracking peer below minimum sync peer version", "nodeID", nodeID, "nodeVersion", nodeVersion, "minVersion", n.minSyncPeerVersion)
+			n.peers.Disconnected(nodeID)
+		}
 	}
 
 	return n.p2pNetwork.Connected(ctx, nodeID, nodeVersion)
@@ -526,6 +597,7 @@ func (n *network) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
 	if nodeID != n.self {
 		// The legacy peer tracker doesn't expect to be connected to itself.
 		n.peers.Disconnected(nodeID)
+		delete(n.peerVersions, nodeID)
 	}
 
 	return n.p2pNetwork.Disconnected(ctx, nodeID)
@@ -543,7 +615,8 @@ func (n *network) Shutdown() {
 	}
 
 	n.peers = NewPeerTracker() // reset peers
-	n.closed.Set(true)         // mark network as closed
+	n.peerVersions = make(map[ids.NodeID]*version.Application)
+	n.closed.Set(true) // mark network as closed
 }
 
 func (n *network) SetGossipHandler(handler message.GossipHandler) {
@@ -571,7 +644,25 @@ func (n *network) Size() uint32 {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
 
-	return uint32(n.peers.Size())
+	return uint32(len(n.peerVersions))
+}
+
+func (n *network) PeerStats() []PeerStat {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	peerStats := make([]PeerStat, 0, len(n.peerVersions))
+	for nodeID, nodeVersion := range n.peerVersions {
+		peerStats = append(peerStats, PeerStat{
+			NodeID:       nodeID,
+			Version:      nodeVersion.String(),
+			SyncEligible: n.isSyncVersion(nodeVersion),
+		})
+	}
+	slices.SortFunc(peerStats, func(a, b PeerStat) int {
+		return a.NodeID.Compare(b.NodeID)
+	})
+	return peerStats
 }
 
 func (n *network) TrackBandwidth(nodeID ids.NodeID, bandwidth float64) {
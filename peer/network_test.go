@@ -61,11 +61,114 @@ func TestNetworkDoesNotConnectToItself(t *testing.T) {
 	selfNodeID := ids.GenerateTestNodeID()
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	n := NewNetwork(p2pNetwork, nil, nil, nil, selfNodeID, 1, 1)
+	n := NewNetwork(p2pNetwork, nil, nil, nil, selfNodeID, 1, 1, nil)
 	assert.NoError(t, n.Connected(context.Background(), selfNodeID, defaultPeerVersion))
 	assert.EqualValues(t, 0, n.Size())
 }
 
+func TestNetworkMinSyncPeerVersionRouting(t *testing.T) {
+	require := require.New(t)
+
+	var sentTo []ids.NodeID
+	sender := testAppSender{
+		sendAppRequestFn: func(_ context.Context, nodes set.Set[ids.NodeID], _ uint32, _ []byte) error {
+			sentTo = append(sentTo, nodes.List()...)
+			return nil
+		},
+	}
+	minVersion := &version.Application{Major: 1, Minor: 5, Patch: 0}
+	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
+	require.NoError(err)
+	net := NewNetwork(p2pNetwork, sender, nil, nil, ids.EmptyNodeID, 16, 16, minVersion)
+
+	peerVersions := map[ids.NodeID]*version.Application{
+		ids.GenerateTestNodeID(): {Major: 1, Minor: 4, Patch: 9},
+		ids.GenerateTestNodeID(): {Major: 1, Minor: 5, Patch: 0},
+		ids.GenerateTestNodeID(): {Major: 1, Minor: 6, Patch: 0},
+		ids.GenerateTestNodeID(): defaultPeerVersion,
+	}
+	for nodeID, nodeVersion := range peerVersions {
+		require.NoError(net.Connected(context.Background(), nodeID, nodeVersion))
+	}
+	require.EqualValues(len(peerVersions), net.Size())
+
+	for i := 0; i < 10; i++ {
+		_, err := net.SendAppRequestAny(context.Background(), nil, []byte("request"), newWaitingResponseHandler())
+		require.NoError(err)
+	}
+	require.Len(sentTo, 10)
+	for _, nodeID := range sentTo {
+		require.GreaterOrEqual(peerVersions[nodeID].Compare(minVersion), 0, "request sent to peer below minimum version")
+	}
+
+	peerStats := net.PeerStats()
+	require.Len(peerStats, len(peerVersions))
+	for _, peerStat := range peerStats {
+		nodeVersion := peerVersions[peerStat.NodeID]
+		require.Equal(nodeVersion.String(), peerStat.Version)
+		require.Equal(nodeVersion.Compare(minVersion) >= 0, peerStat.SyncEligible)
+	}
+}
+
+func TestNetworkMinSyncPeerVersionRefusal(t *testing.T) {
+	require := require.New(t)
+
+	type appError struct {
+		nodeID ids.NodeID
+		code   int32
+	}
+	var appErrors []appError
+	sender := testAppSender{
+		sendAppErrorFn: func(nodeID ids.NodeID, _ uint32, code int32, _ string) error {
+			appErrors = append(appErrors, appError{nodeID: nodeID, code: code})
+			return nil
+		},
+	}
+	minVersion := &version.Application{Major: 1, Minor: 5, Patch: 0}
+	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
+	require.NoError(err)
+	net := NewNetwork(p2pNetwork, sender, message.Codec, nil, ids.EmptyNodeID, 1, 1, minVersion)
+	handler := &syncRequestHandler{}
+	net.SetRequestHandler(handler)
+
+	var (
+		oldPeer     = ids.GenerateTestNodeID()
+		newPeer     = ids.GenerateTestNodeID()
+		unknownPeer = ids.GenerateTestNodeID()
+	)
+	require.NoError(net.Connected(context.Background(), oldPeer, &version.Application{Major: 1, Minor: 4, Patch: 0}))
+	require.NoError(net.Connected(context.Background(), newPeer, minVersion))
+
+	leafsRequest, err := message.RequestToBytes(message.Codec, message.LeafsRequest{NodeType: message.StateTrieNode})
+	require.NoError(err)
+	signatureRequest, err := message.RequestToBytes(message.Codec, message.MessageSignatureRequest{})
+	require.NoError(err)
+
+	deadline := time.Now().Add(5 * time.Second)
+	require.NoError(net.AppRequest(context.Background(), oldPeer, 0, deadline, leafsRequest))
+	require.NoError(net.AppRequest(context.Background(), unknownPeer, 1, deadline, leafsRequest))
+	require.Equal([]appError{
+		{nodeID: oldPeer, code: ErrPeerVersionTooLow.Code},
+		{nodeID: unknownPeer, code: ErrPeerVersionTooLow.Code},
+	}, appErrors)
+	require.Zero(handler.leafsRequests)
+
+	// Requests other than sync requests are served regardless of the version.
+	require.NoError(net.AppRequest(context.Background(), oldPeer, 2, deadline, signatureRequest))
+	require.Equal(1, handler.signatureRequests)
+
+	require.NoError(net.AppRequest(context.Background(), newPeer, 3, deadline, leafsRequest))
+	require.Equal(1, handler.leafsRequests)
+	require.Len(appErrors, 2)
+
+	// A peer that reconnects below the minimum version is refused again.
+	require.NoError(net.Disconnected(context.Background(), newPeer))
+	require.NoError(net.Connected(context.Background(), newPeer, defaultPeerVersion))
+	require.NoError(net.AppRequest(context.Background(), newPeer, 4, deadline, leafsRequest))
+	require.Equal(1, handler.leafsRequests)
+	require.Len(appErrors, 3)
+}
+
 func TestRequestAnyRequestsRoutingAndResponse(t *testing.T) {
 	callNum := uint32(0)
 	senderWg := &sync.WaitGroup{}
@@ -99,7 +202,7 @@ func TestRequestAnyRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16, nil)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	client := NewNetworkClient(net)
 	nodeID := ids.GenerateTestNodeID()
@@ -153,7 +256,7 @@ func TestAppRequestOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 
 	requestMessage := HelloRequest{Message: "this is a request"}
@@ -207,7 +310,7 @@ func TestRequestRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 16, 16, nil)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	client := NewNetworkClient(net)
 
@@ -289,7 +392,7 @@ func TestAppRequestOnShutdown(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	client := NewNetworkClient(net)
 	nodeID := ids.GenerateTestNodeID()
 	require.NoError(t, net.Connected(context.Background(), nodeID, defaultPeerVersion))
@@ -340,7 +443,7 @@ func TestAppRequestAnyOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetRequestHandler(&HelloGreetingRequestHandler{codec: codecManager})
 	assert.NoError(t,
 		net.Connected(
@@ -419,7 +522,7 @@ func TestRequestMinVersion(t *testing.T) {
 	// passing nil as codec works because the net.AppRequest is never called
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 16)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 16, nil)
 	client := NewNetworkClient(net)
 	requestMessage := TestMessage{Message: "this is a request"}
 	requestBytes, err := message.RequestToBytes(codecManager, requestMessage)
@@ -487,7 +590,7 @@ func TestOnRequestHonoursDeadline(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetRequestHandler(requestHandler)
 	nodeID := ids.GenerateTestNodeID()
 
@@ -518,7 +621,7 @@ func TestHandleInvalidMessages(t *testing.T) {
 	}
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, sender, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	clientNetwork.SetGossipHandler(message.NoopMempoolGossipHandler{})
 	clientNetwork.SetRequestHandler(&testRequestHandler{})
 
@@ -569,7 +672,7 @@ func TestNetworkPropagatesRequestHandlerError(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	clientNetwork := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	clientNetwork.SetGossipHandler(message.NoopMempoolGossipHandler{})
 	clientNetwork.SetRequestHandler(&testRequestHandler{err: errors.New("fail")}) // Return an error from the request handler
 
@@ -611,7 +714,7 @@ func TestCrossChainAppRequest(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 	client := NewNetworkClient(net)
 
@@ -648,7 +751,7 @@ func TestCrossChainAppRequestOnCtxCancellation(t *testing.T) {
 
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net := NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 
 	exampleCrossChainRequest := ExampleCrossChainRequest{
@@ -702,7 +805,7 @@ func TestCrossChainRequestRequestsRoutingAndResponse(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	net.SetCrossChainRequestHandler(&testCrossChainHandler{codec: crossChainCodecManager})
 	client := NewNetworkClient(net)
 
@@ -764,7 +867,7 @@ func TestCrossChainRequestOnShutdown(t *testing.T) {
 	crossChainCodecManager := buildCodec(t, ExampleCrossChainRequest{}, ExampleCrossChainResponse{})
 	p2pNetwork, err := p2p.NewNetwork(logging.NoLog{}, nil, prometheus.NewRegistry(), "")
 	require.NoError(t, err)
-	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1)
+	net = NewNetwork(p2pNetwork, sender, codecManager, crossChainCodecManager, ids.EmptyNodeID, 1, 1, nil)
 	client := NewNetworkClient(net)
 
 	exampleCrossChainRequest := ExampleCrossChainRequest{
@@ -788,7 +891,7 @@ func TestCrossChainRequestOnShutdown(t *testing.T) {
 func TestNetworkAppRequestAfterShutdown(t *testing.T) {
 	require := require.New(t)
 
-	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 1, 0)
+	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 1, 0, nil)
 	net.Shutdown()
 
 	require.NoError(net.SendAppRequest(context.Background(), ids.GenerateTestNodeID(), nil, nil))
@@ -798,7 +901,7 @@ func TestNetworkAppRequestAfterShutdown(t *testing.T) {
 func TestNetworkCrossChainAppRequestAfterShutdown(t *testing.T) {
 	require := require.New(t)
 
-	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 0, 1)
+	net := NewNetwork(nil, nil, nil, nil, ids.EmptyNodeID, 0, 1, nil)
 	net.Shutdown()
 
 	require.NoError(net.SendCrossChainRequest(context.Background(), ids.GenerateTestID(), nil, nil))
@@ -832,6 +935,7 @@ func TestNetworkRouting(t *testing.T) {
 		ids.EmptyNodeID,
 		1,
 		1,
+		nil,
 	)
 
 	nodeID := ids.GenerateTestNodeID()
@@ -877,6 +981,7 @@ type testAppSender struct {
 	sendAppRequestFn            func(context.Context, set.Set[ids.NodeID], uint32, []byte) error
 	sendAppResponseFn           func(ids.NodeID, uint32, []byte) error
 	sendAppGossipFn             func(common.SendConfig, []byte) error
+	sendAppErrorFn              func(ids.NodeID, uint32, int32, string) error
 }
 
 func (t testAppSender) SendCrossChainAppRequest(_ context.Context, chainID ids.ID, requestID uint32, appRequestBytes []byte) error {
@@ -899,8 +1004,8 @@ func (t testAppSender) SendAppGossip(_ context.Context, config common.SendConfig
 	return t.sendAppGossipFn(config, message)
 }
 
-func (t testAppSender) SendAppError(ctx context.Context, nodeID ids.NodeID, requestID uint32, errorCode int32, errorMessage string) error {
-	panic("not implemented")
+func (t testAppSender) SendAppError(_ context.Context, nodeID ids.NodeID, requestID uint32, errorCode int32, errorMessage string) error {
+	return t.sendAppErrorFn(nodeID, requestID, errorCode, errorMessage)
 }
 
 func (t testAppSender) SendCrossChainAppError(ctx context.Context, chainID ids.ID, requestID uint32, errorCode int32, errorMessage string) error {
@@ -1024,6 +1129,22 @@ func (r *testRequestHandler) handleTestRequest(ctx context.Context, _ ids.NodeID
 	return r.response, r.err
 }
 
+type syncRequestHandler struct {
+	message.RequestHandler
+	leafsRequests     int
+	signatureRequests int
+}
+
+func (s *syncRequestHandler) HandleStateTrieLeafsRequest(context.Context, ids.NodeID, uint32, message.LeafsRequest) ([]byte, error) {
+	s.leafsRequests++
+	return nil, nil
+}
+
+func (s *syncRequestHandler) HandleMessageSignatureRequest(context.Context, ids.NodeID, uint32, message.MessageSignatureRequest) ([]byte, error) {
+	s.signatureRequests++
+	return nil, nil
+}
+
 type ExampleCrossChainRequest struct {
 	Message string `serialize:"true"`
 }
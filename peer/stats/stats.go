@@ -13,17 +13,23 @@ import (
 type RequestHandlerStats interface {
 	UpdateTimeUntilDeadline(duration time.Duration)
 	IncDeadlineDroppedRequest()
+	IncVersionRefusedRequest()
 }
 
 type requestHandlerStats struct {
 	timeUntilDeadline metrics.Timer
 	droppedRequests   metrics.Counter
+	refusedRequests   metrics.Counter
 }
 
 func (h *requestHandlerStats) IncDeadlineDroppedRequest() {
 	h.droppedRequests.Inc(1)
 }
 
+func (h *requestHandlerStats) IncVersionRefusedRequest() {
+	h.refusedRequests.Inc(1)
+}
+
 func (h *requestHandlerStats) UpdateTimeUntilDeadline(duration time.Duration) {
 	h.timeUntilDeadline.Update(duration)
 }
@@ -32,6 +38,7 @@ func NewRequestHandlerStats() RequestHandlerStats {
 	return &requestHandlerStats{
 		timeUntilDeadline: metrics.GetOrRegisterTimer("net_req_time_until_deadline", nil),
 		droppedRequests:   metrics.GetOrRegisterCounter("net_req_deadline_dropped", nil),
+		refusedRequests:   metrics.GetOrRegisterCounter("net_req_version_refused", nil),
 	}
 }
 
@@ -39,5 +46,6 @@ func NewCrossChainRequestHandlerStats() RequestHandlerStats {
 	return &requestHandlerStats{
 		timeUntilDeadline: metrics.GetOrRegisterTimer("net_cross_chain_req_time_until_deadline", nil),
 		droppedRequests:   metrics.GetOrRegisterCounter("net_cross_chain_req_deadline_dropped", nil),
+		refusedRequests:   metrics.GetOrRegisterCounter("net_cross_chain_req_version_refused", nil),
 	}
 }
@@ -4,24 +4,50 @@
 package evm
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"sync"
 
 	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/profiler"
+	"github.com/ava-labs/coreth/peer"
 	"github.com/ethereum/go-ethereum/log"
 )
 
+const (
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+
+	// maxFinishedJobs is the number of finished jobs whose status is kept.
+	// The status of the oldest finished job is dropped once the limit is
+	// exceeded.
+	maxFinishedJobs = 16
+)
+
+var errUnknownJob = errors.New("unknown job")
+
 // Admin is the API service for admin API calls
 type Admin struct {
 	vm       *VM
 	profiler profiler.Profiler
+
+	// jobs tracks the status of admin calls which run in the background
+	jobsLock     sync.Mutex
+	nextJobID    uint64
+	jobs         map[uint64]*JobStatusReply
+	finishedJobs []uint64 // IDs of finished jobs in the order they finished
 }
 
 func NewAdminService(vm *VM, performanceDir string) *Admin {
 	return &Admin{
 		vm:       vm,
 		profiler: profiler.New(performanceDir),
+		jobs:     make(map[uint64]*JobStatusReply),
 	}
 }
 
@@ -89,3 +115,159 @@ func (p *Admin) GetVMConfig(_ *http.Request, _ *struct{}, reply *ConfigReply) er
 	reply.Config = &p.vm.config
 	return nil
 }
+
+type PeerStatsReply struct {
+	Peers []peer.PeerStat `json:"peers"`
+}
+
+// GetPeerStats returns the version of each connected peer and whether it
+// takes part in sync
+func (p *Admin) GetPeerStats(_ *http.Request, _ *struct{}, reply *PeerStatsReply) error {
+	reply.Peers = p.vm.Network.PeerStats()
+	return nil
+}
+
+type AtomicTxsFileArgs struct {
+	File string `json:"file"`
+}
+
+// ExportAtomicTxs writes the atomic transaction index to the specified file.
+// Blocks continue to be accepted during the export, so txs accepted while it
+// runs may not be included.
+func (p *Admin) ExportAtomicTxs(_ *http.Request, args *AtomicTxsFileArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: ExportAtomicTxs called", "file", args.File)
+
+	f, err := os.OpenFile(args.File, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := p.vm.atomicTxRepository.Export(w); err != nil {
+		return fmt.Errorf("failed to export atomic txs: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush export file: %w", err)
+	}
+	return f.Sync()
+}
+
+// ImportAtomicTxs populates the atomic transaction index from a file written
+// by ExportAtomicTxs. The txs in the file up to the last committed height of
+// the atomic trie are used to rebuild the atomic trie, and the import fails
+// without modifying the index unless the rebuilt root matches the committed
+// root. The file is read and verified while blocks continue to be accepted.
+func (p *Admin) ImportAtomicTxs(_ *http.Request, args *AtomicTxsFileArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: ImportAtomicTxs called", "file", args.File)
+
+	f, err := os.Open(args.File)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	p.vm.ctx.Lock.Lock()
+	lastCommittedRoot, lastCommittedHeight := p.vm.atomicTrie.LastCommitted()
+	p.vm.ctx.Lock.Unlock()
+
+	imported, err := p.vm.atomicTxRepository.Import(bufio.NewReader(f), lastCommittedHeight)
+	if err != nil {
+		return fmt.Errorf("failed to import atomic txs: %w", err)
+	}
+	if err := verifyAtomicTrieRoot(imported.Repository(), p.vm.codec, lastCommittedHeight, lastCommittedRoot); err != nil {
+		return fmt.Errorf("failed to verify imported atomic txs: %w", err)
+	}
+
+	p.vm.ctx.Lock.Lock()
+	defer p.vm.ctx.Lock.Unlock()
+
+	if err := imported.Commit(); err != nil {
+		return fmt.Errorf("failed to commit imported atomic txs: %w", err)
+	}
+	return nil
+}
+
+type JobReply struct {
+	JobID json.Uint64 `json:"jobID"`
+}
+
+type JobStatusArgs struct {
+	JobID json.Uint64 `json:"jobID"`
+}
+
+type JobStatusReply struct {
+	Status string               `json:"status"`
+	Error  string               `json:"error,omitempty"`
+	Report *AtomicTxIndexReport `json:"report,omitempty"`
+}
+
+// VerifyAtomicTxs starts verifying the consistency of the atomic tx indexes in
+// the background and returns a job ID which can be passed to GetJobStatus.
+// Blocks continue to be accepted while the verification is running.
+func (p *Admin) VerifyAtomicTxs(_ *http.Request, _ *struct{}, reply *JobReply) error {
+	log.Info("Admin: VerifyAtomicTxs called")
+
+	reply.JobID = json.Uint64(p.startJob(func() (*AtomicTxIndexReport, error) {
+		err := p.vm.atomicTxRepository.Verify(func() uint64 {
+			return p.vm.blockChain.LastAcceptedBlock().NumberU64()
+		})
+		var indexErr *AtomicTxIndexError
+		switch {
+		case err == nil:
+			return &AtomicTxIndexReport{}, nil
+		case errors.As(err, &indexErr):
+			return &indexErr.Report, err
+		default:
+			return nil, err
+		}
+	}))
+	return nil
+}
+
+// GetJobStatus returns the status of a job started by an admin call. Only the
+// status of the [maxFinishedJobs] most recently finished jobs is kept.
+func (p *Admin) GetJobStatus(_ *http.Request, args *JobStatusArgs, reply *JobStatusReply) error {
+	p.jobsLock.Lock()
+	defer p.jobsLock.Unlock()
+
+	status, ok := p.jobs[uint64(args.JobID)]
+	if !ok {
+		return fmt.Errorf("%w: %d", errUnknownJob, args.JobID)
+	}
+	*reply = *status
+	return nil
+}
+
+// startJob runs [job] in a new goroutine and returns the ID its status is
+// tracked under.
+func (p *Admin) startJob(job func() (*AtomicTxIndexReport, error)) uint64 {
+	p.jobsLock.Lock()
+	jobID := p.nextJobID
+	p.nextJobID++
+	p.jobs[jobID] = &JobStatusReply{Status: JobStatusRunning}
+	p.jobsLock.Unlock()
+
+	go func() {
+		report, err := job()
+		status := &JobStatusReply{
+			Status: JobStatusSucceeded,
+			Report: report,
+		}
+		if err != nil {
+			status.Status = JobStatusFailed
+			status.Error = err.Error()
+		}
+		log.Info("Admin: job finished", "jobID", jobID, "status", status.Status, "err", err)
+
+		p.jobsLock.Lock()
+		p.jobs[jobID] = status
+		p.finishedJobs = append(p.finishedJobs, jobID)
+		if len(p.finishedJobs) > maxFinishedJobs {
+			delete(p.jobs, p.finishedJobs[0])
+			p.finishedJobs = p.finishedJobs[1:]
+		}
+		p.jobsLock.Unlock()
+	}()
+	return jobID
+}
@@ -0,0 +1,85 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminJobStatus(t *testing.T) {
+	require := require.New(t)
+
+	admin := NewAdminService(nil, t.TempDir())
+
+	release := make(chan struct{})
+	report := &AtomicTxIndexReport{Missing: 1}
+	errJob := errors.New("job failed")
+	jobID := admin.startJob(func() (*AtomicTxIndexReport, error) {
+		<-release
+		return report, errJob
+	})
+
+	reply := &JobStatusReply{}
+	require.NoError(admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobID)}, reply))
+	require.Equal(&JobStatusReply{Status: JobStatusRunning}, reply)
+
+	close(release)
+	require.Eventually(func() bool {
+		reply := &JobStatusReply{}
+		err := admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobID)}, reply)
+		return err == nil && reply.Status != JobStatusRunning
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobID)}, reply))
+	require.Equal(&JobStatusReply{
+		Status: JobStatusFailed,
+		Error:  errJob.Error(),
+		Report: report,
+	}, reply)
+
+	// Job IDs are not reused.
+	require.NotEqual(jobID, admin.startJob(func() (*AtomicTxIndexReport, error) {
+		return &AtomicTxIndexReport{}, nil
+	}))
+
+	err := admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobID + 100)}, reply)
+	require.ErrorIs(err, errUnknownJob)
+}
+
+func TestAdminFinishedJobsEvicted(t *testing.T) {
+	require := require.New(t)
+
+	admin := NewAdminService(nil, t.TempDir())
+
+	finished := func(jobID uint64) bool {
+		reply := &JobStatusReply{}
+		err := admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobID)}, reply)
+		return err == nil && reply.Status == JobStatusSucceeded
+	}
+	jobIDs := make([]uint64, 0, maxFinishedJobs+1)
+	for i := 0; i < maxFinishedJobs+1; i++ {
+		jobID := admin.startJob(func() (*AtomicTxIndexReport, error) {
+			return &AtomicTxIndexReport{}, nil
+		})
+		if i < maxFinishedJobs {
+			require.Eventually(func() bool { return finished(jobID) }, 10*time.Second, 10*time.Millisecond)
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	// Once the last job finishes, the status of the first is dropped.
+	require.Eventually(func() bool {
+		reply := &JobStatusReply{}
+		err := admin.GetJobStatus(nil, &JobStatusArgs{JobID: json.Uint64(jobIDs[0])}, reply)
+		return errors.Is(err, errUnknownJob)
+	}, 10*time.Second, 10*time.Millisecond)
+	for _, jobID := range jobIDs[1:] {
+		require.True(finished(jobID))
+	}
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/coreth/core/types"
 	syncclient "github.com/ava-labs/coreth/sync/client"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -222,6 +224,48 @@ func (a *atomicBackend) initialize(lastAcceptedHeight uint64) error {
 	return nil
 }
 
+// verifyAtomicTrieRoot rebuilds the atomic trie in memory from the txs in
+// [repo] accepted at or below [height] and returns an error if its root does
+// not match [expectedRoot].
+func verifyAtomicTrieRoot(repo AtomicTxRepository, codec codec.Manager, height uint64, expectedRoot common.Hash) error {
+	atomicTrie, err := newAtomicTrie(memdb.New(), memdb.New(), codec, 0, defaultCommitInterval)
+	if err != nil {
+		return err
+	}
+	tr, err := atomicTrie.OpenTrie(types.EmptyRootHash)
+	if err != nil {
+		return err
+	}
+
+	iter := repo.IterateByHeight(1)
+	defer iter.Release()
+	for iter.Next() {
+		txHeight := binary.BigEndian.Uint64(iter.Key())
+		if txHeight > height {
+			break
+		}
+		txs, err := ExtractAtomicTxs(iter.Value(), true, codec)
+		if err != nil {
+			return err
+		}
+		combinedOps, err := mergeAtomicOps(txs)
+		if err != nil {
+			return err
+		}
+		if err := atomicTrie.UpdateTrie(tr, txHeight, combinedOps); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	if root := tr.Hash(); root != expectedRoot {
+		return fmt.Errorf("atomic trie root %s at height %d does not match expected root %s", root, height, expectedRoot)
+	}
+	return nil
+}
+
 // ApplyToSharedMemory applies the atomic operations that have been indexed into the trie
 // but not yet applied to shared memory for heights less than or equal to [lastAcceptedBlock].
 // This executes operations in the range [cursorHeight+1, lastAcceptedBlock].
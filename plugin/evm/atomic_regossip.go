@@ -0,0 +1,177 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ava-labs/coreth/metrics"
+	"github.com/ava-labs/coreth/plugin/evm/message"
+)
+
+// atomicRegossipFrequency is how often the pending atomic txs due to be
+// re-gossiped are looked up.
+const atomicRegossipFrequency = time.Second
+
+type atomicRegossipMetrics struct {
+	attempts  metrics.Counter // txs re-gossiped
+	bytes     metrics.Counter // bytes of the txs re-gossiped
+	invalid   metrics.Counter // txs found invalid instead of being re-gossiped
+	throttled metrics.Counter // txs delayed by the bandwidth cap
+}
+
+func newAtomicRegossipMetrics() *atomicRegossipMetrics {
+	return &atomicRegossipMetrics{
+		attempts:  metrics.GetOrRegisterCounter("atomic_regossip_attempts", nil),
+		bytes:     metrics.GetOrRegisterCounter("atomic_regossip_bytes", nil),
+		invalid:   metrics.GetOrRegisterCounter("atomic_regossip_invalid", nil),
+		throttled: metrics.GetOrRegisterCounter("atomic_regossip_throttled", nil),
+	}
+}
+
+type atomicRegossipConfig struct {
+	// MinBackoff is how long after it is first seen a pending tx is
+	// re-gossiped, and MaxBackoff the maximum time between two re-gossips of
+	// a tx. The time between re-gossips of a tx doubles every time.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxBytes is the maximum number of tx bytes re-gossiped per round.
+	MaxBytes int
+	// Gossip is the branching factor of the re-gossip messages.
+	Gossip commonEng.SendConfig
+}
+
+// atomicRegossipState is the re-gossip schedule of a pending tx.
+type atomicRegossipState struct {
+	next    time.Time
+	backoff time.Duration
+}
+
+// atomicTxRegossiper re-gossips the atomic txs pending in the mempool, in
+// case the gossip sent when they were issued did not reach the block
+// producers. Each tx is re-gossiped with an exponential backoff, and is
+// verified first so that the txs whose UTXOs were consumed since they were
+// issued are removed from the mempool rather than re-gossiped.
+type atomicTxRegossiper struct {
+	config  atomicRegossipConfig
+	mempool *Mempool
+	verify  func(tx *Tx) error
+	sender  commonEng.AppSender
+	codec   codec.Manager
+	metrics *atomicRegossipMetrics
+
+	lock  sync.Mutex
+	state map[ids.ID]*atomicRegossipState
+}
+
+func newAtomicTxRegossiper(
+	config atomicRegossipConfig,
+	mempool *Mempool,
+	verify func(tx *Tx) error,
+	sender commonEng.AppSender,
+	codec codec.Manager,
+) *atomicTxRegossiper {
+	return &atomicTxRegossiper{
+		config:  config,
+		mempool: mempool,
+		verify:  verify,
+		sender:  sender,
+		codec:   codec,
+		metrics: newAtomicRegossipMetrics(),
+		state:   make(map[ids.ID]*atomicRegossipState),
+	}
+}
+
+// Run re-gossips the pending txs every [frequency] until [ctx] is cancelled.
+func (r *atomicTxRegossiper) Run(ctx context.Context, frequency time.Duration) {
+	ticker := time.NewTicker(frequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			r.Regossip(ctx, now)
+		case <-ctx.Done():
+			log.Debug("shutting down atomic tx regossip")
+			return
+		}
+	}
+}
+
+// Regossip re-gossips the pending txs due at [now], up to the bandwidth cap.
+// The txs delayed by the cap are re-gossiped in the next rounds.
+func (r *atomicTxRegossiper) Regossip(ctx context.Context, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// The pending txs are collected first, as verifying or removing them
+	// while iterating over the mempool would deadlock.
+	var txs []*Tx
+	r.mempool.Iterate(func(tx *GossipAtomicTx) bool {
+		txs = append(txs, tx.Tx)
+		return true
+	})
+
+	pending := make(map[ids.ID]struct{}, len(txs))
+	budget := r.config.MaxBytes
+	for _, tx := range txs {
+		txID := tx.ID()
+		pending[txID] = struct{}{}
+		state, ok := r.state[txID]
+		if !ok {
+			r.state[txID] = &atomicRegossipState{
+				next:    now.Add(r.config.MinBackoff),
+				backoff: r.config.MinBackoff,
+			}
+			continue
+		}
+		if now.Before(state.next) {
+			continue
+		}
+
+		if err := r.verify(tx); err != nil {
+			log.Debug("removing invalid atomic tx instead of regossiping it", "txID", txID, "err", err)
+			r.metrics.invalid.Inc(1)
+			r.mempool.RemoveTx(tx)
+			delete(r.state, txID)
+			delete(pending, txID)
+			continue
+		}
+		txBytes := tx.SignedBytes()
+		if len(txBytes) > budget {
+			r.metrics.throttled.Inc(1)
+			continue
+		}
+		msgBytes, err := message.BuildGossipMessage(r.codec, message.AtomicTxGossip{Tx: txBytes})
+		if err != nil {
+			log.Warn("failed to build atomic tx regossip message", "txID", txID, "err", err)
+			continue
+		}
+		if err := r.sender.SendAppGossip(ctx, r.config.Gossip, msgBytes); err != nil {
+			log.Warn("failed to regossip atomic tx", "txID", txID, "err", err)
+			continue
+		}
+		budget -= len(txBytes)
+		r.metrics.attempts.Inc(1)
+		r.metrics.bytes.Inc(int64(len(txBytes)))
+
+		state.backoff = min(2*state.backoff, r.config.MaxBackoff)
+		state.next = now.Add(state.backoff)
+	}
+
+	// The txs which are no longer pending were issued, or removed from the
+	// mempool.
+	for txID := range r.state {
+		if _, ok := pending[txID]; !ok {
+			delete(r.state, txID)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/coreth/plugin/evm/message"
+)
+
+var errTestTxConsumed = errors.New("tx consumed")
+
+// newAtomicRegossipTest returns an atomicTxRegossiper of the txs in a new
+// mempool, which considers the txs in [invalid] invalid, and a function
+// returning the txs gossiped since its last call.
+func newAtomicRegossipTest(t *testing.T, config atomicRegossipConfig, invalid set.Set[ids.ID]) (*atomicTxRegossiper, *Mempool, func() []ids.ID) {
+	require := require.New(t)
+
+	mempool, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 10, 0, 0, nil)
+	require.NoError(err)
+
+	var (
+		lock     sync.Mutex
+		gossiped []ids.ID
+	)
+	sender := &enginetest.Sender{T: t}
+	sender.SendAppGossipF = func(_ context.Context, sendConfig commonEng.SendConfig, msgBytes []byte) error {
+		require.Equal(config.Gossip, sendConfig)
+		msg, err := message.ParseGossipMessage(message.Codec, msgBytes)
+		require.NoError(err)
+		require.IsType(message.AtomicTxGossip{}, msg)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		// The signed bytes of the test txs are their IDs.
+		gossiped = append(gossiped, ids.ID(msg.(message.AtomicTxGossip).Tx))
+		return nil
+	}
+	verify := func(tx *Tx) error {
+		if invalid.Contains(tx.ID()) {
+			return errTestTxConsumed
+		}
+		return nil
+	}
+	regossiper := newAtomicTxRegossiper(config, mempool, verify, sender, message.Codec)
+	return regossiper, mempool, func() []ids.ID {
+		lock.Lock()
+		defer lock.Unlock()
+
+		txIDs := gossiped
+		gossiped = nil
+		return txIDs
+	}
+}
+
+// newAtomicRegossipTestTx returns a tx whose signed bytes are its ID.
+func newAtomicRegossipTestTx() *Tx {
+	txID := ids.GenerateTestID()
+	return &Tx{
+		UnsignedAtomicTx: &TestUnsignedTx{
+			IDV:          txID,
+			SignedBytesV: txID[:],
+		},
+	}
+}
+
+func TestAtomicTxRegossipBackoff(t *testing.T) {
+	require := require.New(t)
+
+	config := atomicRegossipConfig{
+		MinBackoff: 10 * time.Second,
+		MaxBackoff: 30 * time.Second,
+		MaxBytes:   1024,
+		Gossip:     commonEng.SendConfig{Validators: 10},
+	}
+	regossiper, mempool, gossiped := newAtomicRegossipTest(t, config, nil)
+	tx := newAtomicRegossipTestTx()
+	require.NoError(mempool.Add(&GossipAtomicTx{Tx: tx}))
+
+	start := time.Unix(1000, 0)
+	attempts := regossiper.metrics.attempts.Snapshot().Count()
+	for _, test := range []struct {
+		after    time.Duration
+		gossiped bool
+	}{
+		// The tx is first seen, and re-gossiped after the minimum backoff.
+		{after: 0},
+		{after: 9 * time.Second},
+		{after: 10 * time.Second, gossiped: true},
+		// The backoff doubles every time, up to the maximum backoff.
+		{after: 29 * time.Second},
+		{after: 30 * time.Second, gossiped: true},
+		{after: 59 * time.Second},
+		{after: 60 * time.Second, gossiped: true},
+		{after: 89 * time.Second},
+		{after: 90 * time.Second, gossiped: true},
+	} {
+		regossiper.Regossip(context.Background(), start.Add(test.after))
+		if test.gossiped {
+			require.Equal([]ids.ID{tx.ID()}, gossiped(), "after %s", test.after)
+		} else {
+			require.Empty(gossiped(), "after %s", test.after)
+		}
+	}
+	require.Equal(attempts+4, regossiper.metrics.attempts.Snapshot().Count())
+
+	// The tx is no longer re-gossiped once it leaves the mempool.
+	mempool.RemoveTx(tx)
+	regossiper.Regossip(context.Background(), start.Add(time.Hour))
+	require.Empty(gossiped())
+	require.Empty(regossiper.state)
+}
+
+func TestAtomicTxRegossipBandwidthCap(t *testing.T) {
+	require := require.New(t)
+
+	config := atomicRegossipConfig{
+		MinBackoff: 10 * time.Second,
+		MaxBackoff: time.Minute,
+		MaxBytes:   2 * ids.IDLen,
+	}
+	regossiper, mempool, gossiped := newAtomicRegossipTest(t, config, nil)
+	txIDs := set.NewSet[ids.ID](3)
+	for i := 0; i < 3; i++ {
+		tx := newAtomicRegossipTestTx()
+		require.NoError(mempool.Add(&GossipAtomicTx{Tx: tx}))
+		txIDs.Add(tx.ID())
+	}
+
+	start := time.Unix(1000, 0)
+	regossiper.Regossip(context.Background(), start)
+	require.Empty(gossiped())
+
+	// Only 2 of the txs fit in a round, and the third is re-gossiped in the
+	// next one.
+	throttled := regossiper.metrics.throttled.Snapshot().Count()
+	regossiper.Regossip(context.Background(), start.Add(10*time.Second))
+	first := gossiped()
+	require.Len(first, 2)
+	require.Equal(throttled+1, regossiper.metrics.throttled.Snapshot().Count())
+
+	regossiper.Regossip(context.Background(), start.Add(11*time.Second))
+	second := gossiped()
+	require.Len(second, 1)
+	require.Equal(txIDs, set.Of(append(first, second...)...))
+}
+
+func TestAtomicTxRegossipInvalidTx(t *testing.T) {
+	require := require.New(t)
+
+	config := atomicRegossipConfig{
+		MinBackoff: 10 * time.Second,
+		MaxBackoff: time.Minute,
+		MaxBytes:   1024,
+	}
+	invalid := set.NewSet[ids.ID](1)
+	regossiper, mempool, gossiped := newAtomicRegossipTest(t, config, invalid)
+	validTx := newAtomicRegossipTestTx()
+	invalidTx := newAtomicRegossipTestTx()
+	require.NoError(mempool.Add(&GossipAtomicTx{Tx: validTx}))
+	require.NoError(mempool.Add(&GossipAtomicTx{Tx: invalidTx}))
+
+	start := time.Unix(1000, 0)
+	regossiper.Regossip(context.Background(), start)
+	require.Empty(gossiped())
+
+	// The UTXOs of [invalidTx] are consumed before it is re-gossiped, so it
+	// is removed from the mempool instead.
+	invalid.Add(invalidTx.ID())
+	invalidCount := regossiper.metrics.invalid.Snapshot().Count()
+	regossiper.Regossip(context.Background(), start.Add(10*time.Second))
+	require.Equal([]ids.ID{validTx.ID()}, gossiped())
+	require.Equal(invalidCount+1, regossiper.metrics.invalid.Snapshot().Count())
+	require.True(mempool.Has(validTx.ID()))
+	require.False(mempool.Has(invalidTx.ID()))
+}
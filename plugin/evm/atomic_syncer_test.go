@@ -50,7 +50,7 @@ func testAtomicSyncer(t *testing.T, serverTrieDB *trie.Database, targetHeight ui
 	)
 
 	clientDB := versiondb.New(memdb.New())
-	repo, err := NewAtomicTxRepository(clientDB, message.Codec, 0)
+	repo, err := NewAtomicTxRepository(clientDB, message.Codec, 0, defaultAtomicTxCacheSize)
 	if err != nil {
 		t.Fatal("could not initialize atomix tx repository", err)
 	}
@@ -25,7 +25,7 @@ func TestIteratorCanIterate(t *testing.T) {
 	lastAcceptedHeight := uint64(1000)
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
-	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight, defaultAtomicTxCacheSize)
 	assert.NoError(t, err)
 
 	// create state with multiple transactions
@@ -65,7 +65,7 @@ func TestIteratorHandlesInvalidData(t *testing.T) {
 	lastAcceptedHeight := uint64(1000)
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
-	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight)
+	repo, err := NewAtomicTxRepository(db, codec, lastAcceptedHeight, defaultAtomicTxCacheSize)
 	require.NoError(err)
 
 	// create state with multiple transactions
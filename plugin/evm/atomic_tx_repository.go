@@ -4,35 +4,53 @@
 package evm
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
+
+	"github.com/ava-labs/coreth/metrics"
 )
 
 const (
 	repoCommitSizeCap = 10 * units.MiB
+
+	// maxExportRecordSize is the maximum size of a single record read by Import.
+	maxExportRecordSize = 1 * units.MiB
 )
 
 var (
 	atomicTxIDDBPrefix         = []byte("atomicTxDB")
 	atomicHeightTxDBPrefix     = []byte("atomicHeightTxDB")
 	atomicRepoMetadataDBPrefix = []byte("atomicRepoMetadataDB")
+	atomicAddressTxDBPrefix    = []byte("atomicAddressTxDB")
 	maxIndexedHeightKey        = []byte("maxIndexedAtomicTxHeight")
+	addressIndexInitializedKey = []byte("addressIndexInitialized")
 
 	// Historically used to track the completion of a migration
 	// bonusBlocksRepairedKey     = []byte("bonusBlocksRepaired")
+
+	errRepositoryNotEmpty = errors.New("atomic tx repository is not empty")
+	errInconsistentIndex  = errors.New("atomic tx index is inconsistent")
+	errInvalidRange       = errors.New("invalid range")
 )
 
 // AtomicTxRepository defines an entity that manages storage and indexing of
@@ -41,11 +59,67 @@ type AtomicTxRepository interface {
 	GetIndexHeight() (uint64, error)
 	GetByTxID(txID ids.ID) (*Tx, uint64, error)
 	GetByHeight(height uint64) ([]*Tx, error)
+	GetByHeightRange(from, to uint64) ([]*Tx, error)
+	GetByTimestampRange(from, to time.Time, blockTimestamps map[uint64]time.Time) ([]*Tx, error)
+	GetCountByAddress(addr common.Address) (uint64, error)
 	Write(height uint64, txs []*Tx) error
 	WriteBonus(height uint64, txs []*Tx) error
 
 	IterateByHeight(start uint64) database.Iterator
 	Codec() codec.Manager
+
+	// Len returns the total number of atomic txs indexed by txID.
+	Len() (uint64, error)
+
+	// Export writes every atomic tx to [w], in the format read by Import.
+	Export(w io.Writer) error
+	// Import reads the atomic txs written by Export from [r] into an
+	// AtomicTxImport, which leaves the repository unchanged until it is
+	// committed. Import fails if a tx is above [maxHeight], which callers set
+	// to the last height the imported txs can be verified against, such as
+	// the last committed height of the atomic trie.
+	Import(r io.Reader, maxHeight uint64) (*AtomicTxImport, error)
+
+	// Verify checks the txID and height indexes are consistent with each
+	// other and that no txs are indexed above the height returned by
+	// [lastAcceptedHeight]. Blocks may be accepted while Verify runs, so txs
+	// indexed above the index height when Verify is called are not checked
+	// for consistency, and [lastAcceptedHeight] is called again before a
+	// height entry is reported as above the last accepted height.
+	Verify(lastAcceptedHeight func() uint64) error
+}
+
+// AtomicTxIndexReport summarizes the inconsistencies found while verifying
+// the atomic tx indexes.
+type AtomicTxIndexReport struct {
+	// Missing is the number of txs indexed by txID which are not present in
+	// the height entry the txID index points to.
+	Missing uint64 `json:"missing"`
+	// Extra is the number of txs in the height index which are not indexed by
+	// txID.
+	Extra uint64 `json:"extra"`
+	// Duplicate is the number of txs which appear more than once in a single
+	// height entry.
+	Duplicate uint64 `json:"duplicate"`
+	// AboveLastAccepted is the number of height entries above the last
+	// accepted height.
+	AboveLastAccepted uint64 `json:"aboveLastAccepted"`
+}
+
+// AtomicTxIndexError is returned by Verify if the atomic tx indexes are
+// inconsistent.
+type AtomicTxIndexError struct {
+	Report AtomicTxIndexReport
+}
+
+func (e *AtomicTxIndexError) Error() string {
+	return fmt.Sprintf("%s: missing=%d extra=%d duplicate=%d aboveLastAccepted=%d",
+		errInconsistentIndex, e.Report.Missing, e.Report.Extra, e.Report.Duplicate, e.Report.AboveLastAccepted,
+	)
+}
+
+func (e *AtomicTxIndexError) Unwrap() error {
+	return errInconsistentIndex
 }
 
 // atomicTxRepository is a prefixdb implementation of the AtomicTxRepository interface
@@ -60,27 +134,123 @@ type atomicTxRepository struct {
 	// has indexed.
 	atomicRepoMetadataDB database.Database
 
+	// [byAddressDB] maintains an index of [address] => [count] of atomic txs sending funds from or to [address].
+	byAddressDB database.Database
+
 	// [db] is used to commit to the underlying versiondb.
 	db *versiondb.Database
 
 	// Use this codec for serializing
 	codec codec.Manager
+
+	// [txCache] caches recently accessed txs from [acceptedAtomicTxDB] by txID.
+	txCache     *cache.LRU[ids.ID, *indexedTx]
+	cacheHits   metrics.Counter
+	cacheMisses metrics.Counter
+
+	// [txCount] caches the number of entries in [acceptedAtomicTxDB] so that
+	// Len does not need to perform a full scan.
+	txCountLock sync.RWMutex
+	txCount     uint64
+}
+
+// indexedTx is an atomic tx along with the height it was accepted at.
+type indexedTx struct {
+	tx     *Tx
+	height uint64
 }
 
 func NewAtomicTxRepository(
-	db *versiondb.Database, codec codec.Manager, lastAcceptedHeight uint64,
+	db *versiondb.Database, codec codec.Manager, lastAcceptedHeight uint64, cacheSize int,
 ) (*atomicTxRepository, error) {
-	repo := &atomicTxRepository{
+	repo := newAtomicTxRepository(db, codec, cacheSize)
+	if err := repo.initializeHeightIndex(lastAcceptedHeight); err != nil {
+		return nil, err
+	}
+	if err := repo.initializeAddressIndex(); err != nil {
+		return nil, err
+	}
+	if err := repo.initializeTxCount(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// newAtomicTxRepository returns a repository backed by [db] without
+// initializing its indexes.
+func newAtomicTxRepository(db *versiondb.Database, codec codec.Manager, cacheSize int) *atomicTxRepository {
+	return &atomicTxRepository{
+		txCache:                    &cache.LRU[ids.ID, *indexedTx]{Size: cacheSize},
+		cacheHits:                  metrics.GetOrRegisterCounter("atomic_tx_repository_cache_hits", nil),
+		cacheMisses:                metrics.GetOrRegisterCounter("atomic_tx_repository_cache_misses", nil),
 		acceptedAtomicTxDB:         prefixdb.New(atomicTxIDDBPrefix, db),
 		acceptedAtomicTxByHeightDB: prefixdb.New(atomicHeightTxDBPrefix, db),
 		atomicRepoMetadataDB:       prefixdb.New(atomicRepoMetadataDBPrefix, db),
+		byAddressDB:                prefixdb.New(atomicAddressTxDBPrefix, db),
 		codec:                      codec,
 		db:                         db,
 	}
-	if err := repo.initializeHeightIndex(lastAcceptedHeight); err != nil {
-		return nil, err
+}
+
+// initializeAddressIndex populates [byAddressDB] from the txs indexed in
+// [acceptedAtomicTxDB] if it has not yet been populated.
+func (a *atomicTxRepository) initializeAddressIndex() error {
+	initialized, err := a.atomicRepoMetadataDB.Has(addressIndexInitializedKey)
+	if err != nil {
+		return err
 	}
-	return repo, nil
+	if initialized {
+		return nil
+	}
+
+	startTime := time.Now()
+	iter := a.acceptedAtomicTxDB.NewIterator()
+	defer iter.Release()
+
+	indexedTxs := 0
+	for iter.Next() {
+		// iter.Value() consists of [height packed as uint64] + [tx serialized as packed []byte]
+		iterValue := iter.Value()
+		if len(iterValue) < wrappers.LongLen+wrappers.IntLen {
+			return fmt.Errorf("atomic tx DB iterator value had invalid length (%d) < (%d)", len(iterValue), wrappers.LongLen+wrappers.IntLen)
+		}
+		tx, err := ExtractAtomicTx(iterValue[wrappers.LongLen+wrappers.IntLen:], a.codec)
+		if err != nil {
+			return err
+		}
+		if err := a.incrementAddressCounts(tx); err != nil {
+			return err
+		}
+		indexedTxs++
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("atomic tx DB iterator errored while initializing address index: %w", err)
+	}
+	if err := database.PutBool(a.atomicRepoMetadataDB, addressIndexInitializedKey, true); err != nil {
+		return err
+	}
+	log.Info("Completed atomic transaction address index initialization", "indexedTxs", indexedTxs, "duration", time.Since(startTime))
+	return a.db.Commit()
+}
+
+// initializeTxCount counts the entries in [acceptedAtomicTxDB] so that the
+// result can be served by Len without iterating the database again.
+func (a *atomicTxRepository) initializeTxCount() error {
+	iter := a.acceptedAtomicTxDB.NewIterator()
+	defer iter.Release()
+
+	var count uint64
+	for iter.Next() {
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("atomic tx DB iterator errored while counting atomic txs: %w", err)
+	}
+
+	a.txCountLock.Lock()
+	a.txCount = count
+	a.txCountLock.Unlock()
+	return nil
 }
 
 // initializeHeightIndex initializes the atomic repository and takes care of any required migration from the previous database
@@ -201,7 +371,15 @@ func (a *atomicTxRepository) GetIndexHeight() (uint64, error) {
 // GetByTxID queries [acceptedAtomicTxDB] for the [txID], parses a [*Tx] object
 // if an entry is found, and returns it with the block height the atomic tx it
 // represents was accepted on, along with an optional error.
+// Recently accessed txs are served from a cache, so callers must not modify
+// the returned [*Tx].
 func (a *atomicTxRepository) GetByTxID(txID ids.ID) (*Tx, uint64, error) {
+	if cached, ok := a.txCache.Get(txID); ok {
+		a.cacheHits.Inc(1)
+		return cached.tx, cached.height, nil
+	}
+	a.cacheMisses.Inc(1)
+
 	indexedTxBytes, err := a.acceptedAtomicTxDB.Get(txID[:])
 	if err != nil {
 		return nil, 0, err
@@ -220,6 +398,7 @@ func (a *atomicTxRepository) GetByTxID(txID ids.ID) (*Tx, uint64, error) {
 		return nil, 0, err
 	}
 
+	a.txCache.Put(txID, &indexedTx{tx: tx, height: height})
 	return tx, height, nil
 }
 
@@ -236,6 +415,66 @@ func (a *atomicTxRepository) GetByHeight(height uint64) ([]*Tx, error) {
 	return a.getByHeightBytes(heightBytes)
 }
 
+// GetByHeightRange returns all atomic txs processed on blocks at heights in
+// [from, to], in order of increasing height.
+func (a *atomicTxRepository) GetByHeightRange(from, to uint64) ([]*Tx, error) {
+	if from > to {
+		return nil, fmt.Errorf("%w: from height %d is above to height %d", errInvalidRange, from, to)
+	}
+	iter := a.IterateByHeight(from)
+	defer iter.Release()
+
+	var txs []*Tx
+	for iter.Next() {
+		heightBytes := iter.Key()
+		if len(heightBytes) != wrappers.LongLen {
+			return nil, fmt.Errorf("atomic tx height DB iterator key had invalid length (%d) != (%d)", len(heightBytes), wrappers.LongLen)
+		}
+		if binary.BigEndian.Uint64(heightBytes) > to {
+			break
+		}
+		heightTxs, err := ExtractAtomicTxsBatch(iter.Value(), a.codec)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, heightTxs...)
+	}
+	return txs, iter.Error()
+}
+
+// GetByTimestampRange returns all atomic txs processed on blocks with a
+// timestamp in [from, to], in order of increasing height. The timestamps of
+// blocks are looked up in [blockTimestamps], which maps heights to block
+// timestamps. Since block timestamps do not decrease with height, the window
+// is converted to the range between the lowest and highest heights with a
+// timestamp in the window, so heights missing from [blockTimestamps] within
+// that range are included.
+func (a *atomicTxRepository) GetByTimestampRange(from, to time.Time, blockTimestamps map[uint64]time.Time) ([]*Tx, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from time %s is after to time %s", errInvalidRange, from, to)
+	}
+	var (
+		found                bool
+		fromHeight, toHeight uint64
+	)
+	for height, timestamp := range blockTimestamps {
+		if timestamp.Before(from) || timestamp.After(to) {
+			continue
+		}
+		if !found || height < fromHeight {
+			fromHeight = height
+		}
+		if !found || height > toHeight {
+			toHeight = height
+		}
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return a.GetByHeightRange(fromHeight, toHeight)
+}
+
 func (a *atomicTxRepository) getByHeightBytes(heightBytes []byte) ([]*Tx, error) {
 	txsBytes, err := a.acceptedAtomicTxByHeightDB.Get(heightBytes)
 	if err != nil {
@@ -305,20 +544,57 @@ func (a *atomicTxRepository) indexTxByID(heightBytes []byte, tx *Tx) error {
 	if err != nil {
 		return err
 	}
+	txID := tx.ID()
+	exists, err := a.acceptedAtomicTxDB.Has(txID[:])
+	if err != nil {
+		return err
+	}
 
 	// map txID => [height]+[tx bytes]
 	heightTxPacker := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen+wrappers.IntLen+len(txBytes))}
 	heightTxPacker.PackFixedBytes(heightBytes)
 	heightTxPacker.PackBytes(txBytes)
-	txID := tx.ID()
 
 	if err := a.acceptedAtomicTxDB.Put(txID[:], heightTxPacker.Bytes); err != nil {
 		return err
 	}
+	a.txCache.Evict(txID)
 
+	// Only count [tx] if it was not already present in the index.
+	if exists {
+		return nil
+	}
+	a.txCountLock.Lock()
+	a.txCount++
+	a.txCountLock.Unlock()
+	return a.incrementAddressCounts(tx)
+}
+
+// incrementAddressCounts increments the count stored in [byAddressDB] for
+// each EVM address of [tx].
+func (a *atomicTxRepository) incrementAddressCounts(tx *Tx) error {
+	for addr := range tx.EVMAddresses() {
+		count, err := a.GetCountByAddress(addr)
+		if err != nil {
+			return err
+		}
+		if err := database.PutUInt64(a.byAddressDB, addr[:], count+1); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// GetCountByAddress returns the number of accepted atomic txs which send
+// funds from or to [addr].
+func (a *atomicTxRepository) GetCountByAddress(addr common.Address) (uint64, error) {
+	count, err := database.GetUInt64(a.byAddressDB, addr[:])
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return count, err
+}
+
 // indexTxsAtHeight adds [height] -> [txs] to the [acceptedAtomicTxByHeightDB]
 func (a *atomicTxRepository) indexTxsAtHeight(heightBytes []byte, txs []*Tx) error {
 	txsBytes, err := a.codec.Marshal(codecVersion, txs)
@@ -362,6 +638,329 @@ func (a *atomicTxRepository) IterateByHeight(height uint64) database.Iterator {
 	return a.acceptedAtomicTxByHeightDB.NewIteratorWithStart(heightBytes)
 }
 
+// Len returns the total number of atomic txs indexed by txID.
+func (a *atomicTxRepository) Len() (uint64, error) {
+	a.txCountLock.RLock()
+	defer a.txCountLock.RUnlock()
+
+	return a.txCount, nil
+}
+
 func (a *atomicTxRepository) Codec() codec.Manager {
 	return a.codec
 }
+
+// Verify checks that every tx indexed by txID is present in the height entry
+// the txID index points to, that every tx in the height index is indexed by
+// txID, that no height entry contains the same tx more than once, and that no
+// height entry is above the height returned by [lastAcceptedHeight].
+// Returns an [*AtomicTxIndexError] summarizing any inconsistencies found.
+func (a *atomicTxRepository) Verify(lastAcceptedHeight func() uint64) error {
+	var (
+		report    AtomicTxIndexReport
+		startTime = time.Now()
+	)
+	// The txs at heights up to the index height have been completely written,
+	// since the index height is updated after both indexes.
+	indexHeight, err := a.GetIndexHeight()
+	if err != nil && err != database.ErrNotFound {
+		return err
+	}
+	if err := a.verifyTxIDIndex(indexHeight, &report); err != nil {
+		return err
+	}
+	if err := a.verifyHeightIndex(indexHeight, lastAcceptedHeight, &report); err != nil {
+		return err
+	}
+	log.Info("Verified atomic tx index",
+		"missing", report.Missing,
+		"extra", report.Extra,
+		"duplicate", report.Duplicate,
+		"aboveLastAccepted", report.AboveLastAccepted,
+		"duration", time.Since(startTime),
+	)
+	if report != (AtomicTxIndexReport{}) {
+		return &AtomicTxIndexError{Report: report}
+	}
+	return nil
+}
+
+// verifyTxIDIndex counts the txs in [acceptedAtomicTxDB] at or below
+// [indexHeight] which are missing from the height entry they are indexed at.
+func (a *atomicTxRepository) verifyTxIDIndex(indexHeight uint64, report *AtomicTxIndexReport) error {
+	iter := a.acceptedAtomicTxDB.NewIterator()
+	defer iter.Release()
+
+	var (
+		// Txs are not sorted by height in the txID index, so we only avoid
+		// re-parsing the height entry for consecutive txs at the same height.
+		lastHeightBytes []byte
+		lastHeightTxIDs set.Set[ids.ID]
+	)
+	for iter.Next() {
+		txID, err := ids.ToID(iter.Key())
+		if err != nil {
+			return fmt.Errorf("invalid txID in atomic tx index: %w", err)
+		}
+		value := iter.Value()
+		if len(value) < wrappers.LongLen {
+			return fmt.Errorf("acceptedAtomicTxDB entry too short for %s: %d", txID, len(value))
+		}
+		heightBytes := value[:wrappers.LongLen]
+		if binary.BigEndian.Uint64(heightBytes) > indexHeight {
+			continue
+		}
+		if !bytes.Equal(heightBytes, lastHeightBytes) {
+			txs, err := a.getByHeightBytes(heightBytes)
+			if err != nil && err != database.ErrNotFound {
+				return err
+			}
+			lastHeightBytes = slices.Clone(heightBytes)
+			lastHeightTxIDs = set.NewSet[ids.ID](len(txs))
+			for _, tx := range txs {
+				lastHeightTxIDs.Add(tx.ID())
+			}
+		}
+		if !lastHeightTxIDs.Contains(txID) {
+			log.Debug("atomic tx missing from height index", "txID", txID, "height", binary.BigEndian.Uint64(heightBytes))
+			report.Missing++
+		}
+	}
+	return iter.Error()
+}
+
+// verifyHeightIndex counts the txs in [acceptedAtomicTxByHeightDB] at or
+// below [indexHeight] which are not indexed by txID or are duplicated within a
+// height entry, and the height entries above the last accepted height.
+func (a *atomicTxRepository) verifyHeightIndex(indexHeight uint64, lastAcceptedHeight func() uint64, report *AtomicTxIndexReport) error {
+	iter := a.acceptedAtomicTxByHeightDB.NewIterator()
+	defer iter.Release()
+
+	acceptedHeight := lastAcceptedHeight()
+	for iter.Next() {
+		heightBytes := iter.Key()
+		if len(heightBytes) != wrappers.LongLen {
+			return fmt.Errorf("atomic tx height DB iterator key had invalid length (%d) != (%d)", len(heightBytes), wrappers.LongLen)
+		}
+		height := binary.BigEndian.Uint64(heightBytes)
+		if height > acceptedHeight {
+			// Blocks are accepted before their atomic txs are written, so
+			// the entry may belong to a block accepted since the last check.
+			acceptedHeight = lastAcceptedHeight()
+		}
+		if height > acceptedHeight {
+			log.Debug("atomic tx height entry above last accepted height", "height", height, "lastAcceptedHeight", acceptedHeight)
+			report.AboveLastAccepted++
+		}
+		if height > indexHeight {
+			continue
+		}
+		txs, err := ExtractAtomicTxsBatch(iter.Value(), a.codec)
+		if err != nil {
+			return err
+		}
+		txIDs := set.NewSet[ids.ID](len(txs))
+		for _, tx := range txs {
+			txID := tx.ID()
+			if txIDs.Contains(txID) {
+				log.Debug("duplicate atomic tx in height index", "txID", txID, "height", height)
+				report.Duplicate++
+				continue
+			}
+			txIDs.Add(txID)
+
+			indexed, err := a.acceptedAtomicTxDB.Has(txID[:])
+			if err != nil {
+				return err
+			}
+			if !indexed {
+				log.Debug("atomic tx in height index is not indexed by txID", "txID", txID, "height", height)
+				report.Extra++
+			}
+		}
+	}
+	return iter.Error()
+}
+
+// Export writes every atomic tx in the height index to [w] in order of
+// increasing height. Each tx is written as a record prefixed by its length:
+// [height]+[txID]+[indexed]+[tx bytes], where [indexed] is true if the txID
+// index maps [txID] to [height]. This distinguishes the canonical height of
+// txs which were also included in bonus blocks.
+func (a *atomicTxRepository) Export(w io.Writer) error {
+	iter := a.acceptedAtomicTxByHeightDB.NewIterator()
+	defer iter.Release()
+
+	exportedTxs := 0
+	for iter.Next() {
+		heightBytes := iter.Key()
+		if len(heightBytes) != wrappers.LongLen {
+			return fmt.Errorf("atomic tx height DB iterator key had invalid length (%d) != (%d)", len(heightBytes), wrappers.LongLen)
+		}
+		height := binary.BigEndian.Uint64(heightBytes)
+		txs, err := ExtractAtomicTxsBatch(iter.Value(), a.codec)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			txID := tx.ID()
+			_, indexedHeight, err := a.GetByTxID(txID)
+			if err != nil {
+				return fmt.Errorf("failed to get indexed height of atomic tx %s: %w", txID, err)
+			}
+			txBytes, err := a.codec.Marshal(codecVersion, tx)
+			if err != nil {
+				return err
+			}
+
+			// record consists of [record len]+[height]+[txID]+[indexed]+[tx bytes]
+			recordLen := wrappers.LongLen + common.HashLength + wrappers.BoolLen + wrappers.IntLen + len(txBytes)
+			p := wrappers.Packer{Bytes: make([]byte, wrappers.IntLen+recordLen)}
+			p.PackInt(uint32(recordLen))
+			p.PackLong(height)
+			p.PackFixedBytes(txID[:])
+			p.PackBool(indexedHeight == height)
+			p.PackBytes(txBytes)
+			if p.Err != nil {
+				return p.Err
+			}
+			if _, err := w.Write(p.Bytes); err != nil {
+				return err
+			}
+			exportedTxs++
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("atomic tx height DB iterator errored while exporting atomic txs: %w", err)
+	}
+	log.Info("Exported atomic transactions", "exportedTxs", exportedTxs)
+	return nil
+}
+
+// Import reads records written by Export from [r] and indexes them by txID
+// and height, verifying the txID of each record matches its tx bytes and its
+// height is at most [maxHeight]. The txs are written to a staging database
+// layered on the repository's database, so that the repository is unchanged
+// until the returned import is committed and is left unchanged if any record
+// is invalid.
+// Import must only be called on a repository which contains no atomic txs.
+func (a *atomicTxRepository) Import(r io.Reader, maxHeight uint64) (*AtomicTxImport, error) {
+	if count, err := a.Len(); err != nil {
+		return nil, err
+	} else if count != 0 {
+		return nil, fmt.Errorf("%w: found %d atomic txs", errRepositoryNotEmpty, count)
+	}
+
+	var (
+		staged         = newAtomicTxRepository(versiondb.New(a.db), a.codec, 0)
+		importedHeight uint64
+		importedTxs    int
+		recordLenBytes = make([]byte, wrappers.IntLen)
+	)
+	for {
+		if _, err := io.ReadFull(r, recordLenBytes); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read atomic tx record length: %w", err)
+		}
+		recordLen := binary.BigEndian.Uint32(recordLenBytes)
+		if recordLen > maxExportRecordSize {
+			return nil, fmt.Errorf("atomic tx record length (%d) exceeds maximum (%d)", recordLen, maxExportRecordSize)
+		}
+		record := make([]byte, recordLen)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return nil, fmt.Errorf("failed to read atomic tx record: %w", err)
+		}
+
+		p := wrappers.Packer{Bytes: record}
+		height := p.UnpackLong()
+		expectedTxID, err := ids.ToID(p.UnpackFixedBytes(common.HashLength))
+		if err != nil {
+			return nil, err
+		}
+		indexed := p.UnpackBool()
+		txBytes := p.UnpackBytes()
+		if p.Err != nil {
+			return nil, fmt.Errorf("failed to parse atomic tx record: %w", p.Err)
+		}
+		if height > maxHeight {
+			return nil, fmt.Errorf("atomic tx %s at height %d is above the maximum importable height %d", expectedTxID, height, maxHeight)
+		}
+		tx, err := ExtractAtomicTx(txBytes, a.codec)
+		if err != nil {
+			return nil, err
+		}
+		if txID := tx.ID(); txID != expectedTxID {
+			return nil, fmt.Errorf("atomic tx record has txID %s, expected %s", txID, expectedTxID)
+		}
+
+		heightBytes := make([]byte, wrappers.LongLen)
+		binary.BigEndian.PutUint64(heightBytes, height)
+		if indexed {
+			if err := staged.indexTxByID(heightBytes, tx); err != nil {
+				return nil, err
+			}
+		}
+		if err := staged.appendTxToHeightIndex(heightBytes, tx); err != nil {
+			return nil, err
+		}
+		importedHeight = max(importedHeight, height)
+		importedTxs++
+	}
+
+	// Never lower the index height, which may already be past the imported
+	// txs.
+	indexHeight, err := a.GetIndexHeight()
+	if err != nil && err != database.ErrNotFound {
+		return nil, err
+	}
+	if importedHeight > indexHeight {
+		heightBytes := make([]byte, wrappers.LongLen)
+		binary.BigEndian.PutUint64(heightBytes, importedHeight)
+		if err := staged.atomicRepoMetadataDB.Put(maxIndexedHeightKey, heightBytes); err != nil {
+			return nil, err
+		}
+	}
+	log.Info("Read atomic transactions to import", "importedTxs", importedTxs, "importedHeight", importedHeight)
+	return &AtomicTxImport{repo: a, staged: staged}, nil
+}
+
+// AtomicTxImport holds the atomic txs read by Import until they are committed
+// to the repository. The imported txs can be checked through Repository,
+// for instance against the atomic trie, before they are committed.
+type AtomicTxImport struct {
+	repo   *atomicTxRepository
+	staged *atomicTxRepository
+}
+
+// Repository returns a view of the repository with the imported txs applied.
+func (i *AtomicTxImport) Repository() AtomicTxRepository {
+	return i.staged
+}
+
+// Commit writes the imported txs to the repository and commits the
+// repository's database, so that the txs are written to disk in a single
+// batch. It fails if atomic txs were added to the repository since Import was
+// called.
+func (i *AtomicTxImport) Commit() error {
+	if count, err := i.repo.Len(); err != nil {
+		return err
+	} else if count != 0 {
+		return fmt.Errorf("%w: found %d atomic txs", errRepositoryNotEmpty, count)
+	}
+	if err := i.staged.db.Commit(); err != nil {
+		return err
+	}
+	if err := i.repo.db.Commit(); err != nil {
+		return err
+	}
+	count, err := i.staged.Len()
+	if err != nil {
+		return err
+	}
+	i.repo.txCountLock.Lock()
+	i.repo.txCount = count
+	i.repo.txCountLock.Unlock()
+	log.Info("Imported atomic transactions", "importedTxs", count)
+	return nil
+}
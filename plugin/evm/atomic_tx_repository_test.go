@@ -4,8 +4,11 @@
 package evm
 
 import (
+	"bytes"
 	"encoding/binary"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
@@ -183,7 +186,7 @@ func verifyOperations(t testing.TB, atomicTrie AtomicTrie, codec codec.Manager,
 func TestAtomicRepositoryReadWriteSingleTx(t *testing.T) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
-	repo, err := NewAtomicTxRepository(db, codec, 0)
+	repo, err := NewAtomicTxRepository(db, codec, 0, defaultAtomicTxCacheSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -196,7 +199,7 @@ func TestAtomicRepositoryReadWriteSingleTx(t *testing.T) {
 func TestAtomicRepositoryReadWriteMultipleTxs(t *testing.T) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
-	repo, err := NewAtomicTxRepository(db, codec, 0)
+	repo, err := NewAtomicTxRepository(db, codec, 0, defaultAtomicTxCacheSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -206,6 +209,80 @@ func TestAtomicRepositoryReadWriteMultipleTxs(t *testing.T) {
 	verifyTxs(t, repo, txMap)
 }
 
+func TestAtomicRepositoryGetByTimestampRange(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(db, codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txMap := make(map[uint64][]*Tx)
+	writeTxs(t, repo, 1, 6, constTxsPerHeight(2), txMap, nil)
+
+	// 5 blocks spanning a 60 second window.
+	start := time.Unix(1_700_000_000, 0)
+	blockTimestamps := make(map[uint64]time.Time)
+	for height := uint64(1); height <= 5; height++ {
+		blockTimestamps[height] = start.Add(time.Duration(height-1) * 15 * time.Second)
+	}
+
+	// Txs are returned in order of height, and of txID within a height.
+	expectedTxs := func(from, to uint64) []ids.ID {
+		var txIDs []ids.ID
+		for height := from; height <= to; height++ {
+			heightTxs := slices.Clone(txMap[height])
+			utils.Sort(heightTxs)
+			for _, tx := range heightTxs {
+				txIDs = append(txIDs, tx.ID())
+			}
+		}
+		return txIDs
+	}
+
+	tests := map[string]struct {
+		from, to    time.Time
+		expectedTxs []ids.ID
+	}{
+		"whole window": {
+			from:        start,
+			to:          start.Add(60 * time.Second),
+			expectedTxs: expectedTxs(1, 5),
+		},
+		"inner window": {
+			from:        start.Add(10 * time.Second),
+			to:          start.Add(50 * time.Second),
+			expectedTxs: expectedTxs(2, 4),
+		},
+		"single block": {
+			from:        start.Add(30 * time.Second),
+			to:          start.Add(30 * time.Second),
+			expectedTxs: expectedTxs(3, 3),
+		},
+		"before first block": {
+			from: start.Add(-time.Minute),
+			to:   start.Add(-time.Second),
+		},
+		"after last block": {
+			from: start.Add(61 * time.Second),
+			to:   start.Add(2 * time.Minute),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			txs, err := repo.GetByTimestampRange(test.from, test.to, blockTimestamps)
+			assert.NoError(t, err)
+			var txIDs []ids.ID
+			for _, tx := range txs {
+				txIDs = append(txIDs, tx.ID())
+			}
+			assert.Equal(t, test.expectedTxs, txIDs)
+		})
+	}
+
+	_, err = repo.GetByTimestampRange(start.Add(time.Second), start, blockTimestamps)
+	assert.ErrorIs(t, err, errInvalidRange)
+}
+
 func TestAtomicRepositoryPreAP5Migration(t *testing.T) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
@@ -219,7 +296,7 @@ func TestAtomicRepositoryPreAP5Migration(t *testing.T) {
 
 	// Ensure the atomic repository can correctly migrate the transactions
 	// from the old accepted atomic tx DB to add the height index.
-	repo, err := NewAtomicTxRepository(db, codec, 100)
+	repo, err := NewAtomicTxRepository(db, codec, 100, defaultAtomicTxCacheSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -245,7 +322,7 @@ func TestAtomicRepositoryPostAP5Migration(t *testing.T) {
 
 	// Ensure the atomic repository can correctly migrate the transactions
 	// from the old accepted atomic tx DB to add the height index.
-	repo, err := NewAtomicTxRepository(db, codec, 200)
+	repo, err := NewAtomicTxRepository(db, codec, 200, defaultAtomicTxCacheSize)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -256,6 +333,378 @@ func TestAtomicRepositoryPostAP5Migration(t *testing.T) {
 	verifyTxs(t, repo, txMap)
 }
 
+func TestAtomicRepositoryLen(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+
+	acceptedAtomicTxDB := prefixdb.New(atomicTxIDDBPrefix, db)
+	addTxs(t, codec, acceptedAtomicTxDB, 1, 50, 2, nil, nil)
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Len should include transactions indexed prior to initialization.
+	repo, err := NewAtomicTxRepository(db, codec, 50, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := repo.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(98), count)
+
+	writeTxs(t, repo, 50, 60, constTxsPerHeight(3), nil, nil)
+	count, err = repo.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(128), count)
+
+	// Re-writing an already indexed tx as a bonus block must not change the count.
+	txs, err := repo.GetByHeight(55)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.WriteBonus(60, txs))
+	count, err = repo.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(128), count)
+
+	// The count should be recovered after re-opening the repository.
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	repo, err = NewAtomicTxRepository(db, codec, 60, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err = repo.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(128), count)
+}
+
+func TestAtomicRepositoryVerify(t *testing.T) {
+	heightBytes := func(height uint64) []byte {
+		b := make([]byte, wrappers.LongLen)
+		binary.BigEndian.PutUint64(b, height)
+		return b
+	}
+	tests := map[string]struct {
+		lastAcceptedHeight uint64
+		corrupt            func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx)
+		expectedReport     AtomicTxIndexReport
+	}{
+		"consistent": {
+			lastAcceptedHeight: 10,
+			corrupt:            func(*testing.T, *atomicTxRepository, map[uint64][]*Tx) {},
+		},
+		"consistent with bonus block": {
+			lastAcceptedHeight: 10,
+			corrupt: func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx) {
+				assert.NoError(t, repo.WriteBonus(10, txMap[1]))
+			},
+		},
+		"tx missing from height index": {
+			lastAcceptedHeight: 10,
+			corrupt: func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx) {
+				assert.NoError(t, repo.indexTxsAtHeight(heightBytes(2), txMap[2][:1]))
+				assert.NoError(t, repo.acceptedAtomicTxByHeightDB.Delete(heightBytes(3)))
+			},
+			expectedReport: AtomicTxIndexReport{Missing: 3},
+		},
+		"tx missing from txID index": {
+			lastAcceptedHeight: 10,
+			corrupt: func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx) {
+				txID := txMap[4][0].ID()
+				assert.NoError(t, repo.acceptedAtomicTxDB.Delete(txID[:]))
+			},
+			expectedReport: AtomicTxIndexReport{Extra: 1},
+		},
+		"duplicate tx in height index": {
+			lastAcceptedHeight: 10,
+			corrupt: func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx) {
+				txs := append(txMap[5], txMap[5][0])
+				assert.NoError(t, repo.indexTxsAtHeight(heightBytes(5), txs))
+			},
+			expectedReport: AtomicTxIndexReport{Duplicate: 1},
+		},
+		"height entries above last accepted": {
+			lastAcceptedHeight: 7,
+			corrupt:            func(*testing.T, *atomicTxRepository, map[uint64][]*Tx) {},
+			expectedReport:     AtomicTxIndexReport{AboveLastAccepted: 2},
+		},
+		"multiple inconsistencies": {
+			lastAcceptedHeight: 8,
+			corrupt: func(t *testing.T, repo *atomicTxRepository, txMap map[uint64][]*Tx) {
+				assert.NoError(t, repo.indexTxsAtHeight(heightBytes(2), append(txMap[3], txMap[3][1])))
+			},
+			expectedReport: AtomicTxIndexReport{Missing: 2, Duplicate: 1, AboveLastAccepted: 1},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := versiondb.New(memdb.New())
+			repo, err := NewAtomicTxRepository(db, testTxCodec(), 0, defaultAtomicTxCacheSize)
+			assert.NoError(t, err)
+
+			txMap := make(map[uint64][]*Tx)
+			writeTxs(t, repo, 1, 10, constTxsPerHeight(2), txMap, nil)
+			test.corrupt(t, repo, txMap)
+
+			err = repo.Verify(func() uint64 { return test.lastAcceptedHeight })
+			if test.expectedReport == (AtomicTxIndexReport{}) {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, errInconsistentIndex)
+			var indexErr *AtomicTxIndexError
+			if assert.ErrorAs(t, err, &indexErr) {
+				assert.Equal(t, test.expectedReport, indexErr.Report)
+			}
+		})
+	}
+}
+
+func TestAtomicRepositoryVerifyConcurrentAccept(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	repo, err := NewAtomicTxRepository(db, testTxCodec(), 0, defaultAtomicTxCacheSize)
+	assert.NoError(t, err)
+	writeTxs(t, repo, 1, 10, constTxsPerHeight(2), nil, nil)
+
+	// A tx indexed by txID above the index height, as though its block were
+	// being written while Verify runs, is not reported as missing.
+	heightBytes := make([]byte, wrappers.LongLen)
+	binary.BigEndian.PutUint64(heightBytes, 10)
+	assert.NoError(t, repo.indexTxByID(heightBytes, newTestTx()))
+
+	// Height entries above the last accepted height when Verify is called
+	// are not reported if their block is accepted before they are checked.
+	lastAcceptedHeight := uint64(7)
+	assert.NoError(t, repo.Verify(func() uint64 {
+		height := lastAcceptedHeight
+		lastAcceptedHeight = 10
+		return height
+	}))
+}
+
+func TestAtomicRepositoryCacheInvalidation(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(db, codec, 0, defaultAtomicTxCacheSize)
+	assert.NoError(t, err)
+
+	tx := newTestTx()
+	assert.NoError(t, repo.Write(1, []*Tx{tx}))
+
+	// Populate the cache.
+	_, height, err := repo.GetByTxID(tx.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+	_, height, err = repo.GetByTxID(tx.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), height)
+
+	// Re-writing the tx at a new height must not return the cached height.
+	assert.NoError(t, repo.Write(2, []*Tx{tx}))
+	fetchedTx, height, err := repo.GetByTxID(tx.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+	assert.Equal(t, tx.ID(), fetchedTx.ID())
+
+	// Bonus writes do not overwrite the existing height.
+	assert.NoError(t, repo.WriteBonus(3, []*Tx{tx}))
+	_, height, err = repo.GetByTxID(tx.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), height)
+}
+
+func TestAtomicRepositoryGetCountByAddress(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(db, codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := common.Address{1}
+	otherAddr := common.Address{2}
+	for height := uint64(1); height <= 10; height++ {
+		tx := newTestTx()
+		tx.UnsignedAtomicTx.(*TestUnsignedTx).EVMAddressesV = []common.Address{addr}
+		assert.NoError(t, repo.Write(height, []*Tx{tx}))
+	}
+	writeTxs(t, repo, 11, 20, constTxsPerHeight(2), nil, nil)
+
+	count, err := repo.GetCountByAddress(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), count)
+
+	count, err = repo.GetCountByAddress(otherAddr)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Re-writing an already indexed tx as a bonus block must not change the count.
+	txs, err := repo.GetByHeight(5)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.WriteBonus(20, txs))
+	count, err = repo.GetCountByAddress(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), count)
+}
+
+func TestAtomicRepositoryAddressIndexMigration(t *testing.T) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+
+	// Write txs directly to [acceptedAtomicTxDB] as was done prior to the
+	// address index.
+	addr := common.Address{1}
+	acceptedAtomicTxDB := prefixdb.New(atomicTxIDDBPrefix, db)
+	for height := uint64(1); height < 10; height++ {
+		tx := newTestTx()
+		tx.UnsignedAtomicTx.(*TestUnsignedTx).EVMAddressesV = []common.Address{addr}
+		txBytes, err := codec.Marshal(codecVersion, tx)
+		assert.NoError(t, err)
+		packer := wrappers.Packer{Bytes: make([]byte, 1), MaxSize: 1024 * 1024}
+		packer.PackLong(height)
+		packer.PackBytes(txBytes)
+		txID := tx.ID()
+		assert.NoError(t, acceptedAtomicTxDB.Put(txID[:], packer.Bytes))
+	}
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewAtomicTxRepository(db, codec, 10, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := repo.GetCountByAddress(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), count)
+
+	// Re-opening the repository must not count the txs again.
+	repo, err = NewAtomicTxRepository(db, codec, 10, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err = repo.GetCountByAddress(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), count)
+}
+
+func TestAtomicRepositoryExportImport(t *testing.T) {
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txMap := make(map[uint64][]*Tx)
+	writeTxs(t, repo, 1, 500, constTxsPerHeight(5), txMap, nil)
+
+	// Include txs at a bonus height, which must not change the height the
+	// txs are indexed at by txID.
+	bonusTxs := txMap[100]
+	assert.NoError(t, repo.WriteBonus(500, bonusTxs))
+	txMap[500] = bonusTxs
+
+	var buf bytes.Buffer
+	assert.NoError(t, repo.Export(&buf))
+
+	atomicBackend, err := NewAtomicBackend(versiondb.New(memdb.New()), testSharedMemory(), nil, repo, 500, common.Hash{}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, height := atomicBackend.AtomicTrie().LastCommitted()
+	assert.Equal(t, uint64(500), height)
+
+	importedRepo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := importedRepo.Import(bytes.NewReader(buf.Bytes()), height)
+	assert.NoError(t, err)
+	assert.NoError(t, verifyAtomicTrieRoot(imported.Repository(), codec, height, root))
+	assert.Error(t, verifyAtomicTrieRoot(imported.Repository(), codec, height, common.Hash{}))
+
+	// The repository must not be modified until the import is committed.
+	count, err := importedRepo.Len()
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	_, err = importedRepo.GetIndexHeight()
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	assert.NoError(t, imported.Commit())
+	verifyTxs(t, importedRepo, txMap)
+
+	indexHeight, err := importedRepo.GetIndexHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), indexHeight)
+
+	expectedLen, err := repo.Len()
+	assert.NoError(t, err)
+	importedLen, err := importedRepo.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLen, importedLen)
+
+	for height, txs := range txMap {
+		if height == 500 {
+			continue
+		}
+		for _, tx := range txs {
+			_, txHeight, err := importedRepo.GetByTxID(tx.ID())
+			assert.NoError(t, err)
+			assert.Equal(t, height, txHeight)
+		}
+	}
+
+	// Importing into a non-empty repository should fail.
+	_, err = importedRepo.Import(bytes.NewReader(buf.Bytes()), height)
+	assert.ErrorIs(t, err, errRepositoryNotEmpty)
+}
+
+func TestAtomicRepositoryImportAboveMaxHeight(t *testing.T) {
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTxs(t, repo, 1, 10, constTxsPerHeight(1), nil, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, repo.Export(&buf))
+
+	importedRepo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = importedRepo.Import(bytes.NewReader(buf.Bytes()), 8)
+	assert.Error(t, err)
+
+	count, err := importedRepo.Len()
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestAtomicRepositoryImportInvalidTxID(t *testing.T) {
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTxs(t, repo, 1, 2, constTxsPerHeight(1), nil, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, repo.Export(&buf))
+
+	// Corrupt the txID of the first record, which follows the record length
+	// and the height.
+	exported := buf.Bytes()
+	exported[wrappers.IntLen+wrappers.LongLen]++
+
+	importedRepo, err := NewAtomicTxRepository(versiondb.New(memdb.New()), codec, 0, defaultAtomicTxCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = importedRepo.Import(bytes.NewReader(exported), 2)
+	assert.Error(t, err)
+}
+
 func benchAtomicRepositoryIndex10_000(b *testing.B, maxHeight uint64, txsPerHeight int) {
 	db := versiondb.New(memdb.New())
 	codec := testTxCodec()
@@ -267,7 +716,7 @@ func benchAtomicRepositoryIndex10_000(b *testing.B, maxHeight uint64, txsPerHeig
 	if err := db.Commit(); err != nil {
 		b.Fatal(err)
 	}
-	repo, err := NewAtomicTxRepository(db, codec, maxHeight)
+	repo, err := NewAtomicTxRepository(db, codec, maxHeight, defaultAtomicTxCacheSize)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -286,3 +735,33 @@ func BenchmarkAtomicRepositoryIndex_10kBlocks_10Tx(b *testing.B) {
 		benchAtomicRepositoryIndex10_000(b, 10_000, 10)
 	}
 }
+
+func benchAtomicRepositoryGetByTxID(b *testing.B, cacheSize int) {
+	db := versiondb.New(memdb.New())
+	codec := testTxCodec()
+	repo, err := NewAtomicTxRepository(db, codec, 0, cacheSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	txMap := make(map[uint64][]*Tx)
+	writeTxs(b, repo, 0, 100, constTxsPerHeight(1), txMap, nil)
+	txIDs := make([]ids.ID, 0, len(txMap))
+	for _, txs := range txMap {
+		txIDs = append(txIDs, txs[0].ID())
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, _, err := repo.GetByTxID(txIDs[n%len(txIDs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAtomicRepositoryGetByTxID_NoCache(b *testing.B) {
+	benchAtomicRepositoryGetByTxID(b, 1)
+}
+
+func BenchmarkAtomicRepositoryGetByTxID_Cache(b *testing.B) {
+	benchAtomicRepositoryGetByTxID(b, defaultAtomicTxCacheSize)
+}
@@ -159,11 +159,16 @@ func (b *Block) Accept(context.Context) error {
 	if err := vm.blockChain.Accept(b.ethBlock); err != nil {
 		return fmt.Errorf("chain could not accept %s: %w", b.ID(), err)
 	}
+	vm.processingTxs.remove(b.ethBlock.Hash())
 
 	if err := vm.acceptedBlockDB.Put(lastAcceptedKey, b.id[:]); err != nil {
 		return fmt.Errorf("failed to put %s as the last accepted block: %w", b.ID(), err)
 	}
 
+	if vm.gossipFanout != nil {
+		vm.gossipFanout.ObserveBlock(time.Unix(int64(b.ethBlock.Time()), 0))
+	}
+
 	for _, tx := range b.atomicTxs {
 		// Remove the accepted transaction from the mempool
 		vm.mempool.RemoveTx(tx)
@@ -230,6 +235,7 @@ func (b *Block) handlePrecompileAccept(rules params.Rules, sharedMemoryWriter *s
 // If [b] contains an atomic transaction, attempt to re-issue it
 func (b *Block) Reject(context.Context) error {
 	log.Debug(fmt.Sprintf("Rejecting block %s (%s) at height %d", b.ID().Hex(), b.ID(), b.Height()))
+	b.vm.processingTxs.remove(b.ethBlock.Hash())
 	for _, tx := range b.atomicTxs {
 		b.vm.mempool.RemoveTx(tx)
 		if err := b.vm.mempool.AddTx(tx); err != nil {
@@ -358,6 +364,12 @@ func (b *Block) verify(predicateContext *precompileconfig.PredicateContext, writ
 			_ = atomicState.Reject() // ignore this error so we can return the original error instead.
 		}
 	}
+	if err == nil && writes {
+		b.vm.processingTxs.add(b.ethBlock, b.atomicTxs)
+		if predicateContext.ProposerVMBlockCtx != nil {
+			b.vm.proposerPChainHeights.Put(b.ethBlock.Hash(), predicateContext.ProposerVMBlockCtx.PChainHeight)
+		}
+	}
 	return err
 }
 
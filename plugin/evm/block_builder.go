@@ -4,12 +4,14 @@
 package evm
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/utils/timer"
 	"github.com/ava-labs/coreth/core"
 	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ava-labs/coreth/metrics"
 	"github.com/ava-labs/coreth/params"
 
 	"github.com/ava-labs/avalanchego/snow"
@@ -30,8 +32,7 @@ type blockBuilder struct {
 	txPool  *txpool.TxPool
 	mempool *Mempool
 
-	shutdownChan <-chan struct{}
-	shutdownWg   *sync.WaitGroup
+	workers *workerRegistry
 
 	// A message is sent on this channel when a new block
 	// is ready to be build. This notifies the consensus engine.
@@ -49,6 +50,17 @@ type blockBuilder struct {
 	// If the mempool receives a new transaction, the block builder will send a new notification to
 	// the engine and cancel the timer.
 	buildBlockTimer *timer.Timer
+
+	// atomicTxBuildThreshold is how long atomic transactions may be pending before
+	// the engine is notified again, regardless of [buildSent]. 0 disables this.
+	atomicTxBuildThreshold time.Duration
+	// atomicPendingSince is the time at which the mempool was first observed to
+	// have pending atomic transactions, or zero if it has none.
+	// [buildBlockLock] must be held when accessing [atomicPendingSince].
+	atomicPendingSince time.Time
+
+	atomicPendingAgeGauge   metrics.Gauge
+	atomicUrgentBuildsCount metrics.Counter
 }
 
 func (vm *VM) NewBlockBuilder(notifyBuildBlockChan chan<- commonEng.Message) *blockBuilder {
@@ -57,9 +69,12 @@ func (vm *VM) NewBlockBuilder(notifyBuildBlockChan chan<- commonEng.Message) *bl
 		chainConfig:          vm.chainConfig,
 		txPool:               vm.txPool,
 		mempool:              vm.mempool,
-		shutdownChan:         vm.shutdownChan,
-		shutdownWg:           &vm.shutdownWg,
+		workers:              vm.workers,
 		notifyBuildBlockChan: notifyBuildBlockChan,
+
+		atomicTxBuildThreshold:  vm.config.AtomicTxBuildThreshold.Duration,
+		atomicPendingAgeGauge:   metrics.GetOrRegisterGauge("block_builder_atomic_pending_age_ms", nil),
+		atomicUrgentBuildsCount: metrics.GetOrRegisterCounter("block_builder_atomic_urgent_builds", nil),
 	}
 	b.handleBlockBuilding()
 	return b
@@ -69,7 +84,10 @@ func (vm *VM) NewBlockBuilder(notifyBuildBlockChan chan<- commonEng.Message) *bl
 // of the mempool has not been changed since the last attempt.
 func (b *blockBuilder) handleBlockBuilding() {
 	b.buildBlockTimer = timer.NewTimer(b.buildBlockTimerCallback)
-	go b.ctx.Log.RecoverAndPanic(b.buildBlockTimer.Dispatch)
+	// The timer is stopped by the worker started in [awaitSubmittedTxs].
+	b.workers.Go("block_build_timer", func(context.Context) {
+		b.ctx.Log.RecoverAndPanic(b.buildBlockTimer.Dispatch)
+	})
 }
 
 // buildBlockTimerCallback is the timer callback that will send a PendingTxs notification
@@ -92,6 +110,8 @@ func (b *blockBuilder) handleGenerateBlock() {
 
 	// Reset buildSent now that the engine has called BuildBlock.
 	b.buildSent = false
+	// Measure atomic tx urgency from this build attempt onwards.
+	b.atomicPendingSince = time.Time{}
 
 	// Set a timer to check if calling build block a second time is needed.
 	b.buildBlockTimer.SetTimeoutIn(minBlockBuildingRetryDelay)
@@ -137,6 +157,52 @@ func (b *blockBuilder) signalTxsReady() {
 	b.markBuilding()
 }
 
+// signalAtomicTxsPending records the time at which atomic transactions were
+// first observed to be pending and sends a PendingTxs notification.
+func (b *blockBuilder) signalAtomicTxsPending() {
+	b.buildBlockLock.Lock()
+	defer b.buildBlockLock.Unlock()
+
+	if b.atomicPendingSince.IsZero() {
+		b.atomicPendingSince = time.Now()
+	}
+	b.markBuilding()
+}
+
+// checkAtomicTxUrgency notifies the engine to build a block if atomic
+// transactions have been pending for at least [atomicTxBuildThreshold] as of
+// [now], even if a PendingTxs message was already sent. The block is still
+// built and verified as usual, so the block gas cost rules apply.
+func (b *blockBuilder) checkAtomicTxUrgency(now time.Time) {
+	b.buildBlockLock.Lock()
+	defer b.buildBlockLock.Unlock()
+
+	// Txs issued into a processing block are waiting on consensus, not on
+	// block building, so only txs which have not been issued are counted.
+	if b.mempool.PendingLen() == 0 {
+		b.atomicPendingSince = time.Time{}
+		b.atomicPendingAgeGauge.Update(0)
+		return
+	}
+	if b.atomicPendingSince.IsZero() {
+		b.atomicPendingSince = now
+	}
+
+	age := now.Sub(b.atomicPendingSince)
+	b.atomicPendingAgeGauge.Update(age.Milliseconds())
+	if age < b.atomicTxBuildThreshold {
+		return
+	}
+
+	log.Debug("Atomic txs pending past threshold, trying to generate a block", "age", age)
+	b.atomicUrgentBuildsCount.Inc(1)
+	// Restart the age so the engine is not notified again until another
+	// threshold has passed.
+	b.atomicPendingSince = now
+	b.buildSent = false
+	b.markBuilding()
+}
+
 // awaitSubmittedTxs waits for new transactions to be submitted
 // and notifies the VM when the tx pool has transactions to be
 // put into a new block.
@@ -146,22 +212,32 @@ func (b *blockBuilder) awaitSubmittedTxs() {
 	txSubmitChan := make(chan core.NewTxsEvent)
 	b.txPool.SubscribeTransactions(txSubmitChan, true)
 
-	b.shutdownWg.Add(1)
-	go b.ctx.Log.RecoverAndPanic(func() {
-		defer b.shutdownWg.Done()
-
-		for {
-			select {
-			case <-txSubmitChan:
-				log.Trace("New tx detected, trying to generate a block")
-				b.signalTxsReady()
-			case <-b.mempool.Pending:
-				log.Trace("New atomic Tx detected, trying to generate a block")
-				b.signalTxsReady()
-			case <-b.shutdownChan:
-				b.buildBlockTimer.Stop()
-				return
+	b.workers.Go("await_submitted_txs", func(ctx context.Context) {
+		b.ctx.Log.RecoverAndPanic(func() {
+			// atomicUrgencyChan is left nil if the atomic tx build threshold is
+			// disabled, so it is never selected.
+			var atomicUrgencyChan <-chan time.Time
+			if b.atomicTxBuildThreshold > 0 {
+				ticker := time.NewTicker(b.atomicTxBuildThreshold / 2)
+				defer ticker.Stop()
+				atomicUrgencyChan = ticker.C
+			}
+
+			for {
+				select {
+				case <-txSubmitChan:
+					log.Trace("New tx detected, trying to generate a block")
+					b.signalTxsReady()
+				case <-b.mempool.Pending:
+					log.Trace("New atomic Tx detected, trying to generate a block")
+					b.signalAtomicTxsPending()
+				case now := <-atomicUrgencyChan:
+					b.checkAtomicTxUrgency(now)
+				case <-ctx.Done():
+					b.buildBlockTimer.Stop()
+					return
+				}
 			}
-		}
+		})
 	})
 }
@@ -0,0 +1,88 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockBuilderAtomicTxBuildThreshold(t *testing.T) {
+	tests := map[string]struct {
+		configJSON      string
+		expectReminder  bool
+		reminderTimeout time.Duration
+	}{
+		"threshold enabled": {
+			configJSON:      `{"atomic-tx-build-threshold": "100ms"}`,
+			expectReminder:  true,
+			reminderTimeout: 2 * time.Second,
+		},
+		"threshold disabled": {
+			configJSON:      `{"atomic-tx-build-threshold": "0s"}`,
+			expectReminder:  false,
+			reminderTimeout: 500 * time.Millisecond,
+		},
+		"threshold default": {
+			configJSON:      "",
+			expectReminder:  false,
+			reminderTimeout: 500 * time.Millisecond,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			importAmount := uint64(50000000)
+			issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, test.configJSON, "", map[ids.ShortID]uint64{
+				testShortIDAddrs[0]: importAmount,
+			})
+			defer func() {
+				require.NoError(vm.Shutdown(context.Background()))
+			}()
+
+			urgentBuilds := vm.builder.atomicUrgentBuildsCount.Snapshot().Count()
+
+			importTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[0], initialBaseFee, []*secp256k1.PrivateKey{testKeys[0]})
+			require.NoError(err)
+			require.NoError(vm.mempool.AddLocalTx(importTx))
+
+			// Drop the first notification without building, as if the engine
+			// had declined to build a block.
+			require.Equal(commonEng.PendingTxs, <-issuer)
+
+			select {
+			case msg := <-issuer:
+				require.True(test.expectReminder, "unexpected notification")
+				require.Equal(commonEng.PendingTxs, msg)
+			case <-time.After(test.reminderTimeout):
+				require.False(test.expectReminder, "timed out waiting for notification")
+				return
+			}
+			require.Greater(vm.builder.atomicUrgentBuildsCount.Snapshot().Count(), urgentBuilds)
+
+			blk, err := vm.BuildBlock(context.Background())
+			require.NoError(err)
+			require.NoError(blk.Verify(context.Background()))
+
+			evmBlk, ok := blk.(*chain.BlockWrapper).Block.(*Block)
+			require.True(ok)
+			require.Len(evmBlk.atomicTxs, 1)
+			require.Equal(importTx.ID(), evmBlk.atomicTxs[0].ID())
+
+			// The tx is no longer pending once it is issued into a block, so
+			// the engine is not notified again while the block is processing.
+			urgentBuilds = vm.builder.atomicUrgentBuildsCount.Snapshot().Count()
+			vm.builder.checkAtomicTxUrgency(time.Now().Add(time.Hour))
+			require.Equal(urgentBuilds, vm.builder.atomicUrgentBuildsCount.Snapshot().Count())
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// chainIdentityKey is the key in [metadataDB] which stores the identity of
+// the chain the database was created for.
+var chainIdentityKey = []byte("chain_identity")
+
+var errChainIdentityMismatch = errors.New("database was created for a different chain")
+
+// chainIdentity identifies the chain that a database belongs to.
+type chainIdentity struct {
+	NetworkID   uint32
+	ChainID     ids.ID
+	EVMChainID  *big.Int
+	GenesisHash common.Hash
+}
+
+func (c *chainIdentity) Bytes() ([]byte, error) {
+	evmChainIDBytes := c.EVMChainID.Bytes()
+	p := wrappers.Packer{
+		Bytes: make([]byte, wrappers.IntLen+ids.IDLen+common.HashLength+wrappers.IntLen+len(evmChainIDBytes)),
+	}
+	p.PackInt(c.NetworkID)
+	p.PackFixedBytes(c.ChainID[:])
+	p.PackFixedBytes(c.GenesisHash[:])
+	p.PackBytes(evmChainIDBytes)
+	return p.Bytes, p.Err
+}
+
+func parseChainIdentity(b []byte) (*chainIdentity, error) {
+	p := wrappers.Packer{Bytes: b}
+	c := &chainIdentity{
+		NetworkID: p.UnpackInt(),
+	}
+	copy(c.ChainID[:], p.UnpackFixedBytes(ids.IDLen))
+	copy(c.GenesisHash[:], p.UnpackFixedBytes(common.HashLength))
+	c.EVMChainID = new(big.Int).SetBytes(p.UnpackBytes())
+	if p.Err != nil {
+		return nil, fmt.Errorf("failed to parse chain identity: %w", p.Err)
+	}
+	if p.Offset != len(b) {
+		return nil, fmt.Errorf("failed to parse chain identity: %d unexpected trailing bytes", len(b)-p.Offset)
+	}
+	return c, nil
+}
+
+// verifyChainIdentity compares [expected] against the chain identity stored
+// in [db]. If no identity is stored, [expected] is written to [db].
+// If [skipCheck] is true, a mismatch is logged and the stored identity is
+// replaced by [expected] rather than returning an error.
+// Note: the caller is responsible for committing any changes to [db].
+func verifyChainIdentity(db database.KeyValueReaderWriter, expected *chainIdentity, skipCheck bool) error {
+	storedBytes, err := db.Get(chainIdentityKey)
+	switch {
+	case err == database.ErrNotFound:
+		log.Info("Persisting chain identity", "networkID", expected.NetworkID, "chainID", expected.ChainID, "evmChainID", expected.EVMChainID, "genesisHash", expected.GenesisHash)
+		return writeChainIdentity(db, expected)
+	case err != nil:
+		return fmt.Errorf("failed to read chain identity: %w", err)
+	}
+
+	stored, err := parseChainIdentity(storedBytes)
+	if err != nil {
+		return err
+	}
+	mismatchErr := stored.compare(expected)
+	if mismatchErr == nil {
+		return nil
+	}
+	if !skipCheck {
+		return fmt.Errorf("%w (set skip-chain-identity-check to override)", mismatchErr)
+	}
+	log.Warn("Overriding stored chain identity", "err", mismatchErr)
+	return writeChainIdentity(db, expected)
+}
+
+// compare returns an error naming the first field of [c] that does not match
+// [expected], or nil if all fields match.
+func (c *chainIdentity) compare(expected *chainIdentity) error {
+	switch {
+	case c.NetworkID != expected.NetworkID:
+		return fmt.Errorf("%w: stored networkID %d, configured networkID %d", errChainIdentityMismatch, c.NetworkID, expected.NetworkID)
+	case c.ChainID != expected.ChainID:
+		return fmt.Errorf("%w: stored chainID %s, configured chainID %s", errChainIdentityMismatch, c.ChainID, expected.ChainID)
+	case c.EVMChainID.Cmp(expected.EVMChainID) != 0:
+		return fmt.Errorf("%w: stored EVM chainID %d, configured EVM chainID %d", errChainIdentityMismatch, c.EVMChainID, expected.EVMChainID)
+	case c.GenesisHash != expected.GenesisHash:
+		return fmt.Errorf("%w: stored genesis hash %s, configured genesis hash %s", errChainIdentityMismatch, c.GenesisHash, expected.GenesisHash)
+	}
+	return nil
+}
+
+func writeChainIdentity(db database.KeyValueWriter, c *chainIdentity) error {
+	b, err := c.Bytes()
+	if err != nil {
+		return err
+	}
+	return db.Put(chainIdentityKey, b)
+}
@@ -0,0 +1,143 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func testChainIdentity() *chainIdentity {
+	return &chainIdentity{
+		NetworkID:   testNetworkID,
+		ChainID:     testCChainID,
+		EVMChainID:  big.NewInt(43112),
+		GenesisHash: common.Hash{1},
+	}
+}
+
+func TestChainIdentityRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	expected := testChainIdentity()
+	b, err := expected.Bytes()
+	require.NoError(err)
+
+	parsed, err := parseChainIdentity(b)
+	require.NoError(err)
+	require.Equal(expected, parsed)
+
+	_, err = parseChainIdentity(append(b, 0x00))
+	require.Error(err)
+	_, err = parseChainIdentity(b[:len(b)-1])
+	require.Error(err)
+}
+
+func TestVerifyChainIdentity(t *testing.T) {
+	tests := map[string]struct {
+		modify      func(*chainIdentity)
+		skipCheck   bool
+		expectedErr error
+	}{
+		"matching restart": {
+			modify: func(*chainIdentity) {},
+		},
+		"network ID mismatch": {
+			modify:      func(c *chainIdentity) { c.NetworkID++ },
+			expectedErr: errChainIdentityMismatch,
+		},
+		"chain ID mismatch": {
+			modify:      func(c *chainIdentity) { c.ChainID = ids.GenerateTestID() },
+			expectedErr: errChainIdentityMismatch,
+		},
+		"EVM chain ID mismatch": {
+			modify:      func(c *chainIdentity) { c.EVMChainID = big.NewInt(1) },
+			expectedErr: errChainIdentityMismatch,
+		},
+		"genesis hash mismatch": {
+			modify:      func(c *chainIdentity) { c.GenesisHash = common.Hash{2} },
+			expectedErr: errChainIdentityMismatch,
+		},
+		"mismatch with skip check": {
+			modify:    func(c *chainIdentity) { c.GenesisHash = common.Hash{2} },
+			skipCheck: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			db := memdb.New()
+
+			// The identity should be persisted on first run.
+			stored := testChainIdentity()
+			require.NoError(verifyChainIdentity(db, stored, false))
+			b, err := db.Get(chainIdentityKey)
+			require.NoError(err)
+			parsed, err := parseChainIdentity(b)
+			require.NoError(err)
+			require.Equal(stored, parsed)
+
+			configured := testChainIdentity()
+			test.modify(configured)
+			err = verifyChainIdentity(db, configured, test.skipCheck)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+
+			// On success the configured identity must be the stored identity.
+			b, err = db.Get(chainIdentityKey)
+			require.NoError(err)
+			parsed, err = parseChainIdentity(b)
+			require.NoError(err)
+			require.Equal(configured, parsed)
+		})
+	}
+}
+
+func TestVMChainIdentityMismatch(t *testing.T) {
+	require := require.New(t)
+
+	issuer, vm, dbManager, _, _ := GenesisVM(t, false, genesisJSONLatest, "", "")
+	require.NoError(vm.Shutdown(context.Background()))
+
+	// Restarting with the same chain succeeds.
+	restartedVM := &VM{}
+	require.NoError(restartedVM.Initialize(
+		context.Background(),
+		NewContext(),
+		dbManager,
+		[]byte(genesisJSONLatest),
+		[]byte(""),
+		[]byte(""),
+		issuer,
+		[]*commonEng.Fx{},
+		nil,
+	))
+	require.NoError(restartedVM.Shutdown(context.Background()))
+
+	// Restarting with a snow context for a different chain fails.
+	ctx := NewContext()
+	ctx.ChainID = ids.GenerateTestID()
+	mismatchedVM := &VM{}
+	err := mismatchedVM.Initialize(
+		context.Background(),
+		ctx,
+		dbManager,
+		[]byte(genesisJSONLatest),
+		[]byte(""),
+		[]byte(""),
+		issuer,
+		[]*commonEng.Fx{},
+		nil,
+	)
+	require.ErrorIs(err, errChainIdentityMismatch)
+}
@@ -5,9 +5,14 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
 	"golang.org/x/exp/slog"
 
 	"github.com/ava-labs/avalanchego/api"
@@ -22,17 +27,33 @@ import (
 // Interface compliance
 var _ Client = (*client)(nil)
 
+const (
+	// maxAtomicFeeEstimates is the maximum number of times the fee of an
+	// atomic transaction is estimated while waiting for its gas usage to
+	// settle.
+	maxAtomicFeeEstimates = 4
+	// maxAtomicUTXOsRestarts is the maximum number of times the pages of
+	// atomic UTXOs are fetched again after the UTXOs changed in between.
+	maxAtomicUTXOsRestarts = 4
+)
+
+var errAtomicFeeNotConverged = errors.New("atomic tx fee estimate did not converge")
+
 // Client interface for interacting with EVM [chain]
 type Client interface {
 	IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Option) (ids.ID, error)
 	GetAtomicTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (Status, error)
 	GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Option) ([]byte, error)
 	GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error)
+	GetAllAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, options ...rpc.Option) ([][]byte, error)
 	ExportKey(ctx context.Context, userPass api.UserPass, addr common.Address, options ...rpc.Option) (*secp256k1.PrivateKey, string, error)
 	ImportKey(ctx context.Context, userPass api.UserPass, privateKey *secp256k1.PrivateKey, options ...rpc.Option) (common.Address, error)
 	Import(ctx context.Context, userPass api.UserPass, to common.Address, sourceChain string, options ...rpc.Option) (ids.ID, error)
 	ExportAVAX(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, options ...rpc.Option) (ids.ID, error)
 	Export(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, assetID string, options ...rpc.Option) (ids.ID, error)
+	EstimateBaseFee(ctx context.Context, options ...rpc.Option) (*big.Int, error)
+	ImportWithFee(ctx context.Context, userPass api.UserPass, to common.Address, sourceChain string, marginPercent uint64, options ...rpc.Option) (*AtomicTxFeeResult, error)
+	ExportWithFee(ctx context.Context, userPass api.UserPass, amount uint64, to ids.ShortID, targetChain string, assetID string, marginPercent uint64, options ...rpc.Option) (*AtomicTxFeeResult, error)
 	StartCPUProfiler(ctx context.Context, options ...rpc.Option) error
 	StopCPUProfiler(ctx context.Context, options ...rpc.Option) error
 	MemoryProfile(ctx context.Context, options ...rpc.Option) error
@@ -100,28 +121,17 @@ func (c *client) GetAtomicTx(ctx context.Context, txID ids.ID, options ...rpc.Op
 // GetAtomicUTXOs returns the byte representation of the atomic UTXOs controlled by [addresses]
 // from [sourceChain]
 func (c *client) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startAddress ids.ShortID, startUTXOID ids.ID, options ...rpc.Option) ([][]byte, ids.ShortID, ids.ID, error) {
-	res := &api.GetUTXOsReply{}
-	err := c.requester.SendRequest(ctx, "avax.getUTXOs", &api.GetUTXOsArgs{
-		Addresses:   ids.ShortIDsToStrings(addrs),
-		SourceChain: sourceChain,
-		Limit:       json.Uint32(limit),
-		StartIndex: api.Index{
-			Address: startAddress.String(),
-			UTXO:    startUTXOID.String(),
-		},
-		Encoding: formatting.Hex,
-	}, res, options...)
+	res, err := c.getAtomicUTXOs(ctx, addrs, sourceChain, limit, api.Index{
+		Address: startAddress.String(),
+		UTXO:    startUTXOID.String(),
+	}, "", options...)
 	if err != nil {
 		return nil, ids.ShortID{}, ids.Empty, err
 	}
 
-	utxos := make([][]byte, len(res.UTXOs))
-	for i, utxo := range res.UTXOs {
-		utxoBytes, err := formatting.Decode(res.Encoding, utxo)
-		if err != nil {
-			return nil, ids.ShortID{}, ids.Empty, err
-		}
-		utxos[i] = utxoBytes
+	utxos, err := decodeUTXOs(res)
+	if err != nil {
+		return nil, ids.ShortID{}, ids.Empty, err
 	}
 	endAddr, err := address.ParseToID(res.EndIndex.Address)
 	if err != nil {
@@ -131,6 +141,84 @@ func (c *client) GetAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, source
 	return utxos, endAddr, endUTXOID, err
 }
 
+// GetAllAtomicUTXOs returns the byte representation of all the atomic UTXOs
+// controlled by [addrs] from [sourceChain], fetching up to [limit] UTXOs per
+// request. The pages are fetched with the consistency token of the first
+// one, so that they are served from the same UTXOs. If the UTXOs changed in
+// between, the pages are fetched again from the first one.
+func (c *client) GetAllAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, options ...rpc.Option) ([][]byte, error) {
+	// The server returns at most [maxUTXOsToFetch] UTXOs per page.
+	if limit == 0 || limit > maxUTXOsToFetch {
+		limit = maxUTXOsToFetch
+	}
+	for restarts := 0; ; restarts++ {
+		utxos, err := c.getAllAtomicUTXOs(ctx, addrs, sourceChain, limit, options...)
+		if err == nil || !strings.Contains(err.Error(), errUTXOSnapshotExpired.Error()) {
+			return utxos, err
+		}
+		if restarts == maxAtomicUTXOsRestarts {
+			return nil, fmt.Errorf("%w after %d restarts", err, restarts)
+		}
+		log.Warn("Atomic UTXOs changed while fetching pages, restarting from the first page", "sourceChain", sourceChain, "fetched", len(utxos), "restarts", restarts+1)
+	}
+}
+
+// getAllAtomicUTXOs fetches all the pages of atomic UTXOs once. On error, it
+// returns the UTXOs fetched so far.
+func (c *client) getAllAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, options ...rpc.Option) ([][]byte, error) {
+	var (
+		utxos      [][]byte
+		startIndex api.Index
+		token      string
+	)
+	for {
+		res, err := c.getAtomicUTXOs(ctx, addrs, sourceChain, limit, startIndex, token, options...)
+		if err != nil {
+			return utxos, err
+		}
+		page, err := decodeUTXOs(res)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, page...)
+		if len(page) < int(limit) {
+			return utxos, nil
+		}
+		startIndex = res.EndIndex
+		token = res.ConsistencyToken
+	}
+}
+
+// getAtomicUTXOs fetches the page of atomic UTXOs starting after
+// [startIndex], with the consistency [token] of the previous page, if any.
+func (c *client) getAtomicUTXOs(ctx context.Context, addrs []ids.ShortID, sourceChain string, limit uint32, startIndex api.Index, token string, options ...rpc.Option) (*GetUTXOsReply, error) {
+	res := &GetUTXOsReply{}
+	err := c.requester.SendRequest(ctx, "avax.getUTXOs", &GetUTXOsArgs{
+		GetUTXOsArgs: api.GetUTXOsArgs{
+			Addresses:   ids.ShortIDsToStrings(addrs),
+			SourceChain: sourceChain,
+			Limit:       json.Uint32(limit),
+			StartIndex:  startIndex,
+			Encoding:    formatting.Hex,
+		},
+		ConsistencyToken: token,
+	}, res, options...)
+	return res, err
+}
+
+// decodeUTXOs returns the bytes of the UTXOs of [res].
+func decodeUTXOs(res *GetUTXOsReply) ([][]byte, error) {
+	utxos := make([][]byte, len(res.UTXOs))
+	for i, utxo := range res.UTXOs {
+		utxoBytes, err := formatting.Decode(res.Encoding, utxo)
+		if err != nil {
+			return nil, err
+		}
+		utxos[i] = utxoBytes
+	}
+	return utxos, nil
+}
+
 // ExportKey returns the private key corresponding to [addr] controlled by [user]
 // in both Avalanche standard format and hex format
 func (c *client) ExportKey(ctx context.Context, user api.UserPass, addr common.Address, options ...rpc.Option) (*secp256k1.PrivateKey, string, error) {
@@ -205,6 +293,143 @@ func (c *client) Export(
 	return res.TxID, err
 }
 
+// AtomicTxFeeResult describes an atomic transaction issued by ImportWithFee or
+// ExportWithFee and the fee chosen for it.
+type AtomicTxFeeResult struct {
+	TxID ids.ID
+	// EstimatedBaseFee is the node's estimate of the base fee, and BaseFee is
+	// the base fee the transaction was created with after applying
+	// MarginPercent. Both are nil prior to Apricot Phase 3.
+	EstimatedBaseFee *big.Int
+	BaseFee          *big.Int
+	MarginPercent    uint64
+	// GasUsed and Fee, denominated in nAVAX, of the transaction.
+	GasUsed uint64
+	Fee     uint64
+}
+
+// EstimateBaseFee returns the node's estimate of the base fee of the next
+// block. Returns nil prior to Apricot Phase 3.
+func (c *client) EstimateBaseFee(ctx context.Context, options ...rpc.Option) (*big.Int, error) {
+	res := &EstimateBaseFeeReply{}
+	err := c.requester.SendRequest(ctx, "avax.estimateBaseFee", struct{}{}, res, options...)
+	return res.BaseFee.ToInt(), err
+}
+
+// ImportWithFee sends an import transaction to import funds from
+// [sourceChain], paying the minimal fee for the transaction's gas at the
+// estimated base fee increased by [marginPercent].
+func (c *client) ImportWithFee(
+	ctx context.Context,
+	user api.UserPass,
+	to common.Address,
+	sourceChain string,
+	marginPercent uint64,
+	options ...rpc.Option,
+) (*AtomicTxFeeResult, error) {
+	args := &ImportArgs{
+		UserPass:    user,
+		To:          to,
+		SourceChain: sourceChain,
+	}
+	result, err := c.selectAtomicTxFee(ctx, marginPercent, func(baseFee *big.Int) (*AtomicTxFeeReply, error) {
+		args.BaseFee = (*hexutil.Big)(baseFee)
+		res := &AtomicTxFeeReply{}
+		err := c.requester.SendRequest(ctx, "avax.estimateImportFee", args, res, options...)
+		return res, err
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &api.JSONTxID{}
+	if err := c.requester.SendRequest(ctx, "avax.import", args, res, options...); err != nil {
+		return nil, err
+	}
+	result.TxID = res.TxID
+	return result, nil
+}
+
+// ExportWithFee sends an asset from this chain to the P/C-Chain, paying the
+// minimal fee for the transaction's gas at the estimated base fee increased by
+// [marginPercent].
+func (c *client) ExportWithFee(
+	ctx context.Context,
+	user api.UserPass,
+	amount uint64,
+	to ids.ShortID,
+	targetChain string,
+	assetID string,
+	marginPercent uint64,
+	options ...rpc.Option,
+) (*AtomicTxFeeResult, error) {
+	args := &ExportArgs{
+		ExportAVAXArgs: ExportAVAXArgs{
+			UserPass:    user,
+			Amount:      json.Uint64(amount),
+			TargetChain: targetChain,
+			To:          to.String(),
+		},
+		AssetID: assetID,
+	}
+	result, err := c.selectAtomicTxFee(ctx, marginPercent, func(baseFee *big.Int) (*AtomicTxFeeReply, error) {
+		args.BaseFee = (*hexutil.Big)(baseFee)
+		res := &AtomicTxFeeReply{}
+		err := c.requester.SendRequest(ctx, "avax.estimateExportFee", args, res, options...)
+		return res, err
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &api.JSONTxID{}
+	if err := c.requester.SendRequest(ctx, "avax.export", args, res, options...); err != nil {
+		return nil, err
+	}
+	result.TxID = res.TxID
+	return result, nil
+}
+
+// selectAtomicTxFee chooses the base fee to create an atomic transaction with
+// by adding [marginPercent] to the node's base fee estimate. The transaction
+// is re-estimated with [estimate] until its gas usage, which depends on the
+// inputs selected to pay the fee, is unchanged between two estimates.
+func (c *client) selectAtomicTxFee(
+	ctx context.Context,
+	marginPercent uint64,
+	estimate func(baseFee *big.Int) (*AtomicTxFeeReply, error),
+	options ...rpc.Option,
+) (*AtomicTxFeeResult, error) {
+	estimatedBaseFee, err := c.EstimateBaseFee(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	result := &AtomicTxFeeResult{
+		EstimatedBaseFee: estimatedBaseFee,
+		MarginPercent:    marginPercent,
+	}
+	if estimatedBaseFee != nil {
+		result.BaseFee = new(big.Int).Mul(estimatedBaseFee, new(big.Int).SetUint64(100+marginPercent))
+		result.BaseFee.Div(result.BaseFee, big.NewInt(100))
+	}
+
+	var prev *AtomicTxFeeReply
+	for i := 0; i < maxAtomicFeeEstimates; i++ {
+		reply, err := estimate(result.BaseFee)
+		if err != nil {
+			return nil, err
+		}
+		// Prior to Apricot Phase 3 the fee does not depend on the gas used.
+		if result.BaseFee == nil || (prev != nil && reply.GasUsed == prev.GasUsed) {
+			result.GasUsed = uint64(reply.GasUsed)
+			result.Fee = uint64(reply.Fee)
+			return result, nil
+		}
+		prev = reply
+	}
+	return nil, fmt.Errorf("%w after %d estimates", errAtomicFeeNotConverged, maxAtomicFeeEstimates)
+}
+
 func (c *client) StartCPUProfiler(ctx context.Context, options ...rpc.Option) error {
 	return c.adminRequester.SendRequest(ctx, "admin.startCPUProfiler", struct{}{}, &api.EmptyReply{}, options...)
 }
@@ -0,0 +1,272 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceRequester serves client requests directly from an AvaxAPI.
+type serviceRequester struct {
+	service *AvaxAPI
+	// beforeGetUTXOs is called, if set, before serving each avax.getUTXOs
+	// request.
+	beforeGetUTXOs func()
+}
+
+func (r *serviceRequester) SendRequest(_ context.Context, method string, params interface{}, reply interface{}, _ ...rpc.Option) error {
+	switch method {
+	case "avax.estimateBaseFee":
+		return r.service.EstimateBaseFee(nil, nil, reply.(*EstimateBaseFeeReply))
+	case "avax.estimateImportFee":
+		return r.service.EstimateImportFee(nil, params.(*ImportArgs), reply.(*AtomicTxFeeReply))
+	case "avax.import":
+		return r.service.Import(nil, params.(*ImportArgs), reply.(*api.JSONTxID))
+	case "avax.getUTXOs":
+		if r.beforeGetUTXOs != nil {
+			r.beforeGetUTXOs()
+		}
+		return r.service.GetUTXOs(nil, params.(*GetUTXOsArgs), reply.(*GetUTXOsReply))
+	default:
+		return fmt.Errorf("unexpected method %q", method)
+	}
+}
+
+func TestClientImportWithFee(t *testing.T) {
+	require := require.New(t)
+
+	importAmount := uint64(50000000)
+	_, vm, _, _, _ := GenesisVMWithUTXOs(t, true, "", "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	db, err := vm.ctx.Keystore.GetDatabase(username, password)
+	require.NoError(err)
+	require.NoError((&user{db: db}).putAddress(testKeys[0]))
+	require.NoError(db.Close())
+
+	c := &client{requester: &serviceRequester{service: &AvaxAPI{vm}}}
+	userPass := api.UserPass{Username: username, Password: password}
+
+	vm.ctx.Lock.Unlock()
+	result, err := c.ImportWithFee(context.Background(), userPass, testEthAddrs[0], "X", 5)
+	vm.ctx.Lock.Lock()
+	require.NoError(err)
+
+	require.NotNil(result.EstimatedBaseFee)
+	expectedBaseFee := new(big.Int).Mul(result.EstimatedBaseFee, big.NewInt(105))
+	expectedBaseFee.Div(expectedBaseFee, big.NewInt(100))
+	require.Equal(expectedBaseFee, result.BaseFee)
+	require.Equal(uint64(5), result.MarginPercent)
+
+	// The fee is the minimal fee for the transaction's gas at the chosen base
+	// fee, which covers the estimated base fee.
+	expectedFee, err := CalculateDynamicFee(result.GasUsed, result.BaseFee)
+	require.NoError(err)
+	require.Equal(expectedFee, result.Fee)
+	minimalFee, err := CalculateDynamicFee(result.GasUsed, result.EstimatedBaseFee)
+	require.NoError(err)
+	require.GreaterOrEqual(result.Fee, minimalFee)
+
+	// The issued transaction matches the server side estimate.
+	tx, _, ok := vm.mempool.GetTx(result.TxID)
+	require.True(ok)
+	gasUsed, err := tx.GasUsed(vm.currentRules().IsApricotPhase5)
+	require.NoError(err)
+	require.Equal(result.GasUsed, gasUsed)
+	burned, err := tx.Burned(vm.ctx.AVAXAssetID)
+	require.NoError(err)
+	require.Equal(result.Fee, burned)
+}
+
+// baseFeeRequester only serves avax.estimateBaseFee.
+type baseFeeRequester struct {
+	baseFee *big.Int
+}
+
+func (r *baseFeeRequester) SendRequest(_ context.Context, method string, _ interface{}, reply interface{}, _ ...rpc.Option) error {
+	if method != "avax.estimateBaseFee" {
+		return fmt.Errorf("unexpected method %q", method)
+	}
+	reply.(*EstimateBaseFeeReply).BaseFee = (*hexutil.Big)(r.baseFee)
+	return nil
+}
+
+func TestSelectAtomicTxFee(t *testing.T) {
+	tests := map[string]struct {
+		baseFee           *big.Int
+		gasUsed           []uint64
+		expectedBaseFee   *big.Int
+		expectedGasUsed   uint64
+		expectedEstimates int
+		expectedErr       error
+	}{
+		"stable": {
+			baseFee:           big.NewInt(100),
+			gasUsed:           []uint64{1_000, 1_000},
+			expectedBaseFee:   big.NewInt(110),
+			expectedGasUsed:   1_000,
+			expectedEstimates: 2,
+		},
+		"input selection changes": {
+			baseFee:           big.NewInt(100),
+			gasUsed:           []uint64{1_000, 2_100, 2_100},
+			expectedBaseFee:   big.NewInt(110),
+			expectedGasUsed:   2_100,
+			expectedEstimates: 3,
+		},
+		"oscillation": {
+			baseFee:           big.NewInt(100),
+			gasUsed:           []uint64{1_000, 2_100, 1_000, 2_100, 1_000},
+			expectedBaseFee:   big.NewInt(110),
+			expectedEstimates: maxAtomicFeeEstimates,
+			expectedErr:       errAtomicFeeNotConverged,
+		},
+		"fixed fee": {
+			gasUsed:           []uint64{1_000},
+			expectedGasUsed:   1_000,
+			expectedEstimates: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			c := &client{requester: &baseFeeRequester{baseFee: test.baseFee}}
+			var estimates int
+			result, err := c.selectAtomicTxFee(context.Background(), 10, func(baseFee *big.Int) (*AtomicTxFeeReply, error) {
+				require.Equal(test.expectedBaseFee, baseFee)
+				gasUsed := test.gasUsed[estimates]
+				estimates++
+				fee := gasUsed
+				if baseFee != nil {
+					var err error
+					fee, err = CalculateDynamicFee(gasUsed, baseFee)
+					require.NoError(err)
+				}
+				return &AtomicTxFeeReply{
+					GasUsed: json.Uint64(gasUsed),
+					BaseFee: (*hexutil.Big)(baseFee),
+					Fee:     json.Uint64(fee),
+				}, nil
+			})
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedEstimates, estimates)
+			if test.expectedErr != nil {
+				return
+			}
+			require.Equal(test.expectedGasUsed, result.GasUsed)
+			require.Equal(test.expectedBaseFee, result.BaseFee)
+		})
+	}
+}
+
+func TestClientGetAllAtomicUTXOs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	importAmount := uint64(50000000)
+	issuer, vm, _, sharedMemory, _ := GenesisVMWithUTXOs(t, true, "", "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+		testShortIDAddrs[1]: importAmount,
+		testShortIDAddrs[2]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(ctx))
+	}()
+	_, err := addUTXO(sharedMemory, vm.ctx, ids.GenerateTestID(), 0, vm.ctx.AVAXAssetID, importAmount, testShortIDAddrs[1])
+	require.NoError(err)
+
+	// acceptImport accepts a block importing the UTXOs of [key], which
+	// removes them from shared memory.
+	acceptImport := func(key *secp256k1.PrivateKey) {
+		vm.ctx.Lock.Lock()
+		defer vm.ctx.Lock.Unlock()
+
+		importTx, err := vm.newImportTx(vm.ctx.XChainID, GetEthAddress(key), initialBaseFee, []*secp256k1.PrivateKey{key})
+		require.NoError(err)
+		require.NoError(vm.mempool.AddLocalTx(importTx))
+		<-issuer
+		blk, err := vm.BuildBlock(ctx)
+		require.NoError(err)
+		require.NoError(blk.Verify(ctx))
+		require.NoError(vm.SetPreference(ctx, blk.ID()))
+		require.NoError(blk.Accept(ctx))
+	}
+	vm.ctx.Lock.Unlock()
+	defer vm.ctx.Lock.Lock()
+
+	// The pages requested with the consistency token of the first one are
+	// served until a block is accepted.
+	service := &AvaxAPI{vm}
+	args := &GetUTXOsArgs{GetUTXOsArgs: api.GetUTXOsArgs{
+		Addresses:   ids.ShortIDsToStrings(testShortIDAddrs),
+		SourceChain: "X",
+		Limit:       1,
+		Encoding:    formatting.Hex,
+	}}
+	first := &GetUTXOsReply{}
+	require.NoError(service.GetUTXOs(nil, args, first))
+	require.Len(first.UTXOs, 1)
+	require.NotEmpty(first.ConsistencyToken)
+
+	args.StartIndex = first.EndIndex
+	args.ConsistencyToken = first.ConsistencyToken
+	second := &GetUTXOsReply{}
+	require.NoError(service.GetUTXOs(nil, args, second))
+	require.Len(second.UTXOs, 1)
+	require.NotEqual(first.UTXOs, second.UTXOs)
+	require.Equal(first.ConsistencyToken, second.ConsistencyToken)
+
+	acceptImport(testKeys[0])
+	args.StartIndex = second.EndIndex
+	err = service.GetUTXOs(nil, args, &GetUTXOsReply{})
+	require.ErrorIs(err, errUTXOSnapshotExpired)
+
+	// The client restarts from the first page once the UTXOs of
+	// [testShortIDAddrs][2] are imported between its first pages, so that
+	// they are not returned.
+	requests := 0
+	c := &client{requester: &serviceRequester{
+		service: service,
+		beforeGetUTXOs: func() {
+			requests++
+			if requests == 2 {
+				acceptImport(testKeys[2])
+			}
+		},
+	}}
+	utxos, err := c.GetAllAtomicUTXOs(ctx, testShortIDAddrs, "X", 1)
+	require.NoError(err)
+
+	vm.ctx.Lock.Lock()
+	want, _, _, err := vm.GetAtomicUTXOs(vm.ctx.XChainID, set.Of(testShortIDAddrs...), ids.ShortEmpty, ids.Empty, -1)
+	vm.ctx.Lock.Unlock()
+	require.NoError(err)
+	require.Len(want, 2)
+	wantBytes := make([][]byte, len(want))
+	for i, utxo := range want {
+		wantBytes[i], err = vm.codec.Marshal(codecVersion, utxo)
+		require.NoError(err)
+	}
+	require.ElementsMatch(wantBytes, utxos)
+	// The pages were fetched again after the import.
+	require.Equal(5, requests)
+}
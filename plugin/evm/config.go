@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/coreth/core/txpool/legacypool"
 	"github.com/ava-labs/coreth/eth"
+	"github.com/ava-labs/coreth/miner"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cast"
@@ -30,6 +32,7 @@ const (
 	defaultRpcTxFeeCap                                = 100        // 100 AVAX
 	defaultMetricsExpensiveEnabled                    = true
 	defaultApiMaxDuration                             = 0 // Default to no maximum API call duration
+	defaultApiMaxExecutionDuration                    = 2 * time.Second
 	defaultWsCpuRefillRate                            = 0 // Default to no maximum WS CPU usage
 	defaultWsCpuMaxStored                             = 0 // Default to no maximum WS CPU usage
 	defaultMaxBlocksPerRequest                        = 0 // Default to no maximum on the number of blocks per getLogs request
@@ -43,6 +46,8 @@ const (
 	defaultPushGossipFrequency                        = 100 * time.Millisecond
 	defaultPullGossipFrequency                        = 1 * time.Second
 	defaultTxRegossipFrequency                        = 30 * time.Second
+	defaultAtomicRegossipMaxBackoff                   = 10 * time.Minute
+	defaultAtomicRegossipBandwidth                    = 64 * 1024
 	defaultOfflinePruningBloomFilterSize       uint64 = 512 // Default size (MB) for the offline pruner to use
 	defaultLogLevel                                   = "info"
 	defaultLogJSONFormat                              = false
@@ -51,6 +56,13 @@ const (
 	defaultPopulateMissingTriesParallelism            = 1024
 	defaultStateSyncServerTrieCache                   = 64 // MB
 	defaultAcceptedCacheSize                          = 32 // blocks
+	defaultAtomicTxCacheSize                          = 256
+	defaultAtomicMempoolMaxTxsPerAddress              = 16
+	defaultHotContractsCheckInterval                  = 1000
+	defaultGasAnalyticsMetricsTopN                    = 10
+	defaultTxPrioritizeLocals                         = true
+	defaultBlockBuildDeadline                         = 500 * time.Millisecond
+	defaultWarpSignerTimeout                          = 2 * time.Second
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -61,6 +73,13 @@ const (
 	// - state sync time: ~6 hrs.
 	defaultStateSyncMinBlocks   = 300_000
 	defaultStateSyncRequestSize = 1024 // the number of key/values to ask peers for per request
+
+	defaultStateSyncVerifyAccounts        = 1000
+	defaultStateSyncVerifyStorageSlots    = 16
+	defaultStateSyncVerifyRecentBlocks    = 32
+	defaultStateSyncVerifyChecksPerSecond = 1000
+
+	defaultStateSyncHealTimeout = 2 * time.Second
 )
 
 var (
@@ -140,7 +159,25 @@ type Config struct {
 	TxPoolGlobalQueue  uint64   `json:"tx-pool-global-queue"`
 	TxPoolLifetime     Duration `json:"tx-pool-lifetime"`
 
+	// AtomicMempoolMinFeeRate is the amount of AVAX, in nAVAX, that atomic
+	// transactions must burn per byte to be added to the atomic mempool.
+	AtomicMempoolMinFeeRate uint64 `json:"atomic-mempool-min-fee-rate"`
+	// AtomicMempoolMaxTxsPerAddress is the maximum number of atomic
+	// transactions in the atomic mempool whose inputs are controlled by the
+	// same address, so that a single address cannot fill it. 0 means
+	// unlimited.
+	AtomicMempoolMaxTxsPerAddress int `json:"atomic-mempool-max-txs-per-address"`
+
+	// TxOrderingPolicy is the order in which pending transactions are added
+	// to blocks built by the node: "price" (default), "fifo" or "roundrobin".
+	TxOrderingPolicy miner.OrderingPolicy `json:"tx-ordering-policy"`
+	// TxPrioritizeLocals adds the transactions of local senders to blocks
+	// built by the node before those of remote senders. It can be changed at
+	// runtime with the miner_setPrioritizeLocals RPC.
+	TxPrioritizeLocals bool `json:"tx-prioritize-locals"`
+
 	APIMaxDuration           Duration      `json:"api-max-duration"`
+	APIMaxExecutionDuration  Duration      `json:"api-max-execution-duration"` // Maximum duration of a single EVM execution in eth_call and gas estimation
 	WSCPURefillRate          Duration      `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored           Duration      `json:"ws-cpu-max-stored"`
 	MaxBlocksPerRequest      int64         `json:"api-max-blocks-per-request"`
@@ -154,15 +191,49 @@ type Config struct {
 	KeystoreInsecureUnlockAllowed bool   `json:"keystore-insecure-unlock-allowed"`
 
 	// Gossip Settings
-	PushGossipPercentStake    float64  `json:"push-gossip-percent-stake"`
-	PushGossipNumValidators   int      `json:"push-gossip-num-validators"`
-	PushGossipNumPeers        int      `json:"push-gossip-num-peers"`
-	PushRegossipNumValidators int      `json:"push-regossip-num-validators"`
-	PushRegossipNumPeers      int      `json:"push-regossip-num-peers"`
-	PushGossipFrequency       Duration `json:"push-gossip-frequency"`
-	PullGossipFrequency       Duration `json:"pull-gossip-frequency"`
-	RegossipFrequency         Duration `json:"regossip-frequency"`
-	TxRegossipFrequency       Duration `json:"tx-regossip-frequency"` // Deprecated: use RegossipFrequency instead
+	// PushGossipPercentStake and PushGossipFrequency are used when the network
+	// is busy. If PushGossipMinPercentStake or PushGossipMaxFrequency is set,
+	// push gossip is sent to as little as PushGossipMinPercentStake as
+	// infrequently as PushGossipMaxFrequency during quiet periods. Activity is
+	// measured from the accepted blocks and the txs added to the eth tx pool;
+	// atomic mempool inflow is not observed.
+	PushGossipPercentStake    float64   `json:"push-gossip-percent-stake"`
+	PushGossipMinPercentStake *float64  `json:"push-gossip-min-percent-stake,omitempty"`
+	PushGossipNumValidators   int       `json:"push-gossip-num-validators"`
+	PushGossipNumPeers        int       `json:"push-gossip-num-peers"`
+	PushRegossipNumValidators int       `json:"push-regossip-num-validators"`
+	PushRegossipNumPeers      int       `json:"push-regossip-num-peers"`
+	PushGossipFrequency       Duration  `json:"push-gossip-frequency"`
+	PushGossipMaxFrequency    *Duration `json:"push-gossip-max-frequency,omitempty"`
+	PullGossipFrequency       Duration  `json:"pull-gossip-frequency"`
+	RegossipFrequency         Duration  `json:"regossip-frequency"`
+	TxRegossipFrequency       Duration  `json:"tx-regossip-frequency"` // Deprecated: use RegossipFrequency instead
+
+	// AtomicRegossipMaxBackoff is the maximum time between two re-gossips of
+	// an atomic tx pending in the mempool. Pending atomic txs are first
+	// re-gossiped after RegossipFrequency, and the time between re-gossips
+	// doubles every time up to AtomicRegossipMaxBackoff.
+	// AtomicRegossipBandwidth is the maximum number of atomic tx bytes
+	// re-gossiped per second, or 0 to disable the re-gossip.
+	AtomicRegossipMaxBackoff Duration `json:"atomic-regossip-max-backoff"`
+	AtomicRegossipBandwidth  int      `json:"atomic-regossip-bandwidth"`
+
+	// LightGossip stops the node from accepting push gossip, and asks its
+	// peers not to push gossip to it, to save bandwidth. Txs are still
+	// received through pull gossip and the contents of blocks, and the txs
+	// submitted to this node are still pushed to its peers.
+	LightGossip bool `json:"light-gossip"`
+
+	// AtomicTxBuildThreshold is how long atomic transactions may be pending
+	// before the block builder notifies the engine to build a block again,
+	// even if it has already been notified. 0, the default, disables the
+	// trigger.
+	AtomicTxBuildThreshold Duration `json:"atomic-tx-build-threshold"`
+
+	// BlockBuildDeadline is how long the block builder may try pending
+	// transactions before it builds the block from those already executed.
+	// 0 disables the deadline.
+	BlockBuildDeadline Duration `json:"block-build-deadline"`
 
 	// Log
 	LogLevel      string `json:"log-level"`
@@ -176,6 +247,9 @@ type Config struct {
 	// VM2VM network
 	MaxOutboundActiveRequests           int64 `json:"max-outbound-active-requests"`
 	MaxOutboundActiveCrossChainRequests int64 `json:"max-outbound-active-cross-chain-requests"`
+	// MinSyncPeerVersion is the minimum avalanchego version (e.g. "v1.11.0") of
+	// peers that sync requests are sent to and served for. Empty means no minimum.
+	MinSyncPeerVersion string `json:"min-sync-peer-version"`
 
 	// Sync settings
 	StateSyncEnabled         *bool  `json:"state-sync-enabled"`     // Pointer distinguishes false (no state sync) and not set (state sync only at genesis).
@@ -186,9 +260,33 @@ type Config struct {
 	StateSyncMinBlocks       uint64 `json:"state-sync-min-blocks"`
 	StateSyncRequestSize     uint16 `json:"state-sync-request-size"`
 
+	// StateSyncVerifyEnabled runs a verification pass after state sync, which
+	// cross-checks the snapshot against the trie for a sample of accounts and
+	// storage slots, and for the accounts touched by the most recent
+	// [StateSyncVerifyRecentBlocks] synced blocks. Mismatches are logged.
+	// The pass runs before bootstrapping starts, so it delays bootstrapping.
+	StateSyncVerifyEnabled         bool   `json:"state-sync-verify-enabled"`
+	StateSyncVerifyAccounts        int    `json:"state-sync-verify-accounts"`
+	StateSyncVerifyStorageSlots    int    `json:"state-sync-verify-storage-slots"`
+	StateSyncVerifyRecentBlocks    uint64 `json:"state-sync-verify-recent-blocks"`
+	StateSyncVerifyChecksPerSecond int    `json:"state-sync-verify-checks-per-second"`
+
+	// StateSyncHealMissingNodes fetches the trie nodes missing when reading
+	// state from the state sync peers before failing the read, which can
+	// recover from nodes missing after state sync. Each missing node is
+	// fetched for at most [StateSyncHealTimeout].
+	StateSyncHealMissingNodes bool     `json:"state-sync-heal-missing-nodes"`
+	StateSyncHealTimeout      Duration `json:"state-sync-heal-timeout"`
+
 	// Database Settings
 	InspectDatabase bool `json:"inspect-database"` // Inspects the database on startup if enabled.
 
+	// SkipChainIdentityCheck allows starting the VM on a database that was created
+	// for a different network ID, chain ID, or genesis. The stored identity is
+	// replaced with the configured one. This should only be used for intentional
+	// database migrations.
+	SkipChainIdentityCheck bool `json:"skip-chain-identity-check"`
+
 	// SkipUpgradeCheck disables checking that upgrades must take place before the last
 	// accepted block. Skipping this check is useful when a node operator does not update
 	// their node before the network upgrade and their node accepts blocks that have
@@ -202,6 +300,10 @@ type Config struct {
 	// on RPC nodes.
 	AcceptedCacheSize int `json:"accepted-cache-size"`
 
+	// AtomicTxCacheSize is the number of recently accessed atomic txs to keep
+	// decoded in memory.
+	AtomicTxCacheSize int `json:"atomic-tx-cache-size"`
+
 	// TransactionHistory is the maximum number of blocks from head whose tx indices
 	// are reserved:
 	//  * 0:   means no limit
@@ -215,11 +317,45 @@ type Config struct {
 	// TxLookupLimit can be still used to control unindexing old transactions.
 	SkipTxIndexing bool `json:"skip-tx-indexing"`
 
+	// HotContracts are contracts for which the node maintains a flat copy of
+	// their storage, updated on accept, to serve eth_getStorageAt and eth_call
+	// reads without traversing their storage tries. The copies are local to the
+	// node and are rebuilt from the trie if needed.
+	HotContracts []common.Address `json:"hot-contracts"`
+	// HotContractsCheckInterval is the number of hot contract storage reads
+	// between checks of a read value against the trie. An inconsistent copy is
+	// no longer used and is rebuilt when the next block is accepted.
+	// 0 disables the checks.
+	HotContractsCheckInterval uint64 `json:"hot-contracts-check-interval"`
+
+	// GasAnalyticsWindow is the number of recently accepted blocks over which
+	// the node accumulates the gas used by the transactions sent to, or
+	// creating, each address, served by debug_topGasConsumers. The gas used by
+	// internal calls is attributed to the recipient of the transaction. The
+	// usage is not persisted across restarts. 0 disables the accumulation.
+	GasAnalyticsWindow uint64 `json:"gas-analytics-window"`
+	// GasAnalyticsMetricsTopN is the number of addresses using the most gas
+	// over the window for which gas used metrics are exported.
+	GasAnalyticsMetricsTopN int `json:"gas-analytics-metrics-top-n"`
+
 	// WarpOffChainMessages encodes off-chain messages (unrelated to any on-chain event ie. block or AddressedCall)
 	// that the node should be willing to sign.
 	// Note: only supports AddressedCall payloads as defined here:
 	// https://github.com/ava-labs/avalanchego/tree/7623ffd4be915a5185c9ed5e11fa9be15a6e1f00/vms/platformvm/warp/payload#addressedcall
 	WarpOffChainMessages []hexutil.Bytes `json:"warp-off-chain-messages"`
+
+	// WarpSignerEndpoint is the address of a remote gRPC warp signer, which
+	// signs warp messages instead of the local BLS key of the node if set.
+	// The signer is authenticated with mutual TLS, using the client
+	// certificate and key at WarpSignerTLSCertFile and WarpSignerTLSKeyFile,
+	// and the CA certificates at WarpSignerTLSCAFile.
+	WarpSignerEndpoint    string `json:"warp-signer-endpoint"`
+	WarpSignerTLSCertFile string `json:"warp-signer-tls-cert-file"`
+	WarpSignerTLSKeyFile  string `json:"warp-signer-tls-key-file"`
+	WarpSignerTLSCAFile   string `json:"warp-signer-tls-ca-file"`
+	// WarpSignerTimeout is how long a request to the remote warp signer may
+	// take.
+	WarpSignerTimeout Duration `json:"warp-signer-timeout"`
 }
 
 // EthAPIs returns an array of strings representing the Eth APIs that should be enabled
@@ -244,8 +380,10 @@ func (c *Config) SetDefaults() {
 	c.TxPoolAccountQueue = legacypool.DefaultConfig.AccountQueue
 	c.TxPoolGlobalQueue = legacypool.DefaultConfig.GlobalQueue
 	c.TxPoolLifetime.Duration = legacypool.DefaultConfig.Lifetime
+	c.TxPrioritizeLocals = defaultTxPrioritizeLocals
 
 	c.APIMaxDuration.Duration = defaultApiMaxDuration
+	c.APIMaxExecutionDuration.Duration = defaultApiMaxExecutionDuration
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
 	c.WSCPUMaxStored.Duration = defaultWsCpuMaxStored
 	c.MaxBlocksPerRequest = defaultMaxBlocksPerRequest
@@ -268,6 +406,8 @@ func (c *Config) SetDefaults() {
 	c.PushGossipFrequency.Duration = defaultPushGossipFrequency
 	c.PullGossipFrequency.Duration = defaultPullGossipFrequency
 	c.RegossipFrequency.Duration = defaultTxRegossipFrequency
+	c.AtomicRegossipMaxBackoff.Duration = defaultAtomicRegossipMaxBackoff
+	c.AtomicRegossipBandwidth = defaultAtomicRegossipBandwidth
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
 	c.LogLevel = defaultLogLevel
 	c.LogJSONFormat = defaultLogJSONFormat
@@ -277,9 +417,20 @@ func (c *Config) SetDefaults() {
 	c.StateSyncServerTrieCache = defaultStateSyncServerTrieCache
 	c.StateSyncCommitInterval = defaultSyncableCommitInterval
 	c.StateSyncMinBlocks = defaultStateSyncMinBlocks
+	c.HotContractsCheckInterval = defaultHotContractsCheckInterval
+	c.GasAnalyticsMetricsTopN = defaultGasAnalyticsMetricsTopN
 	c.StateSyncRequestSize = defaultStateSyncRequestSize
+	c.StateSyncVerifyAccounts = defaultStateSyncVerifyAccounts
+	c.StateSyncVerifyStorageSlots = defaultStateSyncVerifyStorageSlots
+	c.StateSyncVerifyRecentBlocks = defaultStateSyncVerifyRecentBlocks
+	c.StateSyncVerifyChecksPerSecond = defaultStateSyncVerifyChecksPerSecond
+	c.StateSyncHealTimeout.Duration = defaultStateSyncHealTimeout
 	c.AllowUnprotectedTxHashes = defaultAllowUnprotectedTxHashes
 	c.AcceptedCacheSize = defaultAcceptedCacheSize
+	c.AtomicTxCacheSize = defaultAtomicTxCacheSize
+	c.AtomicMempoolMaxTxsPerAddress = defaultAtomicMempoolMaxTxsPerAddress
+	c.BlockBuildDeadline.Duration = defaultBlockBuildDeadline
+	c.WarpSignerTimeout.Duration = defaultWarpSignerTimeout
 }
 
 func (d *Duration) UnmarshalJSON(data []byte) (err error) {
@@ -321,9 +472,85 @@ func (c *Config) Validate() error {
 	if c.PushGossipPercentStake < 0 || c.PushGossipPercentStake > 1 {
 		return fmt.Errorf("push-gossip-percent-stake is %f but must be in the range [0, 1]", c.PushGossipPercentStake)
 	}
+	if minPercentStake := c.pushGossipMinPercentStake(); minPercentStake < 0 || minPercentStake > c.PushGossipPercentStake {
+		return fmt.Errorf("push-gossip-min-percent-stake is %f but must be in the range [0, %f]", minPercentStake, c.PushGossipPercentStake)
+	}
+	if maxFrequency := c.pushGossipMaxFrequency(); maxFrequency < c.PushGossipFrequency.Duration {
+		return fmt.Errorf("push-gossip-max-frequency (%s) must be at least push-gossip-frequency (%s)", maxFrequency, c.PushGossipFrequency.Duration)
+	}
+	if c.AtomicRegossipBandwidth < 0 {
+		return fmt.Errorf("atomic-regossip-bandwidth (%d) must not be negative", c.AtomicRegossipBandwidth)
+	}
+	if c.AtomicRegossipBandwidth > 0 && c.AtomicRegossipMaxBackoff.Duration < c.RegossipFrequency.Duration {
+		return fmt.Errorf("atomic-regossip-max-backoff (%s) must be at least regossip-frequency (%s)", c.AtomicRegossipMaxBackoff.Duration, c.RegossipFrequency.Duration)
+	}
+	if _, err := c.minSyncPeerVersion(); err != nil {
+		return err
+	}
+	if c.AtomicMempoolMaxTxsPerAddress < 0 {
+		return fmt.Errorf("atomic-mempool-max-txs-per-address (%d) must not be negative", c.AtomicMempoolMaxTxsPerAddress)
+	}
+	if c.BlockBuildDeadline.Duration < 0 {
+		return fmt.Errorf("block-build-deadline (%s) must not be negative", c.BlockBuildDeadline.Duration)
+	}
+	if c.StateSyncVerifyRecentBlocks > parentsToGet {
+		return fmt.Errorf("state-sync-verify-recent-blocks (%d) must be at most the number of synced parent blocks (%d)", c.StateSyncVerifyRecentBlocks, parentsToGet)
+	}
+	if err := c.TxOrderingPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid tx-ordering-policy: %w", err)
+	}
+	if c.StateSyncVerifyAccounts < 0 || c.StateSyncVerifyStorageSlots < 0 || c.StateSyncVerifyChecksPerSecond < 0 {
+		return fmt.Errorf("state-sync-verify-accounts (%d), state-sync-verify-storage-slots (%d) and state-sync-verify-checks-per-second (%d) cannot be negative", c.StateSyncVerifyAccounts, c.StateSyncVerifyStorageSlots, c.StateSyncVerifyChecksPerSecond)
+	}
+	if c.StateSyncHealMissingNodes && c.StateSyncHealTimeout.Duration <= 0 {
+		return fmt.Errorf("state-sync-heal-timeout (%s) must be positive", c.StateSyncHealTimeout.Duration)
+	}
+	if c.WarpSignerEndpoint != "" {
+		if c.WarpSignerTLSCertFile == "" || c.WarpSignerTLSKeyFile == "" || c.WarpSignerTLSCAFile == "" {
+			return fmt.Errorf("warp-signer-endpoint requires warp-signer-tls-cert-file, warp-signer-tls-key-file and warp-signer-tls-ca-file")
+		}
+		if c.WarpSignerTimeout.Duration <= 0 {
+			return fmt.Errorf("warp-signer-timeout (%s) must be positive", c.WarpSignerTimeout.Duration)
+		}
+	}
 	return nil
 }
 
+// pushGossipMinPercentStake returns [PushGossipMinPercentStake], or
+// [PushGossipPercentStake] if it is not set.
+func (c *Config) pushGossipMinPercentStake() float64 {
+	if c.PushGossipMinPercentStake == nil {
+		return c.PushGossipPercentStake
+	}
+	return *c.PushGossipMinPercentStake
+}
+
+// pushGossipMaxFrequency returns [PushGossipMaxFrequency], or
+// [PushGossipFrequency] if it is not set.
+func (c *Config) pushGossipMaxFrequency() time.Duration {
+	if c.PushGossipMaxFrequency == nil {
+		return c.PushGossipFrequency.Duration
+	}
+	return c.PushGossipMaxFrequency.Duration
+}
+
+// minSyncPeerVersion parses [MinSyncPeerVersion], returning nil if it is empty.
+func (c *Config) minSyncPeerVersion() (*version.Application, error) {
+	if c.MinSyncPeerVersion == "" {
+		return nil, nil
+	}
+	semantic, err := version.Parse(c.MinSyncPeerVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min-sync-peer-version %q: %w", c.MinSyncPeerVersion, err)
+	}
+	return &version.Application{
+		Name:  version.Client,
+		Major: semantic.Major,
+		Minor: semantic.Minor,
+		Patch: semantic.Patch,
+	}, nil
+}
+
 func (c *Config) Deprecate() string {
 	msg := ""
 	// Deprecate the old config options and set the new ones.
@@ -126,3 +126,44 @@ func TestUnmarshalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMinSyncPeerVersion(t *testing.T) {
+	var c Config
+	minVersion, err := c.minSyncPeerVersion()
+	assert.NoError(t, err)
+	assert.Nil(t, minVersion)
+
+	c.MinSyncPeerVersion = "v1.11.3"
+	minVersion, err = c.minSyncPeerVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "avalanchego/1.11.3", minVersion.String())
+
+	c.MinSyncPeerVersion = "1.11.3"
+	_, err = c.minSyncPeerVersion()
+	assert.Error(t, err)
+}
+
+func TestPushGossipAdaptiveBounds(t *testing.T) {
+	var c Config
+	c.SetDefaults()
+	// Without adaptive bounds, push gossip uses the fixed values, so configs
+	// which lower the stake or the frequency remain valid.
+	c.PushGossipPercentStake = .3
+	c.PushGossipFrequency = Duration{time.Second}
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, .3, c.pushGossipMinPercentStake())
+	assert.Equal(t, time.Second, c.pushGossipMaxFrequency())
+
+	minPercentStake := .1
+	c.PushGossipMinPercentStake = &minPercentStake
+	c.PushGossipMaxFrequency = &Duration{2 * time.Second}
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, .1, c.pushGossipMinPercentStake())
+	assert.Equal(t, 2*time.Second, c.pushGossipMaxFrequency())
+
+	minPercentStake = .5
+	assert.Error(t, c.Validate())
+	minPercentStake = .1
+	c.PushGossipMaxFrequency = &Duration{time.Millisecond}
+	assert.Error(t, c.Validate())
+}
@@ -65,6 +65,15 @@ func (utx *UnsignedExportTx) InputUTXOs() set.Set[ids.ID] {
 	return set
 }
 
+// EVMAddresses returns the addresses of the EVM inputs
+func (utx *UnsignedExportTx) EVMAddresses() set.Set[common.Address] {
+	set := set.NewSet[common.Address](len(utx.Ins))
+	for _, in := range utx.Ins {
+		set.Add(in.Address)
+	}
+	return set
+}
+
 // Verify this transaction is well-formed
 func (utx *UnsignedExportTx) Verify(
 	ctx *snow.Context,
@@ -115,7 +115,7 @@ func (tx *GossipAtomicTx) GossipID() ids.ID {
 	return tx.Tx.ID()
 }
 
-func NewGossipEthTxPool(mempool *txpool.TxPool, registerer prometheus.Registerer) (*GossipEthTxPool, error) {
+func NewGossipEthTxPool(mempool *txpool.TxPool, chain *core.BlockChain, registerer prometheus.Registerer) (*GossipEthTxPool, error) {
 	bloom, err := gossip.NewBloomFilter(registerer, "eth_tx_bloom_filter", txGossipBloomMinTargetElements, txGossipBloomTargetFalsePositiveRate, txGossipBloomResetFalsePositiveRate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize bloom filter: %w", err)
@@ -123,6 +123,7 @@ func NewGossipEthTxPool(mempool *txpool.TxPool, registerer prometheus.Registerer
 
 	return &GossipEthTxPool{
 		mempool:    mempool,
+		chain:      chain,
 		pendingTxs: make(chan core.NewTxsEvent, pendingTxsBuffer),
 		bloom:      bloom,
 	}, nil
@@ -130,6 +131,7 @@ func NewGossipEthTxPool(mempool *txpool.TxPool, registerer prometheus.Registerer
 
 type GossipEthTxPool struct {
 	mempool    *txpool.TxPool
+	chain      *core.BlockChain
 	pendingTxs chan core.NewTxsEvent
 
 	bloom *gossip.BloomFilter
@@ -190,7 +192,14 @@ func (g *GossipEthTxPool) Subscribe(ctx context.Context) {
 
 // Add enqueues the transaction to the mempool. Subscribe should be called
 // to receive an event if tx is actually added to the mempool or not.
+//
+// Transactions which can never be included in a block because of the size or
+// gas of their predicates are rejected before reaching the mempool.
 func (g *GossipEthTxPool) Add(tx *GossipEthTx) error {
+	head := g.chain.CurrentBlock()
+	if _, err := txpool.ValidateIntrinsicGas(tx.Tx, head, g.chain.Config().Rules(head.Number, head.Time)); err != nil {
+		return err
+	}
 	return g.mempool.Add([]*types.Transaction{tx.Tx}, false, false)[0]
 }
 
@@ -0,0 +1,266 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/network/p2p/gossip"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/txpool"
+	"github.com/ava-labs/coreth/metrics"
+)
+
+const (
+	// gossipFanoutBlockWindow is the number of recent block intervals
+	// averaged when estimating the block rate.
+	gossipFanoutBlockWindow = 16
+	// gossipFanoutBusyBlockInterval is the average block interval at or below
+	// which the block rate is considered to be at maximum load.
+	gossipFanoutBusyBlockInterval = 2 * time.Second
+	// gossipFanoutQuietBlockInterval is the average block interval at or
+	// above which the block rate is considered to be idle.
+	gossipFanoutQuietBlockInterval = 20 * time.Second
+	// gossipFanoutHotTxInflowRate is the mempool inflow rate (txs/second) at
+	// or above which the mempool is considered to be at maximum load.
+	gossipFanoutHotTxInflowRate = 100
+	// gossipFanoutInflowSmoothing is the weight given to the most recent
+	// inflow rate sample in the exponential moving average.
+	gossipFanoutInflowSmoothing = 0.5
+	// gossipFanoutUpdateFrequency is how often the controller recomputes the
+	// gossip fanout and frequency.
+	gossipFanoutUpdateFrequency = time.Second
+)
+
+var _ p2p.ValidatorSubset = (*fanoutValidatorSubset)(nil)
+
+// gossipFanoutConfig specifies the bounds the gossip fanout controller
+// operates within.
+type gossipFanoutConfig struct {
+	// MinStakePercentage and MaxStakePercentage bound the percentage of stake
+	// that push gossip is sent to.
+	MinStakePercentage float64
+	MaxStakePercentage float64
+	// MinFrequency and MaxFrequency bound the interval between push gossip
+	// rounds. MinFrequency is used under maximum load.
+	MinFrequency time.Duration
+	MaxFrequency time.Duration
+}
+
+// gossipFanoutController adapts the push gossip fanout and frequency to
+// recent network activity. Fanout and frequency are increased when blocks are
+// produced frequently and the mempool is receiving many transactions, and are
+// decreased during quiet periods.
+//
+// The controller does not read the clock, so its decisions are a
+// deterministic function of the observations and update times passed in.
+type gossipFanoutController struct {
+	config gossipFanoutConfig
+
+	lock sync.Mutex
+	// blockIntervals is a ring buffer of the most recent block intervals
+	blockIntervals []time.Duration
+	nextInterval   int
+	lastBlockTime  time.Time
+	// newTxs is the number of txs observed since the last update
+	newTxs       int
+	lastUpdate   time.Time
+	txInflowRate float64
+
+	stakePercentage float64
+	frequency       time.Duration
+
+	blockIntervalGauge   metrics.Gauge
+	txInflowRateGauge    metrics.GaugeFloat64
+	loadGauge            metrics.GaugeFloat64
+	stakePercentageGauge metrics.GaugeFloat64
+	frequencyGauge       metrics.Gauge
+}
+
+// newGossipFanoutController returns a controller that starts at the minimum
+// fanout and frequency, as if the network were idle.
+func newGossipFanoutController(config gossipFanoutConfig, now time.Time) *gossipFanoutController {
+	c := &gossipFanoutController{
+		config:               config,
+		blockIntervals:       make([]time.Duration, 0, gossipFanoutBlockWindow),
+		lastUpdate:           now,
+		blockIntervalGauge:   metrics.GetOrRegisterGauge("gossip_fanout_block_interval_ms", nil),
+		txInflowRateGauge:    metrics.GetOrRegisterGaugeFloat64("gossip_fanout_tx_inflow_rate", nil),
+		loadGauge:            metrics.GetOrRegisterGaugeFloat64("gossip_fanout_load", nil),
+		stakePercentageGauge: metrics.GetOrRegisterGaugeFloat64("gossip_fanout_stake_percentage", nil),
+		frequencyGauge:       metrics.GetOrRegisterGauge("gossip_fanout_frequency_ms", nil),
+	}
+	c.apply(0)
+	return c
+}
+
+// ObserveBlock records that a block with [timestamp] was accepted.
+func (c *gossipFanoutController) ObserveBlock(timestamp time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.lastBlockTime.IsZero() {
+		interval := max(timestamp.Sub(c.lastBlockTime), 0)
+		if len(c.blockIntervals) < gossipFanoutBlockWindow {
+			c.blockIntervals = append(c.blockIntervals, interval)
+		} else {
+			c.blockIntervals[c.nextInterval] = interval
+		}
+		c.nextInterval = (c.nextInterval + 1) % gossipFanoutBlockWindow
+	}
+	c.lastBlockTime = timestamp
+}
+
+// ObserveTxs records that [numTxs] were added to the mempool.
+func (c *gossipFanoutController) ObserveTxs(numTxs int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.newTxs += numTxs
+}
+
+// Update recomputes the gossip fanout and frequency from the observations
+// made since the last update and returns the new values.
+func (c *gossipFanoutController) Update(now time.Time) (float64, time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elapsed := now.Sub(c.lastUpdate); elapsed > 0 {
+		rate := float64(c.newTxs) / elapsed.Seconds()
+		c.txInflowRate = gossipFanoutInflowSmoothing*rate + (1-gossipFanoutInflowSmoothing)*c.txInflowRate
+		c.newTxs = 0
+		c.lastUpdate = now
+	}
+
+	blockInterval := c.blockInterval(now)
+	blockLoad := 0.0
+	if !c.lastBlockTime.IsZero() {
+		blockLoad = clampUnit(float64(gossipFanoutQuietBlockInterval-blockInterval) / float64(gossipFanoutQuietBlockInterval-gossipFanoutBusyBlockInterval))
+	}
+	txLoad := clampUnit(c.txInflowRate / gossipFanoutHotTxInflowRate)
+	load := (blockLoad + txLoad) / 2
+
+	c.blockIntervalGauge.Update(blockInterval.Milliseconds())
+	c.txInflowRateGauge.Update(c.txInflowRate)
+	c.loadGauge.Update(load)
+	c.apply(load)
+	return c.stakePercentage, c.frequency
+}
+
+// blockInterval returns the average of the recent block intervals. If more
+// time than the average has passed since the last block, that time is used
+// instead so that the estimate decays when blocks stop being produced.
+// Assumes [c.lock] is held.
+func (c *gossipFanoutController) blockInterval(now time.Time) time.Duration {
+	if c.lastBlockTime.IsZero() {
+		return 0
+	}
+	var average time.Duration
+	if len(c.blockIntervals) > 0 {
+		var sum time.Duration
+		for _, interval := range c.blockIntervals {
+			sum += interval
+		}
+		average = sum / time.Duration(len(c.blockIntervals))
+	}
+	return max(average, now.Sub(c.lastBlockTime))
+}
+
+// apply sets the fanout and frequency for [load] in the range [0, 1].
+// Assumes [c.lock] is held or [c] is not yet shared.
+func (c *gossipFanoutController) apply(load float64) {
+	c.stakePercentage = c.config.MinStakePercentage + load*(c.config.MaxStakePercentage-c.config.MinStakePercentage)
+	c.frequency = c.config.MaxFrequency - time.Duration(load*float64(c.config.MaxFrequency-c.config.MinFrequency))
+
+	c.stakePercentageGauge.Update(c.stakePercentage)
+	c.frequencyGauge.Update(c.frequency.Milliseconds())
+}
+
+// StakePercentage returns the current percentage of stake to push gossip to.
+func (c *gossipFanoutController) StakePercentage() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.stakePercentage
+}
+
+// Frequency returns the current interval between push gossip rounds.
+func (c *gossipFanoutController) Frequency() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.frequency
+}
+
+// Run observes txs added to [txPool] and updates the controller every
+// [updateFrequency] until [ctx] is cancelled.
+func (c *gossipFanoutController) Run(ctx context.Context, txPool *txpool.TxPool, updateFrequency time.Duration) {
+	newTxs := make(chan core.NewTxsEvent, pendingTxsBuffer)
+	sub := txPool.SubscribeTransactions(newTxs, false)
+	if sub == nil {
+		log.Warn("failed to subscribe to new txs event")
+		return
+	}
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(updateFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-newTxs:
+			c.ObserveTxs(len(event.Txs))
+		case now := <-ticker.C:
+			stakePercentage, frequency := c.Update(now)
+			log.Debug("updated gossip fanout", "stakePercentage", stakePercentage, "frequency", frequency)
+		case <-ctx.Done():
+			log.Debug("shutting down gossip fanout controller")
+			return
+		}
+	}
+}
+
+// gossipEveryAdaptive calls [gossiper] with the frequency chosen by
+// [controller] until [ctx] is cancelled.
+func gossipEveryAdaptive(ctx context.Context, gossiper gossip.Gossiper, controller *gossipFanoutController) {
+	timer := time.NewTimer(controller.Frequency())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := gossiper.Gossip(ctx); err != nil {
+				log.Warn("failed to gossip", "err", err)
+			}
+			timer.Reset(controller.Frequency())
+		case <-ctx.Done():
+			log.Debug("shutting down gossip")
+			return
+		}
+	}
+}
+
+// fanoutValidatorSubset scales the percentage of stake requested by push
+// gossip by the fanout currently chosen by [controller].
+type fanoutValidatorSubset struct {
+	validators p2p.ValidatorSubset
+	controller *gossipFanoutController
+}
+
+func (f *fanoutValidatorSubset) Top(ctx context.Context, percentage float64) []ids.NodeID {
+	if maxStakePercentage := f.controller.config.MaxStakePercentage; maxStakePercentage > 0 {
+		percentage *= f.controller.StakePercentage() / maxStakePercentage
+	}
+	return f.validators.Top(ctx, percentage)
+}
+
+func clampUnit(v float64) float64 {
+	return min(max(v, 0), 1)
+}
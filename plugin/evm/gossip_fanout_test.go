@@ -0,0 +1,201 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/stretchr/testify/require"
+)
+
+var testGossipFanoutConfig = gossipFanoutConfig{
+	MinStakePercentage: 0.5,
+	MaxStakePercentage: 0.9,
+	MinFrequency:       100 * time.Millisecond,
+	MaxFrequency:       time.Second,
+}
+
+type fanoutDecision struct {
+	stakePercentage float64
+	frequency       time.Duration
+}
+
+// loadStep describes the activity observed during one second of a synthetic
+// load pattern.
+type loadStep struct {
+	block bool
+	txs   int
+}
+
+// runLoadPattern drives [steps] through a new controller, updating once per
+// second, and returns the resulting fanout trajectory.
+func runLoadPattern(steps []loadStep) []fanoutDecision {
+	start := time.Unix(1_000_000, 0)
+	c := newGossipFanoutController(testGossipFanoutConfig, start)
+	trajectory := make([]fanoutDecision, 0, len(steps))
+	for i, step := range steps {
+		now := start.Add(time.Duration(i+1) * time.Second)
+		if step.block {
+			c.ObserveBlock(now)
+		}
+		c.ObserveTxs(step.txs)
+		stakePercentage, frequency := c.Update(now)
+		trajectory = append(trajectory, fanoutDecision{stakePercentage, frequency})
+	}
+	return trajectory
+}
+
+func repeatLoadStep(step loadStep, n int) []loadStep {
+	steps := make([]loadStep, n)
+	for i := range steps {
+		steps[i] = step
+	}
+	return steps
+}
+
+func TestGossipFanoutControllerStartsIdle(t *testing.T) {
+	require := require.New(t)
+
+	c := newGossipFanoutController(testGossipFanoutConfig, time.Unix(0, 0))
+	require.Equal(testGossipFanoutConfig.MinStakePercentage, c.StakePercentage())
+	require.Equal(testGossipFanoutConfig.MaxFrequency, c.Frequency())
+
+	// Nothing observed should keep the controller idle.
+	trajectory := runLoadPattern(repeatLoadStep(loadStep{}, 10))
+	for _, decision := range trajectory {
+		require.Equal(testGossipFanoutConfig.MinStakePercentage, decision.stakePercentage)
+		require.Equal(testGossipFanoutConfig.MaxFrequency, decision.frequency)
+	}
+}
+
+func TestGossipFanoutControllerBurstAndQuiet(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		burstLength = 30
+		quietLength = 30
+	)
+	steps := append(
+		repeatLoadStep(loadStep{block: true, txs: 500}, burstLength),
+		repeatLoadStep(loadStep{}, quietLength)...,
+	)
+	trajectory := runLoadPattern(steps)
+
+	// Frequent blocks and a hot mempool saturate the fanout.
+	for _, decision := range trajectory[:burstLength] {
+		require.InDelta(testGossipFanoutConfig.MaxStakePercentage, decision.stakePercentage, 1e-9)
+		require.Equal(testGossipFanoutConfig.MinFrequency, decision.frequency)
+	}
+
+	// Once activity stops the fanout should monotonically decay back to the
+	// minimum.
+	quiet := trajectory[burstLength:]
+	for i := 1; i < len(quiet); i++ {
+		require.LessOrEqual(quiet[i].stakePercentage, quiet[i-1].stakePercentage)
+		require.GreaterOrEqual(quiet[i].frequency, quiet[i-1].frequency)
+	}
+	last := quiet[len(quiet)-1]
+	require.InDelta(testGossipFanoutConfig.MinStakePercentage, last.stakePercentage, 1e-6)
+	require.InDelta(float64(testGossipFanoutConfig.MaxFrequency), float64(last.frequency), float64(time.Millisecond))
+}
+
+func TestGossipFanoutControllerRamp(t *testing.T) {
+	require := require.New(t)
+
+	// Increase the tx inflow rate every 10 seconds while blocks are produced
+	// every 11 seconds.
+	var steps []loadStep
+	for _, txs := range []int{0, 20, 40, 60, 80, 100} {
+		for i := 0; i < 10; i++ {
+			steps = append(steps, loadStep{
+				block: len(steps)%11 == 10,
+				txs:   txs,
+			})
+		}
+	}
+	trajectory := runLoadPattern(steps)
+
+	// Sample the decision just after each block, once the inflow rate has
+	// converged.
+	var sampled []fanoutDecision
+	for i, step := range steps {
+		if step.block && i >= 21 {
+			sampled = append(sampled, trajectory[i])
+		}
+	}
+	require.NotEmpty(sampled)
+	for i := 1; i < len(sampled); i++ {
+		require.Greater(sampled[i].stakePercentage, sampled[i-1].stakePercentage)
+		require.Less(sampled[i].frequency, sampled[i-1].frequency)
+	}
+	for _, decision := range trajectory {
+		require.GreaterOrEqual(decision.stakePercentage, testGossipFanoutConfig.MinStakePercentage)
+		require.LessOrEqual(decision.stakePercentage, testGossipFanoutConfig.MaxStakePercentage)
+		require.GreaterOrEqual(decision.frequency, testGossipFanoutConfig.MinFrequency)
+		require.LessOrEqual(decision.frequency, testGossipFanoutConfig.MaxFrequency)
+	}
+}
+
+func TestGossipFanoutControllerBlockInterval(t *testing.T) {
+	require := require.New(t)
+
+	// Blocks every 11 seconds with no txs is half of the block load, which is
+	// a quarter of the total load.
+	start := time.Unix(1_000_000, 0)
+	c := newGossipFanoutController(testGossipFanoutConfig, start)
+	for i := 0; i <= gossipFanoutBlockWindow; i++ {
+		c.ObserveBlock(start.Add(time.Duration(i) * 11 * time.Second))
+	}
+	stakePercentage, frequency := c.Update(start.Add(gossipFanoutBlockWindow * 11 * time.Second))
+	require.InDelta(0.6, stakePercentage, 1e-9)
+	require.Equal(775*time.Millisecond, frequency)
+}
+
+func TestGossipFanoutControllerDeterministic(t *testing.T) {
+	steps := make([]loadStep, 0, 120)
+	for i := 0; i < cap(steps); i++ {
+		steps = append(steps, loadStep{
+			block: i%3 == 0 || (i > 60 && i%7 == 0),
+			txs:   (i * 37) % 250,
+		})
+	}
+	require.Equal(t, runLoadPattern(steps), runLoadPattern(steps))
+}
+
+type testValidatorSubset struct {
+	percentage float64
+}
+
+func (t *testValidatorSubset) Top(_ context.Context, percentage float64) []ids.NodeID {
+	t.percentage = percentage
+	return nil
+}
+
+func TestFanoutValidatorSubset(t *testing.T) {
+	require := require.New(t)
+
+	start := time.Unix(1_000_000, 0)
+	c := newGossipFanoutController(testGossipFanoutConfig, start)
+	validators := &testValidatorSubset{}
+	subset := &fanoutValidatorSubset{
+		validators: validators,
+		controller: c,
+	}
+
+	subset.Top(context.Background(), testGossipFanoutConfig.MaxStakePercentage)
+	require.InDelta(testGossipFanoutConfig.MinStakePercentage, validators.percentage, 1e-9)
+
+	// Regossip does not request any stake and should not be scaled up.
+	subset.Top(context.Background(), 0)
+	require.Zero(validators.percentage)
+
+	c.ObserveBlock(start)
+	c.ObserveTxs(1_000)
+	c.Update(start.Add(time.Second))
+	subset.Top(context.Background(), testGossipFanoutConfig.MaxStakePercentage)
+	require.InDelta(testGossipFanoutConfig.MaxStakePercentage, validators.percentage, 1e-9)
+}
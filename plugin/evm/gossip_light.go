@@ -0,0 +1,132 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/ava-labs/coreth/metrics"
+)
+
+const (
+	lightGossipDisabled byte = 0
+	lightGossipEnabled  byte = 1
+)
+
+var (
+	_ p2p.Handler         = (*lightGossipPeers)(nil)
+	_ p2p.Handler         = (*lightGossipHandler)(nil)
+	_ p2p.ValidatorSubset = (*lightGossipValidatorSubset)(nil)
+)
+
+// lightGossipAnnouncement returns the message sent on [lightGossipProtocol]
+// to announce whether the sender is in light gossip mode.
+func lightGossipAnnouncement(enabled bool) []byte {
+	if enabled {
+		return []byte{lightGossipEnabled}
+	}
+	return []byte{lightGossipDisabled}
+}
+
+// lightGossipPeers tracks the peers which have announced that they are in
+// light gossip mode, so that push gossip is not sent to them.
+type lightGossipPeers struct {
+	p2p.NoOpHandler
+
+	lock  sync.RWMutex
+	peers set.Set[ids.NodeID]
+
+	numPeers metrics.Gauge
+}
+
+func newLightGossipPeers() *lightGossipPeers {
+	return &lightGossipPeers{
+		numPeers: metrics.GetOrRegisterGauge("gossip_light_peers", nil),
+	}
+}
+
+// AppGossip records the light gossip mode announced by [nodeID].
+func (l *lightGossipPeers) AppGossip(_ context.Context, nodeID ids.NodeID, announcement []byte) {
+	if len(announcement) != 1 || announcement[0] > lightGossipEnabled {
+		log.Debug("dropping invalid light gossip announcement", "nodeID", nodeID, "announcement", announcement)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if announcement[0] == lightGossipEnabled {
+		l.peers.Add(nodeID)
+	} else {
+		l.peers.Remove(nodeID)
+	}
+	l.numPeers.Update(int64(l.peers.Len()))
+}
+
+// Disconnected forgets the announcement of [nodeID], which announces its mode
+// again when it reconnects.
+func (l *lightGossipPeers) Disconnected(nodeID ids.NodeID) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.peers.Remove(nodeID)
+	l.numPeers.Update(int64(l.peers.Len()))
+}
+
+// Has returns true if [nodeID] has announced that it is in light gossip mode.
+func (l *lightGossipPeers) Has(nodeID ids.NodeID) bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.peers.Contains(nodeID)
+}
+
+// lightGossipValidatorSubset excludes the peers in light gossip mode from the
+// validators selected by stake for push gossip. Peers sampled uniformly by
+// the p2p client are not filtered, so a light peer may still be pushed to
+// occasionally.
+type lightGossipValidatorSubset struct {
+	validators p2p.ValidatorSubset
+	lightPeers *lightGossipPeers
+}
+
+func (v *lightGossipValidatorSubset) Top(ctx context.Context, percentage float64) []ids.NodeID {
+	top := v.validators.Top(ctx, percentage)
+	filtered := make([]ids.NodeID, 0, len(top))
+	for _, nodeID := range top {
+		if !v.lightPeers.Has(nodeID) {
+			filtered = append(filtered, nodeID)
+		}
+	}
+	return filtered
+}
+
+// lightGossipHandler drops push gossip, so that txs are only received through
+// pull gossip and the contents of blocks. Pull gossip requests from peers are
+// still served by the wrapped handler.
+type lightGossipHandler struct {
+	p2p.Handler
+
+	droppedMessages metrics.Counter
+	droppedBytes    metrics.Counter
+}
+
+func newLightGossipHandler(handler p2p.Handler) *lightGossipHandler {
+	return &lightGossipHandler{
+		Handler:         handler,
+		droppedMessages: metrics.GetOrRegisterCounter("gossip_light_dropped_messages", nil),
+		droppedBytes:    metrics.GetOrRegisterCounter("gossip_light_dropped_bytes", nil),
+	}
+}
+
+func (h *lightGossipHandler) AppGossip(_ context.Context, _ ids.NodeID, gossipBytes []byte) {
+	h.droppedMessages.Inc(1)
+	h.droppedBytes.Inc(int64(len(gossipBytes)))
+}
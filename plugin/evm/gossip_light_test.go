@@ -0,0 +1,130 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/network/p2p/gossip"
+	"github.com/ava-labs/avalanchego/proto/pb/sdk"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/utils"
+)
+
+type staticValidatorSubset []ids.NodeID
+
+func (s staticValidatorSubset) Top(context.Context, float64) []ids.NodeID {
+	return s
+}
+
+func TestLightGossipValidatorSubset(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	lightNodeID := ids.GenerateTestNodeID()
+	otherNodeID := ids.GenerateTestNodeID()
+	peers := newLightGossipPeers()
+	subset := &lightGossipValidatorSubset{
+		validators: staticValidatorSubset{lightNodeID, otherNodeID},
+		lightPeers: peers,
+	}
+	require.Equal([]ids.NodeID{lightNodeID, otherNodeID}, subset.Top(ctx, 1))
+
+	// Once a peer announces light gossip mode, it is no longer pushed to.
+	peers.AppGossip(ctx, lightNodeID, lightGossipAnnouncement(true))
+	require.Equal([]ids.NodeID{otherNodeID}, subset.Top(ctx, 1))
+
+	// Invalid announcements are ignored.
+	peers.AppGossip(ctx, lightNodeID, []byte{2})
+	peers.AppGossip(ctx, lightNodeID, nil)
+	require.Equal([]ids.NodeID{otherNodeID}, subset.Top(ctx, 1))
+
+	peers.AppGossip(ctx, lightNodeID, lightGossipAnnouncement(false))
+	require.Equal([]ids.NodeID{lightNodeID, otherNodeID}, subset.Top(ctx, 1))
+
+	// The announcement is forgotten when the peer disconnects.
+	peers.AppGossip(ctx, lightNodeID, lightGossipAnnouncement(true))
+	peers.Disconnected(lightNodeID)
+	require.Equal([]ids.NodeID{lightNodeID, otherNodeID}, subset.Top(ctx, 1))
+}
+
+func TestLightGossip(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	snowCtx := utils.TestSnowContext()
+
+	sender := &enginetest.SenderStub{
+		SentAppGossip: make(chan []byte, 1),
+	}
+	vm := &VM{
+		p2pSender:            sender,
+		ethTxPullGossiper:    gossip.NoOpGossiper{},
+		atomicTxPullGossiper: gossip.NoOpGossiper{},
+	}
+
+	pk, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	address := GetEthAddress(pk)
+	genesis := newPrefundedGenesis(100_000_000_000_000_000, address)
+	genesisBytes, err := genesis.MarshalJSON()
+	require.NoError(err)
+
+	require.NoError(vm.Initialize(
+		ctx,
+		snowCtx,
+		memdb.New(),
+		genesisBytes,
+		nil,
+		[]byte(`{"light-gossip": true}`),
+		make(chan common.Message),
+		nil,
+		sender,
+	))
+	require.NoError(vm.SetState(ctx, snow.NormalOp))
+
+	defer func() {
+		require.NoError(vm.Shutdown(ctx))
+	}()
+
+	// Light gossip mode is announced to peers when they connect.
+	peerID := ids.GenerateTestNodeID()
+	require.NoError(vm.Connected(ctx, peerID, version.CurrentApp))
+	announcement := <-sender.SentAppGossip
+	require.Equal(append(binary.AppendUvarint(nil, lightGossipProtocol), lightGossipEnabled), announcement)
+
+	// The announcement of a peer in light gossip mode is recorded.
+	require.NoError(vm.AppGossip(ctx, peerID, announcement))
+	require.True(vm.lightGossipPeers.Has(peerID))
+
+	// Pushed txs are dropped.
+	tx := types.NewTransaction(0, address, big.NewInt(10), 100_000, big.NewInt(params.LaunchMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainID), pk.ToECDSA())
+	require.NoError(err)
+	gossipedTxBytes, err := GossipEthTxMarshaller{}.MarshalGossip(&GossipEthTx{Tx: signedTx})
+	require.NoError(err)
+	inboundGossipBytes, err := proto.Marshal(&sdk.PushGossip{
+		Gossip: [][]byte{gossipedTxBytes},
+	})
+	require.NoError(err)
+	inboundGossipMsg := append(binary.AppendUvarint(nil, ethTxGossipProtocol), inboundGossipBytes...)
+
+	droppedBytes := vm.ethTxGossipHandler.(*lightGossipHandler).droppedBytes.Snapshot().Count()
+	require.NoError(vm.AppGossip(ctx, ids.EmptyNodeID, inboundGossipMsg))
+	require.False(vm.txPool.Has(signedTx.Hash()))
+	require.Equal(droppedBytes+int64(len(inboundGossipBytes)), vm.ethTxGossipHandler.(*lightGossipHandler).droppedBytes.Snapshot().Count())
+}
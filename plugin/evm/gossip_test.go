@@ -107,7 +107,7 @@ func TestAtomicMempoolIterate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			require := require.New(t)
-			m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 10, nil)
+			m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 10, 0, 0, nil)
 			require.NoError(err)
 
 			for _, add := range tt.add {
@@ -155,12 +155,12 @@ func TestGossipSubscribe(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 
 	require.NoError(err)
-	txPool := setupPoolWithConfig(t, params.TestChainConfig, addr)
+	txPool, chain := setupPoolWithConfig(t, params.TestChainConfig, addr)
 	defer txPool.Close()
 	txPool.SetGasTip(common.Big1)
 	txPool.SetMinFee(common.Big0)
 
-	gossipTxPool, err := NewGossipEthTxPool(txPool, prometheus.NewRegistry())
+	gossipTxPool, err := NewGossipEthTxPool(txPool, chain, prometheus.NewRegistry())
 	require.NoError(err)
 
 	// use a custom bloom filter to test the bloom filter reset
@@ -198,7 +198,7 @@ func TestGossipSubscribe(t *testing.T) {
 	)
 }
 
-func setupPoolWithConfig(t *testing.T, config *params.ChainConfig, fundedAddress common.Address) *txpool.TxPool {
+func setupPoolWithConfig(t *testing.T, config *params.ChainConfig, fundedAddress common.Address) (*txpool.TxPool, *core.BlockChain) {
 	diskdb := rawdb.NewMemoryDatabase()
 	engine := dummy.NewETHFaker()
 
@@ -214,5 +214,5 @@ func setupPoolWithConfig(t *testing.T, config *params.ChainConfig, fundedAddress
 	txPool, err := txpool.New(new(big.Int).SetUint64(testTxPoolConfig.PriceLimit), chain, []txpool.SubPool{legacyPool})
 	require.NoError(t, err)
 
-	return txPool
+	return txPool, chain
 }
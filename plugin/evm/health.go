@@ -10,5 +10,10 @@ import "context"
 // string, []byte, map[string]string
 func (vm *VM) HealthCheck(context.Context) (interface{}, error) {
 	// TODO perform actual health check
-	return nil, nil
+	if vm.workers == nil {
+		return nil, nil
+	}
+	// Report background workers which failed to stop on shutdown and are
+	// still running.
+	return nil, vm.workers.Err()
 }
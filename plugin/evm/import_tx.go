@@ -58,6 +58,15 @@ func (utx *UnsignedImportTx) InputUTXOs() set.Set[ids.ID] {
 	return set
 }
 
+// EVMAddresses returns the addresses of the EVM outputs
+func (utx *UnsignedImportTx) EVMAddresses() set.Set[common.Address] {
+	set := set.NewSet[common.Address](len(utx.Outs))
+	for _, out := range utx.Outs {
+		set.Add(out.Address)
+	}
+	return set
+}
+
 // Verify this transaction is well-formed
 func (utx *UnsignedImportTx) Verify(
 	ctx *snow.Context,
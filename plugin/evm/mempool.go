@@ -12,6 +12,9 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/network/p2p/gossip"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/coreth/metrics"
@@ -25,18 +28,35 @@ const (
 var (
 	errTxAlreadyKnown = errors.New("tx already known")
 	errNoGasUsed      = errors.New("no gas used")
+	errNoTxBytes      = errors.New("no tx bytes")
 
 	_ gossip.Set[*GossipAtomicTx] = (*Mempool)(nil)
 )
 
+// AddressTxLimitError is returned when adding a transaction whose inputs are
+// controlled by an address which already controls the inputs of [Limit]
+// transactions in the mempool.
+type AddressTxLimitError struct {
+	Address ids.ShortID
+	Limit   int
+}
+
+func (e *AddressTxLimitError) Error() string {
+	return fmt.Sprintf("address %s already has %d pending atomic txs in the mempool", e.Address, e.Limit)
+}
+
 // mempoolMetrics defines the metrics for the atomic mempool
 type mempoolMetrics struct {
 	pendingTxs metrics.Gauge // Gauge of currently pending transactions in the txHeap
 	currentTxs metrics.Gauge // Gauge of current transactions to be issued into a block
 	issuedTxs  metrics.Gauge // Gauge of transactions that have been issued into a block
 
+	size       metrics.Gauge // Gauge of transactions counted towards the size of the mempool
+	minFeeRate metrics.Gauge // Gauge of the lowest fee rate of the pending transactions
+
 	addedTxs     metrics.Counter // Count of all transactions added to the mempool
 	discardedTxs metrics.Counter // Count of all discarded transactions
+	evictedTxs   metrics.Counter // Count of pending transactions evicted by higher fee rate transactions
 }
 
 // newMempoolMetrics constructs metrics for the atomic mempool
@@ -45,8 +65,11 @@ func newMempoolMetrics() *mempoolMetrics {
 		pendingTxs:   metrics.GetOrRegisterGauge("atomic_mempool_pending_txs", nil),
 		currentTxs:   metrics.GetOrRegisterGauge("atomic_mempool_current_txs", nil),
 		issuedTxs:    metrics.GetOrRegisterGauge("atomic_mempool_issued_txs", nil),
+		size:         metrics.GetOrRegisterGauge("atomic_mempool_size", nil),
+		minFeeRate:   metrics.GetOrRegisterGauge("atomic_mempool_min_fee_rate", nil),
 		addedTxs:     metrics.GetOrRegisterCounter("atomic_mempool_added_txs", nil),
 		discardedTxs: metrics.GetOrRegisterCounter("atomic_mempool_discarded_txs", nil),
+		evictedTxs:   metrics.GetOrRegisterCounter("atomic_mempool_evicted_txs", nil),
 	}
 }
 
@@ -57,6 +80,12 @@ type Mempool struct {
 	ctx *snow.Context
 	// maxSize is the maximum number of transactions allowed to be kept in mempool
 	maxSize int
+	// minFeeRate is the fee rate below which transactions are not added to
+	// the mempool, even if it is not full.
+	minFeeRate uint64
+	// maxTxsPerAddress is the maximum number of transactions in the mempool
+	// whose inputs are controlled by the same address, or 0 if unlimited.
+	maxTxsPerAddress int
 	// currentTxs is the set of transactions about to be added to a block.
 	currentTxs map[ids.ID]*Tx
 	// issuedTxs is the set of transactions that have been issued into a new block
@@ -67,11 +96,20 @@ type Mempool struct {
 	// Pending is a channel of length one, which the mempool ensures has an item on
 	// it as long as there is an unissued transaction remaining in [txs]
 	Pending chan struct{}
-	// txHeap is a sorted record of all txs in the mempool by [gasPrice]
-	// NOTE: [txHeap] ONLY contains pending txs
+	// txHeap is a sorted record of all txs in the mempool by [gasPrice] and
+	// by [feeRate]
+	// NOTE: [txHeap] ONLY contains pending txs, so only they can be evicted
 	txHeap *txHeap
 	// utxoSpenders maps utxoIDs to the transaction consuming them in the mempool
 	utxoSpenders map[ids.ID]*Tx
+	// txAddresses maps the txs in the mempool to the addresses controlling
+	// their inputs, and addressTxs counts the txs in the mempool whose inputs
+	// each address controls.
+	txAddresses map[ids.ID]set.Set[ids.ShortID]
+	addressTxs  map[ids.ShortID]int
+	// secpCache caches the public keys recovered from the credentials of the
+	// txs to find the addresses controlling their inputs.
+	secpCache secp256k1.RecoverCache
 	// bloom is a bloom filter containing the txs in the mempool
 	bloom *gossip.BloomFilter
 
@@ -80,25 +118,37 @@ type Mempool struct {
 	verify func(tx *Tx) error
 }
 
-// NewMempool returns a Mempool with [maxSize]
-func NewMempool(ctx *snow.Context, registerer prometheus.Registerer, maxSize int, verify func(tx *Tx) error) (*Mempool, error) {
+// NewMempool returns a Mempool with [maxSize], which does not add
+// transactions paying a fee rate below [minFeeRate], nor more than
+// [maxTxsPerAddress] transactions whose inputs are controlled by the same
+// address, unless it is 0.
+func NewMempool(ctx *snow.Context, registerer prometheus.Registerer, maxSize int, minFeeRate uint64, maxTxsPerAddress int, verify func(tx *Tx) error) (*Mempool, error) {
 	bloom, err := gossip.NewBloomFilter(registerer, "atomic_mempool_bloom_filter", txGossipBloomMinTargetElements, txGossipBloomTargetFalsePositiveRate, txGossipBloomResetFalsePositiveRate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize bloom filter: %w", err)
 	}
 
 	return &Mempool{
-		ctx:          ctx,
-		issuedTxs:    make(map[ids.ID]*Tx),
-		discardedTxs: &cache.LRU[ids.ID, *Tx]{Size: discardedTxsCacheSize},
-		currentTxs:   make(map[ids.ID]*Tx),
-		Pending:      make(chan struct{}, 1),
-		txHeap:       newTxHeap(maxSize),
-		maxSize:      maxSize,
-		utxoSpenders: make(map[ids.ID]*Tx),
-		bloom:        bloom,
-		metrics:      newMempoolMetrics(),
-		verify:       verify,
+		ctx:              ctx,
+		issuedTxs:        make(map[ids.ID]*Tx),
+		discardedTxs:     &cache.LRU[ids.ID, *Tx]{Size: discardedTxsCacheSize},
+		currentTxs:       make(map[ids.ID]*Tx),
+		Pending:          make(chan struct{}, 1),
+		txHeap:           newTxHeap(maxSize),
+		maxSize:          maxSize,
+		minFeeRate:       minFeeRate,
+		maxTxsPerAddress: maxTxsPerAddress,
+		utxoSpenders:     make(map[ids.ID]*Tx),
+		txAddresses:      make(map[ids.ID]set.Set[ids.ShortID]),
+		addressTxs:       make(map[ids.ShortID]int),
+		secpCache: secp256k1.RecoverCache{
+			LRU: cache.LRU[ids.ID, *secp256k1.PublicKey]{
+				Size: secpCacheSize,
+			},
+		},
+		bloom:   bloom,
+		metrics: newMempoolMetrics(),
+		verify:  verify,
 	}, nil
 }
 
@@ -110,6 +160,16 @@ func (m *Mempool) Len() int {
 	return m.length()
 }
 
+// PendingLen returns the number of transactions in the mempool waiting to be
+// issued into a block. Unlike Len, it excludes transactions which have been
+// issued into a block that is still processing.
+func (m *Mempool) PendingLen() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.txHeap.Len()
+}
+
 // assumes the lock is held
 func (m *Mempool) length() int {
 	return m.txHeap.Len() + len(m.issuedTxs)
@@ -139,6 +199,40 @@ func (m *Mempool) atomicTxGasPrice(tx *Tx) (uint64, error) {
 	return burned / gasUsed, nil
 }
 
+// atomicTxFeeRate is the [feeRate] paid by a transaction to be kept in the
+// mempool and gossiped, which is the amount of [AVAXAssetID] it burns per byte.
+func (m *Mempool) atomicTxFeeRate(tx *Tx) (uint64, error) {
+	size := uint64(len(tx.SignedBytes()))
+	if size == 0 {
+		return 0, errNoTxBytes
+	}
+	burned, err := tx.Burned(m.ctx.AVAXAssetID)
+	if err != nil {
+		return 0, err
+	}
+	return burned / size, nil
+}
+
+// atomicTxAddresses returns the addresses of the keys which signed the
+// credentials of [tx], which control its inputs.
+func (m *Mempool) atomicTxAddresses(tx *Tx) (set.Set[ids.ShortID], error) {
+	addresses := set.Set[ids.ShortID]{}
+	for _, cred := range tx.Creds {
+		cred, ok := cred.(*secp256k1fx.Credential)
+		if !ok {
+			return nil, fmt.Errorf("expected *secp256k1fx.Credential but got %T", cred)
+		}
+		for _, sig := range cred.Sigs {
+			pubKey, err := m.secpCache.RecoverPublicKey(tx.Bytes(), sig[:])
+			if err != nil {
+				return nil, err
+			}
+			addresses.Add(pubKey.Address())
+		}
+	}
+	return addresses, nil
+}
+
 func (m *Mempool) Add(tx *GossipAtomicTx) error {
 	m.ctx.Lock.RLock()
 	defer m.ctx.Lock.RUnlock()
@@ -267,53 +361,112 @@ func (m *Mempool) addTx(tx *Tx, force bool) error {
 
 	utxoSet := tx.InputUTXOs()
 	gasPrice, _ := m.atomicTxGasPrice(tx)
+	feeRate, _ := m.atomicTxFeeRate(tx)
+	if !force && feeRate < m.minFeeRate {
+		return fmt.Errorf(
+			"%w minFeeRate=%d provided=%d",
+			errInsufficientAtomicTxFee,
+			m.minFeeRate,
+			feeRate,
+		)
+	}
+	addresses, err := m.atomicTxAddresses(tx)
+	if err != nil && !force {
+		return err
+	}
 	highestGasPrice, highestGasPriceConflictTxID, conflictingTxs, err := m.checkConflictTx(tx)
 	if err != nil {
 		return err
 	}
-	if len(conflictingTxs) != 0 && !force {
-		// If [tx] does not have a higher fee than all of its conflicts,
-		// we refuse to issue it to the mempool.
-		if highestGasPrice >= gasPrice {
-			return fmt.Errorf(
-				"%w: issued tx (%s) gas price %d <= conflict tx (%s) gas price %d (%d total conflicts in mempool)",
-				errConflictingAtomicTx,
-				txID,
-				gasPrice,
-				highestGasPriceConflictTxID,
-				highestGasPrice,
-				len(conflictingTxs),
-			)
+	// If [tx] does not have a higher fee than all of its conflicts, we refuse
+	// to issue it to the mempool.
+	if len(conflictingTxs) != 0 && !force && highestGasPrice >= gasPrice {
+		return fmt.Errorf(
+			"%w: issued tx (%s) gas price %d <= conflict tx (%s) gas price %d (%d total conflicts in mempool)",
+			errConflictingAtomicTx,
+			txID,
+			gasPrice,
+			highestGasPriceConflictTxID,
+			highestGasPrice,
+			len(conflictingTxs),
+		)
+	}
+	// The conflicting transactions which are pending or issued are replaced
+	// by [tx], so they free space in the mempool.
+	conflictingTxIDs := set.NewSet[ids.ID](len(conflictingTxs))
+	replaced := 0
+	for _, conflictTx := range conflictingTxs {
+		conflictTxID := conflictTx.ID()
+		if conflictingTxIDs.Contains(conflictTxID) {
+			continue
 		}
-		// Remove any conflicting transactions from the mempool
-		for _, conflictTx := range conflictingTxs {
-			m.removeTx(conflictTx, true)
+		conflictingTxIDs.Add(conflictTxID)
+		if _, issued := m.issuedTxs[conflictTxID]; issued || m.txHeap.Has(conflictTxID) {
+			replaced++
 		}
 	}
-	// If adding this transaction would exceed the mempool's size, check if there is a lower priced
-	// transaction that can be evicted from the mempool
-	if m.length() >= m.maxSize {
-		if m.txHeap.Len() > 0 {
-			// Get the lowest price item from [txHeap]
-			minTx, minGasPrice := m.txHeap.PeekMin()
-			// If the [gasPrice] of the lowest item is >= the [gasPrice] of the
-			// submitted item, discard the submitted item (we prefer items
-			// already in the mempool).
-			if minGasPrice >= gasPrice {
-				return fmt.Errorf(
-					"%w currentMin=%d provided=%d",
-					errInsufficientAtomicTxFee,
-					minGasPrice,
-					gasPrice,
-				)
+	// Refuse [tx] if one of the addresses controlling its inputs already
+	// controls the inputs of too many transactions, not counting those which
+	// [tx] replaces.
+	if !force && m.maxTxsPerAddress > 0 {
+		for address := range addresses {
+			pending := m.addressTxs[address]
+			for conflictTxID := range conflictingTxIDs {
+				if m.txAddresses[conflictTxID].Contains(address) {
+					pending--
+				}
 			}
-
-			m.removeTx(minTx, true)
-		} else {
+			if pending >= m.maxTxsPerAddress {
+				return &AddressTxLimitError{
+					Address: address,
+					Limit:   m.maxTxsPerAddress,
+				}
+			}
+		}
+	}
+	// If adding this transaction would exceed the mempool's size, check if
+	// there is a pending transaction with a lower fee rate that can be
+	// evicted from the mempool. This is checked before removing the
+	// conflicting transactions, so that they are kept if [tx] is refused.
+	var evictTx *Tx
+	if m.length()-replaced >= m.maxSize {
+		if m.txHeap.Len() == 0 {
 			// This could occur if we have used our entire size allowance on
-			// transactions that are currently processing.
+			// transactions that are currently processing, which are never
+			// evicted.
 			return errTooManyAtomicTx
 		}
+		// Get the lowest fee rate item from [txHeap]
+		minTx, minFeeRate := m.txHeap.PeekMin()
+		// If the [feeRate] of the lowest item is >= the [feeRate] of the
+		// submitted item, discard the submitted item (we prefer items
+		// already in the mempool).
+		if minFeeRate >= feeRate {
+			return fmt.Errorf(
+				"%w currentMin=%d provided=%d",
+				errInsufficientAtomicTxFee,
+				minFeeRate,
+				feeRate,
+			)
+		}
+		// If the lowest item conflicts with [tx], removing it below frees
+		// the space.
+		if !conflictingTxIDs.Contains(minTx.ID()) {
+			evictTx = minTx
+		}
+	}
+
+	// Remove any conflicting transactions from the mempool
+	for _, conflictTx := range conflictingTxs {
+		m.removeTx(conflictTx, true)
+	}
+	if evictTx != nil {
+		log.Debug("evicting atomic tx from the mempool",
+			"txID", evictTx.ID(),
+			"replacementTxID", txID,
+		)
+		m.removeTx(evictTx, true)
+		m.metrics.evictedTxs.Inc(1)
 	}
 
 	// If the transaction was recently discarded, log the event and evict from
@@ -328,12 +481,14 @@ func (m *Mempool) addTx(tx *Tx, force bool) error {
 	// Add the transaction to the [txHeap] so we can evaluate new entries based
 	// on how their [gasPrice] compares and add to [utxoSet] to make sure we can
 	// reject conflicting transactions.
-	m.txHeap.Push(tx, gasPrice)
+	m.txHeap.Push(tx, gasPrice, feeRate)
 	m.metrics.addedTxs.Inc(1)
 	m.metrics.pendingTxs.Update(int64(m.txHeap.Len()))
+	m.updateSizeMetrics()
 	for utxoID := range utxoSet {
 		m.utxoSpenders[utxoID] = tx
 	}
+	m.addAddresses(txID, addresses)
 
 	m.bloom.Add(&GossipAtomicTx{Tx: tx})
 	reset, err := gossip.ResetBloomFilterIfNeeded(m.bloom, m.length()*txGossipBloomChurnMultiplier)
@@ -389,6 +544,7 @@ func (m *Mempool) NextTx() (*Tx, bool) {
 		m.currentTxs[tx.ID()] = tx
 		m.metrics.pendingTxs.Update(int64(m.txHeap.Len()))
 		m.metrics.currentTxs.Update(int64(len(m.currentTxs)))
+		m.updateSizeMetrics()
 		return tx, true
 	}
 
@@ -447,6 +603,7 @@ func (m *Mempool) IssueCurrentTxs() {
 	}
 	m.metrics.issuedTxs.Update(int64(len(m.issuedTxs)))
 	m.metrics.currentTxs.Update(int64(len(m.currentTxs)))
+	m.updateSizeMetrics()
 
 	// If there are more transactions to be issued, add an item
 	// to Pending.
@@ -494,16 +651,19 @@ func (m *Mempool) CancelCurrentTxs() {
 // tx heap.
 // assumes the lock is held.
 func (m *Mempool) cancelTx(tx *Tx) {
-	// Add tx to heap sorted by gasPrice
+	// Add tx to heap sorted by gasPrice and feeRate
 	gasPrice, err := m.atomicTxGasPrice(tx)
 	if err == nil {
-		m.txHeap.Push(tx, gasPrice)
+		feeRate, _ := m.atomicTxFeeRate(tx)
+		m.txHeap.Push(tx, gasPrice, feeRate)
 		m.metrics.pendingTxs.Update(int64(m.txHeap.Len()))
+		m.updateSizeMetrics()
 	} else {
 		// If the err is not nil, we simply discard the transaction because it is
 		// invalid. This should never happen but we guard against the case it does.
 		log.Error("failed to calculate atomic tx gas price while canceling current tx", "err", err)
 		m.removeSpenders(tx)
+		m.removeAddresses(tx.ID())
 		m.discardedTxs.Put(tx.ID(), tx)
 		m.metrics.discardedTxs.Inc(1)
 	}
@@ -537,6 +697,7 @@ func (m *Mempool) DiscardCurrentTxs() {
 // Assumes the lock is held.
 func (m *Mempool) discardCurrentTx(tx *Tx) {
 	m.removeSpenders(tx)
+	m.removeAddresses(tx.ID())
 	m.discardedTxs.Put(tx.ID(), tx)
 	delete(m.currentTxs, tx.ID())
 	m.metrics.currentTxs.Update(int64(len(m.currentTxs)))
@@ -566,9 +727,25 @@ func (m *Mempool) removeTx(tx *Tx, discard bool) {
 	m.metrics.pendingTxs.Update(int64(m.txHeap.Len()))
 	m.metrics.currentTxs.Update(int64(len(m.currentTxs)))
 	m.metrics.issuedTxs.Update(int64(len(m.issuedTxs)))
+	m.updateSizeMetrics()
 
 	// Remove all entries from [utxoSpenders].
 	m.removeSpenders(tx)
+	m.removeAddresses(txID)
+}
+
+// updateSizeMetrics updates the size of the mempool and the lowest fee rate
+// of its pending transactions, which a transaction must exceed to evict one
+// of them once the mempool is full.
+// Assumes the lock is held.
+func (m *Mempool) updateSizeMetrics() {
+	m.metrics.size.Update(int64(m.length()))
+	if m.txHeap.Len() == 0 {
+		m.metrics.minFeeRate.Update(0)
+		return
+	}
+	_, minFeeRate := m.txHeap.PeekMin()
+	m.metrics.minFeeRate.Update(int64(minFeeRate))
 }
 
 // removeSpenders deletes the entries for all input UTXOs of [tx] from the
@@ -580,6 +757,35 @@ func (m *Mempool) removeSpenders(tx *Tx) {
 	}
 }
 
+// addAddresses records that [addresses] control the inputs of [txID].
+// Assumes the lock is held.
+func (m *Mempool) addAddresses(txID ids.ID, addresses set.Set[ids.ShortID]) {
+	if addresses.Len() == 0 {
+		return
+	}
+	m.txAddresses[txID] = addresses
+	for address := range addresses {
+		m.addressTxs[address]++
+	}
+}
+
+// removeAddresses stops counting [txID] towards the limit of the addresses
+// controlling its inputs. It does nothing if [txID] is not in the mempool.
+// Assumes the lock is held.
+func (m *Mempool) removeAddresses(txID ids.ID) {
+	addresses, ok := m.txAddresses[txID]
+	if !ok {
+		return
+	}
+	delete(m.txAddresses, txID)
+	for address := range addresses {
+		m.addressTxs[address]--
+		if m.addressTxs[address] == 0 {
+			delete(m.addressTxs, address)
+		}
+	}
+}
+
 // RemoveTx removes [txID] from the mempool completely.
 // Evicts [tx] from the discarded cache if present.
 func (m *Mempool) RemoveTx(tx *Tx) {
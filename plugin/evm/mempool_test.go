@@ -8,13 +8,19 @@ import (
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/coreth/utils"
+	"github.com/gorilla/rpc/v2/json2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 )
 
 func TestMempoolAddTx(t *testing.T) {
 	require := require.New(t)
-	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 5_000, nil)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 5_000, 0, 0, nil)
 	require.NoError(err)
 
 	txs := make([]*GossipAtomicTx, 0)
@@ -39,7 +45,7 @@ func TestMempoolAddTx(t *testing.T) {
 // Add should return an error if a tx is already known
 func TestMempoolAdd(t *testing.T) {
 	require := require.New(t)
-	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 5_000, nil)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 5_000, 0, 0, nil)
 	require.NoError(err)
 
 	tx := &GossipAtomicTx{
@@ -54,3 +60,205 @@ func TestMempoolAdd(t *testing.T) {
 	err = m.Add(tx)
 	require.ErrorIs(err, errTxAlreadyKnown)
 }
+
+// newFeeRateTestTx returns a tx of 100 bytes burning [burned], so that its fee
+// rate is [burned] / 100, and its gas price is [burned].
+func newFeeRateTestTx(burned uint64, inputUTXOs ...ids.ID) *Tx {
+	return &Tx{
+		UnsignedAtomicTx: &TestUnsignedTx{
+			IDV:            ids.GenerateTestID(),
+			GasUsedV:       1,
+			BurnedV:        burned,
+			SignedBytesV:   make([]byte, 100),
+			InputUTXOsV:    set.Of(inputUTXOs...),
+			UnsignedBytesV: make([]byte, 100),
+		},
+	}
+}
+
+func TestMempoolFeeRateEviction(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 3, 2, 0, nil)
+	require.NoError(err)
+
+	// Transactions below the minimum fee rate are never added.
+	require.ErrorIs(m.AddTx(newFeeRateTestTx(199)), errInsufficientAtomicTxFee)
+
+	// Fill the mempool, and issue the transaction with the lowest fee rate.
+	issued := newFeeRateTestTx(200)
+	require.NoError(m.AddTx(issued))
+	tx, ok := m.NextTx()
+	require.True(ok)
+	require.Equal(issued.ID(), tx.ID())
+	m.IssueCurrentTxs()
+	pending := []*Tx{
+		newFeeRateTestTx(500),
+		newFeeRateTestTx(300),
+	}
+	for _, tx := range pending {
+		require.NoError(m.AddTx(tx))
+	}
+	require.Equal(3, m.Len())
+
+	// Transactions which do not pay a higher fee rate than the lowest
+	// pending transaction are refused.
+	require.ErrorIs(m.AddTx(newFeeRateTestTx(300)), errInsufficientAtomicTxFee)
+
+	// The pending transactions are evicted from the lowest fee rate, and the
+	// issued transaction is kept.
+	for i, evicted := range []*Tx{pending[1], pending[0]} {
+		tx := newFeeRateTestTx(uint64(600 + 100*i))
+		require.NoError(m.AddTx(tx))
+		require.False(m.Has(evicted.ID()))
+		require.True(m.Has(tx.ID()))
+		require.True(m.Has(issued.ID()))
+		require.Equal(3, m.Len())
+	}
+
+	// The issued transaction cannot be evicted.
+	for m.PendingLen() > 0 {
+		_, ok := m.NextTx()
+		require.True(ok)
+	}
+	m.IssueCurrentTxs()
+	require.ErrorIs(m.AddTx(newFeeRateTestTx(1_000)), errTooManyAtomicTx)
+	require.True(m.Has(issued.ID()))
+}
+
+func TestMempoolFeeRateConflicts(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 2, 0, 0, nil)
+	require.NoError(err)
+
+	utxoID := ids.GenerateTestID()
+	conflict := newFeeRateTestTx(500, utxoID)
+	other := newFeeRateTestTx(400)
+	require.NoError(m.AddTx(conflict))
+	require.NoError(m.AddTx(other))
+
+	// A transaction conflicting with a higher fee pending transaction is
+	// refused, even if it could evict another transaction.
+	require.ErrorIs(m.AddTx(newFeeRateTestTx(450, utxoID)), errConflictingAtomicTx)
+	require.True(m.Has(conflict.ID()))
+	require.True(m.Has(other.ID()))
+
+	// A transaction replacing its conflict does not evict another
+	// transaction.
+	replacement := newFeeRateTestTx(600, utxoID)
+	require.NoError(m.AddTx(replacement))
+	require.False(m.Has(conflict.ID()))
+	require.True(m.Has(other.ID()))
+	require.True(m.Has(replacement.ID()))
+}
+
+// newSignedTestTx returns a tx burning [burned] as newFeeRateTestTx, whose
+// inputs are controlled by [keys].
+func newSignedTestTx(t *testing.T, burned uint64, keys []*secp256k1.PrivateKey, inputUTXOs ...ids.ID) *Tx {
+	tx := newFeeRateTestTx(burned, inputUTXOs...)
+	utx := tx.UnsignedAtomicTx.(*TestUnsignedTx)
+	utx.UnsignedBytesV = utx.IDV[:]
+	cred := &secp256k1fx.Credential{}
+	for _, key := range keys {
+		sig, err := key.Sign(utx.UnsignedBytesV)
+		require.NoError(t, err)
+		cred.Sigs = append(cred.Sigs, [secp256k1.SignatureLen]byte(sig))
+	}
+	tx.Creds = []verify.Verifiable{cred}
+	return tx
+}
+
+func TestMempoolAddressTxLimit(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 10, 0, 2, nil)
+	require.NoError(err)
+
+	key0, key1 := testKeys[0], testKeys[1]
+	first := newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key0})
+	require.NoError(m.AddTx(first))
+	utxoID := ids.GenerateTestID()
+	second := newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key0, key1}, utxoID)
+	require.NoError(m.AddTx(second))
+
+	// [key0] controls the inputs of 2 txs, so a third is refused, including
+	// when [key1] also controls its inputs.
+	err = m.AddTx(newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key0}))
+	var limitErr *AddressTxLimitError
+	require.ErrorAs(err, &limitErr)
+	require.Equal(&AddressTxLimitError{Address: key0.Address(), Limit: 2}, limitErr)
+	err = m.AddTx(newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key1, key0}))
+	require.ErrorAs(err, &limitErr)
+	require.Equal(key0.Address(), limitErr.Address)
+
+	// Other addresses are not limited.
+	require.NoError(m.AddTx(newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key1})))
+	require.NoError(m.AddTx(newSignedTestTx(t, 100, []*secp256k1.PrivateKey{testKeys[2]})))
+
+	// A tx replacing a tx of [key0] does not count it.
+	replacement := newSignedTestTx(t, 200, []*secp256k1.PrivateKey{key0}, utxoID)
+	require.NoError(m.AddTx(replacement))
+	require.False(m.Has(second.ID()))
+	require.Equal(2, m.addressTxs[key0.Address()])
+	require.Equal(1, m.addressTxs[key1.Address()])
+
+	// Forced txs are not limited, but are counted.
+	require.NoError(m.ForceAddTx(newSignedTestTx(t, 100, []*secp256k1.PrivateKey{key0})))
+	require.Equal(3, m.addressTxs[key0.Address()])
+}
+
+func TestMempoolAddressTxLimitRemoval(t *testing.T) {
+	require := require.New(t)
+	m, err := NewMempool(&snow.Context{}, prometheus.NewRegistry(), 10, 0, 1, nil)
+	require.NoError(err)
+
+	keys := []*secp256k1.PrivateKey{testKeys[0]}
+	address := testKeys[0].Address()
+
+	// The counter is decremented when the tx is accepted.
+	tx := newSignedTestTx(t, 100, keys)
+	require.NoError(m.AddTx(tx))
+	require.ErrorAs(m.AddTx(newSignedTestTx(t, 100, keys)), new(*AddressTxLimitError))
+	next, ok := m.NextTx()
+	require.True(ok)
+	require.Equal(tx, next)
+	m.IssueCurrentTxs()
+	require.Equal(1, m.addressTxs[address])
+	m.RemoveTx(tx)
+	require.NotContains(m.addressTxs, address)
+
+	// Removing a tx which is not in the mempool does not decrement it.
+	tx = newSignedTestTx(t, 100, keys)
+	require.NoError(m.AddTx(tx))
+	m.RemoveTx(newSignedTestTx(t, 100, keys))
+	require.Equal(1, m.addressTxs[address])
+
+	// The counter is decremented when the tx is discarded.
+	_, ok = m.NextTx()
+	require.True(ok)
+	m.DiscardCurrentTxs()
+	require.NotContains(m.addressTxs, address)
+	require.NotContains(m.txAddresses, tx.ID())
+	require.NoError(m.AddTx(newSignedTestTx(t, 100, keys)))
+}
+
+func TestIssueTxAddressTxLimitError(t *testing.T) {
+	require := require.New(t)
+
+	ctx := utils.TestSnowContext()
+	aliaser := ids.NewAliaser()
+	require.NoError(aliaser.Alias(ctx.ChainID, "C"))
+	ctx.BCLookup = aliaser
+	service := &AvaxAPI{&VM{ctx: ctx}}
+
+	address := testKeys[0].Address()
+	formatted, err := service.vm.FormatLocalAddress(address)
+	require.NoError(err)
+	err = service.issueTxError(&AddressTxLimitError{Address: address, Limit: 16})
+	var jsonErr *json2.Error
+	require.ErrorAs(err, &jsonErr)
+	require.Equal(addressTxLimitErrorCode, jsonErr.Code)
+	require.Contains(jsonErr.Message, formatted)
+	require.Equal(&AddressTxLimitErrorData{Address: formatted, Limit: 16}, jsonErr.Data)
+
+	// Other errors are returned as is.
+	require.Equal(errTooManyAtomicTx, service.issueTxError(errTooManyAtomicTx))
+}
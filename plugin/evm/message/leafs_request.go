@@ -5,16 +5,30 @@ package message
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
-const MaxCodeHashesPerRequest = 5
+const (
+	MaxCodeHashesPerRequest = 5
+
+	// leafsResponseProofOffset is the offset of ProofVals in a marshalled
+	// LeafsResponse without keys or values, following the codec version and
+	// the lengths of Keys and Vals.
+	leafsResponseProofOffset = codec.VersionSize + 2*wrappers.IntLen
+)
 
-var _ Request = LeafsRequest{}
+var (
+	_ Request = LeafsRequest{}
+
+	errLeafsResponseTooLarge = errors.New("leafs response too large")
+)
 
 // NodeType outlines the trie that a leaf node belongs to
 // handlers.LeafsRequestHandler uses this information to determine
@@ -96,3 +110,26 @@ type LeafsResponse struct {
 	// The keys for the proof are simply the keccak256 hashes of the values, so they are not included in the response to save bandwidth.
 	ProofVals [][]byte `serialize:"true"`
 }
+
+// AppendLeafsResponseProof returns a LeafsResponse marshalled with [c], given
+// [leafs], a LeafsResponse with the same keys and values and no ProofVals
+// marshalled with [c], and the [proofVals] of the response. The result is
+// identical to marshalling the complete response, so that the keys and values
+// can be marshalled while the proof is being generated.
+func AppendLeafsResponseProof(c codec.Manager, leafs []byte, proofVals [][]byte) ([]byte, error) {
+	proof, err := c.Marshal(Version, LeafsResponse{ProofVals: proofVals})
+	if err != nil {
+		return nil, err
+	}
+	// [leafs] ends with the length of its empty ProofVals, which is replaced
+	// by the ProofVals of [proof].
+	leafs = leafs[:len(leafs)-wrappers.IntLen]
+	proof = proof[leafsResponseProofOffset:]
+	size := len(leafs) + len(proof)
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("%w: %d > %d", errLeafsResponseTooLarge, size, maxMessageSize)
+	}
+	response := make([]byte, 0, size)
+	response = append(response, leafs...)
+	return append(response, proof...), nil
+}
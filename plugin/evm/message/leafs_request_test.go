@@ -100,6 +100,14 @@ func TestMarshalLeafsResponse(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, base64LeafsResponse, base64.StdEncoding.EncodeToString(leafsResponseBytes))
 
+	// Appending the proof to the marshalled keys and values must produce the
+	// same bytes.
+	leafsBytes, err := Codec.Marshal(Version, LeafsResponse{Keys: keysBytes, Vals: valsBytes})
+	assert.NoError(t, err)
+	appendedBytes, err := AppendLeafsResponseProof(Codec, leafsBytes, proofVals)
+	assert.NoError(t, err)
+	assert.Equal(t, leafsResponseBytes, appendedBytes)
+
 	var l LeafsResponse
 	_, err = Codec.Unmarshal(leafsResponseBytes, &l)
 	assert.NoError(t, err)
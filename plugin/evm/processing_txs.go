@@ -0,0 +1,170 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/miner"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	bloomfilter "github.com/holiman/bloomfilter/v2"
+)
+
+const (
+	// processingTxsRecentBlocks is the number of the closest processing
+	// ancestors of the preferred block whose transactions are kept in an
+	// exact set. The transactions of the deeper ancestors are looked up
+	// only if the bloom filter may contain them.
+	processingTxsRecentBlocks = 4
+	// processingTxsBloomBits and processingTxsBloomHashes size the bloom
+	// filter of the transactions of the processing ancestors.
+	processingTxsBloomBits   = 1 << 16
+	processingTxsBloomHashes = 4
+)
+
+var _ miner.ProcessingTxs = (*processingTxs)(nil)
+
+// txHasher is a hash.Hash64 for a transaction hash or atomic tx ID, which
+// are uniformly distributed.
+type txHasher common.Hash
+
+func (h txHasher) Write([]byte) (int, error) { panic("not implemented") }
+func (h txHasher) Sum([]byte) []byte         { panic("not implemented") }
+func (h txHasher) Reset()                    { panic("not implemented") }
+func (h txHasher) BlockSize() int            { panic("not implemented") }
+func (h txHasher) Size() int                 { return 8 }
+func (h txHasher) Sum64() uint64             { return binary.BigEndian.Uint64(h[:8]) }
+
+// processingBlock is a verified block which is neither accepted nor
+// rejected.
+type processingBlock struct {
+	parent common.Hash
+	// txs are the hashes of the transactions and the IDs of the atomic
+	// transactions of the block.
+	txs set.Set[common.Hash]
+}
+
+// processingTxs tracks the transactions of the processing blocks, so that
+// the blocks built on the preferred block do not include the transactions of
+// its processing ancestors, which would make them fail verification once the
+// ancestors are accepted.
+type processingTxs struct {
+	lock      sync.RWMutex
+	blocks    map[common.Hash]*processingBlock
+	preferred common.Hash
+
+	// recent are the transactions of the [processingTxsRecentBlocks] closest
+	// processing ancestors of the preferred block, including itself, and
+	// older are the deeper processing ancestors. bloom contains the
+	// transactions of all of them.
+	recent set.Set[common.Hash]
+	older  []*processingBlock
+	bloom  *bloomfilter.Filter
+}
+
+func newProcessingTxs() *processingTxs {
+	return &processingTxs{
+		blocks: make(map[common.Hash]*processingBlock),
+	}
+}
+
+// add tracks the transactions of [block], which started processing, with
+// its atomic transactions [atomicTxs].
+func (p *processingTxs) add(block *types.Block, atomicTxs []*Tx) {
+	txs := set.NewSet[common.Hash](len(block.Transactions()) + len(atomicTxs))
+	for _, tx := range block.Transactions() {
+		txs.Add(tx.Hash())
+	}
+	for _, tx := range atomicTxs {
+		txs.Add(common.Hash(tx.ID()))
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.blocks[block.Hash()] = &processingBlock{parent: block.ParentHash(), txs: txs}
+	p.update()
+}
+
+// remove stops tracking the transactions of the block with [hash], which was
+// accepted or rejected.
+func (p *processingTxs) remove(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.blocks[hash]; !ok {
+		return
+	}
+	delete(p.blocks, hash)
+	p.update()
+}
+
+// setPreference sets the block with [hash] as the preferred block, on top of
+// which the next block is built.
+func (p *processingTxs) setPreference(hash common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.preferred = hash
+	p.update()
+}
+
+// update recomputes the transactions of the processing ancestry of the
+// preferred block. The caller must hold [p.lock].
+func (p *processingTxs) update() {
+	p.recent = set.Set[common.Hash]{}
+	p.older = nil
+	p.bloom = nil
+
+	depth := 0
+	for block, ok := p.blocks[p.preferred]; ok; block, ok = p.blocks[block.parent] {
+		if depth < processingTxsRecentBlocks {
+			p.recent.Union(block.txs)
+		} else {
+			p.older = append(p.older, block)
+		}
+		depth++
+	}
+	if len(p.older) == 0 {
+		return
+	}
+
+	bloom, err := bloomfilter.New(processingTxsBloomBits, processingTxsBloomHashes)
+	if err != nil {
+		// The bloom filter is only an optimization, so the older ancestors
+		// are looked up without it.
+		log.Warn("Failed to create processing txs bloom filter", "err", err)
+		return
+	}
+	for _, block := range p.older {
+		for txHash := range block.txs {
+			bloom.Add(txHasher(txHash))
+		}
+	}
+	p.bloom = bloom
+}
+
+// Has implements miner.ProcessingTxs. [txHash] is either the hash of a
+// transaction or the ID of an atomic transaction.
+func (p *processingTxs) Has(txHash common.Hash) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.recent.Contains(txHash) {
+		return true
+	}
+	if p.bloom != nil && !p.bloom.Contains(txHasher(txHash)) {
+		return false
+	}
+	for _, block := range p.older {
+		if block.txs.Contains(txHash) {
+			return true
+		}
+	}
+	return false
+}
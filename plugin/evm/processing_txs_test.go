@@ -0,0 +1,148 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/miner"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// newProcessingTestBlock returns a block with [number] and [parent] holding a
+// transaction for each of [nonces].
+func newProcessingTestBlock(number int64, parent common.Hash, nonces ...uint64) *types.Block {
+	txs := make([]*types.Transaction, len(nonces))
+	for i, nonce := range nonces {
+		txs[i] = types.NewTransaction(nonce, common.Address{}, common.Big0, 0, common.Big0, nil)
+	}
+	header := &types.Header{Number: big.NewInt(number), ParentHash: parent}
+	return types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+}
+
+func TestProcessingTxs(t *testing.T) {
+	require := require.New(t)
+
+	p := newProcessingTxs()
+	// The chain of processing blocks is deeper than the recent blocks, so
+	// that the transactions of the first ones are in the bloom filter.
+	var (
+		blocks []*types.Block
+		parent common.Hash
+	)
+	for i := 0; i < processingTxsRecentBlocks+2; i++ {
+		block := newProcessingTestBlock(int64(i+1), parent, uint64(i))
+		p.add(block, nil)
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	sibling := newProcessingTestBlock(2, blocks[0].Hash(), 100)
+	p.add(sibling, nil)
+	atomicTx := &Tx{UnsignedAtomicTx: &TestUnsignedTx{IDV: ids.GenerateTestID()}}
+	atomicBlock := newProcessingTestBlock(3, sibling.Hash())
+	p.add(atomicBlock, []*Tx{atomicTx})
+
+	// Nothing is in the ancestry of the last accepted block.
+	for _, block := range append(blocks, sibling) {
+		require.False(p.Has(block.Transactions()[0].Hash()))
+	}
+
+	p.setPreference(parent)
+	require.NotNil(p.bloom)
+	for _, block := range blocks {
+		require.True(p.Has(block.Transactions()[0].Hash()))
+	}
+	require.False(p.Has(sibling.Transactions()[0].Hash()))
+	require.False(p.Has(common.Hash(atomicTx.ID())))
+
+	// Switching the preference to another branch drops the transactions of
+	// the previous one.
+	p.setPreference(atomicBlock.Hash())
+	require.Nil(p.bloom)
+	require.True(p.Has(blocks[0].Transactions()[0].Hash()))
+	require.True(p.Has(sibling.Transactions()[0].Hash()))
+	require.True(p.Has(common.Hash(atomicTx.ID())))
+	require.False(p.Has(blocks[1].Transactions()[0].Hash()))
+
+	// The accepted and rejected blocks are no longer processing.
+	p.remove(blocks[0].Hash())
+	p.remove(sibling.Hash())
+	require.False(p.Has(blocks[0].Transactions()[0].Hash()))
+	require.False(p.Has(sibling.Transactions()[0].Hash()))
+	require.True(p.Has(common.Hash(atomicTx.ID())))
+}
+
+func TestBuildBlockSkipsProcessingTxs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	importAmount := 100 * units.Avax
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+		testShortIDAddrs[1]: importAmount,
+		testShortIDAddrs[2]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(ctx))
+	}()
+
+	var importTxs []*Tx
+	for i, key := range testKeys {
+		importTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[i], initialBaseFee, []*secp256k1.PrivateKey{key})
+		require.NoError(err)
+		importTxs = append(importTxs, importTx)
+	}
+
+	// Build a chain of two processing blocks, each with one of the first
+	// import transactions.
+	var blks []*chain.BlockWrapper
+	for _, importTx := range importTxs[:2] {
+		require.NoError(vm.mempool.AddLocalTx(importTx))
+		<-issuer
+
+		blk, err := vm.BuildBlock(ctx)
+		require.NoError(err)
+		require.NoError(blk.Verify(ctx))
+		require.NoError(vm.SetPreference(ctx, blk.ID()))
+		blks = append(blks, blk.(*chain.BlockWrapper))
+	}
+
+	// The transactions of the processing blocks are added back to the
+	// mempool, as if they had been gossiped again, but are not included in
+	// the next block.
+	for _, importTx := range importTxs[:2] {
+		vm.mempool.RemoveTx(importTx)
+		require.NoError(vm.mempool.ForceAddTx(importTx))
+	}
+	require.NoError(vm.mempool.AddLocalTx(importTxs[2]))
+	<-issuer
+
+	blk3, err := vm.BuildBlock(ctx)
+	require.NoError(err)
+	require.Equal([]*Tx{importTxs[2]}, blk3.(*chain.BlockWrapper).Block.(*Block).atomicTxs)
+	require.NoError(blk3.Verify(ctx))
+
+	reports := vm.miner.BlockBuildingReports()
+	report := reports[len(reports)-1]
+	require.Equal([]string{importTxs[2].ID().String()}, report.Included)
+	require.ElementsMatch([]miner.SkippedTxReport{
+		{TxID: importTxs[0].ID().String(), Reason: miner.SkipProcessing},
+		{TxID: importTxs[1].ID().String(), Reason: miner.SkipProcessing},
+	}, report.Skipped)
+
+	// The transactions of the accepted blocks are no longer processing.
+	require.NoError(blks[0].Accept(ctx))
+	require.False(vm.processingTxs.Has(common.Hash(importTxs[0].ID())))
+	require.True(vm.processingTxs.Has(common.Hash(importTxs[1].ID())))
+}
@@ -5,10 +5,12 @@ package evm
 
 import (
 	"context"
+	stdjson "encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
+	"strconv"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/ids"
@@ -20,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/rpc/v2/json2"
 )
 
 // test constants
@@ -29,6 +32,11 @@ const (
 
 	// Max number of addresses that can be passed in as argument to GetUTXOs
 	maxGetUTXOsAddrs = 1024
+
+	// addressTxLimitErrorCode is the JSON-RPC error code returned when an
+	// address controlling the inputs of a transaction already has too many
+	// pending transactions in the atomic mempool.
+	addressTxLimitErrorCode json2.ErrorCode = -32010
 )
 
 var (
@@ -36,6 +44,10 @@ var (
 	errNoSourceChain     = errors.New("no source chain provided")
 	errNilTxID           = errors.New("nil transaction ID")
 	errMissingPrivateKey = errors.New("argument 'privateKey' not given")
+	// errUTXOSnapshotExpired is returned when the UTXOs may have changed
+	// since the first page of a getUTXOs query, so that the query must be
+	// restarted from the first page.
+	errUTXOSnapshotExpired = errors.New("UTXO snapshot expired")
 
 	initialBaseFee = big.NewInt(params.ApricotPhase3InitialBaseFee)
 )
@@ -187,49 +199,69 @@ func (service *AvaxAPI) ImportAVAX(_ *http.Request, args *ImportArgs, response *
 func (service *AvaxAPI) Import(_ *http.Request, args *ImportArgs, response *api.JSONTxID) error {
 	log.Info("EVM: ImportAVAX called")
 
-	chainID, err := service.vm.ctx.BCLookup.Lookup(args.SourceChain)
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	tx, _, err := service.buildImportTx(args)
 	if err != nil {
-		return fmt.Errorf("problem parsing chainID %q: %w", args.SourceChain, err)
+		return err
 	}
 
+	response.TxID = tx.ID()
+	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
+		return service.issueTxError(err)
+	}
+	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
+	return nil
+}
+
+// EstimateImportFee returns the fee of the transaction Import would issue for
+// [args], without issuing it.
+func (service *AvaxAPI) EstimateImportFee(_ *http.Request, args *ImportArgs, reply *AtomicTxFeeReply) error {
+	log.Info("EVM: EstimateImportFee called")
+
 	service.vm.ctx.Lock.Lock()
 	defer service.vm.ctx.Lock.Unlock()
 
+	tx, baseFee, err := service.buildImportTx(args)
+	if err != nil {
+		return err
+	}
+	return service.atomicTxFee(tx, baseFee, reply)
+}
+
+// buildImportTx creates, but does not issue, the import transaction described
+// by [args]. It returns the transaction and the base fee used to create it.
+// Assumes the ctx lock is held.
+func (service *AvaxAPI) buildImportTx(args *ImportArgs) (*Tx, *big.Int, error) {
+	chainID, err := service.vm.ctx.BCLookup.Lookup(args.SourceChain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem parsing chainID %q: %w", args.SourceChain, err)
+	}
+
 	// Get the user's info
 	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
-		return fmt.Errorf("couldn't get user '%s': %w", args.Username, err)
+		return nil, nil, fmt.Errorf("couldn't get user '%s': %w", args.Username, err)
 	}
 	defer db.Close()
 
 	user := user{db: db}
 	privKeys, err := user.getKeys()
 	if err != nil { // Get keys
-		return fmt.Errorf("couldn't get keys controlled by the user: %w", err)
+		return nil, nil, fmt.Errorf("couldn't get keys controlled by the user: %w", err)
 	}
 
-	var baseFee *big.Int
-	if args.BaseFee == nil {
-		// Get the base fee to use
-		baseFee, err = service.vm.estimateBaseFee(context.Background())
-		if err != nil {
-			return err
-		}
-	} else {
-		baseFee = args.BaseFee.ToInt()
+	baseFee, err := service.baseFeeOrEstimate(args.BaseFee)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	tx, err := service.vm.newImportTx(chainID, args.To, baseFee, privKeys)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-
-	response.TxID = tx.ID()
-	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
-		return err
-	}
-	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
-	return nil
+	return tx, baseFee, nil
 }
 
 // ExportAVAXArgs are the arguments to ExportAVAX
@@ -272,13 +304,48 @@ type ExportArgs struct {
 func (service *AvaxAPI) Export(_ *http.Request, args *ExportArgs, response *api.JSONTxID) error {
 	log.Info("EVM: Export called")
 
-	assetID, err := service.parseAssetID(args.AssetID)
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	tx, _, err := service.buildExportTx(args)
 	if err != nil {
 		return err
 	}
 
+	response.TxID = tx.ID()
+	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
+		return service.issueTxError(err)
+	}
+	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
+	return nil
+}
+
+// EstimateExportFee returns the fee of the transaction Export would issue for
+// [args], without issuing it.
+func (service *AvaxAPI) EstimateExportFee(_ *http.Request, args *ExportArgs, reply *AtomicTxFeeReply) error {
+	log.Info("EVM: EstimateExportFee called")
+
+	service.vm.ctx.Lock.Lock()
+	defer service.vm.ctx.Lock.Unlock()
+
+	tx, baseFee, err := service.buildExportTx(args)
+	if err != nil {
+		return err
+	}
+	return service.atomicTxFee(tx, baseFee, reply)
+}
+
+// buildExportTx creates, but does not issue, the export transaction described
+// by [args]. It returns the transaction and the base fee used to create it.
+// Assumes the ctx lock is held.
+func (service *AvaxAPI) buildExportTx(args *ExportArgs) (*Tx, *big.Int, error) {
+	assetID, err := service.parseAssetID(args.AssetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if args.Amount == 0 {
-		return errors.New("argument 'amount' must be > 0")
+		return nil, nil, errors.New("argument 'amount' must be > 0")
 	}
 
 	// Get the chainID and parse the to address
@@ -286,39 +353,30 @@ func (service *AvaxAPI) Export(_ *http.Request, args *ExportArgs, response *api.
 	if err != nil {
 		chainID, err = service.vm.ctx.BCLookup.Lookup(args.TargetChain)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		to, err = ids.ShortFromString(args.To)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
-	service.vm.ctx.Lock.Lock()
-	defer service.vm.ctx.Lock.Unlock()
-
 	// Get this user's data
 	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
-		return fmt.Errorf("problem retrieving user '%s': %w", args.Username, err)
+		return nil, nil, fmt.Errorf("problem retrieving user '%s': %w", args.Username, err)
 	}
 	defer db.Close()
 
 	user := user{db: db}
 	privKeys, err := user.getKeys()
 	if err != nil {
-		return fmt.Errorf("couldn't get addresses controlled by the user: %w", err)
+		return nil, nil, fmt.Errorf("couldn't get addresses controlled by the user: %w", err)
 	}
 
-	var baseFee *big.Int
-	if args.BaseFee == nil {
-		// Get the base fee to use
-		baseFee, err = service.vm.estimateBaseFee(context.Background())
-		if err != nil {
-			return err
-		}
-	} else {
-		baseFee = args.BaseFee.ToInt()
+	baseFee, err := service.baseFeeOrEstimate(args.BaseFee)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create the transaction
@@ -331,19 +389,102 @@ func (service *AvaxAPI) Export(_ *http.Request, args *ExportArgs, response *api.
 		privKeys, // Private keys
 	)
 	if err != nil {
-		return fmt.Errorf("couldn't create tx: %w", err)
+		return nil, nil, fmt.Errorf("couldn't create tx: %w", err)
 	}
+	return tx, baseFee, nil
+}
 
-	response.TxID = tx.ID()
-	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
+// baseFeeOrEstimate returns [baseFee] if it was specified, and otherwise the
+// base fee estimated by the node with some breathing room.
+func (service *AvaxAPI) baseFeeOrEstimate(baseFee *hexutil.Big) (*big.Int, error) {
+	if baseFee != nil {
+		return baseFee.ToInt(), nil
+	}
+	return service.vm.estimateBaseFee(context.Background())
+}
+
+// EstimateBaseFeeReply is the response from EstimateBaseFee
+type EstimateBaseFeeReply struct {
+	// BaseFee is nil prior to Apricot Phase 3, when atomic transactions pay a
+	// fixed fee.
+	BaseFee *hexutil.Big `json:"baseFee"`
+}
+
+// EstimateBaseFee returns the node's estimate of the base fee of the next
+// block, without the breathing room added when issuing atomic transactions.
+func (service *AvaxAPI) EstimateBaseFee(_ *http.Request, _ *struct{}, reply *EstimateBaseFeeReply) error {
+	log.Info("EVM: EstimateBaseFee called")
+
+	baseFee, err := service.vm.eth.APIBackend.EstimateBaseFee(context.Background())
+	if err != nil {
 		return err
 	}
-	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
+	reply.BaseFee = (*hexutil.Big)(baseFee)
+	return nil
+}
+
+// AtomicTxFeeReply is the response from EstimateImportFee and EstimateExportFee
+type AtomicTxFeeReply struct {
+	// GasUsed by the transaction.
+	GasUsed json.Uint64 `json:"gasUsed"`
+	// BaseFee the transaction was created with. This is nil prior to Apricot
+	// Phase 3.
+	BaseFee *hexutil.Big `json:"baseFee"`
+	// Fee paid by the transaction, denominated in nAVAX.
+	Fee json.Uint64 `json:"fee"`
+}
+
+// atomicTxFee populates [reply] with the gas used by [tx] and the AVAX it
+// burns, given that it was created with [baseFee].
+func (service *AvaxAPI) atomicTxFee(tx *Tx, baseFee *big.Int, reply *AtomicTxFeeReply) error {
+	rules := service.vm.currentRules()
+	gasUsed, err := tx.GasUsed(rules.IsApricotPhase5)
+	if err != nil {
+		return err
+	}
+	fee, err := tx.Burned(service.vm.ctx.AVAXAssetID)
+	if err != nil {
+		return err
+	}
+	reply.GasUsed = json.Uint64(gasUsed)
+	reply.Fee = json.Uint64(fee)
+	if rules.IsApricotPhase3 {
+		reply.BaseFee = (*hexutil.Big)(baseFee)
+	}
 	return nil
 }
 
+// GetUTXOsArgs are the arguments for GetUTXOs
+type GetUTXOsArgs struct {
+	api.GetUTXOsArgs
+	// ConsistencyToken is the token returned with the previous page, if any.
+	ConsistencyToken string `json:"consistencyToken"`
+}
+
+// GetUTXOsReply is the response for GetUTXOs
+type GetUTXOsReply struct {
+	api.GetUTXOsReply
+	// ConsistencyToken must be passed with the request of the next page, so
+	// that it is served from the same UTXOs.
+	ConsistencyToken string `json:"consistencyToken"`
+}
+
+// utxosConsistencyToken returns the consistency token of the UTXOs read at the
+// last accepted [height].
+func utxosConsistencyToken(height uint64) string {
+	return strconv.FormatUint(height, 10)
+}
+
 // GetUTXOs gets all utxos for passed in addresses
-func (service *AvaxAPI) GetUTXOs(r *http.Request, args *api.GetUTXOsArgs, reply *api.GetUTXOsReply) error {
+//
+// The reply holds a consistency token, which is the last accepted height at
+// which the UTXOs were read. The atomic UTXOs of this chain are only spent by
+// accepting blocks, and there is no index of the UTXOs at past heights, so a
+// page requested with the token of a previous page fails with
+// [errUTXOSnapshotExpired] if a block was accepted since, and the query must
+// be restarted. UTXOs exported to this chain between pages are returned only
+// if they are after the start index of the next page.
+func (service *AvaxAPI) GetUTXOs(r *http.Request, args *GetUTXOsArgs, reply *GetUTXOsReply) error {
 	log.Info("EVM: GetUTXOs called", "Addresses", args.Addresses)
 
 	if len(args.Addresses) == 0 {
@@ -388,6 +529,19 @@ func (service *AvaxAPI) GetUTXOs(r *http.Request, args *api.GetUTXOsArgs, reply
 	service.vm.ctx.Lock.Lock()
 	defer service.vm.ctx.Lock.Unlock()
 
+	// Blocks are accepted while holding the context lock, so the UTXOs read
+	// below are those at [height].
+	height := service.vm.blockChain.LastAcceptedBlock().NumberU64()
+	if args.ConsistencyToken != "" {
+		tokenHeight, err := strconv.ParseUint(args.ConsistencyToken, 10, 64)
+		if err != nil {
+			return fmt.Errorf("couldn't parse consistency token %q: %w", args.ConsistencyToken, err)
+		}
+		if tokenHeight != height {
+			return fmt.Errorf("%w: blocks were accepted since height %d, restart from the first page", errUTXOSnapshotExpired, tokenHeight)
+		}
+	}
+
 	utxos, endAddr, endUTXOID, err := service.vm.GetAtomicUTXOs(
 		sourceChain,
 		addrSet,
@@ -421,6 +575,7 @@ func (service *AvaxAPI) GetUTXOs(r *http.Request, args *api.GetUTXOsArgs, reply
 	reply.EndIndex.UTXO = endUTXOID.String()
 	reply.NumFetched = json.Uint64(len(utxos))
 	reply.Encoding = args.Encoding
+	reply.ConsistencyToken = utxosConsistencyToken(height)
 	return nil
 }
 
@@ -446,12 +601,47 @@ func (service *AvaxAPI) IssueTx(r *http.Request, args *api.FormattedTx, response
 	defer service.vm.ctx.Lock.Unlock()
 
 	if err := service.vm.mempool.AddLocalTx(tx); err != nil {
-		return err
+		return service.issueTxError(err)
 	}
 	service.vm.atomicTxPushGossiper.Add(&GossipAtomicTx{tx})
 	return nil
 }
 
+// AddressTxLimitErrorData is the data of the JSON-RPC error returned when an
+// address controlling the inputs of a transaction already has too many
+// pending transactions in the atomic mempool.
+type AddressTxLimitErrorData struct {
+	Address string      `json:"address"`
+	Limit   json.Uint32 `json:"limit"`
+}
+
+// issueTxError returns the error returned by the API when adding a
+// transaction to the atomic mempool fails with [err]. Hitting the limit of
+// pending transactions of an address is reported as a JSON-RPC error
+// identifying the address by its formatted form.
+func (service *AvaxAPI) issueTxError(err error) error {
+	var limitErr *AddressTxLimitError
+	if !errors.As(err, &limitErr) {
+		return err
+	}
+	address, fmtErr := service.vm.FormatLocalAddress(limitErr.Address)
+	if fmtErr != nil {
+		address = limitErr.Address.String()
+	}
+	return &json2.Error{
+		Code: addressTxLimitErrorCode,
+		Message: fmt.Sprintf(
+			"address %s already has %d pending atomic txs, wait for them to be accepted before issuing more",
+			address,
+			limitErr.Limit,
+		),
+		Data: &AddressTxLimitErrorData{
+			Address: address,
+			Limit:   json.Uint32(limitErr.Limit),
+		},
+	}
+}
+
 // GetAtomicTxStatusReply defines the GetAtomicTxStatus replies returned from the API
 type GetAtomicTxStatusReply struct {
 	Status      Status       `json:"status"`
@@ -488,9 +678,25 @@ func (service *AvaxAPI) GetAtomicTxStatus(r *http.Request, args *api.JSONTxID, r
 	return nil
 }
 
+// FormattedTx is a tx returned by the API. If the requested encoding is
+// [formatting.JSON], [Tx] is the canonical JSON of the tx. Otherwise, it is a
+// string of the encoded bytes of the tx.
 type FormattedTx struct {
-	api.FormattedTx
-	BlockHeight *json.Uint64 `json:"blockHeight,omitempty"`
+	Tx          stdjson.RawMessage  `json:"tx"`
+	Encoding    formatting.Encoding `json:"encoding"`
+	BlockHeight *json.Uint64        `json:"blockHeight,omitempty"`
+}
+
+// formatTx returns [tx] in [encoding], as the Tx of a FormattedTx.
+func formatTx(tx *Tx, encoding formatting.Encoding) (stdjson.RawMessage, error) {
+	if encoding == formatting.JSON {
+		return stdjson.Marshal(tx)
+	}
+	txBytes, err := formatting.Encode(encoding, tx.SignedBytes())
+	if err != nil {
+		return nil, err
+	}
+	return stdjson.Marshal(txBytes)
 }
 
 // GetAtomicTx returns the specified transaction
@@ -513,11 +719,10 @@ func (service *AvaxAPI) GetAtomicTx(r *http.Request, args *api.GetTxArgs, reply
 		return fmt.Errorf("could not find tx %s", args.TxID)
 	}
 
-	txBytes, err := formatting.Encode(args.Encoding, tx.SignedBytes())
+	reply.Tx, err = formatTx(tx, args.Encoding)
 	if err != nil {
 		return err
 	}
-	reply.Tx = txBytes
 	reply.Encoding = args.Encoding
 	if status == Accepted {
 		// Since chain state updates run asynchronously with VM block acceptance,
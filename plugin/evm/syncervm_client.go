@@ -16,6 +16,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/components/chain"
 	"github.com/ava-labs/coreth/core/rawdb"
 	"github.com/ava-labs/coreth/core/state/snapshot"
+	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/eth"
 	"github.com/ava-labs/coreth/params"
 	"github.com/ava-labs/coreth/plugin/evm/message"
@@ -46,6 +47,14 @@ type stateSyncClientConfig struct {
 
 	lastAcceptedHeight uint64
 
+	// verifyConfig configures the verification pass run after a successful
+	// state sync, or is nil if the pass is disabled. The database, root and
+	// accounts to check are filled in by the client.
+	verifyConfig *statesync.VerifierConfig
+	// verifyRecentBlocks is the number of blocks, ending with the synced
+	// block, whose touched accounts are checked by the verification pass.
+	verifyRecentBlocks uint64
+
 	chain           *eth.Ethereum
 	state           *chain.State
 	chaindb         ethdb.Database
@@ -214,6 +223,11 @@ func (client *stateSyncerClient) acceptSyncSummary(proposedSummary message.SyncS
 		} else {
 			client.stateSyncErr = client.finishSync()
 		}
+		// Verify the synced state before notifying the engine, since the
+		// snapshot is modified by the blocks processed afterwards.
+		if client.stateSyncErr == nil && client.verifyConfig != nil {
+			client.verifySyncedState(ctx)
+		}
 		// notify engine regardless of whether err == nil,
 		// this error will be propagated to the engine when it calls
 		// vm.SetState(snow.Bootstrapping)
@@ -310,6 +324,60 @@ func (client *stateSyncerClient) syncStateTrie(ctx context.Context) error {
 	return err
 }
 
+// verifySyncedState cross-checks the synced snapshot against the synced trie
+// and logs any mismatches. It returns early if [ctx] is cancelled.
+func (client *stateSyncerClient) verifySyncedState(ctx context.Context) {
+	config := *client.verifyConfig
+	config.DB = client.chaindb
+	config.Root = client.syncSummary.BlockRoot
+	config.Accounts = client.recentAccounts(client.verifyRecentBlocks)
+
+	log.Info("state sync: verification starting", "root", config.Root, "recentAccounts", len(config.Accounts))
+	mismatches, err := statesync.VerifySyncedState(ctx, &config)
+	if err != nil {
+		log.Warn("state sync: verification did not complete", "root", config.Root, "err", err)
+		return
+	}
+	for _, mismatch := range mismatches {
+		log.Error("state sync: snapshot does not match trie", "mismatch", mismatch)
+	}
+	log.Info("state sync: verification finished", "root", config.Root, "mismatches", len(mismatches))
+}
+
+// recentAccounts returns the senders, recipients and coinbases of the
+// transactions in the [numBlocks] blocks ending with the synced block, which
+// are available on disk after [syncBlocks].
+func (client *stateSyncerClient) recentAccounts(numBlocks uint64) []common.Address {
+	var (
+		chainConfig = client.chain.BlockChain().Config()
+		hash        = client.syncSummary.BlockHash
+		number      = client.syncSummary.BlockNumber
+		accounts    []common.Address
+	)
+	for i := uint64(0); i < numBlocks; i++ {
+		block := rawdb.ReadBlock(client.chaindb, hash, number)
+		if block == nil {
+			break
+		}
+		accounts = append(accounts, block.Coinbase())
+		signer := types.MakeSigner(chainConfig, block.Number(), block.Time())
+		for _, tx := range block.Transactions() {
+			if from, err := types.Sender(signer, tx); err == nil {
+				accounts = append(accounts, from)
+			}
+			if to := tx.To(); to != nil {
+				accounts = append(accounts, *to)
+			}
+		}
+		if number == 0 {
+			break
+		}
+		hash = block.ParentHash()
+		number--
+	}
+	return accounts
+}
+
 func (client *stateSyncerClient) Shutdown() error {
 	if client.cancel != nil {
 		client.cancel()
@@ -20,6 +20,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type TestUnsignedTx struct {
@@ -32,6 +33,7 @@ type TestUnsignedTx struct {
 	UnsignedBytesV              []byte
 	SignedBytesV                []byte
 	InputUTXOsV                 set.Set[ids.ID]
+	EVMAddressesV               []common.Address `serialize:"true"`
 	SemanticVerifyV             error
 	EVMStateTransferV           error
 }
@@ -67,6 +69,11 @@ func (t *TestUnsignedTx) SignedBytes() []byte { return t.SignedBytesV }
 // InputUTXOs implements the UnsignedAtomicTx interface
 func (t *TestUnsignedTx) InputUTXOs() set.Set[ids.ID] { return t.InputUTXOsV }
 
+// EVMAddresses implements the UnsignedAtomicTx interface
+func (t *TestUnsignedTx) EVMAddresses() set.Set[common.Address] {
+	return set.Of(t.EVMAddressesV...)
+}
+
 // SemanticVerify implements the UnsignedAtomicTx interface
 func (t *TestUnsignedTx) SemanticVerify(vm *VM, stx *Tx, parent *Block, baseFee *big.Int, rules params.Rules) error {
 	return t.SemanticVerifyV
@@ -39,6 +39,11 @@ var (
 	errEmptyAssetID      = errors.New("empty asset ID is not valid")
 	errNilBaseFee        = errors.New("cannot calculate dynamic fee with nil baseFee")
 	errFeeOverflow       = errors.New("overflow occurred while calculating the fee")
+
+	errAtomicTxTooLarge           = errors.New("atomic tx exceeds the maximum size")
+	errTooManyAtomicTxInputs      = errors.New("atomic tx exceeds the maximum number of inputs")
+	errTooManyAtomicTxOutputs     = errors.New("atomic tx exceeds the maximum number of outputs")
+	errTooManyAtomicTxCredentials = errors.New("atomic tx exceeds the maximum number of credentials")
 )
 
 // Constants for calculating the gas consumed by atomic transactions
@@ -121,6 +126,8 @@ type UnsignedAtomicTx interface {
 
 	// InputUTXOs returns the UTXOs this tx consumes
 	InputUTXOs() set.Set[ids.ID]
+	// EVMAddresses returns the EVM addresses this tx sends funds from or to
+	EVMAddresses() set.Set[common.Address]
 	// Verify attempts to verify that the transaction is well formed
 	Verify(ctx *snow.Context, rules params.Rules) error
 	// Attempts to verify this transaction with the provided state.
@@ -263,6 +270,35 @@ func CalculateDynamicFee(cost uint64, baseFee *big.Int) (uint64, error) {
 	return feeInNAVAX.Uint64(), nil
 }
 
+// verifyAtomicTxLimits verifies that [tx] does not exceed the limits on the
+// contents of atomic transactions, if they are enforced by [rules]. It is
+// applied at every point where an atomic tx is accepted, so that a tx is
+// rejected for the same reason when it is issued, gossiped, added to the
+// mempool or verified in a block.
+func verifyAtomicTxLimits(tx *Tx, rules params.Rules) error {
+	if !rules.IsAtomicTxLimits {
+		return nil
+	}
+	var numInputs, numOutputs int
+	switch utx := tx.UnsignedAtomicTx.(type) {
+	case *UnsignedImportTx:
+		numInputs, numOutputs = len(utx.ImportedInputs), len(utx.Outs)
+	case *UnsignedExportTx:
+		numInputs, numOutputs = len(utx.Ins), len(utx.ExportedOutputs)
+	}
+	switch size := len(tx.SignedBytes()); {
+	case size > params.AtomicTxMaxSize:
+		return fmt.Errorf("%w: size (%d) > max (%d)", errAtomicTxTooLarge, size, params.AtomicTxMaxSize)
+	case numInputs > params.AtomicTxMaxInputs:
+		return fmt.Errorf("%w: inputs (%d) > max (%d)", errTooManyAtomicTxInputs, numInputs, params.AtomicTxMaxInputs)
+	case numOutputs > params.AtomicTxMaxOutputs:
+		return fmt.Errorf("%w: outputs (%d) > max (%d)", errTooManyAtomicTxOutputs, numOutputs, params.AtomicTxMaxOutputs)
+	case len(tx.Creds) > params.AtomicTxMaxCredentials:
+		return fmt.Errorf("%w: credentials (%d) > max (%d)", errTooManyAtomicTxCredentials, len(tx.Creds), params.AtomicTxMaxCredentials)
+	}
+	return nil
+}
+
 func calcBytesCost(len int) uint64 {
 	return uint64(len) * TxBytesGas
 }
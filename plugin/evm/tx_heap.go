@@ -10,15 +10,17 @@ import (
 )
 
 // txEntry is used to track the [gasPrice] transactions pay to be included in
-// the mempool.
+// a block, and the [feeRate] they pay to be kept in the mempool.
 type txEntry struct {
 	id       ids.ID
 	gasPrice uint64
+	feeRate  uint64
 	tx       *Tx
 	index    int
 }
 
-// internalTxHeap is used to track pending atomic transactions by [gasPrice]
+// internalTxHeap is used to track pending atomic transactions by [feeRate]
+// if it is a min heap, and by [gasPrice] otherwise.
 type internalTxHeap struct {
 	isMinHeap bool
 	items     []*txEntry
@@ -37,7 +39,7 @@ func (th internalTxHeap) Len() int { return len(th.items) }
 
 func (th internalTxHeap) Less(i, j int) bool {
 	if th.isMinHeap {
-		return th.items[i].gasPrice < th.items[j].gasPrice
+		return th.items[i].feeRate < th.items[j].feeRate
 	}
 	return th.items[i].gasPrice > th.items[j].gasPrice
 }
@@ -79,6 +81,9 @@ func (th *internalTxHeap) Has(id ids.ID) bool {
 	return has
 }
 
+// txHeap tracks the pending atomic transactions by the [gasPrice] they pay,
+// which orders their inclusion in blocks, and by the [feeRate] they pay,
+// which orders their eviction from the mempool.
 type txHeap struct {
 	maxHeap *internalTxHeap
 	minHeap *internalTxHeap
@@ -91,18 +96,20 @@ func newTxHeap(maxSize int) *txHeap {
 	}
 }
 
-func (th *txHeap) Push(tx *Tx, gasPrice uint64) {
+func (th *txHeap) Push(tx *Tx, gasPrice uint64, feeRate uint64) {
 	txID := tx.ID()
 	oldLen := th.Len()
 	heap.Push(th.maxHeap, &txEntry{
 		id:       txID,
 		gasPrice: gasPrice,
+		feeRate:  feeRate,
 		tx:       tx,
 		index:    oldLen,
 	})
 	heap.Push(th.minHeap, &txEntry{
 		id:       txID,
 		gasPrice: gasPrice,
+		feeRate:  feeRate,
 		tx:       tx,
 		index:    oldLen,
 	})
@@ -114,10 +121,12 @@ func (th *txHeap) PeekMax() (*Tx, uint64) {
 	return txEntry.tx, txEntry.gasPrice
 }
 
+// PeekMin returns the transaction with the lowest [feeRate] and its
+// [feeRate].
 // Assumes there is non-zero items in [txHeap]
 func (th *txHeap) PeekMin() (*Tx, uint64) {
 	txEntry := th.minHeap.items[0]
-	return txEntry.tx, txEntry.gasPrice
+	return txEntry.tx, txEntry.feeRate
 }
 
 // Assumes there is non-zero items in [txHeap]
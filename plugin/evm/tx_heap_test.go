@@ -45,7 +45,7 @@ func TestTxHeap(t *testing.T) {
 		assert.Zero(t, h.Len())
 
 		assert := assert.New(t)
-		h.Push(tx0, 5)
+		h.Push(tx0, 5, 5)
 		assert.True(h.Has(id0))
 		gTx0, gHas0 := h.Get(id0)
 		assert.Equal(tx0, gTx0)
@@ -53,7 +53,7 @@ func TestTxHeap(t *testing.T) {
 		h.Remove(id0)
 		assert.False(h.Has(id0))
 		assert.Zero(h.Len())
-		h.Push(tx0, 5)
+		h.Push(tx0, 5, 5)
 		assert.True(h.Has(id0))
 		assert.Equal(1, h.Len())
 	})
@@ -63,13 +63,13 @@ func TestTxHeap(t *testing.T) {
 		assert.Zero(t, h.Len())
 
 		assert := assert.New(t)
-		h.Push(tx1, 10)
+		h.Push(tx1, 10, 10)
 		assert.True(h.Has(id1))
 		gTx1, gHas1 := h.Get(id1)
 		assert.Equal(tx1, gTx1)
 		assert.True(gHas1)
 
-		h.Push(tx2, 2)
+		h.Push(tx2, 2, 2)
 		assert.True(h.Has(id2))
 		gTx2, gHas2 := h.Get(id2)
 		assert.Equal(tx2, gTx2)
@@ -116,27 +116,45 @@ func TestTxHeap(t *testing.T) {
 		h := newTxHeap(3)
 		assert.Zero(t, h.Len())
 
-		h.Push(tx0, 5)
-		h.Push(tx1, 10)
-		h.Push(tx2, 2)
+		h.Push(tx0, 5, 5)
+		h.Push(tx1, 10, 10)
+		h.Push(tx2, 2, 2)
 		verifyRemovalOrder(t, h)
 	})
 	t.Run("drop (alt order)", func(t *testing.T) {
 		h := newTxHeap(3)
 		assert.Zero(t, h.Len())
 
-		h.Push(tx0, 5)
-		h.Push(tx2, 2)
-		h.Push(tx1, 10)
+		h.Push(tx0, 5, 5)
+		h.Push(tx2, 2, 2)
+		h.Push(tx1, 10, 10)
 		verifyRemovalOrder(t, h)
 	})
 	t.Run("drop (alt order 2)", func(t *testing.T) {
 		h := newTxHeap(3)
 		assert.Zero(t, h.Len())
 
-		h.Push(tx2, 2)
-		h.Push(tx0, 5)
-		h.Push(tx1, 10)
+		h.Push(tx2, 2, 2)
+		h.Push(tx0, 5, 5)
+		h.Push(tx1, 10, 10)
 		verifyRemovalOrder(t, h)
 	})
+	t.Run("fee rate order", func(t *testing.T) {
+		h := newTxHeap(3)
+		assert := assert.New(t)
+
+		// The transactions are included by gas price and dropped by fee rate.
+		h.Push(tx0, 5, 10)
+		h.Push(tx1, 10, 5)
+		h.Push(tx2, 2, 2)
+		maxTx, maxGasPrice := h.PeekMax()
+		assert.Equal(id1, maxTx.ID())
+		assert.Equal(uint64(10), maxGasPrice)
+		minTx, minFeeRate := h.PeekMin()
+		assert.Equal(id2, minTx.ID())
+		assert.Equal(uint64(2), minFeeRate)
+		assert.Equal(id2, h.PopMin().ID())
+		assert.Equal(id1, h.PopMin().ID())
+		assert.Equal(id0, h.PopMin().ID())
+	})
 }
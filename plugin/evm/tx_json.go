@@ -0,0 +1,201 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/formatting/address"
+	avajson "github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	_ json.Marshaler = (*Tx)(nil)
+	_ json.Marshaler = (*UnsignedImportTx)(nil)
+	_ json.Marshaler = (*UnsignedExportTx)(nil)
+)
+
+// txJSONVersion is the version of the canonical JSON of atomic txs. It must
+// be incremented whenever a field is removed or changes meaning, so that
+// external tools can detect the change.
+const txJSONVersion = 1
+
+// Types of atomic txs in their canonical JSON.
+const (
+	importTxJSONType = "import"
+	exportTxJSONType = "export"
+)
+
+// txJSON is the canonical JSON of a Tx. Amounts are strings, so that they are
+// not truncated by JSON parsers using floating point numbers.
+type txJSON struct {
+	Version     int              `json:"version"`
+	ID          ids.ID           `json:"id"`
+	UnsignedTx  UnsignedAtomicTx `json:"unsignedTx"`
+	Credentials []credentialJSON `json:"credentials"`
+}
+
+// credentialJSON summarizes a credential of a Tx.
+type credentialJSON struct {
+	Type       string         `json:"type"`
+	Signatures avajson.Uint32 `json:"signatures"`
+}
+
+type importTxJSON struct {
+	Type           string              `json:"type"`
+	NetworkID      avajson.Uint32      `json:"networkID"`
+	BlockchainID   ids.ID              `json:"blockchainID"`
+	SourceChain    ids.ID              `json:"sourceChain"`
+	ImportedInputs []importedInputJSON `json:"importedInputs"`
+	Outputs        []evmOutputJSON     `json:"outputs"`
+}
+
+type exportTxJSON struct {
+	Type             string               `json:"type"`
+	NetworkID        avajson.Uint32       `json:"networkID"`
+	BlockchainID     ids.ID               `json:"blockchainID"`
+	DestinationChain ids.ID               `json:"destinationChain"`
+	Inputs           []evmInputJSON       `json:"inputs"`
+	ExportedOutputs  []exportedOutputJSON `json:"exportedOutputs"`
+}
+
+type importedInputJSON struct {
+	TxID        ids.ID           `json:"txID"`
+	OutputIndex avajson.Uint32   `json:"outputIndex"`
+	AssetID     ids.ID           `json:"assetID"`
+	Amount      avajson.Uint64   `json:"amount"`
+	SigIndices  []avajson.Uint32 `json:"sigIndices"`
+}
+
+type exportedOutputJSON struct {
+	AssetID   ids.ID         `json:"assetID"`
+	Amount    avajson.Uint64 `json:"amount"`
+	Locktime  avajson.Uint64 `json:"locktime"`
+	Threshold avajson.Uint32 `json:"threshold"`
+	Addresses []addressJSON  `json:"addresses"`
+}
+
+// addressJSON is an address of the owners of an exported output, both in
+// bech32, without a chain prefix, and in hex.
+type addressJSON struct {
+	Bech32 string `json:"bech32"`
+	Hex    string `json:"hex"`
+}
+
+type evmOutputJSON struct {
+	Address common.Address `json:"address"`
+	Amount  avajson.Uint64 `json:"amount"`
+	AssetID ids.ID         `json:"assetID"`
+}
+
+type evmInputJSON struct {
+	Address common.Address `json:"address"`
+	Amount  avajson.Uint64 `json:"amount"`
+	AssetID ids.ID         `json:"assetID"`
+	Nonce   avajson.Uint64 `json:"nonce"`
+}
+
+// MarshalJSON returns the canonical JSON of [tx], with its version, ID,
+// unsigned tx and a summary of its credentials.
+func (tx *Tx) MarshalJSON() ([]byte, error) {
+	creds := make([]credentialJSON, len(tx.Creds))
+	for i, cred := range tx.Creds {
+		switch cred := cred.(type) {
+		case *secp256k1fx.Credential:
+			creds[i] = credentialJSON{Type: "secp256k1fx", Signatures: avajson.Uint32(len(cred.Sigs))}
+		default:
+			creds[i] = credentialJSON{Type: fmt.Sprintf("%T", cred)}
+		}
+	}
+	return json.Marshal(txJSON{
+		Version:     txJSONVersion,
+		ID:          tx.ID(),
+		UnsignedTx:  tx.UnsignedAtomicTx,
+		Credentials: creds,
+	})
+}
+
+// MarshalJSON returns the canonical JSON of [utx].
+func (utx *UnsignedImportTx) MarshalJSON() ([]byte, error) {
+	ins := make([]importedInputJSON, len(utx.ImportedInputs))
+	for i, in := range utx.ImportedInputs {
+		ins[i] = importedInputJSON{
+			TxID:        in.TxID,
+			OutputIndex: avajson.Uint32(in.OutputIndex),
+			AssetID:     in.AssetID(),
+			Amount:      avajson.Uint64(in.In.Amount()),
+			SigIndices:  []avajson.Uint32{},
+		}
+		if in, ok := in.In.(*secp256k1fx.TransferInput); ok {
+			for _, index := range in.SigIndices {
+				ins[i].SigIndices = append(ins[i].SigIndices, avajson.Uint32(index))
+			}
+		}
+	}
+	outs := make([]evmOutputJSON, len(utx.Outs))
+	for i, out := range utx.Outs {
+		outs[i] = evmOutputJSON{
+			Address: out.Address,
+			Amount:  avajson.Uint64(out.Amount),
+			AssetID: out.AssetID,
+		}
+	}
+	return json.Marshal(importTxJSON{
+		Type:           importTxJSONType,
+		NetworkID:      avajson.Uint32(utx.NetworkID),
+		BlockchainID:   utx.BlockchainID,
+		SourceChain:    utx.SourceChain,
+		ImportedInputs: ins,
+		Outputs:        outs,
+	})
+}
+
+// MarshalJSON returns the canonical JSON of [utx].
+func (utx *UnsignedExportTx) MarshalJSON() ([]byte, error) {
+	ins := make([]evmInputJSON, len(utx.Ins))
+	for i, in := range utx.Ins {
+		ins[i] = evmInputJSON{
+			Address: in.Address,
+			Amount:  avajson.Uint64(in.Amount),
+			AssetID: in.AssetID,
+			Nonce:   avajson.Uint64(in.Nonce),
+		}
+	}
+	hrp := constants.GetHRP(utx.NetworkID)
+	outs := make([]exportedOutputJSON, len(utx.ExportedOutputs))
+	for i, out := range utx.ExportedOutputs {
+		outs[i] = exportedOutputJSON{
+			AssetID:   out.AssetID(),
+			Amount:    avajson.Uint64(out.Out.Amount()),
+			Addresses: []addressJSON{},
+		}
+		if out, ok := out.Out.(*secp256k1fx.TransferOutput); ok {
+			outs[i].Locktime = avajson.Uint64(out.Locktime)
+			outs[i].Threshold = avajson.Uint32(out.Threshold)
+			for _, addr := range out.Addrs {
+				bech32, err := address.FormatBech32(hrp, addr[:])
+				if err != nil {
+					return nil, err
+				}
+				outs[i].Addresses = append(outs[i].Addresses, addressJSON{
+					Bech32: bech32,
+					Hex:    common.BytesToAddress(addr[:]).Hex(),
+				})
+			}
+		}
+	}
+	return json.Marshal(exportTxJSON{
+		Type:             exportTxJSONType,
+		NetworkID:        avajson.Uint32(utx.NetworkID),
+		BlockchainID:     utx.BlockchainID,
+		DestinationChain: utx.DestinationChain,
+		Inputs:           ins,
+		ExportedOutputs:  outs,
+	})
+}
@@ -0,0 +1,168 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// newTxJSONFixtures returns an import and an export tx signed by a fixed key.
+func newTxJSONFixtures(t testing.TB) (*Tx, *Tx) {
+	key, err := secp256k1.ToPrivateKey(common.LeftPadBytes([]byte{1}, secp256k1.PrivateKeyLen))
+	require.NoError(t, err)
+
+	importTx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+		NetworkID:    constants.UnitTestID,
+		BlockchainID: ids.ID{1},
+		SourceChain:  ids.ID{2},
+		ImportedInputs: []*avax.TransferableInput{{
+			UTXOID: avax.UTXOID{TxID: ids.ID{3}, OutputIndex: 1},
+			Asset:  avax.Asset{ID: ids.ID{4}},
+			In: &secp256k1fx.TransferInput{
+				Amt:   5_000_000,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}},
+		Outs: []EVMOutput{{
+			Address: common.Address{0x5},
+			Amount:  4_000_000,
+			AssetID: ids.ID{4},
+		}},
+	}}
+	require.NoError(t, importTx.Sign(Codec, [][]*secp256k1.PrivateKey{{key}}))
+
+	exportTx := &Tx{UnsignedAtomicTx: &UnsignedExportTx{
+		NetworkID:        constants.UnitTestID,
+		BlockchainID:     ids.ID{1},
+		DestinationChain: ids.ID{2},
+		Ins: []EVMInput{{
+			Address: common.Address{0x5},
+			Amount:  5_000_000,
+			AssetID: ids.ID{4},
+			Nonce:   3,
+		}},
+		ExportedOutputs: []*avax.TransferableOutput{{
+			Asset: avax.Asset{ID: ids.ID{4}},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 4_000_000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{{0x7}},
+				},
+			},
+		}},
+	}}
+	require.NoError(t, exportTx.Sign(Codec, [][]*secp256k1.PrivateKey{{key}}))
+	return importTx, exportTx
+}
+
+func TestTxMarshalJSON(t *testing.T) {
+	importTx, exportTx := newTxJSONFixtures(t)
+
+	tests := map[string]struct {
+		tx   *Tx
+		want string
+	}{
+		"import": {
+			tx: importTx,
+			want: `{
+				"version": 1,
+				"id": "%s",
+				"unsignedTx": {
+					"type": "import",
+					"networkID": "10",
+					"blockchainID": "SYXsAycDPUu4z2ZksJD5fh5nTDcH3vCFHnpcVye5XuJ2jArg",
+					"sourceChain": "t64jLxDRmxo8y48WjbRALPAZuSDZ6qPVaaeDzxHA4oSojhLt",
+					"importedInputs": [{
+						"txID": "2KdbbWvpeAShCx5hGbtdF15FMMepq9kajsNTqVvvEbhiCRSxU",
+						"outputIndex": "1",
+						"assetID": "2mB8TguRrYvbGw7G2UBqKfmL8osS7CfmzAAHSzuZK8bwpRKdY",
+						"amount": "5000000",
+						"sigIndices": ["0"]
+					}],
+					"outputs": [{
+						"address": "0x0500000000000000000000000000000000000000",
+						"amount": "4000000",
+						"assetID": "2mB8TguRrYvbGw7G2UBqKfmL8osS7CfmzAAHSzuZK8bwpRKdY"
+					}]
+				},
+				"credentials": [{"type": "secp256k1fx", "signatures": "1"}]
+			}`,
+		},
+		"export": {
+			tx: exportTx,
+			want: `{
+				"version": 1,
+				"id": "%s",
+				"unsignedTx": {
+					"type": "export",
+					"networkID": "10",
+					"blockchainID": "SYXsAycDPUu4z2ZksJD5fh5nTDcH3vCFHnpcVye5XuJ2jArg",
+					"destinationChain": "t64jLxDRmxo8y48WjbRALPAZuSDZ6qPVaaeDzxHA4oSojhLt",
+					"inputs": [{
+						"address": "0x0500000000000000000000000000000000000000",
+						"amount": "5000000",
+						"assetID": "2mB8TguRrYvbGw7G2UBqKfmL8osS7CfmzAAHSzuZK8bwpRKdY",
+						"nonce": "3"
+					}],
+					"exportedOutputs": [{
+						"assetID": "2mB8TguRrYvbGw7G2UBqKfmL8osS7CfmzAAHSzuZK8bwpRKdY",
+						"amount": "4000000",
+						"locktime": "0",
+						"threshold": "1",
+						"addresses": [{
+							"bech32": "testing1quqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqksl28e",
+							"hex": "0x0700000000000000000000000000000000000000"
+						}]
+					}]
+				},
+				"credentials": [{"type": "secp256k1fx", "signatures": "1"}]
+			}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			have, err := json.Marshal(test.tx)
+			require.NoError(err)
+			require.JSONEq(fmt.Sprintf(test.want, test.tx.ID()), string(have))
+
+			// The JSON of a tx parsed from its bytes is the same.
+			parsed, err := ExtractAtomicTx(test.tx.SignedBytes(), Codec)
+			require.NoError(err)
+			parsedJSON, err := json.Marshal(parsed)
+			require.NoError(err)
+			require.Equal(string(have), string(parsedJSON))
+		})
+	}
+}
+
+func FuzzTxMarshalJSON(f *testing.F) {
+	importTx, exportTx := newTxJSONFixtures(f)
+	f.Add(importTx.SignedBytes())
+	f.Add(exportTx.SignedBytes())
+
+	f.Fuzz(func(t *testing.T, txBytes []byte) {
+		if len(txBytes) == 0 {
+			return
+		}
+		tx, err := ExtractAtomicTx(txBytes, Codec)
+		if err != nil {
+			return
+		}
+		_, err = json.Marshal(tx)
+		require.NoError(t, err)
+	})
+}
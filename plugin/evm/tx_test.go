@@ -14,10 +14,21 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/plugin/evm/message"
+	"github.com/ava-labs/coreth/utils"
 
+	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	agoUtils "github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 )
 
 func TestCalculateDynamicFee(t *testing.T) {
@@ -311,3 +322,155 @@ func TestEVMInputCompare(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyAtomicTxLimits(t *testing.T) {
+	newTx := func(numOutputs int, numCreds int, size int) *Tx {
+		tx := &Tx{
+			UnsignedAtomicTx: &UnsignedImportTx{Outs: make([]EVMOutput, numOutputs)},
+			Creds:            make([]verify.Verifiable, numCreds),
+		}
+		tx.Initialize(nil, make([]byte, size))
+		return tx
+	}
+	limitsRules := params.Rules{IsAtomicTxLimits: true}
+
+	tests := map[string]struct {
+		tx          *Tx
+		rules       params.Rules
+		expectedErr error
+	}{
+		"at limits": {
+			tx:    newTx(params.AtomicTxMaxOutputs, params.AtomicTxMaxCredentials, params.AtomicTxMaxSize),
+			rules: limitsRules,
+		},
+		"too large": {
+			tx:          newTx(0, 0, params.AtomicTxMaxSize+1),
+			rules:       limitsRules,
+			expectedErr: errAtomicTxTooLarge,
+		},
+		"too many outputs": {
+			tx:          newTx(params.AtomicTxMaxOutputs+1, 0, 0),
+			rules:       limitsRules,
+			expectedErr: errTooManyAtomicTxOutputs,
+		},
+		"too many credentials": {
+			tx:          newTx(0, params.AtomicTxMaxCredentials+1, 0),
+			rules:       limitsRules,
+			expectedErr: errTooManyAtomicTxCredentials,
+		},
+		"too many inputs": {
+			tx: &Tx{
+				UnsignedAtomicTx: &UnsignedExportTx{Ins: make([]EVMInput, params.AtomicTxMaxInputs+1)},
+			},
+			rules:       limitsRules,
+			expectedErr: errTooManyAtomicTxInputs,
+		},
+		"not enforced before activation": {
+			tx:    newTx(params.AtomicTxMaxOutputs+1, params.AtomicTxMaxCredentials+1, params.AtomicTxMaxSize+1),
+			rules: params.Rules{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, verifyAtomicTxLimits(test.tx, test.rules), test.expectedErr)
+		})
+	}
+}
+
+// TestAtomicTxLimitsEntryPoints checks that an import tx at and above the
+// maximum number of outputs is accepted or rejected identically when it is
+// issued through the API, gossiped, added to the mempool or verified in a
+// block.
+func TestAtomicTxLimitsEntryPoints(t *testing.T) {
+	chainConfig := *params.TestEUpgradeChainConfig
+	chainConfig.AtomicTxLimitsTimestamp = utils.NewUint64(0)
+	genesis := genesisJSON(&chainConfig)
+
+	key := testKeys[0]
+	utxoAmount := uint64(1_000 * units.Avax)
+	utxoTxID, err := ids.ToID(hashing.ComputeHash256(key.PublicKey().Address().Bytes()))
+	require.NoError(t, err)
+
+	newImportTx := func(t *testing.T, vm *VM, numOutputs int) *Tx {
+		outs := make([]EVMOutput, numOutputs)
+		for i := range outs {
+			outs[i] = EVMOutput{
+				Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+				Amount:  1,
+				AssetID: vm.ctx.AVAXAssetID,
+			}
+		}
+		agoUtils.Sort(outs)
+		tx := &Tx{UnsignedAtomicTx: &UnsignedImportTx{
+			NetworkID:    vm.ctx.NetworkID,
+			BlockchainID: vm.ctx.ChainID,
+			SourceChain:  vm.ctx.XChainID,
+			ImportedInputs: []*avax.TransferableInput{{
+				UTXOID: avax.UTXOID{TxID: utxoTxID},
+				Asset:  avax.Asset{ID: vm.ctx.AVAXAssetID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   utxoAmount,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+			Outs: outs,
+		}}
+		require.NoError(t, tx.Sign(vm.codec, [][]*secp256k1.PrivateKey{{key}}))
+		return tx
+	}
+
+	entryPoints := map[string]func(t *testing.T, vm *VM, tx *Tx) error{
+		"api": func(t *testing.T, vm *VM, tx *Tx) error {
+			txStr, err := formatting.Encode(formatting.Hex, tx.SignedBytes())
+			require.NoError(t, err)
+			service := &AvaxAPI{vm}
+			return service.IssueTx(nil, &api.FormattedTx{Tx: txStr, Encoding: formatting.Hex}, &api.JSONTxID{})
+		},
+		"gossip": func(t *testing.T, vm *VM, tx *Tx) error {
+			handler := NewGossipHandler(vm, NewGossipStats())
+			require.NoError(t, handler.HandleAtomicTx(ids.EmptyNodeID, message.AtomicTxGossip{Tx: tx.SignedBytes()}))
+			// Invalid gossiped txs are dropped without returning an error.
+			if _, dropped, _ := vm.mempool.GetTx(tx.ID()); dropped {
+				return errTooManyAtomicTxOutputs
+			}
+			return nil
+		},
+		"mempool": func(t *testing.T, vm *VM, tx *Tx) error {
+			return vm.mempool.AddTx(tx)
+		},
+		"block": func(t *testing.T, vm *VM, tx *Tx) error {
+			parent := vm.blockChain.CurrentBlock()
+			height := parent.Number.Uint64() + 1
+			rules := vm.chainConfig.Rules(new(big.Int).SetUint64(height), uint64(vm.clock.Time().Unix()))
+			return vm.verifyTxs([]*Tx{tx}, parent.Hash(), initialBaseFee, height, rules)
+		},
+	}
+
+	tests := map[string]struct {
+		numOutputs  int
+		expectedErr error
+	}{
+		"max outputs": {
+			numOutputs: params.AtomicTxMaxOutputs,
+		},
+		"too many outputs": {
+			numOutputs:  params.AtomicTxMaxOutputs + 1,
+			expectedErr: errTooManyAtomicTxOutputs,
+		},
+	}
+	for name, test := range tests {
+		for entryPoint, issue := range entryPoints {
+			t.Run(name+"/"+entryPoint, func(t *testing.T) {
+				_, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesis, "", "", map[ids.ShortID]uint64{
+					key.PublicKey().Address(): utxoAmount,
+				})
+				defer func() {
+					require.NoError(t, vm.Shutdown(context.Background()))
+				}()
+
+				err := issue(t, vm, newImportTx(t, vm, test.numOutputs))
+				require.ErrorIs(t, err, test.expectedErr)
+			})
+		}
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/ava-labs/avalanchego/network/p2p"
@@ -44,6 +43,7 @@ import (
 	"github.com/ava-labs/coreth/rpc"
 	statesyncclient "github.com/ava-labs/coreth/sync/client"
 	"github.com/ava-labs/coreth/sync/client/stats"
+	"github.com/ava-labs/coreth/sync/statesync"
 	"github.com/ava-labs/coreth/trie"
 	"github.com/ava-labs/coreth/utils"
 	"github.com/ava-labs/coreth/warp"
@@ -67,6 +67,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 
 	avalancheRPC "github.com/gorilla/rpc/v2"
+	"google.golang.org/grpc"
 
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/codec"
@@ -75,6 +76,7 @@ import (
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
+	pb "github.com/ava-labs/avalanchego/proto/pb/warp"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
@@ -87,6 +89,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/components/chain"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
@@ -134,6 +137,10 @@ const (
 	bytesToIDCacheSize     = 5 * units.MiB
 	warpSignatureCacheSize = 500
 
+	// proposerPChainHeightsCacheSize is the number of blocks whose proposed
+	// P-Chain height is kept.
+	proposerPChainHeightsCacheSize = 1024
+
 	// Prefixes for metrics gatherers
 	ethMetricsPrefix        = "eth"
 	sdkMetricsPrefix        = "sdk"
@@ -144,6 +151,9 @@ const (
 	// p2p app protocols
 	ethTxGossipProtocol    = 0x0
 	atomicTxGossipProtocol = 0x1
+	// lightGossipProtocol follows 0x2, which is reserved for ACP-118
+	// signature requests.
+	lightGossipProtocol = 0x3
 
 	// gossip constants
 	pushGossipDiscardedElements          = 16_384
@@ -243,8 +253,6 @@ func init() {
 // VM implements the snowman.ChainVM interface
 type VM struct {
 	ctx *snow.Context
-	// [cancel] may be nil until [snow.NormalOp] starts
-	cancel context.CancelFunc
 	// *chain.State helps to implement the VM interface by wrapping blocks
 	// with an efficient caching layer.
 	*chain.State
@@ -263,6 +271,14 @@ type VM struct {
 	blockChain *core.BlockChain
 	miner      *miner.Miner
 
+	// [processingTxs] tracks the transactions of the processing blocks, which
+	// are not included in the blocks built on top of them.
+	processingTxs *processingTxs
+
+	// [proposerPChainHeights] maps the hashes of the blocks verified with
+	// their proposer context to the P-Chain height proposed with them.
+	proposerPChainHeights *cache.LRU[common.Hash, uint64]
+
 	// [db] is the VM's current database managed by ChainState
 	db *versiondb.Database
 
@@ -279,6 +295,8 @@ type VM struct {
 	// [warpDB] is used to store warp message signatures
 	// set to a prefixDB with the prefix [warpPrefix]
 	warpDB database.Database
+	// [warpSignerConn] is the connection to the remote warp signer, if any.
+	warpSignerConn *grpc.ClientConn
 
 	toEngine chan<- commonEng.Message
 
@@ -300,8 +318,9 @@ type VM struct {
 	clock     mockable.Clock
 	mempool   *Mempool
 
-	shutdownChan chan struct{}
-	shutdownWg   sync.WaitGroup
+	// [workers] tracks the background goroutines of the VM so they are
+	// stopped on shutdown.
+	workers *workerRegistry
 
 	fx        secp256k1fx.Fx
 	secpCache secp256k1.RecoverCache
@@ -338,6 +357,13 @@ type VM struct {
 	atomicTxGossipHandler p2p.Handler
 	atomicTxPushGossiper  *gossip.PushGossiper[*GossipAtomicTx]
 	atomicTxPullGossiper  gossip.Gossiper
+	atomicTxRegossiper    *atomicTxRegossiper
+	gossipFanout          *gossipFanoutController
+
+	// lightGossipPeers tracks the peers in light gossip mode, and
+	// lightGossipClient announces the mode of this node to its peers.
+	lightGossipPeers  *lightGossipPeers
+	lightGossipClient *p2p.Client
 }
 
 // CodecRegistry implements the secp256k1fx interface
@@ -387,6 +413,7 @@ func (vm *VM) Initialize(
 	deprecateMsg := vm.config.Deprecate()
 
 	vm.ctx = chainCtx
+	vm.workers = newWorkerRegistry(defaultWorkerStopTimeout)
 
 	// Create logger
 	alias, err := vm.ctx.BCLookup.PrimaryAlias(vm.ctx.ChainID)
@@ -420,7 +447,6 @@ func (vm *VM) Initialize(
 	metrics.EnabledExpensive = vm.config.MetricsExpensiveEnabled
 
 	vm.toEngine = toEngine
-	vm.shutdownChan = make(chan struct{}, 1)
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
 	vm.chaindb = rawdb.NewDatabase(Database{prefixdb.NewNested(ethDBPrefix, db)})
@@ -496,6 +522,9 @@ func (vm *VM) Initialize(
 	vm.ethConfig.Genesis = g
 	vm.ethConfig.NetworkId = vm.chainID.Uint64()
 	vm.genesisHash = vm.ethConfig.Genesis.ToBlock().Hash() // must create genesis hash before [vm.readLastAccepted]
+	if err := vm.verifyChainIdentity(); err != nil {
+		return err
+	}
 	lastAcceptedHash, lastAcceptedHeight, err := vm.readLastAccepted()
 	if err != nil {
 		return err
@@ -506,6 +535,7 @@ func (vm *VM) Initialize(
 	// gas price to prevent so transactions and blocks all use the correct fees
 	vm.ethConfig.RPCGasCap = vm.config.RPCGasCap
 	vm.ethConfig.RPCEVMTimeout = vm.config.APIMaxDuration.Duration
+	vm.ethConfig.RPCEVMExecutionTimeout = vm.config.APIMaxExecutionDuration.Duration
 	vm.ethConfig.RPCTxFeeCap = vm.config.RPCTxFeeCap
 
 	vm.ethConfig.TxPool.NoLocals = !vm.config.LocalTxsEnabled
@@ -516,6 +546,8 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TxPool.AccountQueue = vm.config.TxPoolAccountQueue
 	vm.ethConfig.TxPool.GlobalQueue = vm.config.TxPoolGlobalQueue
 	vm.ethConfig.TxPool.Lifetime = vm.config.TxPoolLifetime.Duration
+	vm.ethConfig.Miner.OrderingPolicy = vm.config.TxOrderingPolicy
+	vm.ethConfig.Miner.DisableLocalsPriority = !vm.config.TxPrioritizeLocals
 
 	vm.ethConfig.AllowUnfinalizedQueries = vm.config.AllowUnfinalizedQueries
 	vm.ethConfig.AllowUnprotectedTxs = vm.config.AllowUnprotectedTxs
@@ -542,6 +574,10 @@ func (vm *VM) Initialize(
 	vm.ethConfig.AcceptedCacheSize = vm.config.AcceptedCacheSize
 	vm.ethConfig.TxLookupLimit = vm.config.TxLookupLimit
 	vm.ethConfig.SkipTxIndexing = vm.config.SkipTxIndexing
+	vm.ethConfig.HotContracts = vm.config.HotContracts
+	vm.ethConfig.HotContractsCheckInterval = vm.config.HotContractsCheckInterval
+	vm.ethConfig.GasAnalyticsWindow = vm.config.GasAnalyticsWindow
+	vm.ethConfig.GasAnalyticsMetricsTopN = vm.config.GasAnalyticsMetricsTopN
 
 	// Create directory for offline pruning
 	if len(vm.ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -570,7 +606,7 @@ func (vm *VM) Initialize(
 	}
 
 	// TODO: read size from settings
-	vm.mempool, err = NewMempool(chainCtx, vm.sdkMetrics, defaultMempoolSize, vm.verifyTxAtTip)
+	vm.mempool, err = NewMempool(chainCtx, vm.sdkMetrics, defaultMempoolSize, vm.config.AtomicMempoolMinFeeRate, vm.config.AtomicMempoolMaxTxsPerAddress, vm.verifyTxAtTip)
 	if err != nil {
 		return fmt.Errorf("failed to initialize mempool: %w", err)
 	}
@@ -586,18 +622,42 @@ func (vm *VM) Initialize(
 	}
 	vm.validators = p2p.NewValidators(p2pNetwork.Peers, vm.ctx.Log, vm.ctx.SubnetID, vm.ctx.ValidatorState, maxValidatorSetStaleness)
 	vm.networkCodec = message.Codec
-	vm.Network = peer.NewNetwork(p2pNetwork, appSender, vm.networkCodec, message.CrossChainCodec, chainCtx.NodeID, vm.config.MaxOutboundActiveRequests, vm.config.MaxOutboundActiveCrossChainRequests)
+	minSyncPeerVersion, err := vm.config.minSyncPeerVersion()
+	if err != nil {
+		return err
+	}
+	vm.Network = peer.NewNetwork(p2pNetwork, appSender, vm.networkCodec, message.CrossChainCodec, chainCtx.NodeID, vm.config.MaxOutboundActiveRequests, vm.config.MaxOutboundActiveCrossChainRequests, minSyncPeerVersion)
 	vm.client = peer.NewNetworkClient(vm.Network)
 
+	vm.lightGossipPeers = newLightGossipPeers()
+	if err := vm.Network.AddHandler(lightGossipProtocol, vm.lightGossipPeers); err != nil {
+		return fmt.Errorf("failed to add light gossip handler: %w", err)
+	}
+	vm.lightGossipClient = vm.Network.NewClient(lightGossipProtocol)
+
 	// Initialize warp backend
 	offchainWarpMessages := make([][]byte, len(vm.config.WarpOffChainMessages))
 	for i, hexMsg := range vm.config.WarpOffChainMessages {
 		offchainWarpMessages[i] = []byte(hexMsg)
 	}
+	warpSigner := vm.ctx.WarpSigner
+	if vm.config.WarpSignerEndpoint != "" {
+		vm.warpSignerConn, err = warp.DialRemoteSigner(
+			vm.config.WarpSignerEndpoint,
+			vm.config.WarpSignerTLSCertFile,
+			vm.config.WarpSignerTLSKeyFile,
+			vm.config.WarpSignerTLSCAFile,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to connect to remote warp signer: %w", err)
+		}
+		warpSigner = warp.NewRemoteSigner(pb.NewSignerClient(vm.warpSignerConn), vm.ctx.PublicKey, vm.config.WarpSignerTimeout.Duration)
+		log.Info("Signing warp messages with remote signer", "endpoint", vm.config.WarpSignerEndpoint)
+	}
 	vm.warpBackend, err = warp.NewBackend(
 		vm.ctx.NetworkID,
 		vm.ctx.ChainID,
-		vm.ctx.WarpSigner,
+		warpSigner,
 		vm,
 		vm.warpDB,
 		warpSignatureCacheSize,
@@ -629,7 +689,7 @@ func (vm *VM) Initialize(
 	}
 
 	// initialize atomic repository
-	vm.atomicTxRepository, err = NewAtomicTxRepository(vm.db, vm.codec, lastAcceptedHeight)
+	vm.atomicTxRepository, err = NewAtomicTxRepository(vm.db, vm.codec, lastAcceptedHeight, vm.config.AtomicTxCacheSize)
 	if err != nil {
 		return fmt.Errorf("failed to create atomic repository: %w", err)
 	}
@@ -643,7 +703,9 @@ func (vm *VM) Initialize(
 	}
 	vm.atomicTrie = vm.atomicBackend.AtomicTrie()
 
-	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
+	vm.workers.Go("continuous_profiler", func(ctx context.Context) {
+		vm.ctx.Log.RecoverAndPanic(func() { vm.startContinuousProfiler(ctx) })
+	})
 
 	// The Codec explicitly registers the types it requires from the secp256k1fx
 	// so [vm.baseCodec] is a dummy codec use to fulfill the secp256k1fx VM
@@ -701,6 +763,10 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash) error {
 	vm.txPool = vm.eth.TxPool()
 	vm.blockChain = vm.eth.BlockChain()
 	vm.miner = vm.eth.Miner()
+	vm.processingTxs = newProcessingTxs()
+	vm.miner.SetProcessingTxs(vm.processingTxs)
+	vm.proposerPChainHeights = &cache.LRU[common.Hash, uint64]{Size: proposerPChainHeightsCacheSize}
+	vm.eth.SetProposerPChainHeights(vm.proposerPChainHeights.Get)
 
 	// Set the gas parameters for the tx pool to the minimum gas price for the
 	// latest upgrade.
@@ -725,19 +791,16 @@ func (vm *VM) setMinFeeAtEUpgrade() {
 	}
 
 	vm.txPool.SetMinFee(big.NewInt(params.ApricotPhase4MinBaseFee))
-	vm.shutdownWg.Add(1)
-	go func() {
-		defer vm.shutdownWg.Done()
-
+	vm.workers.Go("eupgrade_min_fee", func(ctx context.Context) {
 		wait := utils.Uint64ToTime(vm.chainConfig.EUpgradeTime).Sub(now)
 		t := time.NewTimer(wait)
 		select {
 		case <-t.C: // Wait for EUpgrade to be activated
 			vm.txPool.SetMinFee(big.NewInt(params.EUpgradeMinBaseFee))
-		case <-vm.shutdownChan:
+		case <-ctx.Done():
 		}
 		t.Stop()
-	}()
+	})
 }
 
 // initializeStateSyncClient initializes the client for performing state sync.
@@ -759,23 +822,39 @@ func (vm *VM) initializeStateSyncClient(lastAcceptedHeight uint64) error {
 		}
 	}
 
+	var stateSyncVerifyConfig *statesync.VerifierConfig
+	if vm.config.StateSyncVerifyEnabled {
+		stateSyncVerifyConfig = &statesync.VerifierConfig{
+			NumAccounts:     vm.config.StateSyncVerifyAccounts,
+			NumStorageSlots: vm.config.StateSyncVerifyStorageSlots,
+			ChecksPerSecond: vm.config.StateSyncVerifyChecksPerSecond,
+			Seed:            time.Now().UnixNano(),
+		}
+	}
+
+	client := statesyncclient.NewClient(
+		&statesyncclient.ClientConfig{
+			NetworkClient:    vm.client,
+			Codec:            vm.networkCodec,
+			Stats:            stats.NewClientSyncerStats(),
+			StateSyncNodeIDs: stateSyncIDs,
+			BlockParser:      vm,
+		},
+	)
+	if vm.config.StateSyncHealMissingNodes {
+		vm.blockChain.MissingNodeHandler().SetHealer(statesync.NewNodeHealer(client), vm.config.StateSyncHealTimeout.Duration)
+	}
 	vm.StateSyncClient = NewStateSyncClient(&stateSyncClientConfig{
-		chain: vm.eth,
-		state: vm.State,
-		client: statesyncclient.NewClient(
-			&statesyncclient.ClientConfig{
-				NetworkClient:    vm.client,
-				Codec:            vm.networkCodec,
-				Stats:            stats.NewClientSyncerStats(),
-				StateSyncNodeIDs: stateSyncIDs,
-				BlockParser:      vm,
-			},
-		),
+		chain:                vm.eth,
+		state:                vm.State,
+		client:               client,
 		enabled:              stateSyncEnabled,
 		skipResume:           vm.config.StateSyncSkipResume,
 		stateSyncMinBlocks:   vm.config.StateSyncMinBlocks,
 		stateSyncRequestSize: vm.config.StateSyncRequestSize,
 		lastAcceptedHeight:   lastAcceptedHeight, // TODO clean up how this is passed around
+		verifyConfig:         stateSyncVerifyConfig,
+		verifyRecentBlocks:   vm.config.StateSyncVerifyRecentBlocks,
 		chaindb:              vm.chaindb,
 		metadataDB:           vm.metadataDB,
 		acceptedBlockDB:      vm.acceptedBlockDB,
@@ -908,10 +987,21 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 			break
 		}
 
+		// Discard [tx] if it is already in a processing ancestor of the block,
+		// as it would fail verification once the ancestor is accepted. It is
+		// re-issued if the ancestor is rejected.
+		if vm.processingTxs.Has(common.Hash(tx.ID())) {
+			log.Debug("discarding tx already in processing block", "txID", tx.ID())
+			vm.mempool.DiscardCurrentTx(tx.ID())
+			vm.miner.ReportAtomicTxSkipped(tx.ID(), miner.SkipProcessing, nil)
+			continue
+		}
+
 		// Ensure that adding [tx] to the block will not exceed the block size soft limit.
 		txSize := len(tx.SignedBytes())
 		if size+txSize > targetAtomicTxsSize {
 			vm.mempool.CancelCurrentTx(tx.ID())
+			vm.miner.ReportAtomicTxSkipped(tx.ID(), miner.SkipAtomicTxsSizeExceeded, nil)
 			break
 		}
 
@@ -931,6 +1021,7 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 		if totalGasUsed := new(big.Int).Add(batchGasUsed, txGasUsed); totalGasUsed.Cmp(params.AtomicGasLimit) > 0 {
 			// Send [tx] back to the mempool's tx heap.
 			vm.mempool.CancelCurrentTx(tx.ID())
+			vm.miner.ReportAtomicTxSkipped(tx.ID(), miner.SkipAtomicGasLimitReached, nil)
 			break
 		}
 
@@ -943,6 +1034,7 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 			// Discard the transaction from the mempool on failed verification.
 			log.Debug("discarding tx due to overlapping input utxos", "txID", tx.ID())
 			vm.mempool.DiscardCurrentTx(tx.ID())
+			vm.miner.ReportAtomicTxSkipped(tx.ID(), miner.SkipAtomicConflict, nil)
 			continue
 		}
 
@@ -955,10 +1047,12 @@ func (vm *VM) postBatchOnFinalizeAndAssemble(header *types.Header, state *state.
 			log.Debug("discarding tx from mempool due to failed verification", "txID", tx.ID(), "err", err)
 			vm.mempool.DiscardCurrentTx(tx.ID())
 			state.RevertToSnapshot(snapshot)
+			vm.miner.ReportAtomicTxSkipped(tx.ID(), miner.SkipAtomicInvalid, err)
 			continue
 		}
 
 		batchAtomicTxs = append(batchAtomicTxs, tx)
+		vm.miner.ReportAtomicTxIncluded(tx.ID())
 		batchAtomicUTXOs.Union(tx.InputUTXOs())
 		// Add the [txGasUsed] to the [batchGasUsed] when the [tx] has passed verification
 		batchGasUsed.Add(batchGasUsed, txGasUsed)
@@ -1094,26 +1188,41 @@ func (vm *VM) SetState(_ context.Context, state snow.State) error {
 	}
 }
 
+// Connected tracks [nodeID] as a peer and, in light gossip mode, announces to
+// it that push gossip should not be sent to this node.
+func (vm *VM) Connected(ctx context.Context, nodeID ids.NodeID, nodeVersion *version.Application) error {
+	if err := vm.Network.Connected(ctx, nodeID, nodeVersion); err != nil {
+		return err
+	}
+	if !vm.config.LightGossip || nodeID == vm.ctx.NodeID {
+		return nil
+	}
+	return vm.lightGossipClient.AppGossip(
+		ctx,
+		commonEng.SendConfig{NodeIDs: set.Of(nodeID)},
+		lightGossipAnnouncement(true),
+	)
+}
+
+// Disconnected stops tracking [nodeID] as a peer.
+func (vm *VM) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
+	vm.lightGossipPeers.Disconnected(nodeID)
+	return vm.Network.Disconnected(ctx, nodeID)
+}
+
 // initBlockBuilding starts goroutines to manage block building
 func (vm *VM) initBlockBuilding() error {
-	ctx, cancel := context.WithCancel(context.TODO())
-	vm.cancel = cancel
-
 	ethTxGossipMarshaller := GossipEthTxMarshaller{}
 	ethTxGossipClient := vm.Network.NewClient(ethTxGossipProtocol, p2p.WithValidatorSampling(vm.validators))
 	ethTxGossipMetrics, err := gossip.NewMetrics(vm.sdkMetrics, ethTxGossipNamespace)
 	if err != nil {
 		return fmt.Errorf("failed to initialize eth tx gossip metrics: %w", err)
 	}
-	ethTxPool, err := NewGossipEthTxPool(vm.txPool, vm.sdkMetrics)
+	ethTxPool, err := NewGossipEthTxPool(vm.txPool, vm.blockChain, vm.sdkMetrics)
 	if err != nil {
 		return err
 	}
-	vm.shutdownWg.Add(1)
-	go func() {
-		ethTxPool.Subscribe(ctx)
-		vm.shutdownWg.Done()
-	}()
+	vm.workers.Go("eth_tx_pool_subscription", ethTxPool.Subscribe)
 
 	atomicTxGossipMarshaller := GossipAtomicTxMarshaller{}
 	atomicTxGossipClient := vm.Network.NewClient(atomicTxGossipProtocol, p2p.WithValidatorSampling(vm.validators))
@@ -1122,6 +1231,28 @@ func (vm *VM) initBlockBuilding() error {
 		return fmt.Errorf("failed to initialize atomic tx gossip metrics: %w", err)
 	}
 
+	if vm.gossipFanout == nil {
+		vm.gossipFanout = newGossipFanoutController(
+			gossipFanoutConfig{
+				MinStakePercentage: vm.config.pushGossipMinPercentStake(),
+				MaxStakePercentage: vm.config.PushGossipPercentStake,
+				MinFrequency:       vm.config.PushGossipFrequency.Duration,
+				MaxFrequency:       vm.config.pushGossipMaxFrequency(),
+			},
+			time.Now(),
+		)
+	}
+	vm.workers.Go("gossip_fanout", func(ctx context.Context) {
+		vm.gossipFanout.Run(ctx, vm.txPool, gossipFanoutUpdateFrequency)
+	})
+	pushGossipValidators := &lightGossipValidatorSubset{
+		validators: &fanoutValidatorSubset{
+			validators: vm.validators,
+			controller: vm.gossipFanout,
+		},
+		lightPeers: vm.lightGossipPeers,
+	}
+
 	pushGossipParams := gossip.BranchingFactor{
 		StakePercentage: vm.config.PushGossipPercentStake,
 		Validators:      vm.config.PushGossipNumValidators,
@@ -1137,7 +1268,7 @@ func (vm *VM) initBlockBuilding() error {
 		ethTxPushGossiper, err = gossip.NewPushGossiper[*GossipEthTx](
 			ethTxGossipMarshaller,
 			ethTxPool,
-			vm.validators,
+			pushGossipValidators,
 			ethTxGossipClient,
 			ethTxGossipMetrics,
 			pushGossipParams,
@@ -1156,7 +1287,7 @@ func (vm *VM) initBlockBuilding() error {
 		vm.atomicTxPushGossiper, err = gossip.NewPushGossiper[*GossipAtomicTx](
 			atomicTxGossipMarshaller,
 			vm.mempool,
-			vm.validators,
+			pushGossipValidators,
 			atomicTxGossipClient,
 			atomicTxGossipMetrics,
 			pushGossipParams,
@@ -1174,7 +1305,11 @@ func (vm *VM) initBlockBuilding() error {
 	gossipStats := NewGossipStats()
 	vm.builder = vm.NewBlockBuilder(vm.toEngine)
 	vm.builder.awaitSubmittedTxs()
-	vm.Network.SetGossipHandler(NewGossipHandler(vm, gossipStats))
+	if vm.config.LightGossip {
+		vm.Network.SetGossipHandler(message.NoopMempoolGossipHandler{})
+	} else {
+		vm.Network.SetGossipHandler(NewGossipHandler(vm, gossipStats))
+	}
 
 	if vm.ethTxGossipHandler == nil {
 		vm.ethTxGossipHandler = newTxGossipHandler[*GossipEthTx](
@@ -1189,6 +1324,9 @@ func (vm *VM) initBlockBuilding() error {
 		)
 	}
 
+	if vm.config.LightGossip {
+		vm.ethTxGossipHandler = newLightGossipHandler(vm.ethTxGossipHandler)
+	}
 	if err := vm.Network.AddHandler(ethTxGossipProtocol, vm.ethTxGossipHandler); err != nil {
 		return err
 	}
@@ -1206,6 +1344,9 @@ func (vm *VM) initBlockBuilding() error {
 		)
 	}
 
+	if vm.config.LightGossip {
+		vm.atomicTxGossipHandler = newLightGossipHandler(vm.atomicTxGossipHandler)
+	}
 	if err := vm.Network.AddHandler(atomicTxGossipProtocol, vm.atomicTxGossipHandler); err != nil {
 		return err
 	}
@@ -1227,15 +1368,12 @@ func (vm *VM) initBlockBuilding() error {
 		}
 	}
 
-	vm.shutdownWg.Add(2)
-	go func() {
-		gossip.Every(ctx, vm.ctx.Log, ethTxPushGossiper, vm.config.PushGossipFrequency.Duration)
-		vm.shutdownWg.Done()
-	}()
-	go func() {
+	vm.workers.Go("eth_tx_push_gossip", func(ctx context.Context) {
+		gossipEveryAdaptive(ctx, ethTxPushGossiper, vm.gossipFanout)
+	})
+	vm.workers.Go("eth_tx_pull_gossip", func(ctx context.Context) {
 		gossip.Every(ctx, vm.ctx.Log, vm.ethTxPullGossiper, vm.config.PullGossipFrequency.Duration)
-		vm.shutdownWg.Done()
-	}()
+	})
 
 	if vm.atomicTxPullGossiper == nil {
 		atomicTxPullGossiper := gossip.NewPullGossiper[*GossipAtomicTx](
@@ -1254,15 +1392,40 @@ func (vm *VM) initBlockBuilding() error {
 		}
 	}
 
-	vm.shutdownWg.Add(2)
-	go func() {
-		gossip.Every(ctx, vm.ctx.Log, vm.atomicTxPushGossiper, vm.config.PushGossipFrequency.Duration)
-		vm.shutdownWg.Done()
-	}()
-	go func() {
+	vm.workers.Go("atomic_tx_push_gossip", func(ctx context.Context) {
+		gossipEveryAdaptive(ctx, vm.atomicTxPushGossiper, vm.gossipFanout)
+	})
+	vm.workers.Go("atomic_tx_pull_gossip", func(ctx context.Context) {
 		gossip.Every(ctx, vm.ctx.Log, vm.atomicTxPullGossiper, vm.config.PullGossipFrequency.Duration)
-		vm.shutdownWg.Done()
-	}()
+	})
+
+	if vm.config.AtomicRegossipBandwidth > 0 {
+		if vm.atomicTxRegossiper == nil {
+			vm.atomicTxRegossiper = newAtomicTxRegossiper(
+				atomicRegossipConfig{
+					MinBackoff: vm.config.RegossipFrequency.Duration,
+					MaxBackoff: vm.config.AtomicRegossipMaxBackoff.Duration,
+					MaxBytes:   int(float64(vm.config.AtomicRegossipBandwidth) * atomicRegossipFrequency.Seconds()),
+					Gossip: commonEng.SendConfig{
+						Validators: vm.config.PushRegossipNumValidators,
+						Peers:      vm.config.PushRegossipNumPeers,
+					},
+				},
+				vm.mempool,
+				func(tx *Tx) error {
+					vm.ctx.Lock.RLock()
+					defer vm.ctx.Lock.RUnlock()
+
+					return vm.verifyTxAtTip(tx)
+				},
+				vm.p2pSender,
+				vm.networkCodec,
+			)
+		}
+		vm.workers.Go("atomic_tx_regossip", func(ctx context.Context) {
+			vm.atomicTxRegossiper.Run(ctx, atomicRegossipFrequency)
+		})
+	}
 
 	return nil
 }
@@ -1304,16 +1467,22 @@ func (vm *VM) Shutdown(context.Context) error {
 	if vm.ctx == nil {
 		return nil
 	}
-	if vm.cancel != nil {
-		vm.cancel()
+	// Stop the background workers first, as they may use the network and the
+	// chain. If any are stuck, the network and the chain are left open rather
+	// than closed underneath them.
+	if err := vm.workers.Shutdown(); err != nil {
+		return fmt.Errorf("not stopping the network and chain: %w", err)
 	}
 	vm.Network.Shutdown()
 	if err := vm.StateSyncClient.Shutdown(); err != nil {
 		log.Error("error stopping state syncer", "err", err)
 	}
-	close(vm.shutdownChan)
 	vm.eth.Stop()
-	vm.shutdownWg.Wait()
+	if vm.warpSignerConn != nil {
+		if err := vm.warpSignerConn.Close(); err != nil {
+			log.Error("error closing remote warp signer connection", "err", err)
+		}
+	}
 	return nil
 }
 
@@ -1333,7 +1502,15 @@ func (vm *VM) buildBlockWithContext(ctx context.Context, proposerVMBlockCtx *blo
 		ProposerVMBlockCtx: proposerVMBlockCtx,
 	}
 
-	block, err := vm.miner.GenerateBlock(predicateCtx)
+	// The block is built from the transactions executed before the deadline,
+	// so that it is not built too late for the engine.
+	buildCtx := ctx
+	if deadline := vm.config.BlockBuildDeadline.Duration; deadline > 0 {
+		var cancel context.CancelFunc
+		buildCtx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+	block, err := vm.miner.GenerateBlock(buildCtx, predicateCtx)
 	vm.builder.handleGenerateBlock()
 	if err != nil {
 		vm.mempool.CancelCurrentTxs()
@@ -1445,7 +1622,11 @@ func (vm *VM) SetPreference(ctx context.Context, blkID ids.ID) error {
 		return fmt.Errorf("failed to set preference to %s: %w", blkID, err)
 	}
 
-	return vm.blockChain.SetPreference(block.(*Block).ethBlock)
+	if err := vm.blockChain.SetPreference(block.(*Block).ethBlock); err != nil {
+		return err
+	}
+	vm.processingTxs.setPreference(common.Hash(blkID))
+	return nil
 }
 
 // VerifyHeightIndex always returns a nil error since the index is maintained by
@@ -1646,6 +1827,12 @@ func (vm *VM) ParseAddress(addrStr string) (ids.ID, ids.ShortID, error) {
 
 // verifyTxAtTip verifies that [tx] is valid to be issued on top of the currently preferred block
 func (vm *VM) verifyTxAtTip(tx *Tx) error {
+	rules := vm.currentRules()
+	// Check the consensus limits first, so that the violated limit is
+	// reported rather than the local size target.
+	if err := verifyAtomicTxLimits(tx, rules); err != nil {
+		return err
+	}
 	if txByteLen := len(tx.SignedBytes()); txByteLen > targetAtomicTxsSize {
 		return fmt.Errorf("tx size (%d) exceeds total atomic txs size target (%d)", txByteLen, targetAtomicTxsSize)
 	}
@@ -1664,7 +1851,6 @@ func (vm *VM) verifyTxAtTip(tx *Tx) error {
 	if err != nil {
 		return fmt.Errorf("failed to retrieve block state at tip while verifying atomic tx: %w", err)
 	}
-	rules := vm.currentRules()
 	parentHeader := preferredBlock
 	var nextBaseFee *big.Int
 	timestamp := uint64(vm.clock.Time().Unix())
@@ -1687,6 +1873,9 @@ func (vm *VM) verifyTxAtTip(tx *Tx) error {
 // for reverting to the correct snapshot after calling this function. If this function is called with a
 // throwaway state, then this is not necessary.
 func (vm *VM) verifyTx(tx *Tx, parentHash common.Hash, baseFee *big.Int, state *state.StateDB, rules params.Rules) error {
+	if err := verifyAtomicTxLimits(tx, rules); err != nil {
+		return err
+	}
 	parentIntf, err := vm.GetBlockInternal(context.TODO(), ids.ID(parentHash))
 	if err != nil {
 		return fmt.Errorf("failed to get parent block: %w", err)
@@ -1727,6 +1916,9 @@ func (vm *VM) verifyTxs(txs []*Tx, parentHash common.Hash, baseFee *big.Int, hei
 	// a processing ancestor block.
 	inputs := set.Set[ids.ID]{}
 	for _, atomicTx := range txs {
+		if err := verifyAtomicTxLimits(atomicTx, rules); err != nil {
+			return fmt.Errorf("invalid block due to atomic tx limits: %w at height %d", err, height)
+		}
 		utx := atomicTx.UnsignedAtomicTx
 		if err := utx.SemanticVerify(vm, atomicTx, ancestor, baseFee, rules); err != nil {
 			return fmt.Errorf("invalid block due to failed semanatic verify: %w at height %d", err, height)
@@ -1940,7 +2132,7 @@ func (vm *VM) currentRules() params.Rules {
 	return vm.chainConfig.Rules(header.Number, header.Time)
 }
 
-func (vm *VM) startContinuousProfiler() {
+func (vm *VM) startContinuousProfiler(ctx context.Context) {
 	// If the profiler directory is empty, return immediately
 	// without creating or starting a continuous profiler.
 	if vm.config.ContinuousProfilerDir == "" {
@@ -1953,17 +2145,15 @@ func (vm *VM) startContinuousProfiler() {
 	)
 	defer vm.profiler.Shutdown()
 
-	vm.shutdownWg.Add(1)
-	go func() {
-		defer vm.shutdownWg.Done()
+	vm.workers.Go("continuous_profiler_dispatch", func(context.Context) {
 		log.Info("Dispatching continuous profiler", "dir", vm.config.ContinuousProfilerDir, "freq", vm.config.ContinuousProfilerFrequency, "maxFiles", vm.config.ContinuousProfilerMaxFiles)
 		err := vm.profiler.Dispatch()
 		if err != nil {
 			log.Error("continuous profiler failed", "err", err)
 		}
-	}()
-	// Wait for shutdownChan to be closed
-	<-vm.shutdownChan
+	})
+	// Wait for the VM to shut down
+	<-ctx.Done()
 }
 
 func (vm *VM) estimateBaseFee(ctx context.Context) (*big.Int, error) {
@@ -2009,6 +2199,22 @@ func (vm *VM) readLastAccepted() (common.Hash, uint64, error) {
 	}
 }
 
+// verifyChainIdentity ensures the database was created for the network, chain,
+// and genesis the VM is being initialized with, persisting them on first run.
+// Note: assumes [vm.metadataDB], [vm.chainID], and [vm.genesisHash] have been initialized.
+func (vm *VM) verifyChainIdentity() error {
+	expected := &chainIdentity{
+		NetworkID:   vm.ctx.NetworkID,
+		ChainID:     vm.ctx.ChainID,
+		EVMChainID:  vm.chainID,
+		GenesisHash: vm.genesisHash,
+	}
+	if err := verifyChainIdentity(vm.metadataDB, expected, vm.config.SkipChainIdentityCheck); err != nil {
+		return err
+	}
+	return vm.db.Commit()
+}
+
 // attachEthService registers the backend RPC services provided by Ethereum
 // to the provided handler under their assigned namespaces.
 func attachEthService(handler *rpc.Server, apis []rpc.API, names []string) error {
@@ -0,0 +1,113 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/miner"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlockBuildingReport(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	importAmount := 100 * units.Avax
+	issuer, vm, _, _, _ := GenesisVMWithUTXOs(t, true, genesisJSONLatest, "", "", map[ids.ShortID]uint64{
+		testShortIDAddrs[0]: importAmount,
+		testShortIDAddrs[1]: importAmount,
+	})
+	defer func() {
+		require.NoError(vm.Shutdown(ctx))
+	}()
+
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	// The first block imports funds for the EVM transactions. [conflictTx]
+	// spends the same UTXO as [importTxs][0] with a higher fee, so it is
+	// included first and [importTxs][0] conflicts with it.
+	var importTxs []*Tx
+	for i, key := range testKeys[:2] {
+		importTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[i], initialBaseFee, []*secp256k1.PrivateKey{key})
+		require.NoError(err)
+		require.NoError(vm.mempool.AddLocalTx(importTx))
+		importTxs = append(importTxs, importTx)
+	}
+	conflictTx, err := vm.newImportTx(vm.ctx.XChainID, testEthAddrs[0], new(big.Int).Mul(initialBaseFee, big.NewInt(2)), []*secp256k1.PrivateKey{testKeys[0]})
+	require.NoError(err)
+	require.NoError(vm.mempool.ForceAddTx(conflictTx))
+	<-issuer
+
+	blk1, err := vm.BuildBlock(ctx)
+	require.NoError(err)
+	require.NoError(blk1.Verify(ctx))
+	require.NoError(vm.SetPreference(ctx, blk1.ID()))
+	require.NoError(blk1.Accept(ctx))
+	<-newTxPoolHeadChan
+
+	reports := vm.miner.BlockBuildingReports()
+	require.NotEmpty(reports)
+	report := reports[len(reports)-1]
+	require.Equal(common.Hash(blk1.ID()), *report.BlockHash)
+	require.Empty(report.Error)
+	require.Equal([]string{conflictTx.ID().String(), importTxs[1].ID().String()}, report.Included)
+	require.Equal([]miner.SkippedTxReport{{TxID: importTxs[0].ID().String(), Reason: miner.SkipAtomicConflict}}, report.Skipped)
+
+	// In the second block, the first transaction of [testEthAddrs][0] burns
+	// most of the gas of the block, and the next one has a fee cap below the
+	// base fee. The first transaction of [testEthAddrs][1] then exceeds the
+	// gas left, and the next one has a nonce gap.
+	var (
+		signer   = types.LatestSigner(vm.chainConfig)
+		lowPrice = big.NewInt(params.EUpgradeMinBaseFee)
+		// [infiniteLoop] is init code jumping to itself until it runs out of
+		// gas.
+		infiniteLoop = common.FromHex("0x5b600056")
+		unsigned     = []*types.Transaction{
+			types.NewContractCreation(0, common.Big0, 14_000_000, new(big.Int).Mul(initialBaseFee, big.NewInt(4)), infiniteLoop),
+			types.NewTransaction(1, testEthAddrs[1], common.Big1, params.TxGas, lowPrice, nil),
+			types.NewTransaction(0, testEthAddrs[0], common.Big1, 2_000_000, new(big.Int).Mul(initialBaseFee, big.NewInt(2)), nil),
+			types.NewTransaction(1, testEthAddrs[0], common.Big1, params.TxGas, new(big.Int).Mul(initialBaseFee, big.NewInt(2)), nil),
+		}
+		keys = []*secp256k1.PrivateKey{testKeys[0], testKeys[0], testKeys[1], testKeys[1]}
+		txs  = make([]*types.Transaction, len(unsigned))
+	)
+	for i, tx := range unsigned {
+		txs[i], err = types.SignTx(tx, signer, keys[i].ToECDSA())
+		require.NoError(err)
+	}
+	for i, err := range vm.txPool.Add(txs, true /*=local*/, true /*=sync*/) {
+		require.NoError(err, "tx %d", i)
+	}
+	<-issuer
+
+	blk2, err := vm.BuildBlock(ctx)
+	require.NoError(err)
+	require.NoError(blk2.Verify(ctx))
+	ethBlock := blk2.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Negative(lowPrice.Cmp(ethBlock.BaseFee()))
+
+	reports = vm.miner.BlockBuildingReports()
+	report = reports[len(reports)-1]
+	require.Equal(ethBlock.Hash(), *report.BlockHash)
+	require.Equal([]string{txs[0].Hash().Hex()}, report.Included)
+	require.ElementsMatch([]miner.SkippedTxReport{
+		{TxID: txs[1].Hash().Hex(), Reason: miner.SkipFeeBelowBaseFee},
+		{TxID: txs[2].Hash().Hex(), Reason: miner.SkipGasLimitReached},
+		{TxID: txs[3].Hash().Hex(), Reason: miner.SkipNonceGap},
+	}, report.Skipped)
+}
@@ -0,0 +1,101 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/snow"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/enginetest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/coreth/core"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/miner"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
+	"github.com/ava-labs/coreth/utils"
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// generateBlockWithTxs builds a block with [txs] on top of the last accepted
+// block of [vm], as an external block builder would, and verifies it.
+func generateBlockWithTxs(t *testing.T, vm *VM, txs types.Transactions) (*Block, []*miner.SkippedTx) {
+	t.Helper()
+	require := require.New(t)
+
+	parent := vm.blockChain.LastAcceptedBlock()
+	ethBlock, skipped, err := vm.miner.GenerateBlockWithTransactions(context.Background(), parent.Hash(), txs, parent.Time())
+	require.NoError(err)
+	// Atomic txs of the mempool added to the block by the consensus engine
+	// are not issued.
+	vm.mempool.CancelCurrentTxs()
+
+	blk, err := vm.newBlock(ethBlock)
+	require.NoError(err)
+	require.NoError(blk.verify(&precompileconfig.PredicateContext{SnowCtx: vm.ctx}, false /*=writes*/))
+	return blk, skipped
+}
+
+func TestGenerateBlockWithTransactions(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	vm := &VM{}
+	genesis := newPrefundedGenesis(100_000_000_000_000_000, testEthAddrs[0])
+	genesisBytes, err := genesis.MarshalJSON()
+	require.NoError(err)
+	sender := &enginetest.Sender{T: t}
+	require.NoError(vm.Initialize(
+		ctx,
+		utils.TestSnowContext(),
+		memdb.New(),
+		genesisBytes,
+		nil,
+		nil,
+		make(chan commonEng.Message),
+		nil,
+		sender,
+	))
+	require.NoError(vm.SetState(ctx, snow.NormalOp))
+	defer func() {
+		require.NoError(vm.Shutdown(ctx))
+	}()
+
+	// The second transaction exceeds the block gas limit, and the third one
+	// then has a nonce too high.
+	signer := types.LatestSigner(vm.chainConfig)
+	gasPrice := big.NewInt(params.LaunchMinGasPrice)
+	var txs types.Transactions
+	for i, gas := range []uint64{params.TxGas, params.CortinaGasLimit + 1, params.TxGas} {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), testEthAddrs[1], big.NewInt(1), gas, gasPrice, nil), signer, testKeys[0].ToECDSA())
+		require.NoError(err)
+		txs = append(txs, tx)
+	}
+
+	blk, skipped := generateBlockWithTxs(t, vm, txs)
+	require.Equal([]common.Hash{txs[0].Hash()}, txHashes(blk.ethBlock.Transactions()))
+	require.Len(skipped, 2)
+	require.Equal(txs[1], skipped[0].Tx)
+	require.ErrorIs(skipped[0].Err, core.ErrGasLimitReached)
+	require.Equal(txs[2], skipped[1].Tx)
+	require.ErrorIs(skipped[1].Err, vmerrs.ErrNonceTooHigh)
+
+	// The block is not inserted in the chain.
+	require.Equal(blk.ethBlock.ParentHash(), vm.blockChain.LastAcceptedBlock().Hash())
+	require.False(vm.blockChain.HasBlock(blk.ethBlock.Hash(), blk.ethBlock.NumberU64()))
+}
+
+func txHashes(txs types.Transactions) []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
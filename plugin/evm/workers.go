@@ -0,0 +1,134 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultWorkerStopTimeout is how long the VM waits for its background
+// workers to return on shutdown before reporting them as stuck.
+const defaultWorkerStopTimeout = 10 * time.Second
+
+var errStuckWorkers = errors.New("background workers did not stop")
+
+// workerRegistry tracks the background goroutines started by the VM, so that
+// they can all be stopped on shutdown and any which fail to stop are reported
+// by name.
+type workerRegistry struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	stopTimeout time.Duration
+
+	lock    sync.Mutex
+	stopped bool
+	// timedOut is set once the stop deadline has passed with workers still
+	// running
+	timedOut bool
+	// running maps each running worker to its name
+	running map[*worker]string
+}
+
+type worker struct {
+	done chan struct{}
+}
+
+func newWorkerRegistry(stopTimeout time.Duration) *workerRegistry {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerRegistry{
+		ctx:         ctx,
+		cancel:      cancel,
+		stopTimeout: stopTimeout,
+		running:     make(map[*worker]string),
+	}
+}
+
+// Go runs [fn] in a new goroutine tracked under [name]. The context passed to
+// [fn] is cancelled on shutdown, after which [fn] is expected to return.
+// If the registry has already been shut down, [fn] is not run.
+func (w *workerRegistry) Go(name string, fn func(ctx context.Context)) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.stopped {
+		log.Warn("not starting background worker after shutdown", "name", name)
+		return
+	}
+	wk := &worker{done: make(chan struct{})}
+	w.running[wk] = name
+	go func() {
+		defer func() {
+			w.lock.Lock()
+			delete(w.running, wk)
+			w.lock.Unlock()
+			close(wk.done)
+		}()
+		fn(w.ctx)
+	}()
+}
+
+// Shutdown cancels the context of all workers and waits until they return or
+// the stop deadline passes. Workers still running at the deadline are logged
+// by name and returned as an error, which is also reported by [Err] until they
+// return. Subsequent calls return [Err].
+func (w *workerRegistry) Shutdown() error {
+	w.lock.Lock()
+	if w.stopped {
+		w.lock.Unlock()
+		return w.Err()
+	}
+	w.stopped = true
+	running := make([]*worker, 0, len(w.running))
+	for wk := range w.running {
+		running = append(running, wk)
+	}
+	w.lock.Unlock()
+
+	w.cancel()
+
+	deadline := time.NewTimer(w.stopTimeout)
+	defer deadline.Stop()
+
+	for _, wk := range running {
+		select {
+		case <-wk.done:
+		case <-deadline.C:
+			w.lock.Lock()
+			w.timedOut = true
+			w.lock.Unlock()
+
+			err := w.Err()
+			if err != nil {
+				log.Error("background workers did not stop before the deadline", "timeout", w.stopTimeout, "err", err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Err returns an error naming the workers which did not stop before the
+// deadline during [Shutdown] and are still running, or nil if there are none.
+func (w *workerRegistry) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.timedOut || len(w.running) == 0 {
+		return nil
+	}
+	stuck := make([]string, 0, len(w.running))
+	for _, name := range w.running {
+		stuck = append(stuck, name)
+	}
+	sort.Strings(stuck)
+	return fmt.Errorf("%w: %s", errStuckWorkers, strings.Join(stuck, ", "))
+}
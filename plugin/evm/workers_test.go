@@ -0,0 +1,94 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestWorkerRegistryShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	require := require.New(t)
+
+	w := newWorkerRegistry(time.Minute)
+	started := make(chan struct{}, 2)
+	for _, name := range []string{"a", "b"} {
+		w.Go(name, func(ctx context.Context) {
+			started <- struct{}{}
+			<-ctx.Done()
+		})
+	}
+	<-started
+	<-started
+
+	require.NoError(w.Shutdown())
+	require.NoError(w.Err())
+	require.Empty(w.running)
+
+	// Workers are not started after shutdown.
+	w.Go("late", func(context.Context) {
+		require.FailNow("late worker should not run")
+	})
+	require.Empty(w.running)
+	require.NoError(w.Shutdown())
+}
+
+func TestWorkerRegistryStuckWorkers(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+	require := require.New(t)
+
+	w := newWorkerRegistry(10 * time.Millisecond)
+	release := make(chan struct{})
+	for _, name := range []string{"stuck_b", "stuck_a"} {
+		w.Go(name, func(context.Context) {
+			<-release
+		})
+	}
+	w.Go("stops", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	err := w.Shutdown()
+	require.ErrorIs(err, errStuckWorkers)
+	require.ErrorContains(err, "stuck_a, stuck_b")
+	require.NotContains(err.Error(), "stops")
+	require.Equal(err, w.Err())
+
+	// Workers are no longer reported once they return.
+	close(release)
+	require.Eventually(func() bool {
+		return w.Err() == nil
+	}, time.Second, time.Millisecond)
+	w.lock.Lock()
+	require.Empty(w.running)
+	w.lock.Unlock()
+}
+
+func TestVMShutdownStopsWorkers(t *testing.T) {
+	defer goleak.VerifyNone(t,
+		goleak.IgnoreCurrent(),
+		goleak.IgnoreTopFunction("github.com/ava-labs/coreth/core/state/snapshot.(*diskLayer).generate"),
+		goleak.IgnoreTopFunction("github.com/ava-labs/coreth/metrics.(*meterArbiter).tick"),
+		goleak.IgnoreTopFunction("github.com/syndtr/goleveldb/leveldb.(*DB).mpoolDrain"),
+	)
+	require := require.New(t)
+
+	_, vm, _, _, _ := GenesisVM(t, true, "", "", "")
+	vm.workers.lock.Lock()
+	require.NotEmpty(vm.workers.running)
+	vm.workers.lock.Unlock()
+
+	require.NoError(vm.Shutdown(context.Background()))
+	vm.workers.lock.Lock()
+	require.Empty(vm.workers.running)
+	vm.workers.lock.Unlock()
+
+	_, err := vm.HealthCheck(context.Background())
+	require.NoError(err)
+}
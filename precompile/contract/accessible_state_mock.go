@@ -0,0 +1,32 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import "go.uber.org/mock/gomock"
+
+// VerifyCallOrder requires the expected [calls] to be made in the given order,
+// so that a call made before the previous ones is reported as unexpected. The
+// calls may be expected from any mock of the same controller.
+func (mr *MockAccessibleStateMockRecorder) VerifyCallOrder(calls ...*gomock.Call) {
+	mr.mock.ctrl.T.Helper()
+	args := make([]any, len(calls))
+	for i, call := range calls {
+		args[i] = call
+	}
+	gomock.InOrder(args...)
+}
+
+// ExpectReadOnly configures the mock as a state accessor of a static call,
+// returning true from any number of calls to IsReadOnly.
+func (mr *MockAccessibleStateMockRecorder) ExpectReadOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.IsReadOnly().Return(true).AnyTimes()
+}
+
+// ExpectMutable configures the mock as a state accessor of a call allowed to
+// modify the state, returning false from any number of calls to IsReadOnly.
+func (mr *MockAccessibleStateMockRecorder) ExpectMutable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.IsReadOnly().Return(false).AnyTimes()
+}
@@ -0,0 +1,104 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fatalRecorder is a gomock.TestReporter recording the errors of a
+// controller. Like testing.T, Fatalf stops the goroutine calling it.
+type fatalRecorder struct {
+	errors []string
+}
+
+func (r *fatalRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// callMock calls [f] in a goroutine, which is stopped by a fatal error of the
+// controller of the mocks called.
+func callMock(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+func TestMockAccessibleStateVerifyCallOrder(t *testing.T) {
+	require := require.New(t)
+
+	recorder := &fatalRecorder{}
+	ctrl := gomock.NewController(recorder)
+	state := NewMockAccessibleState(ctrl)
+	stateDB := NewMockStateDB(ctrl)
+	state.EXPECT().VerifyCallOrder(
+		state.EXPECT().GetStateDB().Return(stateDB),
+		stateDB.EXPECT().GetNonce(common.Address{0x1}).Return(uint64(1)),
+		state.EXPECT().GetTxIndex().Return(uint(2)),
+	)
+
+	// The calls made after the previous ones succeed.
+	var nonce uint64
+	callMock(func() {
+		nonce = state.GetStateDB().GetNonce(common.Address{0x1})
+	})
+	require.Empty(recorder.errors)
+	require.Equal(uint64(1), nonce)
+
+	// A call made before the previous ones fails.
+	state.EXPECT().VerifyCallOrder(
+		state.EXPECT().GetBlockContext().Return(nil),
+		state.EXPECT().GetGenesisHash().Return(common.Hash{0x1}),
+	)
+	callMock(func() {
+		state.GetGenesisHash()
+	})
+	require.Len(recorder.errors, 1)
+	require.Contains(recorder.errors[0], "GetGenesisHash")
+	require.Contains(recorder.errors[0], "prerequisite call")
+
+	var (
+		genesisHash common.Hash
+		txIndex     uint
+	)
+	callMock(func() {
+		state.GetBlockContext()
+		genesisHash = state.GetGenesisHash()
+		txIndex = state.GetTxIndex()
+	})
+	require.Len(recorder.errors, 1)
+	require.Equal(common.Hash{0x1}, genesisHash)
+	require.Equal(uint(2), txIndex)
+	ctrl.Finish()
+	require.Len(recorder.errors, 1)
+}
+
+func TestMockAccessibleStateExpectReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	readOnly := NewMockAccessibleState(ctrl)
+	readOnly.EXPECT().ExpectReadOnly()
+	mutable := NewMockAccessibleState(ctrl)
+	mutable.EXPECT().ExpectMutable()
+
+	for i := 0; i < 2; i++ {
+		require.True(readOnly.IsReadOnly())
+		require.False(mutable.IsReadOnly())
+	}
+}
@@ -0,0 +1,69 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/mock/gomock"
+)
+
+var _ StateDB = (*BalanceTrackingStateDB)(nil)
+
+// BalanceTrackingStateDB is a MockStateDB which accumulates the net balance
+// change of each account instead of matching the calls to AddBalance and
+// SubBalance against expectations, so that tests can assert the transfers of
+// a precompile without depending on how they are split into calls. The other
+// methods are mocked as usual.
+//
+// Calls to AddBalance and SubBalance are not recorded by the controller, so
+// they are not counted by AssertCallCounts.
+type BalanceTrackingStateDB struct {
+	*MockStateDB
+
+	netChanges map[common.Address]*big.Int
+}
+
+// NewBalanceTrackingStateDB returns a BalanceTrackingStateDB mocked by [ctrl]
+// with no balance changes.
+func NewBalanceTrackingStateDB(ctrl *gomock.Controller) *BalanceTrackingStateDB {
+	return &BalanceTrackingStateDB{
+		MockStateDB: NewMockStateDB(ctrl),
+		netChanges:  make(map[common.Address]*big.Int),
+	}
+}
+
+// AddBalance adds [amount] to the net balance change of [addr].
+func (s *BalanceTrackingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	change := s.netChange(addr)
+	change.Add(change, amount)
+}
+
+// SubBalance subtracts [amount] from the net balance change of [addr].
+func (s *BalanceTrackingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	change := s.netChange(addr)
+	change.Sub(change, amount)
+}
+
+// NetChange returns the sum of the amounts added to the balance of [addr],
+// minus the sum of the amounts subtracted from it. The returned value is a
+// copy and can be modified by the caller.
+func (s *BalanceTrackingStateDB) NetChange(addr common.Address) *big.Int {
+	if change, ok := s.netChanges[addr]; ok {
+		return new(big.Int).Set(change)
+	}
+	return new(big.Int)
+}
+
+// netChange returns the net balance change of [addr], initializing it to zero
+// if the balance of [addr] was not changed yet.
+func (s *BalanceTrackingStateDB) netChange(addr common.Address) *big.Int {
+	change, ok := s.netChanges[addr]
+	if !ok {
+		change = new(big.Int)
+		s.netChanges[addr] = change
+	}
+	return change
+}
@@ -0,0 +1,41 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBalanceTrackingStateDB(t *testing.T) {
+	require := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	stateDB := NewBalanceTrackingStateDB(ctrl)
+
+	sender := common.Address{0x1}
+	recipient := common.Address{0x2}
+	stateDB.EXPECT().GetBalance(sender).Return(big.NewInt(100))
+
+	// Balance changes do not need expectations, while the other methods are
+	// mocked as usual.
+	require.Equal(big.NewInt(100), stateDB.GetBalance(sender))
+	stateDB.SubBalance(sender, big.NewInt(30))
+	stateDB.AddBalance(recipient, big.NewInt(20))
+	stateDB.AddBalance(recipient, big.NewInt(10))
+	stateDB.SubBalance(sender, big.NewInt(5))
+	stateDB.AddBalance(sender, big.NewInt(5))
+
+	require.Equal(big.NewInt(-30), stateDB.NetChange(sender))
+	require.Equal(big.NewInt(30), stateDB.NetChange(recipient))
+	require.Zero(stateDB.NetChange(common.Address{0x3}).Sign())
+
+	// Modifying a returned change does not modify the tracked change.
+	stateDB.NetChange(recipient).SetInt64(0)
+	require.Equal(big.NewInt(30), stateDB.NetChange(recipient))
+}
@@ -0,0 +1,85 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+var errUnsupportedController = errors.New("unsupported gomock controller")
+
+// AssertCallCounts asserts that each method of [mock] named in [expected] was
+// called exactly the expected number of times, across all the expectations
+// recorded for the method. Methods not named in [expected] are not checked.
+// It must be called once the calls under test have returned.
+//
+// gomock does not expose the calls made to a mock, so they are counted from
+// the call records of the controller of [mock].
+func AssertCallCounts(t testing.TB, mock *MockStateDB, expected map[string]int) {
+	t.Helper()
+
+	have, err := callCounts(mock.ctrl, mock)
+	if err != nil {
+		t.Fatalf("failed to count calls to MockStateDB: %v", err)
+		return
+	}
+
+	methods := make([]string, 0, len(expected))
+	for method := range expected {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+
+	var diff strings.Builder
+	for _, method := range methods {
+		if want := expected[method]; have[method] != want {
+			fmt.Fprintf(&diff, "\n\t%s: want %d calls, have %d", method, want, have[method])
+		}
+	}
+	if diff.Len() > 0 {
+		t.Errorf("unexpected MockStateDB call counts:%s", diff.String())
+	}
+}
+
+// callCounts returns the number of calls made to each method of [receiver],
+// read from the expected and exhausted calls of [ctrl].
+func callCounts(ctrl *gomock.Controller, receiver any) (map[string]int, error) {
+	callSet := reflect.ValueOf(ctrl).Elem().FieldByName("expectedCalls")
+	if callSet.Kind() != reflect.Pointer || callSet.IsNil() {
+		return nil, fmt.Errorf("%w: no expected calls", errUnsupportedController)
+	}
+	receiverPtr := reflect.ValueOf(receiver).Pointer()
+
+	counts := make(map[string]int)
+	for _, field := range []string{"expected", "exhausted"} {
+		calls := callSet.Elem().FieldByName(field)
+		if calls.Kind() != reflect.Map {
+			return nil, fmt.Errorf("%w: no %s calls", errUnsupportedController, field)
+		}
+		iter := calls.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			recv := key.FieldByName("receiver")
+			if recv.Kind() != reflect.Interface || recv.IsNil() || recv.Elem().Kind() != reflect.Pointer || recv.Elem().Pointer() != receiverPtr {
+				continue
+			}
+			method := key.FieldByName("fname").String()
+			for i := 0; i < iter.Value().Len(); i++ {
+				numCalls := iter.Value().Index(i).Elem().FieldByName("numCalls")
+				if numCalls.Kind() != reflect.Int {
+					return nil, fmt.Errorf("%w: no call count", errUnsupportedController)
+				}
+				counts[method] += int(numCalls.Int())
+			}
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,69 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// errorRecorder is a testing.TB recording the errors reported to it.
+type errorRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *errorRecorder) Helper() {}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *errorRecorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestAssertCallCounts(t *testing.T) {
+	require := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	stateDB := NewMockStateDB(ctrl)
+	otherStateDB := NewMockStateDB(ctrl)
+
+	addr := common.Address{0x1}
+	stateDB.EXPECT().GetState(addr, common.Hash{0x1}).Return(common.Hash{0x2}).Times(2)
+	stateDB.EXPECT().GetState(addr, gomock.Any()).Return(common.Hash{}).AnyTimes()
+	stateDB.EXPECT().SetState(addr, gomock.Any(), gomock.Any()).AnyTimes()
+	otherStateDB.EXPECT().SetState(addr, gomock.Any(), gomock.Any()).AnyTimes()
+
+	// Calls matching exhausted and unexhausted expectations are counted,
+	// but not the calls to other mocks.
+	stateDB.GetState(addr, common.Hash{0x1})
+	stateDB.GetState(addr, common.Hash{0x1})
+	stateDB.GetState(addr, common.Hash{0x3})
+	stateDB.SetState(addr, common.Hash{}, common.Hash{})
+	otherStateDB.SetState(addr, common.Hash{}, common.Hash{})
+
+	AssertCallCounts(t, stateDB, map[string]int{
+		"GetState": 3,
+		"SetState": 1,
+		"Exist":    0,
+	})
+
+	recorder := &errorRecorder{TB: t}
+	AssertCallCounts(recorder, stateDB, map[string]int{
+		"SetState": 2,
+		"GetState": 3,
+		"Exist":    1,
+	})
+	require.Equal([]string{
+		"unexpected MockStateDB call counts:" +
+			"\n\tExist: want 1 calls, have 0" +
+			"\n\tSetState: want 2 calls, have 1",
+	}, recorder.errors)
+}
@@ -0,0 +1,124 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrNoConfigReader    = errors.New("no config reader registered for address")
+	errReadOutsideModule = errors.New("config reader attempted to read outside of its module's storage")
+
+	// configReaders maps the address of each registered module which
+	// implements ConfigReader to its reader.
+	configReaders = make(map[common.Address]ConfigReader)
+)
+
+// StateReader is a read-only view of a precompile's storage.
+type StateReader interface {
+	GetState(common.Address, common.Hash) common.Hash
+}
+
+// ConfigReader is an optional capability of a module's Configurator which
+// allows other precompiles to read the module's current configuration.
+type ConfigReader interface {
+	// ReadConfig deserializes the configuration stored by the module at [addr].
+	ReadConfig(state StateReader, addr common.Address) (interface{}, error)
+}
+
+// RegisterConfigReader registers [reader] as the ConfigReader of the module at
+// [addr]. This is called when a module implementing ConfigReader is registered
+// and should not be called directly.
+func RegisterConfigReader(addr common.Address, reader ConfigReader) error {
+	if _, exists := configReaders[addr]; exists {
+		return fmt.Errorf("config reader already registered for address %s", addr)
+	}
+	configReaders[addr] = reader
+	return nil
+}
+
+// ReadModuleConfig returns the current configuration of the module at [addr]
+// as read from [state]. The module's ConfigReader is only given read access
+// to the storage of [addr]. No gas is charged, so precompiles should use
+// ReadModuleConfigWithGas instead.
+func ReadModuleConfig(state StateDB, addr common.Address) (interface{}, error) {
+	reader := &moduleStateReader{
+		state: state,
+		addr:  addr,
+	}
+	return reader.readConfig()
+}
+
+// ReadModuleConfigWithGas returns the current configuration of the module at
+// [addr], charging the gas schedule's StoreRead cost for each storage slot
+// read by the module's ConfigReader.
+func ReadModuleConfigWithGas(accessibleState AccessibleState, addr common.Address, suppliedGas uint64) (interface{}, uint64, error) {
+	reader := &moduleStateReader{
+		state:        accessibleState.GetStateDB(),
+		addr:         addr,
+		metered:      true,
+		gasPerSlot:   accessibleState.GetGasSchedule().StoreRead,
+		remainingGas: suppliedGas,
+	}
+	config, err := reader.readConfig()
+	if errors.Is(err, ErrNoConfigReader) {
+		return nil, suppliedGas, err
+	}
+	if err != nil {
+		return nil, reader.remainingGas, err
+	}
+	return config, reader.remainingGas, nil
+}
+
+// moduleStateReader restricts a ConfigReader to reading the storage of a
+// single module and optionally meters the slots it reads.
+type moduleStateReader struct {
+	state StateDB
+	addr  common.Address
+
+	metered      bool
+	gasPerSlot   uint64
+	remainingGas uint64
+
+	// err is the first error encountered while reading state. Once set, all
+	// subsequent reads return the empty hash.
+	err error
+}
+
+func (r *moduleStateReader) GetState(addr common.Address, key common.Hash) common.Hash {
+	if r.err != nil {
+		return common.Hash{}
+	}
+	if addr != r.addr {
+		r.err = fmt.Errorf("%w: %s", errReadOutsideModule, addr)
+		return common.Hash{}
+	}
+	if r.metered {
+		if r.remainingGas, r.err = DeductGas(r.remainingGas, r.gasPerSlot); r.err != nil {
+			return common.Hash{}
+		}
+	}
+	return r.state.GetState(addr, key)
+}
+
+func (r *moduleStateReader) readConfig() (interface{}, error) {
+	configReader, ok := configReaders[r.addr]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNoConfigReader, r.addr)
+	}
+	config, err := configReader.ReadConfig(r, r.addr)
+	// Errors from reading state take precedence, since they may have caused
+	// the config reader to fail.
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
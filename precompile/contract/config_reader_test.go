@@ -0,0 +1,181 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"testing"
+
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	gasLimitSlot  = common.Hash{0}
+	targetGasSlot = common.Hash{1}
+)
+
+// testStorageStateDB is a StateDB which only supports storage access.
+type testStorageStateDB struct {
+	StateDB
+	storage map[common.Address]map[common.Hash]common.Hash
+}
+
+func newTestStorageStateDB() *testStorageStateDB {
+	return &testStorageStateDB{
+		storage: make(map[common.Address]map[common.Hash]common.Hash),
+	}
+}
+
+func (s *testStorageStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	return s.storage[addr][key]
+}
+
+func (s *testStorageStateDB) SetState(addr common.Address, key common.Hash, value common.Hash) {
+	if s.storage[addr] == nil {
+		s.storage[addr] = make(map[common.Hash]common.Hash)
+	}
+	s.storage[addr][key] = value
+}
+
+type testFeeConfig struct {
+	GasLimit  common.Hash
+	TargetGas common.Hash
+}
+
+// testFeeConfigReader reads a testFeeConfig from two storage slots. If
+// [writeAttempt] is set, it also attempts to modify state through [state].
+type testFeeConfigReader struct {
+	writeAttempt   func(state StateReader) bool
+	writeSucceeded bool
+	otherAddr      *common.Address
+}
+
+func (r *testFeeConfigReader) ReadConfig(state StateReader, addr common.Address) (interface{}, error) {
+	if r.writeAttempt != nil {
+		r.writeSucceeded = r.writeAttempt(state)
+	}
+	if r.otherAddr != nil {
+		state.GetState(*r.otherAddr, gasLimitSlot)
+	}
+	return &testFeeConfig{
+		GasLimit:  state.GetState(addr, gasLimitSlot),
+		TargetGas: state.GetState(addr, targetGasSlot),
+	}, nil
+}
+
+// readGasLimit is a precompile function which returns the gas limit of the
+// test fee config module at the address given as [input].
+func readGasLimit(accessibleState AccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+	config, remainingGas, err := ReadModuleConfigWithGas(accessibleState, common.BytesToAddress(input), suppliedGas)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	return config.(*testFeeConfig).GasLimit.Bytes(), remainingGas, nil
+}
+
+func newTestAccessibleState(t *testing.T, state StateDB) AccessibleState {
+	ctrl := gomock.NewController(t)
+	accessibleState := NewMockAccessibleState(ctrl)
+	accessibleState.EXPECT().GetStateDB().Return(state).AnyTimes()
+	accessibleState.EXPECT().GetGasSchedule().Return(DefaultGasSchedule()).AnyTimes()
+	return accessibleState
+}
+
+func TestReadModuleConfig(t *testing.T) {
+	require := require.New(t)
+
+	feeAddr := common.HexToAddress("0x0300000000000000000000000000000000000101")
+	require.NoError(RegisterConfigReader(feeAddr, &testFeeConfigReader{}))
+	require.Error(RegisterConfigReader(feeAddr, &testFeeConfigReader{}))
+
+	state := newTestStorageStateDB()
+	state.SetState(feeAddr, gasLimitSlot, common.Hash{31: 8})
+	state.SetState(feeAddr, targetGasSlot, common.Hash{31: 4})
+
+	config, err := ReadModuleConfig(state, feeAddr)
+	require.NoError(err)
+	require.Equal(&testFeeConfig{GasLimit: common.Hash{31: 8}, TargetGas: common.Hash{31: 4}}, config)
+
+	_, err = ReadModuleConfig(state, common.HexToAddress("0x0300000000000000000000000000000000000102"))
+	require.ErrorIs(err, ErrNoConfigReader)
+}
+
+func TestReadModuleConfigFromPrecompile(t *testing.T) {
+	feeAddr := common.HexToAddress("0x0300000000000000000000000000000000000103")
+	rewardAddr := common.HexToAddress("0x0300000000000000000000000000000000000104")
+	require.NoError(t, RegisterConfigReader(feeAddr, &testFeeConfigReader{}))
+
+	// The reader reads 2 slots.
+	readCost := 2 * DefaultGasSchedule().StoreRead
+
+	tests := map[string]struct {
+		suppliedGas          uint64
+		expectedRes          []byte
+		expectedRemainingGas uint64
+		expectedErr          error
+	}{
+		"sufficient gas": {
+			suppliedGas:          readCost + 1,
+			expectedRes:          common.Hash{31: 8}.Bytes(),
+			expectedRemainingGas: 1,
+		},
+		"insufficient gas": {
+			suppliedGas: readCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			state := newTestStorageStateDB()
+			state.SetState(feeAddr, gasLimitSlot, common.Hash{31: 8})
+			accessibleState := newTestAccessibleState(t, state)
+
+			ret, remainingGas, err := readGasLimit(accessibleState, common.Address{}, rewardAddr, feeAddr.Bytes(), test.suppliedGas, false)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedRes, ret)
+			require.Equal(test.expectedRemainingGas, remainingGas)
+		})
+	}
+}
+
+func TestReadModuleConfigIsReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	feeAddr := common.HexToAddress("0x0300000000000000000000000000000000000105")
+	reader := &testFeeConfigReader{
+		writeAttempt: func(state StateReader) bool {
+			writer, ok := state.(interface {
+				SetState(common.Address, common.Hash, common.Hash)
+			})
+			if ok {
+				writer.SetState(common.Address{}, common.Hash{}, common.Hash{1})
+			}
+			return ok
+		},
+	}
+	require.NoError(RegisterConfigReader(feeAddr, reader))
+
+	state := newTestStorageStateDB()
+	state.SetState(feeAddr, gasLimitSlot, common.Hash{31: 8})
+	_, err := ReadModuleConfig(state, feeAddr)
+	require.NoError(err)
+	require.False(reader.writeSucceeded)
+	require.Equal(common.Hash{}, state.GetState(common.Address{}, common.Hash{}))
+}
+
+func TestReadModuleConfigOutsideModule(t *testing.T) {
+	require := require.New(t)
+
+	feeAddr := common.HexToAddress("0x0300000000000000000000000000000000000106")
+	otherAddr := common.HexToAddress("0x0300000000000000000000000000000000000107")
+	require.NoError(RegisterConfigReader(feeAddr, &testFeeConfigReader{otherAddr: &otherAddr}))
+
+	state := newTestStorageStateDB()
+	_, err := ReadModuleConfig(state, feeAddr)
+	require.ErrorIs(err, errReadOutsideModule)
+}
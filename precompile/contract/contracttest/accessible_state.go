@@ -0,0 +1,212 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+// Package contracttest provides an AccessibleState backed by a real in-memory
+// StateDB, so that precompiles can be tested without mocking state access.
+package contracttest
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/coreth/consensus/misc/eip4844"
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/params"
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ava-labs/coreth/utils"
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ contract.AccessibleState = (*TestAccessibleState)(nil)
+	_ contract.BlockContext    = (*TestBlockContext)(nil)
+
+	errNativeAssetCallUnsupported = errors.New("native asset calls are not supported by the test accessible state")
+)
+
+// TestBlockContext is a configurable contract.BlockContext.
+type TestBlockContext struct {
+	BlockNumber            *big.Int
+	BlockTimestamp         uint64
+	BlockBaseFee           *big.Int
+	BlockCoinbase          common.Address
+	BlockMinerFeeRecipient common.Address
+	BlockGasLimit          uint64
+	BlockGasRemaining      uint64
+	// PredicateResults maps each transaction hash and precompile address to
+	// the predicate results returned by GetPredicateResults.
+	PredicateResults map[common.Hash]map[common.Address][]byte
+}
+
+func (b *TestBlockContext) Number() *big.Int         { return b.BlockNumber }
+func (b *TestBlockContext) Timestamp() uint64        { return b.BlockTimestamp }
+func (b *TestBlockContext) BaseFee() *big.Int        { return b.BlockBaseFee }
+func (b *TestBlockContext) Coinbase() common.Address { return b.BlockCoinbase }
+func (b *TestBlockContext) GasLimit() uint64         { return b.BlockGasLimit }
+func (b *TestBlockContext) GetBlockGasRemaining() uint64 {
+	return b.BlockGasRemaining
+}
+
+// GetMinerFeeRecipient returns [BlockMinerFeeRecipient], or [BlockCoinbase]
+// if it is zero.
+func (b *TestBlockContext) GetMinerFeeRecipient() common.Address {
+	if b.BlockMinerFeeRecipient != (common.Address{}) {
+		return b.BlockMinerFeeRecipient
+	}
+	return b.BlockCoinbase
+}
+
+func (b *TestBlockContext) GetPredicateResults(txHash common.Hash, precompileAddress common.Address) ([]byte, bool) {
+	results, ok := b.PredicateResults[txHash][precompileAddress]
+	return results, ok
+}
+
+// SetPredicateResults sets the predicate results of [txHash] for
+// [precompileAddress].
+func (b *TestBlockContext) SetPredicateResults(txHash common.Hash, precompileAddress common.Address, results []byte) {
+	if b.PredicateResults == nil {
+		b.PredicateResults = make(map[common.Hash]map[common.Address][]byte)
+	}
+	if b.PredicateResults[txHash] == nil {
+		b.PredicateResults[txHash] = make(map[common.Address][]byte)
+	}
+	b.PredicateResults[txHash][precompileAddress] = results
+}
+
+// TestAccessibleState is a contract.AccessibleState backed by an in-memory
+// state.StateDB. Its fields may be modified between precompile calls.
+type TestAccessibleState struct {
+	t       testing.TB
+	stateDB state.Database
+
+	StateDB              *state.StateDB
+	BlockContext         *TestBlockContext
+	SnowContext          *snow.Context
+	ChainConfig          precompileconfig.ChainConfig
+	GasSchedule          contract.GasSchedule
+	LatestAcceptedHeight uint64
+	GenesisHash          common.Hash
+	TxIndex              uint
+	ReadOnly             bool
+	// PrecompileConfigs are the configs returned by GetPrecompileConfig for
+	// the enabled precompiles.
+	PrecompileConfigs map[common.Address]precompileconfig.Config
+	// ExcessBlobGas is the excess blob gas of the block, from which
+	// GetBlobGasPrice is computed. It is nil before Cancun.
+	ExcessBlobGas *uint64
+	// PrecompileAddress is the address of the running precompile, whose
+	// storage is accessed by SetPrecompileData and GetPrecompileData.
+	PrecompileAddress common.Address
+}
+
+// NewTestAccessibleState returns a TestAccessibleState with empty state, the
+// test chain config and the test snow context, for a block with number 1.
+func NewTestAccessibleState(t testing.TB) *TestAccessibleState {
+	// Preimages are recorded so that storage can be enumerated after Commit.
+	stateDB := state.NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	statedb, err := state.New(common.Hash{}, stateDB, nil)
+	require.NoError(t, err)
+	return &TestAccessibleState{
+		t:       t,
+		stateDB: stateDB,
+		StateDB: statedb,
+		BlockContext: &TestBlockContext{
+			BlockNumber:       big.NewInt(1),
+			BlockBaseFee:      big.NewInt(params.ApricotPhase3InitialBaseFee),
+			BlockGasLimit:     params.CortinaGasLimit,
+			BlockGasRemaining: params.CortinaGasLimit,
+		},
+		SnowContext: utils.TestSnowContext(),
+		ChainConfig: params.TestChainConfig,
+		GasSchedule: contract.DefaultGasSchedule(),
+	}
+}
+
+func (s *TestAccessibleState) GetStateDB() contract.StateDB                 { return s.StateDB }
+func (s *TestAccessibleState) GetBlockContext() contract.BlockContext       { return s.BlockContext }
+func (s *TestAccessibleState) GetSnowContext() *snow.Context                { return s.SnowContext }
+func (s *TestAccessibleState) GetChainConfig() precompileconfig.ChainConfig { return s.ChainConfig }
+func (s *TestAccessibleState) GetGasSchedule() contract.GasSchedule         { return s.GasSchedule }
+func (s *TestAccessibleState) GetLatestAcceptedHeight() uint64              { return s.LatestAcceptedHeight }
+func (s *TestAccessibleState) GetGenesisHash() common.Hash                  { return s.GenesisHash }
+func (s *TestAccessibleState) GetTxIndex() uint                             { return s.TxIndex }
+func (s *TestAccessibleState) IsReadOnly() bool                             { return s.ReadOnly }
+
+func (s *TestAccessibleState) GetPrecompileConfig(addr common.Address) (precompileconfig.Config, bool) {
+	config, ok := s.PrecompileConfigs[addr]
+	return config, ok
+}
+
+func (s *TestAccessibleState) GetBlobGasPrice() *big.Int {
+	if s.ExcessBlobGas == nil {
+		return new(big.Int)
+	}
+	return eip4844.CalcBlobFee(*s.ExcessBlobGas)
+}
+
+func (s *TestAccessibleState) GetTotalSupply(suppliedGas uint64) (*big.Int, uint64, error) {
+	return contract.TotalSupplyWithGas(s.StateDB.GetTotalSupply, suppliedGas)
+}
+
+func (s *TestAccessibleState) SetPrecompileData(key []byte, value []byte) error {
+	if s.ReadOnly {
+		return vmerrs.ErrWriteProtection
+	}
+	return contract.WritePrecompileData(s.StateDB, s.PrecompileAddress, key, value)
+}
+
+func (s *TestAccessibleState) GetPrecompileData(key []byte) ([]byte, error) {
+	return contract.ReadPrecompileData(s.StateDB, s.PrecompileAddress, key)
+}
+
+func (s *TestAccessibleState) NativeAssetCall(common.Address, []byte, uint64, uint64, bool) ([]byte, uint64, error) {
+	return nil, 0, errNativeAssetCallUnsupported
+}
+
+// Commit commits the state to the in-memory database and replaces StateDB
+// with a new StateDB opened at the committed root, as though the next block
+// were being processed. The block number and timestamp are advanced by one.
+func (s *TestAccessibleState) Commit() common.Hash {
+	s.t.Helper()
+
+	number := s.BlockContext.BlockNumber.Uint64()
+	root, err := s.StateDB.Commit(number, true, false)
+	require.NoError(s.t, err)
+	s.StateDB, err = state.New(root, s.stateDB, nil)
+	require.NoError(s.t, err)
+
+	s.BlockContext.BlockNumber = new(big.Int).SetUint64(number + 1)
+	s.BlockContext.BlockTimestamp++
+	return root
+}
+
+// RequireRevertRestoresStorage takes a snapshot, calls [fn], reverts to the
+// snapshot and fails the test if the storage of [addr] differs from the
+// storage before [fn] was called.
+func (s *TestAccessibleState) RequireRevertRestoresStorage(addr common.Address, fn func()) {
+	s.t.Helper()
+
+	before := s.storage(addr)
+	snapshot := s.StateDB.Snapshot()
+	fn()
+	s.StateDB.RevertToSnapshot(snapshot)
+	require.Equal(s.t, before, s.storage(addr))
+}
+
+func (s *TestAccessibleState) storage(addr common.Address) map[common.Hash]common.Hash {
+	storage := make(map[common.Hash]common.Hash)
+	s.StateDB.ForEachStorage(addr, func(key, value common.Hash) bool {
+		storage[key] = value
+		return true
+	})
+	return storage
+}
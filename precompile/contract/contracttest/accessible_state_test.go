@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package contracttest
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommit(t *testing.T) {
+	require := require.New(t)
+
+	addr := common.Address{1}
+	state := NewTestAccessibleState(t)
+	state.StateDB.SetState(addr, common.Hash{1}, common.Hash{2})
+	root := state.Commit()
+	require.NotEqual(common.Hash{}, root)
+
+	require.Equal(uint64(2), state.GetBlockContext().Number().Uint64())
+	require.Equal(uint64(1), state.GetBlockContext().Timestamp())
+	require.Equal(common.Hash{2}, state.GetStateDB().GetState(addr, common.Hash{1}))
+
+	// Storage committed in an earlier block is restored on revert.
+	state.RequireRevertRestoresStorage(addr, func() {
+		state.StateDB.SetState(addr, common.Hash{1}, common.Hash{3})
+		state.StateDB.SetState(addr, common.Hash{2}, common.Hash{4})
+	})
+	require.Equal(common.Hash{2}, state.GetStateDB().GetState(addr, common.Hash{1}))
+	require.Equal(common.Hash{}, state.GetStateDB().GetState(addr, common.Hash{2}))
+}
+
+func TestPredicateResults(t *testing.T) {
+	require := require.New(t)
+
+	txHash := common.Hash{1}
+	addr := common.Address{2}
+	blockContext := NewTestAccessibleState(t).BlockContext
+
+	_, ok := blockContext.GetPredicateResults(txHash, addr)
+	require.False(ok)
+
+	blockContext.SetPredicateResults(txHash, addr, []byte{})
+	results, ok := blockContext.GetPredicateResults(txHash, addr)
+	require.True(ok)
+	require.Empty(results)
+}
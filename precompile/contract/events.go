@@ -0,0 +1,144 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/coreth/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxLogTopics is the maximum number of topics of a log, as emitted by LOG4.
+const maxLogTopics = 4
+
+var (
+	ErrEventNotFound          = errors.New("event not found in ABI")
+	ErrEventSignatureMismatch = errors.New("log topic does not match event signature")
+	ErrUnsupportedIndexedType = errors.New("unsupported indexed argument type")
+
+	errEventArgCount   = errors.New("unexpected number of event arguments")
+	errTooManyTopics   = errors.New("too many log topics")
+	errTopicTypeNotMet = errors.New("indexed argument does not match its ABI type")
+)
+
+// PackEvent packs [args] as the topics and data of a log of [event]. The
+// order of [args] must match the order of the event's inputs.
+//
+// Indexed arguments are encoded into topics according to their ABI type, so
+// that a Go value of the wrong type is rejected rather than mis-encoded.
+// Indexed string and bytes arguments are stored as the keccak256 hash of
+// their contents. Indexed arrays and structs are not supported. The event
+// signature is the first topic unless [event] is anonymous.
+func PackEvent(event abi.Event, args ...interface{}) ([]common.Hash, []byte, error) {
+	if len(args) != len(event.Inputs) {
+		return nil, nil, fmt.Errorf("%w for %s: have %d, want %d", errEventArgCount, event.Name, len(args), len(event.Inputs))
+	}
+
+	var (
+		topics           = make([]common.Hash, 0, maxLogTopics)
+		nonIndexedArgs   abi.Arguments
+		nonIndexedValues []interface{}
+	)
+	if !event.Anonymous {
+		topics = append(topics, event.ID)
+	}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexedArgs = append(nonIndexedArgs, input)
+			nonIndexedValues = append(nonIndexedValues, args[i])
+			continue
+		}
+		topic, err := packEventTopic(input, args[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		topics = append(topics, topic)
+	}
+	if len(topics) > maxLogTopics {
+		return nil, nil, fmt.Errorf("%w for %s: %d", errTooManyTopics, event.Name, len(topics))
+	}
+
+	data, err := nonIndexedArgs.Pack(nonIndexedValues...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pack %s data: %w", event.Name, err)
+	}
+	return topics, data, nil
+}
+
+// packEventTopic encodes [value] as the topic of the indexed argument [input].
+func packEventTopic(input abi.Argument, value interface{}) (common.Hash, error) {
+	switch input.Type.T {
+	case abi.StringTy:
+		str, ok := value.(string)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("%w: %s has type %T", errTopicTypeNotMet, input.Name, value)
+		}
+		return crypto.Keccak256Hash([]byte(str)), nil
+	case abi.BytesTy:
+		b, ok := value.([]byte)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("%w: %s has type %T", errTopicTypeNotMet, input.Name, value)
+		}
+		return crypto.Keccak256Hash(b), nil
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return common.Hash{}, fmt.Errorf("%w: %s has type %s", ErrUnsupportedIndexedType, input.Name, input.Type)
+	}
+	// Value types are stored as their 32 byte ABI encoding.
+	packed, err := abi.Arguments{{Type: input.Type}}.Pack(value)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %s: %w", errTopicTypeNotMet, input.Name, err)
+	}
+	return common.BytesToHash(packed), nil
+}
+
+// EmitEvent packs [args] as a log of [event] using PackEvent and adds it to
+// [stateDB] as emitted by [addr] in block [blockNumber].
+func EmitEvent(stateDB StateDB, addr common.Address, blockNumber uint64, event abi.Event, args ...interface{}) error {
+	topics, data, err := PackEvent(event, args...)
+	if err != nil {
+		return err
+	}
+	stateDB.AddLog(addr, topics, data, blockNumber)
+	return nil
+}
+
+// EventDecoder returns a function which decodes logs of the event [eventName]
+// in [contractABI] into a map from argument name to value. It is intended for
+// off-chain parsing of events emitted by precompiles, using the same ABI used
+// to emit them.
+//
+// The decoder accepts the topics and data of a log rather than a *types.Log,
+// since core/types can not be imported here. Indexed arguments of dynamic
+// types are decoded as the keccak256 hash stored in their topic.
+func EventDecoder(contractABI abi.ABI, eventName string) func(topics []common.Hash, data []byte) (map[string]interface{}, error) {
+	event, ok := contractABI.Events[eventName]
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return func(topics []common.Hash, data []byte) (map[string]interface{}, error) {
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventName)
+		}
+		if !event.Anonymous {
+			if len(topics) == 0 || topics[0] != event.ID {
+				return nil, fmt.Errorf("%w: %s", ErrEventSignatureMismatch, event.Sig)
+			}
+			topics = topics[1:]
+		}
+		out := make(map[string]interface{}, len(event.Inputs))
+		if err := event.Inputs.UnpackIntoMap(out, data); err != nil {
+			return nil, fmt.Errorf("failed to unpack %s data: %w", eventName, err)
+		}
+		if err := abi.ParseTopicsIntoMap(out, indexed, topics); err != nil {
+			return nil, fmt.Errorf("failed to parse %s topics: %w", eventName, err)
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,290 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testEventsABI = `[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}
+	]},
+	{"type":"event","name":"Memo","inputs":[
+		{"name":"tag","type":"string","indexed":true},
+		{"name":"note","type":"string","indexed":false},
+		{"name":"payload","type":"bytes","indexed":false}
+	]},
+	{"type":"event","name":"Flagged","anonymous":true,"inputs":[
+		{"name":"id","type":"uint64","indexed":true},
+		{"name":"flag","type":"bool","indexed":false}
+	]},
+	{"type":"event","name":"Ping","inputs":[]},
+	{"type":"event","name":"Typed","inputs":[
+		{"name":"delta","type":"int8","indexed":true},
+		{"name":"id","type":"bytes32","indexed":true},
+		{"name":"ok","type":"bool","indexed":true},
+		{"name":"amount","type":"uint64","indexed":false}
+	]},
+	{"type":"event","name":"Overflow","inputs":[
+		{"name":"a","type":"uint256","indexed":true},
+		{"name":"b","type":"uint256","indexed":true},
+		{"name":"c","type":"uint256","indexed":true},
+		{"name":"d","type":"uint256","indexed":true}
+	]},
+	{"type":"event","name":"Amounts","inputs":[
+		{"name":"amounts","type":"uint256[]","indexed":true}
+	]}
+]`
+
+func TestEventDecoderRoundTrip(t *testing.T) {
+	contractABI := ParseABI(testEventsABI)
+	from := common.HexToAddress("0x0100000000000000000000000000000000000001")
+	to := common.HexToAddress("0x0200000000000000000000000000000000000002")
+
+	tests := map[string]struct {
+		event    string
+		args     []interface{}
+		expected map[string]interface{}
+	}{
+		"indexed and non-indexed": {
+			event: "Transfer",
+			args:  []interface{}{from, to, big.NewInt(1000)},
+			expected: map[string]interface{}{
+				"from":  from,
+				"to":    to,
+				"value": big.NewInt(1000),
+			},
+		},
+		"dynamic types": {
+			event: "Memo",
+			args:  []interface{}{"tag", "note", []byte{1, 2, 3}},
+			expected: map[string]interface{}{
+				// Indexed dynamic types are only available as their hash.
+				"tag":     crypto.Keccak256Hash([]byte("tag")),
+				"note":    "note",
+				"payload": []byte{1, 2, 3},
+			},
+		},
+		"anonymous": {
+			event: "Flagged",
+			args:  []interface{}{uint64(7), true},
+			expected: map[string]interface{}{
+				"id":   uint64(7),
+				"flag": true,
+			},
+		},
+		"no arguments": {
+			event:    "Ping",
+			expected: map[string]interface{}{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			topics, data, err := contractABI.PackEvent(test.event, test.args...)
+			require.NoError(err)
+
+			decoded, err := EventDecoder(contractABI, test.event)(topics, data)
+			require.NoError(err)
+			require.Equal(test.expected, decoded)
+		})
+	}
+}
+
+func TestEventDecoderErrors(t *testing.T) {
+	contractABI := ParseABI(testEventsABI)
+	transferTopics, transferData, err := contractABI.PackEvent("Transfer", common.Address{1}, common.Address{2}, big.NewInt(1))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		event       string
+		topics      []common.Hash
+		data        []byte
+		expectedErr error
+	}{
+		"unknown event": {
+			event:       "Missing",
+			topics:      transferTopics,
+			data:        transferData,
+			expectedErr: ErrEventNotFound,
+		},
+		"signature mismatch": {
+			event:       "Memo",
+			topics:      transferTopics,
+			data:        transferData,
+			expectedErr: ErrEventSignatureMismatch,
+		},
+		"missing signature": {
+			event:       "Transfer",
+			data:        transferData,
+			expectedErr: ErrEventSignatureMismatch,
+		},
+		"missing indexed topic": {
+			event:  "Transfer",
+			topics: transferTopics[:2],
+			data:   transferData,
+		},
+		"missing data": {
+			event:  "Transfer",
+			topics: transferTopics,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			_, err := EventDecoder(contractABI, test.event)(test.topics, test.data)
+			require.Error(err)
+			if test.expectedErr != nil {
+				require.ErrorIs(err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPackEvent(t *testing.T) {
+	contractABI := ParseABI(testEventsABI)
+	from := common.HexToAddress("0x0100000000000000000000000000000000000001")
+	to := common.HexToAddress("0x0200000000000000000000000000000000000002")
+	id := common.HexToHash("0x1234")
+
+	tests := map[string]struct {
+		event string
+		args  []interface{}
+	}{
+		"indexed and non-indexed": {
+			event: "Transfer",
+			args:  []interface{}{from, to, big.NewInt(1000)},
+		},
+		"dynamic types": {
+			event: "Memo",
+			args:  []interface{}{"tag", "note", []byte{1, 2, 3}},
+		},
+		"anonymous": {
+			event: "Flagged",
+			args:  []interface{}{uint64(7), true},
+		},
+		"no arguments": {
+			event: "Ping",
+		},
+		"value types": {
+			event: "Typed",
+			args:  []interface{}{int8(-3), id, true, uint64(5)},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			event := contractABI.Events[test.event]
+			topics, data, err := PackEvent(event, test.args...)
+			require.NoError(err)
+
+			// The log matches the ABI encoding of the event.
+			expectedTopics, expectedData, err := contractABI.PackEvent(test.event, test.args...)
+			require.NoError(err)
+			require.Equal(expectedTopics, topics)
+			require.Equal(expectedData, data)
+
+			// The indexed topics match those used by generated bindings to
+			// filter logs of the event.
+			var query [][]interface{}
+			if !event.Anonymous {
+				query = append(query, []interface{}{event.ID})
+			}
+			for i, input := range event.Inputs {
+				if input.Indexed {
+					query = append(query, []interface{}{test.args[i]})
+				}
+			}
+			filterTopics, err := abi.MakeTopics(query...)
+			require.NoError(err)
+			require.Len(topics, len(filterTopics))
+			for i, filter := range filterTopics {
+				require.Equal([]common.Hash{topics[i]}, filter)
+			}
+		})
+	}
+}
+
+func TestPackEventErrors(t *testing.T) {
+	contractABI := ParseABI(testEventsABI)
+
+	tests := map[string]struct {
+		event       string
+		args        []interface{}
+		expectedErr error
+	}{
+		"missing argument": {
+			event:       "Transfer",
+			args:        []interface{}{common.Address{1}, common.Address{2}},
+			expectedErr: errEventArgCount,
+		},
+		"indexed argument of wrong type": {
+			event:       "Typed",
+			args:        []interface{}{uint8(3), common.Hash{}, true, uint64(5)},
+			expectedErr: errTopicTypeNotMet,
+		},
+		"indexed string of wrong type": {
+			event:       "Memo",
+			args:        []interface{}{[]byte("tag"), "note", []byte{}},
+			expectedErr: errTopicTypeNotMet,
+		},
+		"indexed array": {
+			event:       "Amounts",
+			args:        []interface{}{[]*big.Int{big.NewInt(1)}},
+			expectedErr: ErrUnsupportedIndexedType,
+		},
+		"too many topics": {
+			event:       "Overflow",
+			args:        []interface{}{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)},
+			expectedErr: errTooManyTopics,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := PackEvent(contractABI.Events[test.event], test.args...)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestEmitEvent(t *testing.T) {
+	require := require.New(t)
+
+	contractABI := ParseABI(testEventsABI)
+	event := contractABI.Events["Transfer"]
+	addr := common.HexToAddress("0x0300000000000000000000000000000000000001")
+	args := []interface{}{common.Address{1}, common.Address{2}, big.NewInt(3)}
+	topics, data, err := PackEvent(event, args...)
+	require.NoError(err)
+
+	ctrl := gomock.NewController(t)
+	stateDB := NewMockStateDB(ctrl)
+	stateDB.EXPECT().AddLog(addr, topics, data, uint64(10))
+	require.NoError(EmitEvent(stateDB, addr, 10, event, args...))
+
+	// The emitted log can be decoded with the same ABI.
+	decoded, err := EventDecoder(contractABI, "Transfer")(topics, data)
+	require.NoError(err)
+	require.Equal(map[string]interface{}{
+		"from":  common.Address{1},
+		"to":    common.Address{2},
+		"value": big.NewInt(3),
+	}, decoded)
+
+	// Nothing is emitted if the event cannot be packed.
+	require.ErrorIs(EmitEvent(stateDB, addr, 10, event), errEventArgCount)
+}
@@ -0,0 +1,68 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"encoding/json"
+
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// GasSchedule specifies the gas charged by stateful precompiles for common
+// operations.
+type GasSchedule struct {
+	// StoreRead is the cost of reading a single storage slot.
+	StoreRead uint64 `json:"storeRead"`
+	// StoreWrite is the cost of writing a single storage slot.
+	StoreWrite uint64 `json:"storeWrite"`
+	// Log is the base cost of emitting a log.
+	Log uint64 `json:"log"`
+	// LogTopic is the cost of a single log topic.
+	LogTopic uint64 `json:"logTopic"`
+	// LogData is the cost per byte of log data.
+	LogData uint64 `json:"logData"`
+	// CodeDeposit is the cost per byte of code installed at an address.
+	CodeDeposit uint64 `json:"codeDeposit"`
+}
+
+// DefaultGasSchedule returns the gas schedule used when no overrides are
+// specified in the chain config.
+func DefaultGasSchedule() GasSchedule {
+	return GasSchedule{
+		StoreRead:   ReadGasCostPerSlot,
+		StoreWrite:  WriteGasCostPerSlot,
+		Log:         LogGas,
+		LogTopic:    LogTopicGas,
+		LogData:     LogDataGas,
+		CodeDeposit: CodeDepositGas,
+	}
+}
+
+// SetCodeCost returns the cost of installing [codeSize] bytes of code at an
+// address: a storage write for the code hash plus CodeDeposit per byte.
+func (g GasSchedule) SetCodeCost(codeSize int) (uint64, error) {
+	cost, overflow := math.SafeMul(g.CodeDeposit, uint64(codeSize))
+	if overflow {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	cost, overflow = math.SafeAdd(cost, g.StoreWrite)
+	if overflow {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	return cost, nil
+}
+
+// UnmarshalJSON parses [data] on top of the default gas schedule, so that
+// fields omitted from [data] keep their default values.
+func (g *GasSchedule) UnmarshalJSON(data []byte) error {
+	// Alias GasSchedule to avoid recursion
+	type _GasSchedule GasSchedule
+	tmp := _GasSchedule(DefaultGasSchedule())
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*g = GasSchedule(tmp)
+	return nil
+}
@@ -18,20 +18,94 @@ type StatefulPrecompiledContract interface {
 	Run(accessibleState AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
+// Activator is an optional interface for StatefulPrecompiledContracts to implement.
+// If implemented, OnActivate is called once, after Configure, in the block in which
+// the precompile is activated, including the genesis block. It may be used to
+// initialize the precompile's state. An error from OnActivate fails processing of
+// the block.
+//
+// OnActivate receives the same state and block context as Configure rather than
+// an AccessibleState. Upgrades are applied before the block's transactions are
+// executed, and for the genesis block without a chain, so there is no block hash
+// lookup, transaction index, predicate results or call frame to expose. Only the
+// block number and timestamp of the block are available.
+type Activator interface {
+	OnActivate(state StateDB, blockContext ConfigurationBlockContext) error
+}
+
+// TraceOpFunc receives a named sub-operation of a precompile call and the gas
+// it used.
+type TraceOpFunc func(label string, gasUsed uint64)
+
+// ContractTracer is an optional interface for StatefulPrecompiledContracts to implement.
+// If implemented, RunWithTracing is called instead of Run when the EVM is traced, so that
+// the gas used by the call can be attributed to its sub-operations. RunWithTracing must
+// behave exactly as Run, other than calling [traceOp] as each sub-operation completes.
+type ContractTracer interface {
+	RunWithTracing(accessibleState AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp TraceOpFunc) (ret []byte, remainingGas uint64, err error)
+}
+
+// StorageMigrator is an optional interface for the Configurator of a precompile
+// module to implement. If implemented, MigrateStorage is called once for each
+// storage migration of the module scheduled in the upgrade config, in the
+// first block in which the migration is active, to convert the storage of the
+// precompile from the layout of version [from] to the layout of version [to].
+// It is only called if the precompile was enabled in the parent block and is
+// not reconfigured in the block, since otherwise its storage is already
+// written in the new layout. An error from MigrateStorage fails processing of
+// the block.
+type StorageMigrator interface {
+	MigrateStorage(state StateDB, from, to precompileconfig.ConfigVersion) error
+}
+
 // StateDB is the interface for accessing EVM state
 type StateDB interface {
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
+	// GetLastModifiedBlock returns the number of the block in which a storage
+	// slot was last written by SSTORE, or 0 if it has not been written since
+	// tracking was activated.
+	GetLastModifiedBlock(addr common.Address, slot common.Hash) uint64
+
+	// GetTransientState and SetTransientState access EIP-1153 transient
+	// storage, which is discarded at the end of each transaction.
+	// Transient writes are journaled like any other state change, so
+	// RevertToSnapshot also reverts the transient writes made after the
+	// snapshot was taken.
+	GetTransientState(addr common.Address, key common.Hash) common.Hash
+	SetTransientState(addr common.Address, key, value common.Hash)
+
+	// Access list methods allow precompiles that call into dynamically
+	// determined contracts to charge EIP-2929 cold/warm access costs.
+	AddressInAccessList(addr common.Address) bool
+	SlotInAccessList(addr common.Address, slot common.Hash) (addressOk bool, slotOk bool)
+	AddAddressToAccessList(addr common.Address)
+	AddSlotToAccessList(addr common.Address, slot common.Hash)
 
 	SetNonce(common.Address, uint64)
 	GetNonce(common.Address) uint64
 
 	GetBalance(common.Address) *big.Int
 	AddBalance(common.Address, *big.Int)
+	// SubBalance must not be called with more than the balance of the account.
+	// Precompiles must check the balance first and fail the call if it is
+	// insufficient, since the StateDB provided by the EVM panics otherwise.
+	SubBalance(common.Address, *big.Int)
 	GetBalanceMultiCoin(common.Address, common.Hash) *big.Int
 
+	GetCode(common.Address) []byte
+	SetCode(common.Address, []byte)
+	GetCodeHash(common.Address) common.Hash
+
 	CreateAccount(common.Address)
 	Exist(common.Address) bool
+	// Empty reports whether the account does not exist or is empty according
+	// to EIP-161 (zero balance, zero nonce and no code).
+	Empty(common.Address) bool
+	// HasSelfDestructed reports whether the account has self-destructed in the
+	// current transaction. It returns false once the account is recreated with
+	// CreateAccount in the same transaction, unless the creation is reverted.
+	HasSelfDestructed(common.Address) bool
 
 	AddLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64)
 	GetLogData() (topics [][]common.Hash, data [][]byte)
@@ -50,6 +124,50 @@ type AccessibleState interface {
 	GetBlockContext() BlockContext
 	GetSnowContext() *snow.Context
 	GetChainConfig() precompileconfig.ChainConfig
+	// GetPrecompileConfig returns the config of the precompile at [addr]
+	// active in the current block, which precompiles may type-assert to
+	// their own config type to read its fields. It returns false if the
+	// precompile is not enabled.
+	GetPrecompileConfig(addr common.Address) (precompileconfig.Config, bool)
+	// GetGasSchedule returns the gas schedule precompiles should charge
+	// according to the chain config.
+	GetGasSchedule() GasSchedule
+	// GetBlobGasPrice returns the price of blob gas in the current block,
+	// computed from its excess blob gas with the EIP-4844 formula, or 0
+	// before Cancun.
+	GetBlobGasPrice() *big.Int
+	// GetTotalSupply returns the sum of the native balances of all accounts,
+	// deducting [TotalSupplyGasPerAccount] from [suppliedGas] for each account
+	// in the state. Multicoin balances are not included.
+	GetTotalSupply(suppliedGas uint64) (supply *big.Int, remainingGas uint64, err error)
+	// GetLatestAcceptedHeight returns the height of the parent of the block
+	// being processed, or 0 for the genesis block. The value is derived from
+	// the block itself rather than from the node's last accepted block, so
+	// that it is the same on every node executing the block.
+	GetLatestAcceptedHeight() uint64
+	// GetGenesisHash returns the hash of the genesis block of the chain, which
+	// distinguishes networks sharing the same chain ID.
+	GetGenesisHash() common.Hash
+	// GetTxIndex returns the index in its block of the transaction being
+	// executed. During eth_call and other simulations, which are not part of
+	// a block, it is the index of the simulated transaction, usually 0.
+	GetTxIndex() uint
+	// IsReadOnly returns true if the precompile is called in a static context,
+	// such as through STATICCALL or from a frame entered through STATICCALL.
+	// Precompiles must return ErrWriteProtection before mutating any state if
+	// this is the case.
+	IsReadOnly() bool
+	// SetPrecompileData stores [value] under [key] in the storage of the
+	// running precompile, chunked across as many slots as needed behind a
+	// length prefix. The slots are written atomically: if an error is
+	// returned, none of them was modified. Precompiles must charge for the
+	// PrecompileDataSlots written. It returns ErrWriteProtection in a
+	// read-only context.
+	SetPrecompileData(key []byte, value []byte) error
+	// GetPrecompileData returns the value stored under [key] in the storage
+	// of the running precompile by SetPrecompileData, or an empty value if
+	// it was never set.
+	GetPrecompileData(key []byte) ([]byte, error)
 	NativeAssetCall(caller common.Address, input []byte, suppliedGas uint64, gasCost uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
@@ -61,9 +179,27 @@ type ConfigurationBlockContext interface {
 
 type BlockContext interface {
 	ConfigurationBlockContext
-	// GetResults returns an arbitrary byte array result of verifying the predicates
-	// of the given transaction, precompile address pair.
-	GetPredicateResults(txHash common.Hash, precompileAddress common.Address) []byte
+	// BaseFee returns the base fee of the block. Returns nil for blocks prior
+	// to Apricot Phase 3, which do not have a base fee.
+	BaseFee() *big.Int
+	// Coinbase returns the address receiving the block's fees.
+	Coinbase() common.Address
+	// GetMinerFeeRecipient returns the address to which precompiles route
+	// the share of the block's fees which does not go to the coinbase. It is
+	// the fee recipient of the chain config, or the coinbase if there is
+	// none.
+	GetMinerFeeRecipient() common.Address
+	// GasLimit returns the gas limit of the block.
+	GasLimit() uint64
+	// GetPredicateResults returns an arbitrary byte array result of verifying the predicates
+	// of the given transaction, precompile address pair. The boolean is false if no
+	// predicate results were recorded for the pair, which distinguishes a transaction
+	// without predicates for [precompileAddress] from one whose result is empty.
+	GetPredicateResults(txHash common.Hash, precompileAddress common.Address) ([]byte, bool)
+	// GetBlockGasRemaining returns the gas remaining in the block after the
+	// current transaction's gas limit has been reserved. During eth_call and
+	// other simulations, this is the RPC gas cap.
+	GetBlockGasRemaining() uint64
 }
 
 type Configurator interface {
@@ -42,12 +42,83 @@ func (m *MockBlockContext) EXPECT() *MockBlockContextMockRecorder {
 	return m.recorder
 }
 
+// BaseFee mocks base method.
+func (m *MockBlockContext) BaseFee() *big.Int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BaseFee")
+	ret0, _ := ret[0].(*big.Int)
+	return ret0
+}
+
+// BaseFee indicates an expected call of BaseFee.
+func (mr *MockBlockContextMockRecorder) BaseFee() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BaseFee", reflect.TypeOf((*MockBlockContext)(nil).BaseFee))
+}
+
+// Coinbase mocks base method.
+func (m *MockBlockContext) Coinbase() common.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Coinbase")
+	ret0, _ := ret[0].(common.Address)
+	return ret0
+}
+
+// Coinbase indicates an expected call of Coinbase.
+func (mr *MockBlockContextMockRecorder) Coinbase() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Coinbase", reflect.TypeOf((*MockBlockContext)(nil).Coinbase))
+}
+
+// GasLimit mocks base method.
+func (m *MockBlockContext) GasLimit() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasLimit")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GasLimit indicates an expected call of GasLimit.
+func (mr *MockBlockContextMockRecorder) GasLimit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasLimit", reflect.TypeOf((*MockBlockContext)(nil).GasLimit))
+}
+
+// GetBlockGasRemaining mocks base method.
+func (m *MockBlockContext) GetBlockGasRemaining() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockGasRemaining")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetBlockGasRemaining indicates an expected call of GetBlockGasRemaining.
+func (mr *MockBlockContextMockRecorder) GetBlockGasRemaining() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockGasRemaining", reflect.TypeOf((*MockBlockContext)(nil).GetBlockGasRemaining))
+}
+
+// GetMinerFeeRecipient mocks base method.
+func (m *MockBlockContext) GetMinerFeeRecipient() common.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinerFeeRecipient")
+	ret0, _ := ret[0].(common.Address)
+	return ret0
+}
+
+// GetMinerFeeRecipient indicates an expected call of GetMinerFeeRecipient.
+func (mr *MockBlockContextMockRecorder) GetMinerFeeRecipient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinerFeeRecipient", reflect.TypeOf((*MockBlockContext)(nil).GetMinerFeeRecipient))
+}
+
 // GetPredicateResults mocks base method.
-func (m *MockBlockContext) GetPredicateResults(arg0 common.Hash, arg1 common.Address) []byte {
+func (m *MockBlockContext) GetPredicateResults(arg0 common.Hash, arg1 common.Address) ([]byte, bool) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPredicateResults", arg0, arg1)
 	ret0, _ := ret[0].([]byte)
-	return ret0
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
 }
 
 // GetPredicateResults indicates an expected call of GetPredicateResults.
@@ -107,6 +178,20 @@ func (m *MockAccessibleState) EXPECT() *MockAccessibleStateMockRecorder {
 	return m.recorder
 }
 
+// GetBlobGasPrice mocks base method.
+func (m *MockAccessibleState) GetBlobGasPrice() *big.Int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobGasPrice")
+	ret0, _ := ret[0].(*big.Int)
+	return ret0
+}
+
+// GetBlobGasPrice indicates an expected call of GetBlobGasPrice.
+func (mr *MockAccessibleStateMockRecorder) GetBlobGasPrice() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobGasPrice", reflect.TypeOf((*MockAccessibleState)(nil).GetBlobGasPrice))
+}
+
 // GetBlockContext mocks base method.
 func (m *MockAccessibleState) GetBlockContext() BlockContext {
 	m.ctrl.T.Helper()
@@ -135,6 +220,78 @@ func (mr *MockAccessibleStateMockRecorder) GetChainConfig() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChainConfig", reflect.TypeOf((*MockAccessibleState)(nil).GetChainConfig))
 }
 
+// GetGasSchedule mocks base method.
+func (m *MockAccessibleState) GetGasSchedule() GasSchedule {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGasSchedule")
+	ret0, _ := ret[0].(GasSchedule)
+	return ret0
+}
+
+// GetGasSchedule indicates an expected call of GetGasSchedule.
+func (mr *MockAccessibleStateMockRecorder) GetGasSchedule() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGasSchedule", reflect.TypeOf((*MockAccessibleState)(nil).GetGasSchedule))
+}
+
+// GetGenesisHash mocks base method.
+func (m *MockAccessibleState) GetGenesisHash() common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenesisHash")
+	ret0, _ := ret[0].(common.Hash)
+	return ret0
+}
+
+// GetGenesisHash indicates an expected call of GetGenesisHash.
+func (mr *MockAccessibleStateMockRecorder) GetGenesisHash() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenesisHash", reflect.TypeOf((*MockAccessibleState)(nil).GetGenesisHash))
+}
+
+// GetLatestAcceptedHeight mocks base method.
+func (m *MockAccessibleState) GetLatestAcceptedHeight() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestAcceptedHeight")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetLatestAcceptedHeight indicates an expected call of GetLatestAcceptedHeight.
+func (mr *MockAccessibleStateMockRecorder) GetLatestAcceptedHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestAcceptedHeight", reflect.TypeOf((*MockAccessibleState)(nil).GetLatestAcceptedHeight))
+}
+
+// GetPrecompileConfig mocks base method.
+func (m *MockAccessibleState) GetPrecompileConfig(arg0 common.Address) (precompileconfig.Config, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecompileConfig", arg0)
+	ret0, _ := ret[0].(precompileconfig.Config)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetPrecompileConfig indicates an expected call of GetPrecompileConfig.
+func (mr *MockAccessibleStateMockRecorder) GetPrecompileConfig(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecompileConfig", reflect.TypeOf((*MockAccessibleState)(nil).GetPrecompileConfig), arg0)
+}
+
+// GetPrecompileData mocks base method.
+func (m *MockAccessibleState) GetPrecompileData(arg0 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecompileData", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecompileData indicates an expected call of GetPrecompileData.
+func (mr *MockAccessibleStateMockRecorder) GetPrecompileData(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecompileData", reflect.TypeOf((*MockAccessibleState)(nil).GetPrecompileData), arg0)
+}
+
 // GetSnowContext mocks base method.
 func (m *MockAccessibleState) GetSnowContext() *snow.Context {
 	m.ctrl.T.Helper()
@@ -163,6 +320,50 @@ func (mr *MockAccessibleStateMockRecorder) GetStateDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateDB", reflect.TypeOf((*MockAccessibleState)(nil).GetStateDB))
 }
 
+// GetTotalSupply mocks base method.
+func (m *MockAccessibleState) GetTotalSupply(arg0 uint64) (*big.Int, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalSupply", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTotalSupply indicates an expected call of GetTotalSupply.
+func (mr *MockAccessibleStateMockRecorder) GetTotalSupply(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalSupply", reflect.TypeOf((*MockAccessibleState)(nil).GetTotalSupply), arg0)
+}
+
+// GetTxIndex mocks base method.
+func (m *MockAccessibleState) GetTxIndex() uint {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTxIndex")
+	ret0, _ := ret[0].(uint)
+	return ret0
+}
+
+// GetTxIndex indicates an expected call of GetTxIndex.
+func (mr *MockAccessibleStateMockRecorder) GetTxIndex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxIndex", reflect.TypeOf((*MockAccessibleState)(nil).GetTxIndex))
+}
+
+// IsReadOnly mocks base method.
+func (m *MockAccessibleState) IsReadOnly() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReadOnly")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsReadOnly indicates an expected call of IsReadOnly.
+func (mr *MockAccessibleStateMockRecorder) IsReadOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReadOnly", reflect.TypeOf((*MockAccessibleState)(nil).IsReadOnly))
+}
+
 // NativeAssetCall mocks base method.
 func (m *MockAccessibleState) NativeAssetCall(arg0 common.Address, arg1 []byte, arg2, arg3 uint64, arg4 bool) ([]byte, uint64, error) {
 	m.ctrl.T.Helper()
@@ -179,6 +380,20 @@ func (mr *MockAccessibleStateMockRecorder) NativeAssetCall(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NativeAssetCall", reflect.TypeOf((*MockAccessibleState)(nil).NativeAssetCall), arg0, arg1, arg2, arg3, arg4)
 }
 
+// SetPrecompileData mocks base method.
+func (m *MockAccessibleState) SetPrecompileData(arg0, arg1 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrecompileData", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPrecompileData indicates an expected call of SetPrecompileData.
+func (mr *MockAccessibleStateMockRecorder) SetPrecompileData(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrecompileData", reflect.TypeOf((*MockAccessibleState)(nil).SetPrecompileData), arg0, arg1)
+}
+
 // MockStateDB is a mock of StateDB interface.
 type MockStateDB struct {
 	ctrl     *gomock.Controller
@@ -202,6 +417,18 @@ func (m *MockStateDB) EXPECT() *MockStateDBMockRecorder {
 	return m.recorder
 }
 
+// AddAddressToAccessList mocks base method.
+func (m *MockStateDB) AddAddressToAccessList(arg0 common.Address) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddAddressToAccessList", arg0)
+}
+
+// AddAddressToAccessList indicates an expected call of AddAddressToAccessList.
+func (mr *MockStateDBMockRecorder) AddAddressToAccessList(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAddressToAccessList", reflect.TypeOf((*MockStateDB)(nil).AddAddressToAccessList), arg0)
+}
+
 // AddBalance mocks base method.
 func (m *MockStateDB) AddBalance(arg0 common.Address, arg1 *big.Int) {
 	m.ctrl.T.Helper()
@@ -226,6 +453,32 @@ func (mr *MockStateDBMockRecorder) AddLog(arg0, arg1, arg2, arg3 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLog", reflect.TypeOf((*MockStateDB)(nil).AddLog), arg0, arg1, arg2, arg3)
 }
 
+// AddSlotToAccessList mocks base method.
+func (m *MockStateDB) AddSlotToAccessList(arg0 common.Address, arg1 common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddSlotToAccessList", arg0, arg1)
+}
+
+// AddSlotToAccessList indicates an expected call of AddSlotToAccessList.
+func (mr *MockStateDBMockRecorder) AddSlotToAccessList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSlotToAccessList", reflect.TypeOf((*MockStateDB)(nil).AddSlotToAccessList), arg0, arg1)
+}
+
+// AddressInAccessList mocks base method.
+func (m *MockStateDB) AddressInAccessList(arg0 common.Address) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddressInAccessList", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AddressInAccessList indicates an expected call of AddressInAccessList.
+func (mr *MockStateDBMockRecorder) AddressInAccessList(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddressInAccessList", reflect.TypeOf((*MockStateDB)(nil).AddressInAccessList), arg0)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStateDB) CreateAccount(arg0 common.Address) {
 	m.ctrl.T.Helper()
@@ -238,6 +491,20 @@ func (mr *MockStateDBMockRecorder) CreateAccount(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStateDB)(nil).CreateAccount), arg0)
 }
 
+// Empty mocks base method.
+func (m *MockStateDB) Empty(arg0 common.Address) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Empty", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Empty indicates an expected call of Empty.
+func (mr *MockStateDBMockRecorder) Empty(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Empty", reflect.TypeOf((*MockStateDB)(nil).Empty), arg0)
+}
+
 // Exist mocks base method.
 func (m *MockStateDB) Exist(arg0 common.Address) bool {
 	m.ctrl.T.Helper()
@@ -280,6 +547,48 @@ func (mr *MockStateDBMockRecorder) GetBalanceMultiCoin(arg0, arg1 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceMultiCoin", reflect.TypeOf((*MockStateDB)(nil).GetBalanceMultiCoin), arg0, arg1)
 }
 
+// GetCode mocks base method.
+func (m *MockStateDB) GetCode(arg0 common.Address) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCode", arg0)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// GetCode indicates an expected call of GetCode.
+func (mr *MockStateDBMockRecorder) GetCode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCode", reflect.TypeOf((*MockStateDB)(nil).GetCode), arg0)
+}
+
+// GetCodeHash mocks base method.
+func (m *MockStateDB) GetCodeHash(arg0 common.Address) common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCodeHash", arg0)
+	ret0, _ := ret[0].(common.Hash)
+	return ret0
+}
+
+// GetCodeHash indicates an expected call of GetCodeHash.
+func (mr *MockStateDBMockRecorder) GetCodeHash(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCodeHash", reflect.TypeOf((*MockStateDB)(nil).GetCodeHash), arg0)
+}
+
+// GetLastModifiedBlock mocks base method.
+func (m *MockStateDB) GetLastModifiedBlock(arg0 common.Address, arg1 common.Hash) uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastModifiedBlock", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// GetLastModifiedBlock indicates an expected call of GetLastModifiedBlock.
+func (mr *MockStateDBMockRecorder) GetLastModifiedBlock(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastModifiedBlock", reflect.TypeOf((*MockStateDB)(nil).GetLastModifiedBlock), arg0, arg1)
+}
+
 // GetLogData mocks base method.
 func (m *MockStateDB) GetLogData() ([][]common.Hash, [][]byte) {
 	m.ctrl.T.Helper()
@@ -338,6 +647,20 @@ func (mr *MockStateDBMockRecorder) GetState(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetState", reflect.TypeOf((*MockStateDB)(nil).GetState), arg0, arg1)
 }
 
+// GetTransientState mocks base method.
+func (m *MockStateDB) GetTransientState(arg0 common.Address, arg1 common.Hash) common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransientState", arg0, arg1)
+	ret0, _ := ret[0].(common.Hash)
+	return ret0
+}
+
+// GetTransientState indicates an expected call of GetTransientState.
+func (mr *MockStateDBMockRecorder) GetTransientState(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransientState", reflect.TypeOf((*MockStateDB)(nil).GetTransientState), arg0, arg1)
+}
+
 // GetTxHash mocks base method.
 func (m *MockStateDB) GetTxHash() common.Hash {
 	m.ctrl.T.Helper()
@@ -352,6 +675,20 @@ func (mr *MockStateDBMockRecorder) GetTxHash() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTxHash", reflect.TypeOf((*MockStateDB)(nil).GetTxHash))
 }
 
+// HasSelfDestructed mocks base method.
+func (m *MockStateDB) HasSelfDestructed(arg0 common.Address) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSelfDestructed", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasSelfDestructed indicates an expected call of HasSelfDestructed.
+func (mr *MockStateDBMockRecorder) HasSelfDestructed(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSelfDestructed", reflect.TypeOf((*MockStateDB)(nil).HasSelfDestructed), arg0)
+}
+
 // RevertToSnapshot mocks base method.
 func (m *MockStateDB) RevertToSnapshot(arg0 int) {
 	m.ctrl.T.Helper()
@@ -364,6 +701,18 @@ func (mr *MockStateDBMockRecorder) RevertToSnapshot(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertToSnapshot", reflect.TypeOf((*MockStateDB)(nil).RevertToSnapshot), arg0)
 }
 
+// SetCode mocks base method.
+func (m *MockStateDB) SetCode(arg0 common.Address, arg1 []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCode", arg0, arg1)
+}
+
+// SetCode indicates an expected call of SetCode.
+func (mr *MockStateDBMockRecorder) SetCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCode", reflect.TypeOf((*MockStateDB)(nil).SetCode), arg0, arg1)
+}
+
 // SetNonce mocks base method.
 func (m *MockStateDB) SetNonce(arg0 common.Address, arg1 uint64) {
 	m.ctrl.T.Helper()
@@ -400,6 +749,33 @@ func (mr *MockStateDBMockRecorder) SetState(arg0, arg1, arg2 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetState", reflect.TypeOf((*MockStateDB)(nil).SetState), arg0, arg1, arg2)
 }
 
+// SetTransientState mocks base method.
+func (m *MockStateDB) SetTransientState(arg0 common.Address, arg1, arg2 common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTransientState", arg0, arg1, arg2)
+}
+
+// SetTransientState indicates an expected call of SetTransientState.
+func (mr *MockStateDBMockRecorder) SetTransientState(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransientState", reflect.TypeOf((*MockStateDB)(nil).SetTransientState), arg0, arg1, arg2)
+}
+
+// SlotInAccessList mocks base method.
+func (m *MockStateDB) SlotInAccessList(arg0 common.Address, arg1 common.Hash) (bool, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlotInAccessList", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// SlotInAccessList indicates an expected call of SlotInAccessList.
+func (mr *MockStateDBMockRecorder) SlotInAccessList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlotInAccessList", reflect.TypeOf((*MockStateDB)(nil).SlotInAccessList), arg0, arg1)
+}
+
 // Snapshot mocks base method.
 func (m *MockStateDB) Snapshot() int {
 	m.ctrl.T.Helper()
@@ -413,3 +789,15 @@ func (mr *MockStateDBMockRecorder) Snapshot() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockStateDB)(nil).Snapshot))
 }
+
+// SubBalance mocks base method.
+func (m *MockStateDB) SubBalance(arg0 common.Address, arg1 *big.Int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubBalance", arg0, arg1)
+}
+
+// SubBalance indicates an expected call of SubBalance.
+func (mr *MockStateDBMockRecorder) SubBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubBalance", reflect.TypeOf((*MockStateDB)(nil).SubBalance), arg0, arg1)
+}
@@ -0,0 +1,78 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MaxPrecompileDataLen is the maximum length of a value stored with
+// SetPrecompileData.
+const MaxPrecompileDataLen = 64 * 1024
+
+var (
+	ErrPrecompileDataTooLarge = errors.New("precompile data too large")
+
+	// precompileDataPrefix separates the slots of the values stored with
+	// SetPrecompileData from the other slots of a precompile.
+	precompileDataPrefix = []byte("precompileData")
+)
+
+// PrecompileDataSlot returns the slot holding the length of the value stored
+// under [key], which is followed by its data as in a Solidity bytes value
+// (see StorageCursor).
+func PrecompileDataSlot(key []byte) common.Hash {
+	return crypto.Keccak256Hash(precompileDataPrefix, key)
+}
+
+// PrecompileDataSlots returns the number of storage slots written to store a
+// value of [length] bytes, which precompiles should charge for.
+func PrecompileDataSlots(length int) uint64 {
+	return 1 + storageBytesDataSlots(uint64(length))
+}
+
+// WritePrecompileData stores [value] under [key] in the storage of [addr],
+// across as many slots as needed, clearing the slots of the previous value
+// which are no longer used. The slots are written under a single snapshot of
+// [state], so that [key] holds either its previous value or [value]: values
+// which are too large are refused before writing, and if writing panics, for
+// example because [state] does, the partial writes are reverted before the
+// panic propagates.
+func WritePrecompileData(state StateDB, addr common.Address, key []byte, value []byte) error {
+	if len(value) > MaxPrecompileDataLen {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrPrecompileDataTooLarge, len(value), MaxPrecompileDataLen)
+	}
+
+	snapshot := state.Snapshot()
+	written := false
+	defer func() {
+		if !written {
+			state.RevertToSnapshot(snapshot)
+		}
+	}()
+	NewStorageCursor(state, addr, PrecompileDataSlot(key)).WriteBytes(value)
+	written = true
+	return nil
+}
+
+// ReadPrecompileData returns the value stored under [key] in the storage of
+// [addr] by WritePrecompileData. A key which was never written holds an empty
+// value.
+func ReadPrecompileData(state StateDB, addr common.Address, key []byte) ([]byte, error) {
+	// The length is checked before reading the data, so that a corrupted
+	// length does not read up to 4GiB of storage.
+	slot := PrecompileDataSlot(key)
+	length, err := decodeStorageBytesLength(state.GetState(addr, slot))
+	if err != nil {
+		return nil, fmt.Errorf("%w at slot %s", err, slot)
+	}
+	if length > MaxPrecompileDataLen {
+		return nil, fmt.Errorf("%w: stored value of %d bytes exceeds %d", ErrPrecompileDataTooLarge, length, MaxPrecompileDataLen)
+	}
+	return NewStorageCursor(state, addr, slot).ReadBytes()
+}
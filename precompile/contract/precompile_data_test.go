@@ -0,0 +1,113 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package contract_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/contract/contracttest"
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStateDB panics on the [failAt]th call to SetState.
+type failingStateDB struct {
+	contract.StateDB
+	failAt int
+	calls  int
+}
+
+func (s *failingStateDB) SetState(addr common.Address, key, value common.Hash) {
+	s.calls++
+	if s.calls == s.failAt {
+		panic("failed to set state")
+	}
+	s.StateDB.SetState(addr, key, value)
+}
+
+// precompileDataSlots returns the length slot and the first [n] data slots of
+// the value stored under [key] in the storage of [addr].
+func precompileDataSlots(state contract.StateDB, addr common.Address, key []byte, n int) []common.Hash {
+	header := contract.PrecompileDataSlot(key)
+	dataSlot := crypto.Keccak256Hash(header[:]).Big()
+	slots := []common.Hash{state.GetState(addr, header)}
+	for i := 0; i < n; i++ {
+		slot := common.BigToHash(new(big.Int).Add(dataSlot, big.NewInt(int64(i))))
+		slots = append(slots, state.GetState(addr, slot))
+	}
+	return slots
+}
+
+func TestPrecompileData(t *testing.T) {
+	require := require.New(t)
+
+	accessibleState := contracttest.NewTestAccessibleState(t)
+	accessibleState.PrecompileAddress = common.HexToAddress("0xaa")
+	key := []byte("key")
+
+	// A key which was never written holds an empty value.
+	value, err := accessibleState.GetPrecompileData(key)
+	require.NoError(err)
+	require.Empty(value)
+
+	for _, length := range []int{1, common.HashLength - 1, common.HashLength, 100, contract.MaxPrecompileDataLen, 0} {
+		want := bytes.Repeat([]byte{byte(length)}, length)
+		require.NoError(accessibleState.SetPrecompileData(key, want))
+		value, err := accessibleState.GetPrecompileData(key)
+		require.NoError(err)
+		require.Equal(want, value)
+	}
+	// The data slots of the previous values are cleared.
+	require.Equal(make([]common.Hash, 5), precompileDataSlots(accessibleState.StateDB, accessibleState.PrecompileAddress, key, 4))
+
+	// Other keys and precompiles are unaffected.
+	require.NoError(accessibleState.SetPrecompileData(key, []byte("value")))
+	value, err = accessibleState.GetPrecompileData([]byte("other key"))
+	require.NoError(err)
+	require.Empty(value)
+	accessibleState.PrecompileAddress = common.HexToAddress("0xbb")
+	value, err = accessibleState.GetPrecompileData(key)
+	require.NoError(err)
+	require.Empty(value)
+}
+
+func TestPrecompileDataErrors(t *testing.T) {
+	require := require.New(t)
+
+	addr := common.HexToAddress("0xaa")
+	key := []byte("key")
+	accessibleState := contracttest.NewTestAccessibleState(t)
+	accessibleState.PrecompileAddress = addr
+	prev := bytes.Repeat([]byte{1}, 2*common.HashLength)
+	require.NoError(accessibleState.SetPrecompileData(key, prev))
+	before := precompileDataSlots(accessibleState.StateDB, addr, key, 4)
+
+	// Values which are too large are refused.
+	err := accessibleState.SetPrecompileData(key, make([]byte, contract.MaxPrecompileDataLen+1))
+	require.ErrorIs(err, contract.ErrPrecompileDataTooLarge)
+	require.Equal(before, precompileDataSlots(accessibleState.StateDB, addr, key, 4))
+
+	// Writes are refused in a read-only context.
+	accessibleState.ReadOnly = true
+	require.ErrorIs(accessibleState.SetPrecompileData(key, []byte("value")), vmerrs.ErrWriteProtection)
+	require.Equal(before, precompileDataSlots(accessibleState.StateDB, addr, key, 4))
+	accessibleState.ReadOnly = false
+
+	// A write failing after some of the slots were written is reverted.
+	stateDB := &failingStateDB{StateDB: accessibleState.StateDB, failAt: 3}
+	require.Panics(func() {
+		_ = contract.WritePrecompileData(stateDB, addr, key, bytes.Repeat([]byte{2}, 4*common.HashLength))
+	})
+	require.Equal(before, precompileDataSlots(accessibleState.StateDB, addr, key, 4))
+	value, err := accessibleState.GetPrecompileData(key)
+	require.NoError(err)
+	require.Equal(prev, value)
+}
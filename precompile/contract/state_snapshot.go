@@ -0,0 +1,160 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"go.uber.org/mock/gomock"
+)
+
+// stateSnapshots are the expectation snapshots of the MockStateDBs, which
+// are generated and cannot hold them.
+var stateSnapshots = struct {
+	lock      sync.Mutex
+	snapshots map[*MockStateDB]*stateSnapshot
+}{snapshots: make(map[*MockStateDB]*stateSnapshot)}
+
+// stateSnapshot is the set of expected calls of a MockStateDB when a snapshot
+// was taken. [lock] is held from the snapshot until it is restored.
+type stateSnapshot struct {
+	lock  sync.Mutex
+	calls map[uintptr]struct{}
+}
+
+// stateSnapshotOf returns the expectation snapshot of [mock].
+func stateSnapshotOf(mock *MockStateDB) *stateSnapshot {
+	stateSnapshots.lock.Lock()
+	defer stateSnapshots.lock.Unlock()
+
+	s, ok := stateSnapshots.snapshots[mock]
+	if !ok {
+		s = &stateSnapshot{}
+		stateSnapshots.snapshots[mock] = s
+	}
+	return s
+}
+
+// WithStateSnapshot records the calls expected from [m] so far, and returns
+// a teardown function restoring them with RestoreStateSnapshot. The
+// expectations recorded between the two are scoped to the snapshot: the
+// teardown reports those which were not satisfied to [t] and cancels all of
+// them, so they cannot match the calls made by later tests sharing [m].
+//
+// A snapshot is held until it is restored, and parallel subtests taking a
+// snapshot of the same mock wait for each other, so that they do not cancel
+// each other's expectations. Snapshots of the same mock must not be nested.
+//
+// gomock does not expose the expected calls, so they are read and cancelled
+// through the internals of the controller of [m].
+func (m *MockStateDB) WithStateSnapshot(t testing.TB) func() {
+	t.Helper()
+
+	s := stateSnapshotOf(m)
+	s.lock.Lock()
+	calls := make(map[uintptr]struct{})
+	err := withExpectedCalls(m.ctrl, m, func(_ string, call reflect.Value) bool {
+		calls[call.Pointer()] = struct{}{}
+		return true
+	})
+	if err != nil {
+		s.lock.Unlock()
+		t.Fatalf("failed to snapshot MockStateDB expectations: %v", err)
+		return func() {}
+	}
+	s.calls = calls
+	return func() {
+		t.Helper()
+		m.RestoreStateSnapshot(t)
+	}
+}
+
+// RestoreStateSnapshot cancels the calls expected from [m] since the last
+// call to WithStateSnapshot, reporting those which were not satisfied to [t],
+// and releases the snapshot.
+func (m *MockStateDB) RestoreStateSnapshot(t testing.TB) {
+	t.Helper()
+
+	s := stateSnapshotOf(m)
+	if s.calls == nil {
+		t.Fatalf("no MockStateDB expectations snapshot to restore")
+		return
+	}
+	defer s.lock.Unlock()
+
+	calls := s.calls
+	s.calls = nil
+	err := withExpectedCalls(m.ctrl, m, func(field string, call reflect.Value) bool {
+		if _, ok := calls[call.Pointer()]; ok {
+			return true
+		}
+		if field == "expected" && call.Elem().FieldByName("numCalls").Int() < call.Elem().FieldByName("minCalls").Int() {
+			t.Errorf("missing call(s) to %v", call.Interface())
+		}
+		return false
+	})
+	if err != nil {
+		t.Fatalf("failed to restore MockStateDB expectations: %v", err)
+	}
+}
+
+// withExpectedCalls calls [keep] with each expected and exhausted call of
+// [receiver] recorded by [ctrl], and the name of the field of the call set
+// holding it. The calls for which [keep] returns false are removed. The locks
+// of [ctrl] are held meanwhile.
+func withExpectedCalls(ctrl *gomock.Controller, receiver any, keep func(field string, call reflect.Value) bool) error {
+	ctrlValue := reflect.ValueOf(ctrl).Elem()
+	mu := ctrlValue.FieldByName("mu")
+	callSet := ctrlValue.FieldByName("expectedCalls")
+	if !mu.IsValid() || mu.Type() != reflect.TypeOf(sync.Mutex{}) || callSet.Kind() != reflect.Pointer || callSet.IsNil() {
+		return fmt.Errorf("%w: no expected calls", errUnsupportedController)
+	}
+	expectedMu := callSet.Elem().FieldByName("expectedMu")
+	if !expectedMu.IsValid() || expectedMu.Type() != reflect.TypeOf(&sync.Mutex{}) || expectedMu.IsNil() {
+		return fmt.Errorf("%w: no expected calls lock", errUnsupportedController)
+	}
+	// The fields are unexported, so they are accessed through their address.
+	ctrlMu := (*sync.Mutex)(unsafe.Pointer(mu.UnsafeAddr()))
+	callsMu := (*sync.Mutex)(unsafe.Pointer(expectedMu.Pointer()))
+	ctrlMu.Lock()
+	defer ctrlMu.Unlock()
+	callsMu.Lock()
+	defer callsMu.Unlock()
+
+	receiverPtr := reflect.ValueOf(receiver).Pointer()
+	for _, field := range []string{"expected", "exhausted"} {
+		calls := callSet.Elem().FieldByName(field)
+		if calls.Kind() != reflect.Map {
+			return fmt.Errorf("%w: no %s calls", errUnsupportedController, field)
+		}
+		calls = reflect.NewAt(calls.Type(), unsafe.Pointer(calls.UnsafeAddr())).Elem()
+
+		var keys, kept []reflect.Value
+		iter := calls.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			recv := key.FieldByName("receiver")
+			if recv.Kind() != reflect.Interface || recv.IsNil() || recv.Elem().Kind() != reflect.Pointer || recv.Elem().Pointer() != receiverPtr {
+				continue
+			}
+			value := iter.Value()
+			keptCalls := reflect.MakeSlice(value.Type(), 0, value.Len())
+			for i := 0; i < value.Len(); i++ {
+				if call := value.Index(i); keep(field, call) {
+					keptCalls = reflect.Append(keptCalls, call)
+				}
+			}
+			keys = append(keys, key)
+			kept = append(kept, keptCalls)
+		}
+		for i, key := range keys {
+			calls.SetMapIndex(key, kept[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMockStateDBStateSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	stateDB := NewMockStateDB(ctrl)
+	otherStateDB := NewMockStateDB(ctrl)
+
+	addr := common.Address{0x1}
+	stateDB.EXPECT().GetState(addr, common.Hash{0x1}).Return(common.Hash{0x1}).AnyTimes()
+	otherStateDB.EXPECT().Exist(addr).Return(true)
+
+	recorder := &errorRecorder{TB: t}
+	teardown := stateDB.WithStateSnapshot(recorder)
+	// The expectations of the snapshot match until it is restored, and the
+	// missing calls are reported by the teardown.
+	stateDB.EXPECT().GetState(addr, common.Hash{0x2}).Return(common.Hash{0x2}).AnyTimes()
+	stateDB.EXPECT().Exist(addr).Return(false)
+	stateDB.EXPECT().GetNonce(addr).Return(uint64(1))
+	require.Equal(common.Hash{0x1}, stateDB.GetState(addr, common.Hash{0x1}))
+	require.Equal(common.Hash{0x2}, stateDB.GetState(addr, common.Hash{0x2}))
+	require.False(stateDB.Exist(addr))
+	teardown()
+	require.Len(recorder.errors, 1)
+	require.Contains(recorder.errors[0], "GetNonce")
+
+	// The expectations of the snapshot are cancelled, including the calls
+	// counted, but not those of the other mocks.
+	require.Equal(common.Hash{0x1}, stateDB.GetState(addr, common.Hash{0x1}))
+	AssertCallCounts(t, stateDB, map[string]int{
+		"GetState": 2,
+		"Exist":    0,
+		"GetNonce": 0,
+	})
+	require.True(otherStateDB.Exist(addr))
+
+	// Restoring requires a snapshot.
+	recorder = &errorRecorder{TB: t}
+	stateDB.RestoreStateSnapshot(recorder)
+	require.Len(recorder.errors, 1)
+}
@@ -0,0 +1,215 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+var (
+	errInvalidStorageBigInt = errors.New("big integer must be non-negative and fit in 256 bits")
+	errInvalidStorageBytes  = errors.New("invalid encoding of bytes in storage")
+)
+
+// StorageCursor reads and writes a sequence of values in the storage of a
+// contract, starting at a base slot. Values are laid out the way Solidity lays
+// out the fields of a struct, so that a contract declaring the same struct can
+// read the same data:
+//   - values smaller than a slot (uint64, address) are packed into the current
+//     slot, starting from its lowest-order bytes, and move to the next slot if
+//     they do not fit.
+//   - big integers (uint256) and byte strings always occupy slots of their own.
+//   - byte strings use the encoding of Solidity's bytes type: up to 31 bytes
+//     are stored in the slot along with their length, while longer data is
+//     stored in consecutive slots starting at keccak256(slot).
+//
+// Reading values with the same sequence of types as they were written returns
+// the written values.
+type StorageCursor struct {
+	state StateDB
+	addr  common.Address
+	slot  uint256.Int
+	// offset is the number of low-order bytes of [slot] already used by
+	// packed values.
+	offset int
+}
+
+// NewStorageCursor returns a StorageCursor over the storage of [addr] in
+// [state], positioned at [base].
+func NewStorageCursor(state StateDB, addr common.Address, base common.Hash) *StorageCursor {
+	c := &StorageCursor{
+		state: state,
+		addr:  addr,
+	}
+	c.slot.SetBytes32(base[:])
+	return c
+}
+
+// WriteUint64 writes [value] and advances the cursor.
+func (c *StorageCursor) WriteUint64(value uint64) {
+	c.writePacked(binary.BigEndian.AppendUint64(nil, value))
+}
+
+// ReadUint64 reads a value written by WriteUint64 and advances the cursor.
+func (c *StorageCursor) ReadUint64() uint64 {
+	return binary.BigEndian.Uint64(c.readPacked(8))
+}
+
+// WriteAddress writes [addr] and advances the cursor.
+func (c *StorageCursor) WriteAddress(addr common.Address) {
+	c.writePacked(addr.Bytes())
+}
+
+// ReadAddress reads a value written by WriteAddress and advances the cursor.
+func (c *StorageCursor) ReadAddress() common.Address {
+	return common.BytesToAddress(c.readPacked(common.AddressLength))
+}
+
+// WriteBigInt writes [value] as a uint256 and advances the cursor. Returns an
+// error without writing or advancing if [value] is negative or does not fit
+// in 256 bits.
+func (c *StorageCursor) WriteBigInt(value *big.Int) error {
+	if value.Sign() < 0 || value.BitLen() > 256 {
+		return fmt.Errorf("%w: %s", errInvalidStorageBigInt, value)
+	}
+	c.writePacked(common.BigToHash(value).Bytes())
+	return nil
+}
+
+// ReadBigInt reads a value written by WriteBigInt and advances the cursor.
+func (c *StorageCursor) ReadBigInt() *big.Int {
+	return new(big.Int).SetBytes(c.readPacked(common.HashLength))
+}
+
+// WriteBytes writes [data] and advances the cursor. Any slots used by the
+// previous value which are not used by [data] are cleared.
+func (c *StorageCursor) WriteBytes(data []byte) {
+	slot, _ := c.next(common.HashLength)
+
+	// Ignore invalid previous values, since they are overwritten regardless.
+	prevLength, _ := decodeStorageBytesLength(c.state.GetState(c.addr, slot))
+	prevSlots := storageBytesDataSlots(prevLength)
+
+	var header common.Hash
+	if len(data) < common.HashLength {
+		copy(header[:], data)
+		header[common.HashLength-1] = byte(2 * len(data))
+	} else {
+		header = new(uint256.Int).SetUint64(2*uint64(len(data)) + 1).Bytes32()
+	}
+	c.state.SetState(c.addr, slot, header)
+
+	dataSlot := storageBytesDataSlot(slot)
+	newSlots := storageBytesDataSlots(uint64(len(data)))
+	for i := uint64(0); i < newSlots; i++ {
+		var chunk common.Hash
+		copy(chunk[:], data[i*common.HashLength:])
+		c.state.SetState(c.addr, addStorageSlot(dataSlot, i), chunk)
+	}
+	for i := newSlots; i < prevSlots; i++ {
+		c.state.SetState(c.addr, addStorageSlot(dataSlot, i), common.Hash{})
+	}
+}
+
+// ReadBytes reads a value written by WriteBytes and advances the cursor.
+// Returns an error if the slot does not hold a valid encoding of bytes.
+func (c *StorageCursor) ReadBytes() ([]byte, error) {
+	slot, _ := c.next(common.HashLength)
+	header := c.state.GetState(c.addr, slot)
+	length, err := decodeStorageBytesLength(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w at slot %s", err, slot)
+	}
+	if length < common.HashLength {
+		return common.CopyBytes(header[:length]), nil
+	}
+
+	// The buffer is grown as the data is read rather than allocated upfront,
+	// since [length] is read from storage and may be up to 4GiB.
+	var data []byte
+	dataSlot := storageBytesDataSlot(slot)
+	for i := uint64(0); uint64(len(data)) < length; i++ {
+		chunk := c.state.GetState(c.addr, addStorageSlot(dataSlot, i))
+		data = append(data, chunk[:min(length-uint64(len(data)), common.HashLength)]...)
+	}
+	return data, nil
+}
+
+// writePacked writes [value] into the slot it is packed into, preserving the
+// other values packed into the same slot.
+func (c *StorageCursor) writePacked(value []byte) {
+	slot, offset := c.next(len(value))
+	word := c.state.GetState(c.addr, slot)
+	end := common.HashLength - offset
+	copy(word[end-len(value):end], value)
+	c.state.SetState(c.addr, slot, word)
+}
+
+// readPacked reads a value of [size] bytes written by writePacked.
+func (c *StorageCursor) readPacked(size int) []byte {
+	slot, offset := c.next(size)
+	word := c.state.GetState(c.addr, slot)
+	end := common.HashLength - offset
+	return word[end-size : end]
+}
+
+// next returns the slot a value of [size] bytes is stored in and the offset
+// of the value from the lowest-order byte of the slot, and advances the cursor
+// past it. Values of a full slot leave the slot full, so that the next value
+// starts a new slot.
+func (c *StorageCursor) next(size int) (common.Hash, int) {
+	if c.offset+size > common.HashLength {
+		c.slot.AddUint64(&c.slot, 1)
+		c.offset = 0
+	}
+	offset := c.offset
+	c.offset += size
+	return c.slot.Bytes32(), offset
+}
+
+// decodeStorageBytesLength returns the length of the bytes value whose first
+// slot is [header].
+func decodeStorageBytesLength(header common.Hash) (uint64, error) {
+	if header[common.HashLength-1]&1 == 0 {
+		length := uint64(header[common.HashLength-1] / 2)
+		if length >= common.HashLength {
+			return 0, fmt.Errorf("%w: short length %d", errInvalidStorageBytes, length)
+		}
+		return length, nil
+	}
+	encoded := new(uint256.Int).SetBytes32(header[:])
+	length := encoded.Rsh(encoded, 1)
+	if !length.IsUint64() || length.Uint64() > math.MaxUint32 || length.Uint64() < common.HashLength {
+		return 0, fmt.Errorf("%w: long length %s", errInvalidStorageBytes, length)
+	}
+	return length.Uint64(), nil
+}
+
+// storageBytesDataSlots returns the number of data slots used by a bytes value
+// of [length]. Values shorter than a slot are stored in their header slot.
+func storageBytesDataSlots(length uint64) uint64 {
+	if length < common.HashLength {
+		return 0
+	}
+	return (length + common.HashLength - 1) / common.HashLength
+}
+
+// storageBytesDataSlot returns the first slot of the data of a long bytes
+// value whose header is at [slot].
+func storageBytesDataSlot(slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(slot[:])
+}
+
+func addStorageSlot(slot common.Hash, n uint64) common.Hash {
+	s := new(uint256.Int).SetBytes32(slot[:])
+	return s.AddUint64(s, n).Bytes32()
+}
@@ -0,0 +1,58 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package contract_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/contract/contracttest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageCursorOverwriteBytes(t *testing.T) {
+	require := require.New(t)
+
+	addr := common.HexToAddress("0xaa")
+	base := common.Hash{31: 5}
+	dataSlot := crypto.Keccak256Hash(base.Bytes())
+	slot := func(n int64) common.Hash {
+		return common.BigToHash(new(big.Int).Add(dataSlot.Big(), big.NewInt(n)))
+	}
+
+	accessibleState := contracttest.NewTestAccessibleState(t)
+	write := func(data []byte) {
+		contract.NewStorageCursor(accessibleState.GetStateDB(), addr, base).WriteBytes(data)
+	}
+	long := bytes.Repeat([]byte{1}, 3*common.HashLength)
+	write(long)
+	require.NotEqual(common.Hash{}, accessibleState.StateDB.GetState(addr, slot(2)))
+	accessibleState.Commit()
+
+	// Shortening the value clears the data slots which are no longer used, and
+	// reverting restores them.
+	accessibleState.RequireRevertRestoresStorage(addr, func() {
+		write(bytes.Repeat([]byte{2}, common.HashLength+1))
+		require.NotEqual(common.Hash{}, accessibleState.StateDB.GetState(addr, slot(1)))
+		require.Equal(common.Hash{}, accessibleState.StateDB.GetState(addr, slot(2)))
+
+		write([]byte{3})
+		require.Equal(common.Hash{}, accessibleState.StateDB.GetState(addr, slot(0)))
+		require.Equal(common.Hash{}, accessibleState.StateDB.GetState(addr, slot(1)))
+
+		data, err := contract.NewStorageCursor(accessibleState.GetStateDB(), addr, base).ReadBytes()
+		require.NoError(err)
+		require.Equal([]byte{3}, data)
+	})
+
+	data, err := contract.NewStorageCursor(accessibleState.GetStateDB(), addr, base).ReadBytes()
+	require.NoError(err)
+	require.Equal(long, data)
+}
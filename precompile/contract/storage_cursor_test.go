@@ -0,0 +1,197 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	cursorAddr = common.HexToAddress("0xaa")
+	cursorBase = common.Hash{31: 5}
+)
+
+func TestStorageCursorRoundTrip(t *testing.T) {
+	type field struct {
+		write func(c *StorageCursor)
+		read  func(c *StorageCursor) interface{}
+		value interface{}
+	}
+	uint64Field := func(v uint64) field {
+		return field{
+			write: func(c *StorageCursor) { c.WriteUint64(v) },
+			read:  func(c *StorageCursor) interface{} { return c.ReadUint64() },
+			value: v,
+		}
+	}
+	addressField := func(v common.Address) field {
+		return field{
+			write: func(c *StorageCursor) { c.WriteAddress(v) },
+			read:  func(c *StorageCursor) interface{} { return c.ReadAddress() },
+			value: v,
+		}
+	}
+	bigIntField := func(v *big.Int) field {
+		return field{
+			write: func(c *StorageCursor) { require.NoError(t, c.WriteBigInt(v)) },
+			// Compare the decimal representations, since equal big integers
+			// may differ in their internal representation.
+			read:  func(c *StorageCursor) interface{} { return c.ReadBigInt().String() },
+			value: v.String(),
+		}
+	}
+	bytesField := func(v []byte) field {
+		return field{
+			write: func(c *StorageCursor) { c.WriteBytes(v) },
+			read: func(c *StorageCursor) interface{} {
+				data, err := c.ReadBytes()
+				require.NoError(t, err)
+				return data
+			},
+			value: v,
+		}
+	}
+
+	var allLengthsFields []field
+	for length := 0; length <= 3*common.HashLength+1; length++ {
+		allLengthsFields = append(allLengthsFields, bytesField(bytes.Repeat([]byte{byte(length)}, length)), uint64Field(uint64(length)))
+	}
+
+	tests := map[string]struct {
+		fields []field
+	}{
+		"packed values": {
+			fields: []field{
+				uint64Field(1),
+				uint64Field(math.MaxUint64),
+				addressField(common.HexToAddress("0xbb")),
+				addressField(common.HexToAddress("0xcc")),
+				uint64Field(2),
+			},
+		},
+		"big integers": {
+			fields: []field{
+				bigIntField(big.NewInt(0)),
+				uint64Field(3),
+				bigIntField(math.MaxBig256),
+				bigIntField(big.NewInt(1)),
+			},
+		},
+		"bytes of every length up to three slots": {
+			fields: allLengthsFields,
+		},
+		"mixed": {
+			fields: []field{
+				addressField(common.HexToAddress("0xdd")),
+				bytesField([]byte("short")),
+				uint64Field(4),
+				bytesField(bytes.Repeat([]byte("long"), 100)),
+				bigIntField(big.NewInt(5)),
+				bytesField([]byte{}),
+				addressField(common.HexToAddress("0xee")),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			state := newTestStorageStateDB()
+			writer := NewStorageCursor(state, cursorAddr, cursorBase)
+			for _, f := range test.fields {
+				f.write(writer)
+			}
+			reader := NewStorageCursor(state, cursorAddr, cursorBase)
+			for i, f := range test.fields {
+				require.Equal(f.value, f.read(reader), "field %d", i)
+			}
+			// Only the storage of the given address is used.
+			require.Len(state.storage, 1)
+		})
+	}
+}
+
+func TestStorageCursorSolidityLayout(t *testing.T) {
+	require := require.New(t)
+
+	// Equivalent to the Solidity struct:
+	//   struct S {
+	//     uint64 a;
+	//     address b;
+	//     address c;
+	//     uint256 d;
+	//     bytes e;
+	//     bytes f;
+	//     uint64 g;
+	//   }
+	state := newTestStorageStateDB()
+	c := NewStorageCursor(state, cursorAddr, cursorBase)
+	c.WriteUint64(0x0102)
+	c.WriteAddress(common.HexToAddress("0xbb"))
+	c.WriteAddress(common.HexToAddress("0xcc"))
+	require.NoError(c.WriteBigInt(big.NewInt(7)))
+	c.WriteBytes([]byte{0xab, 0xcd})
+	long := bytes.Repeat([]byte{0xef}, 40)
+	c.WriteBytes(long)
+	c.WriteUint64(9)
+
+	slot := func(n uint64) common.Hash {
+		return addStorageSlot(cursorBase, n)
+	}
+	// a and b share the first slot, starting from the lowest-order bytes.
+	require.Equal(common.HexToHash("0x00000000000000000000000000000000000000bb0000000000000102"), state.GetState(cursorAddr, slot(0)))
+	require.Equal(common.HexToHash("0xcc"), state.GetState(cursorAddr, slot(1)))
+	require.Equal(common.HexToHash("0x07"), state.GetState(cursorAddr, slot(2)))
+	require.Equal(common.HexToHash("0xabcd000000000000000000000000000000000000000000000000000000000004"), state.GetState(cursorAddr, slot(3)))
+	require.Equal(common.HexToHash("0x51"), state.GetState(cursorAddr, slot(4)))
+	dataSlot := crypto.Keccak256Hash(slot(4).Bytes())
+	require.Equal(common.BytesToHash(long[:32]), state.GetState(cursorAddr, dataSlot))
+	require.Equal(common.HexToHash("0xefefefefefefefef000000000000000000000000000000000000000000000000"), state.GetState(cursorAddr, addStorageSlot(dataSlot, 1)))
+	require.Equal(common.HexToHash("0x09"), state.GetState(cursorAddr, slot(5)))
+}
+
+func TestStorageCursorInvalidValues(t *testing.T) {
+	tests := map[string]struct {
+		header common.Hash
+	}{
+		"short length too long": {
+			header: common.Hash{31: 64},
+		},
+		"long length too short": {
+			header: common.Hash{31: 63},
+		},
+		"long length too large": {
+			header: common.HexToHash("0x010000000000000001"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := newTestStorageStateDB()
+			state.SetState(cursorAddr, cursorBase, test.header)
+			_, err := NewStorageCursor(state, cursorAddr, cursorBase).ReadBytes()
+			require.ErrorIs(t, err, errInvalidStorageBytes)
+		})
+	}
+
+	t.Run("big integers", func(t *testing.T) {
+		require := require.New(t)
+
+		state := newTestStorageStateDB()
+		c := NewStorageCursor(state, cursorAddr, cursorBase)
+		require.ErrorIs(c.WriteBigInt(big.NewInt(-1)), errInvalidStorageBigInt)
+		require.ErrorIs(c.WriteBigInt(new(big.Int).Lsh(big.NewInt(1), 256)), errInvalidStorageBigInt)
+		require.Empty(state.storage)
+
+		// The cursor did not advance.
+		c.WriteUint64(1)
+		require.Equal(common.HexToHash("0x01"), state.GetState(cursorAddr, cursorBase))
+	})
+}
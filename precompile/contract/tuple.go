@@ -0,0 +1,177 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/ava-labs/coreth/accounts/abi"
+)
+
+var (
+	ErrNotTuple = errors.New("ABI type is not a tuple")
+
+	errMissingTupleField = errors.New("missing tuple field")
+	errUnknownTupleField = errors.New("unknown tuple field")
+	errTupleFieldType    = errors.New("tuple field does not match its ABI type")
+)
+
+// EncodeTuple packs [fields], keyed by the names of the components of
+// [abiType], in the same way as the outputs of a function returning those
+// components. Nested tuples are given as map[string]interface{}, and arrays
+// of nested tuples as slices of them. Other values must have the Go type that
+// the abi package uses for their ABI type.
+func EncodeTuple(fields map[string]interface{}, abiType abi.Type) ([]byte, error) {
+	if abiType.T != abi.TupleTy {
+		return nil, fmt.Errorf("%w: %s", ErrNotTuple, abiType)
+	}
+	values, err := tupleFieldValues(fields, abiType, "")
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value.Interface()
+	}
+	return tupleArguments(abiType).PackValues(args)
+}
+
+// DecodeTuple unpacks [data] encoded by [EncodeTuple] into a map keyed by
+// the names of the components of [abiType]. Nested tuples are returned as
+// map[string]interface{}, and arrays containing nested tuples as
+// []interface{}.
+func DecodeTuple(data []byte, abiType abi.Type) (map[string]interface{}, error) {
+	if abiType.T != abi.TupleTy {
+		return nil, fmt.Errorf("%w: %s", ErrNotTuple, abiType)
+	}
+	values, err := tupleArguments(abiType).UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		fields[abiType.TupleRawNames[i]] = fromABIValue(*abiType.TupleElems[i], reflect.ValueOf(value))
+	}
+	return fields, nil
+}
+
+// tupleArguments returns the components of [abiType] as arguments.
+func tupleArguments(abiType abi.Type) abi.Arguments {
+	args := make(abi.Arguments, len(abiType.TupleElems))
+	for i, elem := range abiType.TupleElems {
+		args[i] = abi.Argument{Name: abiType.TupleRawNames[i], Type: *elem}
+	}
+	return args
+}
+
+// tupleFieldValues converts [fields] to the values of the components of
+// [abiType], in order. [path] locates the tuple in errors.
+func tupleFieldValues(fields map[string]interface{}, abiType abi.Type, path string) ([]reflect.Value, error) {
+	values := make([]reflect.Value, len(abiType.TupleElems))
+	for i, elem := range abiType.TupleElems {
+		name := abiType.TupleRawNames[i]
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errMissingTupleField, path+name)
+		}
+		value, err := toABIValue(field, *elem, path+name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	if len(fields) != len(abiType.TupleElems) {
+		for name := range fields {
+			if !slices.Contains(abiType.TupleRawNames, name) {
+				return nil, fmt.Errorf("%w: %s", errUnknownTupleField, path+name)
+			}
+		}
+	}
+	return values, nil
+}
+
+// toABIValue converts [v] to the Go type of [abiType], replacing nested tuple
+// maps by the struct types used by the abi package.
+func toABIValue(v interface{}, abiType abi.Type, path string) (reflect.Value, error) {
+	typ := abiType.GetType()
+	switch abiType.T {
+	case abi.TupleTy:
+		if fields, ok := v.(map[string]interface{}); ok {
+			values, err := tupleFieldValues(fields, abiType, path+".")
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			tuple := reflect.New(typ).Elem()
+			for i, value := range values {
+				tuple.Field(i).Set(value)
+			}
+			return tuple, nil
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return reflect.Value{}, fmt.Errorf("%w: %s is %T, want %s", errTupleFieldType, path, v, abiType)
+		}
+		var out reflect.Value
+		if abiType.T == abi.SliceTy {
+			out = reflect.MakeSlice(typ, rv.Len(), rv.Len())
+		} else {
+			if rv.Len() != abiType.Size {
+				return reflect.Value{}, fmt.Errorf("%w: %s has length %d, want %s", errTupleFieldType, path, rv.Len(), abiType)
+			}
+			out = reflect.New(typ).Elem()
+		}
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := toABIValue(rv.Index(i).Interface(), *abiType.Elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !rv.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("%w: %s is %T, want %s", errTupleFieldType, path, v, abiType)
+	}
+	return rv, nil
+}
+
+// fromABIValue converts [v] of the Go type of [abiType] back to the
+// representation accepted by [toABIValue].
+func fromABIValue(abiType abi.Type, v reflect.Value) interface{} {
+	switch abiType.T {
+	case abi.TupleTy:
+		fields := make(map[string]interface{}, len(abiType.TupleElems))
+		for i, elem := range abiType.TupleElems {
+			fields[abiType.TupleRawNames[i]] = fromABIValue(*elem, v.Field(i))
+		}
+		return fields
+	case abi.SliceTy, abi.ArrayTy:
+		if !containsTuple(abiType) {
+			break
+		}
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = fromABIValue(*abiType.Elem, v.Index(i))
+		}
+		return elems
+	}
+	return v.Interface()
+}
+
+func containsTuple(abiType abi.Type) bool {
+	switch abiType.T {
+	case abi.TupleTy:
+		return true
+	case abi.SliceTy, abi.ArrayTy:
+		return containsTuple(*abiType.Elem)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/coreth/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTupleType returns the type of
+// (uint256 amount, address[] recipients, (bytes data, string memo) meta, (uint64 id, bool ok)[2] entries)
+func newTestTupleType(t *testing.T) abi.Type {
+	typ, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "amount", Type: "uint256"},
+		{Name: "recipients", Type: "address[]"},
+		{Name: "meta", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "data", Type: "bytes"},
+			{Name: "memo", Type: "string"},
+		}},
+		{Name: "entries", Type: "tuple[2]", Components: []abi.ArgumentMarshaling{
+			{Name: "id", Type: "uint64"},
+			{Name: "ok", Type: "bool"},
+		}},
+	})
+	require.NoError(t, err)
+	return typ
+}
+
+func TestEncodeDecodeTuple(t *testing.T) {
+	require := require.New(t)
+
+	typ := newTestTupleType(t)
+	fields := map[string]interface{}{
+		"amount":     big.NewInt(1_000),
+		"recipients": []common.Address{{1}, {2}},
+		"meta": map[string]interface{}{
+			"data": []byte{0xde, 0xad, 0xbe, 0xef},
+			"memo": "hello",
+		},
+		"entries": []interface{}{
+			map[string]interface{}{"id": uint64(1), "ok": true},
+			map[string]interface{}{"id": uint64(2), "ok": false},
+		},
+	}
+	encoded, err := EncodeTuple(fields, typ)
+	require.NoError(err)
+
+	// The encoding matches packing the components as function outputs.
+	type meta struct {
+		Data []byte
+		Memo string
+	}
+	type entry struct {
+		Id uint64
+		Ok bool
+	}
+	expected, err := tupleArguments(typ).Pack(
+		big.NewInt(1_000),
+		[]common.Address{{1}, {2}},
+		meta{Data: []byte{0xde, 0xad, 0xbe, 0xef}, Memo: "hello"},
+		[2]entry{{Id: 1, Ok: true}, {Id: 2, Ok: false}},
+	)
+	require.NoError(err)
+	require.Equal(expected, encoded)
+
+	decoded, err := DecodeTuple(encoded, typ)
+	require.NoError(err)
+	require.Equal(fields, decoded)
+}
+
+func TestEncodeTupleErrors(t *testing.T) {
+	typ := newTestTupleType(t)
+	validFields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"amount":     big.NewInt(1),
+			"recipients": []common.Address{},
+			"meta":       map[string]interface{}{"data": []byte{}, "memo": ""},
+			"entries": []map[string]interface{}{
+				{"id": uint64(1), "ok": true},
+				{"id": uint64(2), "ok": true},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		fields      func() map[string]interface{}
+		abiType     abi.Type
+		expectedErr error
+	}{
+		"not a tuple": {
+			fields:      validFields,
+			abiType:     abi.Type{T: abi.UintTy, Size: 256},
+			expectedErr: ErrNotTuple,
+		},
+		"missing field": {
+			fields: func() map[string]interface{} {
+				fields := validFields()
+				delete(fields, "amount")
+				return fields
+			},
+			abiType:     typ,
+			expectedErr: errMissingTupleField,
+		},
+		"unknown field": {
+			fields: func() map[string]interface{} {
+				fields := validFields()
+				fields["other"] = true
+				return fields
+			},
+			abiType:     typ,
+			expectedErr: errUnknownTupleField,
+		},
+		"missing nested field": {
+			fields: func() map[string]interface{} {
+				fields := validFields()
+				fields["meta"] = map[string]interface{}{"data": []byte{}}
+				return fields
+			},
+			abiType:     typ,
+			expectedErr: errMissingTupleField,
+		},
+		"wrong field type": {
+			fields: func() map[string]interface{} {
+				fields := validFields()
+				fields["amount"] = uint64(1)
+				return fields
+			},
+			abiType:     typ,
+			expectedErr: errTupleFieldType,
+		},
+		"wrong array length": {
+			fields: func() map[string]interface{} {
+				fields := validFields()
+				fields["entries"] = []map[string]interface{}{{"id": uint64(1), "ok": true}}
+				return fields
+			},
+			abiType:     typ,
+			expectedErr: errTupleFieldType,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := EncodeTuple(test.fields(), test.abiType)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
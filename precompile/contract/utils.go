@@ -5,11 +5,13 @@ package contract
 
 import (
 	"fmt"
+	"math/big"
 	"regexp"
 	"strings"
 
 	"github.com/ava-labs/coreth/accounts/abi"
 	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -24,6 +26,11 @@ const (
 	LogTopicGas uint64 = 375 // from params/protocol_params.go
 	// Per byte cost in a LOG operation's data. Should be multiplied by the byte size of the data.
 	LogDataGas uint64 = 8 // from params/protocol_params.go
+	// Per byte cost of installing code at an address. Should be multiplied by the byte size of the code.
+	CodeDepositGas uint64 = 200 // from params/protocol_params.go
+	// Per account cost of computing the total supply, which iterates over every
+	// account in the state. Should be multiplied by the number of accounts.
+	TotalSupplyGasPerAccount uint64 = 2_100 // ColdSloadCostEIP2929 from params/protocol_params.go
 )
 
 var functionSignatureRegex = regexp.MustCompile(`\w+\((\w*|(\w+,)+\w+)\)`)
@@ -48,6 +55,38 @@ func DeductGas(suppliedGas uint64, requiredGas uint64) (uint64, error) {
 	return suppliedGas - requiredGas, nil
 }
 
+// TotalSupplyWithGas returns the total supply computed by [getTotalSupply],
+// deducting [TotalSupplyGasPerAccount] from [suppliedGas] for each account
+// visited. [getTotalSupply] must stop once more than maxAccounts accounts have
+// been visited and return false, so that the work done is bounded by
+// [suppliedGas].
+func TotalSupplyWithGas(getTotalSupply func(maxAccounts uint64) (*big.Int, uint64, bool), suppliedGas uint64) (*big.Int, uint64, error) {
+	supply, accounts, ok := getTotalSupply(suppliedGas / TotalSupplyGasPerAccount)
+	if !ok {
+		return nil, 0, vmerrs.ErrOutOfGas
+	}
+	remainingGas, err := DeductGas(suppliedGas, accounts*TotalSupplyGasPerAccount)
+	if err != nil {
+		return nil, 0, err
+	}
+	return supply, remainingGas, nil
+}
+
+// SetCodeWithGas installs [code] at [addr], deducting the cost of the write
+// according to the gas schedule from [suppliedGas].
+func SetCodeWithGas(accessibleState AccessibleState, addr common.Address, code []byte, suppliedGas uint64) (uint64, error) {
+	cost, err := accessibleState.GetGasSchedule().SetCodeCost(len(code))
+	if err != nil {
+		return 0, err
+	}
+	remainingGas, err := DeductGas(suppliedGas, cost)
+	if err != nil {
+		return 0, err
+	}
+	accessibleState.GetStateDB().SetCode(addr, code)
+	return remainingGas, nil
+}
+
 // ParseABI parses the given ABI string and returns the parsed ABI.
 // If the ABI is invalid, it panics.
 func ParseABI(rawABI string) abi.ABI {
@@ -4,9 +4,15 @@
 package contract
 
 import (
+	"math"
+	"math/big"
 	"testing"
 
+	"github.com/ava-labs/coreth/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestFunctionSignatureRegex(t *testing.T) {
@@ -64,3 +70,228 @@ func TestFunctionSignatureRegex(t *testing.T) {
 		assert.Equal(t, test.pass, functionSignatureRegex.MatchString(test.str), "unexpected result for %q", test.str)
 	}
 }
+
+func TestSetCodeWithGas(t *testing.T) {
+	addr := common.Address{1}
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xfd}
+	codeCost := WriteGasCostPerSlot + uint64(len(code))*CodeDepositGas
+
+	overflowSchedule := DefaultGasSchedule()
+	overflowSchedule.CodeDeposit = math.MaxUint64
+
+	tests := map[string]struct {
+		schedule             GasSchedule
+		suppliedGas          uint64
+		expectedRemainingGas uint64
+		expectedErr          error
+	}{
+		"sufficient gas": {
+			schedule:             DefaultGasSchedule(),
+			suppliedGas:          codeCost + 1,
+			expectedRemainingGas: 1,
+		},
+		"insufficient gas": {
+			schedule:    DefaultGasSchedule(),
+			suppliedGas: codeCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+		"cost overflow": {
+			schedule:    overflowSchedule,
+			suppliedGas: math.MaxUint64,
+			expectedErr: vmerrs.ErrGasUintOverflow,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			state := NewMockStateDB(ctrl)
+			if test.expectedErr == nil {
+				state.EXPECT().SetCode(addr, code)
+			}
+			accessibleState := NewMockAccessibleState(ctrl)
+			accessibleState.EXPECT().GetStateDB().Return(state).AnyTimes()
+			accessibleState.EXPECT().GetGasSchedule().Return(test.schedule).AnyTimes()
+
+			remainingGas, err := SetCodeWithGas(accessibleState, addr, code, test.suppliedGas)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedRemainingGas, remainingGas)
+		})
+	}
+}
+
+// baseFeePricedRun charges 1 gas per gwei of the block's base fee, and a
+// fixed 1 gas before dynamic fees.
+func baseFeePricedRun(accessibleState AccessibleState, suppliedGas uint64) (uint64, error) {
+	baseFee := accessibleState.GetBlockContext().BaseFee()
+	if baseFee == nil {
+		return DeductGas(suppliedGas, 1)
+	}
+	return DeductGas(suppliedGas, new(big.Int).Div(baseFee, big.NewInt(1_000_000_000)).Uint64())
+}
+
+func TestBaseFeePricedPrecompile(t *testing.T) {
+	tests := map[string]struct {
+		baseFee              *big.Int
+		suppliedGas          uint64
+		expectedRemainingGas uint64
+		expectedErr          error
+	}{
+		"sufficient gas": {
+			baseFee:              big.NewInt(25_000_000_000),
+			suppliedGas:          30,
+			expectedRemainingGas: 5,
+		},
+		"insufficient gas": {
+			baseFee:     big.NewInt(25_000_000_000),
+			suppliedGas: 24,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+		"pre dynamic fees": {
+			suppliedGas:          30,
+			expectedRemainingGas: 29,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			blockContext := NewMockBlockContext(ctrl)
+			blockContext.EXPECT().BaseFee().Return(test.baseFee)
+			accessibleState := NewMockAccessibleState(ctrl)
+			accessibleState.EXPECT().GetBlockContext().Return(blockContext)
+
+			remainingGas, err := baseFeePricedRun(accessibleState, test.suppliedGas)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedRemainingGas, remainingGas)
+		})
+	}
+}
+
+// revenueSharingRun credits [value] from [caller] to the block's coinbase.
+func revenueSharingRun(accessibleState AccessibleState, caller common.Address, value *big.Int) error {
+	stateDB := accessibleState.GetStateDB()
+	if stateDB.GetBalance(caller).Cmp(value) < 0 {
+		return vmerrs.ErrInsufficientBalance
+	}
+	stateDB.SubBalance(caller, value)
+	stateDB.AddBalance(accessibleState.GetBlockContext().Coinbase(), value)
+	return nil
+}
+
+func TestCoinbaseRevenuePrecompile(t *testing.T) {
+	var (
+		caller   = common.Address{1}
+		coinbase = common.Address{2}
+		value    = big.NewInt(100)
+	)
+	tests := map[string]struct {
+		balance     *big.Int
+		expectedErr error
+	}{
+		"sufficient balance": {
+			balance: big.NewInt(150),
+		},
+		"insufficient balance": {
+			balance:     big.NewInt(50),
+			expectedErr: vmerrs.ErrInsufficientBalance,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			state := NewMockStateDB(ctrl)
+			state.EXPECT().GetBalance(caller).Return(test.balance)
+			accessibleState := NewMockAccessibleState(ctrl)
+			accessibleState.EXPECT().GetStateDB().Return(state)
+			if test.expectedErr == nil {
+				blockContext := NewMockBlockContext(ctrl)
+				blockContext.EXPECT().Coinbase().Return(coinbase)
+				accessibleState.EXPECT().GetBlockContext().Return(blockContext)
+				gomock.InOrder(
+					state.EXPECT().SubBalance(caller, value),
+					state.EXPECT().AddBalance(coinbase, value),
+				)
+			}
+
+			require.ErrorIs(t, revenueSharingRun(accessibleState, caller, value), test.expectedErr)
+		})
+	}
+}
+
+// feeSplittingRun credits [value] from [caller] to the block's coinbase and
+// miner fee recipient, the coinbase receiving [coinbasePercent] of it.
+func feeSplittingRun(accessibleState AccessibleState, caller common.Address, value *big.Int, coinbasePercent uint64) error {
+	stateDB := accessibleState.GetStateDB()
+	if stateDB.GetBalance(caller).Cmp(value) < 0 {
+		return vmerrs.ErrInsufficientBalance
+	}
+	coinbaseShare := new(big.Int).Mul(value, new(big.Int).SetUint64(coinbasePercent))
+	coinbaseShare.Div(coinbaseShare, big.NewInt(100))
+	recipientShare := new(big.Int).Sub(value, coinbaseShare)
+
+	blockContext := accessibleState.GetBlockContext()
+	stateDB.SubBalance(caller, value)
+	stateDB.AddBalance(blockContext.Coinbase(), coinbaseShare)
+	stateDB.AddBalance(blockContext.GetMinerFeeRecipient(), recipientShare)
+	return nil
+}
+
+func TestFeeSplittingPrecompile(t *testing.T) {
+	var (
+		caller       = common.Address{1}
+		coinbase     = common.Address{2}
+		feeRecipient = common.Address{3}
+		value        = big.NewInt(1000)
+	)
+	tests := map[string]struct {
+		coinbasePercent        uint64
+		feeRecipient           common.Address
+		expectedCoinbaseShare  *big.Int
+		expectedRecipientShare *big.Int
+	}{
+		"fee recipient": {
+			coinbasePercent:        30,
+			feeRecipient:           feeRecipient,
+			expectedCoinbaseShare:  big.NewInt(300),
+			expectedRecipientShare: big.NewInt(700),
+		},
+		"small coinbase share": {
+			coinbasePercent:        33,
+			feeRecipient:           feeRecipient,
+			expectedCoinbaseShare:  big.NewInt(330),
+			expectedRecipientShare: big.NewInt(670),
+		},
+		// Without a fee recipient, both shares go to the coinbase.
+		"no fee recipient": {
+			coinbasePercent:        30,
+			feeRecipient:           coinbase,
+			expectedCoinbaseShare:  big.NewInt(300),
+			expectedRecipientShare: big.NewInt(700),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			state := NewMockStateDB(ctrl)
+			state.EXPECT().GetBalance(caller).Return(value)
+			blockContext := NewMockBlockContext(ctrl)
+			blockContext.EXPECT().Coinbase().Return(coinbase)
+			blockContext.EXPECT().GetMinerFeeRecipient().Return(test.feeRecipient)
+			accessibleState := NewMockAccessibleState(ctrl)
+			accessibleState.EXPECT().GetStateDB().Return(state)
+			accessibleState.EXPECT().GetBlockContext().Return(blockContext)
+			gomock.InOrder(
+				state.EXPECT().SubBalance(caller, value),
+				state.EXPECT().AddBalance(coinbase, test.expectedCoinbaseShare),
+				state.EXPECT().AddBalance(test.feeRecipient, test.expectedRecipientShare),
+			)
+
+			require.NoError(t, feeSplittingRun(accessibleState, caller, value, test.coinbasePercent))
+		})
+	}
+}
@@ -48,14 +48,19 @@ var (
 type Config struct {
 	precompileconfig.Upgrade
 	QuorumNumerator uint64 `json:"quorumNumerator"`
+
+	// verifiedSignatures caches successful signature verifications in
+	// VerifyPredicate. If nil, every predicate is verified.
+	verifiedSignatures *verifiedSignatureCache
 }
 
 // NewConfig returns a config for a network upgrade at [blockTimestamp] that enables
 // Warp with the given quorum numerator.
 func NewConfig(blockTimestamp *uint64, quorumNumerator uint64) *Config {
 	return &Config{
-		Upgrade:         precompileconfig.Upgrade{BlockTimestamp: blockTimestamp},
-		QuorumNumerator: quorumNumerator,
+		Upgrade:            precompileconfig.Upgrade{BlockTimestamp: blockTimestamp},
+		QuorumNumerator:    quorumNumerator,
+		verifiedSignatures: newVerifiedSignatureCache(),
 	}
 }
 
@@ -197,6 +202,20 @@ func (c *Config) VerifyPredicate(predicateContext *precompileconfig.PredicateCon
 		quorumNumerator = c.QuorumNumerator
 	}
 
+	// Skip the signature verification if this message was already verified
+	// against the same validator set.
+	cacheKey := verifiedSignatureKey(
+		unpackedPredicateBytes,
+		predicateContext.SnowCtx.NetworkID,
+		predicateContext.SnowCtx.SubnetID,
+		predicateContext.ProposerVMBlockCtx.PChainHeight,
+		quorumNumerator,
+		WarpQuorumDenominator,
+	)
+	if c.verifiedSignatures.contains(cacheKey) {
+		return nil
+	}
+
 	log.Debug("verifying warp message", "warpMsg", warpMsg, "quorumNum", quorumNumerator, "quorumDenom", WarpQuorumDenominator)
 	err = warpMsg.Signature.Verify(
 		context.Background(),
@@ -213,5 +232,6 @@ func (c *Config) VerifyPredicate(predicateContext *precompileconfig.PredicateCon
 		return fmt.Errorf("%w: %w", errFailedVerification, err)
 	}
 
+	c.verifiedSignatures.add(cacheKey)
 	return nil
 }
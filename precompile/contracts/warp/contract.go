@@ -92,10 +92,11 @@ func PackGetBlockchainIDOutput(blockchainID common.Hash) ([]byte, error) {
 }
 
 // getBlockchainID returns the snow Chain Context ChainID of this blockchain.
-func getBlockchainID(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+func getBlockchainID(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error) {
 	if remainingGas, err = contract.DeductGas(suppliedGas, GetBlockchainIDGasCost); err != nil {
 		return nil, 0, err
 	}
+	traceOp("base", GetBlockchainIDGasCost)
 	packedOutput, err := PackGetBlockchainIDOutput(common.Hash(accessibleState.GetSnowContext().ChainID))
 	if err != nil {
 		return nil, remainingGas, err
@@ -143,8 +144,8 @@ func UnpackGetVerifiedWarpBlockHashOutput(output []byte) (GetVerifiedWarpBlockHa
 	return outputStruct, err
 }
 
-func getVerifiedWarpBlockHash(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	return handleWarpMessage(accessibleState, input, suppliedGas, blockHashHandler{})
+func getVerifiedWarpBlockHash(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error) {
+	return handleWarpMessage(accessibleState, input, suppliedGas, blockHashHandler{}, traceOp)
 }
 
 // UnpackGetVerifiedWarpMessageInput attempts to unpack [input] into the uint32 type argument
@@ -187,8 +188,8 @@ func UnpackGetVerifiedWarpMessageOutput(output []byte) (GetVerifiedWarpMessageOu
 
 // getVerifiedWarpMessage retrieves the pre-verified warp message from the predicate storage slots and returns
 // the expected ABI encoding of the message to the caller.
-func getVerifiedWarpMessage(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	return handleWarpMessage(accessibleState, input, suppliedGas, addressedPayloadHandler{})
+func getVerifiedWarpMessage(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error) {
+	return handleWarpMessage(accessibleState, input, suppliedGas, addressedPayloadHandler{}, traceOp)
 }
 
 // UnpackSendWarpMessageInput attempts to unpack [input] as []byte
@@ -226,22 +227,32 @@ func UnpackSendWarpMessageOutput(output []byte) (common.Hash, error) {
 	return unpacked, nil
 }
 
+// sendWarpMessageGasCost returns the base and per byte gas cost of sendWarpMessage
+// under [schedule]. Under the default schedule these are [SendWarpMessageGasCost]
+// and [SendWarpMessageGasCostPerByte].
+func sendWarpMessageGasCost(schedule contract.GasSchedule) (uint64, uint64) {
+	return schedule.Log + 3*schedule.LogTopic + AddWarpMessageGasCost + schedule.StoreWrite, schedule.LogData
+}
+
 // sendWarpMessage constructs an Avalanche Warp Message containing an AddressedPayload and emits a log to signal validators that they should
 // be willing to sign this message.
-func sendWarpMessage(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = contract.DeductGas(suppliedGas, SendWarpMessageGasCost); err != nil {
+func sendWarpMessage(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error) {
+	baseGas, gasPerByte := sendWarpMessageGasCost(accessibleState.GetGasSchedule())
+	if remainingGas, err = contract.DeductGas(suppliedGas, baseGas); err != nil {
 		return nil, 0, err
 	}
+	traceOp("base", baseGas)
 	// This gas cost includes buffer room because it is based off of the total size of the input instead of the produced payload.
 	// This ensures that we charge gas before we unpack the variable sized input.
-	payloadGas, overflow := math.SafeMul(SendWarpMessageGasCostPerByte, uint64(len(input)))
+	payloadGas, overflow := math.SafeMul(gasPerByte, uint64(len(input)))
 	if overflow {
 		return nil, 0, vmerrs.ErrOutOfGas
 	}
 	if remainingGas, err = contract.DeductGas(remainingGas, payloadGas); err != nil {
 		return nil, 0, err
 	}
-	if readOnly {
+	traceOp("payload", payloadGas)
+	if readOnly || accessibleState.IsReadOnly() {
 		return nil, remainingGas, vmerrs.ErrWriteProtection
 	}
 	// unpack the arguments
@@ -311,11 +322,45 @@ func UnpackSendWarpEventDataToMessage(data []byte) (*warp.UnsignedMessage, error
 	return warp.ParseUnsignedMessage(event.Message)
 }
 
+// tracedPrecompileFunc is a function of the precompile that reports the gas
+// charged by each of its steps to [traceOp].
+type tracedPrecompileFunc func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error)
+
+func noopTraceOp(string, uint64) {}
+
+// untraced returns [function] as a RunStatefulPrecompileFunc that reports nothing.
+func untraced(function tracedPrecompileFunc) contract.RunStatefulPrecompileFunc {
+	return func(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) ([]byte, uint64, error) {
+		return function(accessibleState, caller, addr, input, suppliedGas, readOnly, noopTraceOp)
+	}
+}
+
+var _ contract.ContractTracer = (*warpContract)(nil)
+
+// warpContract implements ContractTracer on top of the function selector
+// based contract, dispatching traced calls to the same functions.
+type warpContract struct {
+	contract.StatefulPrecompiledContract
+	traced map[string]tracedPrecompileFunc
+}
+
+// RunWithTracing implements the ContractTracer interface.
+func (w *warpContract) RunWithTracing(accessibleState contract.AccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool, traceOp contract.TraceOpFunc) (ret []byte, remainingGas uint64, err error) {
+	if len(input) >= contract.SelectorLen {
+		if function, ok := w.traced[string(input[:contract.SelectorLen])]; ok {
+			return function(accessibleState, caller, addr, input[contract.SelectorLen:], suppliedGas, readOnly, traceOp)
+		}
+	}
+	// Invalid inputs fail without charging gas, as in Run.
+	return w.Run(accessibleState, caller, addr, input, suppliedGas, readOnly)
+}
+
 // createWarpPrecompile returns a StatefulPrecompiledContract with getters and setters for the precompile.
 func createWarpPrecompile() contract.StatefulPrecompiledContract {
 	var functions []*contract.StatefulPrecompileFunction
+	traced := make(map[string]tracedPrecompileFunc)
 
-	abiFunctionMap := map[string]contract.RunStatefulPrecompileFunc{
+	abiFunctionMap := map[string]tracedPrecompileFunc{
 		"getBlockchainID":          getBlockchainID,
 		"getVerifiedWarpBlockHash": getVerifiedWarpBlockHash,
 		"getVerifiedWarpMessage":   getVerifiedWarpMessage,
@@ -327,12 +372,16 @@ func createWarpPrecompile() contract.StatefulPrecompiledContract {
 		if !ok {
 			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
 		}
-		functions = append(functions, contract.NewStatefulPrecompileFunction(method.ID, function))
+		functions = append(functions, contract.NewStatefulPrecompileFunction(method.ID, untraced(function)))
+		traced[string(method.ID)] = function
 	}
 	// Construct the contract with no fallback function.
 	statefulContract, err := contract.NewStatefulPrecompileContract(nil, functions)
 	if err != nil {
 		panic(err)
 	}
-	return statefulContract
+	return &warpContract{
+		StatefulPrecompiledContract: statefulContract,
+		traced:                      traced,
+	}
 }
@@ -16,12 +16,14 @@ import (
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
 	"github.com/ava-labs/coreth/core/state"
 	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/ava-labs/coreth/precompile/contract/contracttest"
 	"github.com/ava-labs/coreth/precompile/testutils"
 	"github.com/ava-labs/coreth/predicate"
 	"github.com/ava-labs/coreth/utils"
 	"github.com/ava-labs/coreth/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestGetBlockchainID(t *testing.T) {
@@ -103,6 +105,13 @@ func TestSendWarpMessage(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	// A gas schedule with a cheaper storage write and more expensive log data
+	// than the default schedule.
+	gasSchedule := contract.DefaultGasSchedule()
+	gasSchedule.StoreWrite = 1_000
+	gasSchedule.LogData = 50
+	scheduledGasCost := gasSchedule.Log + 3*gasSchedule.LogTopic + AddWarpMessageGasCost + gasSchedule.StoreWrite + uint64(len(sendWarpMessageInput[4:]))*gasSchedule.LogData
+
 	tests := map[string]testutils.PrecompileTest{
 		"send warp message readOnly": {
 			Caller:      callerAddr,
@@ -167,11 +176,115 @@ func TestSendWarpMessage(t *testing.T) {
 				require.Equal(t, addressedPayload.Payload, sendWarpMessagePayload)
 			},
 		},
+		"send warp message with gas schedule": {
+			Caller:      callerAddr,
+			InputFn:     func(t testing.TB) []byte { return sendWarpMessageInput },
+			SuppliedGas: scheduledGasCost,
+			ReadOnly:    false,
+			GasSchedule: &gasSchedule,
+			ExpectedRes: func() []byte {
+				bytes, err := PackSendWarpMessageOutput(common.Hash(unsignedWarpMessage.ID()))
+				if err != nil {
+					panic(err)
+				}
+				return bytes
+			}(),
+		},
+		"send warp message with gas schedule insufficient gas": {
+			Caller:      callerAddr,
+			InputFn:     func(t testing.TB) []byte { return sendWarpMessageInput },
+			SuppliedGas: scheduledGasCost - 1,
+			ReadOnly:    false,
+			GasSchedule: &gasSchedule,
+			ExpectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
 	}
 
 	testutils.RunPrecompileTests(t, Module, state.NewTestStateDB, tests)
 }
 
+func TestSendWarpMessageStaticContext(t *testing.T) {
+	require := require.New(t)
+
+	input, err := PackSendWarpMessage(agoUtils.RandomBytes(100))
+	require.NoError(err)
+
+	// The precompile is called from a static frame without [readOnly] being
+	// passed, so the static context is only known to the accessible state.
+	ctrl := gomock.NewController(t)
+	accessibleState := contract.NewMockAccessibleState(ctrl)
+	accessibleState.EXPECT().GetGasSchedule().Return(contract.DefaultGasSchedule()).AnyTimes()
+	accessibleState.EXPECT().IsReadOnly().Return(true).AnyTimes()
+
+	suppliedGas := SendWarpMessageGasCost + uint64(len(input[4:]))*SendWarpMessageGasCostPerByte
+	_, _, err = Module.Contract.Run(accessibleState, common.HexToAddress("0x0123"), ContractAddress, input, suppliedGas, false)
+	require.Equal(vmerrs.ErrWriteProtection, err)
+}
+
+func TestSendWarpMessageWithTracing(t *testing.T) {
+	callerAddr := common.HexToAddress("0x0123")
+	input, err := PackSendWarpMessage(agoUtils.RandomBytes(100))
+	require.NoError(t, err)
+	payloadGas := uint64(len(input[4:])) * SendWarpMessageGasCostPerByte
+
+	type op struct {
+		label   string
+		gasUsed uint64
+	}
+	tests := map[string]struct {
+		input       []byte
+		suppliedGas uint64
+		readOnly    bool
+		expectedOps []op
+	}{
+		"success": {
+			input:       input,
+			suppliedGas: SendWarpMessageGasCost + payloadGas + 1,
+			expectedOps: []op{{"base", SendWarpMessageGasCost}, {"payload", payloadGas}},
+		},
+		"readOnly": {
+			input:       input,
+			suppliedGas: SendWarpMessageGasCost + payloadGas,
+			readOnly:    true,
+			expectedOps: []op{{"base", SendWarpMessageGasCost}, {"payload", payloadGas}},
+		},
+		"insufficient gas for payload bytes": {
+			input:       input,
+			suppliedGas: SendWarpMessageGasCost + payloadGas - 1,
+			expectedOps: []op{{"base", SendWarpMessageGasCost}},
+		},
+		"invalid selector": {
+			input:       []byte{0x01, 0x02, 0x03, 0x04},
+			suppliedGas: SendWarpMessageGasCost,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			expectedRet, expectedRemainingGas, expectedErr := Module.Contract.Run(contracttest.NewTestAccessibleState(t), callerAddr, ContractAddress, test.input, test.suppliedGas, test.readOnly)
+
+			tracer, ok := Module.Contract.(contract.ContractTracer)
+			require.True(ok)
+			var ops []op
+			ret, remainingGas, err := tracer.RunWithTracing(contracttest.NewTestAccessibleState(t), callerAddr, ContractAddress, test.input, test.suppliedGas, test.readOnly, func(label string, gasUsed uint64) {
+				ops = append(ops, op{label, gasUsed})
+			})
+			require.Equal(expectedErr, err)
+			require.Equal(expectedRet, ret)
+			require.Equal(expectedRemainingGas, remainingGas)
+			require.Equal(test.expectedOps, ops)
+			if err == nil {
+				var gasUsed uint64
+				for _, op := range ops {
+					gasUsed += op.gasUsed
+				}
+				require.Equal(test.suppliedGas-remainingGas, gasUsed)
+			}
+		})
+	}
+}
+
 func TestGetVerifiedWarpMessage(t *testing.T) {
 	networkID := uint32(54321)
 	callerAddr := common.HexToAddress("0x0123")
@@ -201,7 +314,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    false,
@@ -231,7 +344,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -254,7 +367,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{{}, warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0).Bytes())
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0).Bytes(), true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    false,
@@ -284,7 +397,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{{}, warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0, 1).Bytes())
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0, 1).Bytes(), true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -300,7 +413,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 			Caller:  callerAddr,
 			InputFn: func(t testing.TB) []byte { return getVerifiedWarpMsg },
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -319,7 +432,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    true,
@@ -342,7 +455,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 			Caller:  callerAddr,
 			InputFn: func(t testing.TB) []byte { return getVerifiedWarpMsg },
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    true,
@@ -371,7 +484,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)) - 1,
 			ReadOnly:    false,
@@ -384,7 +497,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessage.Bytes()})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessage.Bytes())),
 			ReadOnly:    false,
@@ -397,7 +510,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{predicate.PackPredicate([]byte{1, 2, 3})})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(32),
 			ReadOnly:    false,
@@ -415,7 +528,7 @@ func TestGetVerifiedWarpMessage(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{predicate.PackPredicate(warpMessage.Bytes())})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(160),
 			ReadOnly:    false,
@@ -482,7 +595,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    false,
@@ -511,7 +624,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -534,7 +647,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{{}, warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0).Bytes())
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0).Bytes(), true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    false,
@@ -563,7 +676,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{{}, warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0, 1).Bytes())
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(set.NewBits(0, 1).Bytes(), true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -579,7 +692,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 			Caller:  callerAddr,
 			InputFn: func(t testing.TB) []byte { return getVerifiedWarpBlockHash },
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    false,
@@ -598,7 +711,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)),
 			ReadOnly:    true,
@@ -620,7 +733,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 			Caller:  callerAddr,
 			InputFn: func(t testing.TB) []byte { return getVerifiedWarpBlockHash },
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost,
 			ReadOnly:    true,
@@ -649,7 +762,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessagePredicateBytes})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessagePredicateBytes)) - 1,
 			ReadOnly:    false,
@@ -662,7 +775,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{warpMessage.Bytes()})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(len(warpMessage.Bytes())),
 			ReadOnly:    false,
@@ -675,7 +788,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{predicate.PackPredicate([]byte{1, 2, 3})})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(32),
 			ReadOnly:    false,
@@ -693,7 +806,7 @@ func TestGetVerifiedWarpBlockHash(t *testing.T) {
 				state.SetPredicateStorageSlots(ContractAddress, [][]byte{predicate.PackPredicate(warpMessage.Bytes())})
 			},
 			SetupBlockContext: func(mbc *contract.MockBlockContext) {
-				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures)
+				mbc.EXPECT().GetPredicateResults(common.Hash{}, ContractAddress).Return(noFailures, true)
 			},
 			SuppliedGas: GetVerifiedWarpMessageBaseCost + GasCostPerWarpMessageBytes*uint64(160),
 			ReadOnly:    false,
@@ -45,11 +45,12 @@ type messageHandler interface {
 	handleMessage(msg *warp.Message) ([]byte, error)
 }
 
-func handleWarpMessage(accessibleState contract.AccessibleState, input []byte, suppliedGas uint64, handler messageHandler) ([]byte, uint64, error) {
+func handleWarpMessage(accessibleState contract.AccessibleState, input []byte, suppliedGas uint64, handler messageHandler, traceOp contract.TraceOpFunc) ([]byte, uint64, error) {
 	remainingGas, err := contract.DeductGas(suppliedGas, GetVerifiedWarpMessageBaseCost)
 	if err != nil {
 		return nil, remainingGas, err
 	}
+	traceOp("base", GetVerifiedWarpMessageBaseCost)
 
 	warpIndexInput, err := UnpackGetVerifiedWarpMessageInput(input)
 	if err != nil {
@@ -61,7 +62,9 @@ func handleWarpMessage(accessibleState contract.AccessibleState, input []byte, s
 	warpIndex := int(warpIndexInput) // This conversion is safe even if int is 32 bits because we checked above.
 	state := accessibleState.GetStateDB()
 	predicateBytes, exists := state.GetPredicateStorageSlots(ContractAddress, warpIndex)
-	predicateResults := accessibleState.GetBlockContext().GetPredicateResults(state.GetTxHash(), ContractAddress)
+	// If no results were recorded, as when simulating a call on a block built
+	// without verifying this tx's predicates, no predicate is marked as failed.
+	predicateResults, _ := accessibleState.GetBlockContext().GetPredicateResults(state.GetTxHash(), ContractAddress)
 	valid := exists && !set.BitsFromBytes(predicateResults).Contains(warpIndex)
 	if !valid {
 		return handler.packFailed(), remainingGas, nil
@@ -76,6 +79,7 @@ func handleWarpMessage(accessibleState contract.AccessibleState, input []byte, s
 	if remainingGas, err = contract.DeductGas(remainingGas, msgBytesGas); err != nil {
 		return nil, 0, err
 	}
+	traceOp("message bytes", msgBytesGas)
 	// Note: since the predicate is verified in advance of execution, the precompile should not
 	// hit an error during execution.
 	unpackedPredicateBytes, err := predicate.UnpackPredicate(predicateBytes)
@@ -43,7 +43,7 @@ func init() {
 // MakeConfig returns a new precompile config instance.
 // This is required to Marshal/Unmarshal the precompile config.
 func (*configurator) MakeConfig() precompileconfig.Config {
-	return new(Config)
+	return &Config{verifiedSignatures: newVerifiedSignatureCache()}
 }
 
 // Configure is a no-op for warp since it does not need to store any information in the state
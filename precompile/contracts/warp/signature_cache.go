@@ -0,0 +1,84 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"encoding/binary"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/coreth/metrics"
+)
+
+const verifiedSignatureCacheSize = 1024
+
+var (
+	verifiedSignatureCacheHit  = metrics.GetOrRegisterCounter("warp_verified_signature_cache_hit", nil)
+	verifiedSignatureCacheMiss = metrics.GetOrRegisterCounter("warp_verified_signature_cache_miss", nil)
+)
+
+// verifiedSignatureCache records the signed warp messages whose aggregate
+// signature was verified by this node, so that a message included in several
+// blocks is only verified once per validator set.
+//
+// Only successful verifications are cached, and each entry is keyed by
+// everything the verification depends on, so a cache hit always agrees with
+// the result of verifying the message again.
+type verifiedSignatureCache struct {
+	verified *cache.LRU[ids.ID, struct{}]
+}
+
+func newVerifiedSignatureCache() *verifiedSignatureCache {
+	return &verifiedSignatureCache{
+		verified: &cache.LRU[ids.ID, struct{}]{Size: verifiedSignatureCacheSize},
+	}
+}
+
+// verifiedSignatureKey returns the cache key of the signed warp message
+// [signedMessageBytes] verified on [networkID] by the subnet [subnetID]
+// against the validator set at [pChainHeight] with the given quorum.
+func verifiedSignatureKey(
+	signedMessageBytes []byte,
+	networkID uint32,
+	subnetID ids.ID,
+	pChainHeight uint64,
+	quorumNumerator uint64,
+	quorumDenominator uint64,
+) ids.ID {
+	// The validator set used for verification is identified by the
+	// receiving subnet, which determines the validators of messages from the
+	// Primary Network, and the P-Chain height.
+	buf := make([]byte, 0, 4+len(subnetID)+3*8+len(signedMessageBytes))
+	buf = binary.BigEndian.AppendUint32(buf, networkID)
+	buf = append(buf, subnetID[:]...)
+	buf = binary.BigEndian.AppendUint64(buf, pChainHeight)
+	buf = binary.BigEndian.AppendUint64(buf, quorumNumerator)
+	buf = binary.BigEndian.AppendUint64(buf, quorumDenominator)
+	buf = append(buf, signedMessageBytes...)
+	return hashing.ComputeHash256Array(buf)
+}
+
+// contains returns true if the message identified by [key] was previously
+// verified. A nil cache never contains any message.
+func (c *verifiedSignatureCache) contains(key ids.ID) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.verified.Get(key)
+	if ok {
+		verifiedSignatureCacheHit.Inc(1)
+	} else {
+		verifiedSignatureCacheMiss.Inc(1)
+	}
+	return ok
+}
+
+// add records that the message identified by [key] passed verification.
+func (c *verifiedSignatureCache) add(key ids.ID) {
+	if c == nil {
+		return
+	}
+	c.verified.Put(key, struct{}{})
+}
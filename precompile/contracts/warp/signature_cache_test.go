@@ -0,0 +1,107 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/snow/validators/validatorstest"
+	"github.com/ava-labs/coreth/precompile/precompileconfig"
+	"github.com/ava-labs/coreth/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingPredicateContext returns a predicate context at [pChainHeight]
+// whose validator set consists of the first 100 test validators, and a
+// pointer to the number of signature verifications performed against it.
+func newCountingPredicateContext(pChainHeight uint64) (*precompileconfig.PredicateContext, *int) {
+	snowCtx := createSnowCtx([]validatorRange{
+		{
+			start:     0,
+			end:       100,
+			weight:    20,
+			publicKey: true,
+		},
+	})
+	// Each signature verification fetches the validator set exactly once.
+	var verifications int
+	state := snowCtx.ValidatorState.(*validatorstest.State)
+	getValidatorSet := state.GetValidatorSetF
+	state.GetValidatorSetF = func(ctx context.Context, height uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+		verifications++
+		return getValidatorSet(ctx, height, subnetID)
+	}
+	return &precompileconfig.PredicateContext{
+		SnowCtx: snowCtx,
+		ProposerVMBlockCtx: &block.Context{
+			PChainHeight: pChainHeight,
+		},
+	}, &verifications
+}
+
+func TestVerifyPredicateCachesVerifiedSignatures(t *testing.T) {
+	require := require.New(t)
+
+	config := NewDefaultConfig(utils.NewUint64(0))
+	predicateContext, verifications := newCountingPredicateContext(1)
+	predicateBytes := createPredicate(int(WarpDefaultQuorumNumerator))
+
+	require.NoError(config.VerifyPredicate(predicateContext, predicateBytes))
+	require.Equal(1, *verifications)
+
+	// A second block with the same message at the same P-Chain height skips
+	// the signature verification.
+	require.NoError(config.VerifyPredicate(predicateContext, predicateBytes))
+	require.Equal(1, *verifications)
+
+	// The validator set changes with the P-Chain height.
+	predicateContext.ProposerVMBlockCtx = &block.Context{PChainHeight: 2}
+	require.NoError(config.VerifyPredicate(predicateContext, predicateBytes))
+	require.Equal(2, *verifications)
+
+	// A different signature of the same message is verified.
+	otherPredicateBytes := createPredicate(int(WarpDefaultQuorumNumerator) + 1)
+	require.NoError(config.VerifyPredicate(predicateContext, otherPredicateBytes))
+	require.Equal(3, *verifications)
+
+	// Verifications are not shared between configs, which may require a
+	// different quorum.
+	otherConfig := NewConfig(utils.NewUint64(0), WarpQuorumDenominator)
+	err := otherConfig.VerifyPredicate(predicateContext, predicateBytes)
+	require.ErrorIs(err, errFailedVerification)
+	require.Equal(4, *verifications)
+}
+
+func TestVerifyPredicateDoesNotCacheFailures(t *testing.T) {
+	require := require.New(t)
+
+	config := NewDefaultConfig(utils.NewUint64(0))
+	predicateContext, verifications := newCountingPredicateContext(1)
+	predicateBytes := createPredicate(int(WarpDefaultQuorumNumerator) - 1)
+
+	for i := 1; i <= 2; i++ {
+		err := config.VerifyPredicate(predicateContext, predicateBytes)
+		require.ErrorIs(err, errFailedVerification)
+		require.Equal(i, *verifications)
+	}
+}
+
+func TestVerifyPredicateWithoutCache(t *testing.T) {
+	require := require.New(t)
+
+	// Configs which are not constructed through NewConfig or the module do
+	// not cache verifications.
+	config := &Config{}
+	predicateContext, verifications := newCountingPredicateContext(1)
+	predicateBytes := createPredicate(int(WarpDefaultQuorumNumerator))
+
+	for i := 1; i <= 2; i++ {
+		require.NoError(config.VerifyPredicate(predicateContext, predicateBytes))
+		require.Equal(i, *verifications)
+	}
+}
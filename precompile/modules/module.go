@@ -19,6 +19,8 @@ type Module struct {
 	// this config is enabled.
 	Contract contract.StatefulPrecompiledContract
 	// Configurator is used to configure the stateful precompile when the config is enabled.
+	// If it also implements contract.ConfigReader, other precompiles may read
+	// this precompile's config with contract.ReadModuleConfig.
 	contract.Configurator
 }
 
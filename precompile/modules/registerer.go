@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"github.com/ava-labs/coreth/constants"
+	"github.com/ava-labs/coreth/precompile/contract"
 	"github.com/ava-labs/coreth/utils"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -64,6 +65,13 @@ func RegisterModule(stm Module) error {
 			return fmt.Errorf("address %s already used by a stateful precompile", address)
 		}
 	}
+	// Expose the module's config to other precompiles if it supports reading
+	// its config from state.
+	if reader, ok := stm.Configurator.(contract.ConfigReader); ok {
+		if err := contract.RegisterConfigReader(address, reader); err != nil {
+			return err
+		}
+	}
 	// sort by address to ensure deterministic iteration
 	registeredModules = insertSortedByAddress(registeredModules, stm)
 	return nil
@@ -0,0 +1,34 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/ava-labs/coreth/precompile/contract"
+	"github.com/stretchr/testify/require"
+)
+
+// RegisterModuleForTest registers [module] until the test completes, so that
+// modules used by a single test are not visible to the rest of its package.
+// Tests registering modules must not run in parallel with tests using the
+// registered modules. Modules whose Configurator is a [contract.ConfigReader]
+// are not supported, since their config reader can not be unregistered.
+func RegisterModuleForTest(t testing.TB, module Module) {
+	t.Helper()
+
+	_, isConfigReader := module.Configurator.(contract.ConfigReader)
+	require.False(t, isConfigReader, "config readers can not be registered for a test")
+	require.NoError(t, RegisterModule(module))
+	t.Cleanup(func() {
+		for i, registeredModule := range registeredModules {
+			if registeredModule.Address == module.Address {
+				registeredModules = append(registeredModules[:i:i], registeredModules[i+1:]...)
+				return
+			}
+		}
+	})
+}
@@ -5,6 +5,8 @@
 package precompileconfig
 
 import (
+	"math/big"
+
 	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
@@ -31,6 +33,11 @@ type Config interface {
 	Verify(ChainConfig) error
 }
 
+// ConfigVersion identifies the layout of the storage of a stateful precompile.
+// The storage of a precompile starts at version 0 and is migrated to later
+// versions by the storage migrations scheduled in the upgrade config.
+type ConfigVersion uint64
+
 // PredicateContext is the context passed in to the Predicater interface to verify
 // a precompile predicate within a specific ProposerVM wrapper.
 type PredicateContext struct {
@@ -85,4 +92,22 @@ type Accepter interface {
 type ChainConfig interface {
 	// IsDurango returns true if the time is after Durango.
 	IsDurango(time uint64) bool
+	// GetMinBaseFee returns the minimum base fee of the child of a block with
+	// [parentTimestamp], or nil if the child does not have a bounded base fee.
+	GetMinBaseFee(parentTimestamp uint64) *big.Int
+	// GetSubnetCreatorAddress returns the address of the creator of the
+	// subnet specified in the genesis, or the zero address if there is none.
+	GetSubnetCreatorAddress() common.Address
+	// ValidateAtBlockTimestamp returns an error if the upgrades active at
+	// [timestamp] are inconsistent.
+	ValidateAtBlockTimestamp(timestamp uint64) error
+}
+
+// StorageVersions is optionally implemented by the ChainConfig passed to
+// precompiles. Configurators of precompiles with storage migrations can use it
+// to write the storage layout of the version active at the block.
+type StorageVersions interface {
+	// GetPrecompileStorageVersion returns the storage layout version of the
+	// precompile with [key] at [timestamp].
+	GetPrecompileStorageVersion(key string, timestamp uint64) ConfigVersion
 }
@@ -10,6 +10,7 @@
 package precompileconfig
 
 import (
+	big "math/big"
 	reflect "reflect"
 
 	common "github.com/ethereum/go-ethereum/common"
@@ -184,6 +185,34 @@ func (m *MockChainConfig) EXPECT() *MockChainConfigMockRecorder {
 	return m.recorder
 }
 
+// GetMinBaseFee mocks base method.
+func (m *MockChainConfig) GetMinBaseFee(arg0 uint64) *big.Int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMinBaseFee", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	return ret0
+}
+
+// GetMinBaseFee indicates an expected call of GetMinBaseFee.
+func (mr *MockChainConfigMockRecorder) GetMinBaseFee(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinBaseFee", reflect.TypeOf((*MockChainConfig)(nil).GetMinBaseFee), arg0)
+}
+
+// GetSubnetCreatorAddress mocks base method.
+func (m *MockChainConfig) GetSubnetCreatorAddress() common.Address {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetCreatorAddress")
+	ret0, _ := ret[0].(common.Address)
+	return ret0
+}
+
+// GetSubnetCreatorAddress indicates an expected call of GetSubnetCreatorAddress.
+func (mr *MockChainConfigMockRecorder) GetSubnetCreatorAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetCreatorAddress", reflect.TypeOf((*MockChainConfig)(nil).GetSubnetCreatorAddress))
+}
+
 // IsDurango mocks base method.
 func (m *MockChainConfig) IsDurango(arg0 uint64) bool {
 	m.ctrl.T.Helper()
@@ -198,6 +227,20 @@ func (mr *MockChainConfigMockRecorder) IsDurango(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDurango", reflect.TypeOf((*MockChainConfig)(nil).IsDurango), arg0)
 }
 
+// ValidateAtBlockTimestamp mocks base method.
+func (m *MockChainConfig) ValidateAtBlockTimestamp(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAtBlockTimestamp", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAtBlockTimestamp indicates an expected call of ValidateAtBlockTimestamp.
+func (mr *MockChainConfigMockRecorder) ValidateAtBlockTimestamp(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAtBlockTimestamp", reflect.TypeOf((*MockChainConfig)(nil).ValidateAtBlockTimestamp), arg0)
+}
+
 // MockAccepter is a mock of Accepter interface.
 type MockAccepter struct {
 	ctrl     *gomock.Controller
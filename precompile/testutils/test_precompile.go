@@ -51,6 +51,9 @@ type PrecompileTest struct {
 	// ChainConfig is the chain config to use for the precompile's block context
 	// If nil, the default chain config will be used.
 	ChainConfig precompileconfig.ChainConfig
+	// GasSchedule is the gas schedule returned by the precompile's accessible state
+	// If nil, the default gas schedule will be used.
+	GasSchedule *contract.GasSchedule
 }
 
 type PrecompileRunparams struct {
@@ -112,6 +115,19 @@ func (test PrecompileTest) setup(t testing.TB, module modules.Module, state cont
 	accessibleState.EXPECT().GetBlockContext().Return(blockContext).AnyTimes()
 	accessibleState.EXPECT().GetSnowContext().Return(snowContext).AnyTimes()
 	accessibleState.EXPECT().GetChainConfig().Return(chainConfig).AnyTimes()
+	gasSchedule := contract.DefaultGasSchedule()
+	if test.GasSchedule != nil {
+		gasSchedule = *test.GasSchedule
+	}
+	accessibleState.EXPECT().GetGasSchedule().Return(gasSchedule).AnyTimes()
+	accessibleState.EXPECT().IsReadOnly().Return(test.ReadOnly).AnyTimes()
+	if supplyState, ok := state.(interface {
+		GetTotalSupply(uint64) (*big.Int, uint64, bool)
+	}); ok {
+		accessibleState.EXPECT().GetTotalSupply(gomock.Any()).DoAndReturn(func(suppliedGas uint64) (*big.Int, uint64, error) {
+			return contract.TotalSupplyWithGas(supplyState.GetTotalSupply, suppliedGas)
+		}).AnyTimes()
+	}
 
 	if test.Config != nil {
 		err := module.Configure(chainConfig, test.Config, state, blockContext)
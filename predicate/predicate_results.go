@@ -70,13 +70,16 @@ func ParseResults(b []byte) (*Results, error) {
 	return res, nil
 }
 
-// GetResults returns the byte array results for [txHash] from precompile [address] if available.
-func (r *Results) GetResults(txHash common.Hash, address common.Address) []byte {
+// GetResults returns the byte array results for [txHash] from precompile [address].
+// Returns false if there are no results for the pair. Note an empty result may be
+// present, for example if all of the predicates of [address] passed verification.
+func (r *Results) GetResults(txHash common.Hash, address common.Address) ([]byte, bool) {
 	txResults, ok := r.Results[txHash]
 	if !ok {
-		return nil
+		return nil, false
 	}
-	return txResults[address]
+	res, ok := txResults[address]
+	return res, ok
 }
 
 // SetTxResults sets the predicate results for the given [txHash]. Overrides results if present.
@@ -4,8 +4,10 @@
 package predicate
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
@@ -114,15 +116,74 @@ func TestPredicateResultsAccessors(t *testing.T) {
 		addr: predicateResult,
 	}
 
-	require.Empty(predicateResults.GetResults(txHash, addr))
+	requireNoResults := func() {
+		res, ok := predicateResults.GetResults(txHash, addr)
+		require.False(ok)
+		require.Empty(res)
+	}
+	requireResults := func() {
+		res, ok := predicateResults.GetResults(txHash, addr)
+		require.True(ok)
+		require.Equal(predicateResult, res)
+	}
+
+	requireNoResults()
 	predicateResults.SetTxResults(txHash, txPredicateResults)
-	require.Equal(predicateResult, predicateResults.GetResults(txHash, addr))
+	requireResults()
 	predicateResults.DeleteTxResults(txHash)
-	require.Empty(predicateResults.GetResults(txHash, addr))
+	requireNoResults()
 
 	// Ensure setting empty tx predicate results removes the entry
 	predicateResults.SetTxResults(txHash, txPredicateResults)
-	require.Equal(predicateResult, predicateResults.GetResults(txHash, addr))
+	requireResults()
 	predicateResults.SetTxResults(txHash, map[common.Address][]byte{})
-	require.Empty(predicateResults.GetResults(txHash, addr))
+	requireNoResults()
+}
+
+func TestPredicateResultsPresence(t *testing.T) {
+	txHash := common.Hash{1}
+	addr := common.Address{2}
+	for name, test := range map[string]struct {
+		txResults      TxResults
+		expectedOk     bool
+		expectedResult []byte
+	}{
+		"no predicates": {
+			txResults:  nil,
+			expectedOk: false,
+		},
+		"predicates of another address": {
+			txResults:  TxResults{{3}: {}},
+			expectedOk: false,
+		},
+		"all predicates failed": {
+			txResults:      TxResults{addr: set.NewBits(0, 1, 2).Bytes()},
+			expectedOk:     true,
+			expectedResult: set.NewBits(0, 1, 2).Bytes(),
+		},
+		"all predicates passed": {
+			txResults:      TxResults{addr: set.NewBits().Bytes()},
+			expectedOk:     true,
+			expectedResult: []byte{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			results := NewResults()
+			results.SetTxResults(txHash, test.txResults)
+
+			// Presence must survive the round trip through the block header.
+			b, err := results.Bytes()
+			require.NoError(err)
+			parsedResults, err := ParseResults(b)
+			require.NoError(err)
+
+			for _, r := range []*Results{results, parsedResults} {
+				res, ok := r.GetResults(txHash, addr)
+				require.Equal(test.expectedOk, ok)
+				require.True(bytes.Equal(test.expectedResult, res))
+			}
+		})
+	}
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -133,6 +134,7 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 		request:   &leafsRequest,
 		response:  &leafsResponse,
 		t:         t,
+		codec:     lrh.codec,
 		keyLength: keyLength,
 		limit:     limit,
 		stats:     lrh.stats,
@@ -160,7 +162,12 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 		return nil, nil
 	}
 
-	responseBytes, err := lrh.codec.Marshal(message.Version, leafsResponse)
+	var responseBytes []byte
+	if responseBuilder.leafsBytes != nil {
+		responseBytes, err = message.AppendLeafsResponseProof(lrh.codec, responseBuilder.leafsBytes, leafsResponse.ProofVals)
+	} else {
+		responseBytes, err = lrh.codec.Marshal(message.Version, leafsResponse)
+	}
 	if err != nil {
 		log.Debug("failed to marshal LeafsResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", leafsRequest, "err", err)
 		return nil, nil
@@ -175,9 +182,14 @@ type responseBuilder struct {
 	response  *message.LeafsResponse
 	t         *trie.Trie
 	snap      *snapshot.Tree
+	codec     codec.Manager
 	keyLength int
 	limit     uint16
 
+	// leafsBytes is the marshalled keys and values of the response, if they
+	// were marshalled while the range proof was generated.
+	leafsBytes []byte
+
 	// stats
 	trieReadTime time.Duration
 	proofTime    time.Duration
@@ -196,10 +208,24 @@ func (rb *responseBuilder) handleRequest(ctx context.Context) error {
 		rb.response.ProofVals = nil
 	}
 
+	// The proof for a non-empty start of the range is always included in the
+	// response and does not depend on the leafs, so generate it on a copy of
+	// the trie while iterating the trie. The proof for an empty start is only
+	// generated if it is included in the response.
+	proof := memorydb.New()
+	defer proof.Close() // closing memdb does not error
+	var startProofErr <-chan error
+	if len(rb.request.Start) > 0 {
+		startProofErr = rb.proveAsync(rb.t.Copy(), rb.request.Start, proof)
+	}
+
 	if len(rb.response.Keys) < int(rb.limit) {
 		// more indicates whether there are more leaves in the trie
 		more, err := rb.fillFromTrie(ctx, rb.request.End)
 		if err != nil {
+			if startProofErr != nil {
+				<-startProofErr
+			}
 			rb.stats.IncTrieError()
 			return err
 		}
@@ -209,19 +235,59 @@ func (rb *responseBuilder) handleRequest(ctx context.Context) error {
 		}
 	}
 
-	// Generate the proof and add it to the response.
-	proof, err := rb.generateRangeProof(rb.request.Start, rb.response.Keys)
-	if err != nil {
-		rb.stats.IncProofError()
+	// The leafs are final, so marshal them while the proof is completed.
+	var (
+		eg         errgroup.Group
+		leafsBytes []byte
+	)
+	eg.Go(func() error {
+		var err error
+		leafsBytes, err = rb.codec.Marshal(message.Version, message.LeafsResponse{
+			Keys: rb.response.Keys,
+			Vals: rb.response.Vals,
+		})
+		return err
+	})
+	proofErr := rb.completeRangeProof(startProofErr, proof)
+	if err := eg.Wait(); err != nil {
 		return err
 	}
-	defer proof.Close() // closing memdb does not error
+	if proofErr != nil {
+		rb.stats.IncProofError()
+		return proofErr
+	}
 
-	rb.response.ProofVals, err = iterateVals(proof)
+	proofVals, err := iterateVals(proof)
 	if err != nil {
 		rb.stats.IncProofError()
 		return err
 	}
+	rb.response.ProofVals = proofVals
+	rb.leafsBytes = leafsBytes
+	return nil
+}
+
+// completeRangeProof waits for the proof of the start of the range sent on
+// [startProofErr], or generates it if [startProofErr] is nil, and adds the
+// proof of the last key in the response to [proof].
+func (rb *responseBuilder) completeRangeProof(startProofErr <-chan error, proof *memorydb.Database) error {
+	if startProofErr != nil {
+		if err := <-startProofErr; err != nil {
+			return err
+		}
+	}
+
+	startTime := time.Now()
+	defer func() { rb.proofTime += time.Since(startTime) }()
+
+	if startProofErr == nil {
+		if err := rb.prove(rb.t, rb.request.Start, proof); err != nil {
+			return err
+		}
+	}
+	if len(rb.response.Keys) > 0 {
+		return rb.prove(rb.t, rb.response.Keys[len(rb.response.Keys)-1], proof)
+	}
 	return nil
 }
 
@@ -331,31 +397,56 @@ func (rb *responseBuilder) fillFromSnapshot(ctx context.Context) (bool, error) {
 }
 
 // generateRangeProof returns a range proof for the range specified by [start] and [keys] using [t].
+// The proofs for both ends of the range are generated concurrently, the end on
+// a copy of [t] since a trie must not be accessed concurrently. Since the proof
+// is keyed by node hash, the result is identical to proving them serially.
 func (rb *responseBuilder) generateRangeProof(start []byte, keys [][]byte) (*memorydb.Database, error) {
 	proof := memorydb.New()
 	startTime := time.Now()
 	defer func() { rb.proofTime += time.Since(startTime) }()
 
-	// If [start] is empty, populate it with the appropriate length key starting at 0.
-	if len(start) == 0 {
-		start = bytes.Repeat([]byte{0x00}, rb.keyLength)
-	}
-
-	if err := rb.t.Prove(start, proof); err != nil {
-		_ = proof.Close() // closing memdb does not error
-		return nil, err
-	}
+	var eg errgroup.Group
 	if len(keys) > 0 {
 		// If there is a non-zero number of keys, set [end] for the range proof to the last key.
 		end := keys[len(keys)-1]
-		if err := rb.t.Prove(end, proof); err != nil {
-			_ = proof.Close() // closing memdb does not error
-			return nil, err
-		}
+		t := rb.t.Copy()
+		eg.Go(func() error {
+			return rb.prove(t, end, proof)
+		})
+	}
+	eg.Go(func() error {
+		return rb.prove(rb.t, start, proof)
+	})
+	if err := eg.Wait(); err != nil {
+		_ = proof.Close() // closing memdb does not error
+		return nil, err
 	}
 	return proof, nil
 }
 
+// prove writes the merkle proof for [key] in [t] to [proof]. If [key] is
+// empty, it is populated with the appropriate length key starting at 0.
+func (rb *responseBuilder) prove(t *trie.Trie, key []byte, proof *memorydb.Database) error {
+	if len(key) == 0 {
+		key = bytes.Repeat([]byte{0x00}, rb.keyLength)
+	}
+	return t.Prove(key, proof)
+}
+
+// proveAsync calls prove on a separate goroutine and returns a channel that
+// receives its result. [t] must not be accessed by the caller, and neither
+// [proof] nor [rb.proofTime] may be accessed until the result is received.
+func (rb *responseBuilder) proveAsync(t *trie.Trie, key []byte, proof *memorydb.Database) <-chan error {
+	errChan := make(chan error, 1)
+	go func() {
+		startTime := time.Now()
+		err := rb.prove(t, key, proof)
+		rb.proofTime += time.Since(startTime)
+		errChan <- err
+	}()
+	return errChan
+}
+
 // verifyRangeProof verifies the provided range proof with [keys/vals], starting at [start].
 // Returns a boolean indicating if there are more leaves to the right of the last key in the trie and a nil error if the range proof is successfully verified.
 func (rb *responseBuilder) verifyRangeProof(keys, vals [][]byte, start []byte, proof *memorydb.Database) (bool, error) {
@@ -7,7 +7,9 @@ import (
 	"bytes"
 	"context"
 	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/coreth/core/rawdb"
@@ -722,3 +724,162 @@ func assertRangeProofIsValid(t *testing.T, request *message.LeafsRequest, respon
 	assert.NoError(t, err)
 	assert.Equal(t, expectMore, more)
 }
+
+func TestLeafsRequestHandler_ProofMatchesSerialProof(t *testing.T) {
+	rand.Seed(1)
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, keys, _ := syncutils.GenerateTrie(t, trieDB, 10_000, common.HashLength)
+	leafsHandler := NewLeafsRequestHandler(trieDB, nil, message.Codec, &stats.MockHandlerStats{})
+
+	request := message.LeafsRequest{
+		Root:     root,
+		Start:    keys[100],
+		Limit:    maxLeavesLimit,
+		NodeType: message.StateTrieNode,
+	}
+	responseBytes, err := leafsHandler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+	assertSerialResponse(t, trieDB, &request, responseBytes, int(maxLeavesLimit))
+}
+
+// TestLeafsRequestHandler_ConcurrentRequests serves requests from many
+// goroutines with a shared handler, so that the race detector observes the
+// proofs generated concurrently with iterating the trie and with each other.
+func TestLeafsRequestHandler_ConcurrentRequests(t *testing.T) {
+	rand.Seed(1)
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, keys, _ := syncutils.GenerateTrie(t, trieDB, 10_000, common.HashLength)
+	mockHandlerStats := &stats.MockHandlerStats{}
+	leafsHandler := NewLeafsRequestHandler(trieDB, nil, message.Codec, mockHandlerStats)
+
+	const numRequests = 16
+	var (
+		requests  = make([]message.LeafsRequest, numRequests)
+		responses = make([][]byte, numRequests)
+		errs      = make([]error, numRequests)
+		wg        sync.WaitGroup
+	)
+	for i := range requests {
+		requests[i] = message.LeafsRequest{
+			Root:     root,
+			Limit:    maxLeavesLimit,
+			NodeType: message.StateTrieNode,
+		}
+		// Leave the start of the first request empty, so that both ways of
+		// generating the start proof are exercised.
+		if i > 0 {
+			requests[i].Start = keys[i*500]
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = leafsHandler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), uint32(i), requests[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range requests {
+		assert.NoError(t, errs[i])
+		assertSerialResponse(t, trieDB, &requests[i], responses[i], int(maxLeavesLimit))
+	}
+	// Proofs generated concurrently with iterating the trie must not be
+	// counted twice.
+	assert.LessOrEqual(t, mockHandlerStats.GenerateRangeProofTime, mockHandlerStats.LeafRequestProcessingTimeSum)
+}
+
+// assertSerialResponse asserts that [responseBytes] is a valid response to
+// [request] with [numKeys] keys and more leaves to the right, identical byte
+// for byte to the response with a serially generated proof.
+func assertSerialResponse(t *testing.T, trieDB *trie.Database, request *message.LeafsRequest, responseBytes []byte, numKeys int) {
+	t.Helper()
+
+	var response message.LeafsResponse
+	_, err := message.Codec.Unmarshal(responseBytes, &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Keys, numKeys)
+	assertRangeProofIsValid(t, request, &response, true)
+
+	start := request.Start
+	if len(start) == 0 {
+		start = bytes.Repeat([]byte{0x00}, common.HashLength)
+	}
+	tr, err := trie.New(trie.TrieID(request.Root), trieDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := rawdb.NewMemoryDatabase()
+	defer proof.Close()
+	assert.NoError(t, tr.Prove(start, proof))
+	assert.NoError(t, tr.Prove(response.Keys[len(response.Keys)-1], proof))
+	it := proof.NewIterator(nil, nil)
+	defer it.Release()
+	expectedProofVals := make([][]byte, 0)
+	for it.Next() {
+		expectedProofVals = append(expectedProofVals, common.CopyBytes(it.Value()))
+	}
+	assert.NoError(t, it.Error())
+
+	expectedResponse := message.LeafsResponse{
+		Keys:      response.Keys,
+		Vals:      response.Vals,
+		ProofVals: expectedProofVals,
+	}
+	expectedBytes, err := message.Codec.Marshal(message.Version, expectedResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedBytes, responseBytes)
+}
+
+func TestLeafsRequestHandler_DeadlineReturnsValidProof(t *testing.T) {
+	rand.Seed(1)
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, _, _ := syncutils.GenerateTrie(t, trieDB, 10_000, common.HashLength)
+	leafsHandler := NewLeafsRequestHandler(trieDB, nil, message.Codec, &stats.MockHandlerStats{})
+
+	request := message.LeafsRequest{
+		Root:     root,
+		Start:    bytes.Repeat([]byte{0x00}, common.HashLength),
+		Limit:    maxLeavesLimit,
+		NodeType: message.StateTrieNode,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The handler must return before the deadline is exceeded by a large
+	// margin, and any partial response must still carry a valid range proof.
+	start := time.Now()
+	responseBytes, err := leafsHandler.OnLeafsRequest(ctx, ids.GenerateTestNodeID(), 1, request)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	if responseBytes == nil {
+		return
+	}
+
+	var response message.LeafsResponse
+	_, err = message.Codec.Unmarshal(responseBytes, &response)
+	assert.NoError(t, err)
+	assertRangeProofIsValid(t, &request, &response, true)
+}
+
+func BenchmarkLeafsRequestHandler_MaxResponse(b *testing.B) {
+	trieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, keys, _ := syncutils.GenerateTrie(b, trieDB, 100_000, common.HashLength)
+	leafsHandler := NewLeafsRequestHandler(trieDB, nil, message.Codec, &stats.MockHandlerStats{})
+
+	request := message.LeafsRequest{
+		Root:     root,
+		Start:    keys[len(keys)/2],
+		Limit:    maxLeavesLimit,
+		NodeType: message.StateTrieNode,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		response, err := leafsHandler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, request)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(response) == 0 {
+			b.Fatal("expected non-empty response")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/coreth/core/state"
+	"github.com/ava-labs/coreth/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/coreth/sync/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	_ state.NodeHealer = (*NodeHealer)(nil)
+
+	errInvalidNodePath = errors.New("invalid trie node path")
+	errNodeNotInProof  = errors.New("trie node not in range proof")
+)
+
+// NodeHealer fetches trie nodes missing from the local database from peers.
+// There is no request for a single trie node, so the node is taken from the
+// range proof of a leafs request starting at a key below its path, which
+// contains every node on the path to the key.
+type NodeHealer struct {
+	client statesyncclient.Client
+}
+
+// NewNodeHealer returns a NodeHealer sending its requests with [client].
+func NewNodeHealer(client statesyncclient.Client) *NodeHealer {
+	return &NodeHealer{client: client}
+}
+
+// HealNode implements state.NodeHealer. The client retries failed requests,
+// so the time spent is bounded by [ctx].
+func (h *NodeHealer) HealNode(ctx context.Context, root common.Hash, account common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	start, err := keyFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	response, err := h.client.GetLeafs(ctx, message.LeafsRequest{
+		Root:     root,
+		Account:  account,
+		Start:    start,
+		Limit:    1,
+		NodeType: message.StateTrieNode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range response.ProofVals {
+		if crypto.Keccak256Hash(node) == hash {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %x", errNodeNotInProof, hash)
+}
+
+// keyFromPath returns the smallest key of the state trie below the node at
+// [path], which is in nibbles.
+func keyFromPath(path []byte) ([]byte, error) {
+	if len(path) > 2*common.HashLength {
+		return nil, fmt.Errorf("%w: %d nibbles", errInvalidNodePath, len(path))
+	}
+	key := make([]byte, common.HashLength)
+	for i, nibble := range path {
+		if nibble > 0xf {
+			return nil, fmt.Errorf("%w: nibble %d is %d", errInvalidNodePath, i, nibble)
+		}
+		key[i/2] |= nibble << (4 * (1 - i%2))
+	}
+	return key, nil
+}
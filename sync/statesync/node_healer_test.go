@@ -0,0 +1,52 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/coreth/sync/client"
+	"github.com/ava-labs/coreth/sync/handlers"
+	handlerstats "github.com/ava-labs/coreth/sync/handlers/stats"
+	"github.com/ava-labs/coreth/sync/syncutils"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeHealer(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	serverTrieDB := trie.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	root, _, _ := syncutils.GenerateTrie(t, serverTrieDB, 500, common.HashLength)
+	leafsRequestHandler := handlers.NewLeafsRequestHandler(serverTrieDB, nil, message.Codec, handlerstats.NewNoopHandlerStats())
+	healer := NewNodeHealer(statesyncclient.NewMockClient(message.Codec, leafsRequestHandler, nil, nil))
+
+	// Every node of the trie can be healed from its path and hash.
+	tr, err := trie.New(trie.TrieID(root), serverTrieDB)
+	require.NoError(err)
+	it, err := tr.NodeIterator(nil)
+	require.NoError(err)
+	var healed int
+	for it.Next(true) {
+		if it.Hash() == (common.Hash{}) {
+			continue
+		}
+		node, err := healer.HealNode(ctx, root, common.Hash{}, it.Path(), it.Hash())
+		require.NoError(err, "path %x", it.Path())
+		require.Equal(it.NodeBlob(), node)
+		healed++
+	}
+	require.NoError(it.Error())
+	require.Greater(healed, 1)
+
+	_, err = healer.HealNode(ctx, root, common.Hash{}, []byte{0x1}, common.Hash{0x1})
+	require.ErrorIs(err, errNodeNotInProof)
+	_, err = healer.HealNode(ctx, root, common.Hash{}, []byte{0x10}, common.Hash{0x1})
+	require.ErrorIs(err, errInvalidNodePath)
+}
@@ -0,0 +1,255 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/time/rate"
+)
+
+// Sources of the accounts checked by [VerifySyncedState], reported in each
+// [Mismatch].
+const (
+	SourceTrieSample     = "trie sample"
+	SourceSnapshotSample = "snapshot sample"
+	SourceAccountList    = "account list"
+)
+
+// VerifierConfig configures the post-sync verification pass.
+type VerifierConfig struct {
+	DB   ethdb.Database
+	Root common.Hash
+
+	// NumAccounts is the number of accounts sampled from each of the trie and
+	// the snapshot, starting from random hashes.
+	NumAccounts int
+	// NumStorageSlots is the number of storage slots sampled from each of the
+	// storage trie and the storage snapshot of each checked account.
+	NumStorageSlots int
+	// Accounts are checked in addition to the sampled accounts, such as the
+	// accounts touched by the most recent blocks.
+	Accounts []common.Address
+	// ChecksPerSecond limits the rate at which accounts and slots are checked.
+	// Zero means unlimited.
+	ChecksPerSecond int
+	// Seed seeds the sampling, so that a pass can be reproduced.
+	Seed int64
+}
+
+// Mismatch describes a difference between the snapshot and the trie.
+// A nil value means the entry is missing.
+type Mismatch struct {
+	Source  string
+	Account common.Hash
+	// Slot is nil if the mismatch is in the account itself.
+	Slot *common.Hash
+	// Expected is the value resolved from the trie.
+	Expected []byte
+	// Got is the value read from the snapshot.
+	Got []byte
+}
+
+func (m Mismatch) String() string {
+	if m.Slot == nil {
+		return fmt.Sprintf("account %s (%s): expected %x, got %x", m.Account, m.Source, m.Expected, m.Got)
+	}
+	return fmt.Sprintf("account %s slot %s (%s): expected %x, got %x", m.Account, *m.Slot, m.Source, m.Expected, m.Got)
+}
+
+type verifier struct {
+	config     *VerifierConfig
+	trieDB     *trie.Database
+	accounts   *trie.Trie
+	rand       *rand.Rand
+	limiter    *rate.Limiter
+	checked    map[common.Hash]struct{}
+	mismatches []Mismatch
+}
+
+// VerifySyncedState cross-checks the snapshot in [config.DB] against the
+// trie at [config.Root] for a sample of accounts and storage slots, and
+// returns the mismatches found. It returns early with ctx.Err() if [ctx] is
+// cancelled.
+func VerifySyncedState(ctx context.Context, config *VerifierConfig) ([]Mismatch, error) {
+	trieDB := trie.NewDatabase(config.DB, nil)
+	accounts, err := trie.New(trie.StateTrieID(config.Root), trieDB)
+	if err != nil {
+		return nil, err
+	}
+	limit := rate.Inf
+	if config.ChecksPerSecond > 0 {
+		limit = rate.Limit(config.ChecksPerSecond)
+	}
+	v := &verifier{
+		config:   config,
+		trieDB:   trieDB,
+		accounts: accounts,
+		rand:     rand.New(rand.NewSource(config.Seed)), // #nosec G404
+		limiter:  rate.NewLimiter(limit, 1),
+		checked:  make(map[common.Hash]struct{}),
+	}
+
+	for i := 0; i < config.NumAccounts; i++ {
+		start := v.randomHash()
+		if accHash, ok, err := firstTrieKey(accounts, start); err != nil {
+			return nil, err
+		} else if ok {
+			if err := v.checkAccount(ctx, SourceTrieSample, accHash); err != nil {
+				return nil, err
+			}
+		}
+		if accHash, ok, err := firstSnapshotKey(config.DB, rawdb.SnapshotAccountPrefix, start); err != nil {
+			return nil, err
+		} else if ok {
+			if err := v.checkAccount(ctx, SourceSnapshotSample, accHash); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, addr := range config.Accounts {
+		if err := v.checkAccount(ctx, SourceAccountList, crypto.Keccak256Hash(addr[:])); err != nil {
+			return nil, err
+		}
+	}
+	return v.mismatches, nil
+}
+
+func (v *verifier) randomHash() common.Hash {
+	var h common.Hash
+	_, _ = v.rand.Read(h[:])
+	return h
+}
+
+// checkAccount compares the snapshot and trie entries of [accHash] and a
+// sample of its storage slots. Each account is checked at most once.
+func (v *verifier) checkAccount(ctx context.Context, source string, accHash common.Hash) error {
+	if _, ok := v.checked[accHash]; ok {
+		return nil
+	}
+	v.checked[accHash] = struct{}{}
+	if err := v.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	var (
+		expected    []byte
+		storageRoot = types.EmptyRootHash
+	)
+	encoded, err := v.accounts.Get(accHash[:])
+	if err != nil {
+		return err
+	}
+	if len(encoded) > 0 {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(encoded, &acc); err != nil {
+			return fmt.Errorf("failed to decode account %s: %w", accHash, err)
+		}
+		expected = types.SlimAccountRLP(acc)
+		storageRoot = acc.Root
+	}
+	if got := rawdb.ReadAccountSnapshot(v.config.DB, accHash); !bytes.Equal(expected, got) {
+		v.mismatches = append(v.mismatches, Mismatch{
+			Source:   source,
+			Account:  accHash,
+			Expected: expected,
+			Got:      got,
+		})
+	}
+
+	// Storage is sampled even if the account has no storage in the trie,
+	// so that leftover storage snapshot entries are detected.
+	storage, err := trie.New(trie.StorageTrieID(v.config.Root, accHash, storageRoot), v.trieDB)
+	if err != nil {
+		return err
+	}
+	storagePrefix := append(append([]byte{}, rawdb.SnapshotStoragePrefix...), accHash[:]...)
+	for i := 0; i < v.config.NumStorageSlots; i++ {
+		start := v.randomHash()
+		if slot, ok, err := firstTrieKey(storage, start); err != nil {
+			return err
+		} else if ok {
+			if err := v.checkSlot(ctx, source, accHash, slot, storage); err != nil {
+				return err
+			}
+		}
+		if slot, ok, err := firstSnapshotKey(v.config.DB, storagePrefix, start); err != nil {
+			return err
+		} else if ok {
+			if err := v.checkSlot(ctx, source, accHash, slot, storage); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *verifier) checkSlot(ctx context.Context, source string, accHash, slot common.Hash, storage *trie.Trie) error {
+	if err := v.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	expected, err := storage.Get(slot[:])
+	if err != nil {
+		return err
+	}
+	if got := rawdb.ReadStorageSnapshot(v.config.DB, accHash, slot); !bytes.Equal(expected, got) {
+		v.mismatches = append(v.mismatches, Mismatch{
+			Source:   source,
+			Account:  accHash,
+			Slot:     &slot,
+			Expected: expected,
+			Got:      got,
+		})
+	}
+	return nil
+}
+
+// firstTrieKey returns the first key of [tr] at or after [start], wrapping
+// around to the first key of [tr]. It returns false if [tr] is empty.
+func firstTrieKey(tr *trie.Trie, start common.Hash) (common.Hash, bool, error) {
+	for _, from := range [][]byte{start[:], nil} {
+		nodeIt, err := tr.NodeIterator(from)
+		if err != nil {
+			return common.Hash{}, false, err
+		}
+		it := trie.NewIterator(nodeIt)
+		if it.Next() {
+			return common.BytesToHash(it.Key), true, nil
+		}
+		if it.Err != nil {
+			return common.Hash{}, false, it.Err
+		}
+	}
+	return common.Hash{}, false, nil
+}
+
+// firstSnapshotKey returns the hash following [prefix] in the first snapshot
+// key at or after [start], wrapping around to the first key with [prefix].
+// It returns false if there are no keys with [prefix].
+func firstSnapshotKey(db ethdb.Iteratee, prefix []byte, start common.Hash) (common.Hash, bool, error) {
+	for _, from := range [][]byte{start[:], nil} {
+		it := rawdb.NewKeyLengthIterator(db.NewIterator(prefix, from), len(prefix)+common.HashLength)
+		ok := it.Next()
+		var key common.Hash
+		if ok {
+			key = common.BytesToHash(it.Key()[len(prefix):])
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil || ok {
+			return key, ok, err
+		}
+	}
+	return common.Hash{}, false, nil
+}
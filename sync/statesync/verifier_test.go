@@ -0,0 +1,131 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/coreth/core/rawdb"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/coreth/sync/client"
+	"github.com/ava-labs/coreth/sync/handlers"
+	handlerstats "github.com/ava-labs/coreth/sync/handlers/stats"
+	"github.com/ava-labs/coreth/sync/syncutils"
+	"github.com/ava-labs/coreth/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/require"
+)
+
+// syncAccountsWithStorage syncs [numAccounts] accounts with storage to a new
+// database and returns it with the synced root and the address of an account.
+func syncAccountsWithStorage(t *testing.T, numAccounts int) (ethdb.Database, common.Hash, common.Address) {
+	serverDB := rawdb.NewMemoryDatabase()
+	serverTrieDB := trie.NewDatabase(serverDB, nil)
+	root, accounts := syncutils.FillAccounts(t, serverTrieDB, common.Hash{}, numAccounts, func(t *testing.T, _ int, account types.StateAccount) types.StateAccount {
+		account.Root, _, _ = syncutils.GenerateTrie(t, serverTrieDB, 16, common.HashLength)
+		return account
+	})
+
+	leafsRequestHandler := handlers.NewLeafsRequestHandler(serverTrieDB, nil, message.Codec, handlerstats.NewNoopHandlerStats())
+	codeRequestHandler := handlers.NewCodeRequestHandler(serverDB, message.Codec, handlerstats.NewNoopHandlerStats())
+	clientDB := rawdb.NewMemoryDatabase()
+	s, err := NewStateSyncer(&StateSyncerConfig{
+		Client:                   statesyncclient.NewMockClient(message.Codec, leafsRequestHandler, codeRequestHandler, nil),
+		Root:                     root,
+		DB:                       clientDB,
+		BatchSize:                1000,
+		NumCodeFetchingWorkers:   DefaultNumCodeFetchingWorkers,
+		MaxOutstandingCodeHashes: DefaultMaxOutstandingCodeHashes,
+		RequestSize:              1024,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Start(context.Background()))
+	waitFor(t, s.Done(), nil, testSyncTimeout)
+
+	for key := range accounts {
+		return clientDB, root, key.Address
+	}
+	t.Fatal("no accounts created")
+	return nil, common.Hash{}, common.Address{}
+}
+
+func TestVerifySyncedState(t *testing.T) {
+	require := require.New(t)
+
+	db, root, addr := syncAccountsWithStorage(t, 20)
+	config := &VerifierConfig{
+		DB:              db,
+		Root:            root,
+		NumAccounts:     20,
+		NumStorageSlots: 4,
+		Accounts:        []common.Address{addr},
+		Seed:            1,
+	}
+	mismatches, err := VerifySyncedState(context.Background(), config)
+	require.NoError(err)
+	require.Empty(mismatches)
+
+	// Corrupt every storage snapshot slot of [addr], so that any sampled slot
+	// is detected.
+	accHash := crypto.Keccak256Hash(addr[:])
+	var slots []common.Hash
+	it := rawdb.IterateStorageSnapshots(db, accHash)
+	for it.Next() {
+		slots = append(slots, common.BytesToHash(it.Key()[len(rawdb.SnapshotStoragePrefix)+common.HashLength:]))
+	}
+	it.Release()
+	require.NoError(it.Error())
+	require.NotEmpty(slots)
+	for _, slot := range slots {
+		rawdb.WriteStorageSnapshot(db, accHash, slot, []byte{0x01})
+	}
+
+	mismatches, err = VerifySyncedState(context.Background(), &VerifierConfig{
+		DB:              db,
+		Root:            root,
+		NumStorageSlots: 1,
+		Accounts:        []common.Address{addr},
+	})
+	require.NoError(err)
+	require.NotEmpty(mismatches)
+	for _, mismatch := range mismatches {
+		require.Equal(SourceAccountList, mismatch.Source)
+		require.Equal(accHash, mismatch.Account)
+		require.NotNil(mismatch.Slot)
+		require.Contains(slots, *mismatch.Slot)
+		require.Equal([]byte{0x01}, mismatch.Got)
+		require.NotEqual(mismatch.Got, mismatch.Expected)
+	}
+
+	// A missing account snapshot is detected.
+	rawdb.DeleteAccountSnapshot(db, accHash)
+	mismatches, err = VerifySyncedState(context.Background(), &VerifierConfig{
+		DB:       db,
+		Root:     root,
+		Accounts: []common.Address{addr},
+	})
+	require.NoError(err)
+	require.Len(mismatches, 1)
+	require.Nil(mismatches[0].Slot)
+	require.Nil(mismatches[0].Got)
+	require.NotNil(mismatches[0].Expected)
+}
+
+func TestVerifySyncedStateCancelled(t *testing.T) {
+	db, root, _ := syncAccountsWithStorage(t, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := VerifySyncedState(ctx, &VerifierConfig{
+		DB:              db,
+		Root:            root,
+		NumAccounts:     20,
+		ChecksPerSecond: 1,
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -255,6 +255,12 @@ func (t *StateTrie) Hash() common.Hash {
 	return t.trie.Hash()
 }
 
+// Witness returns the set of the rlp-encoded trie nodes loaded from the
+// database since the trie was opened or last committed.
+func (t *StateTrie) Witness() map[string]struct{} {
+	return t.trie.Witness()
+}
+
 // Copy returns a copy of StateTrie.
 func (t *StateTrie) Copy() *StateTrie {
 	return &StateTrie{
@@ -657,6 +657,21 @@ func (t *Trie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet, error)
 	return rootHash, nodes, nil
 }
 
+// Witness returns the set of the rlp-encoded trie nodes loaded from the
+// database since the trie was opened or last committed, which are the nodes
+// needed to repeat the accesses and updates made to the trie from an empty
+// database containing only them.
+func (t *Trie) Witness() map[string]struct{} {
+	if len(t.tracer.accessList) == 0 {
+		return nil
+	}
+	witness := make(map[string]struct{}, len(t.tracer.accessList))
+	for _, node := range t.tracer.accessList {
+		witness[string(node)] = struct{}{}
+	}
+	return witness
+}
+
 // hashRoot calculates the root hash of the given trie
 func (t *Trie) hashRoot() (node, node) {
 	if t.root == nil {
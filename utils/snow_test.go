@@ -0,0 +1,43 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowContextValidators(t *testing.T) {
+	require := require.New(t)
+
+	for _, n := range []int{1, 5, 10, 20} {
+		snowCtx, secretKeys := TestSnowContextWithNValidators(t, n, 100)
+		require.Len(secretKeys, n)
+
+		subnetID, err := snowCtx.ValidatorState.GetSubnetID(context.Background(), snowCtx.ChainID)
+		require.NoError(err)
+		vdrs, err := snowCtx.ValidatorState.GetValidatorSet(context.Background(), 1, subnetID)
+		require.NoError(err)
+		require.Len(vdrs, n)
+
+		var totalWeight uint64
+		publicKeys := make(map[string]struct{}, n)
+		for _, vdr := range vdrs {
+			totalWeight += vdr.Weight
+			publicKeys[string(bls.PublicKeyToCompressedBytes(vdr.PublicKey))] = struct{}{}
+		}
+		require.Equal(uint64(n)*100, totalWeight)
+
+		// Every validator has a distinct key, which is returned for signing.
+		require.Len(publicKeys, n)
+		for _, sk := range secretKeys {
+			require.Contains(publicKeys, string(bls.PublicKeyToCompressedBytes(bls.PublicFromSecretKey(sk))))
+		}
+		require.Contains(vdrs, snowCtx.NodeID)
+		require.Equal(snowCtx.PublicKey, vdrs[snowCtx.NodeID].PublicKey)
+	}
+}
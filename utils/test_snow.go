@@ -0,0 +1,73 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package utils
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/snow/validators/validatorstest"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+)
+
+// TestSnowContextWithNValidators returns a snow context whose validator state
+// reports [n] validators of the context's subnet at every height, each with a
+// distinct BLS key and [weightPerValidator] weight, along with the secret keys
+// of the validators in order.
+// The context's own NodeID and PublicKey are those of the first validator.
+func TestSnowContextWithNValidators(t testing.TB, n int, weightPerValidator uint64) (*snow.Context, []*bls.SecretKey) {
+	t.Helper()
+	if n <= 0 {
+		t.Fatalf("number of validators must be positive, got %d", n)
+	}
+	if weightPerValidator == 0 || weightPerValidator > math.MaxUint64/uint64(n) {
+		t.Fatalf("invalid weight per validator %d for %d validators", weightPerValidator, n)
+	}
+
+	var (
+		secretKeys = make([]*bls.SecretKey, n)
+		vdrs       = make(map[ids.NodeID]*validators.GetValidatorOutput, n)
+		nodeIDs    = make([]ids.NodeID, n)
+	)
+	for i := 0; i < n; i++ {
+		sk, err := bls.NewSecretKey()
+		if err != nil {
+			t.Fatalf("failed to generate BLS key: %v", err)
+		}
+		nodeID := ids.GenerateTestNodeID()
+		secretKeys[i] = sk
+		nodeIDs[i] = nodeID
+		vdrs[nodeID] = &validators.GetValidatorOutput{
+			NodeID:    nodeID,
+			PublicKey: bls.PublicFromSecretKey(sk),
+			Weight:    weightPerValidator,
+		}
+	}
+
+	snowCtx := TestSnowContext()
+	snowCtx.NodeID = nodeIDs[0]
+	snowCtx.PublicKey = vdrs[nodeIDs[0]].PublicKey
+	snowCtx.ValidatorState = &validatorstest.State{
+		GetSubnetIDF: func(context.Context, ids.ID) (ids.ID, error) {
+			return snowCtx.SubnetID, nil
+		},
+		GetValidatorSetF: func(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			// Return a copy so that callers modifying the set do not affect
+			// later calls.
+			set := make(map[ids.NodeID]*validators.GetValidatorOutput, len(vdrs))
+			for nodeID, vdr := range vdrs {
+				vdrCopy := *vdr
+				set[nodeID] = &vdrCopy
+			}
+			return set, nil
+		},
+	}
+	return snowCtx, secretKeys
+}
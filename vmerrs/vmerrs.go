@@ -28,6 +28,12 @@ package vmerrs
 
 import (
 	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/coreth/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // List evm execution errors
@@ -39,7 +45,6 @@ var (
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrExecutionReverted        = errors.New("execution reverted")
 	ErrMaxInitCodeSizeExceeded  = errors.New("max initcode size exceeded")
-	ErrMaxCodeSizeExceeded      = errors.New("max code size exceeded")
 	ErrInvalidJump              = errors.New("invalid jump destination")
 	ErrWriteProtection          = errors.New("write protection")
 	ErrReturnDataOutOfBounds    = errors.New("return data out of bounds")
@@ -48,3 +53,278 @@ var (
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
 	ErrAddrProhibited           = errors.New("prohibited address cannot be sender or created contract address")
 )
+
+var (
+	// ErrExecutionAborted is returned when a call is made after the EVM was
+	// cancelled.
+	ErrExecutionAborted = errors.New("execution aborted")
+	// ErrExecutionTimeout is returned by RPC simulations which exceed their
+	// per-execution time limit.
+	ErrExecutionTimeout = errors.New("execution timeout")
+)
+
+// ErrIntrinsicGas is returned if a transaction's gas limit is below the
+// intrinsic gas required to process it, before any execution takes place.
+var ErrIntrinsicGas = errors.New("intrinsic gas too low")
+
+// Transaction-level validation errors, returned if a transaction can not be
+// applied to the current state before any execution takes place.
+var (
+	// ErrNonceTooLow is returned if the nonce of a transaction is lower than
+	// the one present in the local chain.
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than
+	// the next one expected based on the local chain.
+	ErrNonceTooHigh = errors.New("nonce too high")
+	// ErrInsufficientFunds is returned if the total cost of executing a
+	// transaction is higher than the balance of the sender.
+	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
+	// ErrFeeCapTooLow is returned if the fee cap of a transaction is less than
+	// the base fee of the block.
+	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+)
+
+// IsIntrinsicGasError reports whether [err] was caused by a transaction not
+// covering its intrinsic gas, as opposed to running out of gas during
+// execution.
+func IsIntrinsicGasError(err error) bool {
+	return errors.Is(err, ErrIntrinsicGas)
+}
+
+var (
+	// executionErrors are returned by the EVM while executing a transaction.
+	// A transaction failing with one of these errors is still included in a
+	// block.
+	executionErrors = []error{
+		ErrOutOfGas,
+		ErrCodeStoreOutOfGas,
+		ErrDepth,
+		ErrContractAddressCollision,
+		ErrExecutionReverted,
+		ErrMaxInitCodeSizeExceeded,
+		ErrMaxCodeSizeExceeded{},
+		ErrInvalidJump,
+		ErrWriteProtection,
+		ErrReturnDataOutOfBounds,
+		ErrGasUintOverflow,
+		ErrInvalidCode,
+		ErrNonceUintOverflow,
+		ErrInsufficientBalance,
+		ErrAddrProhibited,
+	}
+	// validationErrors are returned before a transaction is executed, when it
+	// can not be included in a block.
+	validationErrors = []error{
+		ErrIntrinsicGas,
+		ErrNonceTooLow,
+		ErrNonceTooHigh,
+		ErrInsufficientFunds,
+		ErrFeeCapTooLow,
+	}
+)
+
+// IsExecutionError reports whether [err] occurred while executing a
+// transaction in the EVM.
+//
+// Validation errors may wrap the execution-time error which caused them, so
+// IsExecutionError returns false for any error which IsValidationError.
+func IsExecutionError(err error) bool {
+	return !IsValidationError(err) && isAny(err, executionErrors)
+}
+
+// IsValidationError reports whether [err] indicates that a transaction is
+// invalid and can not be included in a block.
+func IsValidationError(err error) bool {
+	return isAny(err, validationErrors)
+}
+
+func isAny(err error, targets []error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrMaxCodeSizeExceeded is returned when the code returned by a contract
+// creation is larger than [Limit] bytes (EIP-170).
+type ErrMaxCodeSizeExceeded struct {
+	Limit, Size int
+}
+
+func (e ErrMaxCodeSizeExceeded) Error() string {
+	return fmt.Sprintf("max code size exceeded: size %d, limit %d", e.Size, e.Limit)
+}
+
+// Is reports whether [target] is an ErrMaxCodeSizeExceeded, regardless of its
+// field values.
+func (ErrMaxCodeSizeExceeded) Is(target error) bool {
+	_, ok := target.(ErrMaxCodeSizeExceeded)
+	return ok
+}
+
+// ErrWriteProtectionWithOpcode is an ErrWriteProtection recording the
+// [Opcode] which attempted to modify state during a static call.
+type ErrWriteProtectionWithOpcode struct {
+	Opcode byte
+}
+
+func (e ErrWriteProtectionWithOpcode) Error() string {
+	return fmt.Sprintf("%s: opcode 0x%x", ErrWriteProtection, e.Opcode)
+}
+
+func (ErrWriteProtectionWithOpcode) Unwrap() error {
+	return ErrWriteProtection
+}
+
+// AddrProhibitedError is an ErrAddrProhibited recording the prohibited [Addr]
+// which was used as a sender or created contract address.
+type AddrProhibitedError struct {
+	Addr common.Address
+}
+
+func (e AddrProhibitedError) Error() string {
+	return fmt.Sprintf("%s: address %s", ErrAddrProhibited, e.Addr.Hex())
+}
+
+func (AddrProhibitedError) Unwrap() error {
+	return ErrAddrProhibited
+}
+
+// IntrinsicGasError is an ErrIntrinsicGas recording the gas [Have] supplied
+// by a transaction and the intrinsic gas [Want] it requires.
+type IntrinsicGasError struct {
+	Have, Want uint64
+}
+
+func (e IntrinsicGasError) Error() string {
+	return fmt.Sprintf("%s: have %d, want %d", ErrIntrinsicGas, e.Have, e.Want)
+}
+
+func (IntrinsicGasError) Unwrap() error {
+	return ErrIntrinsicGas
+}
+
+// NonceTooLowError is an ErrNonceTooLow recording the sender [Addr], the
+// nonce of its transaction and the nonce of its account.
+type NonceTooLowError struct {
+	Addr       common.Address
+	TxNonce    uint64
+	StateNonce uint64
+}
+
+func (e NonceTooLowError) Error() string {
+	return fmt.Sprintf("%s: address %s, tx: %d state: %d", ErrNonceTooLow, e.Addr.Hex(), e.TxNonce, e.StateNonce)
+}
+
+func (NonceTooLowError) Unwrap() error {
+	return ErrNonceTooLow
+}
+
+// NonceTooHighError is an ErrNonceTooHigh recording the sender [Addr], the
+// nonce of its transaction and the next nonce expected for its account.
+type NonceTooHighError struct {
+	Addr       common.Address
+	TxNonce    uint64
+	StateNonce uint64
+}
+
+func (e NonceTooHighError) Error() string {
+	return fmt.Sprintf("%s: address %s, tx: %d state: %d", ErrNonceTooHigh, e.Addr.Hex(), e.TxNonce, e.StateNonce)
+}
+
+func (NonceTooHighError) Unwrap() error {
+	return ErrNonceTooHigh
+}
+
+// InsufficientFundsError is an ErrInsufficientFunds recording the sender
+// [Addr], its balance [Have] and the balance [Want] required to cover the
+// cost of its transactions. [Have] and [Want] must not be shared with the
+// state, which may modify them after the error is returned.
+type InsufficientFundsError struct {
+	Addr common.Address
+	Have *big.Int
+	Want *big.Int
+}
+
+func (e InsufficientFundsError) Error() string {
+	return fmt.Sprintf("%s: address %s have %v want %v", ErrInsufficientFunds, e.Addr.Hex(), e.Have, e.Want)
+}
+
+func (InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// FeeCapTooLowError is an ErrFeeCapTooLow recording the sender [Addr], the
+// fee cap of its transaction and the base fee of the block.
+type FeeCapTooLowError struct {
+	Addr    common.Address
+	FeeCap  *big.Int
+	BaseFee *big.Int
+}
+
+func (e FeeCapTooLowError) Error() string {
+	return fmt.Sprintf("%s: address %s, maxFeePerGas: %s, baseFee: %s", ErrFeeCapTooLow, e.Addr.Hex(), e.FeeCap, e.BaseFee)
+}
+
+func (FeeCapTooLowError) Unwrap() error {
+	return ErrFeeCapTooLow
+}
+
+// RevertError is an ErrExecutionReverted carrying the ABI-encoded revert data
+// returned by the EVM. It implements the ErrorCode and ErrorData methods of
+// rpc.DataError, so that the revert data is included in JSON-RPC errors.
+type RevertError struct {
+	// Reason is the raw revert data, which is not necessarily a standard
+	// Error(string) or Panic(uint256) call, such as for a custom Solidity
+	// error.
+	Reason []byte
+}
+
+// Error returns the message of ErrExecutionReverted, so that reverts are
+// reported the same way with or without revert data. The decoded revert
+// reason is returned by [Decode].
+func (e *RevertError) Error() string {
+	return ErrExecutionReverted.Error()
+}
+
+func (e *RevertError) Unwrap() error {
+	return ErrExecutionReverted
+}
+
+// ErrorCode returns the JSON-RPC error code for a revert.
+// See: https://github.com/ethereum/wiki/wiki/JSON-RPC-Error-Codes-Improvement-Proposal
+func (e *RevertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert data, which callers may decode
+// against the custom errors of the reverted contract.
+func (e *RevertError) ErrorData() interface{} {
+	return hexutil.Encode(e.Reason)
+}
+
+// Decode returns the revert reason ABI-decoded from [Reason] as a standard
+// Error(string) or Panic(uint256) call. It returns an error if [Reason] is not
+// one of these, such as for a custom Solidity error.
+func (e *RevertError) Decode() (string, error) {
+	return abi.UnpackRevert(e.Reason)
+}
+
+// IsRevertError reports whether [err] is, or wraps, ErrExecutionReverted,
+// including a RevertError carrying revert data.
+func IsRevertError(err error) bool {
+	return errors.Is(err, ErrExecutionReverted)
+}
+
+// ExtractRevertData returns the data of the RevertError wrapped by [err], or
+// nil if [err] does not wrap a RevertError. A revert without data, such as a
+// bare ErrExecutionReverted, also returns nil.
+func ExtractRevertData(err error) []byte {
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) {
+		return nil
+	}
+	return revertErr.Reason
+}
@@ -0,0 +1,303 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vmerrs
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertError(t *testing.T) {
+	tests := map[string]struct {
+		data         []byte
+		wantReason   string
+		wantDecoded  bool
+		wantErrorHex string
+	}{
+		"error string": {
+			// abi.encodeWithSignature("Error(string)", "insufficient allowance")
+			data: common.FromHex("0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000016" +
+				"696e73756666696369656e7420616c6c6f77616e636500000000000000000000"),
+			wantReason:  "insufficient allowance",
+			wantDecoded: true,
+		},
+		"panic": {
+			// abi.encodeWithSignature("Panic(uint256)", 0x11)
+			data: common.FromHex("0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000011"),
+			wantReason:  "arithmetic underflow or overflow",
+			wantDecoded: true,
+		},
+		"custom error": {
+			// abi.encodeWithSignature("InsufficientBalance(uint256,uint256)", 1, 2)
+			data: common.FromHex("0xcf479181" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002"),
+		},
+		"no data": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			var err error = fmt.Errorf("call failed: %w", &RevertError{Reason: test.data})
+			require.ErrorIs(err, ErrExecutionReverted)
+			var revertErr *RevertError
+			require.True(errors.As(err, &revertErr))
+			require.Equal(ErrExecutionReverted.Error(), revertErr.Error())
+			require.Equal(3, revertErr.ErrorCode())
+
+			// The revert data is passed through as is, whether or not it
+			// can be decoded.
+			require.Equal(hexutil.Encode(test.data), revertErr.ErrorData())
+
+			require.Equal(test.data, revertErr.Reason)
+			reason, err := revertErr.Decode()
+			if test.wantDecoded {
+				require.NoError(err)
+			} else {
+				require.Error(err)
+			}
+			require.Equal(test.wantReason, reason)
+		})
+	}
+}
+
+func TestIsRevertError(t *testing.T) {
+	data := []byte{0xca, 0xfe}
+	tests := map[string]struct {
+		err          error
+		expected     bool
+		expectedData []byte
+	}{
+		"sentinel": {
+			err:      ErrExecutionReverted,
+			expected: true,
+		},
+		"revert error": {
+			err:          &RevertError{Reason: data},
+			expected:     true,
+			expectedData: data,
+		},
+		"wrapped revert error": {
+			err:          fmt.Errorf("call failed: %w", &RevertError{Reason: data}),
+			expected:     true,
+			expectedData: data,
+		},
+		"wrapped sentinel": {
+			err:      fmt.Errorf("call failed: %w", ErrExecutionReverted),
+			expected: true,
+		},
+		"out of gas": {
+			err:      ErrOutOfGas,
+			expected: false,
+		},
+		"nil": {
+			err:      nil,
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, IsRevertError(test.err))
+			require.Equal(t, test.expectedData, ExtractRevertData(test.err))
+		})
+	}
+}
+
+func TestErrMaxCodeSizeExceeded(t *testing.T) {
+	require := require.New(t)
+
+	var err error = ErrMaxCodeSizeExceeded{Limit: 24576, Size: 24577}
+	require.Equal("max code size exceeded: size 24577, limit 24576", err.Error())
+	require.ErrorIs(err, ErrMaxCodeSizeExceeded{})
+	require.ErrorIs(fmt.Errorf("deployment failed: %w", err), ErrMaxCodeSizeExceeded{})
+	require.NotErrorIs(err, ErrExecutionReverted)
+	require.NotErrorIs(ErrExecutionReverted, ErrMaxCodeSizeExceeded{})
+
+	var sizeErr ErrMaxCodeSizeExceeded
+	require.True(errors.As(fmt.Errorf("deployment failed: %w", err), &sizeErr))
+	require.Equal(24577, sizeErr.Size)
+	require.Equal(24576, sizeErr.Limit)
+}
+
+func TestIsIntrinsicGasError(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"sentinel": {
+			err:      ErrIntrinsicGas,
+			expected: true,
+		},
+		"wrapped": {
+			err:      fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, 20_000, 21_000),
+			expected: true,
+		},
+		"overflow": {
+			err:      ErrGasUintOverflow,
+			expected: false,
+		},
+		"out of gas": {
+			err:      ErrOutOfGas,
+			expected: false,
+		},
+		"nil": {
+			err:      nil,
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, IsIntrinsicGasError(test.err))
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	tests := map[string]struct {
+		err               error
+		isExecutionError  bool
+		isValidationError bool
+	}{
+		"out of gas": {
+			err:              ErrOutOfGas,
+			isExecutionError: true,
+		},
+		"revert": {
+			err:              &RevertError{Reason: []byte{0x01}},
+			isExecutionError: true,
+		},
+		"write protection": {
+			err:              ErrWriteProtection,
+			isExecutionError: true,
+		},
+		"max code size exceeded": {
+			err:              fmt.Errorf("create failed: %w", ErrMaxCodeSizeExceeded{Limit: 1, Size: 2}),
+			isExecutionError: true,
+		},
+		"intrinsic gas": {
+			err:               fmt.Errorf("%w: have %d, want %d", ErrIntrinsicGas, 20_000, 21_000),
+			isValidationError: true,
+		},
+		"insufficient balance": {
+			err:              ErrInsufficientBalance,
+			isExecutionError: true,
+		},
+		"prohibited address": {
+			err:              fmt.Errorf("%w: address %v", ErrAddrProhibited, "0x01"),
+			isExecutionError: true,
+		},
+		"prohibited address with address": {
+			err:              AddrProhibitedError{Addr: common.Address{1}},
+			isExecutionError: true,
+		},
+		"unknown": {
+			err: errors.New("unknown"),
+		},
+		"nil": {
+			err: nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(test.isExecutionError, IsExecutionError(test.err))
+			require.Equal(test.isValidationError, IsValidationError(test.err))
+		})
+	}
+}
+
+func TestErrWriteProtectionWithOpcode(t *testing.T) {
+	require := require.New(t)
+
+	err := fmt.Errorf("static call failed: %w", ErrWriteProtectionWithOpcode{Opcode: 0x55})
+	require.ErrorIs(err, ErrWriteProtection)
+	require.True(IsExecutionError(err))
+
+	var writeErr ErrWriteProtectionWithOpcode
+	require.ErrorAs(err, &writeErr)
+	require.Equal(byte(0x55), writeErr.Opcode)
+	require.Equal("static call failed: write protection: opcode 0x55", err.Error())
+
+	require.False(errors.As(ErrWriteProtection, &writeErr))
+}
+
+func TestAddrProhibitedError(t *testing.T) {
+	require := require.New(t)
+
+	addr := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	err := fmt.Errorf("create failed: %w", AddrProhibitedError{Addr: addr})
+	require.ErrorIs(err, ErrAddrProhibited)
+
+	var prohibitedErr AddrProhibitedError
+	require.ErrorAs(err, &prohibitedErr)
+	require.Equal(addr, prohibitedErr.Addr)
+	require.Equal("create failed: prohibited address cannot be sender or created contract address: address 0x0100000000000000000000000000000000000000", err.Error())
+
+	require.False(errors.As(ErrAddrProhibited, &prohibitedErr))
+}
+
+func TestTransactionValidationErrors(t *testing.T) {
+	addr := common.HexToAddress("0x0100000000000000000000000000000000000000")
+	tests := map[string]struct {
+		err         error
+		sentinel    error
+		expectedMsg string
+	}{
+		"nonce too low": {
+			err:         NonceTooLowError{Addr: addr, TxNonce: 1, StateNonce: 2},
+			sentinel:    ErrNonceTooLow,
+			expectedMsg: "nonce too low: address 0x0100000000000000000000000000000000000000, tx: 1 state: 2",
+		},
+		"nonce too high": {
+			err:         NonceTooHighError{Addr: addr, TxNonce: 3, StateNonce: 2},
+			sentinel:    ErrNonceTooHigh,
+			expectedMsg: "nonce too high: address 0x0100000000000000000000000000000000000000, tx: 3 state: 2",
+		},
+		"insufficient funds": {
+			err:         InsufficientFundsError{Addr: addr, Have: big.NewInt(1), Want: big.NewInt(2)},
+			sentinel:    ErrInsufficientFunds,
+			expectedMsg: "insufficient funds for gas * price + value: address 0x0100000000000000000000000000000000000000 have 1 want 2",
+		},
+		"intrinsic gas": {
+			err:         IntrinsicGasError{Have: 20_000, Want: 21_000},
+			sentinel:    ErrIntrinsicGas,
+			expectedMsg: "intrinsic gas too low: have 20000, want 21000",
+		},
+		"fee cap too low": {
+			err:         FeeCapTooLowError{Addr: addr, FeeCap: big.NewInt(1), BaseFee: big.NewInt(2)},
+			sentinel:    ErrFeeCapTooLow,
+			expectedMsg: "max fee per gas less than block base fee: address 0x0100000000000000000000000000000000000000, maxFeePerGas: 1, baseFee: 2",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(test.expectedMsg, test.err.Error())
+			require.ErrorIs(test.err, test.sentinel)
+			wrapped := fmt.Errorf("could not apply tx: %w", test.err)
+			require.ErrorIs(wrapped, test.sentinel)
+			require.True(IsValidationError(test.sentinel))
+			require.True(IsValidationError(wrapped))
+			require.False(IsExecutionError(wrapped))
+		})
+	}
+
+	// The annotated values can be recovered from the wrapped form.
+	var nonceErr NonceTooLowError
+	require.ErrorAs(t, fmt.Errorf("could not apply tx: %w", NonceTooLowError{Addr: addr, TxNonce: 1, StateNonce: 2}), &nonceErr)
+	require.Equal(t, addr, nonceErr.Addr)
+	require.Equal(t, uint64(2), nonceErr.StateNonce)
+	require.NotErrorIs(t, ErrNonceTooLow, ErrNonceTooHigh)
+}
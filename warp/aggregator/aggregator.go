@@ -24,6 +24,10 @@ type AggregateSignatureResult struct {
 	Message *avalancheWarp.Message
 }
 
+// defaultMaxConcurrency is the maximum number of signatures fetched
+// concurrently by an Aggregator created with New.
+const defaultMaxConcurrency = 64
+
 type signatureFetchResult struct {
 	sig    *bls.Signature
 	index  int
@@ -33,17 +37,26 @@ type signatureFetchResult struct {
 // Aggregator requests signatures from validators and
 // aggregates them into a single signature.
 type Aggregator struct {
-	validators  []*avalancheWarp.Validator
-	totalWeight uint64
-	client      SignatureGetter
+	validators     []*avalancheWarp.Validator
+	totalWeight    uint64
+	client         SignatureGetter
+	maxConcurrency int
 }
 
 // New returns a signature aggregator that will attempt to aggregate signatures from [validators].
 func New(client SignatureGetter, validators []*avalancheWarp.Validator, totalWeight uint64) *Aggregator {
+	return NewWithMaxConcurrency(client, validators, totalWeight, defaultMaxConcurrency)
+}
+
+// NewWithMaxConcurrency returns a signature aggregator that will attempt to
+// aggregate signatures from [validators], fetching at most [maxConcurrency]
+// signatures at a time.
+func NewWithMaxConcurrency(client SignatureGetter, validators []*avalancheWarp.Validator, totalWeight uint64, maxConcurrency int) *Aggregator {
 	return &Aggregator{
-		client:      client,
-		validators:  validators,
-		totalWeight: totalWeight,
+		client:         client,
+		validators:     validators,
+		totalWeight:    totalWeight,
+		maxConcurrency: maxConcurrency,
 	}
 }
 
@@ -54,8 +67,13 @@ func (a *Aggregator) AggregateSignatures(ctx context.Context, unsignedMessage *a
 	signatureFetchCtx, signatureFetchCancel := context.WithCancel(ctx)
 	defer signatureFetchCancel()
 
-	// Fetch signatures from validators concurrently.
-	signatureFetchResultChan := make(chan *signatureFetchResult)
+	// Fetch signatures from validators concurrently, at most [maxConcurrency]
+	// at a time. The results are buffered so that fetches which complete
+	// after the threshold is reached do not block.
+	var (
+		signatureFetchResultChan = make(chan *signatureFetchResult, len(a.validators))
+		semaphore                = make(chan struct{}, a.maxConcurrency)
+	)
 	for i, validator := range a.validators {
 		var (
 			i         = i
@@ -64,6 +82,14 @@ func (a *Aggregator) AggregateSignatures(ctx context.Context, unsignedMessage *a
 			nodeID = validator.NodeIDs[0]
 		)
 		go func() {
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-signatureFetchCtx.Done():
+				signatureFetchResultChan <- nil
+				return
+			}
+
 			log.Debug("Fetching warp signature",
 				"nodeID", nodeID,
 				"index", i,
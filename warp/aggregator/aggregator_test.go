@@ -6,15 +6,20 @@ package aggregator
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/coreth/warp/warptest"
 )
 
 func newValidator(t testing.TB, weight uint64) (*bls.SecretKey, *avalancheWarp.Validator) {
@@ -382,3 +387,83 @@ func TestAggregateSignatures(t *testing.T) {
 		})
 	}
 }
+
+// blockSignatureGetter signs block hash messages for the validators in [sks]
+// once the block has been fetched from [blockClient], as a validator serving
+// a BlockSignatureRequest would.
+type blockSignatureGetter struct {
+	blockClient warptest.BlockClient
+	sks         map[ids.NodeID]*bls.SecretKey
+}
+
+func (g *blockSignatureGetter) GetSignature(ctx context.Context, nodeID ids.NodeID, unsignedMessage *avalancheWarp.UnsignedMessage) (*bls.Signature, error) {
+	hash, err := payload.ParseHash(unsignedMessage.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.blockClient.GetAcceptedBlock(ctx, hash.Hash); err != nil {
+		return nil, err
+	}
+	return bls.Sign(g.sks[nodeID], unsignedMessage.Bytes()), nil
+}
+
+func TestAggregateSignaturesMaxConcurrency(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		numValidators  = 16
+		maxConcurrency = 4
+	)
+	var (
+		vdrs = make([]*avalancheWarp.Validator, numValidators)
+		sks  = make(map[ids.NodeID]*bls.SecretKey, numValidators)
+	)
+	for i := range vdrs {
+		var sk *bls.SecretKey
+		sk, vdrs[i] = newValidator(t, 1)
+		sks[vdrs[i].NodeIDs[0]] = sk
+	}
+
+	blkID := ids.GenerateTestID()
+	hashPayload, err := payload.NewHash(blkID)
+	require.NoError(err)
+	unsignedMsg, err := avalancheWarp.NewUnsignedMessage(1338, ids.GenerateTestID(), hashPayload.Bytes())
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Hold every fetch until [maxConcurrency] fetches are in-flight, so that
+	// the peak concurrency reaches the limit, and any fetch beyond the limit
+	// is observed by the tracker.
+	var (
+		inner   = warptest.MakeBlockClient(blkID)
+		calls   atomic.Int32
+		release = make(chan struct{})
+	)
+	blockingClient := warptest.BlockClient(func(ctx context.Context, blkID ids.ID) (snowman.Block, error) {
+		if calls.Add(1) == maxConcurrency {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return inner(ctx, blkID)
+	})
+	tracker, blockClient := warptest.StressTestBlockClient(blockingClient, maxConcurrency)
+
+	aggregator := NewWithMaxConcurrency(
+		&blockSignatureGetter{blockClient: blockClient, sks: sks},
+		vdrs,
+		numValidators,
+		maxConcurrency,
+	)
+	// Require every signature, so that every validator is fetched from.
+	res, err := aggregator.AggregateSignatures(ctx, unsignedMsg, 100)
+	require.NoError(err)
+	require.Equal(uint64(numValidators), res.SignatureWeight)
+	require.LessOrEqual(tracker.PeakConcurrency(), maxConcurrency)
+	require.Equal(maxConcurrency, tracker.PeakConcurrency())
+}
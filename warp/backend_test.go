@@ -4,16 +4,23 @@
 package warp
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/avalanchego/vms/platformvm/warp/payload"
+	"github.com/ava-labs/coreth/core/types"
 	"github.com/ava-labs/coreth/warp/warptest"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -146,6 +153,51 @@ func TestGetBlockSignature(t *testing.T) {
 	require.Error(err)
 }
 
+// testBlockChain is a warptest.BlockChain holding a fixed set of blocks.
+type testBlockChain map[common.Hash]*types.Block
+
+func (c testBlockChain) GetBlockByHash(hash common.Hash) *types.Block {
+	return c[hash]
+}
+
+func TestGetBlockSignatureFromAcceptedChain(t *testing.T) {
+	require := require.New(t)
+
+	parent := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	block := types.NewBlockWithHeader(&types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(2),
+		Time:       10,
+	})
+	chain := testBlockChain{
+		parent.Hash(): parent,
+		block.Hash():  block,
+	}
+	blockClient := warptest.NewBlockClientFromAcceptedChain(chain)
+
+	blkID := ids.ID(block.Hash())
+	blk, err := blockClient.GetAcceptedBlock(context.Background(), blkID)
+	require.NoError(err)
+	require.Equal(blkID, blk.ID())
+	require.Equal(ids.ID(parent.Hash()), blk.Parent())
+	require.Equal(uint64(2), blk.Height())
+	require.Equal(int64(10), blk.Timestamp().Unix())
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	warpSigner := avalancheWarp.NewSigner(sk, networkID, sourceChainID)
+	backend, err := NewBackend(networkID, sourceChainID, warpSigner, blockClient, memdb.New(), 500, nil)
+	require.NoError(err)
+
+	_, err = backend.GetBlockSignature(blkID)
+	require.NoError(err)
+
+	_, err = blockClient.GetAcceptedBlock(context.Background(), ids.GenerateTestID())
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = backend.GetBlockSignature(ids.GenerateTestID())
+	require.Error(err)
+}
+
 func TestZeroSizedCache(t *testing.T) {
 	db := memdb.New()
 
@@ -216,3 +268,119 @@ func TestOffChainMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockClientWithTimeout(t *testing.T) {
+	blkID := ids.GenerateTestID()
+	inner := warptest.MakeBlockClient(blkID)
+	// slowClient returns the blocks of [inner] after [delay], ignoring the
+	// context of the call.
+	slowClient := func(delay time.Duration) warptest.BlockClient {
+		return func(ctx context.Context, blkID ids.ID) (snowman.Block, error) {
+			time.Sleep(delay)
+			return inner(ctx, blkID)
+		}
+	}
+
+	tests := map[string]struct {
+		delay   time.Duration
+		blkID   ids.ID
+		cancel  bool
+		wantErr error
+	}{
+		"before timeout": {
+			blkID: blkID,
+		},
+		"inner error before timeout": {
+			blkID:   ids.GenerateTestID(),
+			wantErr: database.ErrNotFound,
+		},
+		"after timeout": {
+			delay:   time.Second,
+			blkID:   blkID,
+			wantErr: warptest.ErrBlockFetchTimeout,
+		},
+		"cancelled": {
+			delay:   time.Second,
+			blkID:   blkID,
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+			client := warptest.NewBlockClientWithTimeout(slowClient(test.delay), 50*time.Millisecond)
+			start := time.Now()
+			blk, err := client.GetAcceptedBlock(ctx, test.blkID)
+			require.ErrorIs(err, test.wantErr)
+			// The call returns without waiting for the slow client.
+			require.Less(time.Since(start), time.Second)
+			if test.wantErr == nil {
+				require.Equal(test.blkID, blk.ID())
+			}
+		})
+	}
+}
+
+func TestRecordingBlockClient(t *testing.T) {
+	require := require.New(t)
+
+	blkIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	blockClient, recorded := warptest.NewRecordingBlockClient(warptest.MakeBlockClient(blkIDs...))
+	require.Empty(recorded())
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	warpSigner := avalancheWarp.NewSigner(sk, networkID, sourceChainID)
+	backend, err := NewBackend(networkID, sourceChainID, warpSigner, blockClient, memdb.New(), 500, nil)
+	require.NoError(err)
+
+	// Failed lookups are recorded too.
+	unknownBlkID := ids.GenerateTestID()
+	_, err = backend.GetBlockSignature(blkIDs[1])
+	require.NoError(err)
+	_, err = backend.GetBlockSignature(unknownBlkID)
+	require.Error(err)
+	_, err = backend.GetBlockSignature(blkIDs[0])
+	require.NoError(err)
+	require.Equal([]ids.ID{blkIDs[1], unknownBlkID, blkIDs[0]}, recorded())
+}
+
+func TestCachingBlockClient(t *testing.T) {
+	require := require.New(t)
+
+	blkIDs := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID(), ids.GenerateTestID()}
+	inner, recorded := warptest.NewRecordingBlockClient(warptest.MakeBlockClient(blkIDs...))
+	blockClient := NewCachingBlockClient(inner, 2)
+
+	ctx := context.Background()
+	for _, blkID := range []ids.ID{blkIDs[0], blkIDs[1], blkIDs[0], blkIDs[1]} {
+		blk, err := blockClient.GetAcceptedBlock(ctx, blkID)
+		require.NoError(err)
+		require.Equal(blkID, blk.ID())
+	}
+	require.Equal(blkIDs[:2], recorded())
+
+	// Failed lookups are not cached.
+	unknownBlkID := ids.GenerateTestID()
+	for i := 0; i < 2; i++ {
+		_, err := blockClient.GetAcceptedBlock(ctx, unknownBlkID)
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+	require.Equal([]ids.ID{blkIDs[0], blkIDs[1], unknownBlkID, unknownBlkID}, recorded())
+
+	// Fetching a third block evicts the least recently used one.
+	_, err := blockClient.GetAcceptedBlock(ctx, blkIDs[2])
+	require.NoError(err)
+	_, err = blockClient.GetAcceptedBlock(ctx, blkIDs[1])
+	require.NoError(err)
+	_, err = blockClient.GetAcceptedBlock(ctx, blkIDs[0])
+	require.NoError(err)
+	require.Equal([]ids.ID{blkIDs[0], blkIDs[1], unknownBlkID, unknownBlkID, blkIDs[2], blkIDs[0]}, recorded())
+}
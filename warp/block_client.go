@@ -0,0 +1,43 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+var _ BlockClient = (*cachingBlockClient)(nil)
+
+// cachingBlockClient caches the accepted blocks returned by an inner
+// BlockClient, as an accepted block never changes.
+type cachingBlockClient struct {
+	inner BlockClient
+	cache *cache.LRU[ids.ID, snowman.Block]
+}
+
+// NewCachingBlockClient returns a BlockClient forwarding calls to [inner] and
+// caching up to [size] of the blocks it returns, so that a block requested
+// again is not fetched from [inner]. Errors are not cached.
+func NewCachingBlockClient(inner BlockClient, size int) BlockClient {
+	return &cachingBlockClient{
+		inner: inner,
+		cache: &cache.LRU[ids.ID, snowman.Block]{Size: size},
+	}
+}
+
+func (c *cachingBlockClient) GetAcceptedBlock(ctx context.Context, blockID ids.ID) (snowman.Block, error) {
+	if blk, ok := c.cache.Get(blockID); ok {
+		return blk, nil
+	}
+	blk, err := c.inner.GetAcceptedBlock(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Put(blockID, blk)
+	return blk, nil
+}
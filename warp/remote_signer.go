@@ -0,0 +1,185 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+	pb "github.com/ava-labs/avalanchego/proto/pb/warp"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ethereum/go-ethereum/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// remoteSignerFailureThreshold is the number of consecutive failures
+	// after which the circuit breaker of a RemoteSigner opens.
+	remoteSignerFailureThreshold = 5
+	// remoteSignerCooldown is how long the circuit breaker stays open before
+	// a request is sent to the remote signer again, and how long a failure to
+	// sign a message is cached.
+	remoteSignerCooldown = 10 * time.Second
+	// remoteSignerFailureCacheSize is the number of messages whose signing
+	// failure is cached.
+	remoteSignerFailureCacheSize = 1024
+)
+
+var (
+	_ avalancheWarp.Signer = (*RemoteSigner)(nil)
+
+	ErrRemoteSignerUnavailable      = errors.New("remote warp signer unavailable")
+	ErrRemoteSignerInvalidSignature = errors.New("remote warp signer returned an invalid signature")
+
+	errMissingCACerts = errors.New("no CA certificates found")
+)
+
+// RemoteSigner is an avalancheWarp.Signer which signs messages with a remote
+// gRPC signer, so that the BLS key of the node does not need to be on the
+// same host.
+//
+// The remote signer is only trusted to sign: the signatures it returns are
+// verified against the public key of the node, which is known at startup.
+// Each request is bounded by a timeout, and after consecutive failures a
+// circuit breaker fails all requests without sending them until a cooldown
+// elapses. Messages which failed to be signed are not retried until the
+// cooldown elapses either, so that callers repeatedly asking for the same
+// signature do not stall on an unhealthy signer.
+type RemoteSigner struct {
+	client    pb.SignerClient
+	publicKey *bls.PublicKey
+	timeout   time.Duration
+
+	lock sync.Mutex
+	// failures is the number of consecutive failures of the remote signer.
+	failures int
+	// openUntil is the time until which the circuit breaker is open.
+	openUntil time.Time
+	// failedMessages maps the IDs of the messages which failed to be signed
+	// to the time until which the failure is cached.
+	failedMessages *cache.LRU[ids.ID, time.Time]
+
+	clock mockable.Clock
+}
+
+// NewRemoteSigner returns a RemoteSigner signing with [client] on behalf of
+// the node with [publicKey], timing out requests after [timeout].
+func NewRemoteSigner(client pb.SignerClient, publicKey *bls.PublicKey, timeout time.Duration) *RemoteSigner {
+	return &RemoteSigner{
+		client:         client,
+		publicKey:      publicKey,
+		timeout:        timeout,
+		failedMessages: &cache.LRU[ids.ID, time.Time]{Size: remoteSignerFailureCacheSize},
+	}
+}
+
+// DialRemoteSigner connects to the remote signer at [endpoint] with mutual
+// TLS, authenticating with the certificate and key at [certFile] and
+// [keyFile], and verifying the signer with the CA certificates at [caFile].
+func DialRemoteSigner(endpoint, certFile, keyFile, caFile string) (*grpc.ClientConn, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load warp signer client certificate: %w", err)
+	}
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read warp signer CA certificates: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("%w in %s", errMissingCACerts, caFile)
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caPool,
+		MinVersion:   tls.VersionTLS13,
+	})
+	return grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+}
+
+// Sign implements avalancheWarp.Signer.
+func (s *RemoteSigner) Sign(unsignedMsg *avalancheWarp.UnsignedMessage) ([]byte, error) {
+	msgID := unsignedMsg.ID()
+	if err := s.allow(msgID); err != nil {
+		return nil, err
+	}
+
+	sig, err := s.sign(unsignedMsg)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err != nil {
+		s.failures++
+		now := s.clock.Time()
+		if s.failures >= remoteSignerFailureThreshold {
+			s.openUntil = now.Add(remoteSignerCooldown)
+		}
+		s.failedMessages.Put(msgID, now.Add(remoteSignerCooldown))
+		log.Warn("Failed to sign warp message with remote signer",
+			"msgID", msgID,
+			"consecutiveFailures", s.failures,
+			"err", err,
+		)
+		return nil, err
+	}
+	s.failures = 0
+	s.openUntil = time.Time{}
+	s.failedMessages.Evict(msgID)
+	return sig, nil
+}
+
+// allow returns an error if the signing of the message with [msgID] should
+// not be requested, because the circuit breaker is open or it recently
+// failed.
+func (s *RemoteSigner) allow(msgID ids.ID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.clock.Time()
+	if now.Before(s.openUntil) {
+		return fmt.Errorf("%w: %d consecutive failures", ErrRemoteSignerUnavailable, s.failures)
+	}
+	if until, ok := s.failedMessages.Get(msgID); ok {
+		if now.Before(until) {
+			return fmt.Errorf("%w: message %s recently failed to be signed", ErrRemoteSignerUnavailable, msgID)
+		}
+		s.failedMessages.Evict(msgID)
+	}
+	return nil
+}
+
+// sign requests the signature of [unsignedMsg] from the remote signer and
+// verifies it.
+func (s *RemoteSigner) sign(unsignedMsg *avalancheWarp.UnsignedMessage) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp, err := s.client.Sign(ctx, &pb.SignRequest{
+		NetworkId:     unsignedMsg.NetworkID,
+		SourceChainId: unsignedMsg.SourceChainID[:],
+		Payload:       unsignedMsg.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := bls.SignatureFromBytes(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteSignerInvalidSignature, err)
+	}
+	if !bls.Verify(s.publicKey, sig, unsignedMsg.Bytes()) {
+		return nil, ErrRemoteSignerInvalidSignature
+	}
+	return resp.Signature, nil
+}
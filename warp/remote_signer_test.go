@@ -0,0 +1,143 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package warp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/coreth/warp/warptest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRemoteSigner(t *testing.T, timeout time.Duration) (*RemoteSigner, *warptest.SignerServer, avalancheWarp.Signer) {
+	t.Helper()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	localSigner := avalancheWarp.NewSigner(sk, networkID, sourceChainID)
+	server := warptest.NewSignerServer(localSigner)
+	signer := NewRemoteSigner(server.Client(), bls.PublicFromSecretKey(sk), timeout)
+	signer.clock.Set(time.Unix(1_000, 0))
+	return signer, server, localSigner
+}
+
+func newTestUnsignedMessage(t *testing.T, payload string) *avalancheWarp.UnsignedMessage {
+	t.Helper()
+
+	msg, err := avalancheWarp.NewUnsignedMessage(networkID, sourceChainID, []byte(payload))
+	require.NoError(t, err)
+	return msg
+}
+
+func TestRemoteSigner(t *testing.T) {
+	require := require.New(t)
+	signer, server, localSigner := newTestRemoteSigner(t, time.Second)
+
+	// The remote signer signs like the local signer.
+	want, err := localSigner.Sign(testUnsignedMessage)
+	require.NoError(err)
+	sig, err := signer.Sign(testUnsignedMessage)
+	require.NoError(err)
+	require.Equal(want, sig)
+
+	// The backend can sign with the remote signer.
+	backend, err := NewBackend(networkID, sourceChainID, signer, nil, memdb.New(), 500, nil)
+	require.NoError(err)
+	require.NoError(backend.AddMessage(testUnsignedMessage))
+	backendSig, err := backend.GetMessageSignature(testUnsignedMessage.ID())
+	require.NoError(err)
+	require.Equal(want, backendSig[:])
+	require.Equal(2, server.Requests())
+}
+
+func TestRemoteSignerFailures(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := map[string]struct {
+		setup   func(*warptest.SignerServer)
+		wantErr error
+	}{
+		"error": {
+			setup:   func(s *warptest.SignerServer) { s.SetErr(errFailed) },
+			wantErr: errFailed,
+		},
+		"timeout": {
+			setup:   func(s *warptest.SignerServer) { s.SetHang(true) },
+			wantErr: context.DeadlineExceeded,
+		},
+		"invalid signature": {
+			setup:   func(s *warptest.SignerServer) { s.SetBadSignatures(true) },
+			wantErr: ErrRemoteSignerInvalidSignature,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			signer, server, _ := newTestRemoteSigner(t, 10*time.Millisecond)
+
+			test.setup(server)
+			_, err := signer.Sign(testUnsignedMessage)
+			require.ErrorIs(err, test.wantErr)
+			require.Equal(1, server.Requests())
+
+			// The failure is cached, so the message is not requested again
+			// until the cooldown elapses.
+			_, err = signer.Sign(testUnsignedMessage)
+			require.ErrorIs(err, ErrRemoteSignerUnavailable)
+			require.Equal(1, server.Requests())
+
+			server.SetErr(nil)
+			server.SetHang(false)
+			server.SetBadSignatures(false)
+			signer.clock.Set(signer.clock.Time().Add(remoteSignerCooldown))
+			_, err = signer.Sign(testUnsignedMessage)
+			require.NoError(err)
+			require.Equal(2, server.Requests())
+		})
+	}
+}
+
+func TestRemoteSignerCircuitBreaker(t *testing.T) {
+	require := require.New(t)
+	signer, server, _ := newTestRemoteSigner(t, time.Second)
+
+	server.SetErr(errors.New("failed"))
+	for i := 0; i < remoteSignerFailureThreshold; i++ {
+		_, err := signer.Sign(newTestUnsignedMessage(t, fmt.Sprintf("message %d", i)))
+		require.Error(err)
+		require.NotErrorIs(err, ErrRemoteSignerUnavailable)
+	}
+	require.Equal(remoteSignerFailureThreshold, server.Requests())
+
+	// The circuit breaker is open, so no request is sent.
+	server.SetErr(nil)
+	_, err := signer.Sign(testUnsignedMessage)
+	require.ErrorIs(err, ErrRemoteSignerUnavailable)
+	require.Equal(remoteSignerFailureThreshold, server.Requests())
+
+	// A failure once the cooldown elapsed opens the circuit breaker again.
+	server.SetErr(errors.New("failed"))
+	signer.clock.Set(signer.clock.Time().Add(remoteSignerCooldown))
+	_, err = signer.Sign(testUnsignedMessage)
+	require.Error(err)
+	require.NotErrorIs(err, ErrRemoteSignerUnavailable)
+	_, err = signer.Sign(newTestUnsignedMessage(t, "other message"))
+	require.ErrorIs(err, ErrRemoteSignerUnavailable)
+	require.Equal(remoteSignerFailureThreshold+1, server.Requests())
+
+	// A success once the cooldown elapsed closes the circuit breaker.
+	server.SetErr(nil)
+	signer.clock.Set(signer.clock.Time().Add(remoteSignerCooldown))
+	_, err = signer.Sign(testUnsignedMessage)
+	require.NoError(err)
+	_, err = signer.Sign(newTestUnsignedMessage(t, "other message"))
+	require.NoError(err)
+	require.Equal(remoteSignerFailureThreshold+3, server.Requests())
+}
@@ -0,0 +1,51 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package warptest
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman/snowmantest"
+	"github.com/ava-labs/avalanchego/snow/snowtest"
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BlockChain is the subset of the blockchain used by
+// NewBlockClientFromAcceptedChain. It is implemented by *core.BlockChain.
+type BlockChain interface {
+	GetBlockByHash(common.Hash) *types.Block
+}
+
+// NewBlockClientFromAcceptedChain returns a BlockClient that returns the blocks
+// of [blockchain] as accepted blocks. If a block is requested that isn't known
+// to [blockchain], database.ErrNotFound is returned.
+//
+// The caller is responsible for only inserting accepted blocks into
+// [blockchain], as GetBlockByHash does not distinguish them from blocks which
+// are still processing.
+func NewBlockClientFromAcceptedChain(blockchain BlockChain) BlockClient {
+	return func(_ context.Context, blkID ids.ID) (snowman.Block, error) {
+		block := blockchain.GetBlockByHash(common.Hash(blkID))
+		if block == nil {
+			return nil, database.ErrNotFound
+		}
+
+		return &snowmantest.Block{
+			Decidable: snowtest.Decidable{
+				IDV:    blkID,
+				Status: snowtest.Accepted,
+			},
+			ParentV:    ids.ID(block.ParentHash()),
+			HeightV:    block.NumberU64(),
+			TimestampV: time.Unix(int64(block.Time()), 0),
+		}, nil
+	}
+}
@@ -0,0 +1,47 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package warptest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+// RecordingBlockClient forwards calls to an inner BlockClient and records the
+// IDs of the blocks requested.
+type RecordingBlockClient struct {
+	inner BlockClient
+
+	lock      sync.Mutex
+	requested []ids.ID
+}
+
+// NewRecordingBlockClient returns a RecordingBlockClient forwarding calls to
+// [inner], and a function returning the IDs of the blocks requested so far in
+// call order.
+func NewRecordingBlockClient(inner BlockClient) (*RecordingBlockClient, func() []ids.ID) {
+	c := &RecordingBlockClient{inner: inner}
+	return c, c.recorded
+}
+
+func (c *RecordingBlockClient) GetAcceptedBlock(ctx context.Context, blockID ids.ID) (snowman.Block, error) {
+	c.lock.Lock()
+	c.requested = append(c.requested, blockID)
+	c.lock.Unlock()
+
+	return c.inner(ctx, blockID)
+}
+
+func (c *RecordingBlockClient) recorded() []ids.ID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return slices.Clone(c.requested)
+}
@@ -0,0 +1,119 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package warptest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	pb "github.com/ava-labs/avalanchego/proto/pb/warp"
+	avalancheWarp "github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"google.golang.org/grpc"
+)
+
+var (
+	_ pb.SignerServer = (*SignerServer)(nil)
+	_ pb.SignerClient = (*signerClient)(nil)
+)
+
+// SignerServer is an in-process remote warp signer, signing with [Signer].
+// It can be made to fail, hang or return invalid signatures to test the
+// clients of remote signers.
+type SignerServer struct {
+	pb.UnsafeSignerServer
+	Signer avalancheWarp.Signer
+
+	lock          sync.Mutex
+	err           error
+	hang          bool
+	badSignatures bool
+	requests      int
+}
+
+// NewSignerServer returns a SignerServer signing with [signer].
+func NewSignerServer(signer avalancheWarp.Signer) *SignerServer {
+	return &SignerServer{Signer: signer}
+}
+
+// SetErr makes the requests fail with [err], or succeed if [err] is nil.
+func (s *SignerServer) SetErr(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = err
+}
+
+// SetHang makes the requests block until they are cancelled.
+func (s *SignerServer) SetHang(hang bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.hang = hang
+}
+
+// SetBadSignatures makes the requests return signatures of other messages.
+func (s *SignerServer) SetBadSignatures(bad bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.badSignatures = bad
+}
+
+// Requests returns the number of requests received.
+func (s *SignerServer) Requests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.requests
+}
+
+// Sign implements pb.SignerServer.
+func (s *SignerServer) Sign(ctx context.Context, req *pb.SignRequest) (*pb.SignResponse, error) {
+	s.lock.Lock()
+	s.requests++
+	err, hang, bad := s.err, s.hang, s.badSignatures
+	s.lock.Unlock()
+
+	if hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sourceChainID, err := ids.ToID(req.SourceChainId)
+	if err != nil {
+		return nil, err
+	}
+	payload := req.Payload
+	if bad {
+		payload = append([]byte{0xff}, payload...)
+	}
+	msg, err := avalancheWarp.NewUnsignedMessage(req.NetworkId, sourceChainID, payload)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Signer.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignResponse{Signature: sig}, nil
+}
+
+// Client returns a pb.SignerClient calling [s] in process.
+func (s *SignerServer) Client() pb.SignerClient {
+	return &signerClient{server: s}
+}
+
+type signerClient struct {
+	server *SignerServer
+}
+
+func (c *signerClient) Sign(ctx context.Context, req *pb.SignRequest, _ ...grpc.CallOption) (*pb.SignResponse, error) {
+	return c.server.Sign(ctx, req)
+}
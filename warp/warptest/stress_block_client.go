@@ -0,0 +1,71 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package warptest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+var ErrMaxConcurrencyExceeded = errors.New("max concurrency exceeded")
+
+// ConcurrencyTracker records the number of simultaneous in-flight calls made
+// to a BlockClient returned by StressTestBlockClient.
+type ConcurrencyTracker struct {
+	lock     sync.Mutex
+	inFlight int
+	peak     int
+	max      int
+}
+
+// PeakConcurrency returns the maximum number of simultaneous in-flight calls
+// observed.
+func (c *ConcurrencyTracker) PeakConcurrency() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.peak
+}
+
+func (c *ConcurrencyTracker) start() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	if c.inFlight > c.max {
+		return ErrMaxConcurrencyExceeded
+	}
+	return nil
+}
+
+func (c *ConcurrencyTracker) finish() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.inFlight--
+}
+
+// StressTestBlockClient returns a BlockClient that forwards calls to [inner]
+// and a ConcurrencyTracker that records the peak number of simultaneous calls.
+// Calls made while more than [maxConcurrency] calls are in-flight return
+// ErrMaxConcurrencyExceeded without calling [inner].
+func StressTestBlockClient(inner BlockClient, maxConcurrency int) (*ConcurrencyTracker, BlockClient) {
+	tracker := &ConcurrencyTracker{
+		max: maxConcurrency,
+	}
+	return tracker, func(ctx context.Context, blkID ids.ID) (snowman.Block, error) {
+		defer tracker.finish()
+		if err := tracker.start(); err != nil {
+			return nil, err
+		}
+		return inner(ctx, blkID)
+	}
+}
@@ -0,0 +1,55 @@
+// (c) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package warptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+var ErrBlockFetchTimeout = errors.New("block fetch timed out")
+
+type blockResult struct {
+	blk snowman.Block
+	err error
+}
+
+// NewBlockClientWithTimeout returns a BlockClient that forwards calls to
+// [inner] with a context cancelled after [timeout]. Calls still in progress
+// after [timeout] return ErrBlockFetchTimeout without waiting for [inner] to
+// return, and calls whose own context is done first return its error.
+func NewBlockClientWithTimeout(inner BlockClient, timeout time.Duration) BlockClient {
+	return func(ctx context.Context, blkID ids.ID) (snowman.Block, error) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// [results] is buffered so that [inner] does not block once the call
+		// has returned.
+		results := make(chan blockResult, 1)
+		go func() {
+			blk, err := inner(timeoutCtx, blkID)
+			results <- blockResult{blk: blk, err: err}
+		}()
+
+		select {
+		case result := <-results:
+			// Only the errors caused by the timeout are replaced.
+			if result.err == nil || timeoutCtx.Err() == nil || ctx.Err() != nil {
+				return result.blk, result.err
+			}
+		case <-timeoutCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("%w: block %s after %s", ErrBlockFetchTimeout, blkID, timeout)
+	}
+}